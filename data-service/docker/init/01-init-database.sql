@@ -5,6 +5,7 @@
 
 -- Enable required extensions
 CREATE EXTENSION IF NOT EXISTS "pgcrypto";
+CREATE EXTENSION IF NOT EXISTS pg_stat_statements;
 
 -- Create sequences
 CREATE SEQUENCE IF NOT EXISTS order_number_seq START 1;
@@ -41,11 +42,17 @@ CREATE TABLE customers (
 -- 6. Menu Categories (top level: Drinks, Desserts, etc.)
 CREATE TABLE menu_categories (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    name VARCHAR(255) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
     display_order INTEGER NOT NULL DEFAULT 0,
     description TEXT,
+    -- Earning margin prices are suggested at, NULL uses the DEFAULT_EARNING_MARGIN setting
+    target_margin DECIMAL(5,2) CHECK (target_margin >= 0 AND target_margin < 100),
+    -- Name and description in other languages than DEFAULT_LANGUAGE, keyed by language tag
+    translations JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(translations) = 'object'),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
 );
 
 -- 7. Menu Sub-Categories (second level: Smoothies, Sodas, etc. - grouped by category)
@@ -57,8 +64,13 @@ CREATE TABLE menu_sub_categories (
     item_type VARCHAR(20) NOT NULL CHECK (item_type IN ('kitchen', 'bar')),
     display_order INTEGER NOT NULL DEFAULT 0,
     is_active BOOLEAN NOT NULL DEFAULT true,
+    -- Overrides the category's target margin, NULL inherits it
+    target_margin DECIMAL(5,2) CHECK (target_margin >= 0 AND target_margin < 100),
+    translations JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(translations) = 'object'),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
 );
 
 -- 8. Menu Variants (third level: Banana Smoothie, Pineapple Smoothie, etc. - with pricing)
@@ -78,19 +90,24 @@ CREATE TABLE menu_variants (
     dietary_tags JSONB,
     allergens JSONB,
     is_alcoholic BOOLEAN DEFAULT false,
+    translations JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(translations) = 'object'),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
 );
 
 -- 9. Stock Categories
 CREATE TABLE stock_categories (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    name VARCHAR(255) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
     description TEXT,
     display_order INTEGER NOT NULL DEFAULT 0,
     is_active BOOLEAN NOT NULL DEFAULT true,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
 );
 
 -- 10. Stock Sub-Categories
@@ -98,11 +115,13 @@ CREATE TABLE stock_sub_categories (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     name VARCHAR(255) NOT NULL,
     description TEXT,
-    stock_category_id UUID NOT NULL REFERENCES stock_categories(id) ON DELETE CASCADE,
+    stock_category_id UUID NOT NULL REFERENCES stock_categories(id) ON DELETE RESTRICT,
     display_order INTEGER NOT NULL DEFAULT 0,
     is_active BOOLEAN NOT NULL DEFAULT true,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
 );
 
 -- 11. Stock Variants (simplified - actual counts are tracked in stock_count table)
@@ -110,11 +129,13 @@ CREATE TABLE stock_variants (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     name VARCHAR(255) NOT NULL,
     description TEXT,
-    stock_sub_category_id UUID NOT NULL REFERENCES stock_sub_categories(id) ON DELETE CASCADE,
+    stock_sub_category_id UUID NOT NULL REFERENCES stock_sub_categories(id) ON DELETE RESTRICT,
     avg_cost DECIMAL(10,2) DEFAULT 0,  -- Average cost per portion across active stock counts
     is_active BOOLEAN NOT NULL DEFAULT true,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
 );
 
 -- 12. Menu Ingredients (links menu items to stock variants they require)
@@ -126,30 +147,145 @@ CREATE TABLE menu_ingredients (
     quantity DECIMAL(10,2) NOT NULL CHECK (quantity > 0),
     is_optional BOOLEAN NOT NULL DEFAULT false,
     notes TEXT,
+    -- How "any variant of a sub-category" is costed, NULL uses the RECIPE_COST_STRATEGY setting
+    cost_strategy VARCHAR(10),
+    default_variant_id UUID REFERENCES menu_variants(id) ON DELETE SET NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
     -- Ensure exactly one of stock_variant_id or menu_sub_category_id is provided
     CONSTRAINT chk_ingredient_type CHECK (
         (stock_variant_id IS NOT NULL AND menu_sub_category_id IS NULL) OR
         (stock_variant_id IS NULL AND menu_sub_category_id IS NOT NULL)
+    ),
+    -- Only sub-category ingredients have a cost strategy
+    CONSTRAINT chk_ingredient_cost_strategy CHECK (
+        (cost_strategy IS NULL OR cost_strategy IN ('average', 'max', 'default')) AND
+        (menu_sub_category_id IS NOT NULL OR (cost_strategy IS NULL AND default_variant_id IS NULL))
     )
 );
 
 -- Menu Ingredients indexes
 CREATE INDEX idx_menu_ingredients_stock_variant ON menu_ingredients(stock_variant_id);
 CREATE INDEX idx_menu_ingredients_menu_sub_category ON menu_ingredients(menu_sub_category_id);
+CREATE INDEX idx_menu_ingredients_default_variant ON menu_ingredients(default_variant_id);
+
+-- 12. Service Periods (when each menu type of menu_variants.menu_types is served)
+CREATE TABLE service_periods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) NOT NULL,
+    description TEXT,
+    -- [{"days": ["monday", ...], "from": "11:00", "to": "15:00"}] on the venue's wall clock
+    windows JSONB NOT NULL CHECK (jsonb_typeof(windows) = 'array' AND jsonb_array_length(windows) > 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
+);
+
+-- 12. Modifier Groups ("choose your mixer", "extra shot", "no onions" - picked when ordering)
+CREATE TABLE modifier_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    min_selections INTEGER NOT NULL DEFAULT 0 CHECK (min_selections >= 0),
+    -- NULL allows picking every option
+    max_selections INTEGER CHECK (max_selections >= 1),
+    display_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP,
+    CONSTRAINT chk_modifier_group_selections CHECK (max_selections IS NULL OR max_selections >= min_selections)
+);
+
+CREATE TABLE modifier_options (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    modifier_group_id UUID NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(10,2) NOT NULL DEFAULT 0,
+    stock_variant_id UUID REFERENCES stock_variants(id) ON DELETE RESTRICT,
+    quantity DECIMAL(10,2) CHECK (quantity > 0),
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- A stock variant is consumed in a quantity, and only then
+    CONSTRAINT chk_modifier_option_stock CHECK ((stock_variant_id IS NULL) = (quantity IS NULL))
+);
+
+-- A group is attached to either a menu variant or a menu sub-category
+CREATE TABLE modifier_group_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    modifier_group_id UUID NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    menu_variant_id UUID REFERENCES menu_variants(id) ON DELETE CASCADE,
+    menu_sub_category_id UUID REFERENCES menu_sub_categories(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_modifier_group_link_target CHECK ((menu_variant_id IS NULL) <> (menu_sub_category_id IS NULL))
+);
+
+-- Modifier Groups indexes
+CREATE UNIQUE INDEX idx_modifier_options_name ON modifier_options(modifier_group_id, name);
+CREATE INDEX idx_modifier_options_stock_variant ON modifier_options(stock_variant_id);
+CREATE UNIQUE INDEX idx_modifier_group_links_variant ON modifier_group_links(modifier_group_id, menu_variant_id) WHERE menu_variant_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_modifier_group_links_sub_category ON modifier_group_links(modifier_group_id, menu_sub_category_id) WHERE menu_sub_category_id IS NOT NULL;
+CREATE INDEX idx_modifier_group_links_menu_variant ON modifier_group_links(menu_variant_id);
+CREATE INDEX idx_modifier_group_links_menu_sub_category ON modifier_group_links(menu_sub_category_id);
+
+-- 12. Combos ("burger + fries + soda" - menu variants sold together at one price)
+CREATE TABLE combos (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    price DECIMAL(10,2) NOT NULL CHECK (price >= 0),
+    image_url VARCHAR(500),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
+);
+
+-- A slot with one option is fixed, one with several is choose-one
+CREATE TABLE combo_slots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    combo_id UUID NOT NULL REFERENCES combos(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    is_required BOOLEAN NOT NULL DEFAULT true,
+    display_order INTEGER NOT NULL DEFAULT 0
+);
+
+-- price_delta is charged on top of the combo price for upgrades, e.g. large fries
+CREATE TABLE combo_slot_options (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    combo_slot_id UUID NOT NULL REFERENCES combo_slots(id) ON DELETE CASCADE,
+    menu_variant_id UUID NOT NULL REFERENCES menu_variants(id) ON DELETE RESTRICT,
+    price_delta DECIMAL(10,2) NOT NULL DEFAULT 0,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    display_order INTEGER NOT NULL DEFAULT 0
+);
+
+-- Combos indexes
+CREATE INDEX idx_combo_slots_combo ON combo_slots(combo_id);
+CREATE UNIQUE INDEX idx_combo_slot_options_variant ON combo_slot_options(combo_slot_id, menu_variant_id);
+CREATE INDEX idx_combo_slot_options_menu_variant ON combo_slot_options(menu_variant_id);
 
 -- 13. Suppliers
 CREATE TABLE suppliers (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    name VARCHAR(255) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
     contact_name VARCHAR(255),
     phone VARCHAR(20),
     email VARCHAR(255),
     address TEXT,
     notes TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
 );
 
 -- 14. Outcome Invoices (Supplier Purchase Invoices)
@@ -166,14 +302,16 @@ CREATE TABLE outcome_invoices (
     image_url VARCHAR(500),
     notes TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
 );
 
 -- 15. Stock Count (inventory tracking - links stock variants to purchases)
 CREATE TABLE stock_count (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    stock_variant_id UUID NOT NULL REFERENCES stock_variants(id) ON DELETE CASCADE,
-    invoice_id UUID REFERENCES outcome_invoices(id) ON DELETE CASCADE,  -- Nullable for manual stock counts
+    stock_variant_id UUID NOT NULL REFERENCES stock_variants(id) ON DELETE RESTRICT,
+    invoice_id UUID REFERENCES outcome_invoices(id) ON DELETE RESTRICT,  -- Nullable for manual stock counts
     count DECIMAL(10,2) NOT NULL CHECK (count > 0),
     unit VARCHAR(50) NOT NULL,  -- Supported: kg, g, l, ml (all convert to kg for cost calculation)
     unit_price DECIMAL(10,2),  -- Price per unit (passed from invoice or manual input)
@@ -181,7 +319,9 @@ CREATE TABLE stock_count (
     purchased_at TIMESTAMP NOT NULL,
     is_out BOOLEAN NOT NULL DEFAULT false,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
 );
 
 -- Stock Count indexes
@@ -336,7 +476,9 @@ CREATE TABLE income_invoices (
     status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'generated', 'sent', 'cancelled')),
     generated_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
 );
 
 -- 23. Table Sessions
@@ -453,6 +595,63 @@ CREATE TABLE settings (
     UNIQUE(service, key)
 );
 
+-- =============================================================================
+-- EVENT OUTBOX FOR CROSS-SERVICE EVENTS
+-- =============================================================================
+
+-- Domain events written in the same transaction as the change that raised them
+CREATE TABLE outbox_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type VARCHAR(100) NOT NULL,
+    aggregate_type VARCHAR(100) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP
+);
+
+-- Events already handled by each consumer (idempotent delivery)
+CREATE TABLE processed_events (
+    consumer VARCHAR(50) NOT NULL,
+    event_id UUID NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+    processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (consumer, event_id)
+);
+
+-- Failed deliveries per consumer; an event is dead-lettered after too many attempts
+CREATE TABLE event_failures (
+    consumer VARCHAR(50) NOT NULL,
+    event_id UUID NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    failed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dead_lettered_at TIMESTAMP,
+    PRIMARY KEY (consumer, event_id)
+);
+
+-- =============================================================================
+-- AUDIT TRAIL OF DATA CHANGES
+-- =============================================================================
+
+-- Every create, update, delete and restore with who made it; before/after hold the
+-- whole row for creates, deletes and restores and only the changed fields for updates
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entity VARCHAR(100) NOT NULL,
+    entity_id VARCHAR(100) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore')),
+    before JSONB,
+    after JSONB,
+    actor_id VARCHAR(100) NOT NULL,
+    actor_name VARCHAR(100),
+    request_id VARCHAR(100),
+    source VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 -- =============================================================================
 -- INDEXES FOR PERFORMANCE
 -- =============================================================================
@@ -513,6 +712,12 @@ INSERT INTO settings (service, key, value, description) VALUES
 ('Data', 'DB_CONN_MAX_IDLE_TIME', '5m', 'Maximum idle time of connections'),
 ('Data', 'DB_CONNECT_TIMEOUT', '10s', 'Database connection timeout'),
 ('Data', 'DB_QUERY_TIMEOUT', '30s', 'Database query timeout'),
+('Data', 'RETENTION_STOCK_COUNT_DAYS', '365', 'Days stock counts marked out stay live before they are archived'),
+('Data', 'RETENTION_ORDER_ITEMS_DAYS', '730', 'Days items of paid or cancelled orders stay live before they are archived'),
+('Data', 'RETENTION_INCOME_INVOICES_DAYS', '1825', 'Days issued sales invoices stay live before they are archived, the legal retention period'),
+('Data', 'RETENTION_OUTCOME_INVOICES_DAYS', '1825', 'Days purchase invoices and their items stay live before they are archived, the legal retention period'),
+('Data', 'RETENTION_REQUEST_NOTIFICATIONS_DAYS', '30', 'Days completed or cancelled notifications are kept before they are deleted'),
+('Data', 'RETENTION_SESSIONS_DAYS', '30', 'Days sessions are kept before they are deleted'),
 
 -- UI Service Settings
 ('UI', 'UI_PORT', '3000', 'Port for the UI service'),
@@ -747,8 +952,6 @@ SELECT 'Manzana', 'Manzana fresca', id FROM stock_sub_categories WHERE name = 'F
 INSERT INTO stock_variants (name, description, stock_sub_category_id)
 SELECT 'Pera', 'Pera fresca', id FROM stock_sub_categories WHERE name = 'Frutas';
 INSERT INTO stock_variants (name, description, stock_sub_category_id)
-SELECT 'Naranja', 'Naranja fresca', id FROM stock_sub_categories WHERE name = 'Frutas';
-INSERT INTO stock_variants (name, description, stock_sub_category_id)
 SELECT 'Uva', 'Uva fresca', id FROM stock_sub_categories WHERE name = 'Frutas';
 
 -- 2. Frutas y Verduras - Verduras
@@ -1201,6 +1404,95 @@ CREATE INDEX idx_promotions_dates ON promotions(valid_from, valid_until);
 CREATE INDEX idx_settings_service ON settings(service);
 CREATE INDEX idx_settings_key ON settings(key);
 
+-- Outbox indexes
+CREATE INDEX idx_outbox_events_unpublished ON outbox_events(created_at) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_events_type_created ON outbox_events(event_type, created_at);
+CREATE INDEX idx_event_failures_dead_lettered ON event_failures(consumer, dead_lettered_at) WHERE dead_lettered_at IS NOT NULL;
+
+-- Audit log indexes
+CREATE INDEX idx_audit_log_entity ON audit_log(entity, entity_id, created_at);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, created_at);
+CREATE INDEX idx_audit_log_created ON audit_log(created_at);
+
+-- Soft delete: names are only unique among live rows, deleted rows are found by the purge job
+CREATE UNIQUE INDEX idx_menu_categories_name ON menu_categories(name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_stock_categories_name ON stock_categories(name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_suppliers_name ON suppliers(name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_service_periods_name ON service_periods(name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_modifier_groups_name ON modifier_groups(name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_combos_name ON combos(name) WHERE deleted_at IS NULL;
+-- Imports upsert sub-categories and variants by name within their parent
+CREATE UNIQUE INDEX idx_menu_sub_categories_name ON menu_sub_categories(category_id, name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_menu_variants_name ON menu_variants(sub_category_id, name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_stock_sub_categories_name ON stock_sub_categories(stock_category_id, name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_stock_variants_name ON stock_variants(stock_sub_category_id, name) WHERE deleted_at IS NULL;
+CREATE INDEX idx_menu_categories_deleted ON menu_categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_menu_sub_categories_deleted ON menu_sub_categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_menu_variants_deleted ON menu_variants(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_stock_categories_deleted ON stock_categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_stock_sub_categories_deleted ON stock_sub_categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_stock_variants_deleted ON stock_variants(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_stock_count_deleted ON stock_count(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_suppliers_deleted ON suppliers(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_outcome_invoices_deleted ON outcome_invoices(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_income_invoices_deleted ON income_invoices(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_service_periods_deleted ON service_periods(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_modifier_groups_deleted ON modifier_groups(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_combos_deleted ON combos(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Retention: the policies look closed rows up by these columns
+CREATE INDEX idx_stock_count_purchased_at ON stock_count(purchased_at) WHERE is_out = true;
+CREATE INDEX idx_order_items_requested_at ON order_items(requested_at);
+CREATE INDEX idx_income_invoices_created_at ON income_invoices(created_at);
+CREATE INDEX idx_outcome_invoices_transaction_date ON outcome_invoices(transaction_date);
+CREATE INDEX idx_invoice_items_invoice ON invoice_items(invoice_id);
+CREATE INDEX idx_request_notifications_handled ON request_notifications(updated_at)
+    WHERE status IN ('completed', 'cancelled');
+
+-- =============================================================================
+-- ARCHIVE SCHEMA
+-- =============================================================================
+
+-- Closed history moved out of the live tables by the data-service retention policies.
+-- Archive tables copy the columns of their live table, keep them in step.
+CREATE SCHEMA archive;
+
+CREATE TABLE archive.stock_count (
+    LIKE public.stock_count,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_archive_stock_count_id ON archive.stock_count(id);
+CREATE INDEX idx_archive_stock_count_purchased_at ON archive.stock_count(purchased_at);
+
+CREATE TABLE archive.order_items (
+    LIKE public.order_items,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_archive_order_items_id ON archive.order_items(id);
+CREATE INDEX idx_archive_order_items_requested_at ON archive.order_items(requested_at);
+CREATE INDEX idx_archive_order_items_order ON archive.order_items(order_id);
+
+CREATE TABLE archive.income_invoices (
+    LIKE public.income_invoices,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_archive_income_invoices_id ON archive.income_invoices(id);
+CREATE INDEX idx_archive_income_invoices_created_at ON archive.income_invoices(created_at);
+
+CREATE TABLE archive.outcome_invoices (
+    LIKE public.outcome_invoices,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_archive_outcome_invoices_id ON archive.outcome_invoices(id);
+CREATE INDEX idx_archive_outcome_invoices_transaction_date ON archive.outcome_invoices(transaction_date);
+
+CREATE TABLE archive.invoice_items (
+    LIKE public.invoice_items,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_archive_invoice_items_id ON archive.invoice_items(id);
+CREATE INDEX idx_archive_invoice_items_invoice ON archive.invoice_items(invoice_id);
+
 -- =============================================================================
 -- TRIGGERS FOR AUTOMATIC UPDATED_AT
 -- =============================================================================
@@ -1214,6 +1506,15 @@ BEGIN
 END;
 $$ language 'plpgsql';
 
+-- Row version trigger function, every update moves the version used by ETags/If-Match
+CREATE OR REPLACE FUNCTION increment_row_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
 -- Apply triggers to all tables with updated_at
 CREATE TRIGGER update_tables_updated_at BEFORE UPDATE ON tables
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -1236,6 +1537,18 @@ CREATE TRIGGER update_menu_variants_updated_at BEFORE UPDATE ON menu_variants
 CREATE TRIGGER update_menu_ingredients_updated_at BEFORE UPDATE ON menu_ingredients
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+CREATE TRIGGER update_service_periods_updated_at BEFORE UPDATE ON service_periods
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_modifier_groups_updated_at BEFORE UPDATE ON modifier_groups
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_modifier_options_updated_at BEFORE UPDATE ON modifier_options
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_combos_updated_at BEFORE UPDATE ON combos
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
 CREATE TRIGGER update_stock_categories_updated_at BEFORE UPDATE ON stock_categories
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
@@ -1296,6 +1609,49 @@ CREATE TRIGGER update_reviews_updated_at BEFORE UPDATE ON reviews
 CREATE TRIGGER update_promotions_updated_at BEFORE UPDATE ON promotions
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+-- Apply row version triggers to all tables with optimistic concurrency control
+CREATE TRIGGER increment_menu_categories_version BEFORE UPDATE ON menu_categories
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_menu_sub_categories_version BEFORE UPDATE ON menu_sub_categories
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_menu_variants_version BEFORE UPDATE ON menu_variants
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_menu_ingredients_version BEFORE UPDATE ON menu_ingredients
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_service_periods_version BEFORE UPDATE ON service_periods
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_modifier_groups_version BEFORE UPDATE ON modifier_groups
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_combos_version BEFORE UPDATE ON combos
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_stock_categories_version BEFORE UPDATE ON stock_categories
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_stock_sub_categories_version BEFORE UPDATE ON stock_sub_categories
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_stock_variants_version BEFORE UPDATE ON stock_variants
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_stock_count_version BEFORE UPDATE ON stock_count
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_suppliers_version BEFORE UPDATE ON suppliers
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_outcome_invoices_version BEFORE UPDATE ON outcome_invoices
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+CREATE TRIGGER increment_income_invoices_version BEFORE UPDATE ON income_invoices
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
 -- Note: invoice_items.invoice_id can reference either income_invoices or outcome_invoices
 -- based on the invoice_type field. Foreign key constraints are handled in application code.
 
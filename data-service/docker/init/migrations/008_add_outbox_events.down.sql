@@ -0,0 +1,4 @@
+-- Migration 008: Rollback Transactional Outbox
+
+DROP TABLE IF EXISTS processed_events;
+DROP TABLE IF EXISTS outbox_events;
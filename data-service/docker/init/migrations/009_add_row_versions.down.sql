@@ -0,0 +1,27 @@
+-- Migration 009: Rollback Row Versions
+
+DROP TRIGGER IF EXISTS increment_menu_categories_version ON menu_categories;
+DROP TRIGGER IF EXISTS increment_menu_sub_categories_version ON menu_sub_categories;
+DROP TRIGGER IF EXISTS increment_menu_variants_version ON menu_variants;
+DROP TRIGGER IF EXISTS increment_menu_ingredients_version ON menu_ingredients;
+DROP TRIGGER IF EXISTS increment_stock_categories_version ON stock_categories;
+DROP TRIGGER IF EXISTS increment_stock_sub_categories_version ON stock_sub_categories;
+DROP TRIGGER IF EXISTS increment_stock_variants_version ON stock_variants;
+DROP TRIGGER IF EXISTS increment_stock_count_version ON stock_count;
+DROP TRIGGER IF EXISTS increment_suppliers_version ON suppliers;
+DROP TRIGGER IF EXISTS increment_outcome_invoices_version ON outcome_invoices;
+DROP TRIGGER IF EXISTS increment_income_invoices_version ON income_invoices;
+
+ALTER TABLE menu_categories DROP COLUMN IF EXISTS version;
+ALTER TABLE menu_sub_categories DROP COLUMN IF EXISTS version;
+ALTER TABLE menu_variants DROP COLUMN IF EXISTS version;
+ALTER TABLE menu_ingredients DROP COLUMN IF EXISTS version;
+ALTER TABLE stock_categories DROP COLUMN IF EXISTS version;
+ALTER TABLE stock_sub_categories DROP COLUMN IF EXISTS version;
+ALTER TABLE stock_variants DROP COLUMN IF EXISTS version;
+ALTER TABLE stock_count DROP COLUMN IF EXISTS version;
+ALTER TABLE suppliers DROP COLUMN IF EXISTS version;
+ALTER TABLE outcome_invoices DROP COLUMN IF EXISTS version;
+ALTER TABLE income_invoices DROP COLUMN IF EXISTS version;
+
+DROP FUNCTION IF EXISTS increment_row_version();
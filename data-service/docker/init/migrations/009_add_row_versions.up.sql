@@ -0,0 +1,66 @@
+-- Migration 009: Row Versions
+-- Purpose: Optimistic concurrency control. Every update increments the row version,
+-- GET responses expose it as the ETag and update scripts only match the version sent in If-Match.
+
+CREATE OR REPLACE FUNCTION increment_row_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+ALTER TABLE menu_categories ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+DROP TRIGGER IF EXISTS increment_menu_categories_version ON menu_categories;
+CREATE TRIGGER increment_menu_categories_version BEFORE UPDATE ON menu_categories
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+ALTER TABLE menu_sub_categories ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+DROP TRIGGER IF EXISTS increment_menu_sub_categories_version ON menu_sub_categories;
+CREATE TRIGGER increment_menu_sub_categories_version BEFORE UPDATE ON menu_sub_categories
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+ALTER TABLE menu_variants ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+DROP TRIGGER IF EXISTS increment_menu_variants_version ON menu_variants;
+CREATE TRIGGER increment_menu_variants_version BEFORE UPDATE ON menu_variants
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+ALTER TABLE menu_ingredients ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+DROP TRIGGER IF EXISTS increment_menu_ingredients_version ON menu_ingredients;
+CREATE TRIGGER increment_menu_ingredients_version BEFORE UPDATE ON menu_ingredients
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+ALTER TABLE stock_categories ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+DROP TRIGGER IF EXISTS increment_stock_categories_version ON stock_categories;
+CREATE TRIGGER increment_stock_categories_version BEFORE UPDATE ON stock_categories
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+ALTER TABLE stock_sub_categories ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+DROP TRIGGER IF EXISTS increment_stock_sub_categories_version ON stock_sub_categories;
+CREATE TRIGGER increment_stock_sub_categories_version BEFORE UPDATE ON stock_sub_categories
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+ALTER TABLE stock_variants ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+DROP TRIGGER IF EXISTS increment_stock_variants_version ON stock_variants;
+CREATE TRIGGER increment_stock_variants_version BEFORE UPDATE ON stock_variants
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+ALTER TABLE stock_count ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+DROP TRIGGER IF EXISTS increment_stock_count_version ON stock_count;
+CREATE TRIGGER increment_stock_count_version BEFORE UPDATE ON stock_count
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+ALTER TABLE suppliers ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+DROP TRIGGER IF EXISTS increment_suppliers_version ON suppliers;
+CREATE TRIGGER increment_suppliers_version BEFORE UPDATE ON suppliers
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+ALTER TABLE outcome_invoices ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+DROP TRIGGER IF EXISTS increment_outcome_invoices_version ON outcome_invoices;
+CREATE TRIGGER increment_outcome_invoices_version BEFORE UPDATE ON outcome_invoices
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
+
+ALTER TABLE income_invoices ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+DROP TRIGGER IF EXISTS increment_income_invoices_version ON income_invoices;
+CREATE TRIGGER increment_income_invoices_version BEFORE UPDATE ON income_invoices
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
@@ -0,0 +1,3 @@
+-- Migration 010: Rollback Audit Log
+
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,59 @@
+-- Migration 011: Rollback Soft Delete
+-- Soft deleted rows are removed, the schema cannot represent them any more
+
+DROP INDEX IF EXISTS idx_menu_categories_deleted;
+DROP INDEX IF EXISTS idx_menu_sub_categories_deleted;
+DROP INDEX IF EXISTS idx_menu_variants_deleted;
+DROP INDEX IF EXISTS idx_stock_categories_deleted;
+DROP INDEX IF EXISTS idx_stock_sub_categories_deleted;
+DROP INDEX IF EXISTS idx_stock_variants_deleted;
+DROP INDEX IF EXISTS idx_stock_count_deleted;
+DROP INDEX IF EXISTS idx_suppliers_deleted;
+DROP INDEX IF EXISTS idx_outcome_invoices_deleted;
+DROP INDEX IF EXISTS idx_income_invoices_deleted;
+
+DELETE FROM audit_log WHERE action = 'restore';
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_action_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_action_check CHECK (action IN ('create', 'update', 'delete'));
+
+ALTER TABLE stock_sub_categories DROP CONSTRAINT IF EXISTS stock_sub_categories_stock_category_id_fkey;
+ALTER TABLE stock_sub_categories ADD CONSTRAINT stock_sub_categories_stock_category_id_fkey
+    FOREIGN KEY (stock_category_id) REFERENCES stock_categories(id) ON DELETE CASCADE;
+ALTER TABLE stock_variants DROP CONSTRAINT IF EXISTS stock_variants_stock_sub_category_id_fkey;
+ALTER TABLE stock_variants ADD CONSTRAINT stock_variants_stock_sub_category_id_fkey
+    FOREIGN KEY (stock_sub_category_id) REFERENCES stock_sub_categories(id) ON DELETE CASCADE;
+ALTER TABLE stock_count DROP CONSTRAINT IF EXISTS stock_count_stock_variant_id_fkey;
+ALTER TABLE stock_count ADD CONSTRAINT stock_count_stock_variant_id_fkey
+    FOREIGN KEY (stock_variant_id) REFERENCES stock_variants(id) ON DELETE CASCADE;
+ALTER TABLE stock_count DROP CONSTRAINT IF EXISTS stock_count_invoice_id_fkey;
+ALTER TABLE stock_count ADD CONSTRAINT stock_count_invoice_id_fkey
+    FOREIGN KEY (invoice_id) REFERENCES outcome_invoices(id) ON DELETE CASCADE;
+
+DELETE FROM stock_count WHERE deleted_at IS NOT NULL;
+DELETE FROM menu_variants WHERE deleted_at IS NOT NULL;
+DELETE FROM menu_sub_categories WHERE deleted_at IS NOT NULL;
+DELETE FROM menu_categories WHERE deleted_at IS NOT NULL;
+DELETE FROM stock_variants WHERE deleted_at IS NOT NULL;
+DELETE FROM stock_sub_categories WHERE deleted_at IS NOT NULL;
+DELETE FROM stock_categories WHERE deleted_at IS NOT NULL;
+DELETE FROM outcome_invoices WHERE deleted_at IS NOT NULL;
+DELETE FROM income_invoices WHERE deleted_at IS NOT NULL;
+DELETE FROM suppliers WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_menu_categories_name;
+DROP INDEX IF EXISTS idx_stock_categories_name;
+DROP INDEX IF EXISTS idx_suppliers_name;
+ALTER TABLE menu_categories ADD CONSTRAINT menu_categories_name_key UNIQUE (name);
+ALTER TABLE stock_categories ADD CONSTRAINT stock_categories_name_key UNIQUE (name);
+ALTER TABLE suppliers ADD CONSTRAINT suppliers_name_key UNIQUE (name);
+
+ALTER TABLE menu_categories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE menu_sub_categories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE menu_variants DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE stock_categories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE stock_sub_categories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE stock_variants DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE stock_count DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE suppliers DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE outcome_invoices DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE income_invoices DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,52 @@
+-- Migration 011: Soft Delete
+-- Purpose: Deleting catalog and financial rows only marks them with deleted_at, lists hide
+-- them by default and a purge job removes them after the retention window. Deleting a parent
+-- no longer cascades into stock and purchase history.
+
+ALTER TABLE menu_categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE menu_sub_categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE menu_variants ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE stock_categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE stock_sub_categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE stock_variants ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE stock_count ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE suppliers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE outcome_invoices ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE income_invoices ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Names only need to be unique among live rows, so a deleted name can be reused
+ALTER TABLE menu_categories DROP CONSTRAINT IF EXISTS menu_categories_name_key;
+ALTER TABLE stock_categories DROP CONSTRAINT IF EXISTS stock_categories_name_key;
+ALTER TABLE suppliers DROP CONSTRAINT IF EXISTS suppliers_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_menu_categories_name ON menu_categories(name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_categories_name ON stock_categories(name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_suppliers_name ON suppliers(name) WHERE deleted_at IS NULL;
+
+-- Hard deletes only happen in the purge job, which never removes referenced rows
+ALTER TABLE stock_sub_categories DROP CONSTRAINT IF EXISTS stock_sub_categories_stock_category_id_fkey;
+ALTER TABLE stock_sub_categories ADD CONSTRAINT stock_sub_categories_stock_category_id_fkey
+    FOREIGN KEY (stock_category_id) REFERENCES stock_categories(id) ON DELETE RESTRICT;
+ALTER TABLE stock_variants DROP CONSTRAINT IF EXISTS stock_variants_stock_sub_category_id_fkey;
+ALTER TABLE stock_variants ADD CONSTRAINT stock_variants_stock_sub_category_id_fkey
+    FOREIGN KEY (stock_sub_category_id) REFERENCES stock_sub_categories(id) ON DELETE RESTRICT;
+ALTER TABLE stock_count DROP CONSTRAINT IF EXISTS stock_count_stock_variant_id_fkey;
+ALTER TABLE stock_count ADD CONSTRAINT stock_count_stock_variant_id_fkey
+    FOREIGN KEY (stock_variant_id) REFERENCES stock_variants(id) ON DELETE RESTRICT;
+ALTER TABLE stock_count DROP CONSTRAINT IF EXISTS stock_count_invoice_id_fkey;
+ALTER TABLE stock_count ADD CONSTRAINT stock_count_invoice_id_fkey
+    FOREIGN KEY (invoice_id) REFERENCES outcome_invoices(id) ON DELETE RESTRICT;
+
+-- Restores are audited like any other change
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_action_check;
+ALTER TABLE audit_log ADD CONSTRAINT audit_log_action_check CHECK (action IN ('create', 'update', 'delete', 'restore'));
+
+CREATE INDEX IF NOT EXISTS idx_menu_categories_deleted ON menu_categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_menu_sub_categories_deleted ON menu_sub_categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_menu_variants_deleted ON menu_variants(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_categories_deleted ON stock_categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_sub_categories_deleted ON stock_sub_categories(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_variants_deleted ON stock_variants(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_count_deleted ON stock_count(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_suppliers_deleted ON suppliers(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_outcome_invoices_deleted ON outcome_invoices(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_income_invoices_deleted ON income_invoices(deleted_at) WHERE deleted_at IS NOT NULL;
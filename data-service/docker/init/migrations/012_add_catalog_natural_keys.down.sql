@@ -0,0 +1,6 @@
+-- Migration 012: Rollback Catalog Natural Keys
+
+DROP INDEX IF EXISTS idx_menu_sub_categories_name;
+DROP INDEX IF EXISTS idx_menu_variants_name;
+DROP INDEX IF EXISTS idx_stock_sub_categories_name;
+DROP INDEX IF EXISTS idx_stock_variants_name;
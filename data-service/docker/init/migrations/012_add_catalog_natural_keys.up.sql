@@ -0,0 +1,38 @@
+-- Migration 012: Catalog Natural Keys
+-- Purpose: Bulk imports upsert catalog rows by name within their parent, so those names
+-- must be unique among live rows. Existing duplicates (the seed data listed 'Naranja'
+-- twice under 'Frutas') are renamed with a numeric suffix instead of being removed, since
+-- stock counts and recipes may reference any of them.
+
+UPDATE stock_variants sv SET name = d.name || ' (' || d.rn || ')'
+FROM (
+    SELECT id, name, ROW_NUMBER() OVER (PARTITION BY stock_sub_category_id, name ORDER BY created_at, id) AS rn
+    FROM stock_variants WHERE deleted_at IS NULL
+) d
+WHERE sv.id = d.id AND d.rn > 1;
+
+UPDATE stock_sub_categories ssc SET name = d.name || ' (' || d.rn || ')'
+FROM (
+    SELECT id, name, ROW_NUMBER() OVER (PARTITION BY stock_category_id, name ORDER BY created_at, id) AS rn
+    FROM stock_sub_categories WHERE deleted_at IS NULL
+) d
+WHERE ssc.id = d.id AND d.rn > 1;
+
+UPDATE menu_variants mv SET name = d.name || ' (' || d.rn || ')'
+FROM (
+    SELECT id, name, ROW_NUMBER() OVER (PARTITION BY sub_category_id, name ORDER BY created_at, id) AS rn
+    FROM menu_variants WHERE deleted_at IS NULL
+) d
+WHERE mv.id = d.id AND d.rn > 1;
+
+UPDATE menu_sub_categories msc SET name = d.name || ' (' || d.rn || ')'
+FROM (
+    SELECT id, name, ROW_NUMBER() OVER (PARTITION BY category_id, name ORDER BY created_at, id) AS rn
+    FROM menu_sub_categories WHERE deleted_at IS NULL
+) d
+WHERE msc.id = d.id AND d.rn > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_menu_sub_categories_name ON menu_sub_categories(category_id, name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_menu_variants_name ON menu_variants(sub_category_id, name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_sub_categories_name ON stock_sub_categories(stock_category_id, name) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_variants_name ON stock_variants(stock_sub_category_id, name) WHERE deleted_at IS NULL;
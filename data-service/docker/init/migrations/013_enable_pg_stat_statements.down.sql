@@ -0,0 +1,3 @@
+-- Migration 013: Rollback Query Statistics
+
+DROP EXTENSION IF EXISTS pg_stat_statements;
@@ -0,0 +1,5 @@
+-- Migration 013: Query Statistics
+-- Purpose: The data-service admin API reports slow queries from pg_stat_statements. The
+-- server already preloads the library (docker-compose), the extension exposes its view.
+
+CREATE EXTENSION IF NOT EXISTS pg_stat_statements;
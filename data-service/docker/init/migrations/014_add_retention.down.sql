@@ -0,0 +1,31 @@
+-- Migration 014: Rollback Data Retention
+-- Archived rows are moved back into their live tables before the archive schema is dropped,
+-- parents before the rows that reference them
+
+DO $$
+DECLARE
+    t TEXT;
+    cols TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['outcome_invoices', 'invoice_items', 'stock_count', 'income_invoices', 'order_items'] LOOP
+        SELECT string_agg(quote_ident(column_name), ', ' ORDER BY ordinal_position) INTO cols
+        FROM information_schema.columns
+        WHERE table_schema = 'public' AND table_name = t AND is_generated = 'NEVER';
+
+        EXECUTE format('INSERT INTO public.%I (%s) SELECT %s FROM archive.%I ON CONFLICT (id) DO NOTHING', t, cols, cols, t);
+    END LOOP;
+END $$;
+
+DROP SCHEMA IF EXISTS archive CASCADE;
+
+DROP INDEX IF EXISTS idx_sessions_created_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS created_at;
+
+DROP INDEX IF EXISTS idx_stock_count_purchased_at;
+DROP INDEX IF EXISTS idx_order_items_requested_at;
+DROP INDEX IF EXISTS idx_income_invoices_created_at;
+DROP INDEX IF EXISTS idx_outcome_invoices_transaction_date;
+DROP INDEX IF EXISTS idx_invoice_items_invoice;
+DROP INDEX IF EXISTS idx_request_notifications_handled;
+
+DELETE FROM settings WHERE service = 'Data' AND key LIKE 'RETENTION\_%\_DAYS';
@@ -0,0 +1,67 @@
+-- Migration 014: Data Retention
+-- Purpose: Closed history (stock that ran out, items of settled orders, old invoices) is moved
+-- out of the live tables into the archive schema, where it stays queryable for reports.
+-- Handled notifications and old sessions are deleted. How many days each table keeps is a
+-- Data setting, 0 disables its policy. Archive tables copy the columns of their live table,
+-- so a migration adding a column to a live table must add it to its archive table too.
+
+CREATE SCHEMA IF NOT EXISTS archive;
+
+CREATE TABLE IF NOT EXISTS archive.stock_count (
+    LIKE public.stock_count,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_archive_stock_count_id ON archive.stock_count(id);
+CREATE INDEX IF NOT EXISTS idx_archive_stock_count_purchased_at ON archive.stock_count(purchased_at);
+
+CREATE TABLE IF NOT EXISTS archive.order_items (
+    LIKE public.order_items,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_archive_order_items_id ON archive.order_items(id);
+CREATE INDEX IF NOT EXISTS idx_archive_order_items_requested_at ON archive.order_items(requested_at);
+CREATE INDEX IF NOT EXISTS idx_archive_order_items_order ON archive.order_items(order_id);
+
+CREATE TABLE IF NOT EXISTS archive.income_invoices (
+    LIKE public.income_invoices,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_archive_income_invoices_id ON archive.income_invoices(id);
+CREATE INDEX IF NOT EXISTS idx_archive_income_invoices_created_at ON archive.income_invoices(created_at);
+
+CREATE TABLE IF NOT EXISTS archive.outcome_invoices (
+    LIKE public.outcome_invoices,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_archive_outcome_invoices_id ON archive.outcome_invoices(id);
+CREATE INDEX IF NOT EXISTS idx_archive_outcome_invoices_transaction_date ON archive.outcome_invoices(transaction_date);
+
+-- Items move with their outcome invoice; the generated total is copied as a plain value
+CREATE TABLE IF NOT EXISTS archive.invoice_items (
+    LIKE public.invoice_items,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_archive_invoice_items_id ON archive.invoice_items(id);
+CREATE INDEX IF NOT EXISTS idx_archive_invoice_items_invoice ON archive.invoice_items(invoice_id);
+
+-- Sessions had no timestamp to expire them by; existing ones count from now
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_sessions_created_at ON sessions(created_at);
+
+-- The retention policies look closed rows up by these columns
+CREATE INDEX IF NOT EXISTS idx_stock_count_purchased_at ON stock_count(purchased_at) WHERE is_out = true;
+CREATE INDEX IF NOT EXISTS idx_order_items_requested_at ON order_items(requested_at);
+CREATE INDEX IF NOT EXISTS idx_income_invoices_created_at ON income_invoices(created_at);
+CREATE INDEX IF NOT EXISTS idx_outcome_invoices_transaction_date ON outcome_invoices(transaction_date);
+CREATE INDEX IF NOT EXISTS idx_invoice_items_invoice ON invoice_items(invoice_id);
+CREATE INDEX IF NOT EXISTS idx_request_notifications_handled ON request_notifications(updated_at)
+    WHERE status IN ('completed', 'cancelled');
+
+INSERT INTO settings (service, key, value, description) VALUES
+    ('Data', 'RETENTION_STOCK_COUNT_DAYS', '365', 'Days stock counts marked out stay live before they are archived'),
+    ('Data', 'RETENTION_ORDER_ITEMS_DAYS', '730', 'Days items of paid or cancelled orders stay live before they are archived'),
+    ('Data', 'RETENTION_INCOME_INVOICES_DAYS', '1825', 'Days issued sales invoices stay live before they are archived, the legal retention period'),
+    ('Data', 'RETENTION_OUTCOME_INVOICES_DAYS', '1825', 'Days purchase invoices and their items stay live before they are archived, the legal retention period'),
+    ('Data', 'RETENTION_REQUEST_NOTIFICATIONS_DAYS', '30', 'Days completed or cancelled notifications are kept before they are deleted'),
+    ('Data', 'RETENTION_SESSIONS_DAYS', '30', 'Days sessions are kept before they are deleted')
+ON CONFLICT (service, key) DO NOTHING;
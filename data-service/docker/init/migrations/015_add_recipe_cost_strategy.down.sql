@@ -0,0 +1,6 @@
+-- Migration 015: Rollback Recipe Cost Strategy
+
+DROP INDEX IF EXISTS idx_menu_ingredients_default_variant;
+ALTER TABLE menu_ingredients DROP CONSTRAINT IF EXISTS chk_ingredient_cost_strategy;
+ALTER TABLE menu_ingredients DROP COLUMN IF EXISTS default_variant_id;
+ALTER TABLE menu_ingredients DROP COLUMN IF EXISTS cost_strategy;
@@ -0,0 +1,16 @@
+-- Migration 015: Recipe Cost Strategy
+-- Purpose: An ingredient can be "any" variant of a menu sub-category (any side dish). Its cost
+-- is the average or the most expensive of the sub-category's variants, or with the 'default'
+-- strategy the cost of default_variant_id. cost_strategy overrides the RECIPE_COST_STRATEGY
+-- setting of menu-service for one ingredient; stock ingredients have neither column.
+
+ALTER TABLE menu_ingredients ADD COLUMN IF NOT EXISTS cost_strategy VARCHAR(10);
+ALTER TABLE menu_ingredients ADD COLUMN IF NOT EXISTS default_variant_id UUID REFERENCES menu_variants(id) ON DELETE SET NULL;
+
+ALTER TABLE menu_ingredients DROP CONSTRAINT IF EXISTS chk_ingredient_cost_strategy;
+ALTER TABLE menu_ingredients ADD CONSTRAINT chk_ingredient_cost_strategy CHECK (
+    (cost_strategy IS NULL OR cost_strategy IN ('average', 'max', 'default')) AND
+    (menu_sub_category_id IS NOT NULL OR (cost_strategy IS NULL AND default_variant_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_menu_ingredients_default_variant ON menu_ingredients(default_variant_id);
@@ -0,0 +1,4 @@
+-- Migration 016: Rollback Menu Target Margins
+
+ALTER TABLE menu_sub_categories DROP COLUMN IF EXISTS target_margin;
+ALTER TABLE menu_categories DROP COLUMN IF EXISTS target_margin;
@@ -0,0 +1,9 @@
+-- Migration 016: Menu Target Margins
+-- Purpose: The earning margin menu prices are suggested at defaults to the DEFAULT_EARNING_MARGIN
+-- setting of menu-service. A category can set its own, and a sub-category can override its
+-- category's; NULL inherits. Margins are a percentage of the price, below 100.
+
+ALTER TABLE menu_categories ADD COLUMN IF NOT EXISTS target_margin DECIMAL(5,2)
+    CHECK (target_margin >= 0 AND target_margin < 100);
+ALTER TABLE menu_sub_categories ADD COLUMN IF NOT EXISTS target_margin DECIMAL(5,2)
+    CHECK (target_margin >= 0 AND target_margin < 100);
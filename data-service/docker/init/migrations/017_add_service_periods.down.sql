@@ -0,0 +1,3 @@
+-- Migration 017: Rollback Service Periods
+
+DROP TABLE IF EXISTS service_periods;
@@ -0,0 +1,26 @@
+-- Migration 017: Service Periods
+-- Purpose: Define when each menu type of menu_variants.menu_types is served, e.g. "lunch"
+-- on weekdays from 11:00 to 15:00, so the menu can list what is orderable now and orders
+-- can be checked against the active periods. windows holds the day and time windows on
+-- the venue's wall clock.
+
+CREATE TABLE IF NOT EXISTS service_periods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) NOT NULL,
+    description TEXT,
+    windows JSONB NOT NULL CHECK (jsonb_typeof(windows) = 'array' AND jsonb_array_length(windows) > 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_service_periods_name ON service_periods(name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_service_periods_deleted ON service_periods(deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TRIGGER update_service_periods_updated_at BEFORE UPDATE ON service_periods
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER increment_service_periods_version BEFORE UPDATE ON service_periods
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
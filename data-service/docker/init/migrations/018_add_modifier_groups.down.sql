@@ -0,0 +1,5 @@
+-- Migration 018: Rollback Modifier Groups
+
+DROP TABLE IF EXISTS modifier_group_links;
+DROP TABLE IF EXISTS modifier_options;
+DROP TABLE IF EXISTS modifier_groups;
@@ -0,0 +1,63 @@
+-- Migration 018: Modifier Groups
+-- Purpose: Options a guest picks when ordering a menu variant, e.g. "choose your mixer",
+-- "extra shot +500" or "no onions". A group sets how many of its options must and may be
+-- picked and is attached to menu variants or to every variant of a sub-category. An option
+-- adjusts the price by price_delta and may consume quantity of a stock variant.
+
+CREATE TABLE IF NOT EXISTS modifier_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    min_selections INTEGER NOT NULL DEFAULT 0 CHECK (min_selections >= 0),
+    -- NULL allows picking every option
+    max_selections INTEGER CHECK (max_selections >= 1),
+    display_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP,
+    CONSTRAINT chk_modifier_group_selections CHECK (max_selections IS NULL OR max_selections >= min_selections)
+);
+
+CREATE TABLE IF NOT EXISTS modifier_options (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    modifier_group_id UUID NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(10,2) NOT NULL DEFAULT 0,
+    stock_variant_id UUID REFERENCES stock_variants(id) ON DELETE RESTRICT,
+    quantity DECIMAL(10,2) CHECK (quantity > 0),
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- A stock variant is consumed in a quantity, and only then
+    CONSTRAINT chk_modifier_option_stock CHECK ((stock_variant_id IS NULL) = (quantity IS NULL))
+);
+
+-- A group is attached to either a menu variant or a menu sub-category
+CREATE TABLE IF NOT EXISTS modifier_group_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    modifier_group_id UUID NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    menu_variant_id UUID REFERENCES menu_variants(id) ON DELETE CASCADE,
+    menu_sub_category_id UUID REFERENCES menu_sub_categories(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_modifier_group_link_target CHECK ((menu_variant_id IS NULL) <> (menu_sub_category_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_modifier_groups_name ON modifier_groups(name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_modifier_groups_deleted ON modifier_groups(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_modifier_options_name ON modifier_options(modifier_group_id, name);
+CREATE INDEX IF NOT EXISTS idx_modifier_options_stock_variant ON modifier_options(stock_variant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_modifier_group_links_variant ON modifier_group_links(modifier_group_id, menu_variant_id) WHERE menu_variant_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_modifier_group_links_sub_category ON modifier_group_links(modifier_group_id, menu_sub_category_id) WHERE menu_sub_category_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_modifier_group_links_menu_variant ON modifier_group_links(menu_variant_id);
+CREATE INDEX IF NOT EXISTS idx_modifier_group_links_menu_sub_category ON modifier_group_links(menu_sub_category_id);
+
+CREATE TRIGGER update_modifier_groups_updated_at BEFORE UPDATE ON modifier_groups
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_modifier_options_updated_at BEFORE UPDATE ON modifier_options
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER increment_modifier_groups_version BEFORE UPDATE ON modifier_groups
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
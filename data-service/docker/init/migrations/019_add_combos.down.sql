@@ -0,0 +1,5 @@
+-- Migration 019: Rollback Combos
+
+DROP TABLE IF EXISTS combo_slot_options;
+DROP TABLE IF EXISTS combo_slots;
+DROP TABLE IF EXISTS combos;
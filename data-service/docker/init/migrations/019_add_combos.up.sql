@@ -0,0 +1,50 @@
+-- Migration 019: Combos
+-- Purpose: Bundles of menu variants sold at one price, e.g. "burger + fries + soda". A
+-- combo has slots, each filled by one of its options: a slot with a single option is
+-- fixed, one with several is choose-one. Cost and availability are not stored, they roll
+-- up from the component variants when a combo is read.
+
+CREATE TABLE IF NOT EXISTS combos (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    price DECIMAL(10,2) NOT NULL CHECK (price >= 0),
+    image_url VARCHAR(500),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS combo_slots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    combo_id UUID NOT NULL REFERENCES combos(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    is_required BOOLEAN NOT NULL DEFAULT true,
+    display_order INTEGER NOT NULL DEFAULT 0
+);
+
+-- price_delta is charged on top of the combo price for upgrades, e.g. large fries
+CREATE TABLE IF NOT EXISTS combo_slot_options (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    combo_slot_id UUID NOT NULL REFERENCES combo_slots(id) ON DELETE CASCADE,
+    menu_variant_id UUID NOT NULL REFERENCES menu_variants(id) ON DELETE RESTRICT,
+    price_delta DECIMAL(10,2) NOT NULL DEFAULT 0,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    display_order INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_combos_name ON combos(name) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_combos_deleted ON combos(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_combo_slots_combo ON combo_slots(combo_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_combo_slot_options_variant ON combo_slot_options(combo_slot_id, menu_variant_id);
+CREATE INDEX IF NOT EXISTS idx_combo_slot_options_menu_variant ON combo_slot_options(menu_variant_id);
+
+CREATE TRIGGER update_combos_updated_at BEFORE UPDATE ON combos
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER increment_combos_version BEFORE UPDATE ON combos
+    FOR EACH ROW EXECUTE FUNCTION increment_row_version();
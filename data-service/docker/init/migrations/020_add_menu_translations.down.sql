@@ -0,0 +1,5 @@
+-- Migration 020: Rollback Menu Translations
+
+ALTER TABLE menu_variants DROP COLUMN IF EXISTS translations;
+ALTER TABLE menu_sub_categories DROP COLUMN IF EXISTS translations;
+ALTER TABLE menu_categories DROP COLUMN IF EXISTS translations;
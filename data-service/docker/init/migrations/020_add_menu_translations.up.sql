@@ -0,0 +1,11 @@
+-- Migration 020: Menu Translations
+-- Purpose: Names and descriptions are written in the venue's DEFAULT_LANGUAGE. Categories,
+-- sub-categories and variants can carry them in other languages for the public menu, keyed
+-- by language tag: {"en": {"name": "Banana smoothie", "description": "..."}}.
+
+ALTER TABLE menu_categories ADD COLUMN IF NOT EXISTS translations JSONB NOT NULL DEFAULT '{}'
+    CHECK (jsonb_typeof(translations) = 'object');
+ALTER TABLE menu_sub_categories ADD COLUMN IF NOT EXISTS translations JSONB NOT NULL DEFAULT '{}'
+    CHECK (jsonb_typeof(translations) = 'object');
+ALTER TABLE menu_variants ADD COLUMN IF NOT EXISTS translations JSONB NOT NULL DEFAULT '{}'
+    CHECK (jsonb_typeof(translations) = 'object');
@@ -0,0 +1,3 @@
+-- Migration 021: Rollback Event Failures
+
+DROP TABLE IF EXISTS event_failures;
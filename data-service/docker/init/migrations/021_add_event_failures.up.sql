@@ -0,0 +1,15 @@
+-- Migration 021: Event Failures
+-- Purpose: Count failed deliveries per consumer so an event that keeps failing is
+-- dead-lettered instead of being retried forever and starving newer events.
+
+CREATE TABLE IF NOT EXISTS event_failures (
+    consumer VARCHAR(50) NOT NULL,
+    event_id UUID NOT NULL REFERENCES outbox_events(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    failed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dead_lettered_at TIMESTAMP,
+    PRIMARY KEY (consumer, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_failures_dead_lettered ON event_failures(consumer, dead_lettered_at) WHERE dead_lettered_at IS NOT NULL;
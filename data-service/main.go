@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"data-service/pkg/backups"
 	"data-service/pkg/handlers"
+	"data-service/pkg/retention"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,8 +12,10 @@ import (
 	"syscall"
 	"time"
 
+	"shared/audit"
 	sharedConfig "shared/config"
 	sharedDb "shared/db"
+	sharedHttp "shared/http"
 	sharedLogger "shared/logger"
 
 	"github.com/gorilla/mux"
@@ -20,6 +24,11 @@ import (
 func main() {
 	logger := sharedLogger.SetupLogger(sharedLogger.SERVICE_DATA_SERVICE, "INFO")
 
+	serviceConfig, err := sharedConfig.NewConfigLoader(sharedConfig.DATA_SERVICE_URL).LoadConfig("data", logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to load configuration")
+	}
+
 	config := sharedDb.DefaultConfig(logger)
 
 	// Create database handler
@@ -29,8 +38,42 @@ func main() {
 	}
 	defer db.Close()
 
+	// Create cancellable context for health monitor
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Create health monitor for readiness, the database is the only dependency
+	healthMonitor, err := sharedHttp.NewHealthMonitor(logger, sharedDb.DBHealthCheckInterval)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create health monitor")
+	}
+	healthMonitor.AddCheck("database", sharedHttp.Critical, func(ctx context.Context) error {
+		return db.Ping()
+	})
+	healthMonitor.Start(ctx)
+
+	auditLog, err := audit.NewLog(db, "data-service", logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create audit log")
+	}
+
+	// Create the backup manager and schedule backups
+	backupManager, err := backups.NewManager(db, config, auditLog, serviceConfig.GetString("BACKUP_DIR"),
+		serviceConfig.GetDuration("BACKUP_INTERVAL"), serviceConfig.GetInt("BACKUP_RETENTION_DAYS"), logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create backup manager")
+	}
+	go backupManager.Start(ctx)
+
+	// Create the retention manager and schedule the archiving of closed history
+	retentionManager, err := retention.NewManager(db, auditLog, serviceConfig.GetDuration("RETENTION_INTERVAL"), logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create retention manager")
+	}
+	go retentionManager.Start(ctx)
+
 	// Setup HTTP handler and router
-	httpHandler, err := handlers.NewHTTPHandler(db, config, logger)
+	httpHandler, err := handlers.NewHTTPHandler(db, config, healthMonitor, auditLog, backupManager, retentionManager, logger)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to create HTTP handler")
 	}
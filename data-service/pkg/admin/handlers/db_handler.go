@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"data-service/pkg/admin/models"
+	adminSQL "data-service/pkg/admin/sql"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	sharedErrors "shared/errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DBHandler reads the statistics views of the shared Postgres server
+type DBHandler struct {
+	db      *sharedDb.DbHandler
+	queries *queries.Registry
+	logger  *logrus.Logger
+}
+
+// NewDBHandler creates a new database handler
+func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, error) {
+	queries, err := adminSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DBHandler{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}, nil
+}
+
+// Activity returns the sessions connected to the database, from pg_stat_activity
+func (h *DBHandler) Activity(ctx context.Context) ([]models.Session, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(adminSQL.ActivityQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read database activity: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var s models.Session
+		err := rows.Scan(&s.PID, &s.ApplicationName, &s.Username, &s.ClientAddr, &s.State, &s.WaitEventType,
+			&s.WaitEvent, &s.BackendStart, &s.XactStart, &s.QueryStart, &s.RunningMs, &s.Query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// Connections returns the connection usage of the server and of each application
+func (h *DBHandler) Connections(ctx context.Context) (*models.ConnectionsResponse, error) {
+	response := &models.ConnectionsResponse{
+		Applications: []models.ApplicationConnections{},
+		Pool:         h.db.GetStats(),
+	}
+
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(adminSQL.ConnectionLimitsQuery), nil).
+		Scan(&response.MaxConnections, &response.ReservedConnections, &response.UsedConnections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read connection limits: %w", err)
+	}
+
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(adminSQL.ConnectionsByApplicationQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count connections: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.ApplicationConnections
+		if err := rows.Scan(&c.ApplicationName, &c.Total, &c.Active, &c.Idle, &c.IdleInTransaction, &c.WaitingOnLocks); err != nil {
+			return nil, fmt.Errorf("failed to scan connection count: %w", err)
+		}
+		response.Applications = append(response.Applications, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating connection counts: %w", err)
+	}
+
+	return response, nil
+}
+
+// SlowQueries returns up to limit statements from pg_stat_statements whose mean time
+// is at least minMeanMs, slowest first
+func (h *DBHandler) SlowQueries(ctx context.Context, limit int, minMeanMs float64) ([]models.SlowQuery, error) {
+	var installed bool
+	if err := h.db.QueryRowNamedContext(ctx, h.queries.Get(adminSQL.HasPgStatStatementsQuery), nil).Scan(&installed); err != nil {
+		return nil, fmt.Errorf("failed to look up pg_stat_statements: %w", err)
+	}
+	if !installed {
+		return nil, sharedErrors.Unavailable("pg_stat_statements_unavailable",
+			"the pg_stat_statements extension is not installed, apply the migrations and restart Postgres with shared_preload_libraries=pg_stat_statements")
+	}
+
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(adminSQL.SlowQueriesQuery), queries.Args{
+		"limit":       limit,
+		"min_mean_ms": minMeanMs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read slow queries: %w", err)
+	}
+	defer rows.Close()
+
+	slowQueries := []models.SlowQuery{}
+	for rows.Next() {
+		var q models.SlowQuery
+		err := rows.Scan(&q.QueryID, &q.Query, &q.Username, &q.Calls, &q.Rows, &q.TotalMs, &q.MeanMs,
+			&q.MaxMs, &q.StddevMs, &q.CacheHitRatio)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan slow query: %w", err)
+		}
+		slowQueries = append(slowQueries, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating slow queries: %w", err)
+	}
+
+	return slowQueries, nil
+}
+
+// TableSizes returns the size and activity of every table, largest first
+func (h *DBHandler) TableSizes(ctx context.Context) ([]models.TableSize, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(adminSQL.TableSizesQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table sizes: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []models.TableSize{}
+	for rows.Next() {
+		var t models.TableSize
+		err := rows.Scan(&t.TableName, &t.TotalBytes, &t.TableBytes, &t.IndexBytes, &t.ToastBytes, &t.LiveRows,
+			&t.DeadRows, &t.SeqScans, &t.IndexScans, &t.LastVacuum, &t.LastAnalyze)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan table size: %w", err)
+		}
+		tables = append(tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table sizes: %w", err)
+	}
+
+	return tables, nil
+}
+
+// IndexSizes returns the size and use of every index, largest first
+func (h *DBHandler) IndexSizes(ctx context.Context) ([]models.IndexSize, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(adminSQL.IndexSizesQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index sizes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := []models.IndexSize{}
+	for rows.Next() {
+		var i models.IndexSize
+		if err := rows.Scan(&i.TableName, &i.IndexName, &i.IndexBytes, &i.Scans, &i.TuplesRead, &i.IsUnique, &i.IsPrimary); err != nil {
+			return nil, fmt.Errorf("failed to scan index size: %w", err)
+		}
+		indexes = append(indexes, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating index sizes: %w", err)
+	}
+
+	return indexes, nil
+}
+
+// Bloat returns the estimated bloat of every table, most wasted space first
+func (h *DBHandler) Bloat(ctx context.Context) ([]models.TableBloat, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(adminSQL.TableBloatQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate table bloat: %w", err)
+	}
+	defer rows.Close()
+
+	tables := []models.TableBloat{}
+	for rows.Next() {
+		var b models.TableBloat
+		err := rows.Scan(&b.TableName, &b.TableBytes, &b.LiveRows, &b.DeadRows, &b.DeadRatio,
+			&b.EstimatedBloatBytes, &b.LastVacuum)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan table bloat: %w", err)
+		}
+		tables = append(tables, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table bloat: %w", err)
+	}
+
+	return tables, nil
+}
+
+// LockWaits returns the sessions waiting on locks and the sessions blocking them
+func (h *DBHandler) LockWaits(ctx context.Context) ([]models.LockWait, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(adminSQL.LockWaitsQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock waits: %w", err)
+	}
+	defer rows.Close()
+
+	waits := []models.LockWait{}
+	for rows.Next() {
+		var l models.LockWait
+		err := rows.Scan(&l.BlockedPID, &l.BlockedApplication, &l.BlockedQuery, &l.WaitingMs, &l.LockType,
+			&l.LockMode, &l.Relation, &l.BlockingPID, &l.BlockingApplication, &l.BlockingState, &l.BlockingQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan lock wait: %w", err)
+		}
+		waits = append(waits, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating lock waits: %w", err)
+	}
+
+	return waits, nil
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	sharedErrors "shared/errors"
+	sharedHttp "shared/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultSlowQueryLimit is how many statements /slow-queries returns by default
+	DefaultSlowQueryLimit = 20
+	// MaxSlowQueryLimit is the most statements /slow-queries returns
+	MaxSlowQueryLimit = 100
+)
+
+// HTTPHandler serves the database statistics of the admin API
+type HTTPHandler struct {
+	dbHandler *DBHandler
+	logger    *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{dbHandler: dbHandler, logger: logger}
+}
+
+// Activity handles GET /api/v1/data/admin/activity
+func (h *HTTPHandler) Activity(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.dbHandler.Activity(r.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read database activity")
+		sharedHttp.SendError(w, r, err, "Failed to read database activity")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Database activity retrieved", sessions)
+}
+
+// Connections handles GET /api/v1/data/admin/connections
+func (h *HTTPHandler) Connections(w http.ResponseWriter, r *http.Request) {
+	connections, err := h.dbHandler.Connections(r.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read connections")
+		sharedHttp.SendError(w, r, err, "Failed to read connections")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Connections retrieved", connections)
+}
+
+// SlowQueries handles GET /api/v1/data/admin/slow-queries?limit=20&min_mean_ms=100
+func (h *HTTPHandler) SlowQueries(w http.ResponseWriter, r *http.Request) {
+	var fieldErrors []sharedErrors.FieldError
+
+	limit := DefaultSlowQueryLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 || value > MaxSlowQueryLimit {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "limit", Code: "invalid_limit", Message: fmt.Sprintf("limit must be between 1 and %d", MaxSlowQueryLimit)})
+		} else {
+			limit = value
+		}
+	}
+
+	minMeanMs := 0.0
+	if raw := r.URL.Query().Get("min_mean_ms"); raw != "" {
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || value < 0 {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "min_mean_ms", Code: "invalid_number", Message: "min_mean_ms must be a non-negative number"})
+		} else {
+			minMeanMs = value
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		sharedHttp.SendError(w, r, sharedErrors.Validation("invalid_query", "invalid slow query parameters", fieldErrors...), "")
+		return
+	}
+
+	slowQueries, err := h.dbHandler.SlowQueries(r.Context(), limit, minMeanMs)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read slow queries")
+		sharedHttp.SendError(w, r, err, "Failed to read slow queries")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Slow queries retrieved", slowQueries)
+}
+
+// Tables handles GET /api/v1/data/admin/tables
+func (h *HTTPHandler) Tables(w http.ResponseWriter, r *http.Request) {
+	tables, err := h.dbHandler.TableSizes(r.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read table sizes")
+		sharedHttp.SendError(w, r, err, "Failed to read table sizes")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Table sizes retrieved", tables)
+}
+
+// Indexes handles GET /api/v1/data/admin/indexes
+func (h *HTTPHandler) Indexes(w http.ResponseWriter, r *http.Request) {
+	indexes, err := h.dbHandler.IndexSizes(r.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read index sizes")
+		sharedHttp.SendError(w, r, err, "Failed to read index sizes")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Index sizes retrieved", indexes)
+}
+
+// Bloat handles GET /api/v1/data/admin/bloat
+func (h *HTTPHandler) Bloat(w http.ResponseWriter, r *http.Request) {
+	tables, err := h.dbHandler.Bloat(r.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to estimate table bloat")
+		sharedHttp.SendError(w, r, err, "Failed to estimate table bloat")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Table bloat estimated", tables)
+}
+
+// Locks handles GET /api/v1/data/admin/locks
+func (h *HTTPHandler) Locks(w http.ResponseWriter, r *http.Request) {
+	waits, err := h.dbHandler.LockWaits(r.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read lock waits")
+		sharedHttp.SendError(w, r, err, "Failed to read lock waits")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Lock waits retrieved", waits)
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Session is a connection to the database as seen in pg_stat_activity
+type Session struct {
+	PID             int        `json:"pid"`
+	ApplicationName string     `json:"application_name"`
+	Username        string     `json:"username"`
+	ClientAddr      *string    `json:"client_addr,omitempty"`
+	State           *string    `json:"state,omitempty"`
+	WaitEventType   *string    `json:"wait_event_type,omitempty"`
+	WaitEvent       *string    `json:"wait_event,omitempty"`
+	BackendStart    *time.Time `json:"backend_start,omitempty"`
+	XactStart       *time.Time `json:"xact_start,omitempty"`
+	QueryStart      *time.Time `json:"query_start,omitempty"`
+	RunningMs       *float64   `json:"running_ms,omitempty"`
+	Query           *string    `json:"query,omitempty"`
+}
+
+// ApplicationConnections counts the connections an application holds by state
+type ApplicationConnections struct {
+	ApplicationName   string `json:"application_name"`
+	Total             int    `json:"total"`
+	Active            int    `json:"active"`
+	Idle              int    `json:"idle"`
+	IdleInTransaction int    `json:"idle_in_transaction"`
+	WaitingOnLocks    int    `json:"waiting_on_locks"`
+}
+
+// ConnectionsResponse is the connection usage of the server and of each application,
+// along with the pool of data-service itself
+type ConnectionsResponse struct {
+	MaxConnections      int                      `json:"max_connections"`
+	ReservedConnections int                      `json:"reserved_connections"`
+	UsedConnections     int                      `json:"used_connections"`
+	Applications        []ApplicationConnections `json:"applications"`
+	Pool                sql.DBStats              `json:"pool"`
+}
+
+// SlowQuery is a normalized statement from pg_stat_statements. Times are in milliseconds.
+type SlowQuery struct {
+	QueryID       string   `json:"query_id"`
+	Query         string   `json:"query"`
+	Username      string   `json:"username"`
+	Calls         int64    `json:"calls"`
+	Rows          int64    `json:"rows"`
+	TotalMs       float64  `json:"total_ms"`
+	MeanMs        float64  `json:"mean_ms"`
+	MaxMs         float64  `json:"max_ms"`
+	StddevMs      float64  `json:"stddev_ms"`
+	CacheHitRatio *float64 `json:"cache_hit_ratio,omitempty"`
+}
+
+// TableSize is the disk usage and activity of a table
+type TableSize struct {
+	TableName   string     `json:"table_name"`
+	TotalBytes  int64      `json:"total_bytes"`
+	TableBytes  int64      `json:"table_bytes"`
+	IndexBytes  int64      `json:"index_bytes"`
+	ToastBytes  int64      `json:"toast_bytes"`
+	LiveRows    int64      `json:"live_rows"`
+	DeadRows    int64      `json:"dead_rows"`
+	SeqScans    int64      `json:"seq_scans"`
+	IndexScans  int64      `json:"index_scans"`
+	LastVacuum  *time.Time `json:"last_vacuum,omitempty"`
+	LastAnalyze *time.Time `json:"last_analyze,omitempty"`
+}
+
+// IndexSize is the disk usage and use of an index
+type IndexSize struct {
+	TableName  string `json:"table_name"`
+	IndexName  string `json:"index_name"`
+	IndexBytes int64  `json:"index_bytes"`
+	Scans      int64  `json:"scans"`
+	TuplesRead int64  `json:"tuples_read"`
+	IsUnique   bool   `json:"is_unique"`
+	IsPrimary  bool   `json:"is_primary"`
+}
+
+// TableBloat is the space a table wastes on dead rows, estimated from its statistics
+type TableBloat struct {
+	TableName           string     `json:"table_name"`
+	TableBytes          int64      `json:"table_bytes"`
+	LiveRows            int64      `json:"live_rows"`
+	DeadRows            int64      `json:"dead_rows"`
+	DeadRatio           float64    `json:"dead_ratio"`
+	EstimatedBloatBytes int64      `json:"estimated_bloat_bytes"`
+	LastVacuum          *time.Time `json:"last_vacuum,omitempty"`
+}
+
+// LockWait is a session waiting on a lock held by another session
+type LockWait struct {
+	BlockedPID          int      `json:"blocked_pid"`
+	BlockedApplication  string   `json:"blocked_application"`
+	BlockedQuery        *string  `json:"blocked_query,omitempty"`
+	WaitingMs           *float64 `json:"waiting_ms,omitempty"`
+	LockType            *string  `json:"lock_type,omitempty"`
+	LockMode            *string  `json:"lock_mode,omitempty"`
+	Relation            *string  `json:"relation,omitempty"`
+	BlockingPID         int      `json:"blocking_pid"`
+	BlockingApplication string   `json:"blocking_application"`
+	BlockingState       *string  `json:"blocking_state,omitempty"`
+	BlockingQuery       *string  `json:"blocking_query,omitempty"`
+}
@@ -0,0 +1,38 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	ActivityQuery                 queries.Name = "activity"
+	ConnectionsByApplicationQuery queries.Name = "connections_by_application"
+	ConnectionLimitsQuery         queries.Name = "connection_limits"
+	HasPgStatStatementsQuery      queries.Name = "has_pg_stat_statements"
+	SlowQueriesQuery              queries.Name = "slow_queries"
+	TableSizesQuery               queries.Name = "table_sizes"
+	IndexSizesQuery               queries.Name = "index_sizes"
+	TableBloatQuery               queries.Name = "table_bloat"
+	LockWaitsQuery                queries.Name = "lock_waits"
+)
+
+// LoadQueries loads and validates the database statistics SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ActivityQuery,
+		ConnectionsByApplicationQuery,
+		ConnectionLimitsQuery,
+		HasPgStatStatementsQuery,
+		SlowQueriesQuery,
+		TableSizesQuery,
+		IndexSizesQuery,
+		TableBloatQuery,
+		LockWaitsQuery,
+	)
+}
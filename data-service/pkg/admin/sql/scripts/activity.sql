@@ -0,0 +1,18 @@
+-- Connections to this database other than the one running the query, busiest first
+SELECT pid,
+       COALESCE(application_name, '') AS application_name,
+       COALESCE(usename, '') AS username,
+       host(client_addr) AS client_addr,
+       state,
+       wait_event_type,
+       wait_event,
+       backend_start,
+       xact_start,
+       query_start,
+       CASE WHEN state = 'active' THEN EXTRACT(EPOCH FROM clock_timestamp() - query_start) * 1000 END AS running_ms,
+       LEFT(query, 1000) AS query
+FROM pg_stat_activity
+WHERE datname = current_database()
+  AND backend_type = 'client backend'
+  AND pid <> pg_backend_pid()
+ORDER BY state = 'active' DESC, query_start ASC NULLS LAST;
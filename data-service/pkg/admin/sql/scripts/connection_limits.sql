@@ -0,0 +1,4 @@
+-- Server wide connection limit and usage, since every database shares max_connections
+SELECT current_setting('max_connections')::int AS max_connections,
+       current_setting('superuser_reserved_connections')::int AS reserved_connections,
+       (SELECT COUNT(*) FROM pg_stat_activity WHERE backend_type = 'client backend') AS used_connections;
@@ -0,0 +1,12 @@
+-- Client connections to this database per application_name and state
+SELECT COALESCE(NULLIF(application_name, ''), 'unknown') AS application_name,
+       COUNT(*) AS total,
+       COUNT(*) FILTER (WHERE state = 'active') AS active,
+       COUNT(*) FILTER (WHERE state = 'idle') AS idle,
+       COUNT(*) FILTER (WHERE state LIKE 'idle in transaction%') AS idle_in_transaction,
+       COUNT(*) FILTER (WHERE wait_event_type = 'Lock') AS waiting_on_locks
+FROM pg_stat_activity
+WHERE datname = current_database()
+  AND backend_type = 'client backend'
+GROUP BY 1
+ORDER BY total DESC, application_name ASC;
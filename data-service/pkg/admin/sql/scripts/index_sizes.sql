@@ -0,0 +1,13 @@
+-- Indexes of the public schema, largest first. Indexes never scanned since the
+-- statistics were reset are candidates for removal, unless they back a constraint.
+SELECT s.relname AS table_name,
+       s.indexrelname AS index_name,
+       pg_relation_size(s.indexrelid) AS index_bytes,
+       s.idx_scan AS scans,
+       s.idx_tup_read AS tuples_read,
+       i.indisunique AS is_unique,
+       i.indisprimary AS is_primary
+FROM pg_stat_user_indexes s
+JOIN pg_index i ON i.indexrelid = s.indexrelid
+WHERE s.schemaname = 'public'
+ORDER BY index_bytes DESC, index_name ASC;
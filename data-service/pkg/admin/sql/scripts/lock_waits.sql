@@ -0,0 +1,23 @@
+-- Sessions waiting on a lock, one row per session blocking them, longest wait first
+SELECT blocked.pid AS blocked_pid,
+       COALESCE(blocked.application_name, '') AS blocked_application,
+       LEFT(blocked.query, 1000) AS blocked_query,
+       EXTRACT(EPOCH FROM clock_timestamp() - blocked.state_change) * 1000 AS waiting_ms,
+       l.locktype AS lock_type,
+       l.mode AS lock_mode,
+       l.relation::regclass::text AS relation,
+       blocking.pid AS blocking_pid,
+       COALESCE(blocking.application_name, '') AS blocking_application,
+       blocking.state AS blocking_state,
+       LEFT(blocking.query, 1000) AS blocking_query
+FROM pg_stat_activity blocked
+CROSS JOIN LATERAL unnest(pg_blocking_pids(blocked.pid)) AS b(pid)
+JOIN pg_stat_activity blocking ON blocking.pid = b.pid
+LEFT JOIN LATERAL (
+    SELECT locktype, mode, relation
+    FROM pg_locks
+    WHERE pid = blocked.pid AND NOT granted
+    LIMIT 1
+) l ON TRUE
+WHERE blocked.datname = current_database()
+ORDER BY waiting_ms DESC, blocked_pid ASC, blocking_pid ASC;
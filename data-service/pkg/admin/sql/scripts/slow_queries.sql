@@ -0,0 +1,20 @@
+-- Statements run against this database, slowest on average first
+SELECT s.queryid::text AS query_id,
+       LEFT(s.query, 2000) AS query,
+       COALESCE(r.rolname, '') AS username,
+       s.calls,
+       s.rows,
+       s.total_exec_time AS total_ms,
+       s.mean_exec_time AS mean_ms,
+       s.max_exec_time AS max_ms,
+       s.stddev_exec_time AS stddev_ms,
+       CASE WHEN s.shared_blks_hit + s.shared_blks_read > 0
+            THEN s.shared_blks_hit::float8 / (s.shared_blks_hit + s.shared_blks_read)
+       END AS cache_hit_ratio
+FROM pg_stat_statements s
+JOIN pg_database d ON d.oid = s.dbid
+LEFT JOIN pg_roles r ON r.oid = s.userid
+WHERE d.datname = current_database()
+  AND s.mean_exec_time >= @min_mean_ms
+ORDER BY s.mean_exec_time DESC
+LIMIT @limit;
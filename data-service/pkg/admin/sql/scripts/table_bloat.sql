@@ -0,0 +1,19 @@
+-- Bloat estimated from the dead tuples autovacuum has not reclaimed yet: the share
+-- of dead rows applied to the size of the table. Cheap and good enough to spot tables
+-- that need a VACUUM; it does not see space freed by VACUUM but not returned to the OS.
+SELECT s.relname AS table_name,
+       pg_relation_size(s.relid) AS table_bytes,
+       s.n_live_tup AS live_rows,
+       s.n_dead_tup AS dead_rows,
+       CASE WHEN s.n_live_tup + s.n_dead_tup > 0
+            THEN s.n_dead_tup::float8 / (s.n_live_tup + s.n_dead_tup)
+            ELSE 0
+       END AS dead_ratio,
+       CASE WHEN s.n_live_tup + s.n_dead_tup > 0
+            THEN (pg_relation_size(s.relid) * s.n_dead_tup / (s.n_live_tup + s.n_dead_tup))::bigint
+            ELSE 0
+       END AS estimated_bloat_bytes,
+       GREATEST(s.last_vacuum, s.last_autovacuum) AS last_vacuum
+FROM pg_stat_user_tables s
+WHERE s.schemaname = 'public'
+ORDER BY estimated_bloat_bytes DESC, dead_rows DESC, table_name ASC;
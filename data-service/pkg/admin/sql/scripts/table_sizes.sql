@@ -0,0 +1,18 @@
+-- Tables of the public schema, largest first. total_bytes includes indexes and TOAST.
+SELECT c.relname AS table_name,
+       pg_total_relation_size(c.oid) AS total_bytes,
+       pg_relation_size(c.oid) AS table_bytes,
+       pg_indexes_size(c.oid) AS index_bytes,
+       COALESCE(pg_total_relation_size(NULLIF(c.reltoastrelid, 0)), 0) AS toast_bytes,
+       COALESCE(s.n_live_tup, 0) AS live_rows,
+       COALESCE(s.n_dead_tup, 0) AS dead_rows,
+       COALESCE(s.seq_scan, 0) AS seq_scans,
+       COALESCE(s.idx_scan, 0) AS index_scans,
+       GREATEST(s.last_vacuum, s.last_autovacuum) AS last_vacuum,
+       GREATEST(s.last_analyze, s.last_autoanalyze) AS last_analyze
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_stat_user_tables s ON s.relid = c.oid
+WHERE n.nspname = 'public'
+  AND c.relkind IN ('r', 'p')
+ORDER BY total_bytes DESC, table_name ASC;
@@ -0,0 +1,119 @@
+package backups
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"time"
+
+	sharedHttp "shared/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// longRequestTimeout replaces the server write timeout for dumps, restores and
+// downloads, which take longer than a regular request on a large database
+const longRequestTimeout = 30 * time.Minute
+
+// HTTPHandler serves the backup API
+type HTTPHandler struct {
+	manager *Manager
+	logger  *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(manager *Manager, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{manager: manager, logger: logger}
+}
+
+// RestoreRequest confirms a restore with the token issued for it
+type RestoreRequest struct {
+	ConfirmationToken string `json:"confirmation_token"`
+}
+
+// List handles GET /api/v1/data/backups
+func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
+	backups, err := h.manager.List()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list backups")
+		sharedHttp.SendError(w, r, err, "Failed to list backups")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Backups retrieved", backups)
+}
+
+// Create handles POST /api/v1/data/backups, taking a backup now
+func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
+	extendWriteDeadline(w)
+
+	backup, err := h.manager.Create(r.Context(), TriggerManual)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to take backup")
+		sharedHttp.SendError(w, r, err, "Failed to take backup")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Backup taken", backup)
+}
+
+// Download handles GET /api/v1/data/backups/{name}, sending the dump file
+func (h *HTTPHandler) Download(w http.ResponseWriter, r *http.Request) {
+	backup, path, err := h.manager.Get(mux.Vars(r)["name"])
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to get backup")
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		h.logger.WithError(err).WithField("backup", backup.Name).Error("Failed to open backup")
+		sharedHttp.SendError(w, r, err, "Failed to get backup")
+		return
+	}
+	defer file.Close()
+
+	extendWriteDeadline(w)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": backup.Name}))
+	http.ServeContent(w, r, backup.Name, backup.CreatedAt, file)
+}
+
+// IssueRestoreToken handles POST /api/v1/data/backups/{name}/restore-token
+func (h *HTTPHandler) IssueRestoreToken(w http.ResponseWriter, r *http.Request) {
+	token, err := h.manager.IssueRestoreToken(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to issue restore token")
+		return
+	}
+
+	message := fmt.Sprintf("Send this confirmation_token to /restore before %s to restore the backup", token.ExpiresAt.Format(time.RFC3339))
+	sharedHttp.SendSuccessResponse(w, http.StatusCreated, message, token)
+}
+
+// Restore handles POST /api/v1/data/backups/{name}/restore with the confirmation token
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	var req RestoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	extendWriteDeadline(w)
+	result, err := h.manager.Restore(r.Context(), mux.Vars(r)["name"], req.ConfirmationToken)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore backup")
+		sharedHttp.SendError(w, r, err, "Failed to restore backup")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Backup restored", result)
+}
+
+func extendWriteDeadline(w http.ResponseWriter) {
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(longRequestTimeout))
+}
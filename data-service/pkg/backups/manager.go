@@ -0,0 +1,468 @@
+package backups
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"shared/audit"
+	sharedDb "shared/db"
+	sharedErrors "shared/errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultInterval is how often a backup is taken when the service does not configure it
+	DefaultInterval = 24 * time.Hour
+	// DefaultRetentionDays is how long backups are kept when the service does not configure it
+	DefaultRetentionDays = 14
+	// RestoreTokenTTL is how long a restore confirmation token stays valid
+	RestoreTokenTTL = 5 * time.Minute
+	// restoreLockTimeout bounds how long a restore waits for the locks held by the
+	// other services before it gives up and leaves the database untouched
+	restoreLockTimeout = "60s"
+
+	auditEntity = "database_backup"
+	timeLayout  = "20060102T150405.000Z"
+	fileExt     = ".dump"
+)
+
+// Trigger is what a backup was taken for
+type Trigger string
+
+// Backup triggers
+const (
+	TriggerScheduled  Trigger = "scheduled"
+	TriggerManual     Trigger = "manual"
+	TriggerPreRestore Trigger = "pre-restore"
+)
+
+// namePattern matches backup file names, <database>-<time>-<trigger>.dump
+var namePattern = regexp.MustCompile(`^([A-Za-z0-9_]+)-(\d{8}T\d{6}\.\d{3}Z)-(scheduled|manual|pre-restore)\.dump$`)
+
+// Backup is a logical backup of the database in pg_dump's custom format
+type Backup struct {
+	Name      string    `json:"name"`
+	Trigger   Trigger   `json:"trigger"`
+	SizeBytes int64     `json:"size_bytes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RestoreToken confirms the restore of one backup by the user who asked for it
+type RestoreToken struct {
+	Token     string    `json:"confirmation_token"`
+	Backup    string    `json:"backup"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RestoreResult is the outcome of a restore
+type RestoreResult struct {
+	Restored     Backup    `json:"restored"`
+	SafetyBackup Backup    `json:"safety_backup"`
+	RestoredAt   time.Time `json:"restored_at"`
+}
+
+// auditedBackup is what the audit log records about a backup
+type auditedBackup struct {
+	ID        string  `json:"id"`
+	Trigger   Trigger `json:"trigger"`
+	SizeBytes int64   `json:"size_bytes"`
+}
+
+// pendingRestore is an issued restore token
+type pendingRestore struct {
+	backup    string
+	actorID   string
+	expiresAt time.Time
+}
+
+// runFunc runs a PostgreSQL client tool with extra environment variables
+type runFunc func(ctx context.Context, env []string, name string, args ...string) error
+
+// Manager takes, lists, prunes and restores logical backups of the database with
+// pg_dump and pg_restore, keeping them as files in one directory
+type Manager struct {
+	db        *sharedDb.DbHandler
+	dbConfig  *sharedDb.Config
+	audit     *audit.Log
+	dir       string
+	interval  time.Duration
+	retention time.Duration
+	run       runFunc
+	now       func() time.Time
+	logger    *logrus.Logger
+
+	// running serializes backups and restores, so a restore never reads a half
+	// written file and two dumps never compete for the same locks
+	running sync.Mutex
+
+	tokensMu sync.Mutex
+	tokens   map[string]pendingRestore
+}
+
+// NewManager creates a backup manager writing to dir. Backups are taken every interval
+// and kept for retentionDays; zero values fall back to the defaults.
+func NewManager(db *sharedDb.DbHandler, dbConfig *sharedDb.Config, auditLog *audit.Log, dir string, interval time.Duration, retentionDays int, logger *logrus.Logger) (*Manager, error) {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if retentionDays <= 0 {
+		retentionDays = DefaultRetentionDays
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	return &Manager{
+		db:        db,
+		dbConfig:  dbConfig,
+		audit:     auditLog,
+		dir:       dir,
+		interval:  interval,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		run:       runTool,
+		now:       time.Now,
+		logger:    logger,
+		tokens:    make(map[string]pendingRestore),
+	}, nil
+}
+
+// Start takes a backup every interval and prunes the expired ones until ctx is cancelled
+func (m *Manager) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.logger.WithFields(logrus.Fields{
+		"dir":       m.dir,
+		"interval":  m.interval.String(),
+		"retention": m.retention.String(),
+	}).Info("Backup scheduler started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Backup scheduler stopped")
+			return
+		case <-ticker.C:
+			if !m.db.IsConnected() {
+				continue
+			}
+			if _, err := m.Create(ctx, TriggerScheduled); err != nil {
+				m.logger.WithError(err).Warn("Failed to take scheduled backup")
+			}
+		}
+	}
+}
+
+// List returns the backups in the directory, newest first
+func (m *Manager) List() ([]Backup, error) {
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	backups := []Backup{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		backup, ok := parseName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat backup %s: %w", entry.Name(), err)
+		}
+		backup.SizeBytes = info.Size()
+		backups = append(backups, backup)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// Get returns a backup and the path of its file
+func (m *Manager) Get(name string) (Backup, string, error) {
+	backup, ok := parseName(name)
+	if !ok {
+		return Backup{}, "", sharedErrors.NotFound("backup_not_found", fmt.Sprintf("backup %q not found", name))
+	}
+
+	path := filepath.Join(m.dir, name)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return Backup{}, "", sharedErrors.NotFound("backup_not_found", fmt.Sprintf("backup %q not found", name))
+	}
+	if err != nil {
+		return Backup{}, "", fmt.Errorf("failed to stat backup %s: %w", name, err)
+	}
+	backup.SizeBytes = info.Size()
+	return backup, path, nil
+}
+
+// Create takes a backup and prunes the ones older than the retention window
+func (m *Manager) Create(ctx context.Context, trigger Trigger) (Backup, error) {
+	if !m.running.TryLock() {
+		return Backup{}, sharedErrors.Conflict("backup_in_progress", "another backup or restore is running, try again later")
+	}
+	defer m.running.Unlock()
+
+	backup, err := m.create(ctx, trigger)
+	if err != nil {
+		return Backup{}, err
+	}
+	if err := m.prune(); err != nil {
+		m.logger.WithError(err).Warn("Failed to prune old backups")
+	}
+	return backup, nil
+}
+
+// create dumps the database to a new file; the caller holds running
+func (m *Manager) create(ctx context.Context, trigger Trigger) (Backup, error) {
+	backup := Backup{Trigger: trigger, CreatedAt: m.now().UTC().Truncate(time.Millisecond)}
+	backup.Name = fmt.Sprintf("%s-%s-%s%s", m.dbConfig.DBName, backup.CreatedAt.Format(timeLayout), trigger, fileExt)
+	path := filepath.Join(m.dir, backup.Name)
+
+	// Dump to a partial file first so a failed dump is never listed as a backup
+	partial := path + ".partial"
+	err := m.run(ctx, m.env(), "pg_dump", append(m.connectionArgs(),
+		"--format=custom",
+		"--file="+partial,
+	)...)
+	if err != nil {
+		os.Remove(partial)
+		return Backup{}, fmt.Errorf("failed to dump database: %w", err)
+	}
+	if err := os.Rename(partial, path); err != nil {
+		os.Remove(partial)
+		return Backup{}, fmt.Errorf("failed to store backup: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Backup{}, fmt.Errorf("failed to stat backup %s: %w", backup.Name, err)
+	}
+	backup.SizeBytes = info.Size()
+
+	if err := m.record(ctx, audit.ActionCreate, backup); err != nil {
+		m.logger.WithError(err).WithField("backup", backup.Name).Warn("Failed to record backup in audit log")
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"backup":  backup.Name,
+		"trigger": trigger,
+		"size":    backup.SizeBytes,
+	}).Info("Database backup taken")
+	return backup, nil
+}
+
+// prune removes the backups older than the retention window, always keeping the
+// newest one so a long outage never leaves the directory empty
+func (m *Manager) prune() error {
+	backups, err := m.List()
+	if err != nil {
+		return err
+	}
+
+	cutoff := m.now().UTC().Add(-m.retention)
+	for i, backup := range backups {
+		if i == 0 || !backup.CreatedAt.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(m.dir, backup.Name)); err != nil {
+			return fmt.Errorf("failed to remove backup %s: %w", backup.Name, err)
+		}
+		m.logger.WithField("backup", backup.Name).Info("Expired backup removed")
+	}
+	return nil
+}
+
+// IssueRestoreToken returns a single use token that confirms the restore of a backup.
+// Only the user it was issued to can use it, within RestoreTokenTTL.
+func (m *Manager) IssueRestoreToken(ctx context.Context, name string) (*RestoreToken, error) {
+	if _, _, err := m.Get(name); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate restore token: %w", err)
+	}
+	token := &RestoreToken{
+		Token:     hex.EncodeToString(buf),
+		Backup:    name,
+		ExpiresAt: m.now().UTC().Add(RestoreTokenTTL),
+	}
+
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	for key, pending := range m.tokens {
+		if m.now().After(pending.expiresAt) {
+			delete(m.tokens, key)
+		}
+	}
+	m.tokens[token.Token] = pendingRestore{
+		backup:    name,
+		actorID:   audit.ActorFromContext(ctx).ID,
+		expiresAt: token.ExpiresAt,
+	}
+	return token, nil
+}
+
+// consumeToken checks and invalidates a restore token
+func (m *Manager) consumeToken(ctx context.Context, name, token string) error {
+	if token == "" {
+		return sharedErrors.Validation("confirmation_token_required", "a confirmation token is required to restore a backup",
+			sharedErrors.FieldError{Field: "confirmation_token", Code: "required", Message: "confirmation_token is required"})
+	}
+
+	m.tokensMu.Lock()
+	defer m.tokensMu.Unlock()
+	pending, ok := m.tokens[token]
+	delete(m.tokens, token)
+
+	invalid := sharedErrors.PreconditionFailed("invalid_confirmation_token", "the confirmation token is invalid or expired, request a new one")
+	if !ok || pending.backup != name || pending.actorID != audit.ActorFromContext(ctx).ID {
+		return invalid
+	}
+	if m.now().After(pending.expiresAt) {
+		return invalid
+	}
+	return nil
+}
+
+// Restore replaces the contents of the database with a backup. It takes a pre-restore
+// backup first, so the restore itself can be rolled back, and runs pg_restore in a
+// single transaction, so a failed restore leaves the database as it was.
+func (m *Manager) Restore(ctx context.Context, name, token string) (*RestoreResult, error) {
+	backup, path, err := m.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	// Locked before the token is used up, so a restore turned away keeps its token
+	if !m.running.TryLock() {
+		return nil, sharedErrors.Conflict("backup_in_progress", "another backup or restore is running, try again later")
+	}
+	defer m.running.Unlock()
+
+	if err := m.consumeToken(ctx, name, token); err != nil {
+		return nil, err
+	}
+	// A client that disconnects must not kill pg_restore halfway through
+	ctx = context.WithoutCancel(ctx)
+
+	safety, err := m.create(ctx, TriggerPreRestore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take pre-restore backup: %w", err)
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"backup":        name,
+		"safety_backup": safety.Name,
+		"actor_id":      audit.ActorFromContext(ctx).ID,
+	}).Warn("Restoring database backup")
+
+	err = m.run(ctx, append(m.env(), "PGOPTIONS=-c lock_timeout="+restoreLockTimeout), "pg_restore", append(m.connectionArgs(),
+		"--clean",
+		"--if-exists",
+		"--single-transaction",
+		"--exit-on-error",
+		"--no-owner",
+		"--no-privileges",
+		path,
+	)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore backup %s: %w", name, err)
+	}
+
+	// Recorded after the restore, which replaced audit_log along with everything else
+	if err := m.record(ctx, audit.ActionRestore, backup); err != nil {
+		m.logger.WithError(err).WithField("backup", name).Warn("Failed to record restore in audit log")
+	}
+
+	m.logger.WithField("backup", name).Info("Database backup restored")
+	return &RestoreResult{
+		Restored:     backup,
+		SafetyBackup: safety,
+		RestoredAt:   m.now().UTC(),
+	}, nil
+}
+
+// record writes a backup action to the audit log
+func (m *Manager) record(ctx context.Context, action audit.Action, backup Backup) error {
+	if m.audit == nil {
+		return nil
+	}
+	return m.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		return m.audit.Record(ctx, auditEntity, action, nil, &auditedBackup{
+			ID:        backup.Name,
+			Trigger:   backup.Trigger,
+			SizeBytes: backup.SizeBytes,
+		})
+	})
+}
+
+// connectionArgs are the pg_dump and pg_restore flags that select the database
+func (m *Manager) connectionArgs() []string {
+	return []string{
+		"--host=" + m.dbConfig.Host,
+		"--port=" + strconv.Itoa(m.dbConfig.Port),
+		"--username=" + m.dbConfig.User,
+		"--dbname=" + m.dbConfig.DBName,
+		"--no-password",
+	}
+}
+
+// env passes the password and SSL mode the way libpq reads them, keeping the
+// password out of the process list
+func (m *Manager) env() []string {
+	return []string{
+		"PGPASSWORD=" + m.dbConfig.Password,
+		"PGSSLMODE=" + m.dbConfig.SSLMode,
+		"PGAPPNAME=data-service-backup",
+	}
+}
+
+// parseName returns the backup a file name describes
+func parseName(name string) (Backup, bool) {
+	match := namePattern.FindStringSubmatch(name)
+	if match == nil {
+		return Backup{}, false
+	}
+	createdAt, err := time.Parse(timeLayout, match[2])
+	if err != nil {
+		return Backup{}, false
+	}
+	return Backup{Name: name, Trigger: Trigger(match[3]), CreatedAt: createdAt}, true
+}
+
+// runTool runs a PostgreSQL client tool, returning its stderr as the error
+func runTool(ctx context.Context, env []string, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
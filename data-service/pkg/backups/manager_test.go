@@ -0,0 +1,194 @@
+package backups
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shared/audit"
+	sharedDb "shared/db"
+	sharedErrors "shared/errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeTools stands in for pg_dump and pg_restore, recording the calls
+type fakeTools struct {
+	calls   []string
+	dumpErr error
+}
+
+func (f *fakeTools) run(ctx context.Context, env []string, name string, args ...string) error {
+	f.calls = append(f.calls, name)
+	if name != "pg_dump" {
+		return nil
+	}
+	if f.dumpErr != nil {
+		return f.dumpErr
+	}
+	for _, arg := range args {
+		if file, ok := strings.CutPrefix(arg, "--file="); ok {
+			return os.WriteFile(file, []byte("PGDMP"), 0o600)
+		}
+	}
+	return errors.New("pg_dump called without --file")
+}
+
+func newTestManager(t *testing.T, now time.Time) (*Manager, *fakeTools) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	m, err := NewManager(nil, &sharedDb.Config{DBName: "barrest_db"}, nil, t.TempDir(), 0, 7, logger)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	tools := &fakeTools{}
+	m.run = tools.run
+	m.now = func() time.Time { return now }
+	return m, tools
+}
+
+func TestCreate_ListsNewestFirst(t *testing.T) {
+	now := time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC)
+	m, _ := newTestManager(t, now)
+
+	first, err := m.Create(context.Background(), TriggerScheduled)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if first.Name != "barrest_db-20260301T040000.000Z-scheduled.dump" || first.SizeBytes != 5 {
+		t.Errorf("Create() = %+v", first)
+	}
+
+	m.now = func() time.Time { return now.Add(time.Hour) }
+	second, err := m.Create(context.Background(), TriggerManual)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	backups, err := m.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(backups) != 2 || backups[0].Name != second.Name || backups[1].Trigger != TriggerScheduled {
+		t.Errorf("List() = %+v, want the manual backup first", backups)
+	}
+}
+
+func TestCreate_FailedDumpLeavesNoFile(t *testing.T) {
+	m, tools := newTestManager(t, time.Now())
+	tools.dumpErr = errors.New("connection refused")
+
+	if _, err := m.Create(context.Background(), TriggerManual); err == nil {
+		t.Fatal("Create() error = nil, want the dump error")
+	}
+	entries, _ := os.ReadDir(m.dir)
+	if len(entries) != 0 {
+		t.Errorf("backup directory has %d files, want none", len(entries))
+	}
+}
+
+func TestCreate_PrunesExpiredBackups(t *testing.T) {
+	now := time.Date(2026, 3, 20, 4, 0, 0, 0, time.UTC)
+	m, _ := newTestManager(t, now)
+
+	for _, name := range []string{
+		"barrest_db-20260301T040000.000Z-scheduled.dump", // expired
+		"barrest_db-20260315T040000.000Z-manual.dump",    // within 7 days
+		"notes.txt", // not a backup, left alone
+	} {
+		os.WriteFile(filepath.Join(m.dir, name), []byte("x"), 0o600)
+	}
+
+	if _, err := m.Create(context.Background(), TriggerScheduled); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	backups, _ := m.List()
+	if len(backups) != 2 || backups[1].Name != "barrest_db-20260315T040000.000Z-manual.dump" {
+		t.Errorf("List() = %+v, want the new and the manual backup", backups)
+	}
+	if _, err := os.Stat(filepath.Join(m.dir, "notes.txt")); err != nil {
+		t.Errorf("notes.txt was removed: %v", err)
+	}
+}
+
+func TestPrune_KeepsNewestBackup(t *testing.T) {
+	m, _ := newTestManager(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC))
+	os.WriteFile(filepath.Join(m.dir, "barrest_db-20260301T040000.000Z-scheduled.dump"), []byte("x"), 0o600)
+
+	if err := m.prune(); err != nil {
+		t.Fatalf("prune() error = %v", err)
+	}
+	if backups, _ := m.List(); len(backups) != 1 {
+		t.Errorf("List() = %+v, want the newest backup kept", backups)
+	}
+}
+
+func TestGet_RejectsPaths(t *testing.T) {
+	m, _ := newTestManager(t, time.Now())
+	for _, name := range []string{"../etc/passwd", "barrest_db-20260301T040000.000Z-scheduled.dump"} {
+		if _, _, err := m.Get(name); !errors.Is(err, sharedErrors.ErrNotFound) {
+			t.Errorf("Get(%q) error = %v, want not found", name, err)
+		}
+	}
+}
+
+func TestRestore_RequiresConfirmationToken(t *testing.T) {
+	now := time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC)
+	m, tools := newTestManager(t, now)
+	admin := audit.WithActor(context.Background(), audit.Actor{ID: "admin-1"})
+	other := audit.WithActor(context.Background(), audit.Actor{ID: "admin-2"})
+
+	backup, err := m.Create(admin, TriggerManual)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if _, err := m.Restore(admin, backup.Name, ""); !errors.Is(err, sharedErrors.ErrValidation) {
+		t.Errorf("Restore() without token error = %v, want a validation error", err)
+	}
+
+	token, err := m.IssueRestoreToken(admin, backup.Name)
+	if err != nil {
+		t.Fatalf("IssueRestoreToken() error = %v", err)
+	}
+	if _, err := m.Restore(other, backup.Name, token.Token); !errors.Is(err, sharedErrors.ErrPreconditionFailed) {
+		t.Errorf("Restore() by another user error = %v, want precondition failed", err)
+	}
+	// The failed attempt used the token up
+	if _, err := m.Restore(admin, backup.Name, token.Token); !errors.Is(err, sharedErrors.ErrPreconditionFailed) {
+		t.Errorf("Restore() with a used token error = %v, want precondition failed", err)
+	}
+
+	token, _ = m.IssueRestoreToken(admin, backup.Name)
+	m.now = func() time.Time { return now.Add(RestoreTokenTTL + time.Second) }
+	if _, err := m.Restore(admin, backup.Name, token.Token); !errors.Is(err, sharedErrors.ErrPreconditionFailed) {
+		t.Errorf("Restore() with an expired token error = %v, want precondition failed", err)
+	}
+
+	m.now = func() time.Time { return now.Add(time.Minute) }
+	token, _ = m.IssueRestoreToken(admin, backup.Name)
+	// A restore turned away by a running backup keeps its token
+	m.running.Lock()
+	if _, err := m.Restore(admin, backup.Name, token.Token); !errors.Is(err, sharedErrors.ErrConflict) {
+		t.Errorf("Restore() during a backup error = %v, want a conflict", err)
+	}
+	m.running.Unlock()
+	result, err := m.Restore(admin, backup.Name, token.Token)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if result.SafetyBackup.Trigger != TriggerPreRestore {
+		t.Errorf("SafetyBackup = %+v, want a pre-restore backup", result.SafetyBackup)
+	}
+	if last := tools.calls[len(tools.calls)-2:]; last[0] != "pg_dump" || last[1] != "pg_restore" {
+		t.Errorf("calls = %v, want pg_dump before pg_restore", tools.calls)
+	}
+}
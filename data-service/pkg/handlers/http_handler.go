@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	adminHandlers "data-service/pkg/admin/handlers"
+	"data-service/pkg/backups"
+	"data-service/pkg/retention"
 	"encoding/json"
 	"net/http"
 	"time"
 
+	"shared/audit"
 	sharedDb "shared/db"
+	sharedHttp "shared/http"
+	"shared/middlewares"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -19,33 +25,76 @@ type HealthChecker interface {
 // Handler is the main HTTP handler for data-service
 type HTTPHandler struct {
 	//settingsHandler *settingsHTTP.HTTPHandler
-	db     *sharedDb.DbHandler
-	config *sharedDb.Config
-	logger *logrus.Logger
+	db               *sharedDb.DbHandler
+	config           *sharedDb.Config
+	healthMonitor    *sharedHttp.HTTPHealthMonitor
+	auditHandler     *audit.HTTPHandler
+	backupHandler    *backups.HTTPHandler
+	retentionHandler *retention.HTTPHandler
+	adminHandler     *adminHandlers.HTTPHandler
+	logger           *logrus.Logger
 }
 
 // NewHandler creates a new HTTP handler
-func NewHTTPHandler(db *sharedDb.DbHandler, config *sharedDb.Config, logger *logrus.Logger) (*HTTPHandler, error) {
+func NewHTTPHandler(db *sharedDb.DbHandler, config *sharedDb.Config, healthMonitor *sharedHttp.HTTPHealthMonitor, auditLog *audit.Log, backupManager *backups.Manager, retentionManager *retention.Manager, logger *logrus.Logger) (*HTTPHandler, error) {
 	// repository, err := settings.NewRepository(db)
 	// if err != nil {
 	// 	return nil, err
 	// }
 	// settingsHandler := settingsHTTP.NewHTTPHandler(repository, logger)
 
+	adminDBHandler, err := adminHandlers.NewDBHandler(db, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	return &HTTPHandler{
 		//settingsHandler: settingsHandler,
-		db:     db,
-		config: config,
-		logger: logger,
+		db:               db,
+		config:           config,
+		healthMonitor:    healthMonitor,
+		auditHandler:     audit.NewHTTPHandler(auditLog, logger),
+		backupHandler:    backups.NewHTTPHandler(backupManager, logger),
+		retentionHandler: retention.NewHTTPHandler(retentionManager, logger),
+		adminHandler:     adminHandlers.NewHTTPHandler(adminDBHandler, logger),
+		logger:           logger,
 	}, nil
 }
 
 // SetupRoutes configures all HTTP routes
 func (h *HTTPHandler) SetupRoutes(router *mux.Router) {
+	// Attribute backups and restores to the user the gateway authenticated
+	router.Use(audit.Middleware)
+
 	//Root endpoint
 	router.HandleFunc("/", h.RootHandler).Methods("GET")
 	//Public endpoints
 	router.HandleFunc("/api/v1/data/p/health", h.HealthCheck).Methods("GET")
+	router.HandleFunc("/api/v1/data/p/livez", sharedHttp.LivenessHandler("data-service")).Methods("GET")
+	router.HandleFunc("/api/v1/data/p/readyz", h.healthMonitor.ReadinessHandler("data-service")).Methods("GET")
+	adminOnly := middlewares.RequireRole("admin")
+	//Audit log, admins only since it holds the before and after of every change
+	router.Handle("/api/v1/data/audit", adminOnly(http.HandlerFunc(h.auditHandler.List))).Methods("GET")
+	//Backups, admins only since a dump holds every table
+	router.Handle("/api/v1/data/backups", adminOnly(http.HandlerFunc(h.backupHandler.List))).Methods("GET")
+	router.Handle("/api/v1/data/backups", adminOnly(http.HandlerFunc(h.backupHandler.Create))).Methods("POST")
+	router.Handle("/api/v1/data/backups/{name}", adminOnly(http.HandlerFunc(h.backupHandler.Download))).Methods("GET")
+	router.Handle("/api/v1/data/backups/{name}/restore-token", adminOnly(http.HandlerFunc(h.backupHandler.IssueRestoreToken))).Methods("POST")
+	router.Handle("/api/v1/data/backups/{name}/restore", adminOnly(http.HandlerFunc(h.backupHandler.Restore))).Methods("POST")
+	//Retention policies and the archived history they moved
+	router.Handle("/api/v1/data/retention", adminOnly(http.HandlerFunc(h.retentionHandler.Policies))).Methods("GET")
+	router.Handle("/api/v1/data/retention/run", adminOnly(http.HandlerFunc(h.retentionHandler.Run))).Methods("POST")
+	router.Handle("/api/v1/data/retention/{table}", adminOnly(http.HandlerFunc(h.retentionHandler.SetDays))).Methods("PUT")
+	router.Handle("/api/v1/data/archive", adminOnly(http.HandlerFunc(h.retentionHandler.ArchiveTables))).Methods("GET")
+	router.Handle("/api/v1/data/archive/{table}", adminOnly(http.HandlerFunc(h.retentionHandler.Archived))).Methods("GET")
+	//Database statistics of the shared Postgres server
+	router.Handle("/api/v1/data/admin/activity", adminOnly(http.HandlerFunc(h.adminHandler.Activity))).Methods("GET")
+	router.Handle("/api/v1/data/admin/connections", adminOnly(http.HandlerFunc(h.adminHandler.Connections))).Methods("GET")
+	router.Handle("/api/v1/data/admin/slow-queries", adminOnly(http.HandlerFunc(h.adminHandler.SlowQueries))).Methods("GET")
+	router.Handle("/api/v1/data/admin/tables", adminOnly(http.HandlerFunc(h.adminHandler.Tables))).Methods("GET")
+	router.Handle("/api/v1/data/admin/indexes", adminOnly(http.HandlerFunc(h.adminHandler.Indexes))).Methods("GET")
+	router.Handle("/api/v1/data/admin/bloat", adminOnly(http.HandlerFunc(h.adminHandler.Bloat))).Methods("GET")
+	router.Handle("/api/v1/data/admin/locks", adminOnly(http.HandlerFunc(h.adminHandler.Locks))).Methods("GET")
 }
 
 // RootHandler handles the root endpoint
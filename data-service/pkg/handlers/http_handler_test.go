@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestSetupRoutes_AuditLogAdminOnly(t *testing.T) {
+	router := mux.NewRouter()
+	(&HTTPHandler{}).SetupRoutes(router)
+
+	for _, role := range []string{"", "waiter", "manager"} {
+		req := httptest.NewRequest("GET", "/api/v1/data/audit?entity=supplier", nil)
+		req.Header.Set("X-User-ID", "user-1")
+		req.Header.Set("X-User-Role", role)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("role %q: status = %d, want %d", role, rr.Code, http.StatusForbidden)
+		}
+	}
+}
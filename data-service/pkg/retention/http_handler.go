@@ -0,0 +1,183 @@
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	sharedErrors "shared/errors"
+	sharedHttp "shared/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// runRequestTimeout replaces the server write timeout for runs, which move a large
+// backlog in many batches
+const runRequestTimeout = 30 * time.Minute
+
+// HTTPHandler serves the retention and archive API
+type HTTPHandler struct {
+	manager *Manager
+	logger  *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(manager *Manager, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{manager: manager, logger: logger}
+}
+
+// SetDaysRequest changes the retention window of a table
+type SetDaysRequest struct {
+	Days *int `json:"days"`
+}
+
+// Policies handles GET /api/v1/data/retention
+func (h *HTTPHandler) Policies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.manager.Policies(r.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read retention policies")
+		sharedHttp.SendError(w, r, err, "Failed to read retention policies")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Retention policies retrieved", policies)
+}
+
+// SetDays handles PUT /api/v1/data/retention/{table} with {"days": 365}
+func (h *HTTPHandler) SetDays(w http.ResponseWriter, r *http.Request) {
+	var req SetDaysRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+	if req.Days == nil {
+		sharedHttp.SendError(w, r, sharedErrors.Validation("invalid_retention_days", "invalid retention window",
+			sharedErrors.FieldError{Field: "days", Code: "required", Message: "days is required"}), "")
+		return
+	}
+
+	policy, err := h.manager.SetDays(r.Context(), mux.Vars(r)["table"], *req.Days)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to change retention window")
+		sharedHttp.SendError(w, r, err, "Failed to change retention window")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Retention window changed", policy)
+}
+
+// Run handles POST /api/v1/data/retention/run?dry_run=true&table=stock_count, where
+// table may repeat and defaults to every table
+func (h *HTTPHandler) Run(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			sharedHttp.SendError(w, r, sharedErrors.Validation("invalid_query", "invalid retention run parameters",
+				sharedErrors.FieldError{Field: "dry_run", Code: "invalid_boolean", Message: "dry_run must be true or false"}), "")
+			return
+		}
+		dryRun = value
+	}
+
+	if !dryRun {
+		http.NewResponseController(w).SetWriteDeadline(time.Now().Add(runRequestTimeout))
+	}
+	result, err := h.manager.Run(r.Context(), dryRun, r.URL.Query()["table"])
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to run retention policies")
+		sharedHttp.SendError(w, r, err, "Failed to run retention policies")
+		return
+	}
+
+	message := "Retention policies applied"
+	if dryRun {
+		message = "Retention dry run completed, nothing was changed"
+	}
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, message, result)
+}
+
+// ArchiveTables handles GET /api/v1/data/archive
+func (h *HTTPHandler) ArchiveTables(w http.ResponseWriter, r *http.Request) {
+	tables, err := h.manager.ArchiveTables(r.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to read archive tables")
+		sharedHttp.SendError(w, r, err, "Failed to read archive tables")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Archive tables retrieved", tables)
+}
+
+// Archived handles GET /api/v1/data/archive/{table}?from=2024-01-01&to=2025-01-01&limit=50&offset=0
+func (h *HTTPHandler) Archived(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseArchiveFilter(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "")
+		return
+	}
+
+	page, err := h.manager.Archived(r.Context(), mux.Vars(r)["table"], filter)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list archived rows")
+		sharedHttp.SendError(w, r, err, "Failed to list archived rows")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Archived rows retrieved", page)
+}
+
+// parseArchiveFilter reads the archive report parameters; from and to take a date or
+// an RFC 3339 time
+func parseArchiveFilter(r *http.Request) (ArchiveFilter, error) {
+	var fieldErrors []sharedErrors.FieldError
+	query := r.URL.Query()
+	filter := ArchiveFilter{Limit: DefaultArchiveLimit}
+
+	for _, field := range []string{"from", "to"} {
+		raw := query.Get(field)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			t, err = time.Parse(time.DateOnly, raw)
+		}
+		if err != nil {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "invalid_time", Message: field + " must be a date (YYYY-MM-DD) or an RFC 3339 time"})
+			continue
+		}
+		t = t.UTC()
+		if field == "from" {
+			filter.From = &t
+		} else {
+			filter.To = &t
+		}
+	}
+
+	if raw := query.Get("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 || value > MaxArchiveLimit {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "limit", Code: "invalid_limit", Message: fmt.Sprintf("limit must be between 1 and %d", MaxArchiveLimit)})
+		} else {
+			filter.Limit = value
+		}
+	}
+	if raw := query.Get("offset"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "offset", Code: "invalid_offset", Message: "offset must be a non-negative number"})
+		} else {
+			filter.Offset = value
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return ArchiveFilter{}, sharedErrors.Validation("invalid_query", "invalid archive report parameters", fieldErrors...)
+	}
+	return filter, nil
+}
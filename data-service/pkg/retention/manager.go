@@ -0,0 +1,572 @@
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	retentionSQL "data-service/pkg/retention/sql"
+	"shared/audit"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	sharedErrors "shared/errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultInterval is how often the policies run when the service does not configure it
+	DefaultInterval = 24 * time.Hour
+	// BatchSize is how many rows one transaction moves or deletes, so a large backlog
+	// never holds locks on a live table for long
+	BatchSize = 5000
+	// MaxDays is the longest retention window a policy accepts
+	MaxDays = 36500
+	// DefaultArchiveLimit is how many archived rows a report page returns by default
+	DefaultArchiveLimit = 50
+	// MaxArchiveLimit is the most archived rows a report page returns
+	MaxArchiveLimit = 500
+
+	runAuditEntity    = "data_retention"
+	policyAuditEntity = "retention_policy"
+)
+
+// Mode is what a policy does with the rows past their retention window
+type Mode string
+
+// Policy modes
+const (
+	ModeArchive Mode = "archive"
+	ModeDelete  Mode = "delete"
+)
+
+// policy moves or deletes the closed rows of one table once they are older than its
+// retention window. The window is the RETENTION_<TABLE>_DAYS setting, 0 disables it.
+type policy struct {
+	table       string
+	mode        Mode
+	appliesTo   string
+	defaultDays int
+	count       queries.Name
+	apply       queries.Name
+}
+
+// policies in the order they run. Stock counts go before the purchase invoices they
+// reference, which stay live while any stock count points at them.
+var policies = []policy{
+	{"stock_count", ModeArchive, "stock counts marked out", 365,
+		retentionSQL.CountStockCountQuery, retentionSQL.ArchiveStockCountQuery},
+	{"order_items", ModeArchive, "items of paid or cancelled orders", 730,
+		retentionSQL.CountOrderItemsQuery, retentionSQL.ArchiveOrderItemsQuery},
+	{"income_invoices", ModeArchive, "issued or cancelled sales invoices", 1825,
+		retentionSQL.CountIncomeInvoicesQuery, retentionSQL.ArchiveIncomeInvoicesQuery},
+	{"outcome_invoices", ModeArchive, "purchase invoices and their items, once no live stock count references them", 1825,
+		retentionSQL.CountOutcomeInvoicesQuery, retentionSQL.ArchiveOutcomeInvoicesQuery},
+	{"request_notifications", ModeDelete, "completed or cancelled notifications", 30,
+		retentionSQL.CountRequestNotificationsQuery, retentionSQL.DeleteRequestNotificationsQuery},
+	{"sessions", ModeDelete, "sessions", 30,
+		retentionSQL.CountSessionsQuery, retentionSQL.DeleteSessionsQuery},
+}
+
+// archiveTable is a table of the archive schema and the column its reports filter by
+type archiveTable struct {
+	table  string
+	column string
+	list   queries.Name
+}
+
+var archiveTables = []archiveTable{
+	{"stock_count", "purchased_at", retentionSQL.ListArchivedStockCountQuery},
+	{"order_items", "requested_at", retentionSQL.ListArchivedOrderItemsQuery},
+	{"income_invoices", "created_at", retentionSQL.ListArchivedIncomeInvoicesQuery},
+	{"outcome_invoices", "transaction_date", retentionSQL.ListArchivedOutcomeInvoicesQuery},
+	{"invoice_items", "created_at", retentionSQL.ListArchivedInvoiceItemsQuery},
+}
+
+// settingKey is the setting holding the retention window of a table
+func settingKey(table string) string {
+	return "RETENTION_" + strings.ToUpper(table) + "_DAYS"
+}
+
+// parseDays reads a retention window setting
+func parseDays(raw string) (int, error) {
+	days, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || days < 0 || days > MaxDays {
+		return 0, fmt.Errorf("retention window %q must be a whole number of days between 0 and %d", raw, MaxDays)
+	}
+	return days, nil
+}
+
+// PolicyStatus is a retention policy with its configured window
+type PolicyStatus struct {
+	Table      string `json:"table"`
+	Mode       Mode   `json:"mode"`
+	AppliesTo  string `json:"applies_to"`
+	SettingKey string `json:"setting_key"`
+	Days       int    `json:"days"`
+	Enabled    bool   `json:"enabled"`
+	// Invalid explains why a malformed setting disabled the policy
+	Invalid string `json:"invalid,omitempty"`
+}
+
+// resolve applies the retention settings to the policies. A missing setting falls back
+// to the policy default, a malformed one disables the policy until it is fixed.
+func resolve(settings map[string]string) []PolicyStatus {
+	statuses := make([]PolicyStatus, len(policies))
+	for i, p := range policies {
+		status := PolicyStatus{
+			Table:      p.table,
+			Mode:       p.mode,
+			AppliesTo:  p.appliesTo,
+			SettingKey: settingKey(p.table),
+			Days:       p.defaultDays,
+		}
+		if raw, ok := settings[status.SettingKey]; ok {
+			days, err := parseDays(raw)
+			if err != nil {
+				status.Days = 0
+				status.Invalid = err.Error()
+			} else {
+				status.Days = days
+			}
+		}
+		status.Enabled = status.Days > 0
+		statuses[i] = status
+	}
+	return statuses
+}
+
+// TableResult is what a run did, or would do, to one table
+type TableResult struct {
+	Table  string    `json:"table"`
+	Mode   Mode      `json:"mode"`
+	Days   int       `json:"days"`
+	Cutoff time.Time `json:"cutoff"`
+	// Rows is how many rows were moved or deleted, or would be on a dry run
+	Rows    int64      `json:"rows"`
+	Oldest  *time.Time `json:"oldest,omitempty"`
+	Newest  *time.Time `json:"newest,omitempty"`
+	Batches int        `json:"batches,omitempty"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// RunResult is the outcome of a retention run
+type RunResult struct {
+	DryRun     bool          `json:"dry_run"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	Tables     []TableResult `json:"tables"`
+	// Skipped lists disabled policies and why
+	Skipped map[string]string `json:"skipped,omitempty"`
+}
+
+// ArchiveTable is a table of the archive schema
+type ArchiveTable struct {
+	Table          string `json:"table"`
+	FilteredBy     string `json:"filtered_by"`
+	EstimatedRows  int64  `json:"estimated_rows"`
+	TotalSizeBytes int64  `json:"total_size_bytes"`
+}
+
+// ArchiveFilter selects archived rows by the column their table is filtered by
+type ArchiveFilter struct {
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
+
+// ArchivePage is a page of archived rows, as they were in the live table plus archived_at
+type ArchivePage struct {
+	Table  string            `json:"table"`
+	Total  int64             `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+	Rows   []json.RawMessage `json:"rows"`
+}
+
+// auditedRun is what the audit log records about each batch a policy moves or deletes
+type auditedRun struct {
+	ID     string    `json:"id"`
+	Mode   Mode      `json:"mode"`
+	Days   int       `json:"days"`
+	Cutoff time.Time `json:"cutoff"`
+	Rows   int64     `json:"rows"`
+}
+
+// auditedPolicy is what the audit log records about a retention window change
+type auditedPolicy struct {
+	ID   string `json:"id"`
+	Days int    `json:"days"`
+}
+
+// Manager runs the retention policies, moving closed history into the archive schema
+// and deleting rows that are not worth keeping
+type Manager struct {
+	db       *sharedDb.DbHandler
+	queries  *queries.Registry
+	audit    *audit.Log
+	interval time.Duration
+	now      func() time.Time
+	logger   *logrus.Logger
+
+	// running keeps two runs from competing for the same rows
+	running sync.Mutex
+}
+
+// NewManager creates a retention manager running the policies every interval; a zero
+// interval falls back to the default
+func NewManager(db *sharedDb.DbHandler, auditLog *audit.Log, interval time.Duration, logger *logrus.Logger) (*Manager, error) {
+	queries, err := retentionSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	return &Manager{
+		db:       db,
+		queries:  queries,
+		audit:    auditLog,
+		interval: interval,
+		now:      time.Now,
+		logger:   logger,
+	}, nil
+}
+
+// Start runs the policies every interval until ctx is cancelled
+func (m *Manager) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	m.logger.WithField("interval", m.interval.String()).Info("Retention scheduler started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Retention scheduler stopped")
+			return
+		case <-ticker.C:
+			if !m.db.IsConnected() {
+				continue
+			}
+			if _, err := m.Run(ctx, false, nil); err != nil {
+				m.logger.WithError(err).Warn("Failed to run retention policies")
+			}
+		}
+	}
+}
+
+// Policies returns the retention policies with their configured windows
+func (m *Manager) Policies(ctx context.Context) ([]PolicyStatus, error) {
+	settings, err := m.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resolve(settings), nil
+}
+
+// Run applies the enabled policies of the given tables, or of every table when none
+// are given. A dry run only counts the rows each policy would move or delete. A table
+// that fails is reported in its result and does not stop the others.
+func (m *Manager) Run(ctx context.Context, dryRun bool, tables []string) (*RunResult, error) {
+	selected, err := selectTables(tables)
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		if !m.running.TryLock() {
+			return nil, sharedErrors.Conflict("retention_in_progress", "another retention run is in progress, try again later")
+		}
+		defer m.running.Unlock()
+	}
+
+	statuses, err := m.Policies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RunResult{DryRun: dryRun, StartedAt: m.now().UTC(), Tables: []TableResult{}}
+	for i, p := range policies {
+		status := statuses[i]
+		if !selected[p.table] {
+			continue
+		}
+		if !status.Enabled {
+			if result.Skipped == nil {
+				result.Skipped = map[string]string{}
+			}
+			result.Skipped[p.table] = "disabled"
+			if status.Invalid != "" {
+				result.Skipped[p.table] = status.Invalid
+			}
+			continue
+		}
+
+		tr := TableResult{
+			Table:  p.table,
+			Mode:   p.mode,
+			Days:   status.Days,
+			Cutoff: result.StartedAt.Add(-time.Duration(status.Days) * 24 * time.Hour),
+		}
+		if dryRun {
+			err = m.count(ctx, p, &tr)
+		} else {
+			err = m.apply(ctx, p, &tr)
+		}
+		if err != nil {
+			m.logger.WithError(err).WithField("table", p.table).Error("Retention policy failed")
+			tr.Error = err.Error()
+		}
+		result.Tables = append(result.Tables, tr)
+	}
+	result.FinishedAt = m.now().UTC()
+
+	if !dryRun {
+		m.logger.WithField("tables", result.Tables).Info("Retention policies applied")
+	}
+	return result, nil
+}
+
+// selectTables checks the tables a run was asked for
+func selectTables(tables []string) (map[string]bool, error) {
+	selected := make(map[string]bool, len(policies))
+	if len(tables) == 0 {
+		for _, p := range policies {
+			selected[p.table] = true
+		}
+		return selected, nil
+	}
+
+	var fieldErrors []sharedErrors.FieldError
+	for _, table := range tables {
+		if _, ok := findPolicy(table); !ok {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "table", Code: "unknown_table", Message: fmt.Sprintf("no retention policy for table %q", table)})
+			continue
+		}
+		selected[table] = true
+	}
+	if len(fieldErrors) > 0 {
+		return nil, sharedErrors.Validation("invalid_tables", "invalid retention tables", fieldErrors...)
+	}
+	return selected, nil
+}
+
+func findPolicy(table string) (policy, bool) {
+	for _, p := range policies {
+		if p.table == table {
+			return p, true
+		}
+	}
+	return policy{}, false
+}
+
+// count fills in how many rows a policy would move or delete
+func (m *Manager) count(ctx context.Context, p policy, tr *TableResult) error {
+	var oldest, newest sql.NullTime
+	err := m.db.QueryRowNamedContext(ctx, m.queries.Get(p.count), queries.Args{"cutoff": tr.Cutoff}).
+		Scan(&tr.Rows, &oldest, &newest)
+	if err != nil {
+		return fmt.Errorf("failed to count %s past retention: %w", p.table, err)
+	}
+	if oldest.Valid {
+		tr.Oldest = &oldest.Time
+	}
+	if newest.Valid {
+		tr.Newest = &newest.Time
+	}
+	return nil
+}
+
+// apply moves or deletes the rows past a policy's window in batches, each batch in its
+// own transaction with its audit entry
+func (m *Manager) apply(ctx context.Context, p policy, tr *TableResult) error {
+	for {
+		var rows int64
+		err := m.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+			err := m.db.QueryRowNamedContext(ctx, m.queries.Get(p.apply), queries.Args{
+				"cutoff":     tr.Cutoff,
+				"batch_size": BatchSize,
+			}).Scan(&rows)
+			if err != nil {
+				return fmt.Errorf("failed to %s %s: %w", p.mode, p.table, err)
+			}
+			if rows == 0 || m.audit == nil {
+				return nil
+			}
+			return m.audit.Record(ctx, runAuditEntity, audit.ActionDelete, &auditedRun{
+				ID:     p.table,
+				Mode:   p.mode,
+				Days:   tr.Days,
+				Cutoff: tr.Cutoff,
+				Rows:   rows,
+			}, nil)
+		})
+		if err != nil {
+			return err
+		}
+
+		tr.Rows += rows
+		if rows > 0 {
+			tr.Batches++
+		}
+		if rows < BatchSize {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// SetDays changes the retention window of a table, 0 disables its policy
+func (m *Manager) SetDays(ctx context.Context, table string, days int) (*PolicyStatus, error) {
+	p, ok := findPolicy(table)
+	if !ok {
+		return nil, sharedErrors.NotFound("retention_policy_not_found", fmt.Sprintf("no retention policy for table %q", table))
+	}
+	if days < 0 || days > MaxDays {
+		return nil, sharedErrors.Validation("invalid_retention_days", "invalid retention window",
+			sharedErrors.FieldError{Field: "days", Code: "out_of_range", Message: fmt.Sprintf("days must be between 0 and %d", MaxDays)})
+	}
+
+	key := settingKey(table)
+	err := m.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		before := p.defaultDays
+		var raw string
+		err := m.db.QueryRowNamedContext(ctx, m.queries.Get(retentionSQL.GetSettingForUpdateQuery), queries.Args{"key": key}).Scan(&raw)
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return fmt.Errorf("failed to read retention setting: %w", err)
+		default:
+			// A malformed window counts as disabled, which is how the policies read it
+			if before, err = parseDays(raw); err != nil {
+				before = 0
+			}
+		}
+
+		_, err = m.db.ExecNamedContext(ctx, m.queries.Get(retentionSQL.UpsertSettingQuery), queries.Args{
+			"key":         key,
+			"value":       strconv.Itoa(days),
+			"description": fmt.Sprintf("Days %s are kept before they are %sd", p.appliesTo, p.mode),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save retention setting: %w", err)
+		}
+
+		if m.audit == nil {
+			return nil
+		}
+		return m.audit.Record(ctx, policyAuditEntity, audit.ActionUpdate,
+			&auditedPolicy{ID: table, Days: before}, &auditedPolicy{ID: table, Days: days})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.WithFields(logrus.Fields{"table": table, "days": days}).Info("Retention window changed")
+	return &PolicyStatus{
+		Table:      p.table,
+		Mode:       p.mode,
+		AppliesTo:  p.appliesTo,
+		SettingKey: key,
+		Days:       days,
+		Enabled:    days > 0,
+	}, nil
+}
+
+// settings reads the retention windows from the settings table
+func (m *Manager) settings(ctx context.Context) (map[string]string, error) {
+	rows, err := m.db.QueryNamedContext(ctx, m.queries.Get(retentionSQL.ListSettingsQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retention settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := map[string]string{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan retention setting: %w", err)
+		}
+		settings[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating retention settings: %w", err)
+	}
+	return settings, nil
+}
+
+// ArchiveTables returns the archive tables with their size
+func (m *Manager) ArchiveTables(ctx context.Context) ([]ArchiveTable, error) {
+	rows, err := m.db.QueryNamedContext(ctx, m.queries.Get(retentionSQL.ArchiveSizesQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive sizes: %w", err)
+	}
+	defer rows.Close()
+
+	sizes := map[string]ArchiveTable{}
+	for rows.Next() {
+		var t ArchiveTable
+		if err := rows.Scan(&t.Table, &t.EstimatedRows, &t.TotalSizeBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan archive size: %w", err)
+		}
+		sizes[t.Table] = t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating archive sizes: %w", err)
+	}
+
+	tables := make([]ArchiveTable, len(archiveTables))
+	for i, a := range archiveTables {
+		tables[i] = sizes[a.table]
+		tables[i].Table = a.table
+		tables[i].FilteredBy = a.column
+	}
+	return tables, nil
+}
+
+// Archived returns a page of the archived rows of a table, newest first
+func (m *Manager) Archived(ctx context.Context, table string, filter ArchiveFilter) (*ArchivePage, error) {
+	var archive *archiveTable
+	for i := range archiveTables {
+		if archiveTables[i].table == table {
+			archive = &archiveTables[i]
+		}
+	}
+	if archive == nil {
+		return nil, sharedErrors.NotFound("archive_not_found", fmt.Sprintf("table %q has no archive", table))
+	}
+
+	rows, err := m.db.QueryNamedContext(ctx, m.queries.Get(archive.list), queries.Args{
+		"from":   filter.From,
+		"to":     filter.To,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	page := &ArchivePage{Table: table, Limit: filter.Limit, Offset: filter.Offset, Rows: []json.RawMessage{}}
+	for rows.Next() {
+		var row string
+		if err := rows.Scan(&row, &page.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan archived %s: %w", table, err)
+		}
+		page.Rows = append(page.Rows, json.RawMessage(row))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating archived %s: %w", table, err)
+	}
+	return page, nil
+}
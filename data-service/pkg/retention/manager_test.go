@@ -0,0 +1,134 @@
+package retention
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	retentionSQL "data-service/pkg/retention/sql"
+)
+
+func TestLoadQueries(t *testing.T) {
+	registry, err := retentionSQL.LoadQueries()
+	if err != nil {
+		t.Fatalf("LoadQueries() error = %v", err)
+	}
+	// Run binds the same arguments to every policy
+	for _, p := range policies {
+		if got := registry.Get(p.count).Params(); !reflect.DeepEqual(got, []string{"cutoff"}) {
+			t.Errorf("%s count query params = %v, want [cutoff]", p.table, got)
+		}
+		if got := registry.Get(p.apply).Params(); !reflect.DeepEqual(got, []string{"cutoff", "batch_size"}) {
+			t.Errorf("%s %s query params = %v, want [cutoff batch_size]", p.table, p.mode, got)
+		}
+	}
+	for _, a := range archiveTables {
+		if got := registry.Get(a.list).Params(); !reflect.DeepEqual(got, []string{"from", "to", "limit", "offset"}) {
+			t.Errorf("%s archive query params = %v, want [from to limit offset]", a.table, got)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	statuses := resolve(map[string]string{
+		"RETENTION_STOCK_COUNT_DAYS":           "90",
+		"RETENTION_SESSIONS_DAYS":              "0",
+		"RETENTION_REQUEST_NOTIFICATIONS_DAYS": "a week",
+	})
+	if len(statuses) != len(policies) {
+		t.Fatalf("resolve() returned %d policies, want %d", len(statuses), len(policies))
+	}
+
+	byTable := map[string]PolicyStatus{}
+	for _, s := range statuses {
+		byTable[s.Table] = s
+	}
+
+	if s := byTable["stock_count"]; s.Days != 90 || !s.Enabled {
+		t.Errorf("stock_count = %d days, enabled %v, want 90 days enabled", s.Days, s.Enabled)
+	}
+	if s := byTable["sessions"]; s.Enabled {
+		t.Error("sessions with 0 days should be disabled")
+	}
+	if s := byTable["request_notifications"]; s.Enabled || s.Invalid == "" {
+		t.Errorf("request_notifications with a malformed setting = enabled %v, invalid %q, want disabled with a reason", s.Enabled, s.Invalid)
+	}
+	if s := byTable["income_invoices"]; s.Days != 1825 || !s.Enabled {
+		t.Errorf("income_invoices without a setting = %d days, want the 1825 day default", s.Days)
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    int
+		wantErr bool
+	}{
+		{"365", 365, false},
+		{" 30 ", 30, false},
+		{"0", 0, false},
+		{"-1", 0, true},
+		{"36501", 0, true},
+		{"1.5", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDays(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDays(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseDays(%q) = %d, want %d", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestSelectTables(t *testing.T) {
+	all, err := selectTables(nil)
+	if err != nil {
+		t.Fatalf("selectTables(nil) error = %v", err)
+	}
+	if len(all) != len(policies) {
+		t.Errorf("selectTables(nil) selected %d tables, want all %d", len(all), len(policies))
+	}
+
+	some, err := selectTables([]string{"sessions"})
+	if err != nil {
+		t.Fatalf("selectTables() error = %v", err)
+	}
+	if !some["sessions"] || some["stock_count"] {
+		t.Errorf("selectTables([sessions]) = %v", some)
+	}
+
+	if _, err := selectTables([]string{"staff"}); err == nil {
+		t.Error("selectTables([staff]) error = nil, want an unknown table error")
+	}
+}
+
+func TestParseArchiveFilter(t *testing.T) {
+	filter, err := parseArchiveFilter(httptest.NewRequest("GET", "/api/v1/data/archive/stock_count?from=2024-01-01&to=2025-01-01T00:00:00Z&limit=10&offset=20", nil))
+	if err != nil {
+		t.Fatalf("parseArchiveFilter() error = %v", err)
+	}
+	if filter.From == nil || filter.From.Year() != 2024 || filter.To == nil || filter.To.Year() != 2025 {
+		t.Errorf("from = %v, to = %v", filter.From, filter.To)
+	}
+	if filter.Limit != 10 || filter.Offset != 20 {
+		t.Errorf("limit = %d, offset = %d, want 10 and 20", filter.Limit, filter.Offset)
+	}
+
+	defaults, err := parseArchiveFilter(httptest.NewRequest("GET", "/api/v1/data/archive/stock_count", nil))
+	if err != nil {
+		t.Fatalf("parseArchiveFilter() error = %v", err)
+	}
+	if defaults.From != nil || defaults.To != nil || defaults.Limit != DefaultArchiveLimit {
+		t.Errorf("defaults = %+v", defaults)
+	}
+
+	for _, query := range []string{"from=yesterday", "limit=0", "limit=501", "offset=-1"} {
+		if _, err := parseArchiveFilter(httptest.NewRequest("GET", "/api/v1/data/archive/stock_count?"+query, nil)); err == nil {
+			t.Errorf("parseArchiveFilter(%s) error = nil, want an error", query)
+		}
+	}
+}
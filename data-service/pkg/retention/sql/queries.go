@@ -0,0 +1,62 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	ListSettingsQuery                queries.Name = "list_settings"
+	GetSettingForUpdateQuery         queries.Name = "get_setting_for_update"
+	UpsertSettingQuery               queries.Name = "upsert_setting"
+	ArchiveSizesQuery                queries.Name = "archive_sizes"
+	CountStockCountQuery             queries.Name = "count_stock_count"
+	ArchiveStockCountQuery           queries.Name = "archive_stock_count"
+	CountOrderItemsQuery             queries.Name = "count_order_items"
+	ArchiveOrderItemsQuery           queries.Name = "archive_order_items"
+	CountIncomeInvoicesQuery         queries.Name = "count_income_invoices"
+	ArchiveIncomeInvoicesQuery       queries.Name = "archive_income_invoices"
+	CountOutcomeInvoicesQuery        queries.Name = "count_outcome_invoices"
+	ArchiveOutcomeInvoicesQuery      queries.Name = "archive_outcome_invoices"
+	CountRequestNotificationsQuery   queries.Name = "count_request_notifications"
+	DeleteRequestNotificationsQuery  queries.Name = "delete_request_notifications"
+	CountSessionsQuery               queries.Name = "count_sessions"
+	DeleteSessionsQuery              queries.Name = "delete_sessions"
+	ListArchivedStockCountQuery      queries.Name = "list_archived_stock_count"
+	ListArchivedOrderItemsQuery      queries.Name = "list_archived_order_items"
+	ListArchivedIncomeInvoicesQuery  queries.Name = "list_archived_income_invoices"
+	ListArchivedOutcomeInvoicesQuery queries.Name = "list_archived_outcome_invoices"
+	ListArchivedInvoiceItemsQuery    queries.Name = "list_archived_invoice_items"
+)
+
+// LoadQueries loads and validates the retention SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListSettingsQuery,
+		GetSettingForUpdateQuery,
+		UpsertSettingQuery,
+		ArchiveSizesQuery,
+		CountStockCountQuery,
+		ArchiveStockCountQuery,
+		CountOrderItemsQuery,
+		ArchiveOrderItemsQuery,
+		CountIncomeInvoicesQuery,
+		ArchiveIncomeInvoicesQuery,
+		CountOutcomeInvoicesQuery,
+		ArchiveOutcomeInvoicesQuery,
+		CountRequestNotificationsQuery,
+		DeleteRequestNotificationsQuery,
+		CountSessionsQuery,
+		DeleteSessionsQuery,
+		ListArchivedStockCountQuery,
+		ListArchivedOrderItemsQuery,
+		ListArchivedIncomeInvoicesQuery,
+		ListArchivedOutcomeInvoicesQuery,
+		ListArchivedInvoiceItemsQuery,
+	)
+}
@@ -0,0 +1,17 @@
+-- Moves one batch of issued or cancelled sales invoices into the archive, oldest first
+WITH moved AS (
+    DELETE FROM income_invoices
+    WHERE id IN (
+        SELECT id FROM income_invoices
+        WHERE status IN ('generated', 'sent', 'cancelled') AND deleted_at IS NULL AND created_at < @cutoff
+        ORDER BY created_at
+        LIMIT @batch_size
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING *
+), archived AS (
+    INSERT INTO archive.income_invoices
+    SELECT moved.*, CURRENT_TIMESTAMP FROM moved
+    RETURNING id
+)
+SELECT COUNT(*) FROM archived;
@@ -0,0 +1,19 @@
+-- Moves one batch of items of paid or cancelled orders into the archive, oldest first.
+-- The orders themselves stay live, payments and invoices reference them.
+WITH moved AS (
+    DELETE FROM order_items
+    WHERE id IN (
+        SELECT oi.id FROM order_items oi
+        JOIN orders o ON o.id = oi.order_id
+        WHERE o.status IN ('paid', 'cancelled') AND oi.requested_at < @cutoff
+        ORDER BY oi.requested_at
+        LIMIT @batch_size
+        FOR UPDATE OF oi SKIP LOCKED
+    )
+    RETURNING *
+), archived AS (
+    INSERT INTO archive.order_items
+    SELECT moved.*, CURRENT_TIMESTAMP FROM moved
+    RETURNING id
+)
+SELECT COUNT(*) FROM archived;
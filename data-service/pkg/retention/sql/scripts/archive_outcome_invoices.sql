@@ -0,0 +1,26 @@
+-- Moves one batch of purchase invoices and their items into the archive, oldest first.
+-- Invoices still referenced by a live stock count stay, the stock count policy runs first.
+WITH invoices AS (
+    DELETE FROM outcome_invoices
+    WHERE id IN (
+        SELECT oi.id FROM outcome_invoices oi
+        WHERE oi.deleted_at IS NULL AND oi.transaction_date < @cutoff
+          AND NOT EXISTS (SELECT 1 FROM stock_count sc WHERE sc.invoice_id = oi.id)
+        ORDER BY oi.transaction_date
+        LIMIT @batch_size
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING *
+), items AS (
+    DELETE FROM invoice_items
+    WHERE invoice_id IN (SELECT id FROM invoices)
+    RETURNING *
+), archived_items AS (
+    INSERT INTO archive.invoice_items
+    SELECT items.*, CURRENT_TIMESTAMP FROM items
+), archived AS (
+    INSERT INTO archive.outcome_invoices
+    SELECT invoices.*, CURRENT_TIMESTAMP FROM invoices
+    RETURNING id
+)
+SELECT COUNT(*) FROM archived;
@@ -0,0 +1,7 @@
+-- Archive tables with their estimated row counts, from the statistics collector
+SELECT s.relname AS table_name,
+       s.n_live_tup AS estimated_rows,
+       pg_total_relation_size(s.relid) AS size_bytes
+FROM pg_stat_user_tables s
+WHERE s.schemaname = 'archive'
+ORDER BY s.relname;
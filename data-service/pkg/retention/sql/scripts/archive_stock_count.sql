@@ -0,0 +1,17 @@
+-- Moves one batch of stock counts marked out into the archive, oldest first
+WITH moved AS (
+    DELETE FROM stock_count
+    WHERE id IN (
+        SELECT id FROM stock_count
+        WHERE is_out = true AND deleted_at IS NULL AND purchased_at < @cutoff
+        ORDER BY purchased_at
+        LIMIT @batch_size
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING *
+), archived AS (
+    INSERT INTO archive.stock_count
+    SELECT moved.*, CURRENT_TIMESTAMP FROM moved
+    RETURNING id
+)
+SELECT COUNT(*) FROM archived;
@@ -0,0 +1,4 @@
+-- Issued or cancelled sales invoices created before the cutoff, drafts are never archived
+SELECT COUNT(*), MIN(created_at), MAX(created_at)
+FROM income_invoices
+WHERE status IN ('generated', 'sent', 'cancelled') AND deleted_at IS NULL AND created_at < @cutoff;
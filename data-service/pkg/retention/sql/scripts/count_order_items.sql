@@ -0,0 +1,5 @@
+-- Items of paid or cancelled orders requested before the cutoff
+SELECT COUNT(*), MIN(oi.requested_at), MAX(oi.requested_at)
+FROM order_items oi
+JOIN orders o ON o.id = oi.order_id
+WHERE o.status IN ('paid', 'cancelled') AND oi.requested_at < @cutoff;
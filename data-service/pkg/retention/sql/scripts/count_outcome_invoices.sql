@@ -0,0 +1,5 @@
+-- Purchase invoices dated before the cutoff that no live stock count references
+SELECT COUNT(*), MIN(oi.transaction_date)::timestamp, MAX(oi.transaction_date)::timestamp
+FROM outcome_invoices oi
+WHERE oi.deleted_at IS NULL AND oi.transaction_date < @cutoff
+  AND NOT EXISTS (SELECT 1 FROM stock_count sc WHERE sc.invoice_id = oi.id);
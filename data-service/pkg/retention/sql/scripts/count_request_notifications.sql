@@ -0,0 +1,4 @@
+-- Completed or cancelled notifications last updated before the cutoff
+SELECT COUNT(*), MIN(updated_at), MAX(updated_at)
+FROM request_notifications
+WHERE status IN ('completed', 'cancelled') AND updated_at < @cutoff;
@@ -0,0 +1,4 @@
+-- Sessions created before the cutoff, long past any token expiration
+SELECT COUNT(*), MIN(created_at), MAX(created_at)
+FROM sessions
+WHERE created_at < @cutoff;
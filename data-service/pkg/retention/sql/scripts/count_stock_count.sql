@@ -0,0 +1,4 @@
+-- Stock counts marked out that were purchased before the cutoff
+SELECT COUNT(*), MIN(purchased_at), MAX(purchased_at)
+FROM stock_count
+WHERE is_out = true AND deleted_at IS NULL AND purchased_at < @cutoff;
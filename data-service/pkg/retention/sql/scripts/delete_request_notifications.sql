@@ -0,0 +1,13 @@
+-- Deletes one batch of completed or cancelled notifications, oldest first
+WITH deleted AS (
+    DELETE FROM request_notifications
+    WHERE id IN (
+        SELECT id FROM request_notifications
+        WHERE status IN ('completed', 'cancelled') AND updated_at < @cutoff
+        ORDER BY updated_at
+        LIMIT @batch_size
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id
+)
+SELECT COUNT(*) FROM deleted;
@@ -0,0 +1,13 @@
+-- Deletes one batch of old sessions, oldest first
+WITH deleted AS (
+    DELETE FROM sessions
+    WHERE session_id IN (
+        SELECT session_id FROM sessions
+        WHERE created_at < @cutoff
+        ORDER BY created_at
+        LIMIT @batch_size
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING session_id
+)
+SELECT COUNT(*) FROM deleted;
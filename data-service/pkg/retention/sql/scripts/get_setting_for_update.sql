@@ -0,0 +1,4 @@
+SELECT value
+FROM settings
+WHERE service = 'Data' AND key = @key
+FOR UPDATE;
@@ -0,0 +1,7 @@
+-- Archived sales invoices created in [@from, @to), newest first, with the total of the filter
+SELECT row_to_json(a)::text, COUNT(*) OVER ()
+FROM archive.income_invoices a
+WHERE (@from::timestamp IS NULL OR a.created_at >= @from)
+  AND (@to::timestamp IS NULL OR a.created_at < @to)
+ORDER BY a.created_at DESC, a.id
+LIMIT @limit OFFSET @offset;
@@ -0,0 +1,7 @@
+-- Archived purchase invoice items created in [@from, @to), newest first, with the total of the filter
+SELECT row_to_json(a)::text, COUNT(*) OVER ()
+FROM archive.invoice_items a
+WHERE (@from::timestamp IS NULL OR a.created_at >= @from)
+  AND (@to::timestamp IS NULL OR a.created_at < @to)
+ORDER BY a.created_at DESC, a.id
+LIMIT @limit OFFSET @offset;
@@ -0,0 +1,7 @@
+-- Archived order items requested in [@from, @to), newest first, with the total of the filter
+SELECT row_to_json(a)::text, COUNT(*) OVER ()
+FROM archive.order_items a
+WHERE (@from::timestamp IS NULL OR a.requested_at >= @from)
+  AND (@to::timestamp IS NULL OR a.requested_at < @to)
+ORDER BY a.requested_at DESC, a.id
+LIMIT @limit OFFSET @offset;
@@ -0,0 +1,7 @@
+-- Archived purchase invoices dated in [@from, @to), newest first, with the total of the filter
+SELECT row_to_json(a)::text, COUNT(*) OVER ()
+FROM archive.outcome_invoices a
+WHERE (@from::timestamp IS NULL OR a.transaction_date >= @from)
+  AND (@to::timestamp IS NULL OR a.transaction_date < @to)
+ORDER BY a.transaction_date DESC, a.id
+LIMIT @limit OFFSET @offset;
@@ -0,0 +1,7 @@
+-- Archived stock counts purchased in [@from, @to), newest first, with the total of the filter
+SELECT row_to_json(a)::text, COUNT(*) OVER ()
+FROM archive.stock_count a
+WHERE (@from::timestamp IS NULL OR a.purchased_at >= @from)
+  AND (@to::timestamp IS NULL OR a.purchased_at < @to)
+ORDER BY a.purchased_at DESC, a.id
+LIMIT @limit OFFSET @offset;
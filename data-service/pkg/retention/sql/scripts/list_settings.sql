@@ -0,0 +1,4 @@
+-- Retention windows of the data service, one RETENTION_<TABLE>_DAYS setting per table
+SELECT key, value
+FROM settings
+WHERE service = 'Data' AND key LIKE 'RETENTION\_%\_DAYS';
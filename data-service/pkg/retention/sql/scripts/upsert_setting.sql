@@ -0,0 +1,3 @@
+INSERT INTO settings (service, key, value, description)
+VALUES ('Data', @key, @value, @description)
+ON CONFLICT (service, key) DO UPDATE SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP;
@@ -0,0 +1,204 @@
+package seed
+
+import "time"
+
+// The static part of the generated restaurant. Category and sub-category names match
+// the defaults of 01-init-database.sql, so seeding a fresh database extends them
+// instead of duplicating them.
+
+// stockItem is a product the restaurant buys, priced per unit (kg or l)
+type stockItem struct {
+	category    string
+	subCategory string
+	name        string
+	unit        string
+	price       int64
+	// minQty and maxQty bound the quantity of one delivery at scale 1
+	minQty, maxQty float64
+}
+
+// key identifies a stock item in menu recipes and supplier lists
+func (s stockItem) key() string {
+	return s.subCategory + "/" + s.name
+}
+
+var stockCategoryDescriptions = map[string]string{
+	"Carnes":            "Res, pollo, cerdo, pescado",
+	"Frutas y Verduras": "Productos frescos",
+	"Bebidas":           "Café, té, refrescos, lácteos y licores",
+	"Salsas":            "Salsas y aderezos",
+	"Congelados":        "Productos congelados",
+	"Alacena":           "Productos de alacena",
+	"Repostería":        "Pasteles, postres, galletas",
+}
+
+var stockItems = []stockItem{
+	{"Carnes", "Res", "Filete", "kg", 9500, 4, 10},
+	{"Carnes", "Res", "Molida", "kg", 4200, 5, 12},
+	{"Carnes", "Pollo", "Pechuga", "kg", 3800, 8, 16},
+	{"Carnes", "Pollo", "Ala", "kg", 3200, 5, 10},
+	{"Carnes", "Cerdo", "Costilla Cerdo", "kg", 4500, 4, 9},
+	{"Carnes", "Cerdo", "Chicharrón", "kg", 4000, 4, 8},
+	{"Carnes", "Pescado", "Tilapia", "kg", 4800, 3, 7},
+	{"Carnes", "Pescado", "Corvina", "kg", 7500, 3, 6},
+	{"Carnes", "Mariscos", "Camarón", "kg", 11000, 2, 5},
+	{"Frutas y Verduras", "Verduras", "Tomate", "kg", 1200, 5, 10},
+	{"Frutas y Verduras", "Verduras", "Cebolla", "kg", 900, 4, 8},
+	{"Frutas y Verduras", "Verduras", "Lechuga", "kg", 1500, 2, 5},
+	{"Frutas y Verduras", "Verduras", "Papa", "kg", 800, 10, 20},
+	{"Frutas y Verduras", "Frutas", "Limón", "kg", 1000, 4, 8},
+	{"Frutas y Verduras", "Frutas", "Piña", "kg", 700, 6, 12},
+	{"Frutas y Verduras", "Hierbas", "Culantro", "kg", 3000, 0.5, 1.5},
+	{"Bebidas", "Cervezas", "Imperial", "l", 2400, 40, 80},
+	{"Bebidas", "Cervezas", "Pilsen", "l", 2400, 30, 60},
+	{"Bebidas", "Cervezas", "Heineken", "l", 3600, 15, 30},
+	{"Bebidas", "Licores", "Guaro Cacique", "l", 6500, 4, 8},
+	{"Bebidas", "Licores", "Ron", "l", 9000, 3, 6},
+	{"Bebidas", "Licores", "Tequila", "l", 18000, 2, 4},
+	{"Bebidas", "Refrescos", "Coca-Cola", "l", 1100, 20, 40},
+	{"Bebidas", "Café y Té", "Café Molido", "kg", 6000, 1, 3},
+	{"Bebidas", "Lácteos", "Leche", "l", 900, 10, 20},
+	{"Bebidas", "Lácteos", "Queso Turrialba", "kg", 5000, 2, 5},
+	{"Alacena", "Granos", "Arroz", "kg", 900, 15, 30},
+	{"Alacena", "Granos", "Frijoles Negros", "kg", 1400, 8, 15},
+	{"Alacena", "Harinas", "Harina de Trigo", "kg", 800, 5, 10},
+	{"Alacena", "Harinas", "Tortillas", "kg", 1600, 3, 6},
+	{"Alacena", "Azúcares", "Azúcar", "kg", 700, 5, 10},
+	{"Salsas", "Aceites y Vinagres", "Aceite Vegetal", "l", 2000, 8, 15},
+	{"Congelados", "Precosidos", "Papas Fritas", "kg", 1800, 10, 20},
+	{"Congelados", "Hielo", "Hielo", "kg", 300, 40, 80},
+	{"Repostería", "Chocolates", "Chocolate", "kg", 7000, 1, 3},
+	{"Repostería", "Helados", "Helado de Vainilla", "l", 3500, 4, 8},
+}
+
+// recipe is the stock a menu item uses, in portions of the stock item
+type recipe map[string]float64
+
+// menuItem is a dish or drink on the menu, priced in colones
+type menuItem struct {
+	category    string
+	subCategory string
+	itemType    string
+	name        string
+	price       int64
+	prepMinutes int
+	alcoholic   bool
+	recipe      recipe
+}
+
+var menuCategoryDescriptions = map[string]string{
+	"Entradas":       "Aperitivos y entradas",
+	"Platos Fuertes": "Platos principales",
+	"Postres":        "Dulces y postres",
+	"Bebidas":        "Cervezas, cocteles, vinos y refrescos",
+	"Bocadillos":     "Snacks de bar",
+}
+
+// menuCategoryOrder is the display order of the menu categories
+var menuCategoryOrder = []string{"Entradas", "Platos Fuertes", "Postres", "Bebidas", "Bocadillos"}
+
+var menuItems = []menuItem{
+	{"Entradas", "Ceviches", "kitchen", "Ceviche de Corvina", 5500, 10, false,
+		recipe{"Pescado/Corvina": 1, "Frutas/Limón": 0.5, "Verduras/Cebolla": 0.3, "Hierbas/Culantro": 0.1}},
+	{"Entradas", "Ceviches", "kitchen", "Ceviche de Camarón", 6500, 10, false,
+		recipe{"Mariscos/Camarón": 1, "Frutas/Limón": 0.5, "Verduras/Cebolla": 0.3, "Hierbas/Culantro": 0.1}},
+	{"Entradas", "Para Compartir", "kitchen", "Nachos", 4500, 12, false,
+		recipe{"Harinas/Tortillas": 1, "Granos/Frijoles Negros": 0.5, "Lácteos/Queso Turrialba": 0.5, "Verduras/Tomate": 0.3}},
+	{"Entradas", "Para Compartir", "kitchen", "Chifrijo", 4200, 10, false,
+		recipe{"Cerdo/Chicharrón": 1, "Granos/Frijoles Negros": 1, "Granos/Arroz": 1, "Verduras/Tomate": 0.3}},
+	{"Entradas", "Para Compartir", "kitchen", "Alitas BBQ", 5200, 18, false,
+		recipe{"Pollo/Ala": 2, "Aceites y Vinagres/Aceite Vegetal": 0.1}},
+	{"Platos Fuertes", "Carnes", "kitchen", "Lomito a la Parrilla", 9800, 25, false,
+		recipe{"Res/Filete": 2, "Verduras/Papa": 1, "Verduras/Lechuga": 0.3}},
+	{"Platos Fuertes", "Carnes", "kitchen", "Costillas BBQ", 8900, 30, false,
+		recipe{"Cerdo/Costilla Cerdo": 3, "Precosidos/Papas Fritas": 1}},
+	{"Platos Fuertes", "Aves", "kitchen", "Pollo a la Plancha", 6500, 20, false,
+		recipe{"Pollo/Pechuga": 1.5, "Granos/Arroz": 1, "Verduras/Lechuga": 0.3}},
+	{"Platos Fuertes", "Aves", "kitchen", "Casado con Pollo", 5200, 15, false,
+		recipe{"Pollo/Pechuga": 1, "Granos/Arroz": 1, "Granos/Frijoles Negros": 1, "Verduras/Tomate": 0.2}},
+	{"Platos Fuertes", "Mariscos", "kitchen", "Filete de Tilapia", 7200, 20, false,
+		recipe{"Pescado/Tilapia": 1.5, "Verduras/Papa": 1}},
+	{"Platos Fuertes", "Mariscos", "kitchen", "Arroz con Camarones", 7800, 20, false,
+		recipe{"Mariscos/Camarón": 1, "Granos/Arroz": 1.5, "Verduras/Cebolla": 0.2}},
+	{"Platos Fuertes", "Hamburguesas", "kitchen", "Hamburguesa Clásica", 5800, 15, false,
+		recipe{"Res/Molida": 1.5, "Verduras/Tomate": 0.2, "Verduras/Lechuga": 0.2, "Lácteos/Queso Turrialba": 0.3,
+			"Harinas/Harina de Trigo": 0.5, "Precosidos/Papas Fritas": 1}},
+	{"Postres", "Postres de la Casa", "kitchen", "Tres Leches", 2800, 5, false,
+		recipe{"Lácteos/Leche": 0.8, "Harinas/Harina de Trigo": 0.3, "Azúcares/Azúcar": 0.3}},
+	{"Postres", "Postres de la Casa", "kitchen", "Brownie con Helado", 3200, 8, false,
+		recipe{"Chocolates/Chocolate": 0.4, "Harinas/Harina de Trigo": 0.3, "Helados/Helado de Vainilla": 0.8}},
+	{"Bebidas", "Cervezas", "bar", "Imperial", 1800, 1, true, recipe{"Cervezas/Imperial": 2.75}},
+	{"Bebidas", "Cervezas", "bar", "Pilsen", 1800, 1, true, recipe{"Cervezas/Pilsen": 2.75}},
+	{"Bebidas", "Cervezas", "bar", "Heineken", 2400, 1, true, recipe{"Cervezas/Heineken": 2.75}},
+	{"Bebidas", "Cócteles", "bar", "Mojito", 4200, 5, true,
+		recipe{"Licores/Ron": 0.5, "Frutas/Limón": 0.3, "Azúcares/Azúcar": 0.1, "Hielo/Hielo": 1}},
+	{"Bebidas", "Cócteles", "bar", "Guaro Sour", 3200, 4, true,
+		recipe{"Licores/Guaro Cacique": 0.5, "Frutas/Limón": 0.3, "Azúcares/Azúcar": 0.1, "Hielo/Hielo": 1}},
+	{"Bebidas", "Cócteles", "bar", "Margarita", 4800, 5, true,
+		recipe{"Licores/Tequila": 0.5, "Frutas/Limón": 0.3, "Hielo/Hielo": 1}},
+	{"Bebidas", "Cócteles", "bar", "Piña Colada", 4500, 6, true,
+		recipe{"Licores/Ron": 0.5, "Frutas/Piña": 1, "Lácteos/Leche": 0.3, "Hielo/Hielo": 1}},
+	{"Bebidas", "Sin Alcohol", "bar", "Coca-Cola", 1500, 1, false, recipe{"Refrescos/Coca-Cola": 3}},
+	{"Bebidas", "Sin Alcohol", "bar", "Café Chorreado", 1400, 4, false,
+		recipe{"Café y Té/Café Molido": 0.15, "Azúcares/Azúcar": 0.05}},
+	{"Bebidas", "Sin Alcohol", "bar", "Natural de Piña", 1800, 4, false,
+		recipe{"Frutas/Piña": 1.5, "Azúcares/Azúcar": 0.1, "Hielo/Hielo": 0.5}},
+	{"Bocadillos", "Frituras", "kitchen", "Papas Fritas", 2500, 8, false,
+		recipe{"Precosidos/Papas Fritas": 2, "Aceites y Vinagres/Aceite Vegetal": 0.1}},
+	{"Bocadillos", "Frituras", "kitchen", "Chicharrones", 4800, 12, false,
+		recipe{"Cerdo/Chicharrón": 2, "Frutas/Limón": 0.2}},
+}
+
+// supplierInfo is a supplier, the stock sub-categories it sells and the weekdays it delivers
+type supplierInfo struct {
+	name          string
+	contactName   string
+	phone         string
+	email         string
+	address       string
+	creditDays    int
+	subCategories []string
+	deliveryDays  []time.Weekday
+}
+
+var suppliers = []supplierInfo{
+	{"Carnicería El Novillo", "Mario Vargas", "2222-1010", "ventas@elnovillo.cr", "Mercado Central, San José", 15,
+		[]string{"Res", "Pollo", "Cerdo"}, []time.Weekday{time.Monday, time.Thursday}},
+	{"Pescadería Puntarenas", "Lucía Mora", "2661-2020", "pedidos@pescapuntarenas.cr", "Muelle de Puntarenas", 8,
+		[]string{"Pescado", "Mariscos"}, []time.Weekday{time.Tuesday, time.Friday}},
+	{"Verdulería La Cosecha", "José Solano", "2551-3030", "lacosecha@correo.cr", "Feria del Agricultor, Cartago", 0,
+		[]string{"Verduras", "Frutas", "Hierbas"}, []time.Weekday{time.Monday, time.Wednesday, time.Friday}},
+	{"Distribuidora Florida", "Andrea Quesada", "2437-4040", "pedidos@florida.cr", "Heredia", 30,
+		[]string{"Cervezas", "Refrescos"}, []time.Weekday{time.Wednesday}},
+	{"Licorera Nacional", "Esteban Rojas", "2296-5050", "ventas@licoreranacional.cr", "La Uruca, San José", 30,
+		[]string{"Licores"}, []time.Weekday{time.Thursday}},
+	{"Abarrotes Don Chepe", "Carmen Jiménez", "2234-6060", "donchepe@correo.cr", "San Pedro, Montes de Oca", 15,
+		[]string{"Granos", "Harinas", "Azúcares", "Aceites y Vinagres", "Café y Té"}, []time.Weekday{time.Tuesday}},
+	{"Lácteos Dos Pinos", "Rodrigo Chaves", "2437-7070", "clientes@dospinos.cr", "Alajuela", 15,
+		[]string{"Lácteos", "Helados", "Chocolates"}, []time.Weekday{time.Monday, time.Thursday}},
+	{"Congelados del Valle", "Sofía Araya", "2441-8080", "ventas@congeladosdelvalle.cr", "Alajuela", 8,
+		[]string{"Precosidos", "Hielo"}, []time.Weekday{time.Tuesday, time.Saturday}},
+}
+
+// staffRoles is how many people of each role work at scale 1
+var staffRoles = []struct {
+	role  string
+	count int
+}{
+	{"waiter", 6},
+	{"bartender", 3},
+	{"chef", 3},
+	{"manager", 2},
+	{"admin", 1},
+	{"dj_karaoke_operator", 1},
+}
+
+var firstNames = []string{
+	"Ana", "Carlos", "María", "José", "Laura", "Diego", "Valeria", "Andrés", "Daniela", "Luis",
+	"Fernanda", "Pablo", "Gabriela", "Jorge", "Natalia", "Ricardo", "Paula", "Sebastián", "Camila", "Alejandro",
+}
+
+var lastNames = []string{
+	"Rodríguez", "Vargas", "Jiménez", "Mora", "Rojas", "Araya", "Solano", "Chaves", "Castro", "Quesada",
+	"Alvarado", "Hernández", "Campos", "Salazar", "Herrera", "Villalobos", "Ramírez", "Calderón", "Brenes", "Umaña",
+}
@@ -0,0 +1,216 @@
+package seed
+
+import (
+	"time"
+
+	"shared/money"
+)
+
+// Dataset is a generated restaurant, one slice per table in the order the rows are
+// inserted. Foreign keys refer to the IDs of other rows in the dataset.
+type Dataset struct {
+	StockCategories    []StockCategory
+	StockSubCategories []StockSubCategory
+	StockVariants      []StockVariant
+	MenuCategories     []MenuCategory
+	MenuSubCategories  []MenuSubCategory
+	MenuVariants       []MenuVariant
+	MenuIngredients    []MenuIngredient
+	Suppliers          []Supplier
+	OutcomeInvoices    []OutcomeInvoice
+	InvoiceItems       []InvoiceItem
+	StockCounts        []StockCount
+	Tables             []Table
+	Staff              []Staff
+	Orders             []Order
+	OrderItems         []OrderItem
+	Payments           []Payment
+	IncomeInvoices     []IncomeInvoice
+}
+
+// StockCategory is a row of stock_categories
+type StockCategory struct {
+	ID           string
+	Name         string
+	Description  string
+	DisplayOrder int
+}
+
+// StockSubCategory is a row of stock_sub_categories
+type StockSubCategory struct {
+	ID              string
+	Name            string
+	Description     string
+	StockCategoryID string
+	DisplayOrder    int
+}
+
+// StockVariant is a row of stock_variants
+type StockVariant struct {
+	ID                 string
+	Name               string
+	Description        string
+	StockSubCategoryID string
+}
+
+// MenuCategory is a row of menu_categories
+type MenuCategory struct {
+	ID           string
+	Name         string
+	Description  string
+	DisplayOrder int
+}
+
+// MenuSubCategory is a row of menu_sub_categories
+type MenuSubCategory struct {
+	ID           string
+	Name         string
+	CategoryID   string
+	ItemType     string
+	DisplayOrder int
+}
+
+// MenuVariant is a row of menu_variants
+type MenuVariant struct {
+	ID              string
+	Name            string
+	SubCategoryID   string
+	Price           money.Money
+	PreparationTime int
+	IsAlcoholic     bool
+	DisplayOrder    int
+}
+
+// MenuIngredient is a row of menu_ingredients, quantity is in portions of the stock variant
+type MenuIngredient struct {
+	ID             string
+	MenuVariantID  string
+	StockVariantID string
+	Quantity       money.Decimal
+}
+
+// Supplier is a row of suppliers
+type Supplier struct {
+	ID          string
+	Name        string
+	ContactName string
+	Phone       string
+	Email       string
+	Address     string
+}
+
+// OutcomeInvoice is a row of outcome_invoices, a purchase from a supplier
+type OutcomeInvoice struct {
+	ID              string
+	InvoiceNumber   string
+	SupplierID      string
+	TransactionDate time.Time
+	DueDate         time.Time
+	Subtotal        money.Money
+	TaxAmount       money.Money
+	TotalAmount     money.Money
+}
+
+// InvoiceItem is a row of invoice_items, a line of an outcome invoice
+type InvoiceItem struct {
+	ID             string
+	InvoiceID      string
+	StockVariantID string
+	Detail         string
+	Count          money.Decimal
+	UnitType       string
+	Price          money.Money
+	Total          money.Money
+}
+
+// StockCount is a row of stock_count, the stock an invoice item brought in
+type StockCount struct {
+	ID             string
+	StockVariantID string
+	InvoiceID      string
+	Count          money.Decimal
+	Unit           string
+	UnitPrice      money.Money
+	CostPerPortion money.Money
+	PurchasedAt    time.Time
+	IsOut          bool
+}
+
+// Table is a row of tables
+type Table struct {
+	ID          string
+	TableNumber string
+	Capacity    int
+}
+
+// Staff is a row of staff
+type Staff struct {
+	ID        string
+	Username  string
+	Email     string
+	FirstName string
+	LastName  string
+	Role      string
+}
+
+// Order is a row of orders
+type Order struct {
+	ID                 string
+	OrderNumber        string
+	TableID            string
+	Status             string
+	PaymentStatus      string
+	TaxAmount          money.Money
+	ServiceCharge      money.Money
+	TotalAmount        money.Money
+	CreatedAt          time.Time
+	ConfirmedAt        *time.Time
+	PaymentRequestedAt *time.Time
+	PaidAt             *time.Time
+}
+
+// OrderItem is a row of order_items
+type OrderItem struct {
+	ID            string
+	OrderID       string
+	MenuVariantID string
+	Quantity      int
+	UnitPrice     money.Money
+	Subtotal      money.Money
+	Status        string
+	OrderType     string
+	LostReason    *string
+	FaultType     *string
+	RequestedAt   time.Time
+	PreparingAt   *time.Time
+	ReadyAt       *time.Time
+	DeliveredAt   *time.Time
+	LostAt        *time.Time
+}
+
+// Payment is a row of payments
+type Payment struct {
+	ID                 string
+	OrderID            string
+	Amount             money.Money
+	PaymentMethod      string
+	CashAmountProvided *money.Money
+	TipAmount          money.Money
+	ProcessedByStaffID string
+	TransactionID      *string
+	ProcessedAt        time.Time
+}
+
+// IncomeInvoice is a row of income_invoices, the electronic invoice of a paid order
+type IncomeInvoice struct {
+	ID            string
+	OrderID       string
+	PaymentID     string
+	InvoiceNumber string
+	Subtotal      money.Money
+	TaxAmount     money.Money
+	ServiceCharge money.Money
+	TotalAmount   money.Money
+	PaymentMethod string
+	GeneratedAt   time.Time
+}
@@ -0,0 +1,600 @@
+package seed
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"shared/money"
+)
+
+const (
+	// DefaultScale generates a small restaurant
+	DefaultScale = 1
+	// MaxScale bounds the scale factor, scale 100 is already millions of rows
+	MaxScale = 100
+	// DefaultWeeks is how many weeks of history are generated by default
+	DefaultWeeks = 4
+	// MaxWeeks bounds the history to two years
+	MaxWeeks = 104
+
+	// PortionGrams is the portion stock costs are split into, the DEFAULT_PORTION_GRAMS
+	// of inventory-service
+	PortionGrams = 120
+	// TaxRate and ServiceRate are the DEFAULT_TAX_RATE and DEFAULT_SERVICE_RATE settings
+	TaxRate     = 13
+	ServiceRate = 10
+
+	// ordersPerDay is the orders of an average day at scale 1
+	ordersPerDay = 30
+)
+
+// dayFactor is how busy each weekday is compared to an average day
+var dayFactor = map[time.Weekday]float64{
+	time.Monday:    0.7,
+	time.Tuesday:   0.8,
+	time.Wednesday: 0.9,
+	time.Thursday:  1.0,
+	time.Friday:    1.4,
+	time.Saturday:  1.6,
+	time.Sunday:    1.1,
+}
+
+var paymentMethods = []struct {
+	method string
+	weight float64
+}{
+	{"cash", 0.35},
+	{"credit_card", 0.35},
+	{"debit_card", 0.20},
+	{"apple_pay", 0.05},
+	{"google_pay", 0.05},
+}
+
+// Options size the generated restaurant
+type Options struct {
+	// Seed makes the output reproducible, the same options always generate the same rows
+	Seed int64
+	// Scale multiplies the tables, staff, purchases and daily orders of a small restaurant
+	Scale int
+	// Weeks is how many weeks of purchases and orders are generated
+	Weeks int
+	// End is the day the history ends on, exclusive. Only its date is used.
+	End time.Time
+}
+
+// Validate checks the options are within bounds
+func (o Options) Validate() error {
+	if o.Scale < 1 || o.Scale > MaxScale {
+		return fmt.Errorf("scale must be between 1 and %d", MaxScale)
+	}
+	if o.Weeks < 1 || o.Weeks > MaxWeeks {
+		return fmt.Errorf("weeks must be between 1 and %d", MaxWeeks)
+	}
+	if o.End.IsZero() {
+		return fmt.Errorf("end date is required")
+	}
+	return nil
+}
+
+// menuEntry is a generated menu variant orders can pick
+type menuEntry struct {
+	id          string
+	price       money.Money
+	prepMinutes int
+}
+
+// generator holds the state of one Generate call
+type generator struct {
+	opts Options
+	rng  *rand.Rand
+	ds   *Dataset
+
+	stockIDs   map[string]string
+	menu       []menuEntry
+	popularity []float64
+	tableIDs   []string
+	waiterIDs  []string
+}
+
+// Generate builds a restaurant from opts. The result depends only on opts: the same
+// seed, scale, weeks and end date always produce the same rows and IDs.
+func Generate(opts Options) (*Dataset, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		opts:     opts,
+		rng:      rand.New(rand.NewSource(opts.Seed)),
+		ds:       &Dataset{},
+		stockIDs: map[string]string{},
+	}
+
+	g.stock()
+	g.menuTree()
+	g.tables()
+	g.staff()
+	g.purchases()
+	g.orders()
+
+	return g.ds, nil
+}
+
+// catalogID returns the ID of a catalog row. It does not depend on the seed, so every
+// run reuses the same menu, stock, suppliers, tables and staff.
+func catalogID(parts ...string) string {
+	return uuidFrom(append([]string{"catalog"}, parts...))
+}
+
+// id returns the ID of a row generated from the seed
+func (g *generator) id(parts ...string) string {
+	return uuidFrom(append([]string{fmt.Sprint(g.opts.Seed)}, parts...))
+}
+
+// uuidFrom derives a version 5 style UUID from parts
+func uuidFrom(parts []string) string {
+	sum := sha1.Sum([]byte("barrest-seed/" + strings.Join(parts, "/")))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// number formats the invoice or order number n of a kind, prefixed with the seed so
+// datasets of different seeds do not collide
+func (g *generator) number(kind string, n int) string {
+	return fmt.Sprintf("DEMO%d-%s-%06d", g.opts.Seed, kind, n)
+}
+
+// between returns a random float in [min, max)
+func (g *generator) between(min, max float64) float64 {
+	return min + g.rng.Float64()*(max-min)
+}
+
+// pick returns an index of a cumulative weight list, each chosen with probability
+// proportional to its weight
+func (g *generator) pick(cumulative []float64) int {
+	return sort.SearchFloat64s(cumulative, g.rng.Float64()*cumulative[len(cumulative)-1])
+}
+
+// stock generates the stock categories, sub-categories and variants
+func (g *generator) stock() {
+	categories := map[string]string{}
+	subCategories := map[string]string{}
+
+	for _, item := range stockItems {
+		categoryID, ok := categories[item.category]
+		if !ok {
+			categoryID = catalogID("stock_category", item.category)
+			categories[item.category] = categoryID
+			g.ds.StockCategories = append(g.ds.StockCategories, StockCategory{
+				ID:           categoryID,
+				Name:         item.category,
+				Description:  stockCategoryDescriptions[item.category],
+				DisplayOrder: len(g.ds.StockCategories) + 1,
+			})
+		}
+
+		subCategoryID, ok := subCategories[item.subCategory]
+		if !ok {
+			subCategoryID = catalogID("stock_sub_category", item.category, item.subCategory)
+			subCategories[item.subCategory] = subCategoryID
+			g.ds.StockSubCategories = append(g.ds.StockSubCategories, StockSubCategory{
+				ID:              subCategoryID,
+				Name:            item.subCategory,
+				StockCategoryID: categoryID,
+				DisplayOrder:    len(g.ds.StockSubCategories) + 1,
+			})
+		}
+
+		variantID := catalogID("stock_variant", item.category, item.key())
+		g.stockIDs[item.key()] = variantID
+		g.ds.StockVariants = append(g.ds.StockVariants, StockVariant{
+			ID:                 variantID,
+			Name:               item.name,
+			Description:        fmt.Sprintf("%s (%s)", item.name, item.unit),
+			StockSubCategoryID: subCategoryID,
+		})
+	}
+}
+
+// menuTree generates the menu categories, sub-categories, variants and their recipes.
+// Prices vary a little with the seed and each variant gets a popularity that orders
+// follow, so menu reports have winners and losers.
+func (g *generator) menuTree() {
+	categories := map[string]string{}
+	for i, name := range menuCategoryOrder {
+		categories[name] = catalogID("menu_category", name)
+		g.ds.MenuCategories = append(g.ds.MenuCategories, MenuCategory{
+			ID:           categories[name],
+			Name:         name,
+			Description:  menuCategoryDescriptions[name],
+			DisplayOrder: i + 1,
+		})
+	}
+
+	subCategories := map[string]string{}
+	cumulative := 0.0
+	for _, item := range menuItems {
+		subKey := item.category + "/" + item.subCategory
+		subCategoryID, ok := subCategories[subKey]
+		if !ok {
+			subCategoryID = catalogID("menu_sub_category", subKey)
+			subCategories[subKey] = subCategoryID
+			g.ds.MenuSubCategories = append(g.ds.MenuSubCategories, MenuSubCategory{
+				ID:           subCategoryID,
+				Name:         item.subCategory,
+				CategoryID:   categories[item.category],
+				ItemType:     item.itemType,
+				DisplayOrder: len(subCategories),
+			})
+		}
+
+		// Prices end in 00 like a printed menu
+		price := money.FromInt(int64(math.Round(float64(item.price)*g.between(0.95, 1.05)/100) * 100))
+		variantID := catalogID("menu_variant", subKey, item.name)
+		g.ds.MenuVariants = append(g.ds.MenuVariants, MenuVariant{
+			ID:              variantID,
+			Name:            item.name,
+			SubCategoryID:   subCategoryID,
+			Price:           price,
+			PreparationTime: item.prepMinutes,
+			IsAlcoholic:     item.alcoholic,
+			DisplayOrder:    len(g.ds.MenuVariants) + 1,
+		})
+
+		stockKeys := make([]string, 0, len(item.recipe))
+		for key := range item.recipe {
+			stockKeys = append(stockKeys, key)
+		}
+		sort.Strings(stockKeys)
+		for _, key := range stockKeys {
+			stockID, ok := g.stockIDs[key]
+			if !ok {
+				panic(fmt.Sprintf("seed: menu item %q uses unknown stock item %q", item.name, key))
+			}
+			g.ds.MenuIngredients = append(g.ds.MenuIngredients, MenuIngredient{
+				ID:             catalogID("menu_ingredient", subKey, item.name, key),
+				MenuVariantID:  variantID,
+				StockVariantID: stockID,
+				Quantity:       money.DecimalFromFloat(item.recipe[key]),
+			})
+		}
+
+		cumulative += 0.2 + 2.8*math.Pow(g.rng.Float64(), 2)
+		g.popularity = append(g.popularity, cumulative)
+		g.menu = append(g.menu, menuEntry{id: variantID, price: price, prepMinutes: item.prepMinutes})
+	}
+}
+
+// tables generates the dining tables and bar seats
+func (g *generator) tables() {
+	capacities := []int{2, 4, 4, 6}
+	dining := 10 * g.opts.Scale
+	for i := 1; i <= dining; i++ {
+		g.addTable(fmt.Sprintf("M%d", i), capacities[i%len(capacities)])
+	}
+	for i := 1; i <= 4*g.opts.Scale; i++ {
+		g.addTable(fmt.Sprintf("BARRA%d", i), 2)
+	}
+}
+
+func (g *generator) addTable(number string, capacity int) {
+	id := catalogID("table", number)
+	g.tableIDs = append(g.tableIDs, id)
+	g.ds.Tables = append(g.ds.Tables, Table{ID: id, TableNumber: number, Capacity: capacity})
+}
+
+// usernameReplacer turns names into plain ASCII usernames
+var usernameReplacer = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ñ", "n", " ", "")
+
+// staff generates people of every role
+func (g *generator) staff() {
+	taken := map[string]bool{}
+	for _, role := range staffRoles {
+		for i := 0; i < role.count*g.opts.Scale; i++ {
+			firstName := firstNames[g.rng.Intn(len(firstNames))]
+			lastName := lastNames[g.rng.Intn(len(lastNames))]
+
+			base := usernameReplacer.Replace(strings.ToLower(firstName + "." + lastName))
+			username := base
+			for n := 2; taken[username]; n++ {
+				username = fmt.Sprintf("%s%d", base, n)
+			}
+			taken[username] = true
+
+			id := catalogID("staff", username)
+			if role.role == "waiter" {
+				g.waiterIDs = append(g.waiterIDs, id)
+			}
+			g.ds.Staff = append(g.ds.Staff, Staff{
+				ID:        id,
+				Username:  username,
+				Email:     username + "@demo.barrest.com",
+				FirstName: firstName,
+				LastName:  lastName,
+				Role:      role.role,
+			})
+		}
+	}
+}
+
+// days returns the first day of the history and how many days it covers
+func (g *generator) days() (time.Time, int) {
+	end := time.Date(g.opts.End.Year(), g.opts.End.Month(), g.opts.End.Day(), 0, 0, 0, 0, time.UTC)
+	days := g.opts.Weeks * 7
+	return end.AddDate(0, 0, -days), days
+}
+
+// purchases generates the suppliers and their deliveries: an outcome invoice per
+// delivery, its items and the stock counts they bring in. Only the latest purchase of
+// each stock variant is still in stock.
+func (g *generator) purchases() {
+	itemsBySubCategory := map[string][]stockItem{}
+	for _, item := range stockItems {
+		itemsBySubCategory[item.subCategory] = append(itemsBySubCategory[item.subCategory], item)
+	}
+
+	supplierIDs := make([]string, len(suppliers))
+	for i, s := range suppliers {
+		supplierIDs[i] = catalogID("supplier", s.name)
+		g.ds.Suppliers = append(g.ds.Suppliers, Supplier{
+			ID:          supplierIDs[i],
+			Name:        s.name,
+			ContactName: s.contactName,
+			Phone:       s.phone,
+			Email:       s.email,
+			Address:     s.address,
+		})
+	}
+
+	latest := map[string]int{}
+	start, days := g.days()
+	for day := 0; day < days; day++ {
+		date := start.AddDate(0, 0, day)
+		for i, s := range suppliers {
+			if !delivers(s, date.Weekday()) {
+				continue
+			}
+
+			var items []stockItem
+			for _, sub := range s.subCategories {
+				for _, item := range itemsBySubCategory[sub] {
+					if g.rng.Float64() < 0.75 {
+						items = append(items, item)
+					}
+				}
+			}
+			if len(items) == 0 {
+				items = itemsBySubCategory[s.subCategories[0]][:1]
+			}
+
+			n := len(g.ds.OutcomeInvoices) + 1
+			invoiceID := g.id("outcome_invoice", fmt.Sprint(n))
+			purchasedAt := date.Add(7*time.Hour + time.Duration(g.rng.Intn(180))*time.Minute)
+			subtotal := money.Money{}
+
+			for _, item := range items {
+				// Quantities come in half units, prices move up to 10% between deliveries
+				quantity := math.Max(0.5, math.Round(g.between(item.minQty, item.maxQty)*float64(g.opts.Scale)*2)/2)
+				count := money.DecimalFromFloat(quantity)
+				price := money.FromInt(int64(math.Round(float64(item.price) * g.between(0.9, 1.1))))
+				total := price.Mul(count).RoundTo(money.StoragePlaces)
+				subtotal = subtotal.Add(total)
+
+				itemID := g.id("invoice_item", fmt.Sprint(n), item.key())
+				g.ds.InvoiceItems = append(g.ds.InvoiceItems, InvoiceItem{
+					ID:             itemID,
+					InvoiceID:      invoiceID,
+					StockVariantID: g.stockIDs[item.key()],
+					Detail:         item.name,
+					Count:          count,
+					UnitType:       item.unit,
+					Price:          price,
+					Total:          total,
+				})
+
+				// kg and l both count as kilograms, the conversion inventory-service uses
+				costPerPortion, _ := total.Mul(money.DecimalFromInt(PortionGrams)).Div(money.DecimalFromFloat(quantity * 1000))
+				latest[item.key()] = len(g.ds.StockCounts)
+				g.ds.StockCounts = append(g.ds.StockCounts, StockCount{
+					ID:             g.id("stock_count", fmt.Sprint(n), item.key()),
+					StockVariantID: g.stockIDs[item.key()],
+					InvoiceID:      invoiceID,
+					Count:          count,
+					Unit:           item.unit,
+					UnitPrice:      total,
+					CostPerPortion: costPerPortion.RoundTo(money.StoragePlaces),
+					PurchasedAt:    purchasedAt,
+					IsOut:          true,
+				})
+			}
+
+			tax := subtotal.Percent(money.DecimalFromInt(TaxRate)).Round()
+			g.ds.OutcomeInvoices = append(g.ds.OutcomeInvoices, OutcomeInvoice{
+				ID:              invoiceID,
+				InvoiceNumber:   g.number("PRV", n),
+				SupplierID:      supplierIDs[i],
+				TransactionDate: date,
+				DueDate:         date.AddDate(0, 0, s.creditDays),
+				Subtotal:        subtotal,
+				TaxAmount:       tax,
+				TotalAmount:     subtotal.Add(tax),
+			})
+		}
+	}
+
+	for _, i := range latest {
+		g.ds.StockCounts[i].IsOut = false
+	}
+}
+
+func delivers(s supplierInfo, weekday time.Weekday) bool {
+	for _, d := range s.deliveryDays {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// orders generates the orders of every day, busier on weekends, split between lunch
+// and dinner. Paid orders get a payment and an income invoice, a few are cancelled.
+func (g *generator) orders() {
+	cumulativeMethods := make([]float64, len(paymentMethods))
+	total := 0.0
+	for i, m := range paymentMethods {
+		total += m.weight
+		cumulativeMethods[i] = total
+	}
+
+	start, days := g.days()
+	for day := 0; day < days; day++ {
+		date := start.AddDate(0, 0, day)
+		count := int(math.Round(ordersPerDay * float64(g.opts.Scale) * dayFactor[date.Weekday()] * g.between(0.85, 1.15)))
+
+		times := make([]time.Time, count)
+		for i := range times {
+			if g.rng.Float64() < 0.45 {
+				times[i] = date.Add(11*time.Hour + 30*time.Minute + time.Duration(g.rng.Intn(210))*time.Minute)
+			} else {
+				times[i] = date.Add(18*time.Hour + time.Duration(g.rng.Intn(330))*time.Minute)
+			}
+		}
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+		for _, at := range times {
+			g.order(at, cumulativeMethods)
+		}
+	}
+}
+
+// order generates one order placed at the given time
+func (g *generator) order(at time.Time, cumulativeMethods []float64) {
+	n := len(g.ds.Orders) + 1
+	orderID := g.id("order", fmt.Sprint(n))
+	cancelled := g.rng.Float64() < 0.03
+
+	lines := 1 + g.rng.Intn(4)
+	chosen := map[int]bool{}
+	subtotal := money.Money{}
+	lastDelivery := at
+	orderType := "for_here"
+	if g.rng.Float64() < 0.1 {
+		orderType = "to_go"
+	}
+
+	for len(chosen) < lines {
+		index := g.pick(g.popularity)
+		if chosen[index] {
+			continue
+		}
+		chosen[index] = true
+		entry := g.menu[index]
+
+		quantity := 1
+		if r := g.rng.Float64(); r > 0.95 {
+			quantity = 3
+		} else if r > 0.7 {
+			quantity = 2
+		}
+		lineTotal := entry.price.Mul(money.DecimalFromInt(int64(quantity)))
+		subtotal = subtotal.Add(lineTotal)
+
+		item := OrderItem{
+			ID:            g.id("order_item", fmt.Sprint(n), fmt.Sprint(len(chosen))),
+			OrderID:       orderID,
+			MenuVariantID: entry.id,
+			Quantity:      quantity,
+			UnitPrice:     entry.price,
+			Subtotal:      lineTotal,
+			OrderType:     orderType,
+			RequestedAt:   at,
+		}
+		if cancelled {
+			lostAt := at.Add(5 * time.Minute)
+			reason, fault := "Orden cancelada por el cliente", "customer_fault"
+			item.Status, item.LostAt, item.LostReason, item.FaultType = "lost", &lostAt, &reason, &fault
+		} else {
+			preparingAt := at.Add(2 * time.Minute)
+			readyAt := preparingAt.Add(time.Duration(entry.prepMinutes+g.rng.Intn(entry.prepMinutes/2+2)) * time.Minute)
+			deliveredAt := readyAt.Add(2 * time.Minute)
+			item.Status, item.PreparingAt, item.ReadyAt, item.DeliveredAt = "delivered", &preparingAt, &readyAt, &deliveredAt
+			if deliveredAt.After(lastDelivery) {
+				lastDelivery = deliveredAt
+			}
+		}
+		g.ds.OrderItems = append(g.ds.OrderItems, item)
+	}
+
+	tax := subtotal.Percent(money.DecimalFromInt(TaxRate)).Round()
+	service := money.Money{}
+	if orderType == "for_here" {
+		service = subtotal.Percent(money.DecimalFromInt(ServiceRate)).Round()
+	}
+	order := Order{
+		ID:            orderID,
+		OrderNumber:   g.number("ORD", n),
+		TableID:       g.tableIDs[g.rng.Intn(len(g.tableIDs))],
+		TaxAmount:     tax,
+		ServiceCharge: service,
+		TotalAmount:   subtotal.Add(tax).Add(service),
+		CreatedAt:     at,
+	}
+	confirmedAt := at.Add(time.Minute)
+	order.ConfirmedAt = &confirmedAt
+
+	if cancelled {
+		order.Status, order.PaymentStatus = "cancelled", "unpaid"
+		g.ds.Orders = append(g.ds.Orders, order)
+		return
+	}
+
+	requestedAt := lastDelivery.Add(time.Duration(30+g.rng.Intn(50)) * time.Minute)
+	paidAt := requestedAt.Add(time.Duration(2+g.rng.Intn(7)) * time.Minute)
+	order.Status, order.PaymentStatus = "paid", "paid"
+	order.PaymentRequestedAt, order.PaidAt = &requestedAt, &paidAt
+	g.ds.Orders = append(g.ds.Orders, order)
+
+	method := paymentMethods[g.pick(cumulativeMethods)].method
+	payment := Payment{
+		ID:                 g.id("payment", fmt.Sprint(n)),
+		OrderID:            orderID,
+		Amount:             order.TotalAmount,
+		PaymentMethod:      method,
+		ProcessedByStaffID: g.waiterIDs[g.rng.Intn(len(g.waiterIDs))],
+		ProcessedAt:        paidAt,
+	}
+	if g.rng.Float64() < 0.3 {
+		// Tips are rounded to ₡100
+		tip := subtotal.Percent(money.DecimalFromFloat(g.between(5, 10))).Amount().Float64()
+		payment.TipAmount = money.FromInt(int64(math.Round(tip/100) * 100))
+	}
+	if method == "cash" {
+		// Cash is paid with the next ₡5000 bill
+		due := payment.Amount.Add(payment.TipAmount).Amount().Float64()
+		provided := money.FromInt(int64(math.Ceil(due/5000) * 5000))
+		payment.CashAmountProvided = &provided
+	} else {
+		transactionID := fmt.Sprintf("TXN%012d", g.rng.Int63n(1_000_000_000_000))
+		payment.TransactionID = &transactionID
+	}
+	g.ds.Payments = append(g.ds.Payments, payment)
+
+	g.ds.IncomeInvoices = append(g.ds.IncomeInvoices, IncomeInvoice{
+		ID:            g.id("income_invoice", fmt.Sprint(n)),
+		OrderID:       orderID,
+		PaymentID:     payment.ID,
+		InvoiceNumber: g.number("FE", n),
+		Subtotal:      subtotal,
+		TaxAmount:     tax,
+		ServiceCharge: service,
+		TotalAmount:   order.TotalAmount,
+		PaymentMethod: method,
+		GeneratedAt:   paidAt,
+	})
+}
@@ -0,0 +1,144 @@
+package seed
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"shared/money"
+)
+
+func testOptions() Options {
+	return Options{Seed: 42, Scale: 1, Weeks: 2, End: time.Date(2026, 3, 2, 15, 4, 5, 0, time.UTC)}
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	first, err := Generate(testOptions())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	second, err := Generate(testOptions())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Error("Generate() with the same options returned different datasets")
+	}
+
+	opts := testOptions()
+	opts.Seed = 7
+	other, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if reflect.DeepEqual(first.Orders, other.Orders) {
+		t.Error("Generate() with another seed returned the same orders")
+	}
+	// The catalog is shared by every seed so reruns extend the same menu
+	if first.MenuVariants[0].ID != other.MenuVariants[0].ID {
+		t.Error("menu variant IDs depend on the seed")
+	}
+	if first.Orders[0].ID == other.Orders[0].ID {
+		t.Error("order IDs do not depend on the seed")
+	}
+}
+
+func TestGenerate_Scale(t *testing.T) {
+	small, err := Generate(testOptions())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	opts := testOptions()
+	opts.Scale = 3
+	large, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if len(large.Orders) < 2*len(small.Orders) {
+		t.Errorf("scale 3 generated %d orders, scale 1 %d", len(large.Orders), len(small.Orders))
+	}
+	if len(large.Tables) != 3*len(small.Tables) || len(large.Staff) != 3*len(small.Staff) {
+		t.Errorf("scale 3 generated %d tables and %d staff, want three times %d and %d",
+			len(large.Tables), len(large.Staff), len(small.Tables), len(small.Staff))
+	}
+	if len(large.MenuVariants) != len(small.MenuVariants) {
+		t.Error("the menu should not grow with the scale")
+	}
+}
+
+func TestGenerate_Consistent(t *testing.T) {
+	ds, err := Generate(testOptions())
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	start := time.Date(2026, 2, 16, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	roles := map[string]bool{}
+	for _, s := range ds.Staff {
+		roles[s.Role] = true
+	}
+	if len(roles) != len(staffRoles) {
+		t.Errorf("staff covers %d roles, want %d", len(roles), len(staffRoles))
+	}
+
+	subtotals := map[string]money.Money{}
+	for _, item := range ds.OrderItems {
+		subtotals[item.OrderID] = subtotals[item.OrderID].Add(item.Subtotal)
+	}
+	paid := 0
+	for _, o := range ds.Orders {
+		if o.CreatedAt.Before(start) || !o.CreatedAt.Before(end) {
+			t.Fatalf("order %s created at %v, outside [%v, %v)", o.OrderNumber, o.CreatedAt, start, end)
+		}
+		want := subtotals[o.ID].Add(o.TaxAmount).Add(o.ServiceCharge)
+		if !o.TotalAmount.Equal(want) {
+			t.Fatalf("order %s total = %s, want %s", o.OrderNumber, o.TotalAmount, want)
+		}
+		if o.Status == "paid" {
+			paid++
+		}
+	}
+	if paid != len(ds.Payments) || paid != len(ds.IncomeInvoices) {
+		t.Errorf("%d paid orders, %d payments and %d income invoices", paid, len(ds.Payments), len(ds.IncomeInvoices))
+	}
+
+	inStock := map[string]int{}
+	for _, sc := range ds.StockCounts {
+		if !sc.IsOut {
+			inStock[sc.StockVariantID]++
+		}
+	}
+	for variantID, n := range inStock {
+		if n != 1 {
+			t.Errorf("stock variant %s has %d stock counts in stock, want 1", variantID, n)
+		}
+	}
+	if len(ds.StockCounts) != len(ds.InvoiceItems) {
+		t.Errorf("%d stock counts for %d invoice items", len(ds.StockCounts), len(ds.InvoiceItems))
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Options)
+	}{
+		{"zero scale", func(o *Options) { o.Scale = 0 }},
+		{"scale too large", func(o *Options) { o.Scale = MaxScale + 1 }},
+		{"zero weeks", func(o *Options) { o.Weeks = 0 }},
+		{"no end date", func(o *Options) { o.End = time.Time{} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := testOptions()
+			tt.modify(&opts)
+			if _, err := Generate(opts); err == nil {
+				t.Error("Generate() error = nil, want an error")
+			}
+		})
+	}
+}
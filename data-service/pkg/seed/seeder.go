@@ -0,0 +1,495 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+
+	seedSQL "data-service/pkg/seed/sql"
+	sharedDb "shared/db"
+	"shared/db/queries"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TableSummary counts the rows a seeding run wrote to a table and the rows it found
+// already there, from an earlier run or the defaults of 01-init-database.sql
+type TableSummary struct {
+	Table    string `json:"table"`
+	Inserted int    `json:"inserted"`
+	Existing int    `json:"existing"`
+}
+
+// Seeder writes generated datasets to the database
+type Seeder struct {
+	db      *sharedDb.DbHandler
+	queries *queries.Registry
+	logger  *logrus.Logger
+}
+
+// NewSeeder creates a new seeder
+func NewSeeder(db *sharedDb.DbHandler, logger *logrus.Logger) (*Seeder, error) {
+	queries, err := seedSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &Seeder{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}, nil
+}
+
+// seedRun holds the state of one Seed call
+type seedRun struct {
+	*Seeder
+	ds       *Dataset
+	password string
+
+	// ids maps generated catalog IDs to the IDs of the rows they matched by name
+	ids map[string]string
+	// created holds the generated catalog IDs this run inserted
+	created   map[string]bool
+	summaries []*TableSummary
+}
+
+// Seed writes ds in a single transaction. Catalog rows (categories, variants, suppliers,
+// tables and staff) are matched by name and reused when they exist; the rest is keyed
+// by the generated IDs, so seeding the same dataset twice inserts nothing the second
+// time. New staff members log in with password.
+func (s *Seeder) Seed(ctx context.Context, ds *Dataset, password string) ([]TableSummary, error) {
+	var run *seedRun
+	err := s.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		// A retried transaction starts over with a fresh run
+		run = &seedRun{Seeder: s, ds: ds, password: password, ids: map[string]string{}, created: map[string]bool{}}
+		for _, step := range []func(context.Context) error{run.stock, run.menu, run.purchases, run.costs, run.floor, run.orders} {
+			if err := step(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]TableSummary, len(run.summaries))
+	for i, summary := range run.summaries {
+		summaries[i] = *summary
+	}
+	return summaries, nil
+}
+
+// table starts the summary of a table
+func (r *seedRun) table(name string) *TableSummary {
+	summary := &TableSummary{Table: name}
+	r.summaries = append(r.summaries, summary)
+	return summary
+}
+
+// done logs the summary of a table
+func (r *seedRun) done(summary *TableSummary) {
+	r.logger.WithFields(logrus.Fields{
+		"table":    summary.Table,
+		"inserted": summary.Inserted,
+		"existing": summary.Existing,
+	}).Info("Seeded table")
+}
+
+// ref returns the ID a generated ID was matched to
+func (r *seedRun) ref(id string) string {
+	if actual, ok := r.ids[id]; ok {
+		return actual
+	}
+	return id
+}
+
+// upsert inserts a catalog row or finds the live row with the same name, remembering
+// which ID the generated one maps to
+func (r *seedRun) upsert(ctx context.Context, summary *TableSummary, name queries.Name, id string, args queries.Args) error {
+	args["id"] = id
+
+	var actualID string
+	var inserted bool
+	if err := r.db.QueryRowNamedContext(ctx, r.queries.Get(name), args).Scan(&actualID, &inserted); err != nil {
+		return fmt.Errorf("failed to seed %s: %w", summary.Table, err)
+	}
+
+	r.ids[id] = actualID
+	if inserted {
+		r.created[id] = true
+		summary.Inserted++
+	} else {
+		summary.Existing++
+	}
+	return nil
+}
+
+// insert inserts a row unless its ID already exists
+func (r *seedRun) insert(ctx context.Context, summary *TableSummary, name queries.Name, args queries.Args) error {
+	result, err := r.db.ExecNamedContext(ctx, r.queries.Get(name), args)
+	if err != nil {
+		return fmt.Errorf("failed to seed %s: %w", summary.Table, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to seed %s: %w", summary.Table, err)
+	}
+	if affected > 0 {
+		summary.Inserted++
+	} else {
+		summary.Existing++
+	}
+	return nil
+}
+
+// nullString returns nil for an empty string so it is stored as NULL
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// stock seeds the stock categories, sub-categories and variants
+func (r *seedRun) stock(ctx context.Context) error {
+	summary := r.table("stock_categories")
+	for _, c := range r.ds.StockCategories {
+		err := r.upsert(ctx, summary, seedSQL.UpsertStockCategoryQuery, c.ID, queries.Args{
+			"name":          c.Name,
+			"description":   nullString(c.Description),
+			"display_order": c.DisplayOrder,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	summary = r.table("stock_sub_categories")
+	for _, c := range r.ds.StockSubCategories {
+		err := r.upsert(ctx, summary, seedSQL.UpsertStockSubCategoryQuery, c.ID, queries.Args{
+			"name":              c.Name,
+			"description":       nullString(c.Description),
+			"stock_category_id": r.ref(c.StockCategoryID),
+			"display_order":     c.DisplayOrder,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	summary = r.table("stock_variants")
+	for _, v := range r.ds.StockVariants {
+		err := r.upsert(ctx, summary, seedSQL.UpsertStockVariantQuery, v.ID, queries.Args{
+			"name":                  v.Name,
+			"description":           nullString(v.Description),
+			"stock_sub_category_id": r.ref(v.StockSubCategoryID),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+	return nil
+}
+
+// menu seeds the menu categories, sub-categories and variants. Recipes are only added
+// to the variants this run created, menu items that already existed keep theirs.
+func (r *seedRun) menu(ctx context.Context) error {
+	summary := r.table("menu_categories")
+	for _, c := range r.ds.MenuCategories {
+		err := r.upsert(ctx, summary, seedSQL.UpsertMenuCategoryQuery, c.ID, queries.Args{
+			"name":          c.Name,
+			"description":   nullString(c.Description),
+			"display_order": c.DisplayOrder,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	summary = r.table("menu_sub_categories")
+	for _, c := range r.ds.MenuSubCategories {
+		err := r.upsert(ctx, summary, seedSQL.UpsertMenuSubCategoryQuery, c.ID, queries.Args{
+			"name":          c.Name,
+			"category_id":   r.ref(c.CategoryID),
+			"item_type":     c.ItemType,
+			"display_order": c.DisplayOrder,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	summary = r.table("menu_variants")
+	for _, v := range r.ds.MenuVariants {
+		err := r.upsert(ctx, summary, seedSQL.UpsertMenuVariantQuery, v.ID, queries.Args{
+			"name":             v.Name,
+			"sub_category_id":  r.ref(v.SubCategoryID),
+			"price":            v.Price,
+			"preparation_time": v.PreparationTime,
+			"is_alcoholic":     v.IsAlcoholic,
+			"display_order":    v.DisplayOrder,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	summary = r.table("menu_ingredients")
+	for _, i := range r.ds.MenuIngredients {
+		if !r.created[i.MenuVariantID] {
+			summary.Existing++
+			continue
+		}
+		err := r.insert(ctx, summary, seedSQL.InsertMenuIngredientQuery, queries.Args{
+			"id":               i.ID,
+			"menu_variant_id":  r.ref(i.MenuVariantID),
+			"stock_variant_id": r.ref(i.StockVariantID),
+			"quantity":         i.Quantity,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+	return nil
+}
+
+// purchases seeds the suppliers and their invoices, invoice items and stock counts
+func (r *seedRun) purchases(ctx context.Context) error {
+	summary := r.table("suppliers")
+	for _, s := range r.ds.Suppliers {
+		err := r.upsert(ctx, summary, seedSQL.UpsertSupplierQuery, s.ID, queries.Args{
+			"name":         s.Name,
+			"contact_name": nullString(s.ContactName),
+			"phone":        nullString(s.Phone),
+			"email":        nullString(s.Email),
+			"address":      nullString(s.Address),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	summary = r.table("outcome_invoices")
+	for _, i := range r.ds.OutcomeInvoices {
+		err := r.insert(ctx, summary, seedSQL.InsertOutcomeInvoiceQuery, queries.Args{
+			"id":               i.ID,
+			"invoice_number":   i.InvoiceNumber,
+			"supplier_id":      r.ref(i.SupplierID),
+			"transaction_date": i.TransactionDate,
+			"due_date":         i.DueDate,
+			"subtotal":         i.Subtotal,
+			"tax_amount":       i.TaxAmount,
+			"total_amount":     i.TotalAmount,
+			"created_at":       i.TransactionDate,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	createdAt := map[string]interface{}{}
+	for _, i := range r.ds.OutcomeInvoices {
+		createdAt[i.ID] = i.TransactionDate
+	}
+
+	summary = r.table("invoice_items")
+	for _, i := range r.ds.InvoiceItems {
+		err := r.insert(ctx, summary, seedSQL.InsertInvoiceItemQuery, queries.Args{
+			"id":               i.ID,
+			"invoice_id":       i.InvoiceID,
+			"stock_variant_id": r.ref(i.StockVariantID),
+			"detail":           nullString(i.Detail),
+			"count":            i.Count,
+			"unit_type":        i.UnitType,
+			"price":            i.Price,
+			"created_at":       createdAt[i.InvoiceID],
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	summary = r.table("stock_count")
+	for _, c := range r.ds.StockCounts {
+		err := r.insert(ctx, summary, seedSQL.InsertStockCountQuery, queries.Args{
+			"id":               c.ID,
+			"stock_variant_id": r.ref(c.StockVariantID),
+			"invoice_id":       c.InvoiceID,
+			"count":            c.Count,
+			"unit":             c.Unit,
+			"unit_price":       c.UnitPrice,
+			"cost_per_portion": c.CostPerPortion,
+			"purchased_at":     c.PurchasedAt,
+			"is_out":           c.IsOut,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+	return nil
+}
+
+// costs recomputes the average cost of the seeded stock variants from their stock
+// counts, then the cost of the menu variants this run created from their recipes
+func (r *seedRun) costs(ctx context.Context) error {
+	for _, v := range r.ds.StockVariants {
+		_, err := r.db.ExecNamedContext(ctx, r.queries.Get(seedSQL.UpdateStockVariantAvgCostQuery), queries.Args{"id": r.ref(v.ID)})
+		if err != nil {
+			return fmt.Errorf("failed to update the average cost of stock variant %s: %w", v.Name, err)
+		}
+	}
+
+	for _, v := range r.ds.MenuVariants {
+		if !r.created[v.ID] {
+			continue
+		}
+		_, err := r.db.ExecNamedContext(ctx, r.queries.Get(seedSQL.UpdateMenuVariantItemCostQuery), queries.Args{"id": r.ref(v.ID)})
+		if err != nil {
+			return fmt.Errorf("failed to update the cost of menu variant %s: %w", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// floor seeds the tables and the staff
+func (r *seedRun) floor(ctx context.Context) error {
+	summary := r.table("tables")
+	for _, t := range r.ds.Tables {
+		err := r.upsert(ctx, summary, seedSQL.UpsertTableQuery, t.ID, queries.Args{
+			"table_number": t.TableNumber,
+			"capacity":     t.Capacity,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	// Every new staff member gets the same hash, bcrypt is too slow to run per row
+	var passwordHash string
+	err := r.db.QueryRowNamedContext(ctx, r.queries.Get(seedSQL.HashPasswordQuery), queries.Args{"password": r.password}).
+		Scan(&passwordHash)
+	if err != nil {
+		return fmt.Errorf("failed to hash the staff password: %w", err)
+	}
+
+	summary = r.table("staff")
+	for _, s := range r.ds.Staff {
+		err := r.upsert(ctx, summary, seedSQL.UpsertStaffQuery, s.ID, queries.Args{
+			"username":      s.Username,
+			"email":         s.Email,
+			"password_hash": passwordHash,
+			"first_name":    s.FirstName,
+			"last_name":     s.LastName,
+			"role":          s.Role,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+	return nil
+}
+
+// orders seeds the orders with their items, payments and income invoices
+func (r *seedRun) orders(ctx context.Context) error {
+	summary := r.table("orders")
+	for _, o := range r.ds.Orders {
+		err := r.insert(ctx, summary, seedSQL.InsertOrderQuery, queries.Args{
+			"id":                   o.ID,
+			"order_number":         o.OrderNumber,
+			"table_id":             r.ref(o.TableID),
+			"status":               o.Status,
+			"payment_status":       o.PaymentStatus,
+			"payment_requested_at": o.PaymentRequestedAt,
+			"paid_at":              o.PaidAt,
+			"total_amount":         o.TotalAmount,
+			"tax_amount":           o.TaxAmount,
+			"service_charge":       o.ServiceCharge,
+			"created_at":           o.CreatedAt,
+			"confirmed_at":         o.ConfirmedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	summary = r.table("order_items")
+	for _, i := range r.ds.OrderItems {
+		err := r.insert(ctx, summary, seedSQL.InsertOrderItemQuery, queries.Args{
+			"id":              i.ID,
+			"order_id":        i.OrderID,
+			"menu_variant_id": r.ref(i.MenuVariantID),
+			"quantity":        i.Quantity,
+			"unit_price":      i.UnitPrice,
+			"subtotal":        i.Subtotal,
+			"status":          i.Status,
+			"order_type":      i.OrderType,
+			"lost_reason":     i.LostReason,
+			"fault_type":      i.FaultType,
+			"requested_at":    i.RequestedAt,
+			"preparing_at":    i.PreparingAt,
+			"ready_at":        i.ReadyAt,
+			"delivered_at":    i.DeliveredAt,
+			"lost_at":         i.LostAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	summary = r.table("payments")
+	for _, p := range r.ds.Payments {
+		err := r.insert(ctx, summary, seedSQL.InsertPaymentQuery, queries.Args{
+			"id":                    p.ID,
+			"order_id":              p.OrderID,
+			"amount":                p.Amount,
+			"payment_method":        p.PaymentMethod,
+			"cash_amount_provided":  p.CashAmountProvided,
+			"tip_amount":            p.TipAmount,
+			"processed_by_staff_id": r.ref(p.ProcessedByStaffID),
+			"transaction_id":        p.TransactionID,
+			"processed_at":          p.ProcessedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+
+	summary = r.table("income_invoices")
+	for _, i := range r.ds.IncomeInvoices {
+		err := r.insert(ctx, summary, seedSQL.InsertIncomeInvoiceQuery, queries.Args{
+			"id":             i.ID,
+			"order_id":       i.OrderID,
+			"payment_id":     i.PaymentID,
+			"invoice_number": i.InvoiceNumber,
+			"subtotal":       i.Subtotal,
+			"tax_amount":     i.TaxAmount,
+			"service_charge": i.ServiceCharge,
+			"total_amount":   i.TotalAmount,
+			"payment_method": i.PaymentMethod,
+			"generated_at":   i.GeneratedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	r.done(summary)
+	return nil
+}
@@ -0,0 +1,60 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	UpsertStockCategoryQuery       queries.Name = "upsert_stock_category"
+	UpsertStockSubCategoryQuery    queries.Name = "upsert_stock_sub_category"
+	UpsertStockVariantQuery        queries.Name = "upsert_stock_variant"
+	UpsertMenuCategoryQuery        queries.Name = "upsert_menu_category"
+	UpsertMenuSubCategoryQuery     queries.Name = "upsert_menu_sub_category"
+	UpsertMenuVariantQuery         queries.Name = "upsert_menu_variant"
+	InsertMenuIngredientQuery      queries.Name = "insert_menu_ingredient"
+	UpsertSupplierQuery            queries.Name = "upsert_supplier"
+	InsertOutcomeInvoiceQuery      queries.Name = "insert_outcome_invoice"
+	InsertInvoiceItemQuery         queries.Name = "insert_invoice_item"
+	InsertStockCountQuery          queries.Name = "insert_stock_count"
+	UpdateStockVariantAvgCostQuery queries.Name = "update_stock_variant_avg_cost"
+	UpdateMenuVariantItemCostQuery queries.Name = "update_menu_variant_item_cost"
+	UpsertTableQuery               queries.Name = "upsert_table"
+	HashPasswordQuery              queries.Name = "hash_password"
+	UpsertStaffQuery               queries.Name = "upsert_staff"
+	InsertOrderQuery               queries.Name = "insert_order"
+	InsertOrderItemQuery           queries.Name = "insert_order_item"
+	InsertPaymentQuery             queries.Name = "insert_payment"
+	InsertIncomeInvoiceQuery       queries.Name = "insert_income_invoice"
+)
+
+// LoadQueries loads and validates the seeder SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		UpsertStockCategoryQuery,
+		UpsertStockSubCategoryQuery,
+		UpsertStockVariantQuery,
+		UpsertMenuCategoryQuery,
+		UpsertMenuSubCategoryQuery,
+		UpsertMenuVariantQuery,
+		InsertMenuIngredientQuery,
+		UpsertSupplierQuery,
+		InsertOutcomeInvoiceQuery,
+		InsertInvoiceItemQuery,
+		InsertStockCountQuery,
+		UpdateStockVariantAvgCostQuery,
+		UpdateMenuVariantItemCostQuery,
+		UpsertTableQuery,
+		HashPasswordQuery,
+		UpsertStaffQuery,
+		InsertOrderQuery,
+		InsertOrderItemQuery,
+		InsertPaymentQuery,
+		InsertIncomeInvoiceQuery,
+	)
+}
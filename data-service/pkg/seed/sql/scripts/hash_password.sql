@@ -0,0 +1,2 @@
+-- Hash a password with bcrypt, the format session-service checks logins against
+SELECT crypt(@password, gen_salt('bf', 10));
@@ -0,0 +1,6 @@
+-- Insert the generated electronic invoice of a paid order
+INSERT INTO income_invoices (id, order_id, payment_id, invoice_number, invoice_type, subtotal, tax_amount,
+                             service_charge, total_amount, payment_method, status, generated_at, created_at, updated_at)
+VALUES (@id, @order_id, @payment_id, @invoice_number, 'sales', @subtotal, @tax_amount,
+        @service_charge, @total_amount, @payment_method, 'generated', @generated_at, @generated_at, @generated_at)
+ON CONFLICT (id) DO NOTHING;
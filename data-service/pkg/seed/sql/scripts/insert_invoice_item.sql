@@ -0,0 +1,4 @@
+-- Insert a line of a supplier invoice, the total is computed by the table
+INSERT INTO invoice_items (id, invoice_id, stock_variant_id, detail, count, unit_type, price, created_at, updated_at)
+VALUES (@id, @invoice_id, @stock_variant_id, @detail, @count, @unit_type, @price, @created_at, @created_at)
+ON CONFLICT (id) DO NOTHING;
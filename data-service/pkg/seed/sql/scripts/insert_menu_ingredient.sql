@@ -0,0 +1,4 @@
+-- Insert a recipe line of a menu variant
+INSERT INTO menu_ingredients (id, menu_variant_id, stock_variant_id, quantity)
+VALUES (@id, @menu_variant_id, @stock_variant_id, @quantity)
+ON CONFLICT (id) DO NOTHING;
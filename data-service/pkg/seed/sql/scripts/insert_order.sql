@@ -0,0 +1,6 @@
+-- Insert an order placed in the past
+INSERT INTO orders (id, order_number, table_id, status, payment_status, payment_requested_at, paid_at, total_amount,
+                    tax_amount, service_charge, created_at, updated_at, confirmed_at)
+VALUES (@id, @order_number, @table_id, @status, @payment_status, @payment_requested_at, @paid_at, @total_amount,
+        @tax_amount, @service_charge, @created_at, @created_at, @confirmed_at)
+ON CONFLICT (id) DO NOTHING;
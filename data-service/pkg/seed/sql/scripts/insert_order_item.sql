@@ -0,0 +1,8 @@
+-- Insert a line of an order
+INSERT INTO order_items (id, order_id, menu_variant_id, quantity, unit_price, subtotal, status, order_type,
+                         lost_reason, fault_type, requested_at, preparing_at, ready_at, delivered_at, lost_at,
+                         created_at, updated_at)
+VALUES (@id, @order_id, @menu_variant_id, @quantity, @unit_price, @subtotal, @status, @order_type,
+        @lost_reason, @fault_type, @requested_at, @preparing_at, @ready_at, @delivered_at, @lost_at,
+        @requested_at, @requested_at)
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,6 @@
+-- Insert a supplier invoice dated in the past
+INSERT INTO outcome_invoices (id, invoice_number, supplier_id, transaction_date, due_date, subtotal, tax_amount,
+                              total_amount, created_at, updated_at)
+VALUES (@id, @invoice_number, @supplier_id, @transaction_date, @due_date, @subtotal, @tax_amount,
+        @total_amount, @created_at, @created_at)
+ON CONFLICT (id) DO NOTHING;
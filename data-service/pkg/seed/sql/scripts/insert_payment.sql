@@ -0,0 +1,6 @@
+-- Insert the completed payment of an order
+INSERT INTO payments (id, order_id, amount, payment_method, payment_status, cash_amount_provided, tip_amount,
+                      processed_by_staff_id, transaction_id, processed_at, created_at, updated_at)
+VALUES (@id, @order_id, @amount, @payment_method, 'completed', @cash_amount_provided, @tip_amount,
+        @processed_by_staff_id, @transaction_id, @processed_at, @processed_at, @processed_at)
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,6 @@
+-- Insert the stock a supplier invoice line brought in
+INSERT INTO stock_count (id, stock_variant_id, invoice_id, count, unit, unit_price, cost_per_portion, purchased_at,
+                         is_out, created_at, updated_at)
+VALUES (@id, @stock_variant_id, @invoice_id, @count, @unit, @unit_price, @cost_per_portion, @purchased_at,
+        @is_out, @purchased_at, @purchased_at)
+ON CONFLICT (id) DO NOTHING;
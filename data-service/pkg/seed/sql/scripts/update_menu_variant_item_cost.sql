@@ -0,0 +1,10 @@
+-- Set the cost of a menu variant to the cost of its recipe
+UPDATE menu_variants mv
+SET item_cost = (
+        SELECT COALESCE(SUM(mi.quantity * sv.avg_cost), 0)
+        FROM menu_ingredients mi
+        JOIN stock_variants sv ON sv.id = mi.stock_variant_id
+        WHERE mi.menu_variant_id = mv.id
+    ),
+    updated_at = CURRENT_TIMESTAMP
+WHERE mv.id = @id;
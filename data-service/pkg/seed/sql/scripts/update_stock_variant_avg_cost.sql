@@ -0,0 +1,13 @@
+-- Recompute the average cost per portion of a stock variant from the stock counts in
+-- stock, the same way inventory-service does after a purchase
+UPDATE stock_variants
+SET avg_cost = COALESCE(
+        (SELECT AVG(cost_per_portion)
+         FROM stock_count
+         WHERE stock_variant_id = @id
+           AND is_out = false
+           AND deleted_at IS NULL
+           AND cost_per_portion > 0),
+        0),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
@@ -0,0 +1,5 @@
+-- Insert a menu sub-category, or return the live sub-category of the same name in its category
+INSERT INTO menu_sub_categories (id, name, category_id, item_type, display_order)
+VALUES (@id, @name, @category_id, @item_type, @display_order)
+ON CONFLICT (category_id, name) WHERE deleted_at IS NULL DO UPDATE SET name = EXCLUDED.name
+RETURNING id, (xmax = 0) AS inserted;
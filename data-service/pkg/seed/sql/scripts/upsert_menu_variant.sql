@@ -0,0 +1,6 @@
+-- Insert a menu variant, or return the live variant of the same name in its sub-category.
+-- An existing variant keeps its price.
+INSERT INTO menu_variants (id, name, sub_category_id, price, preparation_time, is_alcoholic, display_order)
+VALUES (@id, @name, @sub_category_id, @price, @preparation_time, @is_alcoholic, @display_order)
+ON CONFLICT (sub_category_id, name) WHERE deleted_at IS NULL DO UPDATE SET name = EXCLUDED.name
+RETURNING id, (xmax = 0) AS inserted;
@@ -0,0 +1,6 @@
+-- Insert a staff member, or return the staff member with the same username.
+-- An existing staff member keeps their password.
+INSERT INTO staff (id, username, email, password_hash, first_name, last_name, role)
+VALUES (@id, @username, @email, @password_hash, @first_name, @last_name, @role)
+ON CONFLICT (username) DO UPDATE SET username = EXCLUDED.username
+RETURNING id, (xmax = 0) AS inserted;
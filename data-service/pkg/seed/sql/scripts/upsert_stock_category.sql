@@ -0,0 +1,5 @@
+-- Insert a stock category, or return the live category of the same name
+INSERT INTO stock_categories (id, name, description, display_order)
+VALUES (@id, @name, @description, @display_order)
+ON CONFLICT (name) WHERE deleted_at IS NULL DO UPDATE SET name = EXCLUDED.name
+RETURNING id, (xmax = 0) AS inserted;
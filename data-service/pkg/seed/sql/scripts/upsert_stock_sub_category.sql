@@ -0,0 +1,5 @@
+-- Insert a stock sub-category, or return the live sub-category of the same name in its category
+INSERT INTO stock_sub_categories (id, name, description, stock_category_id, display_order)
+VALUES (@id, @name, @description, @stock_category_id, @display_order)
+ON CONFLICT (stock_category_id, name) WHERE deleted_at IS NULL DO UPDATE SET name = EXCLUDED.name
+RETURNING id, (xmax = 0) AS inserted;
@@ -0,0 +1,5 @@
+-- Insert a stock variant, or return the live variant of the same name in its sub-category
+INSERT INTO stock_variants (id, name, description, stock_sub_category_id)
+VALUES (@id, @name, @description, @stock_sub_category_id)
+ON CONFLICT (stock_sub_category_id, name) WHERE deleted_at IS NULL DO UPDATE SET name = EXCLUDED.name
+RETURNING id, (xmax = 0) AS inserted;
@@ -0,0 +1,5 @@
+-- Insert a supplier, or return the live supplier of the same name
+INSERT INTO suppliers (id, name, contact_name, phone, email, address)
+VALUES (@id, @name, @contact_name, @phone, @email, @address)
+ON CONFLICT (name) WHERE deleted_at IS NULL DO UPDATE SET name = EXCLUDED.name
+RETURNING id, (xmax = 0) AS inserted;
@@ -0,0 +1,5 @@
+-- Insert a table, or return the table with the same number
+INSERT INTO tables (id, table_number, capacity)
+VALUES (@id, @table_number, @capacity)
+ON CONFLICT (table_number) DO UPDATE SET table_number = EXCLUDED.table_number
+RETURNING id, (xmax = 0) AS inserted;
@@ -54,11 +54,13 @@ func main() {
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to create HTTP health monitor")
 	}
-	httpHealthMonitor.AddService("session-service", sessionServiceUrl+"/api/v1/sessions/p/health")
-	httpHealthMonitor.AddService("data-service", dataServiceUrl+"/api/v1/data/p/health")
-	httpHealthMonitor.AddService("menu-service", menuServiceUrl+"/api/v1/menu/p/health")
-	httpHealthMonitor.AddService("inventory-service", inventoryServiceUrl+"/api/v1/inventory/p/health")
-	httpHealthMonitor.AddService("invoice-service", invoiceServiceUrl+"/api/v1/invoices/p/health")
+	// Sessions are critical since every protected route validates through them,
+	// the remaining services only take their own routes down when they fail
+	httpHealthMonitor.AddService("session-service", sessionServiceUrl+"/api/v1/sessions/p/readyz", sharedHttp.Critical)
+	httpHealthMonitor.AddService("data-service", dataServiceUrl+"/api/v1/data/p/readyz", sharedHttp.Degraded)
+	httpHealthMonitor.AddService("menu-service", menuServiceUrl+"/api/v1/menu/p/readyz", sharedHttp.Degraded)
+	httpHealthMonitor.AddService("inventory-service", inventoryServiceUrl+"/api/v1/inventory/p/readyz", sharedHttp.Degraded)
+	httpHealthMonitor.AddService("invoice-service", invoiceServiceUrl+"/api/v1/invoices/p/readyz", sharedHttp.Degraded)
 	httpHealthMonitor.Start(ctx)
 
 	// Create session manager for authentication
@@ -66,7 +68,7 @@ func main() {
 	sessionMiddleware := middleware.NewSessionMiddleware(sessionManager, logger)
 
 	// Create HTTP handler with all dependencies
-	httpHandler := handlers.NewHTTPHandler(config, sessionServiceUrl, menuServiceUrl, inventoryServiceUrl, invoiceServiceUrl, httpHealthMonitor, logger)
+	httpHandler := handlers.NewHTTPHandler(config, sessionServiceUrl, dataServiceUrl, menuServiceUrl, inventoryServiceUrl, invoiceServiceUrl, httpHealthMonitor, logger)
 	router := httpHandler.SetupRoutes(sessionMiddleware)
 
 	// Start server
@@ -90,6 +92,8 @@ func main() {
 		logger.Info("")
 		logger.Info("🔓 Public endpoints:")
 		logger.Info("   GET  /api/v1/gateway/p/health       - Gateway health (checks business layer)")
+		logger.Info("   GET  /api/v1/gateway/p/livez        - Gateway liveness")
+		logger.Info("   GET  /api/v1/gateway/p/readyz       - Gateway readiness with dependency graph")
 		logger.Info("   POST /api/v1/sessions/p/login       - Login")
 		logger.Info("   POST /api/v1/sessions/p/validate    - Validate session")
 		logger.Info("   GET  /api/v1/sessions/p/health      - Session service health")
@@ -22,6 +22,7 @@ import (
 type HTTPHandler struct {
 	config              *sharedConfig.Config
 	sessionServiceUrl   string
+	dataServiceUrl      string
 	menuServiceUrl      string
 	inventoryServiceUrl string
 	invoiceServiceUrl   string
@@ -32,6 +33,7 @@ type HTTPHandler struct {
 func NewHTTPHandler(
 	config *sharedConfig.Config,
 	sessionServiceUrl string,
+	dataServiceUrl string,
 	menuServiceUrl string,
 	inventoryServiceUrl string,
 	invoiceServiceUrl string,
@@ -41,6 +43,7 @@ func NewHTTPHandler(
 	return &HTTPHandler{
 		config:              config,
 		sessionServiceUrl:   sessionServiceUrl,
+		dataServiceUrl:      dataServiceUrl,
 		menuServiceUrl:      menuServiceUrl,
 		inventoryServiceUrl: inventoryServiceUrl,
 		invoiceServiceUrl:   invoiceServiceUrl,
@@ -50,19 +53,21 @@ func NewHTTPHandler(
 }
 
 // GatewayHealthCheck handles the gateway health check endpoint
-// Uses cached health state from background health monitor
+// Uses cached health state from background health monitor and reports the
+// dependency graph of every business layer service
 func (h *HTTPHandler) GatewayHealthCheck(w http.ResponseWriter, r *http.Request) {
 	// Get health status from monitor (cached, updated every 10s)
 	healthStatus := h.httpHealthMonitor.GetHealthStatus()
 
-	// Set status code based on overall health
+	// Only a failing critical dependency makes the gateway unavailable
 	statusCode := http.StatusOK
 	if !healthStatus.IsHealthy {
 		statusCode = http.StatusServiceUnavailable
 	}
 
 	h.logger.WithFields(logrus.Fields{
-		"health_status": healthStatus,
+		"status":   healthStatus.Status,
+		"services": healthStatus.Services,
 	}).Info("Gateway health check")
 
 	w.Header().Set("Content-Type", "application/json")
@@ -100,14 +105,9 @@ func (h *HTTPHandler) CreateProxyHandler(targetURL string) http.HandlerFunc {
 			"error":   err.Error(),
 		}).Error("Proxy error - service unavailable")
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadGateway)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"error":     "service_unavailable",
-			"message":   fmt.Sprintf("The %s is currently unavailable", serviceName),
-			"timestamp": time.Now(),
-			"service":   serviceName,
-		})
+		problem := sharedHttp.NewProblem(http.StatusBadGateway, "service_unavailable", fmt.Sprintf("The %s is currently unavailable", serviceName))
+		problem.Service = serviceName
+		sharedHttp.WriteProblem(w, problem.WithRequest(r))
 	}
 
 	originalDirector := proxy.Director
@@ -136,6 +136,15 @@ func (h *HTTPHandler) CreateProxyHandler(targetURL string) http.HandlerFunc {
 	}
 }
 
+// longRunning lifts the server write timeout for proxied requests that take minutes,
+// such as taking, downloading or restoring a database backup
+func longRunning(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.NewResponseController(w).SetWriteDeadline(time.Now().Add(30 * time.Minute))
+		next(w, r)
+	}
+}
+
 // SetupRoutes configures all gateway routes
 func (h *HTTPHandler) SetupRoutes(sessionMiddleware *middleware.SessionMiddleware) *mux.Router {
 	r := mux.NewRouter()
@@ -150,15 +159,17 @@ func (h *HTTPHandler) SetupRoutes(sessionMiddleware *middleware.SessionMiddlewar
 
 	api := r.PathPrefix("/api").Subrouter()
 
-	// Gateway health endpoint (checks business layer services only)
+	// Gateway health endpoints (checks business layer services only)
 	api.HandleFunc("/v1/gateway/p/health", h.GatewayHealthCheck).Methods("GET")
+	api.HandleFunc("/v1/gateway/p/livez", sharedHttp.LivenessHandler("gateway-service")).Methods("GET")
+	api.HandleFunc("/v1/gateway/p/readyz", h.httpHealthMonitor.ReadinessHandler("gateway-service")).Methods("GET")
 
 	// ==== PUBLIC ENDPOINTS (no authentication) ====
 
 	// Session service - public endpoints
 	api.HandleFunc("/v1/sessions/p/login", h.CreateProxyHandler(h.sessionServiceUrl)).Methods("POST")
 	api.HandleFunc("/v1/sessions/p/validate", h.CreateProxyHandler(h.sessionServiceUrl)).Methods("POST")
-	api.HandleFunc("/v1/sessions/p/health", h.CreateProxyHandler(h.sessionServiceUrl)).Methods("GET")
+	api.HandleFunc("/v1/sessions/p/{check:health|livez|readyz}", h.CreateProxyHandler(h.sessionServiceUrl)).Methods("GET")
 
 	// ==== PROTECTED SESSION ENDPOINTS (require authentication) ====
 	protectedSessionRouter := api.PathPrefix("/v1/sessions").Subrouter()
@@ -167,30 +178,83 @@ func (h *HTTPHandler) SetupRoutes(sessionMiddleware *middleware.SessionMiddlewar
 
 	// ==== MENU SERVICE ENDPOINTS ====
 	// Public - health check
-	api.HandleFunc("/v1/menu/p/health", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
-	api.HandleFunc("/v1/inventory/p/health", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET")
-	api.HandleFunc("/v1/invoices/p/health", h.CreateProxyHandler(h.invoiceServiceUrl)).Methods("GET")
+	api.HandleFunc("/v1/menu/p/{check:health|livez|readyz}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
+
+	// Public - customer menu from QR codes, rate limited per client since anyone can call it
+	publicRateLimit := middleware.NewRateLimitMiddleware(h.config.GetInt("PUBLIC_RATE_LIMIT"), h.config.GetInt("PUBLIC_RATE_BURST"), h.logger)
+	api.Handle("/v1/menu/p/menu", publicRateLimit.Limit(h.CreateProxyHandler(h.menuServiceUrl))).Methods("GET")
+	api.HandleFunc("/v1/inventory/p/{check:health|livez|readyz}", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET")
+	api.HandleFunc("/v1/invoices/p/{check:health|livez|readyz}", h.CreateProxyHandler(h.invoiceServiceUrl)).Methods("GET")
 
 	// Protected - Menu Categories
 	menuRouter := api.PathPrefix("/v1/menu").Subrouter()
 	menuRouter.Use(sessionMiddleware.ValidateSession)
 	menuRouter.HandleFunc("/categories", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "POST")
 	menuRouter.HandleFunc("/categories/{id}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "PUT", "DELETE")
+	menuRouter.HandleFunc("/categories/{id}/restore", h.CreateProxyHandler(h.menuServiceUrl)).Methods("POST")
 
 	// Protected - Menu Sub-Categories
 	menuRouter.HandleFunc("/sub-categories", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "POST")
 	menuRouter.HandleFunc("/sub-categories/{id}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "PUT", "DELETE")
+	menuRouter.HandleFunc("/sub-categories/{id}/restore", h.CreateProxyHandler(h.menuServiceUrl)).Methods("POST")
 
 	// Protected - Menu Variants
 	menuRouter.HandleFunc("/variants", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "POST")
+	menuRouter.HandleFunc("/variants/orderable", h.CreateProxyHandler(h.menuServiceUrl)).Methods("POST")
 	menuRouter.HandleFunc("/variants/{id}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "PUT", "DELETE")
+	menuRouter.HandleFunc("/variants/{id}/restore", h.CreateProxyHandler(h.menuServiceUrl)).Methods("POST")
 	menuRouter.HandleFunc("/variants/{id}/availability", h.CreateProxyHandler(h.menuServiceUrl)).Methods("PATCH")
+	menuRouter.HandleFunc("/variants/{id}/price", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
 	menuRouter.HandleFunc("/variants/{variantId}/ingredients", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
+	menuRouter.HandleFunc("/variants/{variantId}/cost", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
+	menuRouter.HandleFunc("/variants/{variantId}/bom", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
+
+	// Protected - Menu Costs (the service checks the role on recomputes)
+	menuRouter.HandleFunc("/costs", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
+	menuRouter.HandleFunc("/costs/recompute", longRunning(h.CreateProxyHandler(h.menuServiceUrl))).Methods("POST")
+
+	// Protected - Menu Pricing (the service checks the role on repricing and targets)
+	menuRouter.HandleFunc("/pricing", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
+	menuRouter.HandleFunc("/pricing/reprice", longRunning(h.CreateProxyHandler(h.menuServiceUrl))).Methods("POST")
+	menuRouter.HandleFunc("/pricing/targets/categories/{id}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("PUT")
+	menuRouter.HandleFunc("/pricing/targets/sub-categories/{id}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("PUT")
+
+	// Protected - Modifier Groups (the service checks the role on changes)
+	menuRouter.HandleFunc("/modifier-groups", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "POST")
+	menuRouter.HandleFunc("/modifier-groups/{id}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "PUT", "DELETE")
+	menuRouter.HandleFunc("/modifier-groups/{id}/restore", h.CreateProxyHandler(h.menuServiceUrl)).Methods("POST")
+
+	// Protected - Combos (the service checks the role on changes)
+	menuRouter.HandleFunc("/combos", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "POST")
+	menuRouter.HandleFunc("/combos/{id}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "PUT", "DELETE")
+	menuRouter.HandleFunc("/combos/{id}/restore", h.CreateProxyHandler(h.menuServiceUrl)).Methods("POST")
+
+	// Protected - Service Periods (the service checks the role on changes)
+	menuRouter.HandleFunc("/service-periods", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "POST")
+	menuRouter.HandleFunc("/service-periods/active", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
+	menuRouter.HandleFunc("/service-periods/{id}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "PUT", "DELETE")
+	menuRouter.HandleFunc("/service-periods/{id}/restore", h.CreateProxyHandler(h.menuServiceUrl)).Methods("POST")
+
+	// Protected - Menu Engineering (the service checks the role)
+	menuRouter.HandleFunc("/engineering", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
 
 	// Protected - Menu Ingredients
 	menuRouter.HandleFunc("/ingredients", h.CreateProxyHandler(h.menuServiceUrl)).Methods("POST")
 	menuRouter.HandleFunc("/ingredients/{id}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET", "PUT", "DELETE")
 
+	// Protected - Menu Tree (the whole menu, polled with If-None-Match)
+	menuRouter.HandleFunc("/tree", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
+
+	// Protected - Menu Translations (the service checks the role)
+	menuRouter.HandleFunc("/translations/{kind:categories|sub-categories|variants}/{id}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("PUT")
+
+	// Protected - Menu Import and Export (the service checks the role on imports)
+	menuRouter.HandleFunc("/export/{sheet}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("GET")
+	menuRouter.HandleFunc("/import/{sheet}", h.CreateProxyHandler(h.menuServiceUrl)).Methods("POST")
+
+	// Protected - Menu Admin (the service checks the role)
+	menuRouter.HandleFunc("/admin/purge", h.CreateProxyHandler(h.menuServiceUrl)).Methods("POST")
+
 	// Protected - Inventory Service (Categories, Sub-Categories, Variants, Suppliers)
 	inventoryRouter := api.PathPrefix("/v1/inventory").Subrouter()
 	inventoryRouter.Use(sessionMiddleware.ValidateSession)
@@ -198,23 +262,35 @@ func (h *HTTPHandler) SetupRoutes(sessionMiddleware *middleware.SessionMiddlewar
 	// Stock Categories
 	inventoryRouter.HandleFunc("/categories", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET", "POST")
 	inventoryRouter.HandleFunc("/categories/{id}", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET", "PUT", "DELETE")
+	inventoryRouter.HandleFunc("/categories/{id}/restore", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("POST")
 
 	// Stock Sub-Categories
 	inventoryRouter.HandleFunc("/sub-categories", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET", "POST")
 	inventoryRouter.HandleFunc("/sub-categories/{id}", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET", "PUT", "DELETE")
+	inventoryRouter.HandleFunc("/sub-categories/{id}/restore", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("POST")
 
 	// Stock Variants
 	inventoryRouter.HandleFunc("/variants", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET", "POST")
 	inventoryRouter.HandleFunc("/variants/{id}", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET", "PUT", "DELETE")
+	inventoryRouter.HandleFunc("/variants/{id}/restore", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("POST")
 
 	// Stock Count
 	inventoryRouter.HandleFunc("/stock-count", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET", "POST")
 	inventoryRouter.HandleFunc("/stock-count/{id}", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET", "PUT", "DELETE")
+	inventoryRouter.HandleFunc("/stock-count/{id}/restore", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("POST")
 	inventoryRouter.HandleFunc("/stock-count/{id}/out", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("PATCH")
 
 	// Suppliers
 	inventoryRouter.HandleFunc("/suppliers", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET", "POST")
 	inventoryRouter.HandleFunc("/suppliers/{id}", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET", "PUT", "DELETE")
+	inventoryRouter.HandleFunc("/suppliers/{id}/restore", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("POST")
+
+	// Catalog Import and Export (the service checks the role on imports)
+	inventoryRouter.HandleFunc("/export/{sheet}", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("GET")
+	inventoryRouter.HandleFunc("/import/{sheet}", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("POST")
+
+	// Inventory Admin (the service checks the role)
+	inventoryRouter.HandleFunc("/admin/purge", h.CreateProxyHandler(h.inventoryServiceUrl)).Methods("POST")
 
 	// Protected - Invoices
 	invoiceRouter := api.PathPrefix("/v1/invoices").Subrouter()
@@ -223,14 +299,40 @@ func (h *HTTPHandler) SetupRoutes(sessionMiddleware *middleware.SessionMiddlewar
 	// Outcome Invoices (supplier purchases - formerly purchase_invoices)
 	invoiceRouter.HandleFunc("/outcome", h.CreateProxyHandler(h.invoiceServiceUrl)).Methods("GET", "POST")
 	invoiceRouter.HandleFunc("/outcome/{id}", h.CreateProxyHandler(h.invoiceServiceUrl)).Methods("GET", "PUT", "DELETE")
+	invoiceRouter.HandleFunc("/outcome/{id}/restore", h.CreateProxyHandler(h.invoiceServiceUrl)).Methods("POST")
 
 	// Income Invoices (customer billing - formerly customer_invoices)
 	invoiceRouter.HandleFunc("/income", h.CreateProxyHandler(h.invoiceServiceUrl)).Methods("GET", "POST")
 	invoiceRouter.HandleFunc("/income/{id}", h.CreateProxyHandler(h.invoiceServiceUrl)).Methods("GET", "PUT", "DELETE")
+	invoiceRouter.HandleFunc("/income/{id}/restore", h.CreateProxyHandler(h.invoiceServiceUrl)).Methods("POST")
 
 	// Invoice Items are now handled within invoice CRUD operations
 	// No separate endpoints for invoice items
 
+	// Invoice Admin (the service checks the role)
+	invoiceRouter.HandleFunc("/admin/purge", h.CreateProxyHandler(h.invoiceServiceUrl)).Methods("POST")
+
+	// Protected - Audit Log
+	dataRouter := api.PathPrefix("/v1/data").Subrouter()
+	dataRouter.Use(sessionMiddleware.ValidateSession)
+	dataRouter.HandleFunc("/audit", h.CreateProxyHandler(h.dataServiceUrl)).Methods("GET")
+
+	// Protected - Database Backups (the service checks the role)
+	dataRouter.HandleFunc("/backups", longRunning(h.CreateProxyHandler(h.dataServiceUrl))).Methods("GET", "POST")
+	dataRouter.HandleFunc("/backups/{name}", longRunning(h.CreateProxyHandler(h.dataServiceUrl))).Methods("GET")
+	dataRouter.HandleFunc("/backups/{name}/restore-token", h.CreateProxyHandler(h.dataServiceUrl)).Methods("POST")
+	dataRouter.HandleFunc("/backups/{name}/restore", longRunning(h.CreateProxyHandler(h.dataServiceUrl))).Methods("POST")
+
+	// Protected - Retention Policies and Archive Reports (the service checks the role)
+	dataRouter.HandleFunc("/retention", h.CreateProxyHandler(h.dataServiceUrl)).Methods("GET")
+	dataRouter.HandleFunc("/retention/run", longRunning(h.CreateProxyHandler(h.dataServiceUrl))).Methods("POST")
+	dataRouter.HandleFunc("/retention/{table}", h.CreateProxyHandler(h.dataServiceUrl)).Methods("PUT")
+	dataRouter.HandleFunc("/archive", h.CreateProxyHandler(h.dataServiceUrl)).Methods("GET")
+	dataRouter.HandleFunc("/archive/{table}", h.CreateProxyHandler(h.dataServiceUrl)).Methods("GET")
+
+	// Protected - Database Statistics (the service checks the role)
+	dataRouter.HandleFunc("/admin/{report}", h.CreateProxyHandler(h.dataServiceUrl)).Methods("GET")
+
 	// OPTIONS handling for CORS preflight
 	r.Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -24,7 +24,8 @@ func TestCORSMiddleware_Headers(t *testing.T) {
 		expected string
 	}{
 		{"Access-Control-Allow-Origin", "*"},
-		{"Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS"},
+		{"Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS"},
+		{"Access-Control-Expose-Headers", "ETag, Content-Disposition"},
 		{"Access-Control-Allow-Credentials", "true"},
 		{"Access-Control-Max-Age", "86400"},
 	}
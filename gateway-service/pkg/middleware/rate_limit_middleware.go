@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	sharedHttp "shared/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimitMiddleware limits the requests of each client, by remote address, with a token
+// bucket: a client may send burst requests at once and perMinute a minute after that
+type RateLimitMiddleware struct {
+	perSecond float64
+	burst     float64
+	now       func() time.Time
+	logger    *logrus.Logger
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// bucket holds the tokens a client has left as of updated
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimitMiddleware creates a rate limit of perMinute requests a minute per client
+// with bursts of burst requests
+func NewRateLimitMiddleware(perMinute, burst int, logger *logrus.Logger) *RateLimitMiddleware {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimitMiddleware{
+		perSecond: float64(perMinute) / 60,
+		burst:     float64(burst),
+		now:       time.Now,
+		logger:    logger,
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Limit middleware answers 429 with Retry-After to clients over the limit
+func (rl *RateLimitMiddleware) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := clientAddress(r)
+		allowed, retryAfter := rl.take(client)
+		if !allowed {
+			rl.logger.WithFields(logrus.Fields{
+				"client": client,
+				"path":   r.URL.Path,
+			}).Warn("Rate limit exceeded")
+
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			sharedHttp.WriteProblem(w, sharedHttp.NewProblem(http.StatusTooManyRequests, "rate_limited", "Too many requests, try again later").WithRequest(r))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// take spends a token of the client, returning false and the seconds until the next one
+// when it has none
+func (rl *RateLimitMiddleware) take(client string) (bool, int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+
+	b, ok := rl.buckets[client]
+	if !ok {
+		b = &bucket{tokens: rl.burst, updated: now}
+		rl.buckets[client] = b
+	}
+	b.tokens = math.Min(rl.burst, b.tokens+now.Sub(b.updated).Seconds()*rl.perSecond)
+	b.updated = now
+
+	if b.tokens < 1 {
+		if rl.perSecond <= 0 {
+			return false, 60
+		}
+		return false, int(math.Ceil((1 - b.tokens) / rl.perSecond))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep forgets, once a minute, the clients whose buckets have filled up again
+func (rl *RateLimitMiddleware) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < time.Minute {
+		return
+	}
+	rl.lastSweep = now
+	for client, b := range rl.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*rl.perSecond >= rl.burst {
+			delete(rl.buckets, client)
+		}
+	}
+}
+
+// clientAddress is the remote IP of a request. X-Forwarded-For is not trusted since the
+// gateway is the edge and clients could set it to dodge the limit.
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
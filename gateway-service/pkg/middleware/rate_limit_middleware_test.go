@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRateLimitMiddleware_Limit(t *testing.T) {
+	now := time.Date(2026, 1, 31, 18, 0, 0, 0, time.UTC)
+	rl := NewRateLimitMiddleware(60, 2, logrus.New())
+	rl.now = func() time.Time { return now }
+	handler := rl.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	get := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/menu/p/menu", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := get("10.0.0.1:5000"); w.Code != http.StatusOK {
+			t.Fatalf("request %d within the burst = %d; want 200", i+1, w.Code)
+		}
+	}
+	w := get("10.0.0.1:5001")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("request over the burst = %d with Retry-After %q; want 429 with 1", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := get("10.0.0.2:5000"); w.Code != http.StatusOK {
+		t.Errorf("another client = %d; want 200", w.Code)
+	}
+
+	now = now.Add(time.Second)
+	if w := get("10.0.0.1:5000"); w.Code != http.StatusOK {
+		t.Errorf("request after a token refilled = %d; want 200", w.Code)
+	}
+	if w := get("10.0.0.1:5000"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second request after one token refilled = %d; want 429", w.Code)
+	}
+}
+
+func TestRateLimitMiddleware_Sweep(t *testing.T) {
+	now := time.Date(2026, 1, 31, 18, 0, 0, 0, time.UTC)
+	rl := NewRateLimitMiddleware(60, 5, logrus.New())
+	rl.now = func() time.Time { return now }
+	rl.lastSweep = now
+
+	rl.take("10.0.0.1")
+	now = now.Add(2 * time.Minute)
+	rl.take("10.0.0.2")
+
+	if _, ok := rl.buckets["10.0.0.1"]; ok {
+		t.Error("idle client with a full bucket was not swept")
+	}
+	if _, ok := rl.buckets["10.0.0.2"]; !ok {
+		t.Error("active client was swept")
+	}
+}
@@ -1,11 +1,10 @@
 package middleware
 
 import (
-	"encoding/json"
 	sessionmanager "gateway-service/pkg/middleware/session-manager"
 	"net/http"
+	sharedHttp "shared/http"
 	"strings"
-	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -77,15 +76,7 @@ func extractTokenFromHeader(r *http.Request) string {
 }
 
 func (sm *SessionMiddleware) writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
-	response := map[string]interface{}{
-		"error":     errorCode,
-		"message":   message,
-		"timestamp": time.Now(),
-		"service":   "gateway",
-	}
-
-	json.NewEncoder(w).Encode(response)
+	problem := sharedHttp.NewProblem(statusCode, errorCode, message)
+	problem.Service = "gateway"
+	sharedHttp.WriteProblem(w, problem)
 }
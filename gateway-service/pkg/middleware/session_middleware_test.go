@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	sharedHttp "shared/http"
 	"testing"
 )
 
@@ -69,8 +70,8 @@ func TestSessionMiddleware_WriteErrorResponse(t *testing.T) {
 	}
 
 	contentType := w.Header().Get("Content-Type")
-	if contentType != "application/json" {
-		t.Errorf("Content-Type = %s; want application/json", contentType)
+	if contentType != sharedHttp.ProblemContentType {
+		t.Errorf("Content-Type = %s; want %s", contentType, sharedHttp.ProblemContentType)
 	}
 
 	var response map[string]interface{}
@@ -78,8 +79,12 @@ func TestSessionMiddleware_WriteErrorResponse(t *testing.T) {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
 
-	if response["error"] != "test_error" {
-		t.Errorf("error = %s; want test_error", response["error"])
+	if response["code"] != "test_error" {
+		t.Errorf("code = %s; want test_error", response["code"])
+	}
+
+	if response["status"] != float64(http.StatusUnauthorized) {
+		t.Errorf("status = %v; want %d", response["status"], http.StatusUnauthorized)
 	}
 
 	if response["message"] != "Test message" {
@@ -119,7 +124,7 @@ func TestSessionMiddleware_ValidateSession_MissingAuth(t *testing.T) {
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
 
-	if response["error"] != "missing_token" {
-		t.Errorf("error = %s; want missing_token", response["error"])
+	if response["code"] != "missing_token" {
+		t.Errorf("code = %s; want missing_token", response["code"])
 	}
 }
@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	catalogSQL "inventory-service/pkg/entities/catalog/sql"
+	stockCategoryHandlers "inventory-service/pkg/entities/stock_categories/handlers"
+	stockCategoryModels "inventory-service/pkg/entities/stock_categories/models"
+	stockSubCategoryHandlers "inventory-service/pkg/entities/stock_sub_categories/handlers"
+	stockSubCategoryModels "inventory-service/pkg/entities/stock_sub_categories/models"
+	stockVariantHandlers "inventory-service/pkg/entities/stock_variants/handlers"
+	stockVariantModels "inventory-service/pkg/entities/stock_variants/models"
+	supplierHandlers "inventory-service/pkg/entities/suppliers/handlers"
+	supplierModels "inventory-service/pkg/entities/suppliers/models"
+	"shared/bulk"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	sharedErrors "shared/errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sheet names, as used in the import and export URLs
+const (
+	CategoriesSheet    = "categories"
+	SubCategoriesSheet = "sub-categories"
+	VariantsSheet      = "variants"
+	SuppliersSheet     = "suppliers"
+)
+
+// sheet describes how a catalog sheet is exported and imported. Rows are matched by
+// their natural key, the name within the parent names, and blank cells keep the
+// current value of an existing row.
+type sheet struct {
+	columns   []string
+	required  []string
+	export    queries.Name
+	importRow bulk.RowFunc
+}
+
+// DBHandler imports and exports the inventory catalog through the entity handlers, so
+// imported rows are validated, versioned and audited like single edits
+type DBHandler struct {
+	db            *sharedDb.DbHandler
+	queries       *queries.Registry
+	categories    *stockCategoryHandlers.DBHandler
+	subCategories *stockSubCategoryHandlers.DBHandler
+	variants      *stockVariantHandlers.DBHandler
+	suppliers     *supplierHandlers.DBHandler
+	sheets        map[string]sheet
+	logger        *logrus.Logger
+}
+
+// NewDBHandler creates a new database handler
+func NewDBHandler(
+	db *sharedDb.DbHandler,
+	categories *stockCategoryHandlers.DBHandler,
+	subCategories *stockSubCategoryHandlers.DBHandler,
+	variants *stockVariantHandlers.DBHandler,
+	suppliers *supplierHandlers.DBHandler,
+	logger *logrus.Logger,
+) (*DBHandler, error) {
+	queries, err := catalogSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	h := &DBHandler{
+		db:            db,
+		queries:       queries,
+		categories:    categories,
+		subCategories: subCategories,
+		variants:      variants,
+		suppliers:     suppliers,
+		logger:        logger,
+	}
+	h.sheets = map[string]sheet{
+		CategoriesSheet: {
+			columns:   []string{"name", "description", "display_order", "is_active"},
+			required:  []string{"name"},
+			export:    catalogSQL.ExportStockCategoriesQuery,
+			importRow: h.importCategory,
+		},
+		SubCategoriesSheet: {
+			columns:   []string{"category", "name", "description", "display_order", "is_active"},
+			required:  []string{"category", "name"},
+			export:    catalogSQL.ExportStockSubCategoriesQuery,
+			importRow: h.importSubCategory,
+		},
+		VariantsSheet: {
+			// avg_cost is computed from stock counts, it is exported for reference only
+			columns:   []string{"category", "sub_category", "name", "description", "is_active", "avg_cost"},
+			required:  []string{"category", "sub_category", "name"},
+			export:    catalogSQL.ExportStockVariantsQuery,
+			importRow: h.importVariant,
+		},
+		SuppliersSheet: {
+			columns:   []string{"name", "contact_name", "phone", "email", "address"},
+			required:  []string{"name"},
+			export:    catalogSQL.ExportSuppliersQuery,
+			importRow: h.importSupplier,
+		},
+	}
+	return h, nil
+}
+
+func (h *DBHandler) sheet(name string) (sheet, error) {
+	s, ok := h.sheets[name]
+	if !ok {
+		return sheet{}, sharedErrors.NotFound("sheet_not_found", fmt.Sprintf("unknown catalog sheet %q", name))
+	}
+	return s, nil
+}
+
+// Export returns every live row of a sheet
+func (h *DBHandler) Export(ctx context.Context, name string) (*bulk.Table, error) {
+	s, err := h.sheet(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(s.export), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	table := bulk.NewTable(s.columns...)
+	values := make([]sql.NullString, len(s.columns))
+	dest := make([]interface{}, len(s.columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", name, err)
+		}
+		row := make([]string, len(values))
+		for i, value := range values {
+			row[i] = value.String
+		}
+		table.Append(row...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s: %w", name, err)
+	}
+
+	return table, nil
+}
+
+// Import upserts the rows of a sheet in one transaction. Nothing is written when
+// dryRun is set or any row fails.
+func (h *DBHandler) Import(ctx context.Context, name string, table *bulk.Table, dryRun bool) (*bulk.Report, error) {
+	s, err := h.sheet(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := table.RequireColumns(s.required...); err != nil {
+		return nil, err
+	}
+
+	report, err := bulk.Import(ctx, h.db, name, table, dryRun, s.importRow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import %s: %w", name, err)
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"sheet":     name,
+		"rows":      report.Rows,
+		"created":   report.Created,
+		"updated":   report.Updated,
+		"errors":    len(report.Errors),
+		"dry_run":   dryRun,
+		"committed": report.Committed,
+	}).Info("Catalog sheet imported")
+	return report, nil
+}
+
+func (h *DBHandler) importCategory(ctx context.Context, rec bulk.Record) (bulk.Action, error) {
+	name, err := rec.Required("name")
+	if err != nil {
+		return "", err
+	}
+	displayOrder, err := rec.Int("display_order")
+	if err != nil {
+		return "", err
+	}
+	isActive, err := rec.Bool("is_active")
+	if err != nil {
+		return "", err
+	}
+
+	id, version, err := h.find(ctx, catalogSQL.FindStockCategoryQuery, queries.Args{"name": name})
+	if err != nil {
+		return "", err
+	}
+	if id != "" {
+		_, err := h.categories.Update(ctx, id, version, &stockCategoryModels.StockCategoryUpdateRequest{
+			Description:  rec.Optional("description"),
+			DisplayOrder: displayOrder,
+			IsActive:     isActive,
+		})
+		return bulk.Updated, err
+	}
+
+	_, err = h.categories.Create(ctx, &stockCategoryModels.StockCategoryCreateRequest{
+		Name:         name,
+		Description:  rec.Optional("description"),
+		DisplayOrder: displayOrder,
+		IsActive:     isActive,
+	})
+	return bulk.Created, err
+}
+
+func (h *DBHandler) importSubCategory(ctx context.Context, rec bulk.Record) (bulk.Action, error) {
+	category, err := rec.Required("category")
+	if err != nil {
+		return "", err
+	}
+	name, err := rec.Required("name")
+	if err != nil {
+		return "", err
+	}
+	displayOrder, err := rec.Int("display_order")
+	if err != nil {
+		return "", err
+	}
+	isActive, err := rec.Bool("is_active")
+	if err != nil {
+		return "", err
+	}
+
+	id, version, err := h.find(ctx, catalogSQL.FindStockSubCategoryQuery, queries.Args{"category": category, "name": name})
+	if err != nil {
+		return "", err
+	}
+	if id != "" {
+		_, err := h.subCategories.Update(ctx, id, version, &stockSubCategoryModels.StockSubCategoryUpdateRequest{
+			Description:  rec.Optional("description"),
+			DisplayOrder: displayOrder,
+			IsActive:     isActive,
+		})
+		return bulk.Updated, err
+	}
+
+	categoryID, _, err := h.find(ctx, catalogSQL.FindStockCategoryQuery, queries.Args{"name": category})
+	if err != nil {
+		return "", err
+	}
+	if categoryID == "" {
+		return "", missingParent("category", fmt.Sprintf("stock category %q does not exist", category))
+	}
+
+	_, err = h.subCategories.Create(ctx, &stockSubCategoryModels.StockSubCategoryCreateRequest{
+		Name:            name,
+		Description:     rec.Optional("description"),
+		StockCategoryID: categoryID,
+		DisplayOrder:    displayOrder,
+		IsActive:        isActive,
+	})
+	return bulk.Created, err
+}
+
+func (h *DBHandler) importVariant(ctx context.Context, rec bulk.Record) (bulk.Action, error) {
+	category, err := rec.Required("category")
+	if err != nil {
+		return "", err
+	}
+	subCategory, err := rec.Required("sub_category")
+	if err != nil {
+		return "", err
+	}
+	name, err := rec.Required("name")
+	if err != nil {
+		return "", err
+	}
+	isActive, err := rec.Bool("is_active")
+	if err != nil {
+		return "", err
+	}
+
+	id, version, err := h.find(ctx, catalogSQL.FindStockVariantQuery, queries.Args{"category": category, "sub_category": subCategory, "name": name})
+	if err != nil {
+		return "", err
+	}
+	if id != "" {
+		_, err := h.variants.Update(ctx, id, version, &stockVariantModels.StockVariantUpdateRequest{
+			Description: rec.Optional("description"),
+			IsActive:    isActive,
+		})
+		return bulk.Updated, err
+	}
+
+	subCategoryID, _, err := h.find(ctx, catalogSQL.FindStockSubCategoryQuery, queries.Args{"category": category, "name": subCategory})
+	if err != nil {
+		return "", err
+	}
+	if subCategoryID == "" {
+		return "", missingParent("sub_category", fmt.Sprintf("stock sub-category %q does not exist in %q", subCategory, category))
+	}
+
+	_, err = h.variants.Create(ctx, &stockVariantModels.StockVariantCreateRequest{
+		Name:               name,
+		Description:        rec.Optional("description"),
+		StockSubCategoryID: subCategoryID,
+		IsActive:           isActive,
+	})
+	return bulk.Created, err
+}
+
+func (h *DBHandler) importSupplier(ctx context.Context, rec bulk.Record) (bulk.Action, error) {
+	name, err := rec.Required("name")
+	if err != nil {
+		return "", err
+	}
+
+	id, version, err := h.find(ctx, catalogSQL.FindSupplierQuery, queries.Args{"name": name})
+	if err != nil {
+		return "", err
+	}
+	if id != "" {
+		_, err := h.suppliers.Update(ctx, id, version, &supplierModels.SupplierUpdateRequest{
+			ContactName: rec.Optional("contact_name"),
+			Phone:       rec.Optional("phone"),
+			Email:       rec.Optional("email"),
+			Address:     rec.Optional("address"),
+		})
+		return bulk.Updated, err
+	}
+
+	_, err = h.suppliers.Create(ctx, &supplierModels.SupplierCreateRequest{
+		Name:        name,
+		ContactName: rec.Optional("contact_name"),
+		Phone:       rec.Optional("phone"),
+		Email:       rec.Optional("email"),
+		Address:     rec.Optional("address"),
+	})
+	return bulk.Created, err
+}
+
+// find returns the ID and version of the live row matching a natural key, an empty ID
+// when there is none
+func (h *DBHandler) find(ctx context.Context, query queries.Name, args queries.Args) (string, int, error) {
+	var id string
+	var version int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(query), args).Scan(&id, &version)
+	if err == sql.ErrNoRows {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to look up %s: %w", query, err)
+	}
+	return id, version, nil
+}
+
+func missingParent(column, message string) error {
+	return sharedErrors.Validation("parent_not_found", message,
+		sharedErrors.FieldError{Field: column, Code: "not_found", Message: message})
+}
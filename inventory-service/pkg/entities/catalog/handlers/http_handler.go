@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"shared/bulk"
+	sharedHttp "shared/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPHandler handles HTTP requests for catalog imports and exports
+type HTTPHandler struct {
+	dbHandler *DBHandler
+	logger    *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{dbHandler: dbHandler, logger: logger}
+}
+
+// Export handles GET /api/v1/inventory/export/{sheet}?format=csv|xlsx
+func (h *HTTPHandler) Export(w http.ResponseWriter, r *http.Request) {
+	sheet := mux.Vars(r)["sheet"]
+
+	table, err := h.dbHandler.Export(r.Context(), sheet)
+	if err != nil {
+		h.logger.WithError(err).WithField("sheet", sheet).Error("Failed to export catalog sheet")
+		sharedHttp.SendError(w, r, err, "Failed to export catalog sheet")
+		return
+	}
+
+	bulk.SendTable(w, r, sheet, table)
+}
+
+// Import handles POST /api/v1/inventory/import/{sheet}?dry_run=true with a CSV or XLSX
+// sheet as the body or as the "file" field of a multipart form
+func (h *HTTPHandler) Import(w http.ResponseWriter, r *http.Request) {
+	sheet := mux.Vars(r)["sheet"]
+
+	dryRun, err := bulk.DryRun(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "")
+		return
+	}
+
+	table, err := bulk.ReadRequest(w, r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to read the sheet")
+		return
+	}
+
+	report, err := h.dbHandler.Import(r.Context(), sheet, table, dryRun)
+	if err != nil {
+		h.logger.WithError(err).WithField("sheet", sheet).Error("Failed to import catalog sheet")
+		sharedHttp.SendError(w, r, err, "Failed to import catalog sheet")
+		return
+	}
+
+	bulk.SendReport(w, r, report)
+}
@@ -0,0 +1,36 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	ExportStockCategoriesQuery    queries.Name = "export_stock_categories"
+	ExportStockSubCategoriesQuery queries.Name = "export_stock_sub_categories"
+	ExportStockVariantsQuery      queries.Name = "export_stock_variants"
+	ExportSuppliersQuery          queries.Name = "export_suppliers"
+	FindStockCategoryQuery        queries.Name = "find_stock_category"
+	FindStockSubCategoryQuery     queries.Name = "find_stock_sub_category"
+	FindStockVariantQuery         queries.Name = "find_stock_variant"
+	FindSupplierQuery             queries.Name = "find_supplier"
+)
+
+// LoadQueries loads and validates the catalog import and export SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ExportStockCategoriesQuery,
+		ExportStockSubCategoriesQuery,
+		ExportStockVariantsQuery,
+		ExportSuppliersQuery,
+		FindStockCategoryQuery,
+		FindStockSubCategoryQuery,
+		FindStockVariantQuery,
+		FindSupplierQuery,
+	)
+}
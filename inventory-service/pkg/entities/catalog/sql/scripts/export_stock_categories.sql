@@ -0,0 +1,4 @@
+SELECT name, description, display_order, is_active
+FROM stock_categories
+WHERE deleted_at IS NULL
+ORDER BY display_order, name;
@@ -0,0 +1,5 @@
+SELECT sc.name, ssc.name, ssc.description, ssc.display_order, ssc.is_active
+FROM stock_sub_categories ssc
+JOIN stock_categories sc ON ssc.stock_category_id = sc.id
+WHERE ssc.deleted_at IS NULL AND sc.deleted_at IS NULL
+ORDER BY sc.display_order, sc.name, ssc.display_order, ssc.name;
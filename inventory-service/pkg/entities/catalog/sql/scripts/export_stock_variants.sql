@@ -0,0 +1,6 @@
+SELECT sc.name, ssc.name, sv.name, sv.description, sv.is_active, sv.avg_cost
+FROM stock_variants sv
+JOIN stock_sub_categories ssc ON sv.stock_sub_category_id = ssc.id
+JOIN stock_categories sc ON ssc.stock_category_id = sc.id
+WHERE sv.deleted_at IS NULL AND ssc.deleted_at IS NULL AND sc.deleted_at IS NULL
+ORDER BY sc.display_order, sc.name, ssc.display_order, ssc.name, sv.name;
@@ -0,0 +1,4 @@
+SELECT name, contact_name, phone, email, address
+FROM suppliers
+WHERE deleted_at IS NULL
+ORDER BY name;
@@ -0,0 +1,5 @@
+SELECT ssc.id, ssc.version
+FROM stock_sub_categories ssc
+JOIN stock_categories sc ON ssc.stock_category_id = sc.id
+WHERE sc.name = @category AND ssc.name = @name
+  AND ssc.deleted_at IS NULL AND sc.deleted_at IS NULL;
@@ -0,0 +1,6 @@
+SELECT sv.id, sv.version
+FROM stock_variants sv
+JOIN stock_sub_categories ssc ON sv.stock_sub_category_id = ssc.id
+JOIN stock_categories sc ON ssc.stock_category_id = sc.id
+WHERE sc.name = @category AND ssc.name = @sub_category AND sv.name = @name
+  AND sv.deleted_at IS NULL AND ssc.deleted_at IS NULL AND sc.deleted_at IS NULL;
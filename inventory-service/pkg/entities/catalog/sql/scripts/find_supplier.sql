@@ -0,0 +1,3 @@
+SELECT id, version
+FROM suppliers
+WHERE name = @name AND deleted_at IS NULL;
@@ -1,24 +1,34 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"inventory-service/pkg/entities/stock_categories/models"
 	stockCategorySQL "inventory-service/pkg/entities/stock_categories/sql"
+	"shared/audit"
 	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// auditEntity names the entity in the audit log
+const auditEntity = "stock_category"
+
 // DBHandler handles database operations for stock categories
 type DBHandler struct {
 	db      *sharedDb.DbHandler
-	queries *stockCategorySQL.Queries
+	audit   *audit.Log
+	queries *queries.Registry
 	logger  *logrus.Logger
 }
 
 // NewDBHandler creates a new database handler
-func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, error) {
+func NewDBHandler(db *sharedDb.DbHandler, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
 	queries, err := stockCategorySQL.LoadQueries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
@@ -26,31 +36,30 @@ func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, er
 
 	return &DBHandler{
 		db:      db,
+		audit:   auditLog,
 		queries: queries,
 		logger:  logger,
 	}, nil
 }
 
-// List returns a paginated list of stock categories
-func (h *DBHandler) List(page, limit int) (*models.StockCategoryListResponse, error) {
-	offset := (page - 1) * limit
-
-	countQuery, err := h.queries.Get(stockCategorySQL.CountStockCategoriesQuery)
+// List returns a page of stock categories matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.StockCategoryListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(stockCategorySQL.ListStockCategoriesQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get count query: %w", err)
+		return nil, fmt.Errorf("failed to build stock categories count: %w", err)
 	}
 
 	var total int
-	if err := h.db.QueryRow(countQuery).Scan(&total); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
 		return nil, fmt.Errorf("failed to count stock categories: %w", err)
 	}
 
-	listQuery, err := h.queries.Get(stockCategorySQL.ListStockCategoriesQuery)
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(stockCategorySQL.ListStockCategoriesQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
+		return nil, fmt.Errorf("failed to build stock categories list: %w", err)
 	}
 
-	rows, err := h.db.Query(listQuery, limit, offset)
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list stock categories: %w", err)
 	}
@@ -61,7 +70,7 @@ func (h *DBHandler) List(page, limit int) (*models.StockCategoryListResponse, er
 		var cat models.StockCategory
 		var description sql.NullString
 
-		if err := rows.Scan(&cat.ID, &cat.Name, &description, &cat.DisplayOrder, &cat.IsActive, &cat.CreatedAt, &cat.UpdatedAt); err != nil {
+		if err := rows.Scan(&cat.ID, &cat.Name, &description, &cat.DisplayOrder, &cat.IsActive, &cat.CreatedAt, &cat.UpdatedAt, &cat.Version, &cat.DeletedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan stock category: %w", err)
 		}
 
@@ -71,25 +80,25 @@ func (h *DBHandler) List(page, limit int) (*models.StockCategoryListResponse, er
 		categories = append(categories, cat)
 	}
 
+	categories, pageInfo, err := queryspec.Paginate(spec, categories)
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.StockCategoryListResponse{
 		Categories: categories,
 		Total:      total,
-		Page:       page,
-		Limit:      limit,
+		Limit:      spec.Limit,
+		PageInfo:   pageInfo,
 	}, nil
 }
 
 // GetByID returns a stock category by ID
-func (h *DBHandler) GetByID(id string) (*models.StockCategory, error) {
-	query, err := h.queries.Get(stockCategorySQL.GetStockCategoryByIDQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.StockCategory, error) {
 	var cat models.StockCategory
 	var description sql.NullString
 
-	err = h.db.QueryRow(query, id).Scan(&cat.ID, &cat.Name, &description, &cat.DisplayOrder, &cat.IsActive, &cat.CreatedAt, &cat.UpdatedAt)
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockCategorySQL.GetStockCategoryByIDQuery), queries.Args{"id": id}).Scan(&cat.ID, &cat.Name, &description, &cat.DisplayOrder, &cat.IsActive, &cat.CreatedAt, &cat.UpdatedAt, &cat.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -105,12 +114,14 @@ func (h *DBHandler) GetByID(id string) (*models.StockCategory, error) {
 }
 
 // Create creates a new stock category
-func (h *DBHandler) Create(req *models.StockCategoryCreateRequest) (*models.StockCategory, error) {
-	query, err := h.queries.Get(stockCategorySQL.CreateStockCategoryQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+func (h *DBHandler) Create(ctx context.Context, req *models.StockCategoryCreateRequest) (*models.StockCategory, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.StockCategory, error) {
+		return h.create(ctx, req)
+	})
+}
 
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.StockCategoryCreateRequest) (*models.StockCategory, error) {
 	// Set defaults if not provided
 	displayOrder := 0
 	if req.DisplayOrder != nil {
@@ -124,8 +135,13 @@ func (h *DBHandler) Create(req *models.StockCategoryCreateRequest) (*models.Stoc
 	var cat models.StockCategory
 	var description sql.NullString
 
-	err = h.db.QueryRow(query, req.Name, req.Description, displayOrder, isActive).Scan(
-		&cat.ID, &cat.Name, &description, &cat.DisplayOrder, &cat.IsActive, &cat.CreatedAt, &cat.UpdatedAt,
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockCategorySQL.CreateStockCategoryQuery), queries.Args{
+		"name":          req.Name,
+		"description":   req.Description,
+		"display_order": displayOrder,
+		"is_active":     isActive,
+	}).Scan(
+		&cat.ID, &cat.Name, &description, &cat.DisplayOrder, &cat.IsActive, &cat.CreatedAt, &cat.UpdatedAt, &cat.Version,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create stock category: %w", err)
@@ -139,22 +155,31 @@ func (h *DBHandler) Create(req *models.StockCategoryCreateRequest) (*models.Stoc
 	return &cat, nil
 }
 
-// Update updates an existing stock category
-func (h *DBHandler) Update(id string, req *models.StockCategoryUpdateRequest) (*models.StockCategory, error) {
-	query, err := h.queries.Get(stockCategorySQL.UpdateStockCategoryQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+// Update updates an existing stock category if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.StockCategoryUpdateRequest) (*models.StockCategory, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.StockCategory, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
 
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.StockCategoryUpdateRequest) (*models.StockCategory, error) {
 	var cat models.StockCategory
 	var description sql.NullString
 
-	err = h.db.QueryRow(query, id, req.Name, req.Description, req.DisplayOrder, req.IsActive).Scan(
-		&cat.ID, &cat.Name, &description, &cat.DisplayOrder, &cat.IsActive, &cat.CreatedAt, &cat.UpdatedAt,
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockCategorySQL.UpdateStockCategoryQuery), queries.Args{
+		"id":            id,
+		"version":       version,
+		"name":          req.Name,
+		"description":   req.Description,
+		"display_order": req.DisplayOrder,
+		"is_active":     req.IsActive,
+	}).Scan(
+		&cat.ID, &cat.Name, &description, &cat.DisplayOrder, &cat.IsActive, &cat.CreatedAt, &cat.UpdatedAt, &cat.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, h.versionMismatch(ctx, id)
 		}
 		return nil, fmt.Errorf("failed to update stock category: %w", err)
 	}
@@ -167,37 +192,76 @@ func (h *DBHandler) Update(id string, req *models.StockCategoryUpdateRequest) (*
 	return &cat, nil
 }
 
-// Delete deletes a stock category
-func (h *DBHandler) Delete(id string) error {
-	checkQuery, err := h.queries.Get(stockCategorySQL.CheckStockCategoryDependenciesQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get check query: %w", err)
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return err
 	}
+	return sharedErrors.PreconditionFailed("stock_category_version_mismatch", "stock category was modified by another request")
+}
 
+// Delete deletes a stock category
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
+
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
 	var count int
-	if err := h.db.QueryRow(checkQuery, id).Scan(&count); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockCategorySQL.CheckStockCategoryDependenciesQuery), queries.Args{"id": id}).Scan(&count); err != nil {
 		return fmt.Errorf("failed to check dependencies: %w", err)
 	}
 
 	if count > 0 {
-		return fmt.Errorf("cannot delete category: %d stock sub-categories depend on it", count)
+		return sharedErrors.Conflict("stock_category_in_use", fmt.Sprintf("cannot delete category: %d stock sub-categories depend on it", count))
 	}
 
-	deleteQuery, err := h.queries.Get(stockCategorySQL.DeleteStockCategoryQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get delete query: %w", err)
-	}
-
-	result, err := h.db.Exec(deleteQuery, id)
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(stockCategorySQL.DeleteStockCategoryQuery), queries.Args{"id": id})
 	if err != nil {
 		return fmt.Errorf("failed to delete stock category: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("stock category not found")
+		return sharedErrors.NotFound("stock_category_not_found", "stock category not found")
 	}
 
 	h.logger.WithField("id", id).Info("Stock category deleted")
 	return nil
 }
+
+// Restore brings back a soft deleted stock category
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.StockCategory, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.StockCategory, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.StockCategory, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(stockCategorySQL.RestoreStockCategoryQuery), queries.Args{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore stock category: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("stock_category_not_found", "deleted stock category not found")
+	}
+
+	h.logger.WithField("id", id).Info("Stock category restored")
+	return h.GetByID(ctx, id)
+}
+
+// Purge hard deletes the stock categories soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockCategorySQL.PurgeStockCategoriesQuery), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge stock categories: %w", err)
+	}
+	return purged, nil
+}
@@ -3,9 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"inventory-service/pkg/entities/stock_categories/models"
+	stockCategorySQL "inventory-service/pkg/entities/stock_categories/sql"
 	sharedHttp "shared/http"
 
 	"github.com/gorilla/mux"
@@ -25,22 +25,19 @@ func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
 
 // List handles GET /api/v1/stock/categories
 func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 20
+	spec, err := stockCategorySQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
 	}
 
-	response, err := h.dbHandler.List(page, limit)
+	response, err := h.dbHandler.List(r.Context(), spec)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list stock categories")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list stock categories")
+		sharedHttp.SendError(w, r, err, "Failed to list stock categories")
 		return
 	}
+	response.SetLinks(r.URL)
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock categories retrieved", response)
 }
@@ -50,10 +47,10 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	category, err := h.dbHandler.GetByID(id)
+	category, err := h.dbHandler.GetByID(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get stock category")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to get stock category")
+		sharedHttp.SendError(w, r, err, "Failed to get stock category")
 		return
 	}
 
@@ -62,6 +59,7 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, category.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock category retrieved", category)
 }
 
@@ -79,13 +77,14 @@ func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	category, err := h.dbHandler.Create(&req)
+	category, err := h.dbHandler.Create(r.Context(), &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create stock category")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to create stock category")
+		sharedHttp.SendError(w, r, err, "Failed to create stock category")
 		return
 	}
 
+	sharedHttp.SetETag(w, category.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Stock category created", category)
 }
 
@@ -94,6 +93,12 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update stock category")
+		return
+	}
+
 	var req models.StockCategoryUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode request body")
@@ -101,10 +106,10 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	category, err := h.dbHandler.Update(id, &req)
+	category, err := h.dbHandler.Update(r.Context(), id, version, &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update stock category")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update stock category")
+		sharedHttp.SendError(w, r, err, "Failed to update stock category")
 		return
 	}
 
@@ -113,6 +118,7 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, category.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock category updated", category)
 }
 
@@ -121,16 +127,28 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.dbHandler.Delete(id)
+	err := h.dbHandler.Delete(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete stock category")
-		if err.Error() == "stock category not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Stock category not found")
-			return
-		}
-		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		sharedHttp.SendError(w, r, err, "Failed to delete stock category")
 		return
 	}
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock category deleted", nil)
 }
+
+// Restore handles POST /api/v1/stock/categories/:id/restore
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	category, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore stock category")
+		sharedHttp.SendError(w, r, err, "Failed to restore stock category")
+		return
+	}
+
+	sharedHttp.SetETag(w, category.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock category restored", category)
+}
@@ -2,17 +2,21 @@ package models
 
 import (
 	"time"
+
+	"shared/db/queryspec"
 )
 
 // StockCategory represents a stock category
 type StockCategory struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	Description  *string   `json:"description,omitempty"`
-	DisplayOrder int       `json:"display_order"`
-	IsActive     bool      `json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Description  *string    `json:"description,omitempty"`
+	DisplayOrder int        `json:"display_order"`
+	IsActive     bool       `json:"is_active"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Version      int        `json:"version"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
 }
 
 // StockCategoryCreateRequest represents a request to create a stock category
@@ -35,6 +39,6 @@ type StockCategoryUpdateRequest struct {
 type StockCategoryListResponse struct {
 	Categories []StockCategory `json:"categories"`
 	Total      int             `json:"total"`
-	Page       int             `json:"page"`
 	Limit      int             `json:"limit"`
+	queryspec.PageInfo
 }
@@ -2,59 +2,45 @@ package sql
 
 import (
 	"embed"
-	"fmt"
-	"strings"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
 )
 
 //go:embed scripts/*.sql
-var sqlFiles embed.FS
-
-// Queries holds all SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from the scripts directory
-func LoadQueries() (*Queries, error) {
-	queries := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files, err := sqlFiles.ReadDir("scripts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			content, err := sqlFiles.ReadFile("scripts/" + file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read SQL file %s: %w", file.Name(), err)
-			}
+var sqlScripts embed.FS
 
-			queryName := strings.TrimSuffix(file.Name(), ".sql")
-			queries.queries[queryName] = string(content)
-		}
-	}
-	return queries, nil
-}
-
-// Get retrieves a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, exists := q.queries[name]
-	if !exists {
-		return "", fmt.Errorf("query '%s' not found", name)
-	}
-	return query, nil
-}
-
-// SQL query constants
+// SQL query names
 const (
-	ListStockCategoriesQuery           = "list_stock_categories"
-	CountStockCategoriesQuery          = "count_stock_categories"
-	GetStockCategoryByIDQuery          = "get_stock_category_by_id"
-	CreateStockCategoryQuery           = "create_stock_category"
-	UpdateStockCategoryQuery           = "update_stock_category"
-	DeleteStockCategoryQuery           = "delete_stock_category"
-	CheckStockCategoryDependenciesQuery = "check_stock_category_dependencies"
+	ListStockCategoriesQuery            queries.Name = "list_stock_categories"
+	GetStockCategoryByIDQuery           queries.Name = "get_stock_category_by_id"
+	CreateStockCategoryQuery            queries.Name = "create_stock_category"
+	UpdateStockCategoryQuery            queries.Name = "update_stock_category"
+	DeleteStockCategoryQuery            queries.Name = "delete_stock_category"
+	RestoreStockCategoryQuery           queries.Name = "restore_stock_category"
+	PurgeStockCategoriesQuery           queries.Name = "purge_stock_categories"
+	CheckStockCategoryDependenciesQuery queries.Name = "check_stock_category_dependencies"
 )
+
+// ListSchema whitelists the columns of list_stock_categories that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("display_order,name",
+	queryspec.Field{Name: "name", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "display_order", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "is_active", Type: queryspec.Bool, Filterable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).WithSoftDelete()
+
+// LoadQueries loads and validates the stock category SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListStockCategoriesQuery,
+		GetStockCategoryByIDQuery,
+		CreateStockCategoryQuery,
+		UpdateStockCategoryQuery,
+		DeleteStockCategoryQuery,
+		RestoreStockCategoryQuery,
+		PurgeStockCategoriesQuery,
+		CheckStockCategoryDependenciesQuery,
+	)
+}
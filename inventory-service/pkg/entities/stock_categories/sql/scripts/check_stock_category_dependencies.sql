@@ -1 +1 @@
-SELECT COUNT(*) FROM stock_sub_categories WHERE stock_category_id = $1;
+SELECT COUNT(*) FROM stock_sub_categories WHERE stock_category_id = @id AND deleted_at IS NULL;
@@ -1 +0,0 @@
-SELECT COUNT(*) FROM stock_categories;
@@ -1 +1 @@
-DELETE FROM stock_categories WHERE id = $1;
+UPDATE stock_categories SET deleted_at = CURRENT_TIMESTAMP WHERE id = @id AND deleted_at IS NULL;
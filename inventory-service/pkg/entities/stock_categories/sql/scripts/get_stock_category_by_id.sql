@@ -1,3 +1,3 @@
-SELECT id, name, description, display_order, is_active, created_at, updated_at
+SELECT id, name, description, display_order, is_active, created_at, updated_at, version
 FROM stock_categories
-WHERE id = $1;
+WHERE id = @id AND deleted_at IS NULL;
@@ -0,0 +1,8 @@
+-- Hard delete categories soft deleted before the retention window that nothing references
+WITH purged AS (
+    DELETE FROM stock_categories sc
+    WHERE sc.deleted_at < @deleted_before
+      AND NOT EXISTS (SELECT 1 FROM stock_sub_categories ssc WHERE ssc.stock_category_id = sc.id)
+    RETURNING sc.id
+)
+SELECT COUNT(*) FROM purged;
@@ -1,35 +1,41 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"strconv"
+	"time"
 
 	"inventory-service/pkg/entities/stock_count/models"
 	stockCountSQL "inventory-service/pkg/entities/stock_count/sql"
+	"shared/audit"
 	sharedConfig "shared/config"
 	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"shared/events"
+	"shared/money"
 
 	"github.com/sirupsen/logrus"
 )
 
-// parseFloat converts a string to float64, returns 0 on error
-func parseFloat(s string) float64 {
-	f, _ := strconv.ParseFloat(s, 64)
-	return f
-}
+// auditEntity names the entity in the audit log
+const auditEntity = "stock_count"
 
 // DBHandler handles database operations for stock count
 type DBHandler struct {
 	db           *sharedDb.DbHandler
-	queries      *stockCountSQL.Queries
+	audit        *audit.Log
+	queries      *queries.Registry
 	logger       *logrus.Logger
 	config       *sharedConfig.Config
+	outbox       *events.Outbox
 	portionGrams float64
 }
 
 // NewDBHandler creates a new database handler
-func NewDBHandler(db *sharedDb.DbHandler, config *sharedConfig.Config, logger *logrus.Logger) (*DBHandler, error) {
+func NewDBHandler(db *sharedDb.DbHandler, outbox *events.Outbox, config *sharedConfig.Config, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
 	queries, err := stockCountSQL.LoadQueries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
@@ -46,33 +52,33 @@ func NewDBHandler(db *sharedDb.DbHandler, config *sharedConfig.Config, logger *l
 
 	return &DBHandler{
 		db:           db,
+		audit:        auditLog,
 		queries:      queries,
 		logger:       logger,
 		config:       config,
+		outbox:       outbox,
 		portionGrams: portionGrams,
 	}, nil
 }
 
-// List returns a paginated list of all stock count records
-func (h *DBHandler) List(page, limit int) (*models.StockCountListResponse, error) {
-	offset := (page - 1) * limit
-
-	countQuery, err := h.queries.Get(stockCountSQL.CountStockCountQuery)
+// List returns a page of stock count records matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.StockCountListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(stockCountSQL.ListStockCountQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get count query: %w", err)
+		return nil, fmt.Errorf("failed to build stock count records count: %w", err)
 	}
 
 	var total int
-	if err := h.db.QueryRow(countQuery).Scan(&total); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
 		return nil, fmt.Errorf("failed to count stock count records: %w", err)
 	}
 
-	listQuery, err := h.queries.Get(stockCountSQL.ListStockCountQuery)
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(stockCountSQL.ListStockCountQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
+		return nil, fmt.Errorf("failed to build stock count records list: %w", err)
 	}
 
-	rows, err := h.db.Query(listQuery, limit, offset)
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list stock count records: %w", err)
 	}
@@ -83,40 +89,7 @@ func (h *DBHandler) List(page, limit int) (*models.StockCountListResponse, error
 		return nil, err
 	}
 
-	return &models.StockCountListResponse{
-		StockCounts: stockCounts,
-		Total:       total,
-		Page:        page,
-		Limit:       limit,
-	}, nil
-}
-
-// ListByVariant returns stock count records for a specific variant
-func (h *DBHandler) ListByVariant(variantID string, page, limit int) (*models.StockCountListResponse, error) {
-	offset := (page - 1) * limit
-
-	countQuery, err := h.queries.Get(stockCountSQL.CountStockCountByVariantQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get count query: %w", err)
-	}
-
-	var total int
-	if err := h.db.QueryRow(countQuery, variantID).Scan(&total); err != nil {
-		return nil, fmt.Errorf("failed to count stock count records: %w", err)
-	}
-
-	listQuery, err := h.queries.Get(stockCountSQL.ListStockCountByVariantQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
-	}
-
-	rows, err := h.db.Query(listQuery, variantID, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list stock count records: %w", err)
-	}
-	defer rows.Close()
-
-	stockCounts, err := h.scanStockCounts(rows)
+	stockCounts, pageInfo, err := queryspec.Paginate(spec, stockCounts)
 	if err != nil {
 		return nil, err
 	}
@@ -124,26 +97,21 @@ func (h *DBHandler) ListByVariant(variantID string, page, limit int) (*models.St
 	return &models.StockCountListResponse{
 		StockCounts: stockCounts,
 		Total:       total,
-		Page:        page,
-		Limit:       limit,
+		Limit:       spec.Limit,
+		PageInfo:    pageInfo,
 	}, nil
 }
 
 // GetByID returns a stock count record by ID
-func (h *DBHandler) GetByID(id string) (*models.StockCount, error) {
-	query, err := h.queries.Get(stockCountSQL.GetStockCountByIDQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.StockCount, error) {
 	var sc models.StockCount
-	var invoiceID, unitPrice, costPerPortion sql.NullString
+	var invoiceID sql.NullString
 	var invoiceNumber, supplierName sql.NullString
 
-	err = h.db.QueryRow(query, id).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockCountSQL.GetStockCountByIDQuery), queries.Args{"id": id}).Scan(
 		&sc.ID, &sc.StockVariantID, &invoiceID, &sc.Count, &sc.Unit,
-		&unitPrice, &costPerPortion,
-		&sc.PurchasedAt, &sc.IsOut, &sc.CreatedAt, &sc.UpdatedAt,
+		&sc.UnitPrice, &sc.CostPerPortion,
+		&sc.PurchasedAt, &sc.IsOut, &sc.CreatedAt, &sc.UpdatedAt, &sc.Version,
 		&sc.StockVariantName, &invoiceNumber, &supplierName,
 	)
 	if err != nil {
@@ -157,14 +125,6 @@ func (h *DBHandler) GetByID(id string) (*models.StockCount, error) {
 	if invoiceID.Valid {
 		sc.InvoiceID = &invoiceID.String
 	}
-	if unitPrice.Valid {
-		val := parseFloat(unitPrice.String)
-		sc.UnitPrice = &val
-	}
-	if costPerPortion.Valid {
-		val := parseFloat(costPerPortion.String)
-		sc.CostPerPortion = &val
-	}
 	if invoiceNumber.Valid {
 		sc.InvoiceNumber = &invoiceNumber.String
 	}
@@ -176,67 +136,84 @@ func (h *DBHandler) GetByID(id string) (*models.StockCount, error) {
 }
 
 // Create creates a new stock count record
-func (h *DBHandler) Create(req *models.StockCountCreateRequest) (*models.StockCount, error) {
-	query, err := h.queries.Get(stockCountSQL.CreateStockCountQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+func (h *DBHandler) Create(ctx context.Context, req *models.StockCountCreateRequest) (*models.StockCount, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.StockCount, error) {
+		return h.create(ctx, req)
+	})
+}
 
-	// Calculate cost per portion if unit_price is provided
-	var costPerPortion *float64
-	if req.UnitPrice != nil && *req.UnitPrice > 0 {
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.StockCountCreateRequest) (*models.StockCount, error) {
+	// Calculate cost per portion if unit_price is provided. Units without a weight
+	// conversion keep the price but leave the cost per portion empty.
+	var costPerPortion *money.Money
+	if req.UnitPrice != nil && req.UnitPrice.Sign() > 0 {
 		totalKG, err := models.ConvertToKG(req.Count, req.Unit)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert unit: %w", err)
+			h.logger.WithError(err).WithField("unit", req.Unit).Warn("Failed to convert unit, cost_per_portion will be null")
+		} else {
+			cost := models.CalculateCostPerPortion(totalKG, *req.UnitPrice, h.portionGrams)
+			costPerPortion = &cost
 		}
-		cost := models.CalculateCostPerPortion(totalKG, *req.UnitPrice, h.portionGrams)
-		costPerPortion = &cost
 	}
 
 	var sc models.StockCount
-	var invoiceID, unitPriceStr, costPerPortionStr sql.NullString
+	var invoiceID sql.NullString
+
+	// Insert the record and refresh the variant avg_cost atomically
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockCountSQL.CreateStockCountQuery), queries.Args{
+			"stock_variant_id": req.StockVariantID,
+			"invoice_id":       req.InvoiceID,
+			"count":            req.Count,
+			"unit":             req.Unit,
+			"unit_price":       req.UnitPrice,
+			"cost_per_portion": costPerPortion,
+			"purchased_at":     req.PurchasedAt,
+		}).Scan(
+			&sc.ID, &sc.StockVariantID, &invoiceID, &sc.Count, &sc.Unit,
+			&sc.UnitPrice, &sc.CostPerPortion,
+			&sc.PurchasedAt, &sc.IsOut, &sc.CreatedAt, &sc.UpdatedAt, &sc.Version,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create stock count record: %w", err)
+		}
 
-	err = h.db.QueryRow(query, req.StockVariantID, req.InvoiceID, req.Count, req.Unit, req.UnitPrice, costPerPortion, req.PurchasedAt).Scan(
-		&sc.ID, &sc.StockVariantID, &invoiceID, &sc.Count, &sc.Unit,
-		&unitPriceStr, &costPerPortionStr,
-		&sc.PurchasedAt, &sc.IsOut, &sc.CreatedAt, &sc.UpdatedAt,
-	)
+		return h.UpdateAvgCost(ctx, req.StockVariantID)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stock count record: %w", err)
+		return nil, err
 	}
 
 	// Handle nullable fields
 	if invoiceID.Valid {
 		sc.InvoiceID = &invoiceID.String
 	}
-	if unitPriceStr.Valid {
-		val := parseFloat(unitPriceStr.String)
-		sc.UnitPrice = &val
-	}
-	if costPerPortionStr.Valid {
-		val := parseFloat(costPerPortionStr.String)
-		sc.CostPerPortion = &val
-	}
-
-	// Update avg_cost for the stock variant
-	if err := h.UpdateAvgCost(req.StockVariantID); err != nil {
-		h.logger.WithError(err).Warn("Failed to update avg_cost for stock variant")
-	}
 
 	h.logger.WithField("id", sc.ID).Info("Stock count record created")
 	return &sc, nil
 }
 
-// Update updates an existing stock count record
-func (h *DBHandler) Update(id string, req *models.StockCountUpdateRequest) (*models.StockCount, error) {
+// Update updates an existing stock count record if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.StockCountUpdateRequest) (*models.StockCount, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.StockCount, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
+
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.StockCountUpdateRequest) (*models.StockCount, error) {
 	// First get the existing record to have all values for cost calculation
-	existing, err := h.GetByID(id)
+	existing, err := h.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get existing record: %w", err)
 	}
 	if existing == nil {
 		return nil, nil
 	}
+	if existing.Version != version {
+		return nil, sharedErrors.PreconditionFailed("stock_count_version_mismatch", "stock count record was modified by another request")
+	}
 
 	// Determine the new values
 	newCount := existing.Count
@@ -254,8 +231,8 @@ func (h *DBHandler) Update(id string, req *models.StockCountUpdateRequest) (*mod
 	}
 
 	// Recalculate cost per portion if we have a unit price
-	var costPerPortion *float64
-	if newUnitPrice != nil && *newUnitPrice > 0 {
+	var costPerPortion *money.Money
+	if newUnitPrice != nil && newUnitPrice.Sign() > 0 {
 		totalKG, err := models.ConvertToKG(newCount, newUnit)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert unit: %w", err)
@@ -264,22 +241,25 @@ func (h *DBHandler) Update(id string, req *models.StockCountUpdateRequest) (*mod
 		costPerPortion = &cost
 	}
 
-	query, err := h.queries.Get(stockCountSQL.UpdateStockCountQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
 	var sc models.StockCount
-	var invoiceID, unitPriceStr, costPerPortionStr sql.NullString
-
-	err = h.db.QueryRow(query, id, req.Count, req.Unit, newUnitPrice, costPerPortion, req.IsOut).Scan(
+	var invoiceID sql.NullString
+
+	err = h.db.QueryRowNamedContext(ctx, h.queries.Get(stockCountSQL.UpdateStockCountQuery), queries.Args{
+		"id":               id,
+		"version":          version,
+		"count":            req.Count,
+		"unit":             req.Unit,
+		"unit_price":       newUnitPrice,
+		"cost_per_portion": costPerPortion,
+		"is_out":           req.IsOut,
+	}).Scan(
 		&sc.ID, &sc.StockVariantID, &invoiceID, &sc.Count, &sc.Unit,
-		&unitPriceStr, &costPerPortionStr,
-		&sc.PurchasedAt, &sc.IsOut, &sc.CreatedAt, &sc.UpdatedAt,
+		&sc.UnitPrice, &sc.CostPerPortion,
+		&sc.PurchasedAt, &sc.IsOut, &sc.CreatedAt, &sc.UpdatedAt, &sc.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, h.versionMismatch(ctx, id)
 		}
 		return nil, fmt.Errorf("failed to update stock count record: %w", err)
 	}
@@ -288,17 +268,9 @@ func (h *DBHandler) Update(id string, req *models.StockCountUpdateRequest) (*mod
 	if invoiceID.Valid {
 		sc.InvoiceID = &invoiceID.String
 	}
-	if unitPriceStr.Valid {
-		val := parseFloat(unitPriceStr.String)
-		sc.UnitPrice = &val
-	}
-	if costPerPortionStr.Valid {
-		val := parseFloat(costPerPortionStr.String)
-		sc.CostPerPortion = &val
-	}
 
 	// Update avg_cost for the stock variant
-	if err := h.UpdateAvgCost(sc.StockVariantID); err != nil {
+	if err := h.UpdateAvgCost(ctx, sc.StockVariantID); err != nil {
 		h.logger.WithError(err).Warn("Failed to update avg_cost for stock variant")
 	}
 
@@ -306,24 +278,49 @@ func (h *DBHandler) Update(id string, req *models.StockCountUpdateRequest) (*mod
 	return &sc, nil
 }
 
-// MarkOut marks a stock count record as out/available
-func (h *DBHandler) MarkOut(id string, isOut bool) (*models.StockCount, error) {
-	query, err := h.queries.Get(stockCountSQL.MarkStockOutQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+// MarkOut marks a stock count record as out/available if it is still at the given version
+func (h *DBHandler) MarkOut(ctx context.Context, id string, version int, isOut bool) (*models.StockCount, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.StockCount, error) {
+		return h.markOut(ctx, id, version, isOut)
+	})
+}
 
+// markOut is MarkOut without the audit log entry
+func (h *DBHandler) markOut(ctx context.Context, id string, version int, isOut bool) (*models.StockCount, error) {
 	var sc models.StockCount
-	var invoiceID, unitPriceStr, costPerPortionStr sql.NullString
+	var invoiceID sql.NullString
+
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockCountSQL.MarkStockOutQuery), queries.Args{
+			"id":      id,
+			"version": version,
+			"is_out":  isOut,
+		}).Scan(
+			&sc.ID, &sc.StockVariantID, &invoiceID, &sc.Count, &sc.Unit,
+			&sc.UnitPrice, &sc.CostPerPortion,
+			&sc.PurchasedAt, &sc.IsOut, &sc.CreatedAt, &sc.UpdatedAt, &sc.Version,
+		)
+		if err != nil {
+			return err
+		}
 
-	err = h.db.QueryRow(query, id, isOut).Scan(
-		&sc.ID, &sc.StockVariantID, &invoiceID, &sc.Count, &sc.Unit,
-		&unitPriceStr, &costPerPortionStr,
-		&sc.PurchasedAt, &sc.IsOut, &sc.CreatedAt, &sc.UpdatedAt,
-	)
+		// Update avg_cost for the stock variant (since is_out affects the avg calculation)
+		if err := h.UpdateAvgCost(ctx, sc.StockVariantID); err != nil {
+			return err
+		}
+
+		if !isOut {
+			return nil
+		}
+		_, err = h.outbox.Add(ctx, events.StockDepleted, "stock_count", sc.ID, events.StockDepletedPayload{
+			StockCountID:   sc.ID,
+			StockVariantID: sc.StockVariantID,
+		})
+		return err
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, h.versionMismatch(ctx, id)
 		}
 		return nil, fmt.Errorf("failed to mark stock out: %w", err)
 	}
@@ -332,53 +329,51 @@ func (h *DBHandler) MarkOut(id string, isOut bool) (*models.StockCount, error) {
 	if invoiceID.Valid {
 		sc.InvoiceID = &invoiceID.String
 	}
-	if unitPriceStr.Valid {
-		val := parseFloat(unitPriceStr.String)
-		sc.UnitPrice = &val
-	}
-	if costPerPortionStr.Valid {
-		val := parseFloat(costPerPortionStr.String)
-		sc.CostPerPortion = &val
-	}
-
-	// Update avg_cost for the stock variant (since is_out affects the avg calculation)
-	if err := h.UpdateAvgCost(sc.StockVariantID); err != nil {
-		h.logger.WithError(err).Warn("Failed to update avg_cost for stock variant")
-	}
 
 	h.logger.WithFields(logrus.Fields{"id": sc.ID, "is_out": isOut}).Info("Stock count out status updated")
 	return &sc, nil
 }
 
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return err
+	}
+	return sharedErrors.PreconditionFailed("stock_count_version_mismatch", "stock count record was modified by another request")
+}
+
 // Delete deletes a stock count record
-func (h *DBHandler) Delete(id string) error {
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
+
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
 	// First get the stock variant ID for updating avg_cost after deletion
-	existing, err := h.GetByID(id)
+	existing, err := h.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get existing record: %w", err)
 	}
 	if existing == nil {
-		return fmt.Errorf("stock count record not found")
+		return sharedErrors.NotFound("stock_count_not_found", "stock count record not found")
 	}
 	stockVariantID := existing.StockVariantID
 
-	query, err := h.queries.Get(stockCountSQL.DeleteStockCountQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get delete query: %w", err)
-	}
-
-	result, err := h.db.Exec(query, id)
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(stockCountSQL.DeleteStockCountQuery), queries.Args{"id": id})
 	if err != nil {
 		return fmt.Errorf("failed to delete stock count record: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("stock count record not found")
+		return sharedErrors.NotFound("stock_count_not_found", "stock count record not found")
 	}
 
 	// Update avg_cost for the stock variant after deletion
-	if err := h.UpdateAvgCost(stockVariantID); err != nil {
+	if err := h.UpdateAvgCost(ctx, stockVariantID); err != nil {
 		h.logger.WithError(err).Warn("Failed to update avg_cost for stock variant")
 	}
 
@@ -386,16 +381,55 @@ func (h *DBHandler) Delete(id string) error {
 	return nil
 }
 
-// UpdateAvgCost updates the average cost per portion for a stock variant
-func (h *DBHandler) UpdateAvgCost(stockVariantID string) error {
-	query, err := h.queries.Get(stockCountSQL.CalculateAvgCostQuery)
+// Restore brings back a soft deleted stock count record
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.StockCount, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.StockCount, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.StockCount, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(stockCountSQL.RestoreStockCountQuery), queries.Args{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore stock count record: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("stock_count_not_found", "deleted stock count record not found")
+	}
+
+	h.logger.WithField("id", id).Info("Stock count record restored")
+	restored, err := h.GetByID(ctx, id)
+	if err != nil || restored == nil {
+		return restored, err
+	}
+
+	// Update avg_cost for the stock variant now that the record counts again
+	if err := h.UpdateAvgCost(ctx, restored.StockVariantID); err != nil {
+		h.logger.WithError(err).Warn("Failed to update avg_cost for stock variant")
+	}
+
+	return restored, nil
+}
+
+// Purge hard deletes the stock count records soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockCountSQL.PurgeStockCountQuery), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
 	if err != nil {
-		return fmt.Errorf("failed to get calculate_avg_cost query: %w", err)
+		return 0, fmt.Errorf("failed to purge stock count records: %w", err)
 	}
+	return purged, nil
+}
 
+// UpdateAvgCost updates the average cost per portion for a stock variant and announces
+// a change, so the menu items made with it get costed again. It must run in a transaction.
+func (h *DBHandler) UpdateAvgCost(ctx context.Context, stockVariantID string) error {
 	var id string
-	var avgCost float64
-	err = h.db.QueryRow(query, stockVariantID).Scan(&id, &avgCost)
+	var oldCost, avgCost money.Money
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockCountSQL.CalculateAvgCostQuery), queries.Args{"stock_variant_id": stockVariantID}).Scan(&id, &oldCost, &avgCost)
 	if err != nil {
 		return fmt.Errorf("failed to update avg_cost: %w", err)
 	}
@@ -404,7 +438,16 @@ func (h *DBHandler) UpdateAvgCost(stockVariantID string) error {
 		"stock_variant_id": stockVariantID,
 		"avg_cost":         avgCost,
 	}).Info("Stock variant avg_cost updated")
-	return nil
+
+	if oldCost.Equal(avgCost) {
+		return nil
+	}
+	_, err = h.outbox.Add(ctx, events.StockVariantCostChanged, "stock_variant", id, events.StockVariantCostChangedPayload{
+		StockVariantID: id,
+		OldCost:        oldCost,
+		NewCost:        avgCost,
+	})
+	return err
 }
 
 // scanStockCounts scans multiple stock count rows
@@ -412,14 +455,14 @@ func (h *DBHandler) scanStockCounts(rows *sql.Rows) ([]models.StockCount, error)
 	var stockCounts []models.StockCount
 	for rows.Next() {
 		var sc models.StockCount
-		var invoiceID, unitPriceStr, costPerPortionStr sql.NullString
+		var invoiceID sql.NullString
 		var invoiceNumber, supplierName sql.NullString
 
 		if err := rows.Scan(
 			&sc.ID, &sc.StockVariantID, &invoiceID, &sc.Count, &sc.Unit,
-			&unitPriceStr, &costPerPortionStr,
-			&sc.PurchasedAt, &sc.IsOut, &sc.CreatedAt, &sc.UpdatedAt,
-			&sc.StockVariantName, &invoiceNumber, &supplierName,
+			&sc.UnitPrice, &sc.CostPerPortion,
+			&sc.PurchasedAt, &sc.IsOut, &sc.CreatedAt, &sc.UpdatedAt, &sc.Version,
+			&sc.StockVariantName, &invoiceNumber, &supplierName, &sc.DeletedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan stock count record: %w", err)
 		}
@@ -428,14 +471,6 @@ func (h *DBHandler) scanStockCounts(rows *sql.Rows) ([]models.StockCount, error)
 		if invoiceID.Valid {
 			sc.InvoiceID = &invoiceID.String
 		}
-		if unitPriceStr.Valid {
-			val := parseFloat(unitPriceStr.String)
-			sc.UnitPrice = &val
-		}
-		if costPerPortionStr.Valid {
-			val := parseFloat(costPerPortionStr.String)
-			sc.CostPerPortion = &val
-		}
 		if invoiceNumber.Valid {
 			sc.InvoiceNumber = &invoiceNumber.String
 		}
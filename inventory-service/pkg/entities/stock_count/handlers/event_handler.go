@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"inventory-service/pkg/entities/stock_count/models"
+	"shared/events"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventHandler consumes domain events that affect stock counts
+type EventHandler struct {
+	dbHandler *DBHandler
+	logger    *logrus.Logger
+}
+
+// NewEventHandler creates a new stock count event handler
+func NewEventHandler(dbHandler *DBHandler, logger *logrus.Logger) *EventHandler {
+	return &EventHandler{
+		dbHandler: dbHandler,
+		logger:    logger,
+	}
+}
+
+// OutcomeInvoiceCreated registers the purchased stock of a supplier invoice
+func (h *EventHandler) OutcomeInvoiceCreated(ctx context.Context, event *events.Event) error {
+	var payload events.OutcomeInvoiceCreatedPayload
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	for _, item := range payload.Items {
+		price := item.Price
+		_, err := h.dbHandler.Create(ctx, &models.StockCountCreateRequest{
+			StockVariantID: item.StockVariantID,
+			InvoiceID:      &payload.InvoiceID,
+			Count:          item.Count,
+			Unit:           item.Unit,
+			UnitPrice:      &price,
+			PurchasedAt:    payload.TransactionDate,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register stock for invoice item %s: %w", item.InvoiceItemID, err)
+		}
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"invoice_id": payload.InvoiceID,
+		"items":      len(payload.Items),
+	}).Info("Stock registered from outcome invoice")
+	return nil
+}
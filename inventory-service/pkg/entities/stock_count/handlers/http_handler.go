@@ -3,9 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"inventory-service/pkg/entities/stock_count/models"
+	stockCountSQL "inventory-service/pkg/entities/stock_count/sql"
 	sharedHttp "shared/http"
 
 	"github.com/gorilla/mux"
@@ -25,33 +25,19 @@ func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
 
 // List handles GET /api/v1/inventory/stock-count
 func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 20
+	spec, err := stockCountSQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
 	}
 
-	// Check if filtering by variant
-	variantID := r.URL.Query().Get("stock_variant_id")
-	
-	var response *models.StockCountListResponse
-	var err error
-	
-	if variantID != "" {
-		response, err = h.dbHandler.ListByVariant(variantID, page, limit)
-	} else {
-		response, err = h.dbHandler.List(page, limit)
-	}
-	
+	response, err := h.dbHandler.List(r.Context(), spec)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list stock count records")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list stock count records")
+		sharedHttp.SendError(w, r, err, "Failed to list stock count records")
 		return
 	}
+	response.SetLinks(r.URL)
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock count records retrieved", response)
 }
@@ -61,10 +47,10 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	stockCount, err := h.dbHandler.GetByID(id)
+	stockCount, err := h.dbHandler.GetByID(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get stock count record")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to get stock count record")
+		sharedHttp.SendError(w, r, err, "Failed to get stock count record")
 		return
 	}
 
@@ -73,6 +59,7 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, stockCount.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock count record retrieved", stockCount)
 }
 
@@ -114,13 +101,14 @@ func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stockCount, err := h.dbHandler.Create(&req)
+	stockCount, err := h.dbHandler.Create(r.Context(), &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create stock count record")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to create stock count record")
+		sharedHttp.SendError(w, r, err, "Failed to create stock count record")
 		return
 	}
 
+	sharedHttp.SetETag(w, stockCount.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Stock count record created", stockCount)
 }
 
@@ -129,6 +117,12 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update stock count record")
+		return
+	}
+
 	var req models.StockCountUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode request body")
@@ -141,10 +135,10 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stockCount, err := h.dbHandler.Update(id, &req)
+	stockCount, err := h.dbHandler.Update(r.Context(), id, version, &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update stock count record")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update stock count record")
+		sharedHttp.SendError(w, r, err, "Failed to update stock count record")
 		return
 	}
 
@@ -153,6 +147,7 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, stockCount.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock count record updated", stockCount)
 }
 
@@ -161,6 +156,12 @@ func (h *HTTPHandler) MarkOut(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to mark stock out")
+		return
+	}
+
 	var req models.StockCountMarkOutRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode request body")
@@ -168,10 +169,10 @@ func (h *HTTPHandler) MarkOut(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	stockCount, err := h.dbHandler.MarkOut(id, req.IsOut)
+	stockCount, err := h.dbHandler.MarkOut(r.Context(), id, version, req.IsOut)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to mark stock out")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to mark stock out")
+		sharedHttp.SendError(w, r, err, "Failed to mark stock out")
 		return
 	}
 
@@ -184,6 +185,7 @@ func (h *HTTPHandler) MarkOut(w http.ResponseWriter, r *http.Request) {
 	if req.IsOut {
 		message = "Stock marked as out"
 	}
+	sharedHttp.SetETag(w, stockCount.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, message, stockCount)
 }
 
@@ -192,16 +194,28 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.dbHandler.Delete(id)
+	err := h.dbHandler.Delete(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete stock count record")
-		if err.Error() == "stock count record not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Stock count record not found")
-			return
-		}
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, err.Error())
+		sharedHttp.SendError(w, r, err, "Failed to delete stock count record")
 		return
 	}
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock count record deleted", nil)
 }
+
+// Restore handles POST /api/v1/inventory/stock-count/:id/restore
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	stockCount, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore stock count record")
+		sharedHttp.SendError(w, r, err, "Failed to restore stock count record")
+		return
+	}
+
+	sharedHttp.SetETag(w, stockCount.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock count record restored", stockCount)
+}
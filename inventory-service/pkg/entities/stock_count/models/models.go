@@ -4,21 +4,26 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"shared/db/queryspec"
+	"shared/money"
 )
 
 // StockCount represents an inventory count record for a stock variant
 type StockCount struct {
-	ID             string    `json:"id"`
-	StockVariantID string    `json:"stock_variant_id"`
-	InvoiceID      *string   `json:"invoice_id,omitempty"`
-	Count          float64   `json:"count"`
-	Unit           string    `json:"unit"`
-	UnitPrice      *float64  `json:"unit_price,omitempty"`
-	CostPerPortion *float64  `json:"cost_per_portion,omitempty"`
-	PurchasedAt    time.Time `json:"purchased_at"`
-	IsOut          bool      `json:"is_out"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             string       `json:"id"`
+	StockVariantID string       `json:"stock_variant_id"`
+	InvoiceID      *string      `json:"invoice_id,omitempty"`
+	Count          float64      `json:"count"`
+	Unit           string       `json:"unit"`
+	UnitPrice      *money.Money `json:"unit_price,omitempty"`
+	CostPerPortion *money.Money `json:"cost_per_portion,omitempty"`
+	PurchasedAt    time.Time    `json:"purchased_at"`
+	IsOut          bool         `json:"is_out"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	Version        int          `json:"version"`
+	DeletedAt      *time.Time   `json:"deleted_at,omitempty"`
 	// Joined fields (optional, populated on list/get)
 	StockVariantName *string `json:"stock_variant_name,omitempty"`
 	InvoiceNumber    *string `json:"invoice_number,omitempty"`
@@ -27,20 +32,20 @@ type StockCount struct {
 
 // StockCountCreateRequest represents a request to create a stock count record
 type StockCountCreateRequest struct {
-	StockVariantID string    `json:"stock_variant_id"`
-	InvoiceID      *string   `json:"invoice_id,omitempty"`
-	Count          float64   `json:"count"`
-	Unit           string    `json:"unit"`
-	UnitPrice      *float64  `json:"unit_price,omitempty"`
-	PurchasedAt    time.Time `json:"purchased_at"`
+	StockVariantID string       `json:"stock_variant_id"`
+	InvoiceID      *string      `json:"invoice_id,omitempty"`
+	Count          float64      `json:"count"`
+	Unit           string       `json:"unit"`
+	UnitPrice      *money.Money `json:"unit_price,omitempty"`
+	PurchasedAt    time.Time    `json:"purchased_at"`
 }
 
 // StockCountUpdateRequest represents a request to update a stock count record
 type StockCountUpdateRequest struct {
-	Count     *float64 `json:"count,omitempty"`
-	Unit      *string  `json:"unit,omitempty"`
-	UnitPrice *float64 `json:"unit_price,omitempty"`
-	IsOut     *bool    `json:"is_out,omitempty"`
+	Count     *float64     `json:"count,omitempty"`
+	Unit      *string      `json:"unit,omitempty"`
+	UnitPrice *money.Money `json:"unit_price,omitempty"`
+	IsOut     *bool        `json:"is_out,omitempty"`
 }
 
 // Supported units for stock count (all convertible to kg)
@@ -69,23 +74,24 @@ func ConvertToKG(count float64, unit string) (float64, error) {
 	}
 }
 
-// CalculateCostPerPortion calculates the cost per portion
+// CalculateCostPerPortion calculates the cost per portion, rounded to the scale of the
+// cost_per_portion column
 // totalKG: total weight in kilograms
 // unitPrice: price per unit of the original count
-// count: original count (before conversion to kg)
 // portionGrams: default portion size in grams (from settings)
-func CalculateCostPerPortion(totalKG float64, unitPrice float64, portionGrams float64) float64 {
+func CalculateCostPerPortion(totalKG float64, unitPrice money.Money, portionGrams float64) money.Money {
 	if totalKG <= 0 || portionGrams <= 0 {
-		return 0
+		return money.Money{}
 	}
 	// Total price = unitPrice (this is the total price for the purchase, not per kg)
-	// Number of portions = totalKG / (portionGrams / 1000)
-	portionKG := portionGrams / 1000
-	numPortions := totalKG / portionKG
-	if numPortions <= 0 {
-		return 0
+	// Cost per portion = unitPrice × portionGrams / total grams, multiplied first so
+	// the division is the only rounding step
+	totalGrams := money.DecimalFromFloat(totalKG * 1000)
+	cost, err := unitPrice.Mul(money.DecimalFromFloat(portionGrams)).Div(totalGrams)
+	if err != nil {
+		return money.Money{}
 	}
-	return unitPrice / numPortions
+	return cost.RoundTo(money.StoragePlaces)
 }
 
 // StockCountMarkOutRequest represents a request to mark stock as out
@@ -97,6 +103,6 @@ type StockCountMarkOutRequest struct {
 type StockCountListResponse struct {
 	StockCounts []StockCount `json:"stock_counts"`
 	Total       int          `json:"total"`
-	Page        int          `json:"page"`
 	Limit       int          `json:"limit"`
+	queryspec.PageInfo
 }
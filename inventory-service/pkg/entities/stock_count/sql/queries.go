@@ -2,62 +2,52 @@ package sql
 
 import (
 	"embed"
-	"fmt"
-	"strings"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
 )
 
 //go:embed scripts/*.sql
-var sqlFiles embed.FS
-
-// Queries holds all SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from the scripts directory
-func LoadQueries() (*Queries, error) {
-	queries := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files, err := sqlFiles.ReadDir("scripts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			content, err := sqlFiles.ReadFile("scripts/" + file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read SQL file %s: %w", file.Name(), err)
-			}
+var sqlScripts embed.FS
 
-			queryName := strings.TrimSuffix(file.Name(), ".sql")
-			queries.queries[queryName] = string(content)
-		}
-	}
-	return queries, nil
-}
-
-// Get retrieves a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, exists := q.queries[name]
-	if !exists {
-		return "", fmt.Errorf("query '%s' not found", name)
-	}
-	return query, nil
-}
-
-// SQL query constants
+// SQL query names
 const (
-	ListStockCountQuery           = "list_stock_count"
-	CountStockCountQuery          = "count_stock_count"
-	ListStockCountByVariantQuery  = "list_stock_count_by_variant"
-	CountStockCountByVariantQuery = "count_stock_count_by_variant"
-	GetStockCountByIDQuery        = "get_stock_count_by_id"
-	CreateStockCountQuery         = "create_stock_count"
-	UpdateStockCountQuery         = "update_stock_count"
-	MarkStockOutQuery             = "mark_stock_out"
-	DeleteStockCountQuery         = "delete_stock_count"
-	CalculateAvgCostQuery         = "calculate_avg_cost"
+	ListStockCountQuery    queries.Name = "list_stock_count"
+	CountStockCountQuery   queries.Name = "count_stock_count"
+	GetStockCountByIDQuery queries.Name = "get_stock_count_by_id"
+	CreateStockCountQuery  queries.Name = "create_stock_count"
+	UpdateStockCountQuery  queries.Name = "update_stock_count"
+	MarkStockOutQuery      queries.Name = "mark_stock_out"
+	DeleteStockCountQuery  queries.Name = "delete_stock_count"
+	RestoreStockCountQuery queries.Name = "restore_stock_count"
+	PurgeStockCountQuery   queries.Name = "purge_stock_count"
+	CalculateAvgCostQuery  queries.Name = "calculate_avg_cost"
 )
+
+// ListSchema whitelists the columns of list_stock_count that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("-purchased_at",
+	queryspec.Field{Name: "stock_variant_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "invoice_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "unit", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "count", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "is_out", Type: queryspec.Bool, Filterable: true},
+	queryspec.Field{Name: "purchased_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).WithSoftDelete()
+
+// LoadQueries loads and validates the stock count SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListStockCountQuery,
+		CountStockCountQuery,
+		GetStockCountByIDQuery,
+		CreateStockCountQuery,
+		UpdateStockCountQuery,
+		MarkStockOutQuery,
+		DeleteStockCountQuery,
+		RestoreStockCountQuery,
+		PurgeStockCountQuery,
+		CalculateAvgCostQuery,
+	)
+}
@@ -1,15 +1,18 @@
 -- Calculate and update the average cost per portion for a stock variant
--- Only considers active stock counts (is_out = false) with cost_per_portion > 0
-UPDATE stock_variants
+-- Only considers active stock counts (is_out = false, not deleted) with cost_per_portion > 0
+-- Returns the previous average too, so a change can be announced
+UPDATE stock_variants sv
 SET avg_cost = COALESCE(
     (SELECT AVG(cost_per_portion) 
      FROM stock_count 
-     WHERE stock_variant_id = $1 
+     WHERE stock_variant_id = @stock_variant_id 
        AND is_out = false 
+       AND deleted_at IS NULL
        AND cost_per_portion IS NOT NULL 
        AND cost_per_portion > 0),
     0
 ),
 updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-RETURNING id, avg_cost;
+FROM (SELECT id, avg_cost FROM stock_variants WHERE id = @stock_variant_id FOR UPDATE) old
+WHERE sv.id = old.id
+RETURNING sv.id, old.avg_cost, sv.avg_cost;
@@ -1 +1 @@
-SELECT COUNT(*) FROM stock_count;
+SELECT COUNT(*) FROM stock_count WHERE deleted_at IS NULL;
@@ -1 +0,0 @@
-SELECT COUNT(*) FROM stock_count WHERE stock_variant_id = $1;
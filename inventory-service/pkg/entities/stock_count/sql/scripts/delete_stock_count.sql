@@ -1 +1 @@
-DELETE FROM stock_count WHERE id = $1;
+UPDATE stock_count SET deleted_at = CURRENT_TIMESTAMP WHERE id = @id AND deleted_at IS NULL;
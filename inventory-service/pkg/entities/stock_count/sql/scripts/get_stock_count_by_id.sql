@@ -10,6 +10,7 @@ SELECT
     sc.is_out, 
     sc.created_at, 
     sc.updated_at,
+    sc.version,
     sv.name AS stock_variant_name,
     oi.invoice_number,
     s.name AS supplier_name
@@ -17,4 +18,4 @@ FROM stock_count sc
 LEFT JOIN stock_variants sv ON sc.stock_variant_id = sv.id
 LEFT JOIN outcome_invoices oi ON sc.invoice_id = oi.id
 LEFT JOIN suppliers s ON oi.supplier_id = s.id
-WHERE sc.id = $1;
+WHERE sc.id = @id AND sc.deleted_at IS NULL;
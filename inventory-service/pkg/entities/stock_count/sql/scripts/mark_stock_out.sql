@@ -1,5 +1,5 @@
 UPDATE stock_count
-SET is_out = $2,
+SET is_out = @is_out,
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-RETURNING id, stock_variant_id, invoice_id, count, unit, unit_price, cost_per_portion, purchased_at, is_out, created_at, updated_at;
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id, stock_variant_id, invoice_id, count, unit, unit_price, cost_per_portion, purchased_at, is_out, created_at, updated_at, version;
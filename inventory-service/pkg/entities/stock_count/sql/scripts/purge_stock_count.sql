@@ -0,0 +1,7 @@
+-- Hard delete stock counts soft deleted before the retention window
+WITH purged AS (
+    DELETE FROM stock_count
+    WHERE deleted_at < @deleted_before
+    RETURNING id
+)
+SELECT COUNT(*) FROM purged;
@@ -1,9 +1,9 @@
 UPDATE stock_count
-SET count = COALESCE($2, count),
-    unit = COALESCE($3, unit),
-    unit_price = COALESCE($4, unit_price),
-    cost_per_portion = COALESCE($5, cost_per_portion),
-    is_out = COALESCE($6, is_out),
+SET count = COALESCE(@count, count),
+    unit = COALESCE(@unit, unit),
+    unit_price = COALESCE(@unit_price, unit_price),
+    cost_per_portion = COALESCE(@cost_per_portion, cost_per_portion),
+    is_out = COALESCE(@is_out, is_out),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-RETURNING id, stock_variant_id, invoice_id, count, unit, unit_price, cost_per_portion, purchased_at, is_out, created_at, updated_at;
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id, stock_variant_id, invoice_id, count, unit, unit_price, cost_per_portion, purchased_at, is_out, created_at, updated_at, version;
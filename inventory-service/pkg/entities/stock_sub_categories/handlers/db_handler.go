@@ -1,24 +1,34 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"inventory-service/pkg/entities/stock_sub_categories/models"
 	stockSubCategorySQL "inventory-service/pkg/entities/stock_sub_categories/sql"
+	"shared/audit"
 	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// auditEntity names the entity in the audit log
+const auditEntity = "stock_sub_category"
+
 // DBHandler handles database operations for stock sub-categories
 type DBHandler struct {
 	db      *sharedDb.DbHandler
-	queries *stockSubCategorySQL.Queries
+	audit   *audit.Log
+	queries *queries.Registry
 	logger  *logrus.Logger
 }
 
 // NewDBHandler creates a new database handler
-func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, error) {
+func NewDBHandler(db *sharedDb.DbHandler, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
 	queries, err := stockSubCategorySQL.LoadQueries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
@@ -26,31 +36,30 @@ func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, er
 
 	return &DBHandler{
 		db:      db,
+		audit:   auditLog,
 		queries: queries,
 		logger:  logger,
 	}, nil
 }
 
-// List returns a paginated list of stock sub-categories
-func (h *DBHandler) List(page, limit int) (*models.StockSubCategoryListResponse, error) {
-	offset := (page - 1) * limit
-
-	countQuery, err := h.queries.Get(stockSubCategorySQL.CountStockSubCategoriesQuery)
+// List returns a page of stock sub-categories matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.StockSubCategoryListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(stockSubCategorySQL.ListStockSubCategoriesQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get count query: %w", err)
+		return nil, fmt.Errorf("failed to build stock sub-categories count: %w", err)
 	}
 
 	var total int
-	if err := h.db.QueryRow(countQuery).Scan(&total); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
 		return nil, fmt.Errorf("failed to count stock sub-categories: %w", err)
 	}
 
-	listQuery, err := h.queries.Get(stockSubCategorySQL.ListStockSubCategoriesQuery)
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(stockSubCategorySQL.ListStockSubCategoriesQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
+		return nil, fmt.Errorf("failed to build stock sub-categories list: %w", err)
 	}
 
-	rows, err := h.db.Query(listQuery, limit, offset)
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list stock sub-categories: %w", err)
 	}
@@ -61,7 +70,7 @@ func (h *DBHandler) List(page, limit int) (*models.StockSubCategoryListResponse,
 		var subCat models.StockSubCategory
 		var description sql.NullString
 
-		if err := rows.Scan(&subCat.ID, &subCat.Name, &description, &subCat.StockCategoryID, &subCat.DisplayOrder, &subCat.IsActive, &subCat.CreatedAt, &subCat.UpdatedAt); err != nil {
+		if err := rows.Scan(&subCat.ID, &subCat.Name, &description, &subCat.StockCategoryID, &subCat.DisplayOrder, &subCat.IsActive, &subCat.CreatedAt, &subCat.UpdatedAt, &subCat.Version, &subCat.DeletedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan stock sub-category: %w", err)
 		}
 
@@ -71,73 +80,25 @@ func (h *DBHandler) List(page, limit int) (*models.StockSubCategoryListResponse,
 		subCategories = append(subCategories, subCat)
 	}
 
-	return &models.StockSubCategoryListResponse{
-		SubCategories: subCategories,
-		Total:         total,
-		Page:          page,
-		Limit:         limit,
-	}, nil
-}
-
-// ListByCategory returns a paginated list of stock sub-categories filtered by category
-func (h *DBHandler) ListByCategory(categoryID string, page, limit int) (*models.StockSubCategoryListResponse, error) {
-	offset := (page - 1) * limit
-
-	countQuery, err := h.queries.Get(stockSubCategorySQL.CountStockSubCategoriesByCategoryQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get count query: %w", err)
-	}
-
-	var total int
-	if err := h.db.QueryRow(countQuery, categoryID).Scan(&total); err != nil {
-		return nil, fmt.Errorf("failed to count stock sub-categories: %w", err)
-	}
-
-	listQuery, err := h.queries.Get(stockSubCategorySQL.ListStockSubCategoriesByCategoryQuery)
+	subCategories, pageInfo, err := queryspec.Paginate(spec, subCategories)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
-	}
-
-	rows, err := h.db.Query(listQuery, categoryID, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list stock sub-categories: %w", err)
-	}
-	defer rows.Close()
-
-	var subCategories []models.StockSubCategory
-	for rows.Next() {
-		var subCat models.StockSubCategory
-		var description sql.NullString
-
-		if err := rows.Scan(&subCat.ID, &subCat.Name, &description, &subCat.StockCategoryID, &subCat.DisplayOrder, &subCat.IsActive, &subCat.CreatedAt, &subCat.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan stock sub-category: %w", err)
-		}
-
-		if description.Valid {
-			subCat.Description = &description.String
-		}
-		subCategories = append(subCategories, subCat)
+		return nil, err
 	}
 
 	return &models.StockSubCategoryListResponse{
 		SubCategories: subCategories,
 		Total:         total,
-		Page:          page,
-		Limit:         limit,
+		Limit:         spec.Limit,
+		PageInfo:      pageInfo,
 	}, nil
 }
 
 // GetByID returns a stock sub-category by ID
-func (h *DBHandler) GetByID(id string) (*models.StockSubCategory, error) {
-	query, err := h.queries.Get(stockSubCategorySQL.GetStockSubCategoryByIDQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.StockSubCategory, error) {
 	var subCat models.StockSubCategory
 	var description sql.NullString
 
-	err = h.db.QueryRow(query, id).Scan(&subCat.ID, &subCat.Name, &description, &subCat.StockCategoryID, &subCat.DisplayOrder, &subCat.IsActive, &subCat.CreatedAt, &subCat.UpdatedAt)
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockSubCategorySQL.GetStockSubCategoryByIDQuery), queries.Args{"id": id}).Scan(&subCat.ID, &subCat.Name, &description, &subCat.StockCategoryID, &subCat.DisplayOrder, &subCat.IsActive, &subCat.CreatedAt, &subCat.UpdatedAt, &subCat.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -153,12 +114,14 @@ func (h *DBHandler) GetByID(id string) (*models.StockSubCategory, error) {
 }
 
 // Create creates a new stock sub-category
-func (h *DBHandler) Create(req *models.StockSubCategoryCreateRequest) (*models.StockSubCategory, error) {
-	query, err := h.queries.Get(stockSubCategorySQL.CreateStockSubCategoryQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+func (h *DBHandler) Create(ctx context.Context, req *models.StockSubCategoryCreateRequest) (*models.StockSubCategory, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.StockSubCategory, error) {
+		return h.create(ctx, req)
+	})
+}
 
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.StockSubCategoryCreateRequest) (*models.StockSubCategory, error) {
 	// Set defaults if not provided
 	displayOrder := 0
 	if req.DisplayOrder != nil {
@@ -172,8 +135,14 @@ func (h *DBHandler) Create(req *models.StockSubCategoryCreateRequest) (*models.S
 	var subCat models.StockSubCategory
 	var description sql.NullString
 
-	err = h.db.QueryRow(query, req.Name, req.Description, req.StockCategoryID, displayOrder, isActive).Scan(
-		&subCat.ID, &subCat.Name, &description, &subCat.StockCategoryID, &subCat.DisplayOrder, &subCat.IsActive, &subCat.CreatedAt, &subCat.UpdatedAt,
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockSubCategorySQL.CreateStockSubCategoryQuery), queries.Args{
+		"name":              req.Name,
+		"description":       req.Description,
+		"stock_category_id": req.StockCategoryID,
+		"display_order":     displayOrder,
+		"is_active":         isActive,
+	}).Scan(
+		&subCat.ID, &subCat.Name, &description, &subCat.StockCategoryID, &subCat.DisplayOrder, &subCat.IsActive, &subCat.CreatedAt, &subCat.UpdatedAt, &subCat.Version,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create stock sub-category: %w", err)
@@ -187,22 +156,31 @@ func (h *DBHandler) Create(req *models.StockSubCategoryCreateRequest) (*models.S
 	return &subCat, nil
 }
 
-// Update updates an existing stock sub-category
-func (h *DBHandler) Update(id string, req *models.StockSubCategoryUpdateRequest) (*models.StockSubCategory, error) {
-	query, err := h.queries.Get(stockSubCategorySQL.UpdateStockSubCategoryQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+// Update updates an existing stock sub-category if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.StockSubCategoryUpdateRequest) (*models.StockSubCategory, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.StockSubCategory, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
 
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.StockSubCategoryUpdateRequest) (*models.StockSubCategory, error) {
 	var subCat models.StockSubCategory
 	var description sql.NullString
 
-	err = h.db.QueryRow(query, id, req.Name, req.Description, req.DisplayOrder, req.IsActive).Scan(
-		&subCat.ID, &subCat.Name, &description, &subCat.StockCategoryID, &subCat.DisplayOrder, &subCat.IsActive, &subCat.CreatedAt, &subCat.UpdatedAt,
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockSubCategorySQL.UpdateStockSubCategoryQuery), queries.Args{
+		"id":            id,
+		"version":       version,
+		"name":          req.Name,
+		"description":   req.Description,
+		"display_order": req.DisplayOrder,
+		"is_active":     req.IsActive,
+	}).Scan(
+		&subCat.ID, &subCat.Name, &description, &subCat.StockCategoryID, &subCat.DisplayOrder, &subCat.IsActive, &subCat.CreatedAt, &subCat.UpdatedAt, &subCat.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, h.versionMismatch(ctx, id)
 		}
 		return nil, fmt.Errorf("failed to update stock sub-category: %w", err)
 	}
@@ -215,37 +193,76 @@ func (h *DBHandler) Update(id string, req *models.StockSubCategoryUpdateRequest)
 	return &subCat, nil
 }
 
-// Delete deletes a stock sub-category
-func (h *DBHandler) Delete(id string) error {
-	checkQuery, err := h.queries.Get(stockSubCategorySQL.CheckStockSubCategoryDependenciesQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get check query: %w", err)
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return err
 	}
+	return sharedErrors.PreconditionFailed("stock_sub_category_version_mismatch", "stock sub-category was modified by another request")
+}
 
+// Delete deletes a stock sub-category
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
+
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
 	var count int
-	if err := h.db.QueryRow(checkQuery, id).Scan(&count); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockSubCategorySQL.CheckStockSubCategoryDependenciesQuery), queries.Args{"id": id}).Scan(&count); err != nil {
 		return fmt.Errorf("failed to check dependencies: %w", err)
 	}
 
 	if count > 0 {
-		return fmt.Errorf("cannot delete sub-category: %d stock variants depend on it", count)
-	}
-
-	deleteQuery, err := h.queries.Get(stockSubCategorySQL.DeleteStockSubCategoryQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get delete query: %w", err)
+		return sharedErrors.Conflict("stock_sub_category_in_use", fmt.Sprintf("cannot delete sub-category: %d stock variants depend on it", count))
 	}
 
-	result, err := h.db.Exec(deleteQuery, id)
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(stockSubCategorySQL.DeleteStockSubCategoryQuery), queries.Args{"id": id})
 	if err != nil {
 		return fmt.Errorf("failed to delete stock sub-category: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("stock sub-category not found")
+		return sharedErrors.NotFound("stock_sub_category_not_found", "stock sub-category not found")
 	}
 
 	h.logger.WithField("id", id).Info("Stock sub-category deleted")
 	return nil
 }
+
+// Restore brings back a soft deleted stock sub-category
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.StockSubCategory, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.StockSubCategory, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.StockSubCategory, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(stockSubCategorySQL.RestoreStockSubCategoryQuery), queries.Args{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore stock sub-category: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("stock_sub_category_not_found", "deleted stock sub-category not found")
+	}
+
+	h.logger.WithField("id", id).Info("Stock sub-category restored")
+	return h.GetByID(ctx, id)
+}
+
+// Purge hard deletes the stock sub-categories soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockSubCategorySQL.PurgeStockSubCategoriesQuery), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge stock sub-categories: %w", err)
+	}
+	return purged, nil
+}
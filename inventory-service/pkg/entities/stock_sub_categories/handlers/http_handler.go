@@ -3,9 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"inventory-service/pkg/entities/stock_sub_categories/models"
+	stockSubCategorySQL "inventory-service/pkg/entities/stock_sub_categories/sql"
 	sharedHttp "shared/http"
 
 	"github.com/gorilla/mux"
@@ -25,33 +25,19 @@ func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
 
 // List handles GET /api/v1/stock/sub-categories
 func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 100
-	}
-
-	// Check if filtering by category
-	categoryID := r.URL.Query().Get("category_id")
-
-	var response *models.StockSubCategoryListResponse
-	var err error
-
-	if categoryID != "" {
-		response, err = h.dbHandler.ListByCategory(categoryID, page, limit)
-	} else {
-		response, err = h.dbHandler.List(page, limit)
+	spec, err := stockSubCategorySQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
 	}
 
+	response, err := h.dbHandler.List(r.Context(), spec)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list stock sub-categories")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list stock sub-categories")
+		sharedHttp.SendError(w, r, err, "Failed to list stock sub-categories")
 		return
 	}
+	response.SetLinks(r.URL)
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock sub-categories retrieved", response)
 }
@@ -61,10 +47,10 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	subCategory, err := h.dbHandler.GetByID(id)
+	subCategory, err := h.dbHandler.GetByID(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get stock sub-category")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to get stock sub-category")
+		sharedHttp.SendError(w, r, err, "Failed to get stock sub-category")
 		return
 	}
 
@@ -73,6 +59,7 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, subCategory.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock sub-category retrieved", subCategory)
 }
 
@@ -95,13 +82,14 @@ func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	subCategory, err := h.dbHandler.Create(&req)
+	subCategory, err := h.dbHandler.Create(r.Context(), &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create stock sub-category")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to create stock sub-category")
+		sharedHttp.SendError(w, r, err, "Failed to create stock sub-category")
 		return
 	}
 
+	sharedHttp.SetETag(w, subCategory.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Stock sub-category created", subCategory)
 }
 
@@ -110,6 +98,12 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update stock sub-category")
+		return
+	}
+
 	var req models.StockSubCategoryUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode request body")
@@ -117,10 +111,10 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	subCategory, err := h.dbHandler.Update(id, &req)
+	subCategory, err := h.dbHandler.Update(r.Context(), id, version, &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update stock sub-category")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update stock sub-category")
+		sharedHttp.SendError(w, r, err, "Failed to update stock sub-category")
 		return
 	}
 
@@ -129,6 +123,7 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, subCategory.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock sub-category updated", subCategory)
 }
 
@@ -137,16 +132,28 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.dbHandler.Delete(id)
+	err := h.dbHandler.Delete(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete stock sub-category")
-		if err.Error() == "stock sub-category not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Stock sub-category not found")
-			return
-		}
-		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		sharedHttp.SendError(w, r, err, "Failed to delete stock sub-category")
 		return
 	}
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock sub-category deleted", nil)
 }
+
+// Restore handles POST /api/v1/stock/sub-categories/:id/restore
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	subCategory, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore stock sub-category")
+		sharedHttp.SendError(w, r, err, "Failed to restore stock sub-category")
+		return
+	}
+
+	sharedHttp.SetETag(w, subCategory.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock sub-category restored", subCategory)
+}
@@ -2,18 +2,22 @@ package models
 
 import (
 	"time"
+
+	"shared/db/queryspec"
 )
 
 // StockSubCategory represents a stock sub-category
 type StockSubCategory struct {
-	ID            string    `json:"id"`
-	Name          string    `json:"name"`
-	Description   *string   `json:"description,omitempty"`
-	StockCategoryID string  `json:"stock_category_id"`
-	DisplayOrder  int       `json:"display_order"`
-	IsActive      bool      `json:"is_active"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Description     *string    `json:"description,omitempty"`
+	StockCategoryID string     `json:"stock_category_id"`
+	DisplayOrder    int        `json:"display_order"`
+	IsActive        bool       `json:"is_active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Version         int        `json:"version"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
 }
 
 // StockSubCategoryCreateRequest represents a request to create a stock sub-category
@@ -36,7 +40,7 @@ type StockSubCategoryUpdateRequest struct {
 // StockSubCategoryListResponse represents a paginated list of stock sub-categories
 type StockSubCategoryListResponse struct {
 	SubCategories []StockSubCategory `json:"sub_categories"`
-	Total         int                 `json:"total"`
-	Page          int                 `json:"page"`
-	Limit         int                 `json:"limit"`
+	Total         int                `json:"total"`
+	Limit         int                `json:"limit"`
+	queryspec.PageInfo
 }
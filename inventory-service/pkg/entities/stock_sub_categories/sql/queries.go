@@ -2,61 +2,46 @@ package sql
 
 import (
 	"embed"
-	"fmt"
-	"strings"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
 )
 
 //go:embed scripts/*.sql
-var sqlFiles embed.FS
-
-// Queries holds all SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from the scripts directory
-func LoadQueries() (*Queries, error) {
-	queries := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files, err := sqlFiles.ReadDir("scripts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			content, err := sqlFiles.ReadFile("scripts/" + file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read SQL file %s: %w", file.Name(), err)
-			}
+var sqlScripts embed.FS
 
-			queryName := strings.TrimSuffix(file.Name(), ".sql")
-			queries.queries[queryName] = string(content)
-		}
-	}
-	return queries, nil
-}
-
-// Get retrieves a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, exists := q.queries[name]
-	if !exists {
-		return "", fmt.Errorf("query '%s' not found", name)
-	}
-	return query, nil
-}
-
-// SQL query constants
+// SQL query names
 const (
-	ListStockSubCategoriesQuery             = "list_stock_sub_categories"
-	ListStockSubCategoriesByCategoryQuery   = "list_stock_sub_categories_by_category"
-	CountStockSubCategoriesQuery            = "count_stock_sub_categories"
-	CountStockSubCategoriesByCategoryQuery  = "count_stock_sub_categories_by_category"
-	GetStockSubCategoryByIDQuery            = "get_stock_sub_category_by_id"
-	CreateStockSubCategoryQuery             = "create_stock_sub_category"
-	UpdateStockSubCategoryQuery             = "update_stock_sub_category"
-	DeleteStockSubCategoryQuery             = "delete_stock_sub_category"
-	CheckStockSubCategoryDependenciesQuery  = "check_stock_sub_category_dependencies"
+	ListStockSubCategoriesQuery            queries.Name = "list_stock_sub_categories"
+	GetStockSubCategoryByIDQuery           queries.Name = "get_stock_sub_category_by_id"
+	CreateStockSubCategoryQuery            queries.Name = "create_stock_sub_category"
+	UpdateStockSubCategoryQuery            queries.Name = "update_stock_sub_category"
+	DeleteStockSubCategoryQuery            queries.Name = "delete_stock_sub_category"
+	RestoreStockSubCategoryQuery           queries.Name = "restore_stock_sub_category"
+	PurgeStockSubCategoriesQuery           queries.Name = "purge_stock_sub_categories"
+	CheckStockSubCategoryDependenciesQuery queries.Name = "check_stock_sub_category_dependencies"
 )
+
+// ListSchema whitelists the columns of list_stock_sub_categories that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("display_order,name",
+	queryspec.Field{Name: "name", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "stock_category_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "display_order", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "is_active", Type: queryspec.Bool, Filterable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).WithAlias("category_id", "stock_category_id", queryspec.Eq).WithDefaultLimit(100).WithSoftDelete()
+
+// LoadQueries loads and validates the stock sub-category SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListStockSubCategoriesQuery,
+		GetStockSubCategoryByIDQuery,
+		CreateStockSubCategoryQuery,
+		UpdateStockSubCategoryQuery,
+		DeleteStockSubCategoryQuery,
+		RestoreStockSubCategoryQuery,
+		PurgeStockSubCategoriesQuery,
+		CheckStockSubCategoryDependenciesQuery,
+	)
+}
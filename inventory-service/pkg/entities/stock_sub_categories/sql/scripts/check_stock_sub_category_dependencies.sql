@@ -1 +1 @@
-SELECT COUNT(*) FROM stock_variants WHERE stock_sub_category_id = $1;
+SELECT COUNT(*) FROM stock_variants WHERE stock_sub_category_id = @id AND deleted_at IS NULL;
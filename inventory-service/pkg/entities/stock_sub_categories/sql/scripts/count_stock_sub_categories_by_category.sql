@@ -1 +0,0 @@
-SELECT COUNT(*) FROM stock_sub_categories WHERE stock_category_id = $1;
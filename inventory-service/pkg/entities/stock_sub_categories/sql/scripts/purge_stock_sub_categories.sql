@@ -0,0 +1,8 @@
+-- Hard delete sub-categories soft deleted before the retention window that nothing references
+WITH purged AS (
+    DELETE FROM stock_sub_categories ssc
+    WHERE ssc.deleted_at < @deleted_before
+      AND NOT EXISTS (SELECT 1 FROM stock_variants sv WHERE sv.stock_sub_category_id = ssc.id)
+    RETURNING ssc.id
+)
+SELECT COUNT(*) FROM purged;
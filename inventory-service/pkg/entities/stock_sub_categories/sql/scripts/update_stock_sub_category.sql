@@ -1,8 +1,8 @@
 UPDATE stock_sub_categories
-SET name = COALESCE($2, name),
-    description = COALESCE($3, description),
-    display_order = COALESCE($4, display_order),
-    is_active = COALESCE($5, is_active),
+SET name = COALESCE(@name, name),
+    description = COALESCE(@description, description),
+    display_order = COALESCE(@display_order, display_order),
+    is_active = COALESCE(@is_active, is_active),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-RETURNING id, name, description, stock_category_id, display_order, is_active, created_at, updated_at;
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id, name, description, stock_category_id, display_order, is_active, created_at, updated_at, version;
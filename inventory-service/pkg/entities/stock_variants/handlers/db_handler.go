@@ -1,24 +1,34 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"inventory-service/pkg/entities/stock_variants/models"
 	stockVariantSQL "inventory-service/pkg/entities/stock_variants/sql"
+	"shared/audit"
 	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// auditEntity names the entity in the audit log
+const auditEntity = "stock_variant"
+
 // DBHandler handles database operations for stock variants
 type DBHandler struct {
 	db      *sharedDb.DbHandler
-	queries *stockVariantSQL.Queries
+	audit   *audit.Log
+	queries *queries.Registry
 	logger  *logrus.Logger
 }
 
 // NewDBHandler creates a new database handler
-func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, error) {
+func NewDBHandler(db *sharedDb.DbHandler, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
 	queries, err := stockVariantSQL.LoadQueries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
@@ -26,53 +36,30 @@ func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, er
 
 	return &DBHandler{
 		db:      db,
+		audit:   auditLog,
 		queries: queries,
 		logger:  logger,
 	}, nil
 }
 
-// ListAll returns all active stock variants without pagination
-func (h *DBHandler) ListAll() (*models.StockVariantListResponse, error) {
-	listQuery, err := h.queries.Get(stockVariantSQL.ListAllStockVariantsQuery)
+// List returns a page of stock variants matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.StockVariantListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(stockVariantSQL.ListStockVariantsQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
+		return nil, fmt.Errorf("failed to build stock variants count: %w", err)
 	}
 
-	rows, err := h.db.Query(listQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list stock variants: %w", err)
+	var total int
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count stock variants: %w", err)
 	}
-	defer rows.Close()
 
-	var variants []models.StockVariant
-	for rows.Next() {
-		var variant models.StockVariant
-
-		if err := rows.Scan(&variant.ID, &variant.Name, &variant.Description, &variant.StockSubCategoryID, &variant.AvgCost, &variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan stock variant: %w", err)
-		}
-
-		variants = append(variants, variant)
-	}
-
-	return &models.StockVariantListResponse{
-		Variants: variants,
-		Total:    len(variants),
-		Page:     1,
-		Limit:    len(variants),
-	}, nil
-}
-
-// List returns a paginated list of active stock variants
-func (h *DBHandler) List(page, limit int) (*models.StockVariantListResponse, error) {
-	offset := (page - 1) * limit
-
-	listQuery, err := h.queries.Get(stockVariantSQL.ListStockVariantsQuery)
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(stockVariantSQL.ListStockVariantsQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
+		return nil, fmt.Errorf("failed to build stock variants list: %w", err)
 	}
 
-	rows, err := h.db.Query(listQuery, limit, offset)
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list stock variants: %w", err)
 	}
@@ -82,99 +69,31 @@ func (h *DBHandler) List(page, limit int) (*models.StockVariantListResponse, err
 	for rows.Next() {
 		var variant models.StockVariant
 
-		if err := rows.Scan(&variant.ID, &variant.Name, &variant.Description, &variant.StockSubCategoryID, &variant.AvgCost, &variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt); err != nil {
+		if err := rows.Scan(&variant.ID, &variant.Name, &variant.Description, &variant.StockSubCategoryID, &variant.StockCategoryID, &variant.AvgCost, &variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt, &variant.Version, &variant.DeletedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan stock variant: %w", err)
 		}
 
 		variants = append(variants, variant)
 	}
 
-	return &models.StockVariantListResponse{
-		Variants: variants,
-		Total:    len(variants),
-		Page:     page,
-		Limit:    limit,
-	}, nil
-}
-
-// ListByCategory returns a paginated list of active stock variants filtered by category
-func (h *DBHandler) ListByCategory(categoryID string, page, limit int) (*models.StockVariantListResponse, error) {
-	offset := (page - 1) * limit
-
-	listQuery, err := h.queries.Get(stockVariantSQL.ListStockVariantsByCategoryQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
-	}
-
-	rows, err := h.db.Query(listQuery, categoryID, limit, offset)
+	variants, pageInfo, err := queryspec.Paginate(spec, variants)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list stock variants: %w", err)
-	}
-	defer rows.Close()
-
-	var variants []models.StockVariant
-	for rows.Next() {
-		var variant models.StockVariant
-
-		if err := rows.Scan(&variant.ID, &variant.Name, &variant.Description, &variant.StockSubCategoryID, &variant.AvgCost, &variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan stock variant: %w", err)
-		}
-
-		variants = append(variants, variant)
+		return nil, err
 	}
 
 	return &models.StockVariantListResponse{
 		Variants: variants,
-		Total:    len(variants),
-		Page:     page,
-		Limit:    limit,
-	}, nil
-}
-
-// ListBySubCategory returns a paginated list of active stock variants filtered by sub-category
-func (h *DBHandler) ListBySubCategory(subCategoryID string, page, limit int) (*models.StockVariantListResponse, error) {
-	offset := (page - 1) * limit
-
-	listQuery, err := h.queries.Get(stockVariantSQL.ListStockVariantsBySubCategoryQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
-	}
-
-	rows, err := h.db.Query(listQuery, subCategoryID, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list stock variants: %w", err)
-	}
-	defer rows.Close()
-
-	var variants []models.StockVariant
-	for rows.Next() {
-		var variant models.StockVariant
-
-		if err := rows.Scan(&variant.ID, &variant.Name, &variant.Description, &variant.StockSubCategoryID, &variant.AvgCost, &variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan stock variant: %w", err)
-		}
-
-		variants = append(variants, variant)
-	}
-
-	return &models.StockVariantListResponse{
-		Variants: variants,
-		Total:    len(variants),
-		Page:     page,
-		Limit:    limit,
+		Total:    total,
+		Limit:    spec.Limit,
+		PageInfo: pageInfo,
 	}, nil
 }
 
 // GetByID returns a stock variant by ID
-func (h *DBHandler) GetByID(id string) (*models.StockVariant, error) {
-	query, err := h.queries.Get(stockVariantSQL.GetStockVariantByIDQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.StockVariant, error) {
 	var variant models.StockVariant
 
-	err = h.db.QueryRow(query, id).Scan(&variant.ID, &variant.Name, &variant.Description, &variant.StockSubCategoryID, &variant.AvgCost, &variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt)
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockVariantSQL.GetStockVariantByIDQuery), queries.Args{"id": id}).Scan(&variant.ID, &variant.Name, &variant.Description, &variant.StockSubCategoryID, &variant.AvgCost, &variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt, &variant.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -186,12 +105,14 @@ func (h *DBHandler) GetByID(id string) (*models.StockVariant, error) {
 }
 
 // Create creates a new stock variant
-func (h *DBHandler) Create(req *models.StockVariantCreateRequest) (*models.StockVariant, error) {
-	query, err := h.queries.Get(stockVariantSQL.CreateStockVariantQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+func (h *DBHandler) Create(ctx context.Context, req *models.StockVariantCreateRequest) (*models.StockVariant, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.StockVariant, error) {
+		return h.create(ctx, req)
+	})
+}
 
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.StockVariantCreateRequest) (*models.StockVariant, error) {
 	// Set defaults if not provided
 	isActive := true
 	if req.IsActive != nil {
@@ -200,8 +121,13 @@ func (h *DBHandler) Create(req *models.StockVariantCreateRequest) (*models.Stock
 
 	var variant models.StockVariant
 
-	err = h.db.QueryRow(query, req.Name, req.Description, req.StockSubCategoryID, isActive).Scan(
-		&variant.ID, &variant.Name, &variant.Description, &variant.StockSubCategoryID, &variant.AvgCost, &variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt,
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockVariantSQL.CreateStockVariantQuery), queries.Args{
+		"name":                  req.Name,
+		"description":           req.Description,
+		"stock_sub_category_id": req.StockSubCategoryID,
+		"is_active":             isActive,
+	}).Scan(
+		&variant.ID, &variant.Name, &variant.Description, &variant.StockSubCategoryID, &variant.AvgCost, &variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt, &variant.Version,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create stock variant: %w", err)
@@ -211,21 +137,29 @@ func (h *DBHandler) Create(req *models.StockVariantCreateRequest) (*models.Stock
 	return &variant, nil
 }
 
-// Update updates an existing stock variant
-func (h *DBHandler) Update(id string, req *models.StockVariantUpdateRequest) (*models.StockVariant, error) {
-	query, err := h.queries.Get(stockVariantSQL.UpdateStockVariantQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+// Update updates an existing stock variant if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.StockVariantUpdateRequest) (*models.StockVariant, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.StockVariant, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
 
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.StockVariantUpdateRequest) (*models.StockVariant, error) {
 	var variant models.StockVariant
 
-	err = h.db.QueryRow(query, id, req.Name, req.Description, req.IsActive).Scan(
-		&variant.ID, &variant.Name, &variant.Description, &variant.StockSubCategoryID, &variant.AvgCost, &variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt,
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockVariantSQL.UpdateStockVariantQuery), queries.Args{
+		"id":          id,
+		"version":     version,
+		"name":        req.Name,
+		"description": req.Description,
+		"is_active":   req.IsActive,
+	}).Scan(
+		&variant.ID, &variant.Name, &variant.Description, &variant.StockSubCategoryID, &variant.AvgCost, &variant.IsActive, &variant.CreatedAt, &variant.UpdatedAt, &variant.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, h.versionMismatch(ctx, id)
 		}
 		return nil, fmt.Errorf("failed to update stock variant: %w", err)
 	}
@@ -234,37 +168,76 @@ func (h *DBHandler) Update(id string, req *models.StockVariantUpdateRequest) (*m
 	return &variant, nil
 }
 
-// Delete deletes a stock variant
-func (h *DBHandler) Delete(id string) error {
-	checkQuery, err := h.queries.Get(stockVariantSQL.CheckStockVariantDependenciesQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get check query: %w", err)
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return err
 	}
+	return sharedErrors.PreconditionFailed("stock_variant_version_mismatch", "stock variant was modified by another request")
+}
+
+// Delete deletes a stock variant
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
 
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
 	var count int
-	if err := h.db.QueryRow(checkQuery, id).Scan(&count); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockVariantSQL.CheckStockVariantDependenciesQuery), queries.Args{"id": id}).Scan(&count); err != nil {
 		return fmt.Errorf("failed to check dependencies: %w", err)
 	}
 
 	if count > 0 {
-		return fmt.Errorf("cannot delete variant: %d dependencies found", count)
+		return sharedErrors.Conflict("stock_variant_in_use", fmt.Sprintf("cannot delete variant: %d dependencies found", count))
 	}
 
-	deleteQuery, err := h.queries.Get(stockVariantSQL.DeleteStockVariantQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get delete query: %w", err)
-	}
-
-	result, err := h.db.Exec(deleteQuery, id)
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(stockVariantSQL.DeleteStockVariantQuery), queries.Args{"id": id})
 	if err != nil {
 		return fmt.Errorf("failed to delete stock variant: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("stock variant not found")
+		return sharedErrors.NotFound("stock_variant_not_found", "stock variant not found")
 	}
 
 	h.logger.WithField("id", id).Info("Stock variant deleted")
 	return nil
 }
+
+// Restore brings back a soft deleted stock variant
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.StockVariant, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.StockVariant, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.StockVariant, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(stockVariantSQL.RestoreStockVariantQuery), queries.Args{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore stock variant: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("stock_variant_not_found", "deleted stock variant not found")
+	}
+
+	h.logger.WithField("id", id).Info("Stock variant restored")
+	return h.GetByID(ctx, id)
+}
+
+// Purge hard deletes the stock variants soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(stockVariantSQL.PurgeStockVariantsQuery), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge stock variants: %w", err)
+	}
+	return purged, nil
+}
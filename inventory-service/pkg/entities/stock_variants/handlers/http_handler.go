@@ -3,9 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"inventory-service/pkg/entities/stock_variants/models"
+	stockVariantSQL "inventory-service/pkg/entities/stock_variants/sql"
 	sharedHttp "shared/http"
 
 	"github.com/gorilla/mux"
@@ -25,43 +25,19 @@ func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
 
 // List handles GET /api/v1/stock/variants
 func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
-	// Check if filtering by sub-category or category
-	subCategoryID := r.URL.Query().Get("sub_category_id")
-	categoryID := r.URL.Query().Get("category_id")
-
-	var response *models.StockVariantListResponse
-	var err error
-
-	if subCategoryID != "" {
-		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-		if page < 1 {
-			page = 1
-		}
-		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-		if limit < 1 || limit > 100 {
-			limit = 100
-		}
-		response, err = h.dbHandler.ListBySubCategory(subCategoryID, page, limit)
-	} else if categoryID != "" {
-		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-		if page < 1 {
-			page = 1
-		}
-		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-		if limit < 1 || limit > 100 {
-			limit = 100
-		}
-		response, err = h.dbHandler.ListByCategory(categoryID, page, limit)
-	} else {
-		// No filters - return all active stock variants
-		response, err = h.dbHandler.ListAll()
+	spec, err := stockVariantSQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
 	}
 
+	response, err := h.dbHandler.List(r.Context(), spec)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list stock variants")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list stock variants")
+		sharedHttp.SendError(w, r, err, "Failed to list stock variants")
 		return
 	}
+	response.SetLinks(r.URL)
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock variants retrieved", response)
 }
@@ -71,10 +47,10 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	variant, err := h.dbHandler.GetByID(id)
+	variant, err := h.dbHandler.GetByID(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get stock variant")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to get stock variant")
+		sharedHttp.SendError(w, r, err, "Failed to get stock variant")
 		return
 	}
 
@@ -83,6 +59,7 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, variant.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock variant retrieved", variant)
 }
 
@@ -105,13 +82,14 @@ func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	variant, err := h.dbHandler.Create(&req)
+	variant, err := h.dbHandler.Create(r.Context(), &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create stock variant")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to create stock variant")
+		sharedHttp.SendError(w, r, err, "Failed to create stock variant")
 		return
 	}
 
+	sharedHttp.SetETag(w, variant.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Stock variant created", variant)
 }
 
@@ -120,6 +98,12 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update stock variant")
+		return
+	}
+
 	var req models.StockVariantUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode request body")
@@ -127,10 +111,10 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	variant, err := h.dbHandler.Update(id, &req)
+	variant, err := h.dbHandler.Update(r.Context(), id, version, &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update stock variant")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update stock variant")
+		sharedHttp.SendError(w, r, err, "Failed to update stock variant")
 		return
 	}
 
@@ -139,6 +123,7 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, variant.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock variant updated", variant)
 }
 
@@ -147,16 +132,28 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.dbHandler.Delete(id)
+	err := h.dbHandler.Delete(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete stock variant")
-		if err.Error() == "stock variant not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Stock variant not found")
-			return
-		}
-		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		sharedHttp.SendError(w, r, err, "Failed to delete stock variant")
 		return
 	}
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock variant deleted", nil)
 }
+
+// Restore handles POST /api/v1/stock/variants/:id/restore
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	variant, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore stock variant")
+		sharedHttp.SendError(w, r, err, "Failed to restore stock variant")
+		return
+	}
+
+	sharedHttp.SetETag(w, variant.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Stock variant restored", variant)
+}
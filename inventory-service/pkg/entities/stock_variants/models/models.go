@@ -2,18 +2,24 @@ package models
 
 import (
 	"time"
+
+	"shared/db/queryspec"
+	"shared/money"
 )
 
 // StockVariant represents a stock variant (defines the item type, actual counts are in stock_count)
 type StockVariant struct {
-	ID                 string    `json:"id"`
-	Name               string    `json:"name"`
-	Description        *string   `json:"description,omitempty"`
-	StockSubCategoryID string    `json:"stock_sub_category_id"`
-	AvgCost            float64   `json:"avg_cost"`
-	IsActive           bool      `json:"is_active"`
-	CreatedAt          time.Time `json:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at"`
+	ID                 string      `json:"id"`
+	Name               string      `json:"name"`
+	Description        *string     `json:"description,omitempty"`
+	StockSubCategoryID string      `json:"stock_sub_category_id"`
+	StockCategoryID    string      `json:"stock_category_id,omitempty"`
+	AvgCost            money.Money `json:"avg_cost"`
+	IsActive           bool        `json:"is_active"`
+	CreatedAt          time.Time   `json:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at"`
+	Version            int         `json:"version"`
+	DeletedAt          *time.Time  `json:"deleted_at,omitempty"`
 }
 
 // StockVariantCreateRequest represents a request to create a stock variant
@@ -35,6 +41,6 @@ type StockVariantUpdateRequest struct {
 type StockVariantListResponse struct {
 	Variants []StockVariant `json:"variants"`
 	Total    int            `json:"total"`
-	Page     int            `json:"page"`
 	Limit    int            `json:"limit"`
+	queryspec.PageInfo
 }
@@ -2,61 +2,50 @@ package sql
 
 import (
 	"embed"
-	"fmt"
-	"strings"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
 )
 
 //go:embed scripts/*.sql
-var sqlFiles embed.FS
-
-// Queries holds all SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from the scripts directory
-func LoadQueries() (*Queries, error) {
-	queries := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files, err := sqlFiles.ReadDir("scripts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			content, err := sqlFiles.ReadFile("scripts/" + file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read SQL file %s: %w", file.Name(), err)
-			}
+var sqlScripts embed.FS
 
-			queryName := strings.TrimSuffix(file.Name(), ".sql")
-			queries.queries[queryName] = string(content)
-		}
-	}
-	return queries, nil
-}
-
-// Get retrieves a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, exists := q.queries[name]
-	if !exists {
-		return "", fmt.Errorf("query '%s' not found", name)
-	}
-	return query, nil
-}
-
-// SQL query constants
+// SQL query names
 const (
-	ListAllStockVariantsQuery           = "list_all_stock_variants"
-	ListStockVariantsQuery              = "list_stock_variants"
-	ListStockVariantsBySubCategoryQuery = "list_stock_variants_by_sub_category"
-	ListStockVariantsByCategoryQuery    = "list_stock_variants_by_category"
-	GetStockVariantByIDQuery            = "get_stock_variant_by_id"
-	CreateStockVariantQuery             = "create_stock_variant"
-	UpdateStockVariantQuery             = "update_stock_variant"
-	DeleteStockVariantQuery             = "delete_stock_variant"
-	CheckStockVariantDependenciesQuery  = "check_stock_variant_dependencies"
+	ListStockVariantsQuery             queries.Name = "list_stock_variants"
+	GetStockVariantByIDQuery           queries.Name = "get_stock_variant_by_id"
+	CreateStockVariantQuery            queries.Name = "create_stock_variant"
+	UpdateStockVariantQuery            queries.Name = "update_stock_variant"
+	DeleteStockVariantQuery            queries.Name = "delete_stock_variant"
+	RestoreStockVariantQuery           queries.Name = "restore_stock_variant"
+	PurgeStockVariantsQuery            queries.Name = "purge_stock_variants"
+	CheckStockVariantDependenciesQuery queries.Name = "check_stock_variant_dependencies"
 )
+
+// ListSchema whitelists the columns of list_stock_variants that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("name",
+	queryspec.Field{Name: "name", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "stock_sub_category_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "stock_category_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "avg_cost", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).
+	WithAlias("category_id", "stock_category_id", queryspec.Eq).
+	WithAlias("sub_category_id", "stock_sub_category_id", queryspec.Eq).
+	WithDefaultLimit(100).
+	WithSoftDelete()
+
+// LoadQueries loads and validates the stock variant SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListStockVariantsQuery,
+		GetStockVariantByIDQuery,
+		CreateStockVariantQuery,
+		UpdateStockVariantQuery,
+		DeleteStockVariantQuery,
+		RestoreStockVariantQuery,
+		PurgeStockVariantsQuery,
+		CheckStockVariantDependenciesQuery,
+	)
+}
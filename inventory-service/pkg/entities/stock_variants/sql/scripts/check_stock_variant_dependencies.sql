@@ -1 +1,3 @@
-SELECT 0;
+SELECT COUNT(*) FROM menu_ingredients mi
+JOIN menu_variants mv ON mi.menu_variant_id = mv.id
+WHERE mi.stock_variant_id = @id AND mv.deleted_at IS NULL;
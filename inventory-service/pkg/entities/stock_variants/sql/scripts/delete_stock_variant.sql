@@ -1 +1 @@
-DELETE FROM stock_variants WHERE id = $1;
+UPDATE stock_variants SET deleted_at = CURRENT_TIMESTAMP WHERE id = @id AND deleted_at IS NULL;
@@ -1,3 +1,3 @@
-SELECT id, name, description, stock_sub_category_id, avg_cost, is_active, created_at, updated_at
+SELECT id, name, description, stock_sub_category_id, avg_cost, is_active, created_at, updated_at, version
 FROM stock_variants
-WHERE id = $1;
+WHERE id = @id AND deleted_at IS NULL;
@@ -1,5 +1,4 @@
-SELECT id, name, description, stock_sub_category_id, avg_cost, is_active, created_at, updated_at
-FROM stock_variants
-WHERE is_active = true
-ORDER BY name ASC
-LIMIT $1 OFFSET $2;
+SELECT sv.id, sv.name, sv.description, sv.stock_sub_category_id, ssc.stock_category_id, sv.avg_cost, sv.is_active, sv.created_at, sv.updated_at, sv.version, sv.deleted_at
+FROM stock_variants sv
+JOIN stock_sub_categories ssc ON sv.stock_sub_category_id = ssc.id
+WHERE sv.is_active = true;
@@ -0,0 +1,11 @@
+-- Hard delete stock variants soft deleted before the retention window that no recipe,
+-- stock count or modifier option references. Invoice items keep their line and lose the link.
+WITH purged AS (
+    DELETE FROM stock_variants sv
+    WHERE sv.deleted_at < @deleted_before
+      AND NOT EXISTS (SELECT 1 FROM menu_ingredients mi WHERE mi.stock_variant_id = sv.id)
+      AND NOT EXISTS (SELECT 1 FROM stock_count sc WHERE sc.stock_variant_id = sv.id)
+      AND NOT EXISTS (SELECT 1 FROM modifier_options mo WHERE mo.stock_variant_id = sv.id)
+    RETURNING sv.id
+)
+SELECT COUNT(*) FROM purged;
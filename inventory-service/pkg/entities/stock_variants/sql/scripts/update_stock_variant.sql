@@ -1,7 +1,7 @@
 UPDATE stock_variants
-SET name = COALESCE($2, name),
-    description = COALESCE($3, description),
-    is_active = COALESCE($4, is_active),
+SET name = COALESCE(@name, name),
+    description = COALESCE(@description, description),
+    is_active = COALESCE(@is_active, is_active),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-RETURNING id, name, description, stock_sub_category_id, avg_cost, is_active, created_at, updated_at;
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id, name, description, stock_sub_category_id, avg_cost, is_active, created_at, updated_at, version;
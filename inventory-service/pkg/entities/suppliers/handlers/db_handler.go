@@ -1,24 +1,34 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"inventory-service/pkg/entities/suppliers/models"
 	supplierSQL "inventory-service/pkg/entities/suppliers/sql"
+	"shared/audit"
 	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// auditEntity names the entity in the audit log
+const auditEntity = "supplier"
+
 // DBHandler handles database operations for suppliers
 type DBHandler struct {
 	db      *sharedDb.DbHandler
-	queries *supplierSQL.Queries
+	audit   *audit.Log
+	queries *queries.Registry
 	logger  *logrus.Logger
 }
 
 // NewDBHandler creates a new database handler
-func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, error) {
+func NewDBHandler(db *sharedDb.DbHandler, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
 	queries, err := supplierSQL.LoadQueries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
@@ -26,31 +36,30 @@ func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, er
 
 	return &DBHandler{
 		db:      db,
+		audit:   auditLog,
 		queries: queries,
 		logger:  logger,
 	}, nil
 }
 
-// List returns a paginated list of suppliers
-func (h *DBHandler) List(req *models.SupplierListRequest) (*models.SupplierListResponse, error) {
-	offset := (req.Page - 1) * req.Limit
-
-	countQuery, err := h.queries.Get(supplierSQL.CountSuppliersQuery)
+// List returns a page of suppliers matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.SupplierListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(supplierSQL.ListSuppliersQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get count query: %w", err)
+		return nil, fmt.Errorf("failed to build suppliers count: %w", err)
 	}
 
 	var total int
-	if err := h.db.QueryRow(countQuery, req.Name, req.Email, req.Phone).Scan(&total); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
 		return nil, fmt.Errorf("failed to count suppliers: %w", err)
 	}
 
-	listQuery, err := h.queries.Get(supplierSQL.ListSuppliersQuery)
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(supplierSQL.ListSuppliersQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
+		return nil, fmt.Errorf("failed to build suppliers list: %w", err)
 	}
 
-	rows, err := h.db.Query(listQuery, req.Name, req.Email, req.Phone, req.Limit, offset)
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list suppliers: %w", err)
 	}
@@ -68,6 +77,8 @@ func (h *DBHandler) List(req *models.SupplierListRequest) (*models.SupplierListR
 			&supplier.Address,
 			&supplier.CreatedAt,
 			&supplier.UpdatedAt,
+			&supplier.Version,
+			&supplier.DeletedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan supplier: %w", err)
@@ -79,23 +90,23 @@ func (h *DBHandler) List(req *models.SupplierListRequest) (*models.SupplierListR
 		return nil, fmt.Errorf("error iterating suppliers: %w", err)
 	}
 
+	suppliers, pageInfo, err := queryspec.Paginate(spec, suppliers)
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.SupplierListResponse{
 		Suppliers: suppliers,
 		Total:     total,
-		Page:      req.Page,
-		Limit:     req.Limit,
+		Limit:     spec.Limit,
+		PageInfo:  pageInfo,
 	}, nil
 }
 
 // GetByID retrieves a supplier by ID
-func (h *DBHandler) GetByID(id string) (*models.Supplier, error) {
-	query, err := h.queries.Get(supplierSQL.GetSupplierByIDQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.Supplier, error) {
 	var supplier models.Supplier
-	err = h.db.QueryRow(query, id).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(supplierSQL.GetSupplierByIDQuery), queries.Args{"id": id}).Scan(
 		&supplier.ID,
 		&supplier.Name,
 		&supplier.ContactName,
@@ -104,11 +115,12 @@ func (h *DBHandler) GetByID(id string) (*models.Supplier, error) {
 		&supplier.Address,
 		&supplier.CreatedAt,
 		&supplier.UpdatedAt,
+		&supplier.Version,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("supplier not found")
+			return nil, sharedErrors.NotFound("supplier_not_found", "supplier not found")
 		}
 		return nil, fmt.Errorf("failed to get supplier: %w", err)
 	}
@@ -117,14 +129,22 @@ func (h *DBHandler) GetByID(id string) (*models.Supplier, error) {
 }
 
 // Create creates a new supplier
-func (h *DBHandler) Create(req *models.SupplierCreateRequest) (*models.Supplier, error) {
-	query, err := h.queries.Get(supplierSQL.CreateSupplierQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+func (h *DBHandler) Create(ctx context.Context, req *models.SupplierCreateRequest) (*models.Supplier, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.Supplier, error) {
+		return h.create(ctx, req)
+	})
+}
 
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.SupplierCreateRequest) (*models.Supplier, error) {
 	var supplier models.Supplier
-	err = h.db.QueryRow(query, req.Name, req.ContactName, req.Phone, req.Email, req.Address).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(supplierSQL.CreateSupplierQuery), queries.Args{
+		"name":         req.Name,
+		"contact_name": req.ContactName,
+		"phone":        req.Phone,
+		"email":        req.Email,
+		"address":      req.Address,
+	}).Scan(
 		&supplier.ID,
 		&supplier.Name,
 		&supplier.ContactName,
@@ -133,6 +153,7 @@ func (h *DBHandler) Create(req *models.SupplierCreateRequest) (*models.Supplier,
 		&supplier.Address,
 		&supplier.CreatedAt,
 		&supplier.UpdatedAt,
+		&supplier.Version,
 	)
 
 	if err != nil {
@@ -147,15 +168,25 @@ func (h *DBHandler) Create(req *models.SupplierCreateRequest) (*models.Supplier,
 	return &supplier, nil
 }
 
-// Update updates an existing supplier
-func (h *DBHandler) Update(id string, req *models.SupplierUpdateRequest) (*models.Supplier, error) {
-	query, err := h.queries.Get(supplierSQL.UpdateSupplierQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+// Update updates an existing supplier if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.SupplierUpdateRequest) (*models.Supplier, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.Supplier, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
 
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.SupplierUpdateRequest) (*models.Supplier, error) {
 	var supplier models.Supplier
-	err = h.db.QueryRow(query, id, req.Name, req.ContactName, req.Phone, req.Email, req.Address).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(supplierSQL.UpdateSupplierQuery), queries.Args{
+		"id":           id,
+		"version":      version,
+		"name":         req.Name,
+		"contact_name": req.ContactName,
+		"phone":        req.Phone,
+		"email":        req.Email,
+		"address":      req.Address,
+	}).Scan(
 		&supplier.ID,
 		&supplier.Name,
 		&supplier.ContactName,
@@ -164,11 +195,12 @@ func (h *DBHandler) Update(id string, req *models.SupplierUpdateRequest) (*model
 		&supplier.Address,
 		&supplier.CreatedAt,
 		&supplier.UpdatedAt,
+		&supplier.Version,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("supplier not found")
+			return nil, h.versionMismatch(ctx, id)
 		}
 		return nil, fmt.Errorf("failed to update supplier: %w", err)
 	}
@@ -181,25 +213,35 @@ func (h *DBHandler) Update(id string, req *models.SupplierUpdateRequest) (*model
 	return &supplier, nil
 }
 
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return err
+	}
+	return sharedErrors.PreconditionFailed("supplier_version_mismatch", "supplier was modified by another request")
+}
+
 // Delete deletes a supplier
-func (h *DBHandler) Delete(id string) error {
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
+
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
 	// Check for dependencies
-	deps, err := h.checkDependencies(id)
+	deps, err := h.checkDependencies(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to check dependencies: %w", err)
 	}
 
-	if deps.PurchaseInvoiceCount > 0 || deps.OutcomeInvoiceCount > 0 {
-		return fmt.Errorf("cannot delete supplier: it has %d purchase invoices and %d outcome invoices",
-			deps.PurchaseInvoiceCount, deps.OutcomeInvoiceCount)
+	if deps.OutcomeInvoiceCount > 0 {
+		return sharedErrors.Conflict("supplier_in_use", fmt.Sprintf("cannot delete supplier: it has %d outcome invoices", deps.OutcomeInvoiceCount))
 	}
 
-	query, err := h.queries.Get(supplierSQL.DeleteSupplierQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get query: %w", err)
-	}
-
-	result, err := h.db.Exec(query, id)
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(supplierSQL.DeleteSupplierQuery), queries.Args{"id": id})
 	if err != nil {
 		return fmt.Errorf("failed to delete supplier: %w", err)
 	}
@@ -210,22 +252,50 @@ func (h *DBHandler) Delete(id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("supplier not found")
+		return sharedErrors.NotFound("supplier_not_found", "supplier not found")
 	}
 
 	h.logger.WithField("supplier_id", id).Info("Supplier deleted successfully")
 	return nil
 }
 
-// checkDependencies checks if a supplier has dependencies
-func (h *DBHandler) checkDependencies(id string) (*SupplierDependencies, error) {
-	query, err := h.queries.Get(supplierSQL.CheckSupplierDependenciesQuery)
+// Restore brings back a soft deleted supplier
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.Supplier, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.Supplier, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.Supplier, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(supplierSQL.RestoreSupplierQuery), queries.Args{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore supplier: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("supplier_not_found", "deleted supplier not found")
+	}
+
+	h.logger.WithField("id", id).Info("Supplier restored")
+	return h.GetByID(ctx, id)
+}
+
+// Purge hard deletes the suppliers soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(supplierSQL.PurgeSuppliersQuery), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
+		return 0, fmt.Errorf("failed to purge suppliers: %w", err)
 	}
+	return purged, nil
+}
 
+// checkDependencies checks if a supplier has dependencies
+func (h *DBHandler) checkDependencies(ctx context.Context, id string) (*SupplierDependencies, error) {
 	var deps SupplierDependencies
-	err = h.db.QueryRow(query, id).Scan(&deps.PurchaseInvoiceCount, &deps.OutcomeInvoiceCount)
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(supplierSQL.CheckSupplierDependenciesQuery), queries.Args{"id": id}).Scan(&deps.OutcomeInvoiceCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check dependencies: %w", err)
 	}
@@ -235,6 +305,5 @@ func (h *DBHandler) checkDependencies(id string) (*SupplierDependencies, error)
 
 // SupplierDependencies represents the dependencies of a supplier
 type SupplierDependencies struct {
-	PurchaseInvoiceCount int `json:"purchase_invoice_count"`
-	OutcomeInvoiceCount  int `json:"outcome_invoice_count"`
-}
\ No newline at end of file
+	OutcomeInvoiceCount int `json:"outcome_invoice_count"`
+}
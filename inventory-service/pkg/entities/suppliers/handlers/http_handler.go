@@ -3,9 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"inventory-service/pkg/entities/suppliers/models"
+	supplierSQL "inventory-service/pkg/entities/suppliers/sql"
 	sharedHttp "shared/http"
 
 	"github.com/gorilla/mux"
@@ -25,37 +25,19 @@ func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
 
 // List handles GET /api/v1/inventory/suppliers
 func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 20
-	}
-
-	req := &models.SupplierListRequest{
-		Page:  page,
-		Limit: limit,
-	}
-
-	if name := r.URL.Query().Get("name"); name != "" {
-		req.Name = &name
-	}
-	if email := r.URL.Query().Get("email"); email != "" {
-		req.Email = &email
-	}
-	if phone := r.URL.Query().Get("phone"); phone != "" {
-		req.Phone = &phone
+	spec, err := supplierSQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
 	}
 
-	response, err := h.dbHandler.List(req)
+	response, err := h.dbHandler.List(r.Context(), spec)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list suppliers")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list suppliers")
+		sharedHttp.SendError(w, r, err, "Failed to list suppliers")
 		return
 	}
+	response.SetLinks(r.URL)
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Suppliers retrieved successfully", response)
 }
@@ -70,17 +52,14 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	supplier, err := h.dbHandler.GetByID(id)
+	supplier, err := h.dbHandler.GetByID(r.Context(), id)
 	if err != nil {
-		if err.Error() == "supplier not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Supplier not found")
-			return
-		}
 		h.logger.WithError(err).Error("Failed to get supplier")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to get supplier")
+		sharedHttp.SendError(w, r, err, "Failed to get supplier")
 		return
 	}
 
+	sharedHttp.SetETag(w, supplier.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Supplier retrieved successfully", supplier)
 }
 
@@ -97,13 +76,14 @@ func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	supplier, err := h.dbHandler.Create(&req)
+	supplier, err := h.dbHandler.Create(r.Context(), &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create supplier")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to create supplier")
+		sharedHttp.SendError(w, r, err, "Failed to create supplier")
 		return
 	}
 
+	sharedHttp.SetETag(w, supplier.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Supplier created successfully", supplier)
 }
 
@@ -117,23 +97,26 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update supplier")
+		return
+	}
+
 	var req models.SupplierUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	supplier, err := h.dbHandler.Update(id, &req)
+	supplier, err := h.dbHandler.Update(r.Context(), id, version, &req)
 	if err != nil {
-		if err.Error() == "supplier not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Supplier not found")
-			return
-		}
 		h.logger.WithError(err).Error("Failed to update supplier")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update supplier")
+		sharedHttp.SendError(w, r, err, "Failed to update supplier")
 		return
 	}
 
+	sharedHttp.SetETag(w, supplier.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Supplier updated successfully", supplier)
 }
 
@@ -147,16 +130,28 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.dbHandler.Delete(id)
+	err := h.dbHandler.Delete(r.Context(), id)
 	if err != nil {
-		if err.Error() == "supplier not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Supplier not found")
-			return
-		}
 		h.logger.WithError(err).Error("Failed to delete supplier")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, err.Error())
+		sharedHttp.SendError(w, r, err, "Failed to delete supplier")
 		return
 	}
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Supplier deleted successfully", nil)
 }
+
+// Restore handles POST /api/v1/inventory/suppliers/{id}/restore
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	supplier, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore supplier")
+		sharedHttp.SendError(w, r, err, "Failed to restore supplier")
+		return
+	}
+
+	sharedHttp.SetETag(w, supplier.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Supplier restored", supplier)
+}
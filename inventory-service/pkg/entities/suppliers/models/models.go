@@ -2,18 +2,22 @@ package models
 
 import (
 	"time"
+
+	"shared/db/queryspec"
 )
 
 // Supplier represents a supplier in the system
 type Supplier struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	ContactName *string   `json:"contact_name,omitempty"`
-	Phone       *string   `json:"phone,omitempty"`
-	Email       *string   `json:"email,omitempty"`
-	Address     *string   `json:"address,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	ContactName *string    `json:"contact_name,omitempty"`
+	Phone       *string    `json:"phone,omitempty"`
+	Email       *string    `json:"email,omitempty"`
+	Address     *string    `json:"address,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Version     int        `json:"version"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 }
 
 // SupplierCreateRequest represents a request to create a supplier
@@ -34,19 +38,10 @@ type SupplierUpdateRequest struct {
 	Address     *string `json:"address,omitempty"`
 }
 
-// SupplierListRequest represents filter parameters for listing suppliers
-type SupplierListRequest struct {
-	Name  *string `json:"name,omitempty"`
-	Email *string `json:"email,omitempty"`
-	Phone *string `json:"phone,omitempty"`
-	Page  int     `json:"page"`
-	Limit int     `json:"limit"`
-}
-
 // SupplierListResponse represents a paginated list of suppliers
 type SupplierListResponse struct {
 	Suppliers []Supplier `json:"suppliers"`
 	Total     int        `json:"total"`
-	Page      int        `json:"page"`
 	Limit     int        `json:"limit"`
+	queryspec.PageInfo
 }
@@ -2,59 +2,50 @@ package sql
 
 import (
 	"embed"
-	"fmt"
-	"strings"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
 )
 
 //go:embed scripts/*.sql
-var sqlFiles embed.FS
-
-// Queries holds all SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from the scripts directory
-func LoadQueries() (*Queries, error) {
-	queries := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files, err := sqlFiles.ReadDir("scripts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			content, err := sqlFiles.ReadFile("scripts/" + file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read SQL file %s: %w", file.Name(), err)
-			}
+var sqlScripts embed.FS
 
-			queryName := strings.TrimSuffix(file.Name(), ".sql")
-			queries.queries[queryName] = string(content)
-		}
-	}
-	return queries, nil
-}
+// SQL query names
+const (
+	ListSuppliersQuery             queries.Name = "list_suppliers"
+	GetSupplierByIDQuery           queries.Name = "get_supplier_by_id"
+	CreateSupplierQuery            queries.Name = "create_supplier"
+	UpdateSupplierQuery            queries.Name = "update_supplier"
+	DeleteSupplierQuery            queries.Name = "delete_supplier"
+	RestoreSupplierQuery           queries.Name = "restore_supplier"
+	PurgeSuppliersQuery            queries.Name = "purge_suppliers"
+	CheckSupplierDependenciesQuery queries.Name = "check_supplier_dependencies"
+)
 
-// Get retrieves a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, exists := q.queries[name]
-	if !exists {
-		return "", fmt.Errorf("query '%s' not found", name)
-	}
-	return query, nil
+// ListSchema whitelists the columns of list_suppliers that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("name",
+	queryspec.Field{Name: "name", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "contact_name", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "phone", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "email", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).
+	WithAlias("name", "name", queryspec.Like).
+	WithAlias("email", "email", queryspec.Like).
+	WithAlias("phone", "phone", queryspec.Like).
+	WithSoftDelete()
+
+// LoadQueries loads and validates the supplier SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListSuppliersQuery,
+		GetSupplierByIDQuery,
+		CreateSupplierQuery,
+		UpdateSupplierQuery,
+		DeleteSupplierQuery,
+		RestoreSupplierQuery,
+		PurgeSuppliersQuery,
+		CheckSupplierDependenciesQuery,
+	)
 }
-
-// SQL query constants
-const (
-	ListSuppliersQuery             = "list_suppliers"
-	CountSuppliersQuery            = "count_suppliers"
-	GetSupplierByIDQuery           = "get_supplier_by_id"
-	CreateSupplierQuery            = "create_supplier"
-	UpdateSupplierQuery            = "update_supplier"
-	DeleteSupplierQuery            = "delete_supplier"
-	CheckSupplierDependenciesQuery = "check_supplier_dependencies"
-)
\ No newline at end of file
@@ -1,3 +1 @@
-SELECT
-    (SELECT COUNT(*) FROM purchase_invoices WHERE supplier_id = $1) as purchase_invoice_count,
-    (SELECT COUNT(*) FROM outcome_invoices WHERE supplier_id = $1) as outcome_invoice_count
\ No newline at end of file
+SELECT COUNT(*) AS outcome_invoice_count FROM outcome_invoices WHERE supplier_id = @id AND deleted_at IS NULL
@@ -1 +1 @@
-DELETE FROM suppliers WHERE id = $1
\ No newline at end of file
+UPDATE suppliers SET deleted_at = CURRENT_TIMESTAMP WHERE id = @id AND deleted_at IS NULL
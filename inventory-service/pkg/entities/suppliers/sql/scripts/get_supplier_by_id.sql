@@ -1,3 +1,3 @@
-SELECT id, name, contact_name, phone, email, address, created_at, updated_at
+SELECT id, name, contact_name, phone, email, address, created_at, updated_at, version
 FROM suppliers
-WHERE id = $1
\ No newline at end of file
+WHERE id = @id AND deleted_at IS NULL
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- Hard delete suppliers soft deleted before the retention window that no invoice references
+WITH purged AS (
+    DELETE FROM suppliers s
+    WHERE s.deleted_at < @deleted_before
+      AND NOT EXISTS (SELECT 1 FROM outcome_invoices oi WHERE oi.supplier_id = s.id)
+    RETURNING s.id
+)
+SELECT COUNT(*) FROM purged
@@ -1,9 +1,9 @@
 UPDATE suppliers
-SET name = COALESCE($2, name),
-    contact_name = COALESCE($3, contact_name),
-    phone = COALESCE($4, phone),
-    email = COALESCE($5, email),
-    address = COALESCE($6, address),
+SET name = COALESCE(@name, name),
+    contact_name = COALESCE(@contact_name, contact_name),
+    phone = COALESCE(@phone, phone),
+    email = COALESCE(@email, email),
+    address = COALESCE(@address, address),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-RETURNING id, name, contact_name, phone, email, address, created_at, updated_at
\ No newline at end of file
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id, name, contact_name, phone, email, address, created_at, updated_at, version
\ No newline at end of file
@@ -2,15 +2,19 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
+	"shared/audit"
 	sharedConfig "shared/config"
 	sharedDb "shared/db"
+	"shared/db/softdelete"
+	"shared/events"
 	sharedHttp "shared/http"
+	"shared/middlewares"
 
+	catalogHandlers "inventory-service/pkg/entities/catalog/handlers"
 	stockCategoryHandlers "inventory-service/pkg/entities/stock_categories/handlers"
 	stockCountHandlers "inventory-service/pkg/entities/stock_count/handlers"
 	stockSubCategoryHandlers "inventory-service/pkg/entities/stock_sub_categories/handlers"
@@ -30,6 +34,8 @@ type MainHTTPHandler struct {
 	stockSubCategoryHandler *stockSubCategoryHandlers.HTTPHandler
 	stockVariantHandler     *stockVariantHandlers.HTTPHandler
 	supplierHandler         *supplierHandlers.HTTPHandler
+	catalogHandler          *catalogHandlers.HTTPHandler
+	purger                  *softdelete.Purger
 	logger                  *logrus.Logger
 }
 
@@ -42,6 +48,7 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 		Password:        cfg.GetString("DB_PASSWORD"),
 		DBName:          cfg.GetString("DB_NAME"),
 		SSLMode:         cfg.GetString("DB_SSL_MODE"),
+		ApplicationName: "inventory-service",
 		MaxOpenConns:    25,
 		MaxIdleConns:    5,
 		ConnMaxLifetime: 5 * time.Minute,
@@ -50,6 +57,7 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 		QueryTimeout:    30 * time.Second,
 		MaxRetries:      3,
 		RetryInterval:   2 * time.Second,
+		CacheStatements: true,
 	}
 
 	db, err := sharedDb.NewDatabaseHandler(dbConfig, logger)
@@ -57,24 +65,55 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 		return nil, fmt.Errorf("failed to create database handler: %w", err)
 	}
 
+	// Create audit log, every entity records its changes in it
+	auditLog, err := audit.NewLog(db, "inventory-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create audit log: %w", err)
+	}
+
 	// Create stock category handlers
-	stockCategoryDBHandler, err := stockCategoryHandlers.NewDBHandler(db, logger)
+	stockCategoryDBHandler, err := stockCategoryHandlers.NewDBHandler(db, auditLog, logger)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create stock category handler: %w", err)
 	}
 	stockCategoryHTTPHandler := stockCategoryHandlers.NewHTTPHandler(stockCategoryDBHandler, logger)
 
+	// Create event outbox, relay and subscriber
+	outbox, err := events.NewOutbox(db, "inventory-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event outbox: %w", err)
+	}
+	broker, err := events.NewPostgresBroker(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event broker: %w", err)
+	}
+	relay, err := events.NewRelay(db, broker, "inventory-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event relay: %w", err)
+	}
+	subscriber, err := events.NewSubscriber(db, "inventory-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event subscriber: %w", err)
+	}
+
 	// Create stock count handlers (pass config for cost calculation settings)
-	stockCountDBHandler, err := stockCountHandlers.NewDBHandler(db, cfg, logger)
+	stockCountDBHandler, err := stockCountHandlers.NewDBHandler(db, outbox, cfg, auditLog, logger)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create stock count handler: %w", err)
 	}
 	stockCountHTTPHandler := stockCountHandlers.NewHTTPHandler(stockCountDBHandler, logger)
+	stockCountEventHandler := stockCountHandlers.NewEventHandler(stockCountDBHandler, logger)
+	subscriber.Handle(events.OutcomeInvoiceCreated, stockCountEventHandler.OutcomeInvoiceCreated)
 
 	// Create stock sub-category handlers
-	stockSubCategoryDBHandler, err := stockSubCategoryHandlers.NewDBHandler(db, logger)
+	stockSubCategoryDBHandler, err := stockSubCategoryHandlers.NewDBHandler(db, auditLog, logger)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create stock sub-category handler: %w", err)
@@ -82,7 +121,7 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 	stockSubCategoryHTTPHandler := stockSubCategoryHandlers.NewHTTPHandler(stockSubCategoryDBHandler, logger)
 
 	// Create stock variant handlers
-	stockVariantDBHandler, err := stockVariantHandlers.NewDBHandler(db, logger)
+	stockVariantDBHandler, err := stockVariantHandlers.NewDBHandler(db, auditLog, logger)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create stock variant handler: %w", err)
@@ -90,26 +129,50 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 	stockVariantHTTPHandler := stockVariantHandlers.NewHTTPHandler(stockVariantDBHandler, logger)
 
 	// Create supplier handlers
-	supplierDBHandler, err := supplierHandlers.NewDBHandler(db, logger)
+	supplierDBHandler, err := supplierHandlers.NewDBHandler(db, auditLog, logger)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create supplier handler: %w", err)
 	}
 	supplierHTTPHandler := supplierHandlers.NewHTTPHandler(supplierDBHandler, logger)
 
-	// Create cancellable context for health monitor
+	// Create catalog import and export handlers on top of the entity handlers
+	catalogDBHandler, err := catalogHandlers.NewDBHandler(db, stockCategoryDBHandler, stockSubCategoryDBHandler, stockVariantDBHandler, supplierDBHandler, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create catalog handler: %w", err)
+	}
+	catalogHTTPHandler := catalogHandlers.NewHTTPHandler(catalogDBHandler, logger)
+
+	// Create the purger of soft deleted rows, children before their parents
+	purger := softdelete.NewPurger(db, cfg.GetInt("SOFT_DELETE_RETENTION_DAYS"), "inventory-service", logger).
+		Add("stock_count", stockCountDBHandler).
+		Add("stock_variants", stockVariantDBHandler).
+		Add("stock_sub_categories", stockSubCategoryDBHandler).
+		Add("stock_categories", stockCategoryDBHandler).
+		Add("suppliers", supplierDBHandler)
+
+	// Create cancellable context for health monitor, event processing and purger
 	ctx, cancel := context.WithCancel(context.Background())
 
 	//pvillalobos this should be configurable
-	// Create HTTP health monitor for data-service
+	// Create health monitor: the database is critical since the service talks to
+	// Postgres directly, data-service only serves configuration at startup
 	httpHealthMonitor, err := sharedHttp.NewHealthMonitor(logger, 1*time.Second)
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to create HTTP health monitor: %w", err)
 	}
-	httpHealthMonitor.AddService("data-service", sharedConfig.DATA_SERVICE_URL+"/api/v1/data/p/health")
+	httpHealthMonitor.AddCheck("database", sharedHttp.Critical, func(ctx context.Context) error {
+		return db.Ping()
+	})
+	httpHealthMonitor.AddService("data-service", sharedConfig.DATA_SERVICE_URL+"/api/v1/data/p/readyz", sharedHttp.Degraded)
 	httpHealthMonitor.Start(ctx)
 
+	go relay.Start(ctx)
+	go subscriber.Start(ctx)
+	go purger.Start(ctx)
+
 	return &MainHTTPHandler{
 		db:                      db,
 		httpHealthMonitor:       httpHealthMonitor,
@@ -119,12 +182,14 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 		stockSubCategoryHandler: stockSubCategoryHTTPHandler,
 		stockVariantHandler:     stockVariantHTTPHandler,
 		supplierHandler:         supplierHTTPHandler,
+		catalogHandler:          catalogHTTPHandler,
+		purger:                  purger,
 		logger:                  logger,
 	}, nil
 }
 
 func (h *MainHTTPHandler) CloseDB() error {
-	// Stop health monitor
+	// Stop health monitor, event processing and purger
 	if h.cancelHealthMonitor != nil {
 		h.cancelHealthMonitor()
 	}
@@ -138,8 +203,13 @@ func (h *MainHTTPHandler) CloseDB() error {
 }
 
 func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
-	// Health check
-	router.HandleFunc("/api/v1/inventory/p/health", h.HealthCheck).Methods("GET")
+	// Attribute changes to the user the gateway authenticated
+	router.Use(audit.Middleware)
+
+	// Health checks (/p/health is kept for existing clients)
+	router.HandleFunc("/api/v1/inventory/p/livez", sharedHttp.LivenessHandler("inventory-service")).Methods("GET")
+	router.HandleFunc("/api/v1/inventory/p/readyz", h.httpHealthMonitor.ReadinessHandler("inventory-service")).Methods("GET")
+	router.HandleFunc("/api/v1/inventory/p/health", h.httpHealthMonitor.ReadinessHandler("inventory-service")).Methods("GET")
 
 	// Stock Categories
 	router.HandleFunc("/api/v1/inventory/categories", h.stockCategoryHandler.List).Methods("GET")
@@ -147,6 +217,7 @@ func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/inventory/categories", h.stockCategoryHandler.Create).Methods("POST")
 	router.HandleFunc("/api/v1/inventory/categories/{id}", h.stockCategoryHandler.Update).Methods("PUT")
 	router.HandleFunc("/api/v1/inventory/categories/{id}", h.stockCategoryHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/v1/inventory/categories/{id}/restore", h.stockCategoryHandler.Restore).Methods("POST")
 
 	// Stock Sub-Categories
 	router.HandleFunc("/api/v1/inventory/sub-categories", h.stockSubCategoryHandler.List).Methods("GET")
@@ -154,6 +225,7 @@ func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/inventory/sub-categories", h.stockSubCategoryHandler.Create).Methods("POST")
 	router.HandleFunc("/api/v1/inventory/sub-categories/{id}", h.stockSubCategoryHandler.Update).Methods("PUT")
 	router.HandleFunc("/api/v1/inventory/sub-categories/{id}", h.stockSubCategoryHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/v1/inventory/sub-categories/{id}/restore", h.stockSubCategoryHandler.Restore).Methods("POST")
 
 	// Stock Variants
 	router.HandleFunc("/api/v1/inventory/variants", h.stockVariantHandler.List).Methods("GET")
@@ -161,6 +233,7 @@ func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/inventory/variants", h.stockVariantHandler.Create).Methods("POST")
 	router.HandleFunc("/api/v1/inventory/variants/{id}", h.stockVariantHandler.Update).Methods("PUT")
 	router.HandleFunc("/api/v1/inventory/variants/{id}", h.stockVariantHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/v1/inventory/variants/{id}/restore", h.stockVariantHandler.Restore).Methods("POST")
 
 	// Stock Count
 	router.HandleFunc("/api/v1/inventory/stock-count", h.stockCountHandler.List).Methods("GET")
@@ -169,6 +242,7 @@ func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/inventory/stock-count/{id}", h.stockCountHandler.Update).Methods("PUT")
 	router.HandleFunc("/api/v1/inventory/stock-count/{id}/out", h.stockCountHandler.MarkOut).Methods("PATCH")
 	router.HandleFunc("/api/v1/inventory/stock-count/{id}", h.stockCountHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/v1/inventory/stock-count/{id}/restore", h.stockCountHandler.Restore).Methods("POST")
 
 	// Suppliers
 	router.HandleFunc("/api/v1/inventory/suppliers", h.supplierHandler.List).Methods("GET")
@@ -176,33 +250,12 @@ func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/inventory/suppliers", h.supplierHandler.Create).Methods("POST")
 	router.HandleFunc("/api/v1/inventory/suppliers/{id}", h.supplierHandler.Update).Methods("PUT")
 	router.HandleFunc("/api/v1/inventory/suppliers/{id}", h.supplierHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/v1/inventory/suppliers/{id}/restore", h.supplierHandler.Restore).Methods("POST")
 
-}
-
-func (h *MainHTTPHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
-		"service":   "inventory-service",
-		"timestamp": time.Now(),
-	}
-
-	// Check cached health state from background monitor
-	healthStatus := h.httpHealthMonitor.GetHealthStatus()
-	if !healthStatus.IsHealthy {
-		response["status"] = "unhealthy"
-		response["message"] = "Dependent services are not healthy"
-		response["services"] = healthStatus.Services
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(response)
-		return
-	}
-
-	response["status"] = "healthy"
-	response["message"] = "Inventory service is healthy"
-	response["services"] = healthStatus.Services
+	// Catalog import and export (sheet: categories, sub-categories, variants, suppliers)
+	router.HandleFunc("/api/v1/inventory/export/{sheet}", h.catalogHandler.Export).Methods("GET")
+	router.Handle("/api/v1/inventory/import/{sheet}", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.catalogHandler.Import))).Methods("POST")
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	// Admin
+	router.Handle("/api/v1/inventory/admin/purge", middlewares.RequireRole("admin")(http.HandlerFunc(h.purger.Handler))).Methods("POST")
 }
@@ -4,24 +4,33 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"invoice-service/pkg/entities/income_invoices/models"
 	incomesql "invoice-service/pkg/entities/income_invoices/sql"
 	invoiceItemModels "invoice-service/pkg/entities/invoice_items/models"
 	invoiceItemSql "invoice-service/pkg/entities/invoice_items/sql"
+	"shared/audit"
 	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
 
 	"github.com/sirupsen/logrus"
 )
 
+// auditEntity names the entity in the audit log
+const auditEntity = "income_invoice"
+
 type DBHandler struct {
 	db                 *sharedDb.DbHandler
+	audit              *audit.Log
 	logger             *logrus.Logger
-	queries            *incomesql.Queries
-	invoiceItemQueries *invoiceItemSql.Queries
+	queries            *queries.Registry
+	invoiceItemQueries *queries.Registry
 }
 
-func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, error) {
+func NewDBHandler(db *sharedDb.DbHandler, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
 	queries, err := incomesql.LoadQueries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load income invoice SQL queries: %w", err)
@@ -34,6 +43,7 @@ func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, er
 
 	return &DBHandler{
 		db:                 db,
+		audit:              auditLog,
 		logger:             logger,
 		queries:            queries,
 		invoiceItemQueries: invoiceItemQueries,
@@ -41,91 +51,85 @@ func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, er
 }
 
 // Create creates a new income invoice with its items in a transaction
-func (h *DBHandler) Create(req *models.IncomeInvoiceCreateRequest) (*models.IncomeInvoice, error) {
-	// Start transaction
-	tx, err := h.db.BeginTx(context.Background())
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	query, err := h.queries.Get(incomesql.CreateIncomeInvoice)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get create query: %w", err)
-	}
+func (h *DBHandler) Create(ctx context.Context, req *models.IncomeInvoiceCreateRequest) (*models.IncomeInvoice, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.IncomeInvoice, error) {
+		return h.create(ctx, req)
+	})
+}
 
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.IncomeInvoiceCreateRequest) (*models.IncomeInvoice, error) {
 	var invoice models.IncomeInvoice
-	err = tx.QueryRow(query,
-		req.OrderID,
-		req.PaymentID,
-		req.CustomerID,
-		req.InvoiceNumber,
-		req.InvoiceType,
-		req.Subtotal,
-		req.TaxAmount,
-		req.ServiceCharge,
-		req.TotalAmount,
-		req.PaymentMethod,
-		req.XMLData,
-		req.DigitalSignature,
-		req.Status,
-		req.GeneratedAt,
-	).Scan(
-		&invoice.ID, &invoice.CreatedAt, &invoice.UpdatedAt,
-	)
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		invoice = models.IncomeInvoice{}
+
+		err := tx.QueryRowNamedContext(ctx, h.queries.Get(incomesql.CreateIncomeInvoice), queries.Args{
+			"order_id":          req.OrderID,
+			"payment_id":        req.PaymentID,
+			"customer_id":       req.CustomerID,
+			"invoice_number":    req.InvoiceNumber,
+			"invoice_type":      req.InvoiceType,
+			"subtotal":          req.Subtotal,
+			"tax_amount":        req.TaxAmount,
+			"service_charge":    req.ServiceCharge,
+			"total_amount":      req.TotalAmount,
+			"payment_method":    req.PaymentMethod,
+			"xml_data":          req.XMLData,
+			"digital_signature": req.DigitalSignature,
+			"status":            req.Status,
+			"generated_at":      req.GeneratedAt,
+		}).Scan(
+			&invoice.ID, &invoice.CreatedAt, &invoice.UpdatedAt, &invoice.Version,
+		)
 
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to create income invoice")
-		return nil, fmt.Errorf("failed to create income invoice: %w", err)
-	}
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to create income invoice")
+			return fmt.Errorf("failed to create income invoice: %w", err)
+		}
 
-	// Fill in the rest of the fields from the request
-	invoice.OrderID = req.OrderID
-	invoice.PaymentID = req.PaymentID
-	invoice.CustomerID = req.CustomerID
-	invoice.InvoiceNumber = req.InvoiceNumber
-	invoice.InvoiceType = req.InvoiceType
-	invoice.Subtotal = req.Subtotal
-	invoice.TaxAmount = req.TaxAmount
-	invoice.ServiceCharge = req.ServiceCharge
-	invoice.TotalAmount = req.TotalAmount
-	invoice.PaymentMethod = req.PaymentMethod
-	invoice.XMLData = req.XMLData
-	invoice.DigitalSignature = req.DigitalSignature
-	invoice.Status = req.Status
-	invoice.GeneratedAt = req.GeneratedAt
-
-	// Create invoice items if provided
-	//pvillalobos TODO: check if the invoice items are empty
-	if len(req.InvoiceItems) > 0 {
-		for _, itemReq := range req.InvoiceItems {
-			itemReq.InvoiceID = invoice.ID
-
-			item, err := h.createInvoiceItem(tx, &itemReq)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create invoice item: %w", err)
+		// Fill in the rest of the fields from the request
+		invoice.OrderID = req.OrderID
+		invoice.PaymentID = req.PaymentID
+		invoice.CustomerID = req.CustomerID
+		invoice.InvoiceNumber = req.InvoiceNumber
+		invoice.InvoiceType = req.InvoiceType
+		invoice.Subtotal = req.Subtotal
+		invoice.TaxAmount = req.TaxAmount
+		invoice.ServiceCharge = req.ServiceCharge
+		invoice.TotalAmount = req.TotalAmount
+		invoice.PaymentMethod = req.PaymentMethod
+		invoice.XMLData = req.XMLData
+		invoice.DigitalSignature = req.DigitalSignature
+		invoice.Status = req.Status
+		invoice.GeneratedAt = req.GeneratedAt
+
+		// Create invoice items if provided
+		//pvillalobos TODO: check if the invoice items are empty
+		if len(req.InvoiceItems) > 0 {
+			for _, itemReq := range req.InvoiceItems {
+				itemReq.InvoiceID = invoice.ID
+
+				item, err := h.createInvoiceItem(ctx, tx, &itemReq)
+				if err != nil {
+					return fmt.Errorf("failed to create invoice item: %w", err)
+				}
+				invoice.InvoiceItems = append(invoice.InvoiceItems, *item)
 			}
-			invoice.InvoiceItems = append(invoice.InvoiceItems, *item)
 		}
-	}
 
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &invoice, nil
 }
 
 // GetByID retrieves an income invoice by ID with its items
-func (h *DBHandler) GetByID(id string) (*models.IncomeInvoice, error) {
-	query, err := h.queries.Get(incomesql.GetIncomeInvoice)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.IncomeInvoice, error) {
 	var invoice models.IncomeInvoice
-	err = h.db.QueryRow(query, id).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(incomesql.GetIncomeInvoice), queries.Args{"id": id}).Scan(
 		&invoice.ID,
 		&invoice.OrderID,
 		&invoice.PaymentID,
@@ -143,18 +147,19 @@ func (h *DBHandler) GetByID(id string) (*models.IncomeInvoice, error) {
 		&invoice.GeneratedAt,
 		&invoice.CreatedAt,
 		&invoice.UpdatedAt,
+		&invoice.Version,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("income invoice not found")
+			return nil, sharedErrors.NotFound("income_invoice_not_found", "income invoice not found")
 		}
 		h.logger.WithError(err).Error("Failed to get income invoice")
 		return nil, fmt.Errorf("failed to get income invoice: %w", err)
 	}
 
 	// Get invoice items
-	invoiceItems, err := h.getInvoiceItems(invoice.ID)
+	invoiceItems, err := h.getInvoiceItems(ctx, invoice.ID)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get invoice items")
 		return nil, fmt.Errorf("failed to get invoice items: %w", err)
@@ -164,46 +169,58 @@ func (h *DBHandler) GetByID(id string) (*models.IncomeInvoice, error) {
 	return &invoice, nil
 }
 
-// Update updates an income invoice (transaction support can be added if items need updating)
-func (h *DBHandler) Update(id string, req *models.IncomeInvoiceUpdateRequest) (*models.IncomeInvoice, error) {
-	query, err := h.queries.Get(incomesql.UpdateIncomeInvoice)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get update query: %w", err)
-	}
+// Update updates an income invoice if it is still at the given version (transaction support can be added if items need updating)
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.IncomeInvoiceUpdateRequest) (*models.IncomeInvoice, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.IncomeInvoice, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
 
-	_, err = h.db.Exec(query,
-		id,
-		req.PaymentID,
-		req.CustomerID,
-		req.InvoiceType,
-		req.Subtotal,
-		req.TaxAmount,
-		req.ServiceCharge,
-		req.TotalAmount,
-		req.PaymentMethod,
-		req.XMLData,
-		req.DigitalSignature,
-		req.Status,
-		req.GeneratedAt,
-	)
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.IncomeInvoiceUpdateRequest) (*models.IncomeInvoice, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(incomesql.UpdateIncomeInvoice), queries.Args{
+		"id":                id,
+		"version":           version,
+		"payment_id":        req.PaymentID,
+		"customer_id":       req.CustomerID,
+		"invoice_type":      req.InvoiceType,
+		"subtotal":          req.Subtotal,
+		"tax_amount":        req.TaxAmount,
+		"service_charge":    req.ServiceCharge,
+		"total_amount":      req.TotalAmount,
+		"payment_method":    req.PaymentMethod,
+		"xml_data":          req.XMLData,
+		"digital_signature": req.DigitalSignature,
+		"status":            req.Status,
+		"generated_at":      req.GeneratedAt,
+	})
 
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update income invoice")
 		return nil, fmt.Errorf("failed to update income invoice: %w", err)
 	}
 
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		if _, err := h.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, sharedErrors.PreconditionFailed("income_invoice_version_mismatch", "income invoice was modified by another request")
+	}
+
 	// Return updated invoice
-	return h.GetByID(id)
+	return h.GetByID(ctx, id)
 }
 
 // Delete deletes an income invoice (transaction support can be added if cascading deletes are needed)
-func (h *DBHandler) Delete(id string) error {
-	query, err := h.queries.Get(incomesql.DeleteIncomeInvoice)
-	if err != nil {
-		return fmt.Errorf("failed to get delete query: %w", err)
-	}
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
 
-	result, err := h.db.Exec(query, id)
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(incomesql.DeleteIncomeInvoice), queries.Args{"id": id})
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete income invoice")
 		return fmt.Errorf("failed to delete income invoice: %w", err)
@@ -215,36 +232,67 @@ func (h *DBHandler) Delete(id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("income invoice not found")
+		return sharedErrors.NotFound("income_invoice_not_found", "income invoice not found")
 	}
 
 	return nil
 }
 
-// List retrieves income invoices with pagination and filtering
-func (h *DBHandler) List(req *models.IncomeInvoiceListRequest) (*models.IncomeInvoiceListResponse, error) {
-	// Get the list and count queries
-	listQuery, err := h.queries.Get(incomesql.ListIncomeInvoices)
+// Restore brings back a soft deleted income invoice
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.IncomeInvoice, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.IncomeInvoice, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.IncomeInvoice, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(incomesql.RestoreIncomeInvoice), queries.Args{"id": id})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
+		return nil, fmt.Errorf("failed to restore income invoice: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("income_invoice_not_found", "deleted income invoice not found")
 	}
 
-	countQuery, err := h.queries.Get(incomesql.CountIncomeInvoices)
+	h.logger.WithField("id", id).Info("Income invoice restored")
+	return h.GetByID(ctx, id)
+}
+
+// Purge hard deletes the income invoices soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(incomesql.PurgeIncomeInvoices), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge income invoices: %w", err)
+	}
+	return purged, nil
+}
+
+// List retrieves a page of income invoices matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.IncomeInvoiceListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(incomesql.ListIncomeInvoices), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get count query: %w", err)
+		return nil, fmt.Errorf("failed to build income invoices count: %w", err)
 	}
 
 	// Get total count
 	var total int
-	err = h.db.QueryRow(countQuery, req.CustomerID, req.InvoiceType, req.Status, req.OrderID).Scan(&total)
+	err = h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to count income invoices")
 		return nil, fmt.Errorf("failed to count income invoices: %w", err)
 	}
 
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(incomesql.ListIncomeInvoices), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build income invoices list: %w", err)
+	}
+
 	// Get paginated results
-	offset := (req.Page - 1) * req.Limit
-	rows, err := h.db.Query(listQuery, req.CustomerID, req.InvoiceType, req.Status, req.OrderID, req.Limit, offset)
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list income invoices")
 		return nil, fmt.Errorf("failed to list income invoices: %w", err)
@@ -272,6 +320,8 @@ func (h *DBHandler) List(req *models.IncomeInvoiceListRequest) (*models.IncomeIn
 			&invoice.GeneratedAt,
 			&invoice.CreatedAt,
 			&invoice.UpdatedAt,
+			&invoice.Version,
+			&invoice.DeletedAt,
 		)
 		if err != nil {
 			h.logger.WithError(err).Error("Failed to scan income invoice")
@@ -279,7 +329,7 @@ func (h *DBHandler) List(req *models.IncomeInvoiceListRequest) (*models.IncomeIn
 		}
 
 		// Get invoice items for this invoice
-		invoiceItems, err := h.getInvoiceItems(invoice.ID)
+		invoiceItems, err := h.getInvoiceItems(ctx, invoice.ID)
 		if err != nil {
 			h.logger.WithError(err).Error("Failed to get invoice items")
 			return nil, fmt.Errorf("failed to get invoice items: %w", err)
@@ -293,58 +343,47 @@ func (h *DBHandler) List(req *models.IncomeInvoiceListRequest) (*models.IncomeIn
 		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
+	invoices, pageInfo, err := queryspec.Paginate(spec, invoices)
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.IncomeInvoiceListResponse{
 		Invoices: invoices,
 		Total:    total,
-		Page:     req.Page,
-		Limit:    req.Limit,
+		Limit:    spec.Limit,
+		PageInfo: pageInfo,
 	}, nil
 }
 
 // createInvoiceItem creates a single invoice item within a transaction
-func (h *DBHandler) createInvoiceItem(tx *sql.Tx, req *invoiceItemModels.InvoiceItemCreateRequest) (*invoiceItemModels.InvoiceItem, error) {
-	query, err := h.invoiceItemQueries.Get(invoiceItemSql.CreateInvoiceItem)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get create item query: %w", err)
-	}
-
+func (h *DBHandler) createInvoiceItem(ctx context.Context, tx *sharedDb.Tx, req *invoiceItemModels.InvoiceItemCreateRequest) (*invoiceItemModels.InvoiceItem, error) {
 	var item invoiceItemModels.InvoiceItem
-	err = tx.QueryRow(query,
-		req.InvoiceID,
-		req.Detail,
-		req.Count,
-		req.UnitType,
-		req.Price,
-		req.ItemsPerUnit,
-		req.ExpirationDate,
-	).Scan(
-		&item.ID, &item.Total, &item.CreatedAt, &item.UpdatedAt,
+	err := tx.QueryRowNamedContext(ctx, h.invoiceItemQueries.Get(invoiceItemSql.CreateInvoiceItem), queries.Args{
+		"invoice_id":       req.InvoiceID,
+		"stock_variant_id": req.StockVariantID,
+		"detail":           req.Detail,
+		"count":            req.Count,
+		"unit_type":        req.UnitType,
+		"price":            req.Price,
+		"items_per_unit":   req.ItemsPerUnit,
+		"expiration_date":  req.ExpirationDate,
+	}).Scan(
+		&item.ID, &item.InvoiceID, &item.StockVariantID, &item.Detail,
+		&item.Count, &item.UnitType, &item.Price, &item.ItemsPerUnit,
+		&item.Total, &item.ExpirationDate, &item.CreatedAt, &item.UpdatedAt,
 	)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create invoice item: %w", err)
 	}
 
-	// Fill in the rest of the fields
-	item.InvoiceID = req.InvoiceID
-	item.Detail = req.Detail
-	item.Count = req.Count
-	item.UnitType = req.UnitType
-	item.Price = req.Price
-	item.ItemsPerUnit = req.ItemsPerUnit
-	item.ExpirationDate = req.ExpirationDate
-
 	return &item, nil
 }
 
 // getInvoiceItems retrieves all invoice items for a given invoice ID
-func (h *DBHandler) getInvoiceItems(invoiceID string) ([]models.InvoiceItem, error) {
-	query, err := h.invoiceItemQueries.Get(invoiceItemSql.ListInvoiceItems)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get list items query: %w", err)
-	}
-
-	rows, err := h.db.Query(query, invoiceID)
+func (h *DBHandler) getInvoiceItems(ctx context.Context, invoiceID string) ([]models.InvoiceItem, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.invoiceItemQueries.Get(invoiceItemSql.ListInvoiceItems), queries.Args{"invoice_id": invoiceID})
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to query invoice items")
 		return nil, fmt.Errorf("failed to query invoice items: %w", err)
@@ -3,9 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"invoice-service/pkg/entities/income_invoices/models"
+	incomesql "invoice-service/pkg/entities/income_invoices/sql"
 	sharedHttp "shared/http"
 
 	"github.com/gorilla/mux"
@@ -31,13 +31,14 @@ func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	invoice, err := h.dbHandler.Create(&req)
+	invoice, err := h.dbHandler.Create(r.Context(), &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create income invoice")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to create income invoice")
+		sharedHttp.SendError(w, r, err, "Failed to create income invoice")
 		return
 	}
 
+	sharedHttp.SetETag(w, invoice.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Income invoice created successfully", invoice)
 }
 
@@ -45,17 +46,14 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	invoice, err := h.dbHandler.GetByID(id)
+	invoice, err := h.dbHandler.GetByID(r.Context(), id)
 	if err != nil {
-		if err.Error() == "income invoice not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Income invoice not found")
-			return
-		}
 		h.logger.WithError(err).Error("Failed to get income invoice")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to get income invoice")
+		sharedHttp.SendError(w, r, err, "Failed to get income invoice")
 		return
 	}
 
+	sharedHttp.SetETag(w, invoice.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Income invoice retrieved successfully", invoice)
 }
 
@@ -63,23 +61,26 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update income invoice")
+		return
+	}
+
 	var req models.IncomeInvoiceUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	invoice, err := h.dbHandler.Update(id, &req)
+	invoice, err := h.dbHandler.Update(r.Context(), id, version, &req)
 	if err != nil {
-		if err.Error() == "income invoice not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Income invoice not found")
-			return
-		}
 		h.logger.WithError(err).Error("Failed to update income invoice")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update income invoice")
+		sharedHttp.SendError(w, r, err, "Failed to update income invoice")
 		return
 	}
 
+	sharedHttp.SetETag(w, invoice.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Income invoice updated successfully", invoice)
 }
 
@@ -87,64 +88,45 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.dbHandler.Delete(id)
+	err := h.dbHandler.Delete(r.Context(), id)
 	if err != nil {
-		if err.Error() == "income invoice not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Income invoice not found")
-			return
-		}
 		h.logger.WithError(err).Error("Failed to delete income invoice")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to delete income invoice")
+		sharedHttp.SendError(w, r, err, "Failed to delete income invoice")
 		return
 	}
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Income invoice deleted successfully", nil)
 }
 
-func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	page := 1
-	limit := 10
-	customerID := r.URL.Query().Get("customer_id")
-	invoiceType := r.URL.Query().Get("invoice_type")
-	status := r.URL.Query().Get("status")
-	orderID := r.URL.Query().Get("order_id")
-
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
-	}
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
 
-	req := &models.IncomeInvoiceListRequest{
-		Page:  page,
-		Limit: limit,
+	invoice, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore income invoice")
+		sharedHttp.SendError(w, r, err, "Failed to restore income invoice")
+		return
 	}
 
-	if customerID != "" {
-		req.CustomerID = &customerID
-	}
-	if invoiceType != "" {
-		req.InvoiceType = &invoiceType
-	}
-	if status != "" {
-		req.Status = &status
-	}
-	if orderID != "" {
-		req.OrderID = &orderID
+	sharedHttp.SetETag(w, invoice.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Income invoice restored", invoice)
+}
+
+func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
+	spec, err := incomesql.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
 	}
 
-	response, err := h.dbHandler.List(req)
+	response, err := h.dbHandler.List(r.Context(), spec)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list income invoices")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list income invoices")
+		sharedHttp.SendError(w, r, err, "Failed to list income invoices")
 		return
 	}
+	response.SetLinks(r.URL)
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Income invoices retrieved successfully", response)
 }
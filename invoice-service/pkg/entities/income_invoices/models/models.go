@@ -4,6 +4,8 @@ import (
 	"time"
 
 	invoiceItemModels "invoice-service/pkg/entities/invoice_items/models"
+	"shared/db/queryspec"
+	"shared/money"
 )
 
 // InvoiceItem alias for easier reference
@@ -18,10 +20,10 @@ type IncomeInvoice struct {
 	CustomerID       *string       `json:"customer_id,omitempty"` // Customer tax ID (Cédula)
 	InvoiceNumber    string        `json:"invoice_number"`
 	InvoiceType      string        `json:"invoice_type"`
-	Subtotal         float64       `json:"subtotal"`
-	TaxAmount        float64       `json:"tax_amount"`
-	ServiceCharge    float64       `json:"service_charge"`
-	TotalAmount      float64       `json:"total_amount"`
+	Subtotal         money.Money   `json:"subtotal"`
+	TaxAmount        money.Money   `json:"tax_amount"`
+	ServiceCharge    money.Money   `json:"service_charge"`
+	TotalAmount      money.Money   `json:"total_amount"`
 	PaymentMethod    string        `json:"payment_method"`
 	XMLData          *string       `json:"xml_data,omitempty"`
 	DigitalSignature *string       `json:"digital_signature,omitempty"`
@@ -30,6 +32,8 @@ type IncomeInvoice struct {
 	GeneratedAt      *time.Time    `json:"generated_at,omitempty"`
 	CreatedAt        time.Time     `json:"created_at"`
 	UpdatedAt        time.Time     `json:"updated_at"`
+	Version          int           `json:"version"`
+	DeletedAt        *time.Time    `json:"deleted_at,omitempty"`
 }
 
 // IncomeInvoiceCreateRequest represents a request to create an income invoice
@@ -39,10 +43,10 @@ type IncomeInvoiceCreateRequest struct {
 	CustomerID       *string                           `json:"customer_id,omitempty"` // Customer tax ID (Cédula)
 	InvoiceNumber    string                            `json:"invoice_number"`
 	InvoiceType      string                            `json:"invoice_type"`
-	Subtotal         float64                           `json:"subtotal"`
-	TaxAmount        float64                           `json:"tax_amount"`
-	ServiceCharge    float64                           `json:"service_charge"`
-	TotalAmount      float64                           `json:"total_amount"`
+	Subtotal         money.Money                       `json:"subtotal"`
+	TaxAmount        money.Money                       `json:"tax_amount"`
+	ServiceCharge    money.Money                       `json:"service_charge"`
+	TotalAmount      money.Money                       `json:"total_amount"`
 	PaymentMethod    string                            `json:"payment_method"`
 	XMLData          *string                           `json:"xml_data,omitempty"`
 	DigitalSignature *string                           `json:"digital_signature,omitempty"`
@@ -53,34 +57,24 @@ type IncomeInvoiceCreateRequest struct {
 
 // IncomeInvoiceUpdateRequest represents a request to update an income invoice
 type IncomeInvoiceUpdateRequest struct {
-	PaymentID        *string    `json:"payment_id,omitempty"`
-	CustomerID       *string    `json:"customer_id,omitempty"` // Customer tax ID (Cédula)
-	InvoiceType      *string    `json:"invoice_type,omitempty"`
-	Subtotal         *float64   `json:"subtotal,omitempty"`
-	TaxAmount        *float64   `json:"tax_amount,omitempty"`
-	ServiceCharge    *float64   `json:"service_charge,omitempty"`
-	TotalAmount      *float64   `json:"total_amount,omitempty"`
-	PaymentMethod    *string    `json:"payment_method,omitempty"`
-	XMLData          *string    `json:"xml_data,omitempty"`
-	DigitalSignature *string    `json:"digital_signature,omitempty"`
-	Status           *string    `json:"status,omitempty"`
-	GeneratedAt      *time.Time `json:"generated_at,omitempty"`
-}
-
-// IncomeInvoiceListRequest represents filter parameters for listing income invoices
-type IncomeInvoiceListRequest struct {
-	CustomerID *string `json:"customer_id,omitempty"` // Customer tax ID filter
-	InvoiceType *string `json:"invoice_type,omitempty"`
-	Status      *string `json:"status,omitempty"`
-	OrderID     *string `json:"order_id,omitempty"`
-	Page        int     `json:"page"`
-	Limit       int     `json:"limit"`
+	PaymentID        *string      `json:"payment_id,omitempty"`
+	CustomerID       *string      `json:"customer_id,omitempty"` // Customer tax ID (Cédula)
+	InvoiceType      *string      `json:"invoice_type,omitempty"`
+	Subtotal         *money.Money `json:"subtotal,omitempty"`
+	TaxAmount        *money.Money `json:"tax_amount,omitempty"`
+	ServiceCharge    *money.Money `json:"service_charge,omitempty"`
+	TotalAmount      *money.Money `json:"total_amount,omitempty"`
+	PaymentMethod    *string      `json:"payment_method,omitempty"`
+	XMLData          *string      `json:"xml_data,omitempty"`
+	DigitalSignature *string      `json:"digital_signature,omitempty"`
+	Status           *string      `json:"status,omitempty"`
+	GeneratedAt      *time.Time   `json:"generated_at,omitempty"`
 }
 
 // IncomeInvoiceListResponse represents a paginated list of income invoices
 type IncomeInvoiceListResponse struct {
 	Invoices []IncomeInvoice `json:"invoices"`
 	Total    int             `json:"total"`
-	Page     int             `json:"page"`
 	Limit    int             `json:"limit"`
+	queryspec.PageInfo
 }
@@ -2,58 +2,48 @@ package sql
 
 import (
 	"embed"
-	"fmt"
-	"strings"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
 )
 
 //go:embed scripts/*.sql
-var sqlFiles embed.FS
-
-// Queries holds all SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from the scripts directory
-func LoadQueries() (*Queries, error) {
-	queries := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files, err := sqlFiles.ReadDir("scripts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			content, err := sqlFiles.ReadFile("scripts/" + file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read SQL file %s: %w", file.Name(), err)
-			}
+var sqlScripts embed.FS
 
-			queryName := strings.TrimSuffix(file.Name(), ".sql")
-			queries.queries[queryName] = string(content)
-		}
-	}
-	return queries, nil
-}
-
-// Get retrieves a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, exists := q.queries[name]
-	if !exists {
-		return "", fmt.Errorf("query '%s' not found", name)
-	}
-	return query, nil
-}
-
-// SQL query constants
+// SQL query names
 const (
-	CreateIncomeInvoice = "create_income_invoice"
-	GetIncomeInvoice    = "get_income_invoice"
-	UpdateIncomeInvoice = "update_income_invoice"
-	DeleteIncomeInvoice = "delete_income_invoice"
-	ListIncomeInvoices  = "list_income_invoices"
-	CountIncomeInvoices = "count_income_invoices"
+	CreateIncomeInvoice  queries.Name = "create_income_invoice"
+	GetIncomeInvoice     queries.Name = "get_income_invoice"
+	UpdateIncomeInvoice  queries.Name = "update_income_invoice"
+	DeleteIncomeInvoice  queries.Name = "delete_income_invoice"
+	RestoreIncomeInvoice queries.Name = "restore_income_invoice"
+	PurgeIncomeInvoices  queries.Name = "purge_income_invoices"
+	ListIncomeInvoices   queries.Name = "list_income_invoices"
 )
+
+// ListSchema whitelists the columns of list_income_invoices that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("-created_at",
+	queryspec.Field{Name: "order_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "customer_id", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "invoice_number", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "invoice_type", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "status", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "payment_method", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "total_amount", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "generated_at", Type: queryspec.Time, Filterable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).WithAlias("customer_id", "customer_id", queryspec.Like).WithDefaultLimit(10).WithSoftDelete()
+
+// LoadQueries loads and validates the income invoice SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		CreateIncomeInvoice,
+		GetIncomeInvoice,
+		UpdateIncomeInvoice,
+		DeleteIncomeInvoice,
+		RestoreIncomeInvoice,
+		PurgeIncomeInvoices,
+		ListIncomeInvoices,
+	)
+}
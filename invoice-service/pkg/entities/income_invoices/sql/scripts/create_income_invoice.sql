@@ -17,5 +17,5 @@ INSERT INTO income_invoices (
     created_at,
     updated_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NOW(), NOW()
-) RETURNING id, created_at, updated_at;
+    @order_id, @payment_id, @customer_id, @invoice_number, @invoice_type, @subtotal, @tax_amount, @service_charge, @total_amount, @payment_method, @xml_data, @digital_signature, @status, @generated_at, NOW(), NOW()
+) RETURNING id, created_at, updated_at, version;
@@ -1,2 +1,2 @@
--- Delete an income invoice
-DELETE FROM income_invoices WHERE id = $1;
+-- Soft delete an income invoice, its items stay until it is purged
+UPDATE income_invoices SET deleted_at = CURRENT_TIMESTAMP WHERE id = @id AND deleted_at IS NULL;
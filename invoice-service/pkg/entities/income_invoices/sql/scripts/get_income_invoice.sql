@@ -16,6 +16,7 @@ SELECT
     status,
     generated_at,
     created_at,
-    updated_at
+    updated_at,
+    version
 FROM income_invoices
-WHERE id = $1;
+WHERE id = @id AND deleted_at IS NULL;
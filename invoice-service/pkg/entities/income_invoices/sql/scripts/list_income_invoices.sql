@@ -1,4 +1,4 @@
--- List income invoices with optional filters
+-- List income invoices, filtered, sorted and paginated through queryspec
 SELECT
     id,
     order_id,
@@ -16,11 +16,7 @@ SELECT
     status,
     generated_at,
     created_at,
-    updated_at
-FROM income_invoices
-WHERE ($1::text IS NULL OR customer_id ILIKE '%' || $1 || '%')
-  AND ($2::text IS NULL OR invoice_type = $2)
-  AND ($3::text IS NULL OR status = $3)
-  AND ($4::text IS NULL OR order_id = $4)
-ORDER BY created_at DESC
-LIMIT $5 OFFSET $6;
+    updated_at,
+    version,
+    deleted_at
+FROM income_invoices;
@@ -0,0 +1,9 @@
+-- Hard delete income invoices soft deleted before the retention window together with their items
+WITH purged AS (
+    DELETE FROM income_invoices
+    WHERE deleted_at < @deleted_before
+    RETURNING id
+), purged_items AS (
+    DELETE FROM invoice_items WHERE invoice_id IN (SELECT id FROM purged)
+)
+SELECT COUNT(*) FROM purged;
@@ -0,0 +1,2 @@
+-- Restore a soft deleted income invoice
+UPDATE income_invoices SET deleted_at = NULL WHERE id = @id AND deleted_at IS NOT NULL;
@@ -1,17 +1,17 @@
 -- Update an income invoice
 UPDATE income_invoices SET
-    payment_id = COALESCE($2, payment_id),
-    customer_id = COALESCE($3, customer_id),
-    invoice_type = COALESCE($4, invoice_type),
-    subtotal = COALESCE($5, subtotal),
-    tax_amount = COALESCE($6, tax_amount),
-    service_charge = COALESCE($7, service_charge),
-    total_amount = COALESCE($8, total_amount),
-    payment_method = COALESCE($9, payment_method),
-    xml_data = COALESCE($10, xml_data),
-    digital_signature = COALESCE($11, digital_signature),
-    status = COALESCE($12, status),
-    generated_at = COALESCE($13, generated_at),
+    payment_id = COALESCE(@payment_id, payment_id),
+    customer_id = COALESCE(@customer_id, customer_id),
+    invoice_type = COALESCE(@invoice_type, invoice_type),
+    subtotal = COALESCE(@subtotal, subtotal),
+    tax_amount = COALESCE(@tax_amount, tax_amount),
+    service_charge = COALESCE(@service_charge, service_charge),
+    total_amount = COALESCE(@total_amount, total_amount),
+    payment_method = COALESCE(@payment_method, payment_method),
+    xml_data = COALESCE(@xml_data, xml_data),
+    digital_signature = COALESCE(@digital_signature, digital_signature),
+    status = COALESCE(@status, status),
+    generated_at = COALESCE(@generated_at, generated_at),
     updated_at = NOW()
-WHERE id = $1
-RETURNING id, updated_at;
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id, updated_at, version;
@@ -2,22 +2,24 @@ package models
 
 import (
 	"time"
+
+	"shared/money"
 )
 
 // InvoiceItem represents a line item in an invoice (can be for outcome or income invoices)
 type InvoiceItem struct {
-	ID             string     `json:"id"`
-	InvoiceID      string     `json:"invoice_id"`
-	StockVariantID *string    `json:"stock_variant_id,omitempty"`
-	Detail         string     `json:"detail,omitempty"`
-	Count          float64    `json:"count"`
-	UnitType       string     `json:"unit_type"`
-	Price          float64    `json:"price"`
-	ItemsPerUnit   int        `json:"items_per_unit"`
-	Total          float64    `json:"total"`
-	ExpirationDate *time.Time `json:"expiration_date,omitempty"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
+	ID             string      `json:"id"`
+	InvoiceID      string      `json:"invoice_id"`
+	StockVariantID *string     `json:"stock_variant_id,omitempty"`
+	Detail         string      `json:"detail,omitempty"`
+	Count          float64     `json:"count"`
+	UnitType       string      `json:"unit_type"`
+	Price          money.Money `json:"price"`
+	ItemsPerUnit   int         `json:"items_per_unit"`
+	Total          money.Money `json:"total"`
+	ExpirationDate *time.Time  `json:"expiration_date,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
 	// Joined fields (optional, populated on list/get)
 	StockVariantName *string `json:"stock_variant_name,omitempty"`
 	// Deprecated fields - kept for backward compatibility
@@ -27,25 +29,25 @@ type InvoiceItem struct {
 
 // InvoiceItemCreateRequest represents a request to create an invoice item
 type InvoiceItemCreateRequest struct {
-	InvoiceID      string     `json:"invoice_id"`
-	StockVariantID *string    `json:"stock_variant_id,omitempty"`
-	Detail         string     `json:"detail,omitempty"`
-	Count          float64    `json:"count"`
-	UnitType       string     `json:"unit_type"`
-	Price          float64    `json:"price"`
-	ItemsPerUnit   int        `json:"items_per_unit"`
-	ExpirationDate *time.Time `json:"expiration_date,omitempty"`
+	InvoiceID      string      `json:"invoice_id"`
+	StockVariantID *string     `json:"stock_variant_id,omitempty"`
+	Detail         string      `json:"detail,omitempty"`
+	Count          float64     `json:"count"`
+	UnitType       string      `json:"unit_type"`
+	Price          money.Money `json:"price"`
+	ItemsPerUnit   int         `json:"items_per_unit"`
+	ExpirationDate *time.Time  `json:"expiration_date,omitempty"`
 }
 
 // InvoiceItemUpdateRequest represents a request to update an invoice item
 type InvoiceItemUpdateRequest struct {
-	StockVariantID *string    `json:"stock_variant_id,omitempty"`
-	Detail         *string    `json:"detail,omitempty"`
-	Count          *float64   `json:"count,omitempty"`
-	UnitType       *string    `json:"unit_type,omitempty"`
-	Price          *float64   `json:"price,omitempty"`
-	ItemsPerUnit   *int       `json:"items_per_unit,omitempty"`
-	ExpirationDate *time.Time `json:"expiration_date,omitempty"`
+	StockVariantID *string      `json:"stock_variant_id,omitempty"`
+	Detail         *string      `json:"detail,omitempty"`
+	Count          *float64     `json:"count,omitempty"`
+	UnitType       *string      `json:"unit_type,omitempty"`
+	Price          *money.Money `json:"price,omitempty"`
+	ItemsPerUnit   *int         `json:"items_per_unit,omitempty"`
+	ExpirationDate *time.Time   `json:"expiration_date,omitempty"`
 }
 
 // InvoiceItemListRequest represents filter parameters for listing invoice items
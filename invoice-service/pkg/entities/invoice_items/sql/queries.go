@@ -2,58 +2,31 @@ package sql
 
 import (
 	"embed"
-	"fmt"
-	"strings"
+
+	"shared/db/queries"
 )
 
 //go:embed scripts/*.sql
-var sqlFiles embed.FS
-
-// Queries holds all SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from the scripts directory
-func LoadQueries() (*Queries, error) {
-	queries := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files, err := sqlFiles.ReadDir("scripts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			content, err := sqlFiles.ReadFile("scripts/" + file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read SQL file %s: %w", file.Name(), err)
-			}
+var sqlScripts embed.FS
 
-			queryName := strings.TrimSuffix(file.Name(), ".sql")
-			queries.queries[queryName] = string(content)
-		}
-	}
-	return queries, nil
-}
-
-// Get retrieves a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, exists := q.queries[name]
-	if !exists {
-		return "", fmt.Errorf("query '%s' not found", name)
-	}
-	return query, nil
-}
-
-// SQL query constants
+// SQL query names
 const (
-	CreateInvoiceItem = "create_invoice_item"
-	GetInvoiceItem    = "get_invoice_item"
-	UpdateInvoiceItem = "update_invoice_item"
-	DeleteInvoiceItem = "delete_invoice_item"
-	ListInvoiceItems  = "list_invoice_items"
-	CountInvoiceItems = "count_invoice_items"
+	CreateInvoiceItem queries.Name = "create_invoice_item"
+	GetInvoiceItem    queries.Name = "get_invoice_item"
+	UpdateInvoiceItem queries.Name = "update_invoice_item"
+	DeleteInvoiceItem queries.Name = "delete_invoice_item"
+	ListInvoiceItems  queries.Name = "list_invoice_items"
+	CountInvoiceItems queries.Name = "count_invoice_items"
 )
+
+// LoadQueries loads and validates the invoice item SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		CreateInvoiceItem,
+		GetInvoiceItem,
+		UpdateInvoiceItem,
+		DeleteInvoiceItem,
+		ListInvoiceItems,
+		CountInvoiceItems,
+	)
+}
@@ -1,4 +1,4 @@
 -- Count invoice items for a specific invoice
 SELECT COUNT(*)
 FROM invoice_items
-WHERE invoice_id = $1;
+WHERE invoice_id = @invoice_id;
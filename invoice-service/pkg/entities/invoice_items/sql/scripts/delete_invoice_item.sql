@@ -1,2 +1,2 @@
 -- Delete an invoice item
-DELETE FROM invoice_items WHERE id = $1;
+DELETE FROM invoice_items WHERE id = @id;
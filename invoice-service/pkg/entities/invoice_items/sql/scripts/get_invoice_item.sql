@@ -15,4 +15,4 @@ SELECT
     sv.name AS stock_variant_name
 FROM invoice_items ii
 LEFT JOIN stock_variants sv ON ii.stock_variant_id = sv.id
-WHERE ii.id = $1;
+WHERE ii.id = @id;
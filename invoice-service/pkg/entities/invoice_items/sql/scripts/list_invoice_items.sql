@@ -15,5 +15,5 @@ SELECT
     sv.name AS stock_variant_name
 FROM invoice_items ii
 LEFT JOIN stock_variants sv ON ii.stock_variant_id = sv.id
-WHERE ii.invoice_id = $1
+WHERE ii.invoice_id = @invoice_id
 ORDER BY ii.created_at DESC;
@@ -4,27 +4,35 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"strings"
+	"time"
 
 	invoiceItemModels "invoice-service/pkg/entities/invoice_items/models"
 	invoiceItemSql "invoice-service/pkg/entities/invoice_items/sql"
 	"invoice-service/pkg/entities/outcome_invoices/models"
 	outcomesql "invoice-service/pkg/entities/outcome_invoices/sql"
-	sharedConfig "shared/config"
+	"shared/audit"
 	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"shared/events"
 
 	"github.com/sirupsen/logrus"
 )
 
+// auditEntity names the entity in the audit log
+const auditEntity = "outcome_invoice"
+
 type DBHandler struct {
 	db                 *sharedDb.DbHandler
+	audit              *audit.Log
 	logger             *logrus.Logger
-	queries            *outcomesql.Queries
-	invoiceItemQueries *invoiceItemSql.Queries
-	portionGrams       float64
+	queries            *queries.Registry
+	invoiceItemQueries *queries.Registry
+	outbox             *events.Outbox
 }
 
-func NewDBHandler(db *sharedDb.DbHandler, config *sharedConfig.Config, logger *logrus.Logger) (*DBHandler, error) {
+func NewDBHandler(db *sharedDb.DbHandler, outbox *events.Outbox, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
 	queries, err := outcomesql.LoadQueries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load outcome invoice SQL queries: %w", err)
@@ -35,114 +43,114 @@ func NewDBHandler(db *sharedDb.DbHandler, config *sharedConfig.Config, logger *l
 		return nil, fmt.Errorf("failed to load invoice item SQL queries: %w", err)
 	}
 
-	// Get default portion grams from config (default 120g)
-	portionGrams := 120.0
-	if config != nil {
-		portionGrams = config.GetFloat("DEFAULT_PORTION_GRAMS")
-		if portionGrams <= 0 {
-			portionGrams = 120.0
-		}
-	}
-
 	return &DBHandler{
 		db:                 db,
+		audit:              auditLog,
 		logger:             logger,
 		queries:            queries,
 		invoiceItemQueries: invoiceItemQueries,
-		portionGrams:       portionGrams,
+		outbox:             outbox,
 	}, nil
 }
 
 // Create creates a new outcome invoice with its items in a transaction
-func (h *DBHandler) Create(req *models.OutcomeInvoiceCreateRequest) (*models.OutcomeInvoice, error) {
-	// Start transaction
-	tx, err := h.db.BeginTx(context.Background())
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	query, err := h.queries.Get(outcomesql.CreateOutcomeInvoice)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get create query: %w", err)
-	}
+func (h *DBHandler) Create(ctx context.Context, req *models.OutcomeInvoiceCreateRequest) (*models.OutcomeInvoice, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.OutcomeInvoice, error) {
+		return h.create(ctx, req)
+	})
+}
 
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.OutcomeInvoiceCreateRequest) (*models.OutcomeInvoice, error) {
 	var invoice models.OutcomeInvoice
-	err = tx.QueryRow(query,
-		req.InvoiceNumber,
-		req.SupplierID,
-		req.TransactionDate,
-		req.DueDate,
-		req.Subtotal,
-		req.TaxAmount,
-		req.DiscountAmount,
-		req.TotalAmount,
-		req.ImageURL,
-		req.Notes,
-	).Scan(
-		&invoice.ID, &invoice.CreatedAt, &invoice.UpdatedAt,
-	)
-
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to create outcome invoice")
-		return nil, fmt.Errorf("failed to create outcome invoice: %w", err)
-	}
-
-	// Fill in the rest of the fields from the request
-	invoice.InvoiceNumber = req.InvoiceNumber
-	invoice.SupplierID = req.SupplierID
-	invoice.TransactionDate = req.TransactionDate
-	invoice.DueDate = req.DueDate
-	invoice.Subtotal = req.Subtotal
-	invoice.TaxAmount = req.TaxAmount
-	invoice.DiscountAmount = req.DiscountAmount
-	invoice.TotalAmount = req.TotalAmount
-	invoice.ImageURL = req.ImageURL
-	invoice.Notes = req.Notes
-
-	// Create invoice items if provided
-	if len(req.InvoiceItems) > 0 {
-		for i, itemReq := range req.InvoiceItems {
-			// Validate required field: stock_variant_id
-			if itemReq.StockVariantID == nil || *itemReq.StockVariantID == "" {
-				h.logger.WithError(fmt.Errorf("stock_variant_id is required for invoice item %d", i+1)).Error("Failed to create outcome invoice")
-				return nil, fmt.Errorf("stock_variant_id is required for invoice item %d", i+1)
-			}
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		invoice = models.OutcomeInvoice{}
+
+		err := tx.QueryRowNamedContext(ctx, h.queries.Get(outcomesql.CreateOutcomeInvoice), queries.Args{
+			"invoice_number":   req.InvoiceNumber,
+			"supplier_id":      req.SupplierID,
+			"transaction_date": req.TransactionDate,
+			"due_date":         req.DueDate,
+			"subtotal":         req.Subtotal,
+			"tax_amount":       req.TaxAmount,
+			"discount_amount":  req.DiscountAmount,
+			"total_amount":     req.TotalAmount,
+			"image_url":        req.ImageURL,
+			"notes":            req.Notes,
+		}).Scan(
+			&invoice.ID, &invoice.CreatedAt, &invoice.UpdatedAt, &invoice.Version,
+		)
 
-			itemReq.InvoiceID = invoice.ID
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to create outcome invoice")
+			return fmt.Errorf("failed to create outcome invoice: %w", err)
+		}
 
-			item, err := h.createInvoiceItem(tx, &itemReq)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create invoice item: %w", err)
-			}
+		// Fill in the rest of the fields from the request
+		invoice.InvoiceNumber = req.InvoiceNumber
+		invoice.SupplierID = req.SupplierID
+		invoice.TransactionDate = req.TransactionDate
+		invoice.DueDate = req.DueDate
+		invoice.Subtotal = req.Subtotal
+		invoice.TaxAmount = req.TaxAmount
+		invoice.DiscountAmount = req.DiscountAmount
+		invoice.TotalAmount = req.TotalAmount
+		invoice.ImageURL = req.ImageURL
+		invoice.Notes = req.Notes
+
+		// Purchased stock is registered by inventory-service when it consumes this event
+		payload := events.OutcomeInvoiceCreatedPayload{
+			InvoiceID:       invoice.ID,
+			TransactionDate: req.TransactionDate,
+			Items:           []events.OutcomeInvoiceItemPayload{},
+		}
 
-			// Create stock_count record for this purchase with cost calculation
-			err = h.createStockCount(tx, *itemReq.StockVariantID, invoice.ID, itemReq.Count, itemReq.UnitType, itemReq.Price, req.TransactionDate)
-			if err != nil {
-				return nil, fmt.Errorf("failed to create stock count: %w", err)
+		// Create invoice items if provided
+		if len(req.InvoiceItems) > 0 {
+			for i, itemReq := range req.InvoiceItems {
+				// Validate required field: stock_variant_id
+				if itemReq.StockVariantID == nil || *itemReq.StockVariantID == "" {
+					field := fmt.Sprintf("invoice_items[%d].stock_variant_id", i)
+					return sharedErrors.Validation("invoice_item_stock_variant_required", fmt.Sprintf("stock_variant_id is required for invoice item %d", i+1),
+						sharedErrors.FieldError{Field: field, Code: "required", Message: "stock_variant_id is required"})
+				}
+
+				itemReq.InvoiceID = invoice.ID
+
+				item, err := h.createInvoiceItem(ctx, tx, &itemReq)
+				if err != nil {
+					return fmt.Errorf("failed to create invoice item: %w", err)
+				}
+
+				payload.Items = append(payload.Items, events.OutcomeInvoiceItemPayload{
+					InvoiceItemID:  item.ID,
+					StockVariantID: *itemReq.StockVariantID,
+					Count:          itemReq.Count,
+					Unit:           itemReq.UnitType,
+					Price:          itemReq.Price,
+				})
+
+				invoice.InvoiceItems = append(invoice.InvoiceItems, *item)
 			}
+		}
 
-			invoice.InvoiceItems = append(invoice.InvoiceItems, *item)
+		if _, err := h.outbox.Add(ctx, events.OutcomeInvoiceCreated, "outcome_invoice", invoice.ID, payload); err != nil {
+			return fmt.Errorf("failed to record outcome invoice event: %w", err)
 		}
-	}
 
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &invoice, nil
 }
 
 // GetByID retrieves an outcome invoice by ID with its items
-func (h *DBHandler) GetByID(id string) (*models.OutcomeInvoice, error) {
-	query, err := h.queries.Get(outcomesql.GetOutcomeInvoice)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.OutcomeInvoice, error) {
 	var invoice models.OutcomeInvoice
-	err = h.db.QueryRow(query, id).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(outcomesql.GetOutcomeInvoice), queries.Args{"id": id}).Scan(
 		&invoice.ID,
 		&invoice.InvoiceNumber,
 		&invoice.SupplierID,
@@ -156,18 +164,19 @@ func (h *DBHandler) GetByID(id string) (*models.OutcomeInvoice, error) {
 		&invoice.Notes,
 		&invoice.CreatedAt,
 		&invoice.UpdatedAt,
+		&invoice.Version,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("outcome invoice not found")
+			return nil, sharedErrors.NotFound("outcome_invoice_not_found", "outcome invoice not found")
 		}
 		h.logger.WithError(err).Error("Failed to get outcome invoice")
 		return nil, fmt.Errorf("failed to get outcome invoice: %w", err)
 	}
 
 	// Get invoice items
-	invoiceItems, err := h.getInvoiceItems(invoice.ID)
+	invoiceItems, err := h.getInvoiceItems(ctx, invoice.ID)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get invoice items")
 		return nil, fmt.Errorf("failed to get invoice items: %w", err)
@@ -177,43 +186,55 @@ func (h *DBHandler) GetByID(id string) (*models.OutcomeInvoice, error) {
 	return &invoice, nil
 }
 
-// Update updates an outcome invoice (transaction support can be added if items need updating)
-func (h *DBHandler) Update(id string, req *models.OutcomeInvoiceUpdateRequest) (*models.OutcomeInvoice, error) {
-	query, err := h.queries.Get(outcomesql.UpdateOutcomeInvoice)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get update query: %w", err)
-	}
+// Update updates an outcome invoice if it is still at the given version (transaction support can be added if items need updating)
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.OutcomeInvoiceUpdateRequest) (*models.OutcomeInvoice, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.OutcomeInvoice, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
 
-	_, err = h.db.Exec(query,
-		id,
-		req.SupplierID,
-		req.TransactionDate,
-		req.DueDate,
-		req.Subtotal,
-		req.TaxAmount,
-		req.DiscountAmount,
-		req.TotalAmount,
-		req.ImageURL,
-		req.Notes,
-	)
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.OutcomeInvoiceUpdateRequest) (*models.OutcomeInvoice, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(outcomesql.UpdateOutcomeInvoice), queries.Args{
+		"id":               id,
+		"version":          version,
+		"supplier_id":      req.SupplierID,
+		"transaction_date": req.TransactionDate,
+		"due_date":         req.DueDate,
+		"subtotal":         req.Subtotal,
+		"tax_amount":       req.TaxAmount,
+		"discount_amount":  req.DiscountAmount,
+		"total_amount":     req.TotalAmount,
+		"image_url":        req.ImageURL,
+		"notes":            req.Notes,
+	})
 
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update outcome invoice")
 		return nil, fmt.Errorf("failed to update outcome invoice: %w", err)
 	}
 
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		if _, err := h.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, sharedErrors.PreconditionFailed("outcome_invoice_version_mismatch", "outcome invoice was modified by another request")
+	}
+
 	// Return updated invoice
-	return h.GetByID(id)
+	return h.GetByID(ctx, id)
 }
 
 // Delete deletes an outcome invoice (transaction support can be added if cascading deletes are needed)
-func (h *DBHandler) Delete(id string) error {
-	query, err := h.queries.Get(outcomesql.DeleteOutcomeInvoice)
-	if err != nil {
-		return fmt.Errorf("failed to get delete query: %w", err)
-	}
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
 
-	result, err := h.db.Exec(query, id)
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(outcomesql.DeleteOutcomeInvoice), queries.Args{"id": id})
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete outcome invoice")
 		return fmt.Errorf("failed to delete outcome invoice: %w", err)
@@ -225,38 +246,67 @@ func (h *DBHandler) Delete(id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("outcome invoice not found")
+		return sharedErrors.NotFound("outcome_invoice_not_found", "outcome invoice not found")
 	}
 
 	return nil
 }
 
-// List retrieves outcome invoices with pagination and filtering
-func (h *DBHandler) List(req *models.OutcomeInvoiceListRequest) (*models.OutcomeInvoiceListResponse, error) {
-	// Get the list and count queries
-	listQuery, err := h.queries.Get(outcomesql.ListOutcomeInvoices)
+// Restore brings back a soft deleted outcome invoice
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.OutcomeInvoice, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.OutcomeInvoice, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.OutcomeInvoice, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(outcomesql.RestoreOutcomeInvoice), queries.Args{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore outcome invoice: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("outcome_invoice_not_found", "deleted outcome invoice not found")
+	}
+
+	h.logger.WithField("id", id).Info("Outcome invoice restored")
+	return h.GetByID(ctx, id)
+}
+
+// Purge hard deletes the outcome invoices soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(outcomesql.PurgeOutcomeInvoices), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
+		return 0, fmt.Errorf("failed to purge outcome invoices: %w", err)
 	}
+	return purged, nil
+}
 
-	countQuery, err := h.queries.Get(outcomesql.CountOutcomeInvoices)
+// List retrieves a page of outcome invoices matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.OutcomeInvoiceListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(outcomesql.ListOutcomeInvoices), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get count query: %w", err)
+		return nil, fmt.Errorf("failed to build outcome invoices count: %w", err)
 	}
 
 	// Get total count
-	// pvillalobos -> revisit later about adding NULL suppliers for filtering
 	var total int
-	err = h.db.QueryRow(countQuery).Scan(&total)
+	err = h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to count outcome invoices")
 		return nil, fmt.Errorf("failed to count outcome invoices: %w", err)
 	}
 
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(outcomesql.ListOutcomeInvoices), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build outcome invoices list: %w", err)
+	}
+
 	// Get paginated results
-	// pvillalobos -> revisit later about adding NULL suppliers for filtering
-	offset := (req.Page - 1) * req.Limit
-	rows, err := h.db.Query(listQuery, req.Limit, offset)
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list outcome invoices")
 		return nil, fmt.Errorf("failed to list outcome invoices: %w", err)
@@ -280,6 +330,8 @@ func (h *DBHandler) List(req *models.OutcomeInvoiceListRequest) (*models.Outcome
 			&invoice.Notes,
 			&invoice.CreatedAt,
 			&invoice.UpdatedAt,
+			&invoice.Version,
+			&invoice.DeletedAt,
 		)
 		if err != nil {
 			h.logger.WithError(err).Error("Failed to scan outcome invoice")
@@ -287,7 +339,7 @@ func (h *DBHandler) List(req *models.OutcomeInvoiceListRequest) (*models.Outcome
 		}
 
 		// Get invoice items for this invoice
-		invoiceItems, err := h.getInvoiceItems(invoice.ID)
+		invoiceItems, err := h.getInvoiceItems(ctx, invoice.ID)
 		if err != nil {
 			h.logger.WithError(err).Error("Failed to get invoice items")
 			return nil, fmt.Errorf("failed to get invoice items: %w", err)
@@ -301,32 +353,32 @@ func (h *DBHandler) List(req *models.OutcomeInvoiceListRequest) (*models.Outcome
 		return nil, fmt.Errorf("error iterating rows: %w", err)
 	}
 
+	invoices, pageInfo, err := queryspec.Paginate(spec, invoices)
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.OutcomeInvoiceListResponse{
 		Invoices: invoices,
 		Total:    total,
-		Page:     req.Page,
-		Limit:    req.Limit,
+		Limit:    spec.Limit,
+		PageInfo: pageInfo,
 	}, nil
 }
 
 // createInvoiceItem creates a single invoice item within a transaction
-func (h *DBHandler) createInvoiceItem(tx *sql.Tx, req *invoiceItemModels.InvoiceItemCreateRequest) (*invoiceItemModels.InvoiceItem, error) {
-	query, err := h.invoiceItemQueries.Get(invoiceItemSql.CreateInvoiceItem)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get create item query: %w", err)
-	}
-
+func (h *DBHandler) createInvoiceItem(ctx context.Context, tx *sharedDb.Tx, req *invoiceItemModels.InvoiceItemCreateRequest) (*invoiceItemModels.InvoiceItem, error) {
 	var item invoiceItemModels.InvoiceItem
-	err = tx.QueryRow(query,
-		req.InvoiceID,
-		req.StockVariantID,
-		req.Detail,
-		req.Count,
-		req.UnitType,
-		req.Price,
-		req.ItemsPerUnit,
-		req.ExpirationDate,
-	).Scan(
+	err := tx.QueryRowNamedContext(ctx, h.invoiceItemQueries.Get(invoiceItemSql.CreateInvoiceItem), queries.Args{
+		"invoice_id":       req.InvoiceID,
+		"stock_variant_id": req.StockVariantID,
+		"detail":           req.Detail,
+		"count":            req.Count,
+		"unit_type":        req.UnitType,
+		"price":            req.Price,
+		"items_per_unit":   req.ItemsPerUnit,
+		"expiration_date":  req.ExpirationDate,
+	}).Scan(
 		&item.ID, &item.InvoiceID, &item.StockVariantID, &item.Detail,
 		&item.Count, &item.UnitType, &item.Price, &item.ItemsPerUnit,
 		&item.Total, &item.ExpirationDate, &item.CreatedAt, &item.UpdatedAt,
@@ -340,13 +392,8 @@ func (h *DBHandler) createInvoiceItem(tx *sql.Tx, req *invoiceItemModels.Invoice
 }
 
 // getInvoiceItems retrieves all invoice items for a given invoice ID
-func (h *DBHandler) getInvoiceItems(invoiceID string) ([]models.InvoiceItem, error) {
-	query, err := h.invoiceItemQueries.Get(invoiceItemSql.ListInvoiceItems)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get list items query: %w", err)
-	}
-
-	rows, err := h.db.Query(query, invoiceID)
+func (h *DBHandler) getInvoiceItems(ctx context.Context, invoiceID string) ([]models.InvoiceItem, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.invoiceItemQueries.Get(invoiceItemSql.ListInvoiceItems), queries.Args{"invoice_id": invoiceID})
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to query invoice items")
 		return nil, fmt.Errorf("failed to query invoice items: %w", err)
@@ -397,89 +444,3 @@ func (h *DBHandler) getInvoiceItems(invoiceID string) ([]models.InvoiceItem, err
 
 	return items, nil
 }
-
-// createStockCount creates a stock count record within a transaction
-func (h *DBHandler) createStockCount(tx *sql.Tx, stockVariantID, invoiceID string, count float64, unit string, price float64, purchasedAt interface{}) error {
-	query, err := h.queries.Get(outcomesql.CreateStockCount)
-	if err != nil {
-		h.logger.WithError(err).Error("[COST_CALC] Failed to get create stock count query")
-		return fmt.Errorf("failed to get create stock count query: %w", err)
-	}
-
-	// Calculate cost per portion
-	var costPerPortion *float64
-	if price > 0 {
-		totalKG, err := h.convertToKG(count, unit)
-		if err != nil {
-			h.logger.WithError(err).WithFields(logrus.Fields{"count": count, "unit": unit}).Warn("[COST_CALC] Failed to convert to KG, cost_per_portion will be null")
-		} else if totalKG > 0 {
-			cost := h.calculateCostPerPortion(totalKG, price)
-			costPerPortion = &cost
-		} else {
-			h.logger.Warn("[COST_CALC] totalKG is 0 or negative, cost_per_portion will be null")
-		}
-	} else {
-		h.logger.Warn("[COST_CALC] Price is 0 or negative, skipping cost calculation")
-	}
-
-	_, err = tx.Exec(query, stockVariantID, invoiceID, count, unit, price, costPerPortion, purchasedAt)
-	if err != nil {
-		h.logger.WithError(err).Error("[COST_CALC] Failed to insert stock count")
-		return fmt.Errorf("failed to create stock count: %w", err)
-	}
-
-	// Update avg_cost for the stock variant
-	if err := h.updateAvgCost(tx, stockVariantID); err != nil {
-		h.logger.WithError(err).WithField("stock_variant_id", stockVariantID).Error("[COST_CALC] Failed to update avg_cost for stock variant")
-	}
-
-	return nil
-}
-
-// convertToKG converts the given count and unit to kilograms
-func (h *DBHandler) convertToKG(count float64, unit string) (float64, error) {
-	switch strings.ToLower(unit) {
-	case "kg":
-		return count, nil
-	case "g":
-		return count / 1000, nil
-	case "l":
-		return count, nil
-	case "ml":
-		return count / 1000, nil
-	default:
-		h.logger.WithField("unit", unit).Warn("[COST_CALC] Unsupported unit for conversion")
-		return 0, fmt.Errorf("unsupported unit: %s", unit)
-	}
-}
-
-// calculateCostPerPortion calculates the cost per portion
-func (h *DBHandler) calculateCostPerPortion(totalKG float64, unitPrice float64) float64 {
-	if totalKG <= 0 || h.portionGrams <= 0 {
-		return 0
-	}
-	portionKG := h.portionGrams / 1000
-	numPortions := totalKG / portionKG
-	if numPortions <= 0 {
-		return 0
-	}
-	return unitPrice / numPortions
-}
-
-// updateAvgCost updates the average cost per portion for a stock variant
-func (h *DBHandler) updateAvgCost(tx *sql.Tx, stockVariantID string) error {
-	query, err := h.queries.Get(outcomesql.UpdateAvgCost)
-	if err != nil {
-		h.logger.WithError(err).Error("[COST_CALC] Failed to get update_avg_cost query")
-		return fmt.Errorf("failed to get update_avg_cost query: %w", err)
-	}
-
-	var id string
-	var avgCost float64
-	err = tx.QueryRow(query, stockVariantID).Scan(&id, &avgCost)
-	if err != nil {
-		h.logger.WithError(err).WithField("stock_variant_id", stockVariantID).Error("[COST_CALC] Failed to update avg_cost")
-		return fmt.Errorf("failed to update avg_cost: %w", err)
-	}
-	return nil
-}
@@ -3,9 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"invoice-service/pkg/entities/outcome_invoices/models"
+	outcomesql "invoice-service/pkg/entities/outcome_invoices/sql"
 	sharedHttp "shared/http"
 
 	"github.com/gorilla/mux"
@@ -36,13 +36,14 @@ func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 		req.SupplierID = nil
 	}
 
-	invoice, err := h.dbHandler.Create(&req)
+	invoice, err := h.dbHandler.Create(r.Context(), &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create outcome invoice")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to create outcome invoice")
+		sharedHttp.SendError(w, r, err, "Failed to create outcome invoice")
 		return
 	}
 
+	sharedHttp.SetETag(w, invoice.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Outcome invoice created successfully", invoice)
 }
 
@@ -50,17 +51,14 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	invoice, err := h.dbHandler.GetByID(id)
+	invoice, err := h.dbHandler.GetByID(r.Context(), id)
 	if err != nil {
-		if err.Error() == "outcome invoice not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Outcome invoice not found")
-			return
-		}
 		h.logger.WithError(err).Error("Failed to get outcome invoice")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to get outcome invoice")
+		sharedHttp.SendError(w, r, err, "Failed to get outcome invoice")
 		return
 	}
 
+	sharedHttp.SetETag(w, invoice.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Outcome invoice retrieved successfully", invoice)
 }
 
@@ -68,6 +66,12 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update outcome invoice")
+		return
+	}
+
 	var req models.OutcomeInvoiceUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request body")
@@ -79,17 +83,14 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		req.SupplierID = nil
 	}
 
-	invoice, err := h.dbHandler.Update(id, &req)
+	invoice, err := h.dbHandler.Update(r.Context(), id, version, &req)
 	if err != nil {
-		if err.Error() == "outcome invoice not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Outcome invoice not found")
-			return
-		}
 		h.logger.WithError(err).Error("Failed to update outcome invoice")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update outcome invoice")
+		sharedHttp.SendError(w, r, err, "Failed to update outcome invoice")
 		return
 	}
 
+	sharedHttp.SetETag(w, invoice.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Outcome invoice updated successfully", invoice)
 }
 
@@ -97,52 +98,45 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.dbHandler.Delete(id)
+	err := h.dbHandler.Delete(r.Context(), id)
 	if err != nil {
-		if err.Error() == "outcome invoice not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Outcome invoice not found")
-			return
-		}
 		h.logger.WithError(err).Error("Failed to delete outcome invoice")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to delete outcome invoice")
+		sharedHttp.SendError(w, r, err, "Failed to delete outcome invoice")
 		return
 	}
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Outcome invoice deleted successfully", nil)
 }
 
-func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	page := 1
-	limit := 10
-	supplierID := r.URL.Query().Get("supplier_id")
-
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
-	}
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
 
-	req := &models.OutcomeInvoiceListRequest{
-		Page:  page,
-		Limit: limit,
+	invoice, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore outcome invoice")
+		sharedHttp.SendError(w, r, err, "Failed to restore outcome invoice")
+		return
 	}
 
-	if supplierID != "" {
-		req.SupplierID = &supplierID
+	sharedHttp.SetETag(w, invoice.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Outcome invoice restored", invoice)
+}
+
+func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
+	spec, err := outcomesql.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
 	}
 
-	response, err := h.dbHandler.List(req)
+	response, err := h.dbHandler.List(r.Context(), spec)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list outcome invoices")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list outcome invoices")
+		sharedHttp.SendError(w, r, err, "Failed to list outcome invoices")
 		return
 	}
+	response.SetLinks(r.URL)
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Outcome invoices retrieved successfully", response)
 }
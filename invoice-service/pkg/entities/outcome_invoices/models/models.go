@@ -4,6 +4,8 @@ import (
 	"time"
 
 	invoiceItemModels "invoice-service/pkg/entities/invoice_items/models"
+	"shared/db/queryspec"
+	"shared/money"
 )
 
 // InvoiceItem alias for easier reference
@@ -17,15 +19,17 @@ type OutcomeInvoice struct {
 	SupplierID      *string       `json:"supplier_id,omitempty"`
 	TransactionDate time.Time     `json:"transaction_date"`
 	DueDate         *time.Time    `json:"due_date,omitempty"`
-	Subtotal        *float64      `json:"subtotal,omitempty"`
-	TaxAmount       *float64      `json:"tax_amount,omitempty"`
-	DiscountAmount  *float64      `json:"discount_amount,omitempty"`
-	TotalAmount     *float64      `json:"total_amount,omitempty"`
+	Subtotal        *money.Money  `json:"subtotal,omitempty"`
+	TaxAmount       *money.Money  `json:"tax_amount,omitempty"`
+	DiscountAmount  *money.Money  `json:"discount_amount,omitempty"`
+	TotalAmount     *money.Money  `json:"total_amount,omitempty"`
 	ImageURL        *string       `json:"image_url,omitempty"`
 	Notes           *string       `json:"notes,omitempty"`
 	InvoiceItems    []InvoiceItem `json:"invoice_items,omitempty"`
 	CreatedAt       time.Time     `json:"created_at"`
 	UpdatedAt       time.Time     `json:"updated_at"`
+	Version         int           `json:"version"`
+	DeletedAt       *time.Time    `json:"deleted_at,omitempty"`
 }
 
 // OutcomeInvoiceCreateRequest represents a request to create an outcome invoice
@@ -34,10 +38,10 @@ type OutcomeInvoiceCreateRequest struct {
 	SupplierID      *string                    `json:"supplier_id,omitempty"`
 	TransactionDate time.Time                  `json:"transaction_date"`
 	DueDate         *time.Time                 `json:"due_date,omitempty"`
-	Subtotal        *float64                   `json:"subtotal,omitempty"`
-	TaxAmount       *float64                   `json:"tax_amount,omitempty"`
-	DiscountAmount  *float64                   `json:"discount_amount,omitempty"`
-	TotalAmount     *float64                   `json:"total_amount,omitempty"`
+	Subtotal        *money.Money               `json:"subtotal,omitempty"`
+	TaxAmount       *money.Money               `json:"tax_amount,omitempty"`
+	DiscountAmount  *money.Money               `json:"discount_amount,omitempty"`
+	TotalAmount     *money.Money               `json:"total_amount,omitempty"`
 	ImageURL        *string                    `json:"image_url,omitempty"`
 	Notes           *string                    `json:"notes,omitempty"`
 	InvoiceItems    []InvoiceItemCreateRequest `json:"invoice_items,omitempty"`
@@ -45,28 +49,21 @@ type OutcomeInvoiceCreateRequest struct {
 
 // OutcomeInvoiceUpdateRequest represents a request to update an outcome invoice
 type OutcomeInvoiceUpdateRequest struct {
-	SupplierID      *string    `json:"supplier_id,omitempty"`
-	TransactionDate *time.Time `json:"transaction_date,omitempty"`
-	DueDate         *time.Time `json:"due_date,omitempty"`
-	Subtotal        *float64   `json:"subtotal,omitempty"`
-	TaxAmount       *float64   `json:"tax_amount,omitempty"`
-	DiscountAmount  *float64   `json:"discount_amount,omitempty"`
-	TotalAmount     *float64   `json:"total_amount,omitempty"`
-	ImageURL        *string    `json:"image_url,omitempty"`
-	Notes           *string    `json:"notes,omitempty"`
-}
-
-// OutcomeInvoiceListRequest represents filter parameters for listing outcome invoices
-type OutcomeInvoiceListRequest struct {
-	SupplierID *string `json:"supplier_id,omitempty"`
-	Page       int     `json:"page"`
-	Limit      int     `json:"limit"`
+	SupplierID      *string      `json:"supplier_id,omitempty"`
+	TransactionDate *time.Time   `json:"transaction_date,omitempty"`
+	DueDate         *time.Time   `json:"due_date,omitempty"`
+	Subtotal        *money.Money `json:"subtotal,omitempty"`
+	TaxAmount       *money.Money `json:"tax_amount,omitempty"`
+	DiscountAmount  *money.Money `json:"discount_amount,omitempty"`
+	TotalAmount     *money.Money `json:"total_amount,omitempty"`
+	ImageURL        *string      `json:"image_url,omitempty"`
+	Notes           *string      `json:"notes,omitempty"`
 }
 
 // OutcomeInvoiceListResponse represents a paginated list of outcome invoices
 type OutcomeInvoiceListResponse struct {
 	Invoices []OutcomeInvoice `json:"invoices"`
 	Total    int              `json:"total"`
-	Page     int              `json:"page"`
 	Limit    int              `json:"limit"`
+	queryspec.PageInfo
 }
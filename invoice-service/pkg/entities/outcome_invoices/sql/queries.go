@@ -2,60 +2,45 @@ package sql
 
 import (
 	"embed"
-	"fmt"
-	"strings"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
 )
 
 //go:embed scripts/*.sql
-var sqlFiles embed.FS
-
-// Queries holds all SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from the scripts directory
-func LoadQueries() (*Queries, error) {
-	queries := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files, err := sqlFiles.ReadDir("scripts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			content, err := sqlFiles.ReadFile("scripts/" + file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read SQL file %s: %w", file.Name(), err)
-			}
+var sqlScripts embed.FS
 
-			queryName := strings.TrimSuffix(file.Name(), ".sql")
-			queries.queries[queryName] = string(content)
-		}
-	}
-	return queries, nil
-}
-
-// Get retrieves a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, exists := q.queries[name]
-	if !exists {
-		return "", fmt.Errorf("query '%s' not found", name)
-	}
-	return query, nil
-}
-
-// SQL query constants
+// SQL query names
 const (
-	CreateOutcomeInvoice = "create_outcome_invoice"
-	GetOutcomeInvoice    = "get_outcome_invoice"
-	UpdateOutcomeInvoice = "update_outcome_invoice"
-	DeleteOutcomeInvoice = "delete_outcome_invoice"
-	ListOutcomeInvoices  = "list_outcome_invoices"
-	CountOutcomeInvoices = "count_outcome_invoices"
-	CreateStockCount     = "create_stock_count"
-	UpdateAvgCost        = "update_avg_cost"
+	CreateOutcomeInvoice  queries.Name = "create_outcome_invoice"
+	GetOutcomeInvoice     queries.Name = "get_outcome_invoice"
+	UpdateOutcomeInvoice  queries.Name = "update_outcome_invoice"
+	DeleteOutcomeInvoice  queries.Name = "delete_outcome_invoice"
+	RestoreOutcomeInvoice queries.Name = "restore_outcome_invoice"
+	PurgeOutcomeInvoices  queries.Name = "purge_outcome_invoices"
+	ListOutcomeInvoices   queries.Name = "list_outcome_invoices"
 )
+
+// ListSchema whitelists the columns of list_outcome_invoices that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("-transaction_date",
+	queryspec.Field{Name: "invoice_number", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "supplier_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "transaction_date", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "due_date", Type: queryspec.Time, Filterable: true},
+	queryspec.Field{Name: "total_amount", Type: queryspec.Number, Filterable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).WithDefaultLimit(10).WithSoftDelete()
+
+// LoadQueries loads and validates the outcome invoice SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		CreateOutcomeInvoice,
+		GetOutcomeInvoice,
+		UpdateOutcomeInvoice,
+		DeleteOutcomeInvoice,
+		RestoreOutcomeInvoice,
+		PurgeOutcomeInvoices,
+		ListOutcomeInvoices,
+	)
+}
@@ -1,4 +0,0 @@
--- Count outcome invoices
--- pvillalobos -> revisit later about adding NULL suppliers for filtering
-SELECT COUNT(*)
-FROM outcome_invoices;
@@ -13,5 +13,5 @@ INSERT INTO outcome_invoices (
     created_at,
     updated_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW()
-) RETURNING id, created_at, updated_at;
+    @invoice_number, @supplier_id, @transaction_date, @due_date, @subtotal, @tax_amount, @discount_amount, @total_amount, @image_url, @notes, NOW(), NOW()
+) RETURNING id, created_at, updated_at, version;
@@ -1,2 +1,2 @@
--- Delete an outcome invoice
-DELETE FROM outcome_invoices WHERE id = $1;
+-- Soft delete an outcome invoice, its items stay until it is purged
+UPDATE outcome_invoices SET deleted_at = CURRENT_TIMESTAMP WHERE id = @id AND deleted_at IS NULL;
@@ -12,6 +12,7 @@ SELECT
     image_url,
     notes,
     created_at,
-    updated_at
+    updated_at,
+    version
 FROM outcome_invoices
-WHERE id = $1;
+WHERE id = @id AND deleted_at IS NULL;
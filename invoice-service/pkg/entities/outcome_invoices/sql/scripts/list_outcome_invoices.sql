@@ -1,5 +1,4 @@
--- List outcome invoices
--- pvillalobos -> revisit later about adding NULL suppliers for filtering
+-- List outcome invoices, filtered, sorted and paginated through queryspec
 SELECT
     id,
     invoice_number,
@@ -13,7 +12,7 @@ SELECT
     image_url,
     notes,
     created_at,
-    updated_at
-FROM outcome_invoices
-ORDER BY transaction_date DESC
-LIMIT $1 OFFSET $2;
+    updated_at,
+    version,
+    deleted_at
+FROM outcome_invoices;
@@ -0,0 +1,11 @@
+-- Hard delete outcome invoices soft deleted before the retention window together with
+-- their items. Invoices that stock counts were recorded from are kept.
+WITH purged AS (
+    DELETE FROM outcome_invoices oi
+    WHERE oi.deleted_at < @deleted_before
+      AND NOT EXISTS (SELECT 1 FROM stock_count sc WHERE sc.invoice_id = oi.id)
+    RETURNING oi.id
+), purged_items AS (
+    DELETE FROM invoice_items WHERE invoice_id IN (SELECT id FROM purged)
+)
+SELECT COUNT(*) FROM purged;
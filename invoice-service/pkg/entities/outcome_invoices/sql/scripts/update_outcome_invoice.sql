@@ -1,14 +1,14 @@
 -- Update an outcome invoice
 UPDATE outcome_invoices SET
-    supplier_id = COALESCE($2, supplier_id),
-    transaction_date = COALESCE($3, transaction_date),
-    due_date = COALESCE($4, due_date),
-    subtotal = COALESCE($5, subtotal),
-    tax_amount = COALESCE($6, tax_amount),
-    discount_amount = COALESCE($7, discount_amount),
-    total_amount = COALESCE($8, total_amount),
-    image_url = COALESCE($9, image_url),
-    notes = COALESCE($10, notes),
+    supplier_id = COALESCE(@supplier_id, supplier_id),
+    transaction_date = COALESCE(@transaction_date, transaction_date),
+    due_date = COALESCE(@due_date, due_date),
+    subtotal = COALESCE(@subtotal, subtotal),
+    tax_amount = COALESCE(@tax_amount, tax_amount),
+    discount_amount = COALESCE(@discount_amount, discount_amount),
+    total_amount = COALESCE(@total_amount, total_amount),
+    image_url = COALESCE(@image_url, image_url),
+    notes = COALESCE(@notes, notes),
     updated_at = NOW()
-WHERE id = $1
-RETURNING id, updated_at;
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id, updated_at, version;
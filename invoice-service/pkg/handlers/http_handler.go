@@ -2,14 +2,17 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
+	"shared/audit"
 	sharedConfig "shared/config"
 	sharedDb "shared/db"
+	"shared/db/softdelete"
+	"shared/events"
 	sharedHttp "shared/http"
+	"shared/middlewares"
 
 	incomeInvoiceHandlers "invoice-service/pkg/entities/income_invoices/handlers"
 	outcomeInvoiceHandlers "invoice-service/pkg/entities/outcome_invoices/handlers"
@@ -24,6 +27,7 @@ type MainHTTPHandler struct {
 	cancelHealthMonitor   context.CancelFunc
 	outcomeInvoiceHandler *outcomeInvoiceHandlers.HTTPHandler
 	incomeInvoiceHandler  *incomeInvoiceHandlers.HTTPHandler
+	purger                *softdelete.Purger
 	logger                *logrus.Logger
 }
 
@@ -36,6 +40,7 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 		Password:        cfg.GetString("DB_PASSWORD"),
 		DBName:          cfg.GetString("DB_NAME"),
 		SSLMode:         cfg.GetString("DB_SSL_MODE"),
+		ApplicationName: "invoice-service",
 		MaxOpenConns:    25,
 		MaxIdleConns:    5,
 		ConnMaxLifetime: 5 * time.Minute,
@@ -44,6 +49,7 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 		QueryTimeout:    30 * time.Second,
 		MaxRetries:      3,
 		RetryInterval:   2 * time.Second,
+		CacheStatements: true,
 	}
 
 	db, err := sharedDb.NewDatabaseHandler(dbConfig, logger)
@@ -51,8 +57,32 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 		return nil, fmt.Errorf("failed to create database handler: %w", err)
 	}
 
+	// Create audit log, every entity records its changes in it
+	auditLog, err := audit.NewLog(db, "invoice-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	// Create event outbox and relay
+	outbox, err := events.NewOutbox(db, "invoice-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event outbox: %w", err)
+	}
+	broker, err := events.NewPostgresBroker(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event broker: %w", err)
+	}
+	relay, err := events.NewRelay(db, broker, "invoice-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event relay: %w", err)
+	}
+
 	// Create outcome invoice handlers
-	outcomeInvoiceDBHandler, err := outcomeInvoiceHandlers.NewDBHandler(db, cfg, logger)
+	outcomeInvoiceDBHandler, err := outcomeInvoiceHandlers.NewDBHandler(db, outbox, auditLog, logger)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create outcome invoice handler: %w", err)
@@ -60,37 +90,50 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 	outcomeInvoiceHTTPHandler := outcomeInvoiceHandlers.NewHTTPHandler(outcomeInvoiceDBHandler, logger)
 
 	// Create income invoice handlers
-	incomeInvoiceDBHandler, err := incomeInvoiceHandlers.NewDBHandler(db, logger)
+	incomeInvoiceDBHandler, err := incomeInvoiceHandlers.NewDBHandler(db, auditLog, logger)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create income invoice handler: %w", err)
 	}
 	incomeInvoiceHTTPHandler := incomeInvoiceHandlers.NewHTTPHandler(incomeInvoiceDBHandler, logger)
 
-	// Create cancellable context for health monitor
+	// Create the purger of soft deleted invoices, it also removes their items
+	purger := softdelete.NewPurger(db, cfg.GetInt("SOFT_DELETE_RETENTION_DAYS"), "invoice-service", logger).
+		Add("outcome_invoices", outcomeInvoiceDBHandler).
+		Add("income_invoices", incomeInvoiceDBHandler)
+
+	// Create cancellable context for health monitor, event relay and purger
 	ctx, cancel := context.WithCancel(context.Background())
 
-	// Create HTTP health monitor for data-service
+	// Create health monitor: the database is critical since the service talks to
+	// Postgres directly, data-service only serves configuration at startup
 	httpHealthMonitor, err := sharedHttp.NewHealthMonitor(logger, 1*time.Second)
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to create HTTP health monitor: %w", err)
 	}
-	httpHealthMonitor.AddService("data-service", sharedConfig.DATA_SERVICE_URL+"/api/v1/data/p/health")
+	httpHealthMonitor.AddCheck("database", sharedHttp.Critical, func(ctx context.Context) error {
+		return db.Ping()
+	})
+	httpHealthMonitor.AddService("data-service", sharedConfig.DATA_SERVICE_URL+"/api/v1/data/p/readyz", sharedHttp.Degraded)
 	httpHealthMonitor.Start(ctx)
 
+	go relay.Start(ctx)
+	go purger.Start(ctx)
+
 	return &MainHTTPHandler{
 		db:                    db,
 		httpHealthMonitor:     httpHealthMonitor,
 		cancelHealthMonitor:   cancel,
 		outcomeInvoiceHandler: outcomeInvoiceHTTPHandler,
 		incomeInvoiceHandler:  incomeInvoiceHTTPHandler,
+		purger:                purger,
 		logger:                logger,
 	}, nil
 }
 
 func (h *MainHTTPHandler) CloseDB() error {
-	// Stop health monitor
+	// Stop health monitor, event relay and purger
 	if h.cancelHealthMonitor != nil {
 		h.cancelHealthMonitor()
 	}
@@ -104,8 +147,13 @@ func (h *MainHTTPHandler) CloseDB() error {
 }
 
 func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
-	// Health check
-	router.HandleFunc("/api/v1/invoices/p/health", h.HealthCheck).Methods("GET")
+	// Attribute changes to the user the gateway authenticated
+	router.Use(audit.Middleware)
+
+	// Health checks (/p/health is kept for existing clients)
+	router.HandleFunc("/api/v1/invoices/p/livez", sharedHttp.LivenessHandler("invoice-service")).Methods("GET")
+	router.HandleFunc("/api/v1/invoices/p/readyz", h.httpHealthMonitor.ReadinessHandler("invoice-service")).Methods("GET")
+	router.HandleFunc("/api/v1/invoices/p/health", h.httpHealthMonitor.ReadinessHandler("invoice-service")).Methods("GET")
 
 	// Outcome Invoices (expenses from suppliers)
 	router.HandleFunc("/api/v1/invoices/outcome", h.outcomeInvoiceHandler.List).Methods("GET")
@@ -113,6 +161,7 @@ func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/invoices/outcome/{id}", h.GetOutcomeInvoiceByID).Methods("GET")
 	router.HandleFunc("/api/v1/invoices/outcome/{id}", h.outcomeInvoiceHandler.Update).Methods("PUT")
 	router.HandleFunc("/api/v1/invoices/outcome/{id}", h.outcomeInvoiceHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/v1/invoices/outcome/{id}/restore", h.outcomeInvoiceHandler.Restore).Methods("POST")
 
 	// Income Invoices (revenue from customers)
 	router.HandleFunc("/api/v1/invoices/income", h.incomeInvoiceHandler.List).Methods("GET")
@@ -120,37 +169,13 @@ func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/invoices/income/{id}", h.GetIncomeInvoiceByID).Methods("GET")
 	router.HandleFunc("/api/v1/invoices/income/{id}", h.incomeInvoiceHandler.Update).Methods("PUT")
 	router.HandleFunc("/api/v1/invoices/income/{id}", h.incomeInvoiceHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/v1/invoices/income/{id}/restore", h.incomeInvoiceHandler.Restore).Methods("POST")
 
 	// Invoice Items are now handled within invoice CRUD operations
 	// No separate endpoints for invoice items
-}
-
-func (h *MainHTTPHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
-		"service":   "invoice-service",
-		"timestamp": time.Now(),
-	}
-
-	// Check cached health state from background monitor
-	healthStatus := h.httpHealthMonitor.GetHealthStatus()
-	if !healthStatus.IsHealthy {
-		response["status"] = "unhealthy"
-		response["message"] = "Dependent services are not healthy"
-		response["services"] = healthStatus.Services
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(response)
-		return
-	}
-
-	response["status"] = "healthy"
-	response["message"] = "Invoice service is healthy"
-	response["services"] = healthStatus.Services
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	// Admin
+	router.Handle("/api/v1/invoices/admin/purge", middlewares.RequireRole("admin")(http.HandlerFunc(h.purger.Handler))).Methods("POST")
 }
 
 // GetOutcomeInvoiceByID gets an outcome invoice with its invoice items
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	catalogSQL "menu-service/pkg/entities/catalog/sql"
+	menuCategoryHandlers "menu-service/pkg/entities/menu_categories/handlers"
+	menuCategoryModels "menu-service/pkg/entities/menu_categories/models"
+	menuSubCategoryHandlers "menu-service/pkg/entities/menu_sub_categories/handlers"
+	menuSubCategoryModels "menu-service/pkg/entities/menu_sub_categories/models"
+	menuVariantHandlers "menu-service/pkg/entities/menu_variants/handlers"
+	menuVariantModels "menu-service/pkg/entities/menu_variants/models"
+	"shared/bulk"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	sharedErrors "shared/errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TreeSheet is the name of the menu tree sheet, as used in the import and export URLs
+const TreeSheet = "tree"
+
+// treeColumns are the columns of the menu tree sheet. Each row is a menu variant with
+// the names of its category and sub-category; a row without a variant name only makes
+// sure its category and sub-category exist.
+var treeColumns = []string{
+	"category", "sub_category", "item_type", "name", "description", "price", "happy_hour_price",
+	"is_available", "is_alcoholic", "preparation_time", "display_order",
+	"menu_types", "dietary_tags", "allergens", "image_url",
+}
+
+// DBHandler imports and exports the menu tree through the entity handlers, so imported
+// rows are validated, versioned, audited and publish price changes like single edits
+type DBHandler struct {
+	db            *sharedDb.DbHandler
+	queries       *queries.Registry
+	categories    *menuCategoryHandlers.DBHandler
+	subCategories *menuSubCategoryHandlers.DBHandler
+	variants      *menuVariantHandlers.DBHandler
+	logger        *logrus.Logger
+}
+
+// NewDBHandler creates a new database handler
+func NewDBHandler(
+	db *sharedDb.DbHandler,
+	categories *menuCategoryHandlers.DBHandler,
+	subCategories *menuSubCategoryHandlers.DBHandler,
+	variants *menuVariantHandlers.DBHandler,
+	logger *logrus.Logger,
+) (*DBHandler, error) {
+	queries, err := catalogSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DBHandler{
+		db:            db,
+		queries:       queries,
+		categories:    categories,
+		subCategories: subCategories,
+		variants:      variants,
+		logger:        logger,
+	}, nil
+}
+
+func checkSheet(name string) error {
+	if name != TreeSheet {
+		return sharedErrors.NotFound("sheet_not_found", fmt.Sprintf("unknown menu sheet %q", name))
+	}
+	return nil
+}
+
+// Export returns the live menu tree, one row per variant. Categories and
+// sub-categories without variants get a row of their own.
+func (h *DBHandler) Export(ctx context.Context, name string) (*bulk.Table, error) {
+	if err := checkSheet(name); err != nil {
+		return nil, err
+	}
+
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(catalogSQL.ExportMenuTreeQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export menu tree: %w", err)
+	}
+	defer rows.Close()
+
+	table := bulk.NewTable(treeColumns...)
+	values := make([]sql.NullString, len(treeColumns))
+	dest := make([]interface{}, len(treeColumns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan menu tree: %w", err)
+		}
+		row := make([]string, len(values))
+		for i, value := range values {
+			row[i] = value.String
+		}
+		table.Append(row...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating menu tree: %w", err)
+	}
+
+	return table, nil
+}
+
+// Import upserts the rows of the menu tree in one transaction, creating missing
+// categories and sub-categories on the way. Nothing is written when dryRun is set or
+// any row fails.
+func (h *DBHandler) Import(ctx context.Context, name string, table *bulk.Table, dryRun bool) (*bulk.Report, error) {
+	if err := checkSheet(name); err != nil {
+		return nil, err
+	}
+	if err := table.RequireColumns("category", "sub_category", "name"); err != nil {
+		return nil, err
+	}
+
+	report, err := bulk.Import(ctx, h.db, name, table, dryRun, h.importRow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import menu tree: %w", err)
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"sheet":     name,
+		"rows":      report.Rows,
+		"created":   report.Created,
+		"updated":   report.Updated,
+		"errors":    len(report.Errors),
+		"dry_run":   dryRun,
+		"committed": report.Committed,
+	}).Info("Menu sheet imported")
+	return report, nil
+}
+
+// importRow upserts the category, sub-category and variant of a row. The action is
+// the one taken on the deepest of them the row names.
+func (h *DBHandler) importRow(ctx context.Context, rec bulk.Record) (bulk.Action, error) {
+	category, err := rec.Required("category")
+	if err != nil {
+		return "", err
+	}
+	subCategory := rec.String("sub_category")
+	name := rec.String("name")
+	if name != "" && subCategory == "" {
+		return "", sharedErrors.Validation("invalid_value", "a variant needs a sub-category",
+			sharedErrors.FieldError{Field: "sub_category", Code: "required", Message: "value is required"})
+	}
+
+	action, err := h.ensureCategory(ctx, category)
+	if err != nil || subCategory == "" {
+		return action, err
+	}
+	if action, err = h.ensureSubCategory(ctx, rec, category, subCategory); err != nil || name == "" {
+		return action, err
+	}
+	return h.upsertVariant(ctx, rec, category, subCategory, name)
+}
+
+func (h *DBHandler) ensureCategory(ctx context.Context, name string) (bulk.Action, error) {
+	id, _, err := h.find(ctx, catalogSQL.FindMenuCategoryQuery, queries.Args{"name": name}, nil)
+	if err != nil || id != "" {
+		return bulk.Updated, err
+	}
+
+	_, err = h.categories.Create(ctx, &menuCategoryModels.MenuCategoryCreateRequest{Name: name})
+	return bulk.Created, err
+}
+
+func (h *DBHandler) ensureSubCategory(ctx context.Context, rec bulk.Record, category, name string) (bulk.Action, error) {
+	itemType := rec.String("item_type")
+	if itemType != "" && itemType != "kitchen" && itemType != "bar" {
+		return "", sharedErrors.Validation("invalid_value", "item type must be kitchen or bar",
+			sharedErrors.FieldError{Field: "item_type", Code: "invalid_item_type", Message: "must be kitchen or bar"})
+	}
+
+	var currentType string
+	id, version, err := h.find(ctx, catalogSQL.FindMenuSubCategoryQuery, queries.Args{"category": category, "name": name}, &currentType)
+	if err != nil {
+		return "", err
+	}
+	if id != "" {
+		if itemType == "" || itemType == currentType {
+			return bulk.Updated, nil
+		}
+		_, err := h.subCategories.Update(ctx, id, version, &menuSubCategoryModels.MenuSubCategoryUpdateRequest{ItemType: &itemType})
+		return bulk.Updated, err
+	}
+
+	if itemType == "" {
+		return "", sharedErrors.Validation("invalid_value", "a new sub-category needs an item type",
+			sharedErrors.FieldError{Field: "item_type", Code: "required", Message: "value is required"})
+	}
+	categoryID, _, err := h.find(ctx, catalogSQL.FindMenuCategoryQuery, queries.Args{"name": category}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = h.subCategories.Create(ctx, &menuSubCategoryModels.MenuSubCategoryCreateRequest{
+		Name:       name,
+		CategoryID: categoryID,
+		ItemType:   itemType,
+		IsActive:   true,
+	})
+	return bulk.Created, err
+}
+
+func (h *DBHandler) upsertVariant(ctx context.Context, rec bulk.Record, category, subCategory, name string) (bulk.Action, error) {
+	price, err := rec.Money("price")
+	if err != nil {
+		return "", err
+	}
+	happyHourPrice, err := rec.Money("happy_hour_price")
+	if err != nil {
+		return "", err
+	}
+	isAvailable, err := rec.Bool("is_available")
+	if err != nil {
+		return "", err
+	}
+	isAlcoholic, err := rec.Bool("is_alcoholic")
+	if err != nil {
+		return "", err
+	}
+	preparationTime, err := rec.Int("preparation_time")
+	if err != nil {
+		return "", err
+	}
+	displayOrder, err := rec.Int("display_order")
+	if err != nil {
+		return "", err
+	}
+	menuTypes := jsonList(rec.List("menu_types"))
+	dietaryTags := jsonList(rec.List("dietary_tags"))
+	allergens := jsonList(rec.List("allergens"))
+
+	id, version, err := h.find(ctx, catalogSQL.FindMenuVariantQuery, queries.Args{"category": category, "sub_category": subCategory, "name": name}, nil)
+	if err != nil {
+		return "", err
+	}
+	if id != "" {
+		_, err := h.variants.Update(ctx, id, version, &menuVariantModels.MenuVariantUpdateRequest{
+			Description:     rec.Optional("description"),
+			Price:           price,
+			HappyHourPrice:  happyHourPrice,
+			ImageURL:        rec.Optional("image_url"),
+			IsAvailable:     isAvailable,
+			PreparationTime: preparationTime,
+			MenuTypes:       menuTypes,
+			DietaryTags:     dietaryTags,
+			Allergens:       allergens,
+			IsAlcoholic:     isAlcoholic,
+			DisplayOrder:    displayOrder,
+		})
+		return bulk.Updated, err
+	}
+
+	if price == nil {
+		return "", sharedErrors.Validation("invalid_value", "a new variant needs a price",
+			sharedErrors.FieldError{Field: "price", Code: "required", Message: "value is required"})
+	}
+	subCategoryID, _, err := h.find(ctx, catalogSQL.FindMenuSubCategoryQuery, queries.Args{"category": category, "name": subCategory}, new(string))
+	if err != nil {
+		return "", err
+	}
+
+	req := &menuVariantModels.MenuVariantCreateRequest{
+		Name:            name,
+		Description:     rec.Optional("description"),
+		SubCategoryID:   subCategoryID,
+		Price:           *price,
+		HappyHourPrice:  happyHourPrice,
+		ImageURL:        rec.Optional("image_url"),
+		IsAvailable:     isAvailable == nil || *isAvailable,
+		PreparationTime: preparationTime,
+		IsAlcoholic:     isAlcoholic != nil && *isAlcoholic,
+	}
+	if displayOrder != nil {
+		req.DisplayOrder = *displayOrder
+	}
+	if menuTypes != nil {
+		req.MenuTypes = *menuTypes
+	}
+	if dietaryTags != nil {
+		req.DietaryTags = *dietaryTags
+	}
+	if allergens != nil {
+		req.Allergens = *allergens
+	}
+
+	_, err = h.variants.Create(ctx, req)
+	return bulk.Created, err
+}
+
+// find returns the ID and version of the live row matching a natural key, an empty ID
+// when there is none. extra receives any column the query returns after them.
+func (h *DBHandler) find(ctx context.Context, query queries.Name, args queries.Args, extra *string) (string, int, error) {
+	var id string
+	var version int
+	dest := []interface{}{&id, &version}
+	if extra != nil {
+		dest = append(dest, extra)
+	}
+
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(query), args).Scan(dest...)
+	if err == sql.ErrNoRows {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to look up %s: %w", query, err)
+	}
+	return id, version, nil
+}
+
+// jsonList turns the items of a list cell into a JSON array, nil for an empty cell
+func jsonList(items []string) *json.RawMessage {
+	if items == nil {
+		return nil
+	}
+	data, _ := json.Marshal(items)
+	raw := json.RawMessage(data)
+	return &raw
+}
@@ -0,0 +1,28 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	ExportMenuTreeQuery      queries.Name = "export_menu_tree"
+	FindMenuCategoryQuery    queries.Name = "find_menu_category"
+	FindMenuSubCategoryQuery queries.Name = "find_menu_sub_category"
+	FindMenuVariantQuery     queries.Name = "find_menu_variant"
+)
+
+// LoadQueries loads and validates the menu import and export SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ExportMenuTreeQuery,
+		FindMenuCategoryQuery,
+		FindMenuSubCategoryQuery,
+		FindMenuVariantQuery,
+	)
+}
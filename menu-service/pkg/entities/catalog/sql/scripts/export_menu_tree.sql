@@ -0,0 +1,11 @@
+SELECT mc.name, msc.name, msc.item_type, mv.name, mv.description, mv.price, mv.happy_hour_price,
+       mv.is_available, mv.is_alcoholic, mv.preparation_time, mv.display_order,
+       array_to_string(ARRAY(SELECT jsonb_array_elements_text(mv.menu_types)), '|'),
+       array_to_string(ARRAY(SELECT jsonb_array_elements_text(COALESCE(mv.dietary_tags, '[]'))), '|'),
+       array_to_string(ARRAY(SELECT jsonb_array_elements_text(COALESCE(mv.allergens, '[]'))), '|'),
+       mv.image_url
+FROM menu_categories mc
+LEFT JOIN menu_sub_categories msc ON msc.category_id = mc.id AND msc.deleted_at IS NULL
+LEFT JOIN menu_variants mv ON mv.sub_category_id = msc.id AND mv.deleted_at IS NULL
+WHERE mc.deleted_at IS NULL
+ORDER BY mc.display_order, mc.name, msc.display_order, msc.name, mv.display_order, mv.name;
@@ -0,0 +1,5 @@
+SELECT msc.id, msc.version, msc.item_type
+FROM menu_sub_categories msc
+JOIN menu_categories mc ON msc.category_id = mc.id
+WHERE mc.name = @category AND msc.name = @name
+  AND msc.deleted_at IS NULL AND mc.deleted_at IS NULL;
@@ -0,0 +1,6 @@
+SELECT mv.id, mv.version
+FROM menu_variants mv
+JOIN menu_sub_categories msc ON mv.sub_category_id = msc.id
+JOIN menu_categories mc ON msc.category_id = mc.id
+WHERE mc.name = @category AND msc.name = @sub_category AND mv.name = @name
+  AND mv.deleted_at IS NULL AND msc.deleted_at IS NULL AND mc.deleted_at IS NULL;
@@ -0,0 +1,426 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"menu-service/pkg/entities/combos/models"
+	comboSQL "menu-service/pkg/entities/combos/sql"
+	"shared/audit"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// auditEntity names the entity in the audit log
+const auditEntity = "combo"
+
+// DBHandler handles database operations for combos and their slots
+type DBHandler struct {
+	db           *sharedDb.DbHandler
+	audit        *audit.Log
+	queries      *queries.Registry
+	costStrategy string // how choose-one slots are costed, see menu_costs
+	logger       *logrus.Logger
+}
+
+// NewDBHandler creates a new database handler
+func NewDBHandler(db *sharedDb.DbHandler, auditLog *audit.Log, costStrategy string, logger *logrus.Logger) (*DBHandler, error) {
+	queries, err := comboSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DBHandler{
+		db:           db,
+		audit:        auditLog,
+		queries:      queries,
+		costStrategy: costStrategy,
+		logger:       logger,
+	}, nil
+}
+
+// rowScanner is a single row or the current row of a result set
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCombo reads a combo without its slots, with deleted_at when withDeleted is set
+func scanCombo(row rowScanner, withDeleted bool) (*models.Combo, error) {
+	var combo models.Combo
+	var description, imageURL sql.NullString
+
+	dest := []interface{}{&combo.ID, &combo.Name, &description, &combo.Price, &imageURL, &combo.IsActive, &combo.DisplayOrder, &combo.CreatedAt, &combo.UpdatedAt, &combo.Version}
+	if withDeleted {
+		dest = append(dest, &combo.DeletedAt)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if description.Valid {
+		combo.Description = &description.String
+	}
+	if imageURL.Valid {
+		combo.ImageURL = &imageURL.String
+	}
+	combo.Slots = []models.ComboSlot{}
+	return &combo, nil
+}
+
+// scanCombos reads the combos of a result set and rolls them up from their slots
+func (h *DBHandler) scanCombos(ctx context.Context, rows *sql.Rows, withDeleted bool) ([]models.Combo, error) {
+	defer rows.Close()
+
+	var combos []models.Combo
+	for rows.Next() {
+		combo, err := scanCombo(rows, withDeleted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan combo: %w", err)
+		}
+		combos = append(combos, *combo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating combos: %w", err)
+	}
+	rows.Close()
+
+	if err := h.loadSlots(ctx, combos); err != nil {
+		return nil, err
+	}
+	return combos, nil
+}
+
+// loadSlots fills in the slots and options of the combos and rolls up their cost and
+// availability
+func (h *DBHandler) loadSlots(ctx context.Context, combos []models.Combo) error {
+	if len(combos) == 0 {
+		return nil
+	}
+	byID := make(map[string]*models.Combo, len(combos))
+	ids := make(pq.StringArray, 0, len(combos))
+	for i := range combos {
+		byID[combos[i].ID] = &combos[i]
+		ids = append(ids, combos[i].ID)
+	}
+
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(comboSQL.ListComboSlotsQuery), queries.Args{"combo_ids": ids})
+	if err != nil {
+		return fmt.Errorf("failed to list combo slots: %w", err)
+	}
+	defer rows.Close()
+	// slots are addressed by combo and index, the slices still grow while they are read
+	type slotRef struct {
+		combo *models.Combo
+		index int
+	}
+	slots := map[string]slotRef{}
+	for rows.Next() {
+		var slot models.ComboSlot
+		var comboID string
+		if err := rows.Scan(&slot.ID, &comboID, &slot.Name, &slot.Quantity, &slot.IsRequired, &slot.DisplayOrder); err != nil {
+			return fmt.Errorf("failed to scan combo slot: %w", err)
+		}
+		slot.Options = []models.ComboOption{}
+		combo := byID[comboID]
+		slots[slot.ID] = slotRef{combo: combo, index: len(combo.Slots)}
+		combo.Slots = append(combo.Slots, slot)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating combo slots: %w", err)
+	}
+	rows.Close()
+
+	options, err := h.db.QueryNamedContext(ctx, h.queries.Get(comboSQL.ListComboSlotOptionsQuery), queries.Args{"combo_ids": ids})
+	if err != nil {
+		return fmt.Errorf("failed to list combo slot options: %w", err)
+	}
+	defer options.Close()
+	for options.Next() {
+		var option models.ComboOption
+		var slotID string
+		if err := options.Scan(&option.ID, &slotID, &option.MenuVariantID, &option.MenuVariantName, &option.PriceDelta, &option.IsDefault,
+			&option.DisplayOrder, &option.Price, &option.ItemCost, &option.IsAvailable); err != nil {
+			return fmt.Errorf("failed to scan combo slot option: %w", err)
+		}
+		ref := slots[slotID]
+		slot := &ref.combo.Slots[ref.index]
+		slot.Options = append(slot.Options, option)
+	}
+	if err := options.Err(); err != nil {
+		return fmt.Errorf("error iterating combo slot options: %w", err)
+	}
+
+	for i := range combos {
+		combos[i].Rollup(h.costStrategy)
+	}
+	return nil
+}
+
+// List returns a page of combos matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.ComboListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(comboSQL.ListCombosQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build combos count: %w", err)
+	}
+
+	var total int
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count combos: %w", err)
+	}
+
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(comboSQL.ListCombosQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build combos list: %w", err)
+	}
+
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list combos: %w", err)
+	}
+	combos, err := h.scanCombos(ctx, rows, true)
+	if err != nil {
+		return nil, err
+	}
+
+	combos, pageInfo, err := queryspec.Paginate(spec, combos)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ComboListResponse{
+		Combos:   combos,
+		Total:    total,
+		Limit:    spec.Limit,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// GetByID returns a combo by ID with its slots, cost and availability
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.Combo, error) {
+	combo, err := scanCombo(h.db.QueryRowNamedContext(ctx, h.queries.Get(comboSQL.GetComboByIDQuery), queries.Args{"id": id}), false)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get combo: %w", err)
+	}
+
+	combos := []models.Combo{*combo}
+	if err := h.loadSlots(ctx, combos); err != nil {
+		return nil, err
+	}
+	return &combos[0], nil
+}
+
+// Create creates a new combo with its slots
+func (h *DBHandler) Create(ctx context.Context, req *models.ComboCreateRequest) (*models.Combo, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.Combo, error) {
+		return h.create(ctx, req)
+	})
+}
+
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.ComboCreateRequest) (*models.Combo, error) {
+	var combo *models.Combo
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		var id string
+		err := h.db.QueryRowNamedContext(ctx, h.queries.Get(comboSQL.CreateComboQuery), queries.Args{
+			"name":          req.Name,
+			"description":   req.Description,
+			"price":         req.Price,
+			"image_url":     req.ImageURL,
+			"is_active":     req.IsActive,
+			"display_order": req.DisplayOrder,
+		}).Scan(&id)
+		if err != nil {
+			return h.writeError("create", err)
+		}
+
+		if err := h.writeSlots(ctx, id, req.Slots); err != nil {
+			return err
+		}
+
+		combo, err = h.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.WithField("id", combo.ID).Info("Combo created")
+	return combo, nil
+}
+
+// Update updates an existing combo if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.ComboUpdateRequest) (*models.Combo, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.Combo, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
+
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.ComboUpdateRequest) (*models.Combo, error) {
+	var combo *models.Combo
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		var updatedID string
+		err := h.db.QueryRowNamedContext(ctx, h.queries.Get(comboSQL.UpdateComboQuery), queries.Args{
+			"id":            id,
+			"version":       version,
+			"name":          req.Name,
+			"description":   req.Description,
+			"price":         req.Price,
+			"image_url":     req.ImageURL,
+			"is_active":     req.IsActive,
+			"display_order": req.DisplayOrder,
+		}).Scan(&updatedID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return h.versionMismatch(ctx, id)
+			}
+			return h.writeError("update", err)
+		}
+
+		if req.Slots != nil {
+			if err := h.writeSlots(ctx, id, *req.Slots); err != nil {
+				return err
+			}
+		}
+
+		combo, err = h.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if combo == nil {
+		return nil, nil
+	}
+
+	h.logger.WithField("id", combo.ID).Info("Combo updated")
+	return combo, nil
+}
+
+// writeSlots replaces the slots of a combo and their options
+func (h *DBHandler) writeSlots(ctx context.Context, comboID string, slots []models.ComboSlotRequest) error {
+	if _, err := h.db.ExecNamedContext(ctx, h.queries.Get(comboSQL.DeleteComboSlotsQuery), queries.Args{"combo_id": comboID}); err != nil {
+		return fmt.Errorf("failed to remove combo slots: %w", err)
+	}
+
+	for i, slot := range slots {
+		var slotID string
+		err := h.db.QueryRowNamedContext(ctx, h.queries.Get(comboSQL.CreateComboSlotQuery), queries.Args{
+			"combo_id":      comboID,
+			"name":          slot.Name,
+			"quantity":      slot.Quantity,
+			"is_required":   *slot.IsRequired,
+			"display_order": slot.DisplayOrder,
+		}).Scan(&slotID)
+		if err != nil {
+			return fmt.Errorf("failed to create combo slot: %w", err)
+		}
+
+		for j, option := range slot.Options {
+			if _, err := h.db.ExecNamedContext(ctx, h.queries.Get(comboSQL.CreateComboSlotOptionQuery), queries.Args{
+				"combo_slot_id":   slotID,
+				"menu_variant_id": option.MenuVariantID,
+				"price_delta":     option.PriceDelta,
+				"is_default":      option.IsDefault,
+				"display_order":   option.DisplayOrder,
+			}); err != nil {
+				return h.optionError(i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// optionError reports a menu variant that doesn't exist against the option at index j of
+// slot i
+func (h *DBHandler) optionError(i, j int, err error) error {
+	if dbErr, _ := sharedErrors.As(err); dbErr != nil && dbErr.Code == "foreign_key_violation" {
+		return sharedErrors.Validation("invalid_combo", "invalid combo", sharedErrors.FieldError{
+			Field: fmt.Sprintf("slots[%d].options[%d]", i, j), Code: "unknown_menu_variant", Message: "menu variant not found",
+		}).Wrap(err)
+	}
+	return fmt.Errorf("failed to create combo slot option: %w", err)
+}
+
+// writeError reports a name another live combo already has as a conflict
+func (h *DBHandler) writeError(action string, err error) error {
+	if dbErr, _ := sharedErrors.As(err); dbErr != nil && dbErr.Code == "unique_violation" {
+		return sharedErrors.Conflict("combo_name_taken", "another combo already has this name").Wrap(err)
+	}
+	return fmt.Errorf("failed to %s combo: %w", action, err)
+}
+
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return err
+	}
+	return sharedErrors.PreconditionFailed("combo_version_mismatch", "combo was modified by another request")
+}
+
+// Delete deletes a combo
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
+
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(comboSQL.DeleteComboQuery), queries.Args{"id": id})
+	if err != nil {
+		return fmt.Errorf("failed to delete combo: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sharedErrors.NotFound("combo_not_found", "combo not found")
+	}
+
+	h.logger.WithField("id", id).Info("Combo deleted")
+	return nil
+}
+
+// Restore brings back a soft deleted combo
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.Combo, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.Combo, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.Combo, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(comboSQL.RestoreComboQuery), queries.Args{"id": id})
+	if err != nil {
+		return nil, h.writeError("restore", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("combo_not_found", "deleted combo not found")
+	}
+
+	h.logger.WithField("id", id).Info("Combo restored")
+	return h.GetByID(ctx, id)
+}
+
+// Purge hard deletes the combos soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(comboSQL.PurgeCombosQuery), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge combos: %w", err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"menu-service/pkg/entities/combos/models"
+	comboSQL "menu-service/pkg/entities/combos/sql"
+	sharedHttp "shared/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPHandler handles HTTP requests for combos
+type HTTPHandler struct {
+	dbHandler *DBHandler
+	logger    *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{dbHandler: dbHandler, logger: logger}
+}
+
+// List handles GET /api/v1/menu/combos
+func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
+	spec, err := comboSQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
+	}
+
+	response, err := h.dbHandler.List(r.Context(), spec)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list combos")
+		sharedHttp.SendError(w, r, err, "Failed to list combos")
+		return
+	}
+	response.SetLinks(r.URL)
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Combos retrieved", response)
+}
+
+// GetByID handles GET /api/v1/menu/combos/:id
+func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	combo, err := h.dbHandler.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get combo")
+		sharedHttp.SendError(w, r, err, "Failed to get combo")
+		return
+	}
+
+	if combo == nil {
+		sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Combo not found")
+		return
+	}
+
+	sharedHttp.SetETag(w, combo.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Combo retrieved", combo)
+}
+
+// Create handles POST /api/v1/menu/combos
+func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.ComboCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid combo")
+		return
+	}
+
+	combo, err := h.dbHandler.Create(r.Context(), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create combo")
+		sharedHttp.SendError(w, r, err, "Failed to create combo")
+		return
+	}
+
+	sharedHttp.SetETag(w, combo.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Combo created", combo)
+}
+
+// Update handles PUT /api/v1/menu/combos/:id
+func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update combo")
+		return
+	}
+
+	var req models.ComboUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid combo")
+		return
+	}
+
+	combo, err := h.dbHandler.Update(r.Context(), id, version, &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to update combo")
+		sharedHttp.SendError(w, r, err, "Failed to update combo")
+		return
+	}
+
+	if combo == nil {
+		sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Combo not found")
+		return
+	}
+
+	sharedHttp.SetETag(w, combo.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Combo updated", combo)
+}
+
+// Delete handles DELETE /api/v1/menu/combos/:id
+func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.dbHandler.Delete(r.Context(), id); err != nil {
+		h.logger.WithError(err).Error("Failed to delete combo")
+		sharedHttp.SendError(w, r, err, "Failed to delete combo")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Combo deleted", nil)
+}
+
+// Restore handles POST /api/v1/menu/combos/:id/restore
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	combo, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore combo")
+		sharedHttp.SendError(w, r, err, "Failed to restore combo")
+		return
+	}
+
+	sharedHttp.SetETag(w, combo.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Combo restored", combo)
+}
@@ -0,0 +1,310 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	menuCostModels "menu-service/pkg/entities/menu_costs/models"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"shared/money"
+)
+
+// Slot kinds
+const (
+	SlotFixed     = "fixed"      // the slot has one option
+	SlotChooseOne = "choose_one" // the guest picks one of the slot's options
+)
+
+// Reasons a combo is unavailable
+const (
+	UnavailableInactive  = "inactive"              // the combo is switched off
+	UnavailableComponent = "component_unavailable" // a required slot has no available option
+)
+
+// ComboOption is a menu variant that can fill a combo slot. Its name, price, cost and
+// availability are read from the variant.
+type ComboOption struct {
+	ID              string       `json:"id"`
+	MenuVariantID   string       `json:"menu_variant_id"`
+	MenuVariantName string       `json:"menu_variant_name"`
+	PriceDelta      money.Money  `json:"price_delta"` // charged on top of the combo price
+	IsDefault       bool         `json:"is_default"`
+	DisplayOrder    int          `json:"display_order"`
+	Price           money.Money  `json:"price"`
+	ItemCost        *money.Money `json:"item_cost"`
+	IsAvailable     bool         `json:"is_available"` // available and not deleted
+}
+
+// ComboSlot is a part of a combo filled by one of its options, Quantity times
+type ComboSlot struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Kind         string        `json:"kind"`
+	Quantity     int           `json:"quantity"`
+	IsRequired   bool          `json:"is_required"`
+	DisplayOrder int           `json:"display_order"`
+	Options      []ComboOption `json:"options"`
+	// IsAvailable tells whether one of the options is available, ItemCost is the cost of
+	// Quantity of the option costed by the recipe cost strategy, nil when unknown
+	IsAvailable bool         `json:"is_available"`
+	ItemCost    *money.Money `json:"item_cost"`
+}
+
+// Combo is a bundle of menu variants sold at one price. ItemCost, ComponentsPrice,
+// Savings and availability roll up from the components when the combo is read.
+type Combo struct {
+	ID           string      `json:"id"`
+	Name         string      `json:"name"`
+	Description  *string     `json:"description,omitempty"`
+	Price        money.Money `json:"price"`
+	ImageURL     *string     `json:"image_url,omitempty"`
+	IsActive     bool        `json:"is_active"`
+	DisplayOrder int         `json:"display_order"`
+	Slots        []ComboSlot `json:"slots"`
+	// ItemCost is the cost of the required slots, nil when a component's cost is unknown
+	ItemCost *money.Money `json:"item_cost"`
+	// ComponentsPrice is what the required slots cost ordered apart, each at its default
+	// option or else its cheapest, and Savings what the combo price takes off it
+	ComponentsPrice   money.Money `json:"components_price"`
+	Savings           money.Money `json:"savings"`
+	IsAvailable       bool        `json:"is_available"`
+	UnavailableReason string      `json:"unavailable_reason,omitempty"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
+	Version           int         `json:"version"`
+	DeletedAt         *time.Time  `json:"deleted_at,omitempty"`
+}
+
+// Rollup sets the slot kinds and the combo's cost, components price and availability from
+// its components. A choose-one slot is costed at the average of its options, or the most
+// expensive with menuCostModels.StrategyMax, like a sub-category ingredient. Optional slots
+// are extras and count toward neither the cost nor the availability.
+func (c *Combo) Rollup(strategy string) {
+	cost := money.Money{}
+	costKnown := true
+	c.ComponentsPrice = money.Money{}
+	c.IsAvailable, c.UnavailableReason = true, ""
+
+	for i := range c.Slots {
+		slot := &c.Slots[i]
+		slot.rollup(strategy)
+		if !slot.IsRequired {
+			continue
+		}
+
+		if slot.ItemCost == nil {
+			costKnown = false
+		} else {
+			cost = cost.Add(*slot.ItemCost)
+		}
+		if price, ok := slot.listPrice(); ok {
+			c.ComponentsPrice = c.ComponentsPrice.Add(price)
+		}
+		if !slot.IsAvailable && c.IsAvailable {
+			c.IsAvailable, c.UnavailableReason = false, UnavailableComponent
+		}
+	}
+	if !c.IsActive {
+		c.IsAvailable, c.UnavailableReason = false, UnavailableInactive
+	}
+
+	c.ItemCost = nil
+	if costKnown {
+		c.ItemCost = &cost
+	}
+	c.Savings = c.ComponentsPrice.Sub(c.Price)
+}
+
+// rollup sets the slot's kind, availability and cost
+func (s *ComboSlot) rollup(strategy string) {
+	s.Kind = SlotChooseOne
+	if len(s.Options) == 1 {
+		s.Kind = SlotFixed
+	}
+
+	s.IsAvailable = false
+	for _, option := range s.Options {
+		s.IsAvailable = s.IsAvailable || option.IsAvailable
+	}
+
+	s.ItemCost = nil
+	if len(s.Options) == 0 {
+		return
+	}
+	var total, highest money.Money
+	for i, option := range s.Options {
+		if option.ItemCost == nil {
+			return
+		}
+		total = total.Add(*option.ItemCost)
+		if i == 0 || option.ItemCost.Cmp(highest) > 0 {
+			highest = *option.ItemCost
+		}
+	}
+	unit := highest
+	if strategy != menuCostModels.StrategyMax {
+		unit, _ = total.Div(money.DecimalFromInt(int64(len(s.Options))))
+	}
+	cost := unit.Mul(money.DecimalFromInt(int64(s.Quantity)))
+	s.ItemCost = &cost
+}
+
+// listPrice is what Quantity of the slot's default option, or else its cheapest, costs
+// ordered apart
+func (s *ComboSlot) listPrice() (money.Money, bool) {
+	var chosen *ComboOption
+	for i := range s.Options {
+		option := &s.Options[i]
+		if option.IsDefault {
+			chosen = option
+			break
+		}
+		if chosen == nil || option.Price.Cmp(chosen.Price) < 0 {
+			chosen = option
+		}
+	}
+	if chosen == nil {
+		return money.Money{}, false
+	}
+	return chosen.Price.Mul(money.DecimalFromInt(int64(s.Quantity))), true
+}
+
+// ComboOptionRequest is an option of a slot in a create or update request
+type ComboOptionRequest struct {
+	MenuVariantID string      `json:"menu_variant_id"`
+	PriceDelta    money.Money `json:"price_delta"`
+	IsDefault     bool        `json:"is_default,omitempty"`
+	DisplayOrder  int         `json:"display_order,omitempty"`
+}
+
+// ComboSlotRequest is a slot in a create or update request
+type ComboSlotRequest struct {
+	Name         string               `json:"name"`
+	Quantity     int                  `json:"quantity,omitempty"` // 1 when unset
+	IsRequired   *bool                `json:"is_required,omitempty"`
+	DisplayOrder int                  `json:"display_order,omitempty"`
+	Options      []ComboOptionRequest `json:"options"`
+}
+
+// ComboCreateRequest represents a request to create a combo
+type ComboCreateRequest struct {
+	Name         string             `json:"name"`
+	Description  *string            `json:"description,omitempty"`
+	Price        money.Money        `json:"price"`
+	ImageURL     *string            `json:"image_url,omitempty"`
+	IsActive     *bool              `json:"is_active,omitempty"`
+	DisplayOrder int                `json:"display_order,omitempty"`
+	Slots        []ComboSlotRequest `json:"slots"`
+}
+
+// Validate checks the request, defaulting slot quantities and required flags
+func (r *ComboCreateRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	var fieldErrors []sharedErrors.FieldError
+	if r.Name == "" {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "name", Code: "required", Message: "name is required"})
+	}
+	if r.Price.Sign() < 0 {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "price", Code: "invalid_price", Message: "price can't be negative"})
+	}
+	fieldErrors = append(fieldErrors, validateSlots(r.Slots)...)
+
+	if len(fieldErrors) > 0 {
+		return sharedErrors.Validation("invalid_combo", "invalid combo", fieldErrors...)
+	}
+	return nil
+}
+
+// ComboUpdateRequest represents a request to update a combo, Slots replaces every slot
+// when set
+type ComboUpdateRequest struct {
+	Name         *string             `json:"name,omitempty"`
+	Description  *string             `json:"description,omitempty"`
+	Price        *money.Money        `json:"price,omitempty"`
+	ImageURL     *string             `json:"image_url,omitempty"`
+	IsActive     *bool               `json:"is_active,omitempty"`
+	DisplayOrder *int                `json:"display_order,omitempty"`
+	Slots        *[]ComboSlotRequest `json:"slots,omitempty"`
+}
+
+// Validate checks the fields being changed
+func (r *ComboUpdateRequest) Validate() error {
+	var fieldErrors []sharedErrors.FieldError
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		r.Name = &name
+		if name == "" {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "name", Code: "required", Message: "name can't be empty"})
+		}
+	}
+	if r.Price != nil && r.Price.Sign() < 0 {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "price", Code: "invalid_price", Message: "price can't be negative"})
+	}
+	if r.Slots != nil {
+		fieldErrors = append(fieldErrors, validateSlots(*r.Slots)...)
+	}
+
+	if len(fieldErrors) > 0 {
+		return sharedErrors.Validation("invalid_combo", "invalid combo", fieldErrors...)
+	}
+	return nil
+}
+
+// validateSlots checks a combo has a required slot and every slot has options, at most
+// one of them default and each variant once
+func validateSlots(slots []ComboSlotRequest) []sharedErrors.FieldError {
+	var fieldErrors []sharedErrors.FieldError
+	required := false
+	for i := range slots {
+		slot := &slots[i]
+		field := fmt.Sprintf("slots[%d]", i)
+		slot.Name = strings.TrimSpace(slot.Name)
+		if slot.Quantity == 0 {
+			slot.Quantity = 1
+		}
+		if slot.IsRequired == nil {
+			isRequired := true
+			slot.IsRequired = &isRequired
+		}
+		required = required || *slot.IsRequired
+
+		if slot.Name == "" {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "required", Message: "name is required"})
+		}
+		if slot.Quantity < 0 {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "invalid_quantity", Message: "quantity must be positive"})
+		}
+		if len(slot.Options) == 0 {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "no_options", Message: "a slot needs at least one menu variant"})
+		}
+
+		defaults := 0
+		variants := map[string]bool{}
+		for j, option := range slot.Options {
+			if option.IsDefault {
+				defaults++
+			}
+			if option.MenuVariantID == "" || variants[option.MenuVariantID] {
+				fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: fmt.Sprintf("%s.options[%d]", field, j), Code: "invalid_option", Message: "each option needs a different menu_variant_id"})
+			}
+			variants[option.MenuVariantID] = true
+		}
+		if defaults > 1 {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "too_many_defaults", Message: "a slot has at most one default option"})
+		}
+	}
+	if !required {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "slots", Code: "required", Message: "a combo needs at least one required slot"})
+	}
+	return fieldErrors
+}
+
+// ComboListResponse represents a paginated list of combos
+type ComboListResponse struct {
+	Combos []Combo `json:"combos"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	queryspec.PageInfo
+}
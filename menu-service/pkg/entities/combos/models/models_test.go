@@ -0,0 +1,112 @@
+package models
+
+import (
+	"testing"
+
+	menuCostModels "menu-service/pkg/entities/menu_costs/models"
+	"shared/money"
+)
+
+func costed(id, price, cost string, available bool) ComboOption {
+	option := ComboOption{MenuVariantID: id, Price: money.MustParse(price), IsAvailable: available}
+	if cost != "" {
+		itemCost := money.MustParse(cost)
+		option.ItemCost = &itemCost
+	}
+	return option
+}
+
+func burgerCombo() Combo {
+	return Combo{
+		Name:     "Burger combo",
+		Price:    money.MustParse("5000"),
+		IsActive: true,
+		Slots: []ComboSlot{
+			{Name: "Main", Quantity: 1, IsRequired: true, Options: []ComboOption{costed("burger", "3500", "1500", true)}},
+			{Name: "Side", Quantity: 1, IsRequired: true, Options: []ComboOption{costed("fries", "1500", "400", true)}},
+			{Name: "Drink", Quantity: 1, IsRequired: true, Options: []ComboOption{
+				costed("cola", "1000", "200", true), costed("juice", "1200", "400", true),
+			}},
+			{Name: "Dessert", Quantity: 1, IsRequired: false, Options: []ComboOption{costed("flan", "1500", "", false)}},
+		},
+	}
+}
+
+func TestRollupCost(t *testing.T) {
+	combo := burgerCombo()
+	combo.Rollup(menuCostModels.StrategyAverage)
+
+	// 1500 + 400 + the average drink 300; the optional dessert is left out
+	if combo.ItemCost == nil || !combo.ItemCost.Equal(money.MustParse("2200")) {
+		t.Errorf("item cost = %v, want 2200", combo.ItemCost)
+	}
+	if combo.Slots[0].Kind != SlotFixed || combo.Slots[2].Kind != SlotChooseOne {
+		t.Errorf("kinds = %s, %s, want fixed and choose_one", combo.Slots[0].Kind, combo.Slots[2].Kind)
+	}
+	// 3500 + 1500 + the cheapest drink 1000
+	if !combo.ComponentsPrice.Equal(money.MustParse("6000")) || !combo.Savings.Equal(money.MustParse("1000")) {
+		t.Errorf("components price = %s, savings = %s, want 6000 and 1000", combo.ComponentsPrice, combo.Savings)
+	}
+	if !combo.IsAvailable {
+		t.Errorf("unavailable (%s), want available: only the optional dessert is out", combo.UnavailableReason)
+	}
+
+	combo.Rollup(menuCostModels.StrategyMax)
+	if combo.ItemCost == nil || !combo.ItemCost.Equal(money.MustParse("2300")) {
+		t.Errorf("item cost at max = %v, want 2300", combo.ItemCost)
+	}
+
+	combo.Slots[1].Options[0].ItemCost = nil
+	combo.Rollup(menuCostModels.StrategyAverage)
+	if combo.ItemCost != nil {
+		t.Errorf("item cost = %v with fries of unknown cost, want unknown", combo.ItemCost)
+	}
+}
+
+func TestRollupAvailability(t *testing.T) {
+	combo := burgerCombo()
+	combo.Slots[2].Options[0].IsAvailable = false
+	combo.Rollup(menuCostModels.StrategyAverage)
+	if !combo.IsAvailable {
+		t.Error("unavailable with juice still available for the drink")
+	}
+
+	combo.Slots[0].Options[0].IsAvailable = false
+	combo.Rollup(menuCostModels.StrategyAverage)
+	if combo.IsAvailable || combo.UnavailableReason != UnavailableComponent {
+		t.Errorf("available = %v (%s) without the burger, want component_unavailable", combo.IsAvailable, combo.UnavailableReason)
+	}
+
+	combo = burgerCombo()
+	combo.IsActive = false
+	combo.Rollup(menuCostModels.StrategyAverage)
+	if combo.IsAvailable || combo.UnavailableReason != UnavailableInactive {
+		t.Errorf("available = %v (%s) when inactive, want inactive", combo.IsAvailable, combo.UnavailableReason)
+	}
+}
+
+func TestCreateRequestValidate(t *testing.T) {
+	valid := ComboCreateRequest{Name: "Burger combo", Price: money.MustParse("5000"), Slots: []ComboSlotRequest{
+		{Name: "Main", Options: []ComboOptionRequest{{MenuVariantID: "burger"}}},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if valid.Slots[0].Quantity != 1 || !*valid.Slots[0].IsRequired {
+		t.Errorf("slot = %+v, want one required", valid.Slots[0])
+	}
+
+	optional := false
+	for name, bad := range map[string]ComboCreateRequest{
+		"no slots":        {Name: "Combo"},
+		"only optional":   {Name: "Combo", Slots: []ComboSlotRequest{{Name: "Extra", IsRequired: &optional, Options: []ComboOptionRequest{{MenuVariantID: "a"}}}}},
+		"no options":      {Name: "Combo", Slots: []ComboSlotRequest{{Name: "Main"}}},
+		"repeated option": {Name: "Combo", Slots: []ComboSlotRequest{{Name: "Main", Options: []ComboOptionRequest{{MenuVariantID: "a"}, {MenuVariantID: "a"}}}}},
+		"two defaults":    {Name: "Combo", Slots: []ComboSlotRequest{{Name: "Main", Options: []ComboOptionRequest{{MenuVariantID: "a", IsDefault: true}, {MenuVariantID: "b", IsDefault: true}}}}},
+		"negative price":  {Name: "Combo", Price: money.MustParse("-1"), Slots: valid.Slots},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%s) succeeded, want an error", name)
+		}
+	}
+}
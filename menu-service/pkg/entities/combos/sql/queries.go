@@ -0,0 +1,55 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	ListCombosQuery            queries.Name = "list_combos"
+	GetComboByIDQuery          queries.Name = "get_combo_by_id"
+	CreateComboQuery           queries.Name = "create_combo"
+	UpdateComboQuery           queries.Name = "update_combo"
+	DeleteComboQuery           queries.Name = "delete_combo"
+	RestoreComboQuery          queries.Name = "restore_combo"
+	PurgeCombosQuery           queries.Name = "purge_combos"
+	ListComboSlotsQuery        queries.Name = "list_combo_slots"
+	ListComboSlotOptionsQuery  queries.Name = "list_combo_slot_options"
+	DeleteComboSlotsQuery      queries.Name = "delete_combo_slots"
+	CreateComboSlotQuery       queries.Name = "create_combo_slot"
+	CreateComboSlotOptionQuery queries.Name = "create_combo_slot_option"
+)
+
+// ListSchema whitelists the columns of list_combos that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("display_order,name",
+	queryspec.Field{Name: "name", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "price", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "is_active", Type: queryspec.Bool, Filterable: true},
+	queryspec.Field{Name: "display_order", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).WithSoftDelete()
+
+// LoadQueries loads and validates the combo SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListCombosQuery,
+		GetComboByIDQuery,
+		CreateComboQuery,
+		UpdateComboQuery,
+		DeleteComboQuery,
+		RestoreComboQuery,
+		PurgeCombosQuery,
+		ListComboSlotsQuery,
+		ListComboSlotOptionsQuery,
+		DeleteComboSlotsQuery,
+		CreateComboSlotQuery,
+		CreateComboSlotOptionQuery,
+	)
+}
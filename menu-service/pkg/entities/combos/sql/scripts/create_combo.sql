@@ -0,0 +1,3 @@
+INSERT INTO combos (name, description, price, image_url, is_active, display_order)
+VALUES (@name, @description, @price, @image_url, COALESCE(@is_active, true), @display_order)
+RETURNING id;
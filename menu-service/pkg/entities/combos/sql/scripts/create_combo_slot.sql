@@ -0,0 +1,3 @@
+INSERT INTO combo_slots (combo_id, name, quantity, is_required, display_order)
+VALUES (@combo_id, @name, @quantity, @is_required, @display_order)
+RETURNING id;
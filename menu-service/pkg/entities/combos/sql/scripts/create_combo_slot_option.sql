@@ -0,0 +1,2 @@
+INSERT INTO combo_slot_options (combo_slot_id, menu_variant_id, price_delta, is_default, display_order)
+VALUES (@combo_slot_id, @menu_variant_id, @price_delta, @is_default, @display_order);
@@ -0,0 +1 @@
+UPDATE combos SET deleted_at = CURRENT_TIMESTAMP WHERE id = @id AND deleted_at IS NULL;
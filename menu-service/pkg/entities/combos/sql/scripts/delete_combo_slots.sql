@@ -0,0 +1,2 @@
+-- Clears the slots of a combo before they are replaced, their options go with them
+DELETE FROM combo_slots WHERE combo_id = @combo_id;
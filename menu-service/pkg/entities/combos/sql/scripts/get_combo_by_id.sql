@@ -0,0 +1,3 @@
+SELECT id, name, description, price, image_url, is_active, display_order, created_at, updated_at, version
+FROM combos
+WHERE id = @id AND deleted_at IS NULL;
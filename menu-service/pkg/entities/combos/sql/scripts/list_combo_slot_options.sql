@@ -0,0 +1,9 @@
+-- The options of the slots with what the component variants sell and cost at now; a
+-- deleted variant is unavailable
+SELECT cso.id, cso.combo_slot_id, cso.menu_variant_id, mv.name, cso.price_delta, cso.is_default,
+       cso.display_order, mv.price, mv.item_cost, mv.is_available AND mv.deleted_at IS NULL
+FROM combo_slot_options cso
+JOIN combo_slots cs ON cs.id = cso.combo_slot_id
+JOIN menu_variants mv ON mv.id = cso.menu_variant_id
+WHERE cs.combo_id = ANY(@combo_ids::uuid[])
+ORDER BY cso.display_order, mv.name;
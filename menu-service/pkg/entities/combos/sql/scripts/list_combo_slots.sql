@@ -0,0 +1,4 @@
+SELECT id, combo_id, name, quantity, is_required, display_order
+FROM combo_slots
+WHERE combo_id = ANY(@combo_ids::uuid[])
+ORDER BY display_order, name;
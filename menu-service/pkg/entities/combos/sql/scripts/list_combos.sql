@@ -0,0 +1,2 @@
+SELECT id, name, description, price, image_url, is_active, display_order, created_at, updated_at, version, deleted_at
+FROM combos;
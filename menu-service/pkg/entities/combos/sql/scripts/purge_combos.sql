@@ -0,0 +1,7 @@
+-- Hard delete combos soft deleted before the retention window, their slots go with them
+WITH purged AS (
+    DELETE FROM combos
+    WHERE deleted_at < @deleted_before
+    RETURNING id
+)
+SELECT COUNT(*) FROM purged;
@@ -0,0 +1,10 @@
+UPDATE combos
+SET name = COALESCE(@name, name),
+    description = COALESCE(@description, description),
+    price = COALESCE(@price, price),
+    image_url = COALESCE(@image_url, image_url),
+    is_active = COALESCE(@is_active, is_active),
+    display_order = COALESCE(@display_order, display_order),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id;
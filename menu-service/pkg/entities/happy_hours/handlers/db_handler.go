@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"menu-service/pkg/entities/happy_hours/models"
+	happyHourSQL "menu-service/pkg/entities/happy_hours/sql"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/schedule"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DBHandler reads the happy hour promotions into the venue's schedule
+type DBHandler struct {
+	db       *sharedDb.DbHandler
+	location *time.Location
+	queries  *queries.Registry
+	logger   *logrus.Logger
+}
+
+// NewDBHandler creates a new happy hour database handler, location is the venue's
+// timezone the promotion times are read in
+func NewDBHandler(db *sharedDb.DbHandler, location *time.Location, logger *logrus.Logger) (*DBHandler, error) {
+	queries, err := happyHourSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DBHandler{
+		db:       db,
+		location: location,
+		queries:  queries,
+		logger:   logger,
+	}, nil
+}
+
+// Location returns the venue's timezone
+func (h *DBHandler) Location() *time.Location {
+	return h.location
+}
+
+// Schedule returns the active happy hour promotions. A promotion whose days or times
+// can't be read is left out with a warning rather than failing every price lookup.
+func (h *DBHandler) Schedule(ctx context.Context) (*models.Schedule, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(happyHourSQL.ListHappyHourPromotionsQuery), queries.Args{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list happy hour promotions: %w", err)
+	}
+	defer rows.Close()
+
+	schedule := &models.Schedule{Location: h.location}
+	for rows.Next() {
+		var id, name, startDate, fromTime, toTime string
+		var endDate sql.NullString
+		var days []byte
+		if err := rows.Scan(&id, &name, &startDate, &endDate, &fromTime, &toTime, &days); err != nil {
+			return nil, fmt.Errorf("failed to scan happy hour promotion: %w", err)
+		}
+
+		happyHour, err := parseHappyHour(id, name, startDate, endDate, fromTime, toTime, days)
+		if err != nil {
+			h.logger.WithError(err).WithField("promotion_id", id).Warn("Skipping unreadable happy hour promotion")
+			continue
+		}
+		schedule.HappyHours = append(schedule.HappyHours, *happyHour)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating happy hour promotions: %w", err)
+	}
+	return schedule, nil
+}
+
+func parseHappyHour(id, name, startDate string, endDate sql.NullString, fromTime, toTime string, days []byte) (*models.HappyHour, error) {
+	happyHour := &models.HappyHour{PromotionID: id, PromotionName: name}
+
+	var err error
+	if happyHour.StartDate, err = time.Parse(time.DateOnly, startDate); err != nil {
+		return nil, fmt.Errorf("invalid start date %q", startDate)
+	}
+	if endDate.Valid {
+		end, err := time.Parse(time.DateOnly, endDate.String)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end date %q", endDate.String)
+		}
+		happyHour.EndDate = &end
+	}
+	if happyHour.From, err = schedule.ParseClock(fromTime); err != nil {
+		return nil, err
+	}
+	if happyHour.To, err = schedule.ParseClock(toTime); err != nil {
+		return nil, err
+	}
+	if happyHour.Days, err = schedule.ParseDays(days); err != nil {
+		return nil, fmt.Errorf("invalid days_of_week: %w", err)
+	}
+	return happyHour, nil
+}
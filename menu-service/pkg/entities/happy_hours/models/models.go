@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"shared/schedule"
+)
+
+// HappyHour is the daily window of a happy hour promotion, on the venue's wall clock,
+// between its first and last day
+type HappyHour struct {
+	PromotionID   string
+	PromotionName string
+	StartDate     time.Time  // first day, midnight UTC
+	EndDate       *time.Time // last day, nil when open ended
+	schedule.Window
+}
+
+// ActiveHappyHour is a happy hour running at some instant
+type ActiveHappyHour struct {
+	PromotionID   string    `json:"promotion_id"`
+	PromotionName string    `json:"promotion_name"`
+	StartsAt      time.Time `json:"starts_at"`
+	EndsAt        time.Time `json:"ends_at"`
+}
+
+// runsOn reports whether the local day day is within the promotion's dates
+func (h *HappyHour) runsOn(day time.Time) bool {
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	return !date.Before(h.StartDate) && (h.EndDate == nil || !date.After(*h.EndDate))
+}
+
+// ActiveAt returns the window running at the instant at in loc, nil when none is. The
+// window may have started the day before.
+func (h *HappyHour) ActiveAt(at time.Time, loc *time.Location) *ActiveHappyHour {
+	start, end, ok := h.Window.ActiveAt(at, loc, h.runsOn)
+	if !ok {
+		return nil
+	}
+	return &ActiveHappyHour{
+		PromotionID:   h.PromotionID,
+		PromotionName: h.PromotionName,
+		StartsAt:      start,
+		EndsAt:        end,
+	}
+}
+
+// Schedule is the set of happy hours of the venue, read in its timezone
+type Schedule struct {
+	HappyHours []HappyHour
+	Location   *time.Location
+}
+
+// ActiveAt returns the first happy hour running at the instant at, nil when none is
+func (s *Schedule) ActiveAt(at time.Time) *ActiveHappyHour {
+	for i := range s.HappyHours {
+		if active := s.HappyHours[i].ActiveAt(at, s.Location); active != nil {
+			return active
+		}
+	}
+	return nil
+}
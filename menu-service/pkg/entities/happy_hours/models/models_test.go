@@ -0,0 +1,97 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"shared/schedule"
+)
+
+// costaRica is UTC-6 all year, like the venue's default timezone
+var costaRica = time.FixedZone("CST", -6*60*60)
+
+func happyHour(from, to string, days ...time.Weekday) HappyHour {
+	h := HappyHour{PromotionID: "promo", PromotionName: "Happy Hour", StartDate: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	h.Days = map[time.Weekday]bool{}
+	h.From, _ = schedule.ParseClock(from)
+	h.To, _ = schedule.ParseClock(to)
+	for _, day := range days {
+		h.Days[day] = true
+	}
+	return h
+}
+
+func local(value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", value, costaRica)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestActiveAt(t *testing.T) {
+	// 2026-03-06 is a Friday
+	weekdays := happyHour("17:00:00", "19:00:00", time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	schedule := &Schedule{HappyHours: []HappyHour{weekdays}, Location: costaRica}
+
+	cases := []struct {
+		at   string
+		want bool
+	}{
+		{"2026-03-06 16:59", false},
+		{"2026-03-06 17:00", true},
+		{"2026-03-06 18:59", true},
+		{"2026-03-06 19:00", false},
+		{"2026-03-07 18:00", false}, // Saturday
+	}
+	for _, c := range cases {
+		if got := schedule.ActiveAt(local(c.at)) != nil; got != c.want {
+			t.Errorf("ActiveAt(%s) = %v, want %v", c.at, got, c.want)
+		}
+	}
+
+	// The venue's clock decides, not UTC: 23:30 UTC is 17:30 in the venue
+	if schedule.ActiveAt(time.Date(2026, 3, 6, 23, 30, 0, 0, time.UTC)) == nil {
+		t.Error("ActiveAt(23:30 UTC) = nil, want the 17:00 happy hour of the venue")
+	}
+
+	active := schedule.ActiveAt(local("2026-03-06 18:00"))
+	if !active.EndsAt.Equal(local("2026-03-06 19:00")) {
+		t.Errorf("ends at %s, want 19:00", active.EndsAt)
+	}
+}
+
+func TestActiveAtOvernight(t *testing.T) {
+	// Friday's late happy hour runs into Saturday morning, not Saturday night
+	late := happyHour("22:00:00", "02:00:00", time.Friday)
+	schedule := &Schedule{HappyHours: []HappyHour{late}, Location: costaRica}
+
+	for at, want := range map[string]bool{
+		"2026-03-06 21:59": false,
+		"2026-03-06 23:00": true,
+		"2026-03-07 01:30": true,
+		"2026-03-07 02:00": false,
+		"2026-03-07 23:00": false,
+	} {
+		if got := schedule.ActiveAt(local(at)) != nil; got != want {
+			t.Errorf("ActiveAt(%s) = %v, want %v", at, got, want)
+		}
+	}
+}
+
+func TestActiveAtDates(t *testing.T) {
+	allDay := happyHour("00:00:00", "00:00:00")
+	end := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	allDay.StartDate, allDay.EndDate = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), &end
+
+	for at, want := range map[string]bool{
+		"2026-02-28 12:00": false,
+		"2026-03-01 00:00": true,
+		"2026-03-31 23:59": true,
+		"2026-04-01 00:00": false,
+	} {
+		if got := allDay.ActiveAt(local(at), costaRica) != nil; got != want {
+			t.Errorf("ActiveAt(%s) = %v, want %v", at, got, want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	ListHappyHourPromotionsQuery queries.Name = "list_happy_hour_promotions"
+)
+
+// LoadQueries loads and validates the happy hour SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListHappyHourPromotionsQuery,
+	)
+}
@@ -0,0 +1,7 @@
+-- List the active happy hour promotions with their dates and times as text, read in the
+-- venue's timezone by the schedule
+SELECT id, name, to_char(start_date, 'YYYY-MM-DD'), to_char(end_date, 'YYYY-MM-DD'),
+       to_char(from_time, 'HH24:MI:SS'), to_char(to_time, 'HH24:MI:SS'), days_of_week
+FROM promotions
+WHERE promotion_type = 'happy_hour' AND is_active = true
+ORDER BY name, id;
@@ -1,24 +1,34 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"menu-service/pkg/entities/menu_categories/models"
 	menuCategorySQL "menu-service/pkg/entities/menu_categories/sql"
+	"shared/audit"
 	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// auditEntity names the entity in the audit log
+const auditEntity = "menu_category"
+
 // DBHandler handles database operations for menu categories
 type DBHandler struct {
 	db      *sharedDb.DbHandler
-	queries *menuCategorySQL.Queries
+	audit   *audit.Log
+	queries *queries.Registry
 	logger  *logrus.Logger
 }
 
 // NewDBHandler creates a new database handler
-func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, error) {
+func NewDBHandler(db *sharedDb.DbHandler, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
 	queries, err := menuCategorySQL.LoadQueries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
@@ -26,33 +36,30 @@ func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, er
 
 	return &DBHandler{
 		db:      db,
+		audit:   auditLog,
 		queries: queries,
 		logger:  logger,
 	}, nil
 }
 
-// List returns a paginated list of menu categories
-func (h *DBHandler) List(page, limit int) (*models.MenuCategoryListResponse, error) {
-	offset := (page - 1) * limit
-
-	// Get total count
-	countQuery, err := h.queries.Get(menuCategorySQL.CountMenuCategoriesQuery)
+// List returns a page of menu categories matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.MenuCategoryListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(menuCategorySQL.ListMenuCategoriesQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get count query: %w", err)
+		return nil, fmt.Errorf("failed to build menu categories count: %w", err)
 	}
 
 	var total int
-	if err := h.db.QueryRow(countQuery).Scan(&total); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
 		return nil, fmt.Errorf("failed to count menu categories: %w", err)
 	}
 
-	// Get categories
-	listQuery, err := h.queries.Get(menuCategorySQL.ListMenuCategoriesQuery)
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(menuCategorySQL.ListMenuCategoriesQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
+		return nil, fmt.Errorf("failed to build menu categories list: %w", err)
 	}
 
-	rows, err := h.db.Query(listQuery, limit, offset)
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list menu categories: %w", err)
 	}
@@ -63,7 +70,7 @@ func (h *DBHandler) List(page, limit int) (*models.MenuCategoryListResponse, err
 		var cat models.MenuCategory
 		var description sql.NullString
 
-		if err := rows.Scan(&cat.ID, &cat.Name, &cat.DisplayOrder, &description, &cat.CreatedAt, &cat.UpdatedAt); err != nil {
+		if err := rows.Scan(&cat.ID, &cat.Name, &cat.DisplayOrder, &description, &cat.CreatedAt, &cat.UpdatedAt, &cat.Version, &cat.DeletedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan menu category: %w", err)
 		}
 
@@ -73,25 +80,25 @@ func (h *DBHandler) List(page, limit int) (*models.MenuCategoryListResponse, err
 		categories = append(categories, cat)
 	}
 
+	categories, pageInfo, err := queryspec.Paginate(spec, categories)
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.MenuCategoryListResponse{
 		Categories: categories,
 		Total:      total,
-		Page:       page,
-		Limit:      limit,
+		Limit:      spec.Limit,
+		PageInfo:   pageInfo,
 	}, nil
 }
 
 // GetByID returns a menu category by ID
-func (h *DBHandler) GetByID(id string) (*models.MenuCategory, error) {
-	query, err := h.queries.Get(menuCategorySQL.GetMenuCategoryByIDQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.MenuCategory, error) {
 	var cat models.MenuCategory
 	var description sql.NullString
 
-	err = h.db.QueryRow(query, id).Scan(&cat.ID, &cat.Name, &cat.DisplayOrder, &description, &cat.CreatedAt, &cat.UpdatedAt)
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuCategorySQL.GetMenuCategoryByIDQuery), queries.Args{"id": id}).Scan(&cat.ID, &cat.Name, &cat.DisplayOrder, &description, &cat.CreatedAt, &cat.UpdatedAt, &cat.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -107,17 +114,23 @@ func (h *DBHandler) GetByID(id string) (*models.MenuCategory, error) {
 }
 
 // Create creates a new menu category
-func (h *DBHandler) Create(req *models.MenuCategoryCreateRequest) (*models.MenuCategory, error) {
-	query, err := h.queries.Get(menuCategorySQL.CreateMenuCategoryQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+func (h *DBHandler) Create(ctx context.Context, req *models.MenuCategoryCreateRequest) (*models.MenuCategory, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.MenuCategory, error) {
+		return h.create(ctx, req)
+	})
+}
 
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.MenuCategoryCreateRequest) (*models.MenuCategory, error) {
 	var cat models.MenuCategory
 	var description sql.NullString
 
-	err = h.db.QueryRow(query, req.Name, req.DisplayOrder, req.Description).Scan(
-		&cat.ID, &cat.Name, &cat.DisplayOrder, &description, &cat.CreatedAt, &cat.UpdatedAt,
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuCategorySQL.CreateMenuCategoryQuery), queries.Args{
+		"name":          req.Name,
+		"display_order": req.DisplayOrder,
+		"description":   req.Description,
+	}).Scan(
+		&cat.ID, &cat.Name, &cat.DisplayOrder, &description, &cat.CreatedAt, &cat.UpdatedAt, &cat.Version,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create menu category: %w", err)
@@ -131,22 +144,30 @@ func (h *DBHandler) Create(req *models.MenuCategoryCreateRequest) (*models.MenuC
 	return &cat, nil
 }
 
-// Update updates an existing menu category
-func (h *DBHandler) Update(id string, req *models.MenuCategoryUpdateRequest) (*models.MenuCategory, error) {
-	query, err := h.queries.Get(menuCategorySQL.UpdateMenuCategoryQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+// Update updates an existing menu category if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.MenuCategoryUpdateRequest) (*models.MenuCategory, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.MenuCategory, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
 
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.MenuCategoryUpdateRequest) (*models.MenuCategory, error) {
 	var cat models.MenuCategory
 	var description sql.NullString
 
-	err = h.db.QueryRow(query, id, req.Name, req.DisplayOrder, req.Description).Scan(
-		&cat.ID, &cat.Name, &cat.DisplayOrder, &description, &cat.CreatedAt, &cat.UpdatedAt,
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuCategorySQL.UpdateMenuCategoryQuery), queries.Args{
+		"id":            id,
+		"version":       version,
+		"name":          req.Name,
+		"display_order": req.DisplayOrder,
+		"description":   req.Description,
+	}).Scan(
+		&cat.ID, &cat.Name, &cat.DisplayOrder, &description, &cat.CreatedAt, &cat.UpdatedAt, &cat.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, h.versionMismatch(ctx, id)
 		}
 		return nil, fmt.Errorf("failed to update menu category: %w", err)
 	}
@@ -159,39 +180,78 @@ func (h *DBHandler) Update(id string, req *models.MenuCategoryUpdateRequest) (*m
 	return &cat, nil
 }
 
-// Delete deletes a menu category
-func (h *DBHandler) Delete(id string) error {
-	// Check for dependencies first
-	checkQuery, err := h.queries.Get(menuCategorySQL.CheckMenuCategoryDependenciesQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get check query: %w", err)
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return err
 	}
+	return sharedErrors.PreconditionFailed("menu_category_version_mismatch", "menu category was modified by another request")
+}
 
+// Delete deletes a menu category
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
+
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
+	// Check for dependencies first
 	var count int
-	if err := h.db.QueryRow(checkQuery, id).Scan(&count); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuCategorySQL.CheckMenuCategoryDependenciesQuery), queries.Args{"id": id}).Scan(&count); err != nil {
 		return fmt.Errorf("failed to check dependencies: %w", err)
 	}
 
 	if count > 0 {
-		return fmt.Errorf("cannot delete category: %d menu items depend on it", count)
+		return sharedErrors.Conflict("menu_category_in_use", fmt.Sprintf("cannot delete category: %d menu items depend on it", count))
 	}
 
 	// Delete the category
-	deleteQuery, err := h.queries.Get(menuCategorySQL.DeleteMenuCategoryQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get delete query: %w", err)
-	}
-
-	result, err := h.db.Exec(deleteQuery, id)
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(menuCategorySQL.DeleteMenuCategoryQuery), queries.Args{"id": id})
 	if err != nil {
 		return fmt.Errorf("failed to delete menu category: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("menu category not found")
+		return sharedErrors.NotFound("menu_category_not_found", "menu category not found")
 	}
 
 	h.logger.WithField("id", id).Info("Menu category deleted")
 	return nil
 }
+
+// Restore brings back a soft deleted menu category
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.MenuCategory, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.MenuCategory, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.MenuCategory, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(menuCategorySQL.RestoreMenuCategoryQuery), queries.Args{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore menu category: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("menu_category_not_found", "deleted menu category not found")
+	}
+
+	h.logger.WithField("id", id).Info("Menu category restored")
+	return h.GetByID(ctx, id)
+}
+
+// Purge hard deletes the menu categories soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuCategorySQL.PurgeMenuCategoriesQuery), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge menu categories: %w", err)
+	}
+	return purged, nil
+}
@@ -3,9 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"menu-service/pkg/entities/menu_categories/models"
+	menuCategorySQL "menu-service/pkg/entities/menu_categories/sql"
 	sharedHttp "shared/http"
 
 	"github.com/gorilla/mux"
@@ -25,22 +25,19 @@ func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
 
 // List handles GET /api/v1/menu/categories
 func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 20
+	spec, err := menuCategorySQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
 	}
 
-	response, err := h.dbHandler.List(page, limit)
+	response, err := h.dbHandler.List(r.Context(), spec)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list menu categories")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list menu categories")
+		sharedHttp.SendError(w, r, err, "Failed to list menu categories")
 		return
 	}
+	response.SetLinks(r.URL)
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu categories retrieved", response)
 }
@@ -50,10 +47,10 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	category, err := h.dbHandler.GetByID(id)
+	category, err := h.dbHandler.GetByID(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get menu category")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to get menu category")
+		sharedHttp.SendError(w, r, err, "Failed to get menu category")
 		return
 	}
 
@@ -62,6 +59,7 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, category.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu category retrieved", category)
 }
 
@@ -79,13 +77,14 @@ func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	category, err := h.dbHandler.Create(&req)
+	category, err := h.dbHandler.Create(r.Context(), &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create menu category")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to create menu category")
+		sharedHttp.SendError(w, r, err, "Failed to create menu category")
 		return
 	}
 
+	sharedHttp.SetETag(w, category.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Menu category created", category)
 }
 
@@ -94,6 +93,12 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update menu category")
+		return
+	}
+
 	var req models.MenuCategoryUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode request body")
@@ -101,10 +106,10 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	category, err := h.dbHandler.Update(id, &req)
+	category, err := h.dbHandler.Update(r.Context(), id, version, &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update menu category")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update menu category")
+		sharedHttp.SendError(w, r, err, "Failed to update menu category")
 		return
 	}
 
@@ -113,6 +118,7 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, category.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu category updated", category)
 }
 
@@ -121,16 +127,28 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.dbHandler.Delete(id)
+	err := h.dbHandler.Delete(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete menu category")
-		if err.Error() == "menu category not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Menu category not found")
-			return
-		}
-		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		sharedHttp.SendError(w, r, err, "Failed to delete menu category")
 		return
 	}
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu category deleted", nil)
 }
+
+// Restore handles POST /api/v1/menu/categories/:id/restore
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	category, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore menu category")
+		sharedHttp.SendError(w, r, err, "Failed to restore menu category")
+		return
+	}
+
+	sharedHttp.SetETag(w, category.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu category restored", category)
+}
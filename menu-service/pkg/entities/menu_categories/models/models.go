@@ -2,16 +2,20 @@ package models
 
 import (
 	"time"
+
+	"shared/db/queryspec"
 )
 
 // MenuCategory represents a menu category
 type MenuCategory struct {
-	ID           string    `json:"id"`
-	Name         string    `json:"name"`
-	DisplayOrder int       `json:"display_order"`
-	Description  *string   `json:"description,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	DisplayOrder int        `json:"display_order"`
+	Description  *string    `json:"description,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Version      int        `json:"version"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
 }
 
 // MenuCategoryCreateRequest represents a request to create a menu category
@@ -32,6 +36,6 @@ type MenuCategoryUpdateRequest struct {
 type MenuCategoryListResponse struct {
 	Categories []MenuCategory `json:"categories"`
 	Total      int            `json:"total"`
-	Page       int            `json:"page"`
 	Limit      int            `json:"limit"`
+	queryspec.PageInfo
 }
@@ -2,59 +2,44 @@ package sql
 
 import (
 	"embed"
-	"fmt"
-	"strings"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
 )
 
 //go:embed scripts/*.sql
-var sqlFiles embed.FS
-
-// Queries holds all SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from the scripts directory
-func LoadQueries() (*Queries, error) {
-	queries := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files, err := sqlFiles.ReadDir("scripts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			content, err := sqlFiles.ReadFile("scripts/" + file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read SQL file %s: %w", file.Name(), err)
-			}
+var sqlScripts embed.FS
 
-			queryName := strings.TrimSuffix(file.Name(), ".sql")
-			queries.queries[queryName] = string(content)
-		}
-	}
-	return queries, nil
-}
-
-// Get retrieves a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, exists := q.queries[name]
-	if !exists {
-		return "", fmt.Errorf("query '%s' not found", name)
-	}
-	return query, nil
-}
-
-// SQL query constants
+// SQL query names
 const (
-	ListMenuCategoriesQuery           = "list_menu_categories"
-	CountMenuCategoriesQuery          = "count_menu_categories"
-	GetMenuCategoryByIDQuery          = "get_menu_category_by_id"
-	CreateMenuCategoryQuery           = "create_menu_category"
-	UpdateMenuCategoryQuery           = "update_menu_category"
-	DeleteMenuCategoryQuery           = "delete_menu_category"
-	CheckMenuCategoryDependenciesQuery = "check_menu_category_dependencies"
+	ListMenuCategoriesQuery            queries.Name = "list_menu_categories"
+	GetMenuCategoryByIDQuery           queries.Name = "get_menu_category_by_id"
+	CreateMenuCategoryQuery            queries.Name = "create_menu_category"
+	UpdateMenuCategoryQuery            queries.Name = "update_menu_category"
+	DeleteMenuCategoryQuery            queries.Name = "delete_menu_category"
+	RestoreMenuCategoryQuery           queries.Name = "restore_menu_category"
+	PurgeMenuCategoriesQuery           queries.Name = "purge_menu_categories"
+	CheckMenuCategoryDependenciesQuery queries.Name = "check_menu_category_dependencies"
 )
+
+// ListSchema whitelists the columns of list_menu_categories that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("display_order,name",
+	queryspec.Field{Name: "name", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "display_order", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).WithSoftDelete()
+
+// LoadQueries loads and validates the menu category SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListMenuCategoriesQuery,
+		GetMenuCategoryByIDQuery,
+		CreateMenuCategoryQuery,
+		UpdateMenuCategoryQuery,
+		DeleteMenuCategoryQuery,
+		RestoreMenuCategoryQuery,
+		PurgeMenuCategoriesQuery,
+		CheckMenuCategoryDependenciesQuery,
+	)
+}
@@ -1 +0,0 @@
-SELECT COUNT(*) FROM menu_categories;
@@ -1,3 +1,3 @@
 INSERT INTO menu_categories (name, display_order, description)
-VALUES ($1, $2, $3)
-RETURNING id, name, display_order, description, created_at, updated_at;
+VALUES (@name, @display_order, @description)
+RETURNING id, name, display_order, description, created_at, updated_at, version;
@@ -1 +1 @@
-DELETE FROM menu_categories WHERE id = $1;
+UPDATE menu_categories SET deleted_at = CURRENT_TIMESTAMP WHERE id = @id AND deleted_at IS NULL;
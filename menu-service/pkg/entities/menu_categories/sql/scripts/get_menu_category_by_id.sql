@@ -1,3 +1,3 @@
-SELECT id, name, display_order, description, created_at, updated_at
+SELECT id, name, display_order, description, created_at, updated_at, version
 FROM menu_categories
-WHERE id = $1;
+WHERE id = @id AND deleted_at IS NULL;
@@ -0,0 +1,8 @@
+-- Hard delete categories soft deleted before the retention window that nothing references
+WITH purged AS (
+    DELETE FROM menu_categories mc
+    WHERE mc.deleted_at < @deleted_before
+      AND NOT EXISTS (SELECT 1 FROM menu_sub_categories msc WHERE msc.category_id = mc.id)
+    RETURNING mc.id
+)
+SELECT COUNT(*) FROM purged;
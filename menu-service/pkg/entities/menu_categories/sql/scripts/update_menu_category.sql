@@ -1,7 +1,7 @@
 UPDATE menu_categories
-SET name = COALESCE($2, name),
-    display_order = COALESCE($3, display_order),
-    description = COALESCE($4, description),
+SET name = COALESCE(@name, name),
+    display_order = COALESCE(@display_order, display_order),
+    description = COALESCE(@description, description),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
-RETURNING id, name, display_order, description, created_at, updated_at;
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id, name, display_order, description, created_at, updated_at, version;
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"menu-service/pkg/entities/menu_costs/models"
+	menuCostSQL "menu-service/pkg/entities/menu_costs/sql"
+	menuVariantHandlers "menu-service/pkg/entities/menu_variants/handlers"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	sharedErrors "shared/errors"
+	"shared/money"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DBHandler computes menu variant costs from their ingredients and stores them through
+// the menu variant handler
+type DBHandler struct {
+	db       *sharedDb.DbHandler
+	variants *menuVariantHandlers.DBHandler
+	strategy string
+	queries  *queries.Registry
+	logger   *logrus.Logger
+}
+
+// NewDBHandler creates a new menu cost database handler. strategy costs the sub-category
+// ingredients that don't set their own, models.StrategyAverage or models.StrategyMax.
+func NewDBHandler(db *sharedDb.DbHandler, variants *menuVariantHandlers.DBHandler, strategy string, logger *logrus.Logger) (*DBHandler, error) {
+	if strategy != models.StrategyAverage && strategy != models.StrategyMax {
+		return nil, fmt.Errorf("invalid recipe cost strategy %q, want %s or %s", strategy, models.StrategyAverage, models.StrategyMax)
+	}
+
+	queries, err := menuCostSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DBHandler{
+		db:       db,
+		variants: variants,
+		strategy: strategy,
+		queries:  queries,
+		logger:   logger,
+	}, nil
+}
+
+// GetByMenuVariant returns the cost breakdown of a menu variant
+func (h *DBHandler) GetByMenuVariant(ctx context.Context, menuVariantID string) (*models.VariantCost, error) {
+	recipes, err := h.recipes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cost := recipes.Get(menuVariantID)
+	if cost == nil {
+		return nil, sharedErrors.NotFound("menu_variant_not_found", "menu variant not found")
+	}
+	return cost, nil
+}
+
+// Tree returns the bill of materials of a menu variant
+func (h *DBHandler) Tree(ctx context.Context, menuVariantID string) (*models.BOMNode, error) {
+	recipes, err := h.recipes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := recipes.Tree(menuVariantID)
+	if tree == nil {
+		return nil, sharedErrors.NotFound("menu_variant_not_found", "menu variant not found")
+	}
+	return tree, nil
+}
+
+// List returns the cost of every menu variant, only those with the given status when
+// one is set
+func (h *DBHandler) List(ctx context.Context, status string) (*models.VariantCostListResponse, error) {
+	recipes, err := h.recipes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := []models.VariantCost{}
+	for _, cost := range recipes.All() {
+		if status == "" || cost.Status == status {
+			variants = append(variants, cost)
+		}
+	}
+
+	return &models.VariantCostListResponse{
+		Variants: variants,
+		Total:    len(variants),
+	}, nil
+}
+
+// Recompute computes the cost of every menu variant and stores those that changed, e.g.
+// after costs were changed outside the service or a variant moved between sub-categories.
+// It joins the caller's transaction, so a recipe change and the new costs commit together.
+func (h *DBHandler) Recompute(ctx context.Context) (*models.RecomputeResponse, error) {
+	var response *models.RecomputeResponse
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		// Full recomputes run one at a time, so none stores costs read before another committed
+		if err := h.variants.LockRecipes(ctx); err != nil {
+			return err
+		}
+
+		recipes, err := h.recipes(ctx)
+		if err != nil {
+			return err
+		}
+
+		response, err = h.store(ctx, recipes, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// RecomputeForStock costs again the menu variants made with a stock variant, directly or
+// through sub-category ingredients, after its average cost changed
+func (h *DBHandler) RecomputeForStock(ctx context.Context, stockVariantID string) (*models.RecomputeResponse, error) {
+	return h.recomputeAffected(ctx, func(recipes *models.Recipes) map[string]bool {
+		return recipes.UsingStock(stockVariantID)
+	})
+}
+
+// RecomputeForVariant costs again a menu variant whose ingredients changed and the
+// variants made with it through sub-category ingredients
+func (h *DBHandler) RecomputeForVariant(ctx context.Context, menuVariantID string) (*models.RecomputeResponse, error) {
+	return h.recomputeAffected(ctx, func(recipes *models.Recipes) map[string]bool {
+		return recipes.Using(menuVariantID)
+	})
+}
+
+// recomputeAffected stores the costs of the variants affected picks. It shares the recipe
+// lock with other such recomputes and only locks the affected variants, so changes that
+// reach different parts of the menu don't wait for each other.
+func (h *DBHandler) recomputeAffected(ctx context.Context, affected func(*models.Recipes) map[string]bool) (*models.RecomputeResponse, error) {
+	var response *models.RecomputeResponse
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		if err := h.variants.LockRecipesShared(ctx); err != nil {
+			return err
+		}
+
+		recipes, err := h.recipes(ctx)
+		if err != nil {
+			return err
+		}
+		ids := affected(recipes)
+		if len(ids) == 0 {
+			response = &models.RecomputeResponse{}
+			return nil
+		}
+
+		locked := make([]string, 0, len(ids))
+		for id := range ids {
+			locked = append(locked, id)
+		}
+		if err := h.variants.LockCosts(ctx, locked); err != nil {
+			return err
+		}
+
+		// Read again once the variants are locked, so costs stored by a recompute that
+		// held them first are not overwritten with older ones
+		if recipes, err = h.recipes(ctx); err != nil {
+			return err
+		}
+		response, err = h.store(ctx, recipes, ids)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// store updates the variants whose computed cost differs from the stored one, only those
+// in ids when it is set
+func (h *DBHandler) store(ctx context.Context, recipes *models.Recipes, ids map[string]bool) (*models.RecomputeResponse, error) {
+	response := &models.RecomputeResponse{}
+	for _, cost := range recipes.All() {
+		if ids != nil && !ids[cost.MenuVariantID] {
+			continue
+		}
+		response.Variants++
+		if sameCost(cost.StoredCost, cost.ItemCost) {
+			continue
+		}
+		if _, err := h.variants.UpdateCost(ctx, cost.MenuVariantID, cost.ItemCost); err != nil {
+			return nil, fmt.Errorf("failed to store cost of menu variant %s: %w", cost.MenuVariantID, err)
+		}
+		response.Changed++
+
+		h.logger.WithFields(logrus.Fields{
+			"menu_variant_id": cost.MenuVariantID,
+			"item_cost":       cost.ItemCost,
+			"status":          cost.Status,
+		}).Info("Menu variant cost updated")
+	}
+	return response, nil
+}
+
+// RecomputeCosts is Recompute without the report, menu variants call it when their
+// sub-category changes, which can reach any variant with a sub-category ingredient
+func (h *DBHandler) RecomputeCosts(ctx context.Context) error {
+	_, err := h.Recompute(ctx)
+	return err
+}
+
+// recipes reads every live menu variant with its ingredients and resolves their costs
+func (h *DBHandler) recipes(ctx context.Context) (*models.Recipes, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(menuCostSQL.ListCostLinesQuery), queries.Args{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list menu variant cost lines: %w", err)
+	}
+	defer rows.Close()
+
+	var costs []models.VariantCost
+	for rows.Next() {
+		var variantID, variantName, subCategoryID string
+		var storedCost, avgCost *money.Money
+		var ingredientID, stockVariantID, stockVariantName, menuSubCategoryID, menuSubCategoryName sql.NullString
+		var costStrategy, defaultVariantID sql.NullString
+		var quantity sql.NullFloat64
+		var isOptional sql.NullBool
+
+		if err := rows.Scan(
+			&variantID, &variantName, &subCategoryID, &storedCost,
+			&ingredientID, &stockVariantID, &stockVariantName, &menuSubCategoryID, &menuSubCategoryName,
+			&quantity, &isOptional, &costStrategy, &defaultVariantID, &avgCost,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan menu variant cost line: %w", err)
+		}
+
+		if len(costs) == 0 || costs[len(costs)-1].MenuVariantID != variantID {
+			costs = append(costs, models.VariantCost{
+				MenuVariantID:   variantID,
+				MenuVariantName: variantName,
+				SubCategoryID:   subCategoryID,
+				StoredCost:      storedCost,
+				Lines:           []models.CostLine{},
+			})
+		}
+		if !ingredientID.Valid {
+			continue
+		}
+
+		line := models.CostLine{
+			IngredientID: ingredientID.String,
+			Quantity:     quantity.Float64,
+			IsOptional:   isOptional.Bool,
+			Strategy:     costStrategy.String,
+		}
+		if stockVariantID.Valid {
+			line.StockVariantID = &stockVariantID.String
+			line.StockVariantName = stockVariantName.String
+			line.UnitCost = avgCost
+		}
+		if menuSubCategoryID.Valid {
+			line.MenuSubCategoryID = &menuSubCategoryID.String
+			line.MenuSubCategoryName = menuSubCategoryName.String
+		}
+		if defaultVariantID.Valid {
+			line.DefaultVariantID = &defaultVariantID.String
+		}
+		cost := &costs[len(costs)-1]
+		cost.Lines = append(cost.Lines, line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating menu variant cost lines: %w", err)
+	}
+
+	return models.NewRecipes(costs, h.strategy), nil
+}
+
+// sameCost reports whether a stored cost already matches a computed one, both nil when
+// the cost is unknown
+func sameCost(stored, computed *money.Money) bool {
+	if stored == nil || computed == nil {
+		return stored == nil && computed == nil
+	}
+	return stored.Equal(*computed)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+
+	"shared/events"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EventHandler consumes domain events that affect menu variant costs
+type EventHandler struct {
+	dbHandler *DBHandler
+	logger    *logrus.Logger
+}
+
+// NewEventHandler creates a new menu cost event handler
+func NewEventHandler(dbHandler *DBHandler, logger *logrus.Logger) *EventHandler {
+	return &EventHandler{
+		dbHandler: dbHandler,
+		logger:    logger,
+	}
+}
+
+// StockVariantCostChanged costs again the variants made with the stock variant, directly
+// or through a sub-category
+func (h *EventHandler) StockVariantCostChanged(ctx context.Context, event *events.Event) error {
+	var payload events.StockVariantCostChangedPayload
+	if err := event.Decode(&payload); err != nil {
+		return err
+	}
+
+	result, err := h.dbHandler.RecomputeForStock(ctx, payload.StockVariantID)
+	if err != nil {
+		return err
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"stock_variant_id": payload.StockVariantID,
+		"old_cost":         payload.OldCost,
+		"new_cost":         payload.NewCost,
+		"variants":         result.Variants,
+		"changed":          result.Changed,
+	}).Info("Menu variant costs recomputed for stock cost change")
+	return nil
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"menu-service/pkg/entities/menu_costs/models"
+	sharedErrors "shared/errors"
+	sharedHttp "shared/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// recomputeRequestTimeout replaces the server write timeout for a full recompute, which
+// may store the cost of every menu variant
+const recomputeRequestTimeout = 10 * time.Minute
+
+// HTTPHandler handles HTTP requests for menu variant costs
+type HTTPHandler struct {
+	db     *DBHandler
+	logger *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(db *DBHandler, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetByMenuVariant handles GET /api/v1/menu/variants/{variantId}/cost
+func (h *HTTPHandler) GetByMenuVariant(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	menuVariantID := vars["variantId"]
+
+	cost, err := h.db.GetByMenuVariant(r.Context(), menuVariantID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get menu variant cost")
+		sharedHttp.SendError(w, r, err, "Failed to retrieve menu variant cost")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu variant cost retrieved", cost)
+}
+
+// Tree handles GET /api/v1/menu/variants/{variantId}/bom, the bill of materials with
+// every sub-category ingredient expanded into its variants down to stock
+func (h *HTTPHandler) Tree(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	menuVariantID := vars["variantId"]
+
+	tree, err := h.db.Tree(r.Context(), menuVariantID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get menu variant bill of materials")
+		sharedHttp.SendError(w, r, err, "Failed to retrieve menu variant bill of materials")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu variant bill of materials retrieved", tree)
+}
+
+// List handles GET /api/v1/menu/costs?status=unknown, status is one of known, unknown
+// and no_recipe
+func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.CostKnown, models.CostUnknown, models.CostNoRecipe:
+	default:
+		sharedHttp.SendError(w, r, sharedErrors.Validation("invalid_query", "invalid menu cost parameters",
+			sharedErrors.FieldError{Field: "status", Code: "invalid_status", Message: "status must be known, unknown or no_recipe"}), "")
+		return
+	}
+
+	response, err := h.db.List(r.Context(), status)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list menu variant costs")
+		sharedHttp.SendError(w, r, err, "Failed to retrieve menu variant costs")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu variant costs retrieved", response)
+}
+
+// Recompute handles POST /api/v1/menu/costs/recompute, it stores the cost of every menu
+// variant again, e.g. after costs were changed outside the service
+func (h *HTTPHandler) Recompute(w http.ResponseWriter, r *http.Request) {
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(recomputeRequestTimeout))
+
+	response, err := h.db.Recompute(r.Context())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to recompute menu variant costs")
+		sharedHttp.SendError(w, r, err, "Failed to recompute menu variant costs")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu variant costs recomputed", response)
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"shared/money"
+)
+
+// Cost line statuses
+const (
+	// LineCosted is an ingredient with a known cost per portion
+	LineCosted = "costed"
+	// LineNoPurchases is a stock ingredient without purchases, so its cost is unknown
+	LineNoPurchases = "no_purchases"
+	// LineUnresolved is a sub-category ingredient whose sub-category has no variants, or
+	// whose variants it is costed from have an unknown cost
+	LineUnresolved = "unresolved"
+	// LineCycle is a sub-category ingredient that leads back to the variant using it
+	LineCycle = "cycle"
+)
+
+// Variant cost statuses
+const (
+	// CostKnown means every ingredient has a cost
+	CostKnown = "known"
+	// CostUnknown means at least one ingredient has no cost
+	CostUnknown = "unknown"
+	// CostNoRecipe means the variant has no ingredients to cost
+	CostNoRecipe = "no_recipe"
+)
+
+// CostLine is one ingredient of a menu variant and what it adds to the variant's cost
+type CostLine struct {
+	IngredientID        string  `json:"ingredient_id"`
+	StockVariantID      *string `json:"stock_variant_id,omitempty"`
+	StockVariantName    string  `json:"stock_variant_name,omitempty"`
+	MenuSubCategoryID   *string `json:"menu_sub_category_id,omitempty"`
+	MenuSubCategoryName string  `json:"menu_sub_category_name,omitempty"`
+	Quantity            float64 `json:"quantity"`
+	IsOptional          bool    `json:"is_optional"`
+	// Strategy and DefaultVariantID resolve a sub-category ingredient, Strategy is the one
+	// applied once costed
+	Strategy         string  `json:"strategy,omitempty"`
+	DefaultVariantID *string `json:"default_variant_id,omitempty"`
+	// UnitCost is the cost per portion: the stock's average cost or the resolved cost of
+	// the sub-category, computed from the variants in CostedFrom
+	UnitCost   *money.Money `json:"unit_cost,omitempty"`
+	CostedFrom []string     `json:"costed_from,omitempty"`
+	Cost       *money.Money `json:"cost,omitempty"`
+	Status     string       `json:"status"`
+}
+
+// VariantCost is the cost breakdown of a menu variant. ItemCost is nil unless Status is
+// CostKnown.
+type VariantCost struct {
+	MenuVariantID   string       `json:"menu_variant_id"`
+	MenuVariantName string       `json:"menu_variant_name"`
+	SubCategoryID   string       `json:"sub_category_id"`
+	ItemCost        *money.Money `json:"item_cost"`
+	Status          string       `json:"status"`
+	MissingCosts    int          `json:"missing_costs"`
+	Lines           []CostLine   `json:"lines"`
+	// StoredCost is the item_cost the variant had when it was read
+	StoredCost *money.Money `json:"-"`
+}
+
+// VariantCostListResponse represents the costs of the menu variants
+type VariantCostListResponse struct {
+	Variants []VariantCost `json:"variants"`
+	Total    int           `json:"total"`
+}
+
+// RecomputeResponse reports a recompute of menu variant costs, Variants is how many were costed
+type RecomputeResponse struct {
+	Variants int `json:"variants"`
+	Changed  int `json:"changed"`
+}
+
+// compute prices the lines and sets the variant cost and status. Each line costs its
+// quantity in portions times its unit cost, optional ingredients included; a line
+// without a unit cost makes the whole variant cost unknown. Sub-category lines must be
+// resolved first.
+func (v *VariantCost) compute() {
+	total := money.Money{}
+	v.MissingCosts = 0
+
+	for i := range v.Lines {
+		line := &v.Lines[i]
+		line.Cost = nil
+
+		if line.StockVariantID != nil {
+			line.Status = LineCosted
+			if line.UnitCost == nil || line.UnitCost.Sign() <= 0 {
+				line.Status = LineNoPurchases
+			}
+		}
+		if line.Status != LineCosted {
+			v.MissingCosts++
+			continue
+		}
+
+		cost := line.UnitCost.Mul(money.DecimalFromFloat(line.Quantity)).RoundTo(money.StoragePlaces)
+		line.Cost = &cost
+		total = total.Add(cost)
+	}
+
+	v.ItemCost = nil
+	switch {
+	case v.MissingCosts > 0:
+		v.Status = CostUnknown
+	case len(v.Lines) == 0:
+		v.Status = CostNoRecipe
+	default:
+		v.Status = CostKnown
+		v.ItemCost = &total
+	}
+}
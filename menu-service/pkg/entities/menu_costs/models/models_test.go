@@ -0,0 +1,197 @@
+package models
+
+import (
+	"testing"
+
+	"shared/money"
+)
+
+func stock(id string, quantity float64, avgCost string) CostLine {
+	line := CostLine{IngredientID: "ing-" + id, StockVariantID: &id, Quantity: quantity}
+	if avgCost != "" {
+		cost := money.MustParse(avgCost)
+		line.UnitCost = &cost
+	}
+	return line
+}
+
+func anyOf(subCategoryID string, quantity float64) CostLine {
+	return CostLine{IngredientID: "ing-" + subCategoryID, MenuSubCategoryID: &subCategoryID, Quantity: quantity}
+}
+
+func variant(id, subCategoryID string, lines ...CostLine) VariantCost {
+	return VariantCost{MenuVariantID: id, MenuVariantName: id, SubCategoryID: subCategoryID, Lines: lines}
+}
+
+func wantCost(t *testing.T, v *VariantCost, want string) {
+	t.Helper()
+	if v.Status != CostKnown || v.ItemCost == nil {
+		t.Fatalf("%s: status = %s, item cost = %v, want %s", v.MenuVariantID, v.Status, v.ItemCost, want)
+	}
+	if !v.ItemCost.Equal(money.MustParse(want)) {
+		t.Errorf("%s: item cost = %s, want %s", v.MenuVariantID, v.ItemCost, want)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	burger := variant("burger", "burgers", stock("beef", 1.5, "1200.333"), stock("bun", 1, "250"))
+	burger.Lines[1].IsOptional = true
+	recipes := NewRecipes([]VariantCost{burger}, StrategyAverage)
+
+	// 1.5 × 1200.333 = 1800.4995, rounded to 1800.50, plus the optional bun
+	wantCost(t, recipes.Get("burger"), "2050.50")
+}
+
+func TestComputeUnknown(t *testing.T) {
+	recipes := NewRecipes([]VariantCost{
+		variant("pasta", "mains", stock("flour", 1, "1200"), stock("truffle", 0.1, ""), stock("salt", 0.01, "0")),
+		variant("water", "drinks"),
+	}, StrategyAverage)
+
+	pasta := recipes.Get("pasta")
+	if pasta.Status != CostUnknown || pasta.ItemCost != nil || pasta.MissingCosts != 2 {
+		t.Errorf("status = %s, item cost = %v, missing = %d, want unknown with 2 missing", pasta.Status, pasta.ItemCost, pasta.MissingCosts)
+	}
+	if pasta.Lines[0].Status != LineCosted || pasta.Lines[1].Status != LineNoPurchases || pasta.Lines[2].Status != LineNoPurchases {
+		t.Errorf("line statuses = %s, %s, %s", pasta.Lines[0].Status, pasta.Lines[1].Status, pasta.Lines[2].Status)
+	}
+	if water := recipes.Get("water"); water.Status != CostNoRecipe || water.ItemCost != nil {
+		t.Errorf("water: status = %s, want no_recipe", water.Status)
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	sides := []VariantCost{
+		variant("fries", "sides", stock("potato", 2, "150")),
+		variant("salad", "sides", stock("lettuce", 1, "400")),
+	}
+	// fries 300, salad 400
+	build := func(line CostLine, strategy string) *Recipes {
+		return NewRecipes(append([]VariantCost{variant("combo", "combos", stock("beef", 1, "1000"), line)}, sides...), strategy)
+	}
+
+	wantCost(t, build(anyOf("sides", 1), StrategyAverage).Get("combo"), "1350")
+	wantCost(t, build(anyOf("sides", 2), StrategyMax).Get("combo"), "1800")
+
+	line := anyOf("sides", 1)
+	line.Strategy = StrategyMax
+	combo := build(line, StrategyAverage).Get("combo")
+	wantCost(t, combo, "1400")
+	if from := combo.Lines[1].CostedFrom; len(from) != 1 || from[0] != "salad" {
+		t.Errorf("max costed from %v, want [salad]", from)
+	}
+
+	fries := "fries"
+	line = anyOf("sides", 1)
+	line.Strategy, line.DefaultVariantID = StrategyDefault, &fries
+	wantCost(t, build(line, StrategyMax).Get("combo"), "1300")
+
+	// A default variant that left the sub-category falls back to the configured strategy
+	gone := "onion-rings"
+	line = anyOf("sides", 1)
+	line.Strategy, line.DefaultVariantID = StrategyDefault, &gone
+	combo = build(line, StrategyMax).Get("combo")
+	wantCost(t, combo, "1400")
+	if combo.Lines[1].Strategy != StrategyMax {
+		t.Errorf("fallback strategy = %s, want max", combo.Lines[1].Strategy)
+	}
+}
+
+func TestNestedAndUnresolved(t *testing.T) {
+	recipes := NewRecipes([]VariantCost{
+		variant("platter", "platters", anyOf("combos", 1)),
+		variant("combo", "combos", stock("beef", 1, "1000"), anyOf("sides", 1)),
+		variant("fries", "sides", stock("potato", 2, "150")),
+		variant("soup", "starters", anyOf("empty", 1)),
+		variant("wrap", "wraps", anyOf("unknown-sides", 1)),
+		variant("truffle-fries", "unknown-sides", stock("truffle", 1, "")),
+	}, StrategyAverage)
+
+	wantCost(t, recipes.Get("platter"), "1300")
+	if soup := recipes.Get("soup"); soup.Status != CostUnknown || soup.Lines[0].Status != LineUnresolved {
+		t.Errorf("empty sub-category: status = %s, line = %s, want unknown and unresolved", soup.Status, soup.Lines[0].Status)
+	}
+	if wrap := recipes.Get("wrap"); wrap.Status != CostUnknown || wrap.Lines[0].Status != LineUnresolved {
+		t.Errorf("unknown option: status = %s, line = %s, want unknown and unresolved", wrap.Status, wrap.Lines[0].Status)
+	}
+}
+
+func TestCycle(t *testing.T) {
+	recipes := NewRecipes([]VariantCost{
+		variant("a", "as", stock("beef", 1, "1000"), anyOf("bs", 1)),
+		variant("b", "bs", anyOf("as", 1)),
+	}, StrategyAverage)
+
+	for _, id := range []string{"a", "b"} {
+		if v := recipes.Get(id); v.Status != CostUnknown || v.ItemCost != nil {
+			t.Errorf("%s: status = %s, want unknown", id, v.Status)
+		}
+	}
+	if got := recipes.Get("b").Lines[0].Status; got != LineCycle {
+		t.Errorf("b's line = %s, want cycle", got)
+	}
+
+	tree := recipes.Tree("a")
+	b := tree.Lines[1].Options[0]
+	if b.MenuVariantID != "b" || b.Cycle {
+		t.Fatalf("a's option = %+v, want b expanded", b)
+	}
+	if again := b.Lines[0].Options[0]; again.MenuVariantID != "a" || !again.Cycle || again.Lines != nil {
+		t.Errorf("b's option = %+v, want a marked as a cycle and not expanded", again)
+	}
+}
+
+func TestUsing(t *testing.T) {
+	recipes := NewRecipes([]VariantCost{
+		variant("platter", "platters", anyOf("combos", 1)),
+		variant("combo", "combos", stock("beef", 1, "1000"), anyOf("sides", 1)),
+		variant("fries", "sides", stock("potato", 2, "150")),
+		variant("burger", "burgers", stock("beef", 1, "1000")),
+		variant("salad", "starters", stock("lettuce", 1, "200")),
+		variant("a", "as", stock("potato", 1, "150"), anyOf("bs", 1)),
+		variant("b", "bs", anyOf("as", 1)),
+	}, StrategyAverage)
+
+	for name, tc := range map[string]struct {
+		got  map[string]bool
+		want []string
+	}{
+		"stock through sub-categories": {recipes.UsingStock("potato"), []string{"fries", "combo", "platter", "a", "b"}},
+		"stock used directly":          {recipes.UsingStock("beef"), []string{"combo", "platter", "burger"}},
+		"unused stock":                 {recipes.UsingStock("salt"), nil},
+		"variant":                      {recipes.Using("combo"), []string{"combo", "platter"}},
+		"variant nothing uses":         {recipes.Using("salad"), []string{"salad"}},
+		"variant not on the menu":      {recipes.Using("gone"), nil},
+	} {
+		if len(tc.got) != len(tc.want) {
+			t.Errorf("%s: got %v, want %v", name, tc.got, tc.want)
+			continue
+		}
+		for _, id := range tc.want {
+			if !tc.got[id] {
+				t.Errorf("%s: got %v, want %v", name, tc.got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestTree(t *testing.T) {
+	recipes := NewRecipes([]VariantCost{
+		variant("combo", "combos", stock("beef", 1, "1000"), anyOf("sides", 1)),
+		variant("fries", "sides", stock("potato", 2, "150")),
+		variant("salad", "sides", stock("lettuce", 1, "400")),
+	}, StrategyAverage)
+
+	if recipes.Tree("missing") != nil {
+		t.Error("Tree(missing) != nil")
+	}
+	tree := recipes.Tree("combo")
+	if len(tree.Lines) != 2 || len(tree.Lines[1].Options) != 2 {
+		t.Fatalf("tree = %+v, want 2 lines and 2 side options", tree)
+	}
+	fries := tree.Lines[1].Options[0]
+	if fries.MenuVariantID != "fries" || len(fries.Lines) != 1 || fries.Lines[0].Cost == nil {
+		t.Errorf("fries = %+v, want its costed potato line", fries)
+	}
+}
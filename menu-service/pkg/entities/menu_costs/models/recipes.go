@@ -0,0 +1,252 @@
+package models
+
+import (
+	"shared/money"
+)
+
+// Strategies that cost an "any variant of a sub-category" ingredient
+const (
+	// StrategyAverage costs the ingredient at the average cost of the sub-category's variants
+	StrategyAverage = "average"
+	// StrategyMax costs the ingredient at its most expensive variant
+	StrategyMax = "max"
+	// StrategyDefault costs the ingredient at its default variant, falling back to the
+	// configured strategy when the default variant left the sub-category
+	StrategyDefault = "default"
+)
+
+// ValidStrategy reports whether s is a strategy an ingredient can use
+func ValidStrategy(s string) bool {
+	return s == StrategyAverage || s == StrategyMax || s == StrategyDefault
+}
+
+// resolve states of a variant
+const (
+	stateNew = iota
+	stateResolving
+	stateResolved
+)
+
+// Recipes is the recipe graph of the menu: every live variant with its ingredient lines,
+// where sub-category ingredients expand into the variants of the sub-category
+type Recipes struct {
+	variants map[string]*VariantCost
+	order    []string
+	members  map[string][]string
+	strategy string
+	state    map[string]int
+}
+
+// NewRecipes resolves the cost of every variant; sub-category ingredients without their
+// own strategy use strategy, StrategyAverage or StrategyMax
+func NewRecipes(variants []VariantCost, strategy string) *Recipes {
+	r := &Recipes{
+		variants: make(map[string]*VariantCost, len(variants)),
+		members:  map[string][]string{},
+		strategy: strategy,
+		state:    make(map[string]int, len(variants)),
+	}
+	for i := range variants {
+		v := &variants[i]
+		r.variants[v.MenuVariantID] = v
+		r.order = append(r.order, v.MenuVariantID)
+		r.members[v.SubCategoryID] = append(r.members[v.SubCategoryID], v.MenuVariantID)
+	}
+
+	for _, id := range r.order {
+		r.resolve(id)
+	}
+	return r
+}
+
+// Get returns the resolved cost of a variant, nil when it is not on the menu
+func (r *Recipes) Get(id string) *VariantCost {
+	return r.variants[id]
+}
+
+// All returns the resolved cost of every variant in the order they were given
+func (r *Recipes) All() []VariantCost {
+	all := make([]VariantCost, 0, len(r.order))
+	for _, id := range r.order {
+		all = append(all, *r.variants[id])
+	}
+	return all
+}
+
+// UsingStock returns the variants made with a stock variant, directly or through
+// sub-category ingredients, which are the ones a change to its cost reaches
+func (r *Recipes) UsingStock(stockVariantID string) map[string]bool {
+	var seeds []string
+	for _, id := range r.order {
+		for _, line := range r.variants[id].Lines {
+			if line.StockVariantID != nil && *line.StockVariantID == stockVariantID {
+				seeds = append(seeds, id)
+				break
+			}
+		}
+	}
+	return r.using(seeds)
+}
+
+// Using returns a variant and the variants made with it through sub-category ingredients,
+// which are the ones a change to its ingredients reaches
+func (r *Recipes) Using(menuVariantID string) map[string]bool {
+	if r.variants[menuVariantID] == nil {
+		return map[string]bool{}
+	}
+	return r.using([]string{menuVariantID})
+}
+
+// using returns the seeds and every variant with a sub-category ingredient that can be
+// one of them, transitively
+func (r *Recipes) using(seeds []string) map[string]bool {
+	users := map[string][]string{}
+	for _, id := range r.order {
+		for _, line := range r.variants[id].Lines {
+			if line.MenuSubCategoryID != nil {
+				users[*line.MenuSubCategoryID] = append(users[*line.MenuSubCategoryID], id)
+			}
+		}
+	}
+
+	affected := make(map[string]bool, len(seeds))
+	for len(seeds) > 0 {
+		id := seeds[len(seeds)-1]
+		seeds = seeds[:len(seeds)-1]
+		if affected[id] {
+			continue
+		}
+		affected[id] = true
+		seeds = append(seeds, users[r.variants[id].SubCategoryID]...)
+	}
+	return affected
+}
+
+// resolve costs a variant after the variants its sub-category ingredients expand into. It
+// returns nil when the variant is already being resolved further up, a cycle.
+func (r *Recipes) resolve(id string) *VariantCost {
+	v := r.variants[id]
+	switch r.state[id] {
+	case stateResolved:
+		return v
+	case stateResolving:
+		return nil
+	}
+
+	r.state[id] = stateResolving
+	for i := range v.Lines {
+		if v.Lines[i].MenuSubCategoryID != nil {
+			r.resolveOption(&v.Lines[i])
+		}
+	}
+	v.compute()
+	r.state[id] = stateResolved
+	return v
+}
+
+// resolveOption sets the unit cost of a sub-category line from the variants its
+// strategy picks
+func (r *Recipes) resolveOption(line *CostLine) {
+	strategy := line.Strategy
+	if strategy == "" {
+		strategy = r.strategy
+	}
+	options := r.members[*line.MenuSubCategoryID]
+	if strategy == StrategyDefault {
+		if def := line.DefaultVariantID; def != nil && r.variants[*def] != nil && r.variants[*def].SubCategoryID == *line.MenuSubCategoryID {
+			options = []string{*def}
+		} else {
+			strategy = r.strategy
+		}
+	}
+
+	line.Strategy = strategy
+	line.UnitCost = nil
+	line.CostedFrom = nil
+	line.Status = LineUnresolved
+	if len(options) == 0 {
+		return
+	}
+
+	var total, highest money.Money
+	var highestID string
+	for i, optionID := range options {
+		option := r.resolve(optionID)
+		if option == nil {
+			line.Status = LineCycle
+			return
+		}
+		if option.ItemCost == nil {
+			return
+		}
+		total = total.Add(*option.ItemCost)
+		if i == 0 || option.ItemCost.Cmp(highest) > 0 {
+			highest, highestID = *option.ItemCost, optionID
+		}
+	}
+
+	unit, costedFrom := highest, []string{highestID}
+	if strategy != StrategyMax {
+		unit, _ = total.Div(money.DecimalFromInt(int64(len(options))))
+		costedFrom = options
+	}
+	line.UnitCost = &unit
+	line.CostedFrom = costedFrom
+	line.Status = LineCosted
+}
+
+// BOMNode is a menu variant in a bill of materials
+type BOMNode struct {
+	MenuVariantID   string       `json:"menu_variant_id"`
+	MenuVariantName string       `json:"menu_variant_name"`
+	ItemCost        *money.Money `json:"item_cost"`
+	Status          string       `json:"status"`
+	// Cycle marks a variant already higher up the tree, which is not expanded again
+	Cycle bool      `json:"cycle,omitempty"`
+	Lines []BOMLine `json:"lines,omitempty"`
+}
+
+// BOMLine is an ingredient in a bill of materials; a sub-category ingredient lists every
+// variant it can be, CostedFrom tells which ones its cost came from
+type BOMLine struct {
+	CostLine
+	Options []BOMNode `json:"options,omitempty"`
+}
+
+// Tree returns the bill of materials of a variant, every sub-category ingredient expanded
+// down to stock, nil when the variant is not on the menu
+func (r *Recipes) Tree(id string) *BOMNode {
+	if r.variants[id] == nil {
+		return nil
+	}
+	node := r.tree(id, map[string]bool{})
+	return &node
+}
+
+// tree builds the node of a variant, path holds the variants above it
+func (r *Recipes) tree(id string, path map[string]bool) BOMNode {
+	v := r.variants[id]
+	node := BOMNode{
+		MenuVariantID:   v.MenuVariantID,
+		MenuVariantName: v.MenuVariantName,
+		ItemCost:        v.ItemCost,
+		Status:          v.Status,
+	}
+	if path[id] {
+		node.Cycle = true
+		return node
+	}
+
+	path[id] = true
+	for _, line := range v.Lines {
+		bomLine := BOMLine{CostLine: line}
+		if line.MenuSubCategoryID != nil {
+			for _, optionID := range r.members[*line.MenuSubCategoryID] {
+				bomLine.Options = append(bomLine.Options, r.tree(optionID, path))
+			}
+		}
+		node.Lines = append(node.Lines, bomLine)
+	}
+	delete(path, id)
+	return node
+}
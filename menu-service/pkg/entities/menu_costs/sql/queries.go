@@ -0,0 +1,22 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	ListCostLinesQuery queries.Name = "list_cost_lines"
+)
+
+// LoadQueries loads and validates the menu cost SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListCostLinesQuery,
+	)
+}
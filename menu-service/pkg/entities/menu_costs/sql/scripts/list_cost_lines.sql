@@ -0,0 +1,11 @@
+-- List every live menu variant with its ingredients and the average cost per portion of
+-- their stock; variants without ingredients come back as a single row with a null ingredient
+SELECT mv.id, mv.name, mv.sub_category_id, mv.item_cost,
+       mi.id, mi.stock_variant_id, sv.name, mi.menu_sub_category_id, msc.name,
+       mi.quantity, mi.is_optional, mi.cost_strategy, mi.default_variant_id, sv.avg_cost
+FROM menu_variants mv
+LEFT JOIN menu_ingredients mi ON mi.menu_variant_id = mv.id
+LEFT JOIN stock_variants sv ON mi.stock_variant_id = sv.id
+LEFT JOIN menu_sub_categories msc ON mi.menu_sub_category_id = msc.id
+WHERE mv.deleted_at IS NULL
+ORDER BY mv.name, mv.id, sv.name, msc.name, mi.id;
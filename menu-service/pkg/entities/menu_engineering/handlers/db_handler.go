@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"menu-service/pkg/entities/menu_engineering/models"
+	menuEngineeringSQL "menu-service/pkg/entities/menu_engineering/sql"
+	sharedDb "shared/db"
+	"shared/db/queries"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DBHandler builds menu engineering reports from order item sales and menu variant costs
+type DBHandler struct {
+	db      *sharedDb.DbHandler
+	queries *queries.Registry
+	logger  *logrus.Logger
+}
+
+// NewDBHandler creates a new menu engineering database handler
+func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, error) {
+	queries, err := menuEngineeringSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DBHandler{
+		db:      db,
+		queries: queries,
+		logger:  logger,
+	}, nil
+}
+
+// Report classifies the live menu variants of every category, or only categoryID when
+// set, by the items sold from the start of from to the end of to. Margins use today's
+// prices and costs.
+func (h *DBHandler) Report(ctx context.Context, from, to time.Time, categoryID *string) (*models.Report, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(menuEngineeringSQL.ListMenuEngineeringSalesQuery), queries.Args{
+		"from":        from,
+		"to":          to.AddDate(0, 0, 1),
+		"category_id": categoryID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list menu engineering sales: %w", err)
+	}
+	defer rows.Close()
+
+	report := &models.Report{
+		From:       from.Format(time.DateOnly),
+		To:         to.Format(time.DateOnly),
+		Categories: []models.CategoryAnalysis{},
+	}
+	for rows.Next() {
+		var categoryID, categoryName string
+		var item models.ItemAnalysis
+		if err := rows.Scan(
+			&categoryID, &categoryName, &item.SubCategoryID, &item.SubCategoryName,
+			&item.MenuVariantID, &item.MenuVariantName, &item.Price, &item.ItemCost,
+			&item.QuantitySold, &item.Revenue,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan menu engineering sales: %w", err)
+		}
+
+		if n := len(report.Categories); n == 0 || report.Categories[n-1].CategoryID != categoryID {
+			report.Categories = append(report.Categories, models.CategoryAnalysis{
+				CategoryID:   categoryID,
+				CategoryName: categoryName,
+			})
+		}
+		category := &report.Categories[len(report.Categories)-1]
+		category.Variants = append(category.Variants, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating menu engineering sales: %w", err)
+	}
+
+	for i := range report.Categories {
+		report.Categories[i].Classify()
+	}
+	return report, nil
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"menu-service/pkg/entities/menu_engineering/models"
+	"shared/bulk"
+	sharedErrors "shared/errors"
+	sharedHttp "shared/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPHandler handles HTTP requests for menu engineering reports
+type HTTPHandler struct {
+	db     *DBHandler
+	logger *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(db *DBHandler, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Report handles GET /api/v1/menu/engineering?from=2026-01-01&to=2026-01-31&category_id=,
+// as JSON or, with format=csv|xlsx, as a download
+func (h *HTTPHandler) Report(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, to, err := parsePeriod(query.Get("from"), query.Get("to"))
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "")
+		return
+	}
+	var categoryID *string
+	if value := query.Get("category_id"); value != "" {
+		categoryID = &value
+	}
+
+	report, err := h.db.Report(r.Context(), from, to, categoryID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build menu engineering report")
+		sharedHttp.SendError(w, r, err, "Failed to build menu engineering report")
+		return
+	}
+
+	if format := query.Get("format"); format != "" && format != "json" {
+		bulk.SendTable(w, r, models.Sheet, report.Table())
+		return
+	}
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu engineering report retrieved", report)
+}
+
+// parsePeriod reads the YYYY-MM-DD bounds of a report, both required and inclusive
+func parsePeriod(fromValue, toValue string) (time.Time, time.Time, error) {
+	var fieldErrors []sharedErrors.FieldError
+	from, err := time.Parse(time.DateOnly, fromValue)
+	if err != nil {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "from", Code: "invalid_date", Message: "from must be a YYYY-MM-DD date"})
+	}
+	to, err := time.Parse(time.DateOnly, toValue)
+	if err != nil {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "to", Code: "invalid_date", Message: "to must be a YYYY-MM-DD date"})
+	}
+	if len(fieldErrors) == 0 && to.Before(from) {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "to", Code: "before_from", Message: "to must not be before from"})
+	}
+
+	if len(fieldErrors) > 0 {
+		return from, to, sharedErrors.Validation("invalid_query", "invalid menu engineering parameters", fieldErrors...)
+	}
+	return from, to, nil
+}
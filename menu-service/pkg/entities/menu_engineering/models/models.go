@@ -0,0 +1,179 @@
+package models
+
+import (
+	"strconv"
+
+	"shared/bulk"
+	"shared/money"
+)
+
+// Menu engineering classes, by popularity and contribution margin within a category
+const (
+	ClassStar      = "star"      // popular and profitable
+	ClassPlowhorse = "plowhorse" // popular, below average margin
+	ClassPuzzle    = "puzzle"    // profitable, rarely sold
+	ClassDog       = "dog"       // neither
+	// ClassUnclassified is a variant whose cost is unknown, so it has no margin
+	ClassUnclassified = "unclassified"
+)
+
+// PopularityFactor is the share of an even menu mix a variant must reach to count as
+// popular: with 10 variants in a category, 70% of 10%, a 7% mix
+var PopularityFactor = money.MustParseDecimal("0.7")
+
+// ItemAnalysis is the sales and contribution of a menu variant over a period
+type ItemAnalysis struct {
+	MenuVariantID      string        `json:"menu_variant_id"`
+	MenuVariantName    string        `json:"menu_variant_name"`
+	SubCategoryID      string        `json:"sub_category_id"`
+	SubCategoryName    string        `json:"sub_category_name"`
+	Price              money.Money   `json:"price"`
+	ItemCost           *money.Money  `json:"item_cost"`
+	QuantitySold       int           `json:"quantity_sold"`
+	Revenue            money.Money   `json:"revenue"`
+	MenuMix            money.Decimal `json:"menu_mix"`
+	ContributionMargin *money.Money  `json:"contribution_margin"`
+	TotalContribution  *money.Money  `json:"total_contribution"`
+	Popular            *bool         `json:"popular"`
+	Profitable         *bool         `json:"profitable"`
+	Class              string        `json:"class"`
+}
+
+// CategoryAnalysis is the menu engineering matrix of a category. Its variants are
+// compared with each other only: the average margin is weighted by the quantities sold,
+// and a variant is popular when it reaches PopularityThreshold sales.
+type CategoryAnalysis struct {
+	CategoryID                string         `json:"category_id"`
+	CategoryName              string         `json:"category_name"`
+	QuantitySold              int            `json:"quantity_sold"`
+	Revenue                   money.Money    `json:"revenue"`
+	TotalContribution         money.Money    `json:"total_contribution"`
+	AverageContributionMargin *money.Money   `json:"average_contribution_margin"`
+	PopularityThreshold       money.Decimal  `json:"popularity_threshold"`
+	Stars                     int            `json:"stars"`
+	Plowhorses                int            `json:"plowhorses"`
+	Puzzles                   int            `json:"puzzles"`
+	Dogs                      int            `json:"dogs"`
+	Unclassified              int            `json:"unclassified"`
+	Variants                  []ItemAnalysis `json:"variants"`
+}
+
+// Classify computes the category totals and classifies its variants. Variants with an
+// unknown cost are left unclassified and out of the averages; when nothing with a known
+// cost sold, the average margin is the plain average of the margins.
+func (c *CategoryAnalysis) Classify() {
+	c.QuantitySold, c.Revenue, c.TotalContribution = 0, money.Money{}, money.Money{}
+	c.Stars, c.Plowhorses, c.Puzzles, c.Dogs, c.Unclassified = 0, 0, 0, 0, 0
+
+	var classified, classifiedSold int64
+	var marginSum money.Money
+	for i := range c.Variants {
+		v := &c.Variants[i]
+		c.QuantitySold += v.QuantitySold
+		c.Revenue = c.Revenue.Add(v.Revenue)
+
+		v.ContributionMargin, v.TotalContribution, v.Popular, v.Profitable = nil, nil, nil, nil
+		if v.ItemCost == nil {
+			continue
+		}
+		margin := v.Price.Sub(*v.ItemCost)
+		total := margin.Mul(money.DecimalFromInt(int64(v.QuantitySold)))
+		v.ContributionMargin, v.TotalContribution = &margin, &total
+
+		c.TotalContribution = c.TotalContribution.Add(total)
+		marginSum = marginSum.Add(margin)
+		classified++
+		classifiedSold += int64(v.QuantitySold)
+	}
+
+	c.AverageContributionMargin, c.PopularityThreshold = nil, money.Decimal{}
+	if classified > 0 {
+		average, _ := marginSum.Div(money.DecimalFromInt(classified))
+		if classifiedSold > 0 {
+			average, _ = c.TotalContribution.Div(money.DecimalFromInt(classifiedSold))
+		}
+		average = average.RoundTo(money.StoragePlaces)
+		c.AverageContributionMargin = &average
+		threshold, _ := money.DecimalFromInt(classifiedSold).Mul(PopularityFactor).Div(money.DecimalFromInt(classified))
+		c.PopularityThreshold = threshold.Round(2)
+	}
+
+	for i := range c.Variants {
+		v := &c.Variants[i]
+		v.MenuMix = mix(v.QuantitySold, c.QuantitySold)
+		if v.ContributionMargin == nil {
+			v.Class = ClassUnclassified
+			c.Unclassified++
+			continue
+		}
+
+		popular := classifiedSold > 0 && money.DecimalFromInt(int64(v.QuantitySold)).Cmp(c.PopularityThreshold) >= 0
+		profitable := v.ContributionMargin.Cmp(*c.AverageContributionMargin) >= 0
+		v.Popular, v.Profitable = &popular, &profitable
+
+		switch {
+		case popular && profitable:
+			v.Class = ClassStar
+			c.Stars++
+		case popular:
+			v.Class = ClassPlowhorse
+			c.Plowhorses++
+		case profitable:
+			v.Class = ClassPuzzle
+			c.Puzzles++
+		default:
+			v.Class = ClassDog
+			c.Dogs++
+		}
+	}
+}
+
+// mix returns quantity as a percentage of total, rounded to two decimals
+func mix(quantity, total int) money.Decimal {
+	if total == 0 {
+		return money.Decimal{}
+	}
+	share, _ := money.DecimalFromInt(int64(quantity) * 100).Div(money.DecimalFromInt(int64(total)))
+	return share.Round(2)
+}
+
+// Report is the menu engineering report of a period, [From, To] as YYYY-MM-DD dates
+type Report struct {
+	From       string             `json:"from"`
+	To         string             `json:"to"`
+	Categories []CategoryAnalysis `json:"categories"`
+}
+
+// Sheet names the report in CSV and XLSX downloads
+const Sheet = "menu_engineering"
+
+// tableColumns are the columns of the report as a sheet, one row per variant
+var tableColumns = []string{
+	"category", "sub_category", "menu_variant_id", "name", "price", "item_cost",
+	"quantity_sold", "menu_mix", "revenue", "contribution_margin", "total_contribution",
+	"category_average_margin", "popularity_threshold", "class",
+}
+
+// Table returns the report as a sheet, one row per variant. Unknown costs are blank.
+func (r *Report) Table() *bulk.Table {
+	table := bulk.NewTable(tableColumns...)
+	for _, c := range r.Categories {
+		for _, v := range c.Variants {
+			table.Append(
+				c.CategoryName, v.SubCategoryName, v.MenuVariantID, v.MenuVariantName,
+				v.Price.Amount().String(), optional(v.ItemCost),
+				strconv.Itoa(v.QuantitySold), v.MenuMix.String(), v.Revenue.Amount().String(),
+				optional(v.ContributionMargin), optional(v.TotalContribution),
+				optional(c.AverageContributionMargin), c.PopularityThreshold.String(), v.Class,
+			)
+		}
+	}
+	return table
+}
+
+func optional(m *money.Money) string {
+	if m == nil {
+		return ""
+	}
+	return m.Amount().String()
+}
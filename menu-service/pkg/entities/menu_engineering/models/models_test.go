@@ -0,0 +1,81 @@
+package models
+
+import (
+	"testing"
+
+	"shared/money"
+)
+
+func item(id, price, cost string, sold int) ItemAnalysis {
+	v := ItemAnalysis{MenuVariantID: id, MenuVariantName: id, Price: money.MustParse(price), QuantitySold: sold}
+	if cost != "" {
+		c := money.MustParse(cost)
+		v.ItemCost = &c
+	}
+	return v
+}
+
+func TestClassify(t *testing.T) {
+	c := CategoryAnalysis{Variants: []ItemAnalysis{
+		item("burger", "5000", "2000", 60), // margin 3000
+		item("salad", "3000", "2000", 30),  // margin 1000
+		item("steak", "12000", "6000", 5),  // margin 6000
+		item("soup", "2500", "2000", 5),    // margin 500
+		item("special", "4000", "", 10),    // unknown cost
+	}}
+	c.Classify()
+
+	// (60×3000 + 30×1000 + 5×6000 + 5×500) / 100 = 2425, popular from 70% of 100/4 = 17.5
+	if c.AverageContributionMargin == nil || !c.AverageContributionMargin.Equal(money.MustParse("2425")) {
+		t.Errorf("average margin = %v, want 2425", c.AverageContributionMargin)
+	}
+	if c.PopularityThreshold.Cmp(money.MustParseDecimal("17.5")) != 0 {
+		t.Errorf("popularity threshold = %s, want 17.5", c.PopularityThreshold)
+	}
+
+	want := map[string]string{"burger": ClassStar, "salad": ClassPlowhorse, "steak": ClassPuzzle, "soup": ClassDog, "special": ClassUnclassified}
+	for _, v := range c.Variants {
+		if v.Class != want[v.MenuVariantID] {
+			t.Errorf("%s: class = %s, want %s", v.MenuVariantID, v.Class, want[v.MenuVariantID])
+		}
+	}
+	if c.Stars != 1 || c.Plowhorses != 1 || c.Puzzles != 1 || c.Dogs != 1 || c.Unclassified != 1 {
+		t.Errorf("counts = %d/%d/%d/%d/%d, want one of each", c.Stars, c.Plowhorses, c.Puzzles, c.Dogs, c.Unclassified)
+	}
+	if c.QuantitySold != 110 || c.Variants[0].MenuMix.Cmp(money.MustParseDecimal("54.55")) != 0 {
+		t.Errorf("quantity = %d, burger mix = %s, want 110 and 54.55", c.QuantitySold, c.Variants[0].MenuMix)
+	}
+}
+
+func TestClassifyWithoutSales(t *testing.T) {
+	c := CategoryAnalysis{Variants: []ItemAnalysis{
+		item("burger", "5000", "2000", 0),
+		item("salad", "3000", "2000", 0),
+	}}
+	c.Classify()
+
+	// Nothing sold: the plain average margin splits puzzles from dogs, nothing is popular
+	if c.AverageContributionMargin == nil || !c.AverageContributionMargin.Equal(money.MustParse("2000")) {
+		t.Errorf("average margin = %v, want 2000", c.AverageContributionMargin)
+	}
+	if c.Variants[0].Class != ClassPuzzle || c.Variants[1].Class != ClassDog {
+		t.Errorf("classes = %s, %s, want puzzle and dog", c.Variants[0].Class, c.Variants[1].Class)
+	}
+}
+
+func TestReportTable(t *testing.T) {
+	c := CategoryAnalysis{CategoryName: "Food", Variants: []ItemAnalysis{item("burger", "5000", "2000", 3), item("special", "4000", "", 1)}}
+	c.Classify()
+	table := (&Report{Categories: []CategoryAnalysis{c}}).Table()
+
+	records := table.Records()
+	if len(records) != 2 {
+		t.Fatalf("rows = %d, want 2", len(records))
+	}
+	if got := records[0].String("contribution_margin"); got != "3000" {
+		t.Errorf("burger contribution_margin = %q, want 3000", got)
+	}
+	if got := records[1].String("item_cost"); got != "" || records[1].String("class") != ClassUnclassified {
+		t.Errorf("special item_cost = %q, class = %q, want blank and unclassified", got, records[1].String("class"))
+	}
+}
@@ -0,0 +1,22 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	ListMenuEngineeringSalesQuery queries.Name = "list_menu_engineering_sales"
+)
+
+// LoadQueries loads and validates the menu engineering SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListMenuEngineeringSalesQuery,
+	)
+}
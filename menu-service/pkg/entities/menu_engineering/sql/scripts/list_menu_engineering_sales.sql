@@ -0,0 +1,18 @@
+-- List every live menu variant with its price, cost and the items sold in [@from, @to):
+-- lost items and items of cancelled orders are not sales
+SELECT mc.id, mc.name, msc.id, msc.name, mv.id, mv.name, mv.price, mv.item_cost,
+       COALESCE(s.quantity, 0), COALESCE(s.revenue, 0)
+FROM menu_variants mv
+JOIN menu_sub_categories msc ON mv.sub_category_id = msc.id
+JOIN menu_categories mc ON msc.category_id = mc.id
+LEFT JOIN (
+    SELECT oi.menu_variant_id, SUM(oi.quantity) AS quantity, SUM(oi.subtotal) AS revenue
+    FROM order_items oi
+    JOIN orders o ON oi.order_id = o.id
+    WHERE oi.requested_at >= @from AND oi.requested_at < @to
+      AND oi.status <> 'lost' AND o.status <> 'cancelled'
+    GROUP BY oi.menu_variant_id
+) s ON s.menu_variant_id = mv.id
+WHERE mv.deleted_at IS NULL
+  AND (@category_id::uuid IS NULL OR mc.id = @category_id)
+ORDER BY mc.display_order, mc.name, mc.id, msc.display_order, msc.name, mv.display_order, mv.name, mv.id;
@@ -1,47 +1,73 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	menuCostHandlers "menu-service/pkg/entities/menu_costs/handlers"
+	menuCostModels "menu-service/pkg/entities/menu_costs/models"
 	"menu-service/pkg/entities/menu_ingredients/models"
 	menuIngredientSQL "menu-service/pkg/entities/menu_ingredients/sql"
+	menuVariantHandlers "menu-service/pkg/entities/menu_variants/handlers"
+	"shared/audit"
 	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
 
-	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
 
+// auditEntity names the entity in the audit log
+const auditEntity = "menu_ingredient"
+
 // DBHandler handles database operations for menu ingredients
 type DBHandler struct {
-	db      *sharedDb.DbHandler
-	queries *menuIngredientSQL.Queries
-	logger  *logrus.Logger
+	db       *sharedDb.DbHandler
+	variants *menuVariantHandlers.DBHandler
+	costs    *menuCostHandlers.DBHandler
+	audit    *audit.Log
+	queries  *queries.Registry
+	logger   *logrus.Logger
 }
 
-// NewDBHandler creates a new menu ingredient database handler
-func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, error) {
+// NewDBHandler creates a new menu ingredient database handler. New sub-category
+// ingredients are checked for cycles through variants, and every change costs the variants
+// it reaches again through costs.
+func NewDBHandler(db *sharedDb.DbHandler, variants *menuVariantHandlers.DBHandler, costs *menuCostHandlers.DBHandler, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
 	queries, err := menuIngredientSQL.LoadQueries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
 	}
 
 	return &DBHandler{
-		db:      db,
-		queries: queries,
-		logger:  logger,
+		db:       db,
+		variants: variants,
+		costs:    costs,
+		audit:    auditLog,
+		queries:  queries,
+		logger:   logger,
 	}, nil
 }
 
-// List retrieves menu ingredients with pagination
-func (h *DBHandler) List(page, limit int) ([]models.MenuIngredient, error) {
-	offset := (page - 1) * limit
+// List returns a page of menu ingredients matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.MenuIngredientPageResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(menuIngredientSQL.ListMenuIngredientsQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build menu ingredients count: %w", err)
+	}
+
+	var total int
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count menu ingredients: %w", err)
+	}
 
-	query, err := h.queries.Get("list_menu_ingredients")
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(menuIngredientSQL.ListMenuIngredientsQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
+		return nil, fmt.Errorf("failed to build menu ingredients list: %w", err)
 	}
 
-	rows, err := h.db.Query(query, limit, offset)
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list menu ingredients: %w", err)
 	}
@@ -62,8 +88,11 @@ func (h *DBHandler) List(page, limit int) ([]models.MenuIngredient, error) {
 			&ingredient.Quantity,
 			&ingredient.IsOptional,
 			&notes,
+			&ingredient.CostStrategy,
+			&ingredient.DefaultVariantID,
 			&ingredient.CreatedAt,
 			&ingredient.UpdatedAt,
+			&ingredient.Version,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan menu ingredient: %w", err)
@@ -88,20 +117,25 @@ func (h *DBHandler) List(page, limit int) ([]models.MenuIngredient, error) {
 		return nil, fmt.Errorf("error iterating menu ingredients: %w", err)
 	}
 
-	return ingredients, nil
-}
-
-// GetByID retrieves a menu ingredient by ID
-func (h *DBHandler) GetByID(id string) (*models.MenuIngredient, error) {
-	query, err := h.queries.Get("get_menu_ingredient_by_id")
+	ingredients, pageInfo, err := queryspec.Paginate(spec, ingredients)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
+		return nil, err
 	}
 
+	return &models.MenuIngredientPageResponse{
+		Ingredients: ingredients,
+		Total:       total,
+		Limit:       spec.Limit,
+		PageInfo:    pageInfo,
+	}, nil
+}
+
+// GetByID retrieves a menu ingredient by ID
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.MenuIngredient, error) {
 	var ingredient models.MenuIngredient
 	var notes, stockVariantID, stockVariantName, menuSubCategoryID, menuSubCategoryName sql.NullString
 
-	err = h.db.QueryRow(query, id).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuIngredientSQL.GetMenuIngredientByIDQuery), queries.Args{"id": id}).Scan(
 		&ingredient.ID,
 		&ingredient.MenuVariantID,
 		&stockVariantID,
@@ -111,8 +145,11 @@ func (h *DBHandler) GetByID(id string) (*models.MenuIngredient, error) {
 		&ingredient.Quantity,
 		&ingredient.IsOptional,
 		&notes,
+		&ingredient.CostStrategy,
+		&ingredient.DefaultVariantID,
 		&ingredient.CreatedAt,
 		&ingredient.UpdatedAt,
+		&ingredient.Version,
 	)
 
 	if err != nil {
@@ -138,16 +175,27 @@ func (h *DBHandler) GetByID(id string) (*models.MenuIngredient, error) {
 }
 
 // Create creates a new menu ingredient
-func (h *DBHandler) Create(req models.MenuIngredientCreateRequest, menuVariantID string) (*models.MenuIngredient, error) {
-	query, err := h.queries.Get("create_menu_ingredient")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+func (h *DBHandler) Create(ctx context.Context, req models.MenuIngredientCreateRequest, menuVariantID string) (*models.MenuIngredient, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.MenuIngredient, error) {
+		return h.create(ctx, req, menuVariantID)
+	})
+}
 
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req models.MenuIngredientCreateRequest, menuVariantID string) (*models.MenuIngredient, error) {
 	var ingredient models.MenuIngredient
 	var notes, stockVariantID, menuSubCategoryID sql.NullString
 
-	err = h.db.QueryRow(query, menuVariantID, req.StockVariantID, req.MenuSubCategoryID, req.Quantity, req.IsOptional, req.Notes).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuIngredientSQL.CreateMenuIngredientQuery), queries.Args{
+		"menu_variant_id":      menuVariantID,
+		"stock_variant_id":     req.StockVariantID,
+		"menu_sub_category_id": req.MenuSubCategoryID,
+		"quantity":             req.Quantity,
+		"is_optional":          req.IsOptional,
+		"notes":                req.Notes,
+		"cost_strategy":        req.CostStrategy,
+		"default_variant_id":   req.DefaultVariantID,
+	}).Scan(
 		&ingredient.ID,
 		&ingredient.MenuVariantID,
 		&stockVariantID,
@@ -155,18 +203,22 @@ func (h *DBHandler) Create(req models.MenuIngredientCreateRequest, menuVariantID
 		&ingredient.Quantity,
 		&ingredient.IsOptional,
 		&notes,
+		&ingredient.CostStrategy,
+		&ingredient.DefaultVariantID,
 		&ingredient.CreatedAt,
 		&ingredient.UpdatedAt,
+		&ingredient.Version,
 	)
 
 	if err != nil {
+		dbErr, _ := sharedErrors.As(err)
 		// Check for unique constraint violation
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
-			return nil, fmt.Errorf("menu ingredient already exists for this menu variant")
+		if dbErr != nil && dbErr.Code == "unique_violation" {
+			return nil, sharedErrors.Conflict("menu_ingredient_exists", "menu ingredient already exists for this menu variant").Wrap(err)
 		}
 		// Check for check constraint violation
-		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23514" {
-			return nil, fmt.Errorf("must specify either stock_variant_id or menu_sub_category_id, but not both")
+		if dbErr != nil && dbErr.Code == "check_violation" {
+			return nil, sharedErrors.Validation("menu_ingredient_source", "must specify either stock_variant_id or menu_sub_category_id, but not both").Wrap(err)
 		}
 		return nil, fmt.Errorf("failed to create menu ingredient: %w", err)
 	}
@@ -181,20 +233,34 @@ func (h *DBHandler) Create(req models.MenuIngredientCreateRequest, menuVariantID
 		ingredient.MenuSubCategoryID = &menuSubCategoryID.String
 	}
 
+	if err := h.recipeChanged(ctx, &ingredient, true); err != nil {
+		return nil, err
+	}
+
 	return &ingredient, nil
 }
 
-// Update updates a menu ingredient
-func (h *DBHandler) Update(id string, req models.MenuIngredientUpdateRequest) (*models.MenuIngredient, error) {
-	query, err := h.queries.Get("update_menu_ingredient")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+// Update updates a menu ingredient if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req models.MenuIngredientUpdateRequest) (*models.MenuIngredient, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.MenuIngredient, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
 
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req models.MenuIngredientUpdateRequest) (*models.MenuIngredient, error) {
 	var ingredient models.MenuIngredient
 	var notes, stockVariantID, menuSubCategoryID sql.NullString
 
-	err = h.db.QueryRow(query, id, req.Quantity, req.IsOptional, req.Notes).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuIngredientSQL.UpdateMenuIngredientQuery), queries.Args{
+		"id":                 id,
+		"version":            version,
+		"quantity":           req.Quantity,
+		"is_optional":        req.IsOptional,
+		"notes":              req.Notes,
+		"cost_strategy":      req.CostStrategy,
+		"default_variant_id": req.DefaultVariantID,
+	}).Scan(
 		&ingredient.ID,
 		&ingredient.MenuVariantID,
 		&stockVariantID,
@@ -202,13 +268,19 @@ func (h *DBHandler) Update(id string, req models.MenuIngredientUpdateRequest) (*
 		&ingredient.Quantity,
 		&ingredient.IsOptional,
 		&notes,
+		&ingredient.CostStrategy,
+		&ingredient.DefaultVariantID,
 		&ingredient.CreatedAt,
 		&ingredient.UpdatedAt,
+		&ingredient.Version,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("menu ingredient not found")
+			return nil, h.versionMismatch(ctx, id)
+		}
+		if dbErr, _ := sharedErrors.As(err); dbErr != nil && dbErr.Code == "check_violation" {
+			return nil, sharedErrors.Validation("menu_ingredient_cost_strategy", "cost_strategy and default_variant_id only apply to menu sub-category ingredients").Wrap(err)
 		}
 		return nil, fmt.Errorf("failed to update menu ingredient: %w", err)
 	}
@@ -223,41 +295,81 @@ func (h *DBHandler) Update(id string, req models.MenuIngredientUpdateRequest) (*
 		ingredient.MenuSubCategoryID = &menuSubCategoryID.String
 	}
 
+	if err := h.recipeChanged(ctx, &ingredient, false); err != nil {
+		return nil, err
+	}
+
 	return &ingredient, nil
 }
 
-// Delete deletes a menu ingredient
-func (h *DBHandler) Delete(id string) error {
-	query, err := h.queries.Get("delete_menu_ingredient")
-	if err != nil {
-		return fmt.Errorf("failed to get query: %w", err)
+// recipeChanged checks a written ingredient, and on request whether it made its variant
+// an ingredient of itself, then costs its variant and those made with it again. A failed
+// check rolls the write back.
+func (h *DBHandler) recipeChanged(ctx context.Context, ingredient *models.MenuIngredient, checkCycle bool) error {
+	if ingredient.CostStrategy != nil && *ingredient.CostStrategy == menuCostModels.StrategyDefault && ingredient.DefaultVariantID == nil {
+		return sharedErrors.Validation("menu_ingredient_default_variant", "the default cost strategy needs a default_variant_id")
+	}
+	if ingredient.DefaultVariantID != nil && ingredient.MenuSubCategoryID != nil {
+		var ok bool
+		err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuIngredientSQL.CheckDefaultVariantQuery), queries.Args{
+			"default_variant_id":   ingredient.DefaultVariantID,
+			"menu_sub_category_id": ingredient.MenuSubCategoryID,
+		}).Scan(&ok)
+		if err != nil {
+			return fmt.Errorf("failed to check default variant: %w", err)
+		}
+		if !ok {
+			return sharedErrors.Validation("menu_ingredient_default_variant", "default_variant_id must be a menu variant of the ingredient's sub-category")
+		}
 	}
 
-	result, err := h.db.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete menu ingredient: %w", err)
+	if checkCycle && ingredient.MenuSubCategoryID != nil {
+		if err := h.variants.CheckRecipeCycle(ctx, ingredient.MenuVariantID); err != nil {
+			return err
+		}
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	_, err := h.costs.RecomputeForVariant(ctx, ingredient.MenuVariantID)
+	return err
+}
+
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return err
 	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("menu ingredient not found")
+	if current == nil {
+		return sharedErrors.NotFound("menu_ingredient_not_found", "menu ingredient not found")
 	}
+	return sharedErrors.PreconditionFailed("menu_ingredient_version_mismatch", "menu ingredient was modified by another request")
+}
 
-	return nil
+// Delete deletes a menu ingredient
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
 }
 
-// GetByMenuVariant retrieves all ingredients for a specific menu variant
-func (h *DBHandler) GetByMenuVariant(menuVariantID string) ([]models.MenuIngredient, error) {
-	query, err := h.queries.Get("get_ingredients_by_menu_variant")
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
+	var menuVariantID string
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuIngredientSQL.DeleteMenuIngredientQuery), queries.Args{"id": id}).Scan(&menuVariantID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
+		if err == sql.ErrNoRows {
+			return sharedErrors.NotFound("menu_ingredient_not_found", "menu ingredient not found")
+		}
+		return fmt.Errorf("failed to delete menu ingredient: %w", err)
 	}
 
-	rows, err := h.db.Query(query, menuVariantID)
+	_, err = h.costs.RecomputeForVariant(ctx, menuVariantID)
+	return err
+}
+
+// GetByMenuVariant retrieves all ingredients for a specific menu variant
+func (h *DBHandler) GetByMenuVariant(ctx context.Context, menuVariantID string) ([]models.MenuIngredient, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(menuIngredientSQL.GetIngredientsByMenuVariantQuery), queries.Args{"menu_variant_id": menuVariantID})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ingredients by menu variant: %w", err)
 	}
@@ -278,8 +390,11 @@ func (h *DBHandler) GetByMenuVariant(menuVariantID string) ([]models.MenuIngredi
 			&ingredient.Quantity,
 			&ingredient.IsOptional,
 			&notes,
+			&ingredient.CostStrategy,
+			&ingredient.DefaultVariantID,
 			&ingredient.CreatedAt,
 			&ingredient.UpdatedAt,
+			&ingredient.Version,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan menu ingredient: %w", err)
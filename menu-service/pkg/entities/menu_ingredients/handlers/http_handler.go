@@ -3,9 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"menu-service/pkg/entities/menu_ingredients/models"
+	menuIngredientSQL "menu-service/pkg/entities/menu_ingredients/sql"
 	sharedHttp "shared/http"
 
 	"github.com/gorilla/mux"
@@ -28,29 +28,19 @@ func NewHTTPHandler(db *DBHandler, logger *logrus.Logger) *HTTPHandler {
 
 // List handles GET /api/v1/menu/ingredients
 func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-
-	if page <= 0 {
-		page = 1
-	}
-	if limit <= 0 || limit > 100 {
-		limit = 10
+	spec, err := menuIngredientSQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
 	}
 
-	ingredients, err := h.db.List(page, limit)
+	response, err := h.db.List(r.Context(), spec)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list menu ingredients")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve menu ingredients")
+		sharedHttp.SendError(w, r, err, "Failed to retrieve menu ingredients")
 		return
 	}
-
-	response := map[string]interface{}{
-		"ingredients": ingredients,
-		"total":       len(ingredients), // Simplified - in real implementation you'd have a count query
-		"page":        page,
-		"limit":       limit,
-	}
+	response.SetLinks(r.URL)
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu ingredients retrieved", response)
 }
@@ -60,10 +50,10 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	ingredient, err := h.db.GetByID(id)
+	ingredient, err := h.db.GetByID(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get menu ingredient by ID")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve menu ingredient")
+		sharedHttp.SendError(w, r, err, "Failed to retrieve menu ingredient")
 		return
 	}
 
@@ -72,6 +62,7 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, ingredient.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu ingredient retrieved", ingredient)
 }
 
@@ -97,13 +88,14 @@ func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ingredient, err := h.db.Create(req, menuVariantID)
+	ingredient, err := h.db.Create(r.Context(), req, menuVariantID)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create menu ingredient")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to create menu ingredient")
+		sharedHttp.SendError(w, r, err, "Failed to create menu ingredient")
 		return
 	}
 
+	sharedHttp.SetETag(w, ingredient.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Menu ingredient created", ingredient)
 }
 
@@ -112,6 +104,12 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update menu ingredient")
+		return
+	}
+
 	var req models.MenuIngredientUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode update menu ingredient request")
@@ -119,13 +117,19 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ingredient, err := h.db.Update(id, req)
+	if err := req.Validate(); err != nil {
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ingredient, err := h.db.Update(r.Context(), id, version, req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update menu ingredient")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update menu ingredient")
+		sharedHttp.SendError(w, r, err, "Failed to update menu ingredient")
 		return
 	}
 
+	sharedHttp.SetETag(w, ingredient.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu ingredient updated", ingredient)
 }
 
@@ -134,7 +138,7 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	if err := h.db.Delete(id); err != nil {
+	if err := h.db.Delete(r.Context(), id); err != nil {
 		h.logger.WithError(err).Error("Failed to delete menu ingredient")
 		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to delete menu ingredient")
 		return
@@ -148,10 +152,10 @@ func (h *HTTPHandler) GetByMenuVariant(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	menuVariantID := vars["variantId"]
 
-	ingredients, err := h.db.GetByMenuVariant(menuVariantID)
+	ingredients, err := h.db.GetByMenuVariant(r.Context(), menuVariantID)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get ingredients by menu variant")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve menu ingredients")
+		sharedHttp.SendError(w, r, err, "Failed to retrieve menu ingredients")
 		return
 	}
 
@@ -3,6 +3,9 @@ package models
 import (
 	"fmt"
 	"time"
+
+	menuCostModels "menu-service/pkg/entities/menu_costs/models"
+	"shared/db/queryspec"
 )
 
 // MenuIngredient represents a menu variant's ingredient (either stock variant or menu sub-category)
@@ -16,8 +19,11 @@ type MenuIngredient struct {
 	Quantity              float64   `json:"quantity"`
 	IsOptional            bool      `json:"is_optional"`
 	Notes                 *string   `json:"notes,omitempty"`
+	CostStrategy          *string   `json:"cost_strategy,omitempty"`
+	DefaultVariantID      *string   `json:"default_variant_id,omitempty"`
 	CreatedAt             time.Time `json:"created_at"`
 	UpdatedAt             time.Time `json:"updated_at"`
+	Version               int       `json:"version"`
 }
 
 // IngredientType returns "stock" or "menu" based on which reference is set
@@ -35,6 +41,10 @@ type MenuIngredientCreateRequest struct {
 	Quantity          float64 `json:"quantity"`
 	IsOptional        bool    `json:"is_optional,omitempty"`
 	Notes             *string `json:"notes,omitempty"`
+	// CostStrategy costs a menu sub-category ingredient: average, max or default, which
+	// takes the cost of DefaultVariantID. Unset uses the RECIPE_COST_STRATEGY setting.
+	CostStrategy     *string `json:"cost_strategy,omitempty"`
+	DefaultVariantID *string `json:"default_variant_id,omitempty"`
 }
 
 // Validate ensures exactly one of StockVariantID or MenuSubCategoryID is provided
@@ -48,7 +58,10 @@ func (r *MenuIngredientCreateRequest) Validate() error {
 	if !hasStock && !hasMenu {
 		return fmt.Errorf("must specify either stock_variant_id or menu_sub_category_id")
 	}
-	return nil
+	if hasStock && (r.CostStrategy != nil || r.DefaultVariantID != nil) {
+		return fmt.Errorf("cost_strategy and default_variant_id only apply to menu_sub_category_id ingredients")
+	}
+	return validateCostStrategy(r.CostStrategy)
 }
 
 // MenuIngredientUpdateRequest represents a request to update an ingredient
@@ -56,6 +69,22 @@ type MenuIngredientUpdateRequest struct {
 	Quantity   *float64 `json:"quantity,omitempty"`
 	IsOptional *bool    `json:"is_optional,omitempty"`
 	Notes      *string  `json:"notes,omitempty"`
+	// CostStrategy and DefaultVariantID only apply to menu sub-category ingredients
+	CostStrategy     *string `json:"cost_strategy,omitempty"`
+	DefaultVariantID *string `json:"default_variant_id,omitempty"`
+}
+
+// Validate checks the cost strategy
+func (r *MenuIngredientUpdateRequest) Validate() error {
+	return validateCostStrategy(r.CostStrategy)
+}
+
+// validateCostStrategy checks a cost strategy is one the recipe resolver knows
+func validateCostStrategy(strategy *string) error {
+	if strategy != nil && !menuCostModels.ValidStrategy(*strategy) {
+		return fmt.Errorf("cost_strategy must be %s, %s or %s", menuCostModels.StrategyAverage, menuCostModels.StrategyMax, menuCostModels.StrategyDefault)
+	}
+	return nil
 }
 
 // MenuIngredientListResponse represents a list of ingredients for a menu variant
@@ -63,3 +92,11 @@ type MenuIngredientListResponse struct {
 	MenuVariantID string           `json:"menu_variant_id"`
 	Ingredients   []MenuIngredient `json:"ingredients"`
 }
+
+// MenuIngredientPageResponse represents a paginated list of menu ingredients
+type MenuIngredientPageResponse struct {
+	Ingredients []MenuIngredient `json:"ingredients"`
+	Total       int              `json:"total"`
+	Limit       int              `json:"limit"`
+	queryspec.PageInfo
+}
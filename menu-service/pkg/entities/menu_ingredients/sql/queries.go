@@ -2,60 +2,45 @@ package sql
 
 import (
 	"embed"
-	"fmt"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
 )
 
 //go:embed scripts/*.sql
 var sqlScripts embed.FS
 
-// Query names
+// SQL query names
 const (
-	ListMenuIngredientsQuery          = "list_menu_ingredients"
-	GetMenuIngredientByIDQuery        = "get_menu_ingredient_by_id"
-	CreateMenuIngredientQuery         = "create_menu_ingredient"
-	UpdateMenuIngredientQuery         = "update_menu_ingredient"
-	DeleteMenuIngredientQuery         = "delete_menu_ingredient"
-	GetIngredientsByMenuVariantQuery  = "get_ingredients_by_menu_variant"
+	ListMenuIngredientsQuery         queries.Name = "list_menu_ingredients"
+	GetMenuIngredientByIDQuery       queries.Name = "get_menu_ingredient_by_id"
+	CreateMenuIngredientQuery        queries.Name = "create_menu_ingredient"
+	UpdateMenuIngredientQuery        queries.Name = "update_menu_ingredient"
+	DeleteMenuIngredientQuery        queries.Name = "delete_menu_ingredient"
+	GetIngredientsByMenuVariantQuery queries.Name = "get_ingredients_by_menu_variant"
+	CheckDefaultVariantQuery         queries.Name = "check_default_variant"
 )
 
-// Queries holds all loaded SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from embedded files
-func LoadQueries() (*Queries, error) {
-	q := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files := []string{
-		"list_menu_ingredients.sql",
-		"get_menu_ingredient_by_id.sql",
-		"create_menu_ingredient.sql",
-		"update_menu_ingredient.sql",
-		"delete_menu_ingredient.sql",
-		"get_ingredients_by_menu_variant.sql",
-	}
-
-	for _, file := range files {
-		content, err := sqlScripts.ReadFile("scripts/" + file)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read SQL file %s: %w", file, err)
-		}
-		// Remove .sql extension for query name
-		queryName := file[:len(file)-4]
-		q.queries[queryName] = string(content)
-	}
-
-	return q, nil
-}
+// ListSchema whitelists the columns of list_menu_ingredients that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("-created_at",
+	queryspec.Field{Name: "menu_variant_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "stock_variant_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "menu_sub_category_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "quantity", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "is_optional", Type: queryspec.Bool, Filterable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+)
 
-// Get returns a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, ok := q.queries[name]
-	if !ok {
-		return "", fmt.Errorf("query not found: %s", name)
-	}
-	return query, nil
+// LoadQueries loads and validates the menu ingredient SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListMenuIngredientsQuery,
+		GetMenuIngredientByIDQuery,
+		CreateMenuIngredientQuery,
+		UpdateMenuIngredientQuery,
+		DeleteMenuIngredientQuery,
+		GetIngredientsByMenuVariantQuery,
+		CheckDefaultVariantQuery,
+	)
 }
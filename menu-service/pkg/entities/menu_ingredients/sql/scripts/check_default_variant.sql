@@ -0,0 +1,8 @@
+-- Check that a default variant is a live variant of the ingredient's sub-category
+SELECT EXISTS (
+    SELECT 1
+    FROM menu_variants
+    WHERE id = @default_variant_id
+      AND sub_category_id = @menu_sub_category_id
+      AND deleted_at IS NULL
+);
@@ -5,8 +5,11 @@ INSERT INTO menu_ingredients (
     menu_sub_category_id,
     quantity,
     is_optional,
-    notes
-) VALUES ($1, $2, $3, $4, $5, $6)
+    notes,
+    cost_strategy,
+    default_variant_id
+) VALUES (@menu_variant_id, @stock_variant_id, @menu_sub_category_id, @quantity, @is_optional, @notes,
+          @cost_strategy, @default_variant_id)
 RETURNING
     id,
     menu_variant_id,
@@ -15,5 +18,8 @@ RETURNING
     quantity,
     is_optional,
     notes,
+    cost_strategy,
+    default_variant_id,
     created_at,
-    updated_at;
+    updated_at,
+    version;
@@ -1,3 +1,4 @@
 -- Delete menu ingredient
 DELETE FROM menu_ingredients
-WHERE id = $1;
+WHERE id = @id
+RETURNING menu_variant_id;
@@ -1,4 +1,4 @@
--- List menu ingredients with pagination
+-- List menu ingredients
 SELECT
     mi.id,
     mi.menu_variant_id,
@@ -9,10 +9,11 @@ SELECT
     mi.quantity,
     mi.is_optional,
     mi.notes,
+    mi.cost_strategy,
+    mi.default_variant_id,
     mi.created_at,
-    mi.updated_at
+    mi.updated_at,
+    mi.version
 FROM menu_ingredients mi
 LEFT JOIN stock_variants sv ON mi.stock_variant_id = sv.id
-LEFT JOIN menu_sub_categories msc ON mi.menu_sub_category_id = msc.id
-ORDER BY mi.created_at DESC
-LIMIT $1 OFFSET $2;
+LEFT JOIN menu_sub_categories msc ON mi.menu_sub_category_id = msc.id;
@@ -1,11 +1,13 @@
 -- Update menu ingredient
 UPDATE menu_ingredients
 SET
-    quantity = COALESCE($2, quantity),
-    is_optional = COALESCE($3, is_optional),
-    notes = COALESCE($4, notes),
+    quantity = COALESCE(@quantity, quantity),
+    is_optional = COALESCE(@is_optional, is_optional),
+    notes = COALESCE(@notes, notes),
+    cost_strategy = COALESCE(@cost_strategy, cost_strategy),
+    default_variant_id = COALESCE(@default_variant_id, default_variant_id),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
+WHERE id = @id AND version = @version
 RETURNING
     id,
     menu_variant_id,
@@ -14,5 +16,8 @@ RETURNING
     quantity,
     is_optional,
     notes,
+    cost_strategy,
+    default_variant_id,
     created_at,
-    updated_at;
+    updated_at,
+    version;
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"menu-service/pkg/entities/menu_pricing/models"
+	menuPricingSQL "menu-service/pkg/entities/menu_pricing/sql"
+	menuVariantHandlers "menu-service/pkg/entities/menu_variants/handlers"
+	menuVariantModels "menu-service/pkg/entities/menu_variants/models"
+	"shared/audit"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	sharedErrors "shared/errors"
+	"shared/money"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Audit log entities of the target margins, they are columns of the category tables
+const (
+	categoryAuditEntity    = "menu_category"
+	subCategoryAuditEntity = "menu_sub_category"
+)
+
+// DBHandler analyses menu prices against their costs and target margins, and reprices
+// menu variants through the menu variant handler
+type DBHandler struct {
+	db       *sharedDb.DbHandler
+	variants *menuVariantHandlers.DBHandler
+	audit    *audit.Log
+	rates    models.Rates
+	queries  *queries.Registry
+	logger   *logrus.Logger
+}
+
+// NewDBHandler creates a new menu pricing database handler. rates.DefaultMargin is the
+// margin of variants whose category and sub-category set none, rates.TaxRate the tax
+// added for the tax-inclusive prices.
+func NewDBHandler(db *sharedDb.DbHandler, variants *menuVariantHandlers.DBHandler, auditLog *audit.Log, rates models.Rates, logger *logrus.Logger) (*DBHandler, error) {
+	if !validMargin(rates.DefaultMargin) {
+		return nil, fmt.Errorf("invalid default earning margin %s, want at least 0 and below 100", rates.DefaultMargin)
+	}
+	if rates.TaxRate.Sign() < 0 {
+		return nil, fmt.Errorf("invalid default tax rate %s, want at least 0", rates.TaxRate)
+	}
+
+	queries, err := menuPricingSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DBHandler{
+		db:       db,
+		variants: variants,
+		audit:    auditLog,
+		rates:    rates,
+		queries:  queries,
+		logger:   logger,
+	}, nil
+}
+
+// List returns the pricing of the live menu variants matching filter
+func (h *DBHandler) List(ctx context.Context, filter models.PricingFilter) (*models.PricingResponse, error) {
+	all, err := h.pricing(ctx, filter.CategoryID, filter.SubCategoryID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := []models.VariantPricing{}
+	for i := range all {
+		if filter.Keep(&all[i]) {
+			variants = append(variants, all[i])
+		}
+	}
+
+	return &models.PricingResponse{
+		Variants: variants,
+		Total:    len(variants),
+		Rates:    h.rates,
+	}, nil
+}
+
+// Reprice sets the selected menu variants to their suggested price, or only reports the
+// changes when dryRun is set. Variants with an unknown cost and those already at their
+// suggested price are left alone. The changes apply in one transaction through the menu
+// variant handler, so each is audited and publishes a price change, and a variant edited
+// since it was read fails the whole run with a version mismatch.
+func (h *DBHandler) Reprice(ctx context.Context, req *models.RepriceRequest, dryRun bool) (*models.RepriceResponse, error) {
+	if req.TargetMargin != nil && !validMargin(*req.TargetMargin) {
+		return nil, targetMarginError()
+	}
+
+	var response *models.RepriceResponse
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		all, err := h.pricing(ctx, req.CategoryID, req.SubCategoryID, req.TargetMargin)
+		if err != nil {
+			return err
+		}
+
+		selected := make(map[string]bool, len(req.MenuVariantIDs))
+		for _, id := range req.MenuVariantIDs {
+			selected[id] = true
+		}
+
+		response = &models.RepriceResponse{Changes: []models.PriceChange{}}
+		for _, variant := range all {
+			if len(selected) > 0 && !selected[variant.MenuVariantID] {
+				continue
+			}
+			if req.OnlyBelowTarget && !variant.BelowTarget {
+				continue
+			}
+			if variant.SuggestedPrice == nil {
+				response.UnknownCost++
+				continue
+			}
+			if variant.SuggestedPrice.Equal(variant.Price) {
+				response.Unchanged++
+				continue
+			}
+
+			response.Changes = append(response.Changes, models.PriceChange{
+				MenuVariantID:   variant.MenuVariantID,
+				MenuVariantName: variant.MenuVariantName,
+				ItemCost:        *variant.ItemCost,
+				OldPrice:        variant.Price,
+				NewPrice:        *variant.SuggestedPrice,
+				NewPriceWithTax: *variant.SuggestedPriceWithTax,
+				OldMargin:       variant.Margin,
+				NewMargin:       models.MarginOf(*variant.ItemCost, *variant.SuggestedPrice),
+				TargetMargin:    variant.TargetMargin,
+			})
+			if dryRun {
+				continue
+			}
+
+			_, err := h.variants.Update(ctx, variant.MenuVariantID, variant.Version, &menuVariantModels.MenuVariantUpdateRequest{
+				Price: variant.SuggestedPrice,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to reprice menu variant %s: %w", variant.MenuVariantID, err)
+			}
+		}
+		response.Changed = len(response.Changes)
+		response.Applied = !dryRun && response.Changed > 0
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Applied {
+		h.logger.WithFields(logrus.Fields{
+			"changed":      response.Changed,
+			"unchanged":    response.Unchanged,
+			"unknown_cost": response.UnknownCost,
+		}).Info("Menu variants repriced")
+	}
+	return response, nil
+}
+
+// SetCategoryTargetMargin sets the target margin of a menu category, nil to use the
+// default again
+func (h *DBHandler) SetCategoryTargetMargin(ctx context.Context, id string, margin *money.Decimal) (*models.TargetMargin, error) {
+	return h.setTargetMargin(ctx, menuPricingSQL.SetCategoryTargetMarginQuery, categoryAuditEntity, id, margin)
+}
+
+// SetSubCategoryTargetMargin sets the target margin of a menu sub-category, nil to
+// inherit its category's again
+func (h *DBHandler) SetSubCategoryTargetMargin(ctx context.Context, id string, margin *money.Decimal) (*models.TargetMargin, error) {
+	return h.setTargetMargin(ctx, menuPricingSQL.SetSubCategoryTargetMarginQuery, subCategoryAuditEntity, id, margin)
+}
+
+// setTargetMargin stores a target margin with the query of its table and audits the change
+func (h *DBHandler) setTargetMargin(ctx context.Context, query queries.Name, entity, id string, margin *money.Decimal) (*models.TargetMargin, error) {
+	if margin != nil && !validMargin(*margin) {
+		return nil, targetMarginError()
+	}
+
+	var after *models.TargetMargin
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		var before models.TargetMargin
+		after = &models.TargetMargin{}
+		err := h.db.QueryRowNamedContext(ctx, h.queries.Get(query), queries.Args{
+			"id":            id,
+			"target_margin": margin,
+		}).Scan(&after.ID, &after.Name, &before.TargetMargin, &after.TargetMargin)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return sharedErrors.NotFound(entity+"_not_found", fmt.Sprintf("%s not found", entityLabel(entity)))
+			}
+			return fmt.Errorf("failed to set %s target margin: %w", entityLabel(entity), err)
+		}
+		before.ID, before.Name = after.ID, after.Name
+
+		return h.audit.Record(ctx, entity, audit.ActionUpdate, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// pricing reads the live menu variants in the category and sub-category, when set, and
+// analyses their prices. override replaces every variant's target margin when set.
+func (h *DBHandler) pricing(ctx context.Context, categoryID, subCategoryID *string, override *money.Decimal) ([]models.VariantPricing, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(menuPricingSQL.ListMenuPricingQuery), queries.Args{
+		"category_id":     categoryID,
+		"sub_category_id": subCategoryID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list menu pricing: %w", err)
+	}
+	defer rows.Close()
+
+	variants := []models.VariantPricing{}
+	for rows.Next() {
+		var v models.VariantPricing
+		var subCategoryTarget, categoryTarget *money.Decimal
+		if err := rows.Scan(
+			&v.MenuVariantID, &v.MenuVariantName, &v.SubCategoryID, &v.SubCategoryName, &v.CategoryID, &v.CategoryName,
+			&v.ItemCost, &v.Price, &v.Version, &subCategoryTarget, &categoryTarget,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan menu pricing: %w", err)
+		}
+
+		if override != nil {
+			subCategoryTarget = override
+		}
+		v.Analyse(subCategoryTarget, categoryTarget, h.rates)
+		variants = append(variants, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating menu pricing: %w", err)
+	}
+	return variants, nil
+}
+
+// validMargin reports whether a margin is at least 0 and below 100 percent
+func validMargin(margin money.Decimal) bool {
+	return margin.Sign() >= 0 && margin.Cmp(models.MaxMargin) < 0
+}
+
+func targetMarginError() error {
+	return sharedErrors.Validation("invalid_target_margin", "invalid target margin",
+		sharedErrors.FieldError{Field: "target_margin", Code: "out_of_range", Message: "target_margin must be at least 0 and below 100"})
+}
+
+// entityLabel names an audit entity in messages, e.g. "menu sub-category"
+func entityLabel(entity string) string {
+	if entity == subCategoryAuditEntity {
+		return "menu sub-category"
+	}
+	return "menu category"
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"menu-service/pkg/entities/menu_pricing/models"
+	"shared/bulk"
+	sharedErrors "shared/errors"
+	sharedHttp "shared/http"
+	"shared/money"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// repriceRequestTimeout replaces the server write timeout for a repricing, which may
+// update the price of every menu variant
+const repriceRequestTimeout = 10 * time.Minute
+
+// HTTPHandler handles HTTP requests for menu pricing
+type HTTPHandler struct {
+	db     *DBHandler
+	logger *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(db *DBHandler, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// List handles GET /api/v1/menu/pricing?category_id=&sub_category_id=&below_target=true&margin_below=40
+func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "")
+		return
+	}
+
+	response, err := h.db.List(r.Context(), filter)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list menu pricing")
+		sharedHttp.SendError(w, r, err, "Failed to retrieve menu pricing")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu pricing retrieved", response)
+}
+
+// Reprice handles POST /api/v1/menu/pricing/reprice?dry_run=true, it sets the selected
+// menu variants to their suggested price
+func (h *HTTPHandler) Reprice(w http.ResponseWriter, r *http.Request) {
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(repriceRequestTimeout))
+
+	dryRun, err := bulk.DryRun(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "")
+		return
+	}
+
+	var req models.RepriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	response, err := h.db.Reprice(r.Context(), &req, dryRun)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to reprice menu variants")
+		sharedHttp.SendError(w, r, err, "Failed to reprice menu variants")
+		return
+	}
+
+	message := "Menu variants repriced"
+	if dryRun {
+		message = "Menu repricing previewed"
+	}
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, message, response)
+}
+
+// SetCategoryTargetMargin handles PUT /api/v1/menu/pricing/targets/categories/{id}
+func (h *HTTPHandler) SetCategoryTargetMargin(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req models.TargetMarginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	target, err := h.db.SetCategoryTargetMargin(r.Context(), id, req.TargetMargin)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to set menu category target margin")
+		sharedHttp.SendError(w, r, err, "Failed to set menu category target margin")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu category target margin updated", target)
+}
+
+// SetSubCategoryTargetMargin handles PUT /api/v1/menu/pricing/targets/sub-categories/{id}
+func (h *HTTPHandler) SetSubCategoryTargetMargin(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req models.TargetMarginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	target, err := h.db.SetSubCategoryTargetMargin(r.Context(), id, req.TargetMargin)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to set menu sub-category target margin")
+		sharedHttp.SendError(w, r, err, "Failed to set menu sub-category target margin")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu sub-category target margin updated", target)
+}
+
+// parseFilter reads the pricing report filters from the query string
+func parseFilter(r *http.Request) (models.PricingFilter, error) {
+	query := r.URL.Query()
+	var filter models.PricingFilter
+	var fieldErrors []sharedErrors.FieldError
+
+	if value := query.Get("category_id"); value != "" {
+		filter.CategoryID = &value
+	}
+	if value := query.Get("sub_category_id"); value != "" {
+		filter.SubCategoryID = &value
+	}
+	if value := query.Get("below_target"); value != "" {
+		belowTarget, err := strconv.ParseBool(value)
+		if err != nil {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "below_target", Code: "invalid_boolean", Message: "must be true or false"})
+		}
+		filter.BelowTarget = belowTarget
+	}
+	if value := query.Get("margin_below"); value != "" {
+		margin, err := money.ParseDecimal(value)
+		if err != nil {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "margin_below", Code: "invalid_number", Message: "must be a number"})
+		}
+		filter.MarginBelow = &margin
+	}
+
+	if len(fieldErrors) > 0 {
+		return filter, sharedErrors.Validation("invalid_query", "invalid menu pricing parameters", fieldErrors...)
+	}
+	return filter, nil
+}
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"shared/money"
+)
+
+// Where a variant's target margin comes from
+const (
+	TargetSubCategory = "sub_category"
+	TargetCategory    = "category"
+	TargetDefault     = "default"
+)
+
+// MaxMargin bounds target margins: a margin is a share of the price, so 100% or more
+// has no price
+var MaxMargin = money.DecimalFromInt(100)
+
+// Rates are the settings prices are analysed with, both percentages
+type Rates struct {
+	DefaultMargin money.Decimal `json:"default_margin"`
+	TaxRate       money.Decimal `json:"tax_rate"`
+}
+
+// VariantPricing is the cost, price and margin of a menu variant. Margin and the
+// suggested prices are nil while the variant's cost is unknown.
+type VariantPricing struct {
+	MenuVariantID         string         `json:"menu_variant_id"`
+	MenuVariantName       string         `json:"menu_variant_name"`
+	SubCategoryID         string         `json:"sub_category_id"`
+	SubCategoryName       string         `json:"sub_category_name"`
+	CategoryID            string         `json:"category_id"`
+	CategoryName          string         `json:"category_name"`
+	ItemCost              *money.Money   `json:"item_cost"`
+	Price                 money.Money    `json:"price"`
+	PriceWithTax          money.Money    `json:"price_with_tax"`
+	Margin                *money.Decimal `json:"margin"`
+	TargetMargin          money.Decimal  `json:"target_margin"`
+	TargetSource          string         `json:"target_source"`
+	SuggestedPrice        *money.Money   `json:"suggested_price"`
+	SuggestedPriceWithTax *money.Money   `json:"suggested_price_with_tax"`
+	BelowTarget           bool           `json:"below_target"`
+	Version               int            `json:"version"`
+}
+
+// Analyse fills in the margin and suggested prices of a variant from its cost and price.
+// The target margin is the sub-category's, else the category's, else the default. The
+// margin is a percentage of the price, (price - cost) / price, and the suggested price is
+// the one that earns the target margin, cost / (1 - target), in whole charged units.
+func (v *VariantPricing) Analyse(subCategoryTarget, categoryTarget *money.Decimal, rates Rates) {
+	switch {
+	case subCategoryTarget != nil:
+		v.TargetMargin, v.TargetSource = *subCategoryTarget, TargetSubCategory
+	case categoryTarget != nil:
+		v.TargetMargin, v.TargetSource = *categoryTarget, TargetCategory
+	default:
+		v.TargetMargin, v.TargetSource = rates.DefaultMargin, TargetDefault
+	}
+
+	v.PriceWithTax = WithTax(v.Price, rates.TaxRate)
+	v.Margin, v.SuggestedPrice, v.SuggestedPriceWithTax, v.BelowTarget = nil, nil, nil, false
+	if v.ItemCost == nil {
+		return
+	}
+
+	v.Margin = MarginOf(*v.ItemCost, v.Price)
+	v.BelowTarget = v.Margin != nil && v.Margin.Cmp(v.TargetMargin) < 0
+
+	suggested, ok := PriceFor(*v.ItemCost, v.TargetMargin)
+	if !ok {
+		return
+	}
+	suggestedWithTax := WithTax(suggested, rates.TaxRate)
+	v.SuggestedPrice, v.SuggestedPriceWithTax = &suggested, &suggestedWithTax
+}
+
+// MarginOf returns the margin a price earns over a cost as a percentage of the price,
+// rounded to two decimals, nil for a price of 0
+func MarginOf(cost, price money.Money) *money.Decimal {
+	if price.Sign() <= 0 {
+		return nil
+	}
+	margin, err := price.Sub(cost).Amount().Mul(money.DecimalFromInt(100)).Div(price.Amount())
+	if err != nil {
+		return nil
+	}
+	margin = margin.Round(2)
+	return &margin
+}
+
+// PriceFor returns the price that earns margin over cost, rounded to whole charged units;
+// false when the margin leaves no price
+func PriceFor(cost money.Money, margin money.Decimal) (money.Money, bool) {
+	share, err := MaxMargin.Sub(margin).Div(MaxMargin)
+	if err != nil || share.Sign() <= 0 {
+		return money.Money{}, false
+	}
+	price, err := cost.Div(share)
+	if err != nil {
+		return money.Money{}, false
+	}
+	return price.Round(), true
+}
+
+// WithTax returns price plus rate percent of tax, rounded to whole charged units
+func WithTax(price money.Money, rate money.Decimal) money.Money {
+	return price.Add(price.Percent(rate)).Round()
+}
+
+// PricingFilter selects the variants of a pricing report
+type PricingFilter struct {
+	CategoryID    *string
+	SubCategoryID *string
+	// BelowTarget keeps the variants earning less than their target margin
+	BelowTarget bool
+	// MarginBelow keeps the variants earning less than this margin
+	MarginBelow *money.Decimal
+}
+
+// Keep reports whether a variant passes the margin filters; a variant without a known
+// margin never does
+func (f PricingFilter) Keep(v *VariantPricing) bool {
+	if !f.BelowTarget && f.MarginBelow == nil {
+		return true
+	}
+	if v.Margin == nil {
+		return false
+	}
+	if f.BelowTarget && !v.BelowTarget {
+		return false
+	}
+	return f.MarginBelow == nil || v.Margin.Cmp(*f.MarginBelow) < 0
+}
+
+// PricingResponse is a pricing report
+type PricingResponse struct {
+	Variants []VariantPricing `json:"variants"`
+	Total    int              `json:"total"`
+	Rates
+}
+
+// TargetMarginRequest sets the target margin of a category or sub-category, null to
+// inherit again
+type TargetMarginRequest struct {
+	TargetMargin *money.Decimal `json:"target_margin"`
+}
+
+// TargetMargin is the target margin of a category or sub-category
+type TargetMargin struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	TargetMargin *money.Decimal `json:"target_margin"`
+}
+
+// RepriceRequest selects the variants to reprice at their suggested price. Every live
+// variant with a known cost is repriced unless a filter narrows it; TargetMargin
+// replaces the variants' own target margins for this run.
+type RepriceRequest struct {
+	CategoryID      *string        `json:"category_id,omitempty"`
+	SubCategoryID   *string        `json:"sub_category_id,omitempty"`
+	MenuVariantIDs  []string       `json:"menu_variant_ids,omitempty"`
+	OnlyBelowTarget bool           `json:"only_below_target,omitempty"`
+	TargetMargin    *money.Decimal `json:"target_margin,omitempty"`
+}
+
+// PriceChange is the new price of a variant in a repricing
+type PriceChange struct {
+	MenuVariantID   string         `json:"menu_variant_id"`
+	MenuVariantName string         `json:"menu_variant_name"`
+	ItemCost        money.Money    `json:"item_cost"`
+	OldPrice        money.Money    `json:"old_price"`
+	NewPrice        money.Money    `json:"new_price"`
+	NewPriceWithTax money.Money    `json:"new_price_with_tax"`
+	OldMargin       *money.Decimal `json:"old_margin"`
+	NewMargin       *money.Decimal `json:"new_margin"`
+	TargetMargin    money.Decimal  `json:"target_margin"`
+}
+
+// RepriceResponse reports a repricing, Applied is false for a dry run
+type RepriceResponse struct {
+	Changes     []PriceChange `json:"changes"`
+	Changed     int           `json:"changed"`
+	Unchanged   int           `json:"unchanged"`
+	UnknownCost int           `json:"unknown_cost"`
+	Applied     bool          `json:"applied"`
+}
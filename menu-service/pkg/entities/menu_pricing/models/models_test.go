@@ -0,0 +1,100 @@
+package models
+
+import (
+	"testing"
+
+	"shared/money"
+)
+
+var rates = Rates{DefaultMargin: money.MustParseDecimal("30"), TaxRate: money.MustParseDecimal("13")}
+
+func pricing(cost, price string) *VariantPricing {
+	v := &VariantPricing{MenuVariantID: "burger", Price: money.MustParse(price)}
+	if cost != "" {
+		c := money.MustParse(cost)
+		v.ItemCost = &c
+	}
+	return v
+}
+
+func decimal(s string) *money.Decimal {
+	d := money.MustParseDecimal(s)
+	return &d
+}
+
+func TestAnalyse(t *testing.T) {
+	v := pricing("1400", "2000")
+	v.Analyse(nil, nil, rates)
+
+	if v.TargetSource != TargetDefault || v.TargetMargin.Cmp(rates.DefaultMargin) != 0 {
+		t.Errorf("target = %s from %s, want 30 from default", v.TargetMargin, v.TargetSource)
+	}
+	if v.Margin == nil || v.Margin.Cmp(money.MustParseDecimal("30")) != 0 || v.BelowTarget {
+		t.Errorf("margin = %v, below target = %v, want 30 on target", v.Margin, v.BelowTarget)
+	}
+	if !v.PriceWithTax.Equal(money.MustParse("2260")) {
+		t.Errorf("price with tax = %s, want 2260", v.PriceWithTax)
+	}
+	// 1400 / 0.7 = 2000, plus 13% tax
+	if v.SuggestedPrice == nil || !v.SuggestedPrice.Equal(money.MustParse("2000")) || !v.SuggestedPriceWithTax.Equal(money.MustParse("2260")) {
+		t.Errorf("suggested = %v with tax %v, want 2000 and 2260", v.SuggestedPrice, v.SuggestedPriceWithTax)
+	}
+}
+
+func TestAnalyseTargetSources(t *testing.T) {
+	v := pricing("1000", "1500")
+	v.Analyse(nil, decimal("40"), rates)
+	if v.TargetSource != TargetCategory || !v.BelowTarget {
+		t.Errorf("category target: source = %s, below target = %v, want category and below", v.TargetSource, v.BelowTarget)
+	}
+	// 1000 / 0.6 = 1666.67, rounded to whole colones
+	if !v.SuggestedPrice.Equal(money.MustParse("1667")) {
+		t.Errorf("suggested = %s, want 1667", v.SuggestedPrice)
+	}
+
+	v.Analyse(decimal("20"), decimal("40"), rates)
+	if v.TargetSource != TargetSubCategory || v.BelowTarget {
+		t.Errorf("sub-category target: source = %s, below target = %v, want sub_category and on target", v.TargetSource, v.BelowTarget)
+	}
+}
+
+func TestAnalyseUnknownCost(t *testing.T) {
+	v := pricing("", "1500")
+	v.Analyse(nil, nil, rates)
+	if v.Margin != nil || v.SuggestedPrice != nil || v.BelowTarget {
+		t.Errorf("margin = %v, suggested = %v, below = %v, want none for an unknown cost", v.Margin, v.SuggestedPrice, v.BelowTarget)
+	}
+
+	free := pricing("500", "0")
+	free.Analyse(nil, nil, rates)
+	if free.Margin != nil || free.SuggestedPrice == nil {
+		t.Errorf("free variant: margin = %v, suggested = %v, want no margin and a suggestion", free.Margin, free.SuggestedPrice)
+	}
+}
+
+func TestFilterKeep(t *testing.T) {
+	low := pricing("1000", "1200")
+	low.Analyse(nil, nil, rates)
+	high := pricing("1000", "3000")
+	high.Analyse(nil, nil, rates)
+	unknown := pricing("", "1000")
+	unknown.Analyse(nil, nil, rates)
+
+	cases := []struct {
+		name   string
+		filter PricingFilter
+		want   [3]bool
+	}{
+		{"none", PricingFilter{}, [3]bool{true, true, true}},
+		{"below target", PricingFilter{BelowTarget: true}, [3]bool{true, false, false}},
+		{"margin below", PricingFilter{MarginBelow: decimal("70")}, [3]bool{true, true, false}},
+		{"both", PricingFilter{BelowTarget: true, MarginBelow: decimal("10")}, [3]bool{false, false, false}},
+	}
+	for _, c := range cases {
+		for i, v := range []*VariantPricing{low, high, unknown} {
+			if got := c.filter.Keep(v); got != c.want[i] {
+				t.Errorf("%s: Keep(#%d) = %v, want %v", c.name, i, got, c.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	ListMenuPricingQuery            queries.Name = "list_menu_pricing"
+	SetCategoryTargetMarginQuery    queries.Name = "set_category_target_margin"
+	SetSubCategoryTargetMarginQuery queries.Name = "set_sub_category_target_margin"
+)
+
+// LoadQueries loads and validates the menu pricing SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListMenuPricingQuery,
+		SetCategoryTargetMarginQuery,
+		SetSubCategoryTargetMarginQuery,
+	)
+}
@@ -0,0 +1,11 @@
+-- List the cost and price of every live menu variant with the target margins of its
+-- sub-category and category
+SELECT mv.id, mv.name, msc.id, msc.name, mc.id, mc.name,
+       mv.item_cost, mv.price, mv.version, msc.target_margin, mc.target_margin
+FROM menu_variants mv
+JOIN menu_sub_categories msc ON mv.sub_category_id = msc.id
+JOIN menu_categories mc ON msc.category_id = mc.id
+WHERE mv.deleted_at IS NULL
+  AND (@category_id::uuid IS NULL OR mc.id = @category_id)
+  AND (@sub_category_id::uuid IS NULL OR msc.id = @sub_category_id)
+ORDER BY mc.display_order, mc.name, msc.display_order, msc.name, mv.display_order, mv.name, mv.id;
@@ -0,0 +1,7 @@
+-- Set the target margin of a menu category, returning the one it replaced
+UPDATE menu_categories mc
+SET target_margin = @target_margin,
+    updated_at = CURRENT_TIMESTAMP
+FROM (SELECT id, target_margin FROM menu_categories WHERE id = @id AND deleted_at IS NULL FOR UPDATE) old
+WHERE mc.id = old.id
+RETURNING mc.id, mc.name, old.target_margin, mc.target_margin;
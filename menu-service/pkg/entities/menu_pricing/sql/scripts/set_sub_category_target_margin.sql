@@ -0,0 +1,7 @@
+-- Set the target margin of a menu sub-category, returning the one it replaced
+UPDATE menu_sub_categories msc
+SET target_margin = @target_margin,
+    updated_at = CURRENT_TIMESTAMP
+FROM (SELECT id, target_margin FROM menu_sub_categories WHERE id = @id AND deleted_at IS NULL FOR UPDATE) old
+WHERE msc.id = old.id
+RETURNING msc.id, msc.name, old.target_margin, msc.target_margin;
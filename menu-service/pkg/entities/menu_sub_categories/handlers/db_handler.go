@@ -1,24 +1,34 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"menu-service/pkg/entities/menu_sub_categories/models"
 	menuSubCategorySQL "menu-service/pkg/entities/menu_sub_categories/sql"
+	"shared/audit"
 	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+// auditEntity names the entity in the audit log
+const auditEntity = "menu_sub_category"
+
 // DBHandler handles database operations for menu sub-categories
 type DBHandler struct {
 	db      *sharedDb.DbHandler
-	queries *menuSubCategorySQL.Queries
+	audit   *audit.Log
+	queries *queries.Registry
 	logger  *logrus.Logger
 }
 
 // NewDBHandler creates a new database handler
-func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, error) {
+func NewDBHandler(db *sharedDb.DbHandler, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
 	queries, err := menuSubCategorySQL.LoadQueries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
@@ -26,31 +36,30 @@ func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, er
 
 	return &DBHandler{
 		db:      db,
+		audit:   auditLog,
 		queries: queries,
 		logger:  logger,
 	}, nil
 }
 
-// List returns a paginated list of sub menus
-func (h *DBHandler) List(req *models.MenuSubCategoryListRequest) (*models.MenuSubCategoryListResponse, error) {
-	offset := (req.Page - 1) * req.Limit
-
-	countQuery, err := h.queries.Get(menuSubCategorySQL.CountMenuSubCategoriesQuery)
+// List returns a page of sub menus matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.MenuSubCategoryListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(menuSubCategorySQL.ListMenuSubCategoriesQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get count query: %w", err)
+		return nil, fmt.Errorf("failed to build sub menus count: %w", err)
 	}
 
 	var total int
-	if err := h.db.QueryRow(countQuery, req.CategoryID, req.ItemType, req.IsActive).Scan(&total); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
 		return nil, fmt.Errorf("failed to count sub menus: %w", err)
 	}
 
-	listQuery, err := h.queries.Get(menuSubCategorySQL.ListMenuSubCategoriesQuery)
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(menuSubCategorySQL.ListMenuSubCategoriesQuery), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
+		return nil, fmt.Errorf("failed to build sub menus list: %w", err)
 	}
 
-	rows, err := h.db.Query(listQuery, req.CategoryID, req.ItemType, req.IsActive, req.Limit, offset)
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list sub menus: %w", err)
 	}
@@ -65,91 +74,148 @@ func (h *DBHandler) List(req *models.MenuSubCategoryListRequest) (*models.MenuSu
 		subMenus = append(subMenus, *subMenu)
 	}
 
+	subMenus, pageInfo, err := queryspec.Paginate(spec, subMenus)
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.MenuSubCategoryListResponse{
 		SubCategories: subMenus,
 		Total:         total,
-		Page:          req.Page,
-		Limit:         req.Limit,
+		Limit:         spec.Limit,
+		PageInfo:      pageInfo,
 	}, nil
 }
 
 // GetByID returns a sub menu by ID
-func (h *DBHandler) GetByID(id string) (*models.MenuSubCategory, error) {
-	query, err := h.queries.Get(menuSubCategorySQL.GetMenuSubCategoryByIDQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
-	row := h.db.QueryRow(query, id)
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.MenuSubCategory, error) {
+	row := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuSubCategorySQL.GetMenuSubCategoryByIDQuery), queries.Args{"id": id})
 	return h.scanMenuSubCategoryRow(row)
 }
 
 // Create creates a new sub menu
-func (h *DBHandler) Create(req *models.MenuSubCategoryCreateRequest) (*models.MenuSubCategory, error) {
-	query, err := h.queries.Get(menuSubCategorySQL.CreateMenuSubCategoryQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+func (h *DBHandler) Create(ctx context.Context, req *models.MenuSubCategoryCreateRequest) (*models.MenuSubCategory, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.MenuSubCategory, error) {
+		return h.create(ctx, req)
+	})
+}
 
-	row := h.db.QueryRow(query,
-		req.Name, req.Description, req.CategoryID,
-		req.ItemType, req.DisplayOrder, req.IsActive,
-	)
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.MenuSubCategoryCreateRequest) (*models.MenuSubCategory, error) {
+	row := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuSubCategorySQL.CreateMenuSubCategoryQuery), queries.Args{
+		"name":          req.Name,
+		"description":   req.Description,
+		"category_id":   req.CategoryID,
+		"item_type":     req.ItemType,
+		"display_order": req.DisplayOrder,
+		"is_active":     req.IsActive,
+	})
 
 	return h.scanMenuSubCategoryRowWithoutCategory(row)
 }
 
-// Update updates an existing sub menu
-func (h *DBHandler) Update(id string, req *models.MenuSubCategoryUpdateRequest) (*models.MenuSubCategory, error) {
-	query, err := h.queries.Get(menuSubCategorySQL.UpdateMenuSubCategoryQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+// Update updates an existing sub menu if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.MenuSubCategoryUpdateRequest) (*models.MenuSubCategory, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.MenuSubCategory, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
 
-	row := h.db.QueryRow(query, id,
-		req.Name, req.Description, req.CategoryID,
-		req.ItemType, req.DisplayOrder, req.IsActive,
-	)
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.MenuSubCategoryUpdateRequest) (*models.MenuSubCategory, error) {
+	row := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuSubCategorySQL.UpdateMenuSubCategoryQuery), queries.Args{
+		"id":            id,
+		"version":       version,
+		"name":          req.Name,
+		"description":   req.Description,
+		"category_id":   req.CategoryID,
+		"item_type":     req.ItemType,
+		"display_order": req.DisplayOrder,
+		"is_active":     req.IsActive,
+	})
+
+	subMenu, err := h.scanMenuSubCategoryRowWithoutCategory(row)
+	if err != nil || subMenu != nil {
+		return subMenu, err
+	}
+	return nil, h.versionMismatch(ctx, id)
+}
 
-	return h.scanMenuSubCategoryRowWithoutCategory(row)
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return err
+	}
+	return sharedErrors.PreconditionFailed("menu_sub_category_version_mismatch", "sub menu was modified by another request")
 }
 
 // Delete deletes a sub menu
-func (h *DBHandler) Delete(id string) error {
-	// Check for dependencies first
-	checkQuery, err := h.queries.Get(menuSubCategorySQL.CheckMenuSubCategoryDependenciesQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get check query: %w", err)
-	}
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
 
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
+	// Check for dependencies first
 	var count int
-	if err := h.db.QueryRow(checkQuery, id).Scan(&count); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuSubCategorySQL.CheckMenuSubCategoryDependenciesQuery), queries.Args{"id": id}).Scan(&count); err != nil {
 		return fmt.Errorf("failed to check dependencies: %w", err)
 	}
 
 	if count > 0 {
-		return fmt.Errorf("cannot delete sub menu: it has %d menu items", count)
-	}
-
-	deleteQuery, err := h.queries.Get(menuSubCategorySQL.DeleteMenuSubCategoryQuery)
-	if err != nil {
-		return fmt.Errorf("failed to get delete query: %w", err)
+		return sharedErrors.Conflict("menu_sub_category_in_use", fmt.Sprintf("cannot delete sub menu: it has %d menu items", count))
 	}
 
-	result, err := h.db.Exec(deleteQuery, id)
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(menuSubCategorySQL.DeleteMenuSubCategoryQuery), queries.Args{"id": id})
 	if err != nil {
 		return fmt.Errorf("failed to delete sub menu: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("sub menu not found")
+		return sharedErrors.NotFound("menu_sub_category_not_found", "sub menu not found")
 	}
 
 	h.logger.WithField("id", id).Info("Sub menu deleted")
 	return nil
 }
 
+// Restore brings back a soft deleted sub menu
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.MenuSubCategory, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.MenuSubCategory, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.MenuSubCategory, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(menuSubCategorySQL.RestoreMenuSubCategoryQuery), queries.Args{"id": id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore sub menu: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("menu_sub_category_not_found", "deleted sub menu not found")
+	}
+
+	h.logger.WithField("id", id).Info("Sub menu restored")
+	return h.GetByID(ctx, id)
+}
+
+// Purge hard deletes the sub menus soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuSubCategorySQL.PurgeMenuSubCategoriesQuery), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge sub menus: %w", err)
+	}
+	return purged, nil
+}
+
 // Helper functions for scanning
 func (h *DBHandler) scanMenuSubCategory(rows *sql.Rows) (*models.MenuSubCategory, error) {
 	var subMenu models.MenuSubCategory
@@ -158,7 +224,7 @@ func (h *DBHandler) scanMenuSubCategory(rows *sql.Rows) (*models.MenuSubCategory
 	err := rows.Scan(
 		&subMenu.ID, &subMenu.Name, &description, &subMenu.CategoryID, &categoryName,
 		&subMenu.ItemType, &subMenu.DisplayOrder, &subMenu.IsActive,
-		&subMenu.CreatedAt, &subMenu.UpdatedAt,
+		&subMenu.CreatedAt, &subMenu.UpdatedAt, &subMenu.Version, &subMenu.DeletedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan sub menu: %w", err)
@@ -174,14 +240,14 @@ func (h *DBHandler) scanMenuSubCategory(rows *sql.Rows) (*models.MenuSubCategory
 	return &subMenu, nil
 }
 
-func (h *DBHandler) scanMenuSubCategoryRow(row *sql.Row) (*models.MenuSubCategory, error) {
+func (h *DBHandler) scanMenuSubCategoryRow(row *sharedDb.Row) (*models.MenuSubCategory, error) {
 	var subMenu models.MenuSubCategory
 	var description, categoryName sql.NullString
 
 	err := row.Scan(
 		&subMenu.ID, &subMenu.Name, &description, &subMenu.CategoryID, &categoryName,
 		&subMenu.ItemType, &subMenu.DisplayOrder, &subMenu.IsActive,
-		&subMenu.CreatedAt, &subMenu.UpdatedAt,
+		&subMenu.CreatedAt, &subMenu.UpdatedAt, &subMenu.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -200,14 +266,14 @@ func (h *DBHandler) scanMenuSubCategoryRow(row *sql.Row) (*models.MenuSubCategor
 	return &subMenu, nil
 }
 
-func (h *DBHandler) scanMenuSubCategoryRowWithoutCategory(row *sql.Row) (*models.MenuSubCategory, error) {
+func (h *DBHandler) scanMenuSubCategoryRowWithoutCategory(row *sharedDb.Row) (*models.MenuSubCategory, error) {
 	var subMenu models.MenuSubCategory
 	var description sql.NullString
 
 	err := row.Scan(
 		&subMenu.ID, &subMenu.Name, &description, &subMenu.CategoryID,
 		&subMenu.ItemType, &subMenu.DisplayOrder, &subMenu.IsActive,
-		&subMenu.CreatedAt, &subMenu.UpdatedAt,
+		&subMenu.CreatedAt, &subMenu.UpdatedAt, &subMenu.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
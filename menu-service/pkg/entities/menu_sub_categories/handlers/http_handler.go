@@ -3,9 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"menu-service/pkg/entities/menu_sub_categories/models"
+	menuSubCategorySQL "menu-service/pkg/entities/menu_sub_categories/sql"
 	sharedHttp "shared/http"
 
 	"github.com/gorilla/mux"
@@ -28,39 +28,19 @@ func NewHTTPHandler(db *DBHandler, logger *logrus.Logger) *HTTPHandler {
 
 // List handles GET /api/v1/menu/submenus
 func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 20
-	}
-
-	req := &models.MenuSubCategoryListRequest{
-		Page:  page,
-		Limit: limit,
-	}
-
-	// Parse optional filters
-	if categoryID := r.URL.Query().Get("category_id"); categoryID != "" {
-		req.CategoryID = &categoryID
-	}
-	if itemType := r.URL.Query().Get("item_type"); itemType != "" {
-		req.ItemType = &itemType
-	}
-	if isActiveStr := r.URL.Query().Get("is_active"); isActiveStr != "" {
-		isActive := isActiveStr == "true"
-		req.IsActive = &isActive
+	spec, err := menuSubCategorySQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
 	}
 
-	response, err := h.db.List(req)
+	response, err := h.db.List(r.Context(), spec)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list sub menus")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list sub menus")
+		sharedHttp.SendError(w, r, err, "Failed to list sub menus")
 		return
 	}
+	response.SetLinks(r.URL)
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Sub menus retrieved", response)
 }
@@ -70,10 +50,10 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	subMenu, err := h.db.GetByID(id)
+	subMenu, err := h.db.GetByID(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get sub menu")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to get sub menu")
+		sharedHttp.SendError(w, r, err, "Failed to get sub menu")
 		return
 	}
 
@@ -82,6 +62,7 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, subMenu.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Sub menu retrieved", subMenu)
 }
 
@@ -112,13 +93,14 @@ func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	subMenu, err := h.db.Create(&req)
+	subMenu, err := h.db.Create(r.Context(), &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create sub menu")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to create sub menu")
+		sharedHttp.SendError(w, r, err, "Failed to create sub menu")
 		return
 	}
 
+	sharedHttp.SetETag(w, subMenu.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Sub menu created", subMenu)
 }
 
@@ -127,6 +109,12 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update sub menu")
+		return
+	}
+
 	var req models.MenuSubCategoryUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode request body")
@@ -140,10 +128,10 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	subMenu, err := h.db.Update(id, &req)
+	subMenu, err := h.db.Update(r.Context(), id, version, &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update sub menu")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update sub menu")
+		sharedHttp.SendError(w, r, err, "Failed to update sub menu")
 		return
 	}
 
@@ -152,6 +140,7 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, subMenu.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Sub menu updated", subMenu)
 }
 
@@ -160,16 +149,28 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.db.Delete(id)
+	err := h.db.Delete(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete sub menu")
-		if err.Error() == "sub menu not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Sub menu not found")
-			return
-		}
-		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		sharedHttp.SendError(w, r, err, "Failed to delete sub menu")
 		return
 	}
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Sub menu deleted", nil)
 }
+
+// Restore handles POST /api/v1/menu/sub-categories/{id}/restore
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	subCategory, err := h.db.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore sub menu")
+		sharedHttp.SendError(w, r, err, "Failed to restore sub menu")
+		return
+	}
+
+	sharedHttp.SetETag(w, subCategory.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Sub menu restored", subCategory)
+}
@@ -2,62 +2,47 @@ package sql
 
 import (
 	"embed"
-	"fmt"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
 )
 
 //go:embed scripts/*.sql
 var sqlScripts embed.FS
 
-// Query names
+// SQL query names
 const (
-	ListMenuSubCategoriesQuery      = "list_menu_sub_categories"
-	CountMenuSubCategoriesQuery     = "count_menu_sub_categories"
-	GetMenuSubCategoryByIDQuery     = "get_menu_sub_category_by_id"
-	CreateMenuSubCategoryQuery      = "create_menu_sub_category"
-	UpdateMenuSubCategoryQuery      = "update_menu_sub_category"
-	DeleteMenuSubCategoryQuery      = "delete_menu_sub_category"
-	CheckMenuSubCategoryDependenciesQuery = "check_menu_sub_category_dependencies"
+	ListMenuSubCategoriesQuery            queries.Name = "list_menu_sub_categories"
+	GetMenuSubCategoryByIDQuery           queries.Name = "get_menu_sub_category_by_id"
+	CreateMenuSubCategoryQuery            queries.Name = "create_menu_sub_category"
+	UpdateMenuSubCategoryQuery            queries.Name = "update_menu_sub_category"
+	DeleteMenuSubCategoryQuery            queries.Name = "delete_menu_sub_category"
+	RestoreMenuSubCategoryQuery           queries.Name = "restore_menu_sub_category"
+	PurgeMenuSubCategoriesQuery           queries.Name = "purge_menu_sub_categories"
+	CheckMenuSubCategoryDependenciesQuery queries.Name = "check_menu_sub_category_dependencies"
 )
 
-// Queries holds all loaded SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from embedded files
-func LoadQueries() (*Queries, error) {
-	q := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files := []string{
-		"list_menu_sub_categories.sql",
-		"count_menu_sub_categories.sql",
-		"get_menu_sub_category_by_id.sql",
-		"create_menu_sub_category.sql",
-		"update_menu_sub_category.sql",
-		"delete_menu_sub_category.sql",
-		"check_menu_sub_category_dependencies.sql",
-	}
-
-	for _, file := range files {
-		content, err := sqlScripts.ReadFile("scripts/" + file)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read SQL file %s: %w", file, err)
-		}
-		// Remove .sql extension for query name
-		queryName := file[:len(file)-4]
-		q.queries[queryName] = string(content)
-	}
-
-	return q, nil
-}
-
-// Get returns a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, ok := q.queries[name]
-	if !ok {
-		return "", fmt.Errorf("query not found: %s", name)
-	}
-	return query, nil
+// ListSchema whitelists the columns of list_menu_sub_categories that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("display_order,name",
+	queryspec.Field{Name: "name", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "category_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "item_type", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "is_active", Type: queryspec.Bool, Filterable: true},
+	queryspec.Field{Name: "display_order", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).WithSoftDelete()
+
+// LoadQueries loads and validates the menu sub-category SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListMenuSubCategoriesQuery,
+		GetMenuSubCategoryByIDQuery,
+		CreateMenuSubCategoryQuery,
+		UpdateMenuSubCategoryQuery,
+		DeleteMenuSubCategoryQuery,
+		RestoreMenuSubCategoryQuery,
+		PurgeMenuSubCategoriesQuery,
+		CheckMenuSubCategoryDependenciesQuery,
+	)
 }
@@ -1,2 +1,2 @@
 -- Check if sub-category has any menu variants
-SELECT COUNT(*) as count FROM menu_variants WHERE sub_category_id = $1;
+SELECT COUNT(*) as count FROM menu_variants WHERE sub_category_id = @id AND deleted_at IS NULL;
@@ -8,7 +8,8 @@ SELECT
     sm.display_order,
     sm.is_active,
     sm.created_at,
-    sm.updated_at
+    sm.updated_at,
+    sm.version
 FROM menu_sub_categories sm
 LEFT JOIN menu_categories mc ON sm.category_id = mc.id
-WHERE sm.id = $1;
+WHERE sm.id = @id AND sm.deleted_at IS NULL;
@@ -0,0 +1,9 @@
+-- Hard delete sub-categories soft deleted before the retention window that nothing references
+WITH purged AS (
+    DELETE FROM menu_sub_categories sm
+    WHERE sm.deleted_at < @deleted_before
+      AND NOT EXISTS (SELECT 1 FROM menu_variants mv WHERE mv.sub_category_id = sm.id)
+      AND NOT EXISTS (SELECT 1 FROM menu_ingredients mi WHERE mi.menu_sub_category_id = sm.id)
+    RETURNING sm.id
+)
+SELECT COUNT(*) FROM purged;
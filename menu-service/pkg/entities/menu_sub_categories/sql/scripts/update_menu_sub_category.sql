@@ -1,10 +1,10 @@
 UPDATE menu_sub_categories
 SET
-    name = COALESCE($2, name),
-    description = COALESCE($3, description),
-    category_id = COALESCE($4, category_id),
-    item_type = COALESCE($5, item_type),
-    display_order = COALESCE($6, display_order),
-    is_active = COALESCE($7, is_active)
-WHERE id = $1
-RETURNING id, name, description, category_id, item_type, display_order, is_active, created_at, updated_at;
+    name = COALESCE(@name, name),
+    description = COALESCE(@description, description),
+    category_id = COALESCE(@category_id, category_id),
+    item_type = COALESCE(@item_type, item_type),
+    display_order = COALESCE(@display_order, display_order),
+    is_active = COALESCE(@is_active, is_active)
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id, name, description, category_id, item_type, display_order, is_active, created_at, updated_at, version;
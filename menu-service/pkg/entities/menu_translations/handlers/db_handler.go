@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"menu-service/pkg/entities/menu_translations/models"
+	menuTranslationSQL "menu-service/pkg/entities/menu_translations/sql"
+	"shared/audit"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	sharedErrors "shared/errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// entry is how the translations of one kind of menu entry are stored and audited, they
+// are a column of the entry's table
+type entry struct {
+	query       queries.Name
+	auditEntity string
+	label       string
+}
+
+// entries are the kinds of menu entries that can be translated
+var entries = map[string]entry{
+	models.KindCategory:    {menuTranslationSQL.SetMenuCategoryTranslationsQuery, "menu_category", "menu category"},
+	models.KindSubCategory: {menuTranslationSQL.SetMenuSubCategoryTranslationsQuery, "menu_sub_category", "menu sub-category"},
+	models.KindVariant:     {menuTranslationSQL.SetMenuVariantTranslationsQuery, "menu_variant", "menu variant"},
+}
+
+// DBHandler stores the translations of menu categories, sub-categories and variants
+type DBHandler struct {
+	db      *sharedDb.DbHandler
+	audit   *audit.Log
+	queries *queries.Registry
+	logger  *logrus.Logger
+}
+
+// NewDBHandler creates a new database handler
+func NewDBHandler(db *sharedDb.DbHandler, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
+	queries, err := menuTranslationSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DBHandler{
+		db:      db,
+		audit:   auditLog,
+		queries: queries,
+		logger:  logger,
+	}, nil
+}
+
+// SetTranslations replaces the translations of the menu entry of a kind with an ID and
+// audits the change
+func (h *DBHandler) SetTranslations(ctx context.Context, kind, id string, translations models.Translations) (*models.EntryTranslations, error) {
+	entry, ok := entries[kind]
+	if !ok {
+		return nil, sharedErrors.NotFound("menu_entry_kind_not_found", fmt.Sprintf("%s can't be translated", kind))
+	}
+	translations, err := translations.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(translations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode translations: %w", err)
+	}
+
+	var after *models.EntryTranslations
+	err = h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		var before models.EntryTranslations
+		var oldTranslations, newTranslations []byte
+		after = &models.EntryTranslations{}
+		err := h.db.QueryRowNamedContext(ctx, h.queries.Get(entry.query), queries.Args{
+			"id":           id,
+			"translations": json.RawMessage(encoded),
+		}).Scan(&after.ID, &after.Name, &oldTranslations, &newTranslations)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return sharedErrors.NotFound(entry.auditEntity+"_not_found", fmt.Sprintf("%s not found", entry.label))
+			}
+			return fmt.Errorf("failed to set %s translations: %w", entry.label, err)
+		}
+		if err := json.Unmarshal(oldTranslations, &before.Translations); err != nil {
+			return fmt.Errorf("invalid translations of %s %s: %w", entry.label, id, err)
+		}
+		if err := json.Unmarshal(newTranslations, &after.Translations); err != nil {
+			return fmt.Errorf("invalid translations of %s %s: %w", entry.label, id, err)
+		}
+		before.ID, before.Name = after.ID, after.Name
+
+		return h.audit.Record(ctx, entry.auditEntity, audit.ActionUpdate, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.WithFields(logrus.Fields{"kind": kind, "id": id}).Info("Menu translations updated")
+	return after, nil
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"menu-service/pkg/entities/menu_translations/models"
+	sharedHttp "shared/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPHandler handles HTTP requests for menu translations
+type HTTPHandler struct {
+	dbHandler *DBHandler
+	logger    *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{dbHandler: dbHandler, logger: logger}
+}
+
+// SetTranslations handles PUT /api/v1/menu/translations/{kind}/{id}, kind is categories,
+// sub-categories or variants
+func (h *HTTPHandler) SetTranslations(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req models.TranslationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	translations, err := h.dbHandler.SetTranslations(r.Context(), vars["kind"], vars["id"], req.Translations)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to set menu translations")
+		sharedHttp.SendError(w, r, err, "Failed to set menu translations")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu translations updated", translations)
+}
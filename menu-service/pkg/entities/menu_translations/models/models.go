@@ -0,0 +1,150 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	sharedErrors "shared/errors"
+)
+
+// Kinds of menu entries that can be translated, as used in the URLs
+const (
+	KindCategory    = "categories"
+	KindSubCategory = "sub-categories"
+	KindVariant     = "variants"
+)
+
+// languageTag matches a lowercased language tag like en, es or pt-br
+var languageTag = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// Translation is the name and description of a menu entry in one language
+type Translation struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+}
+
+// Translations are the translations of a menu entry keyed by language tag. The entry's
+// own name and description are in the venue's default language.
+type Translations map[string]Translation
+
+// Normalize returns the translations with lowercased language tags and trimmed names,
+// failing when a tag is not a language tag or a name is empty
+func (t Translations) Normalize() (Translations, error) {
+	normalized := make(Translations, len(t))
+	var fieldErrors []sharedErrors.FieldError
+	for _, tag := range t.Languages() {
+		translation := t[tag]
+		language, ok := ParseLanguage(tag)
+		field := fmt.Sprintf("translations.%s", tag)
+		if !ok {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "invalid_language", Message: "not a language tag like en or pt-br"})
+			continue
+		}
+		translation.Name = strings.TrimSpace(translation.Name)
+		if translation.Name == "" {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "required", Message: "name is required"})
+		}
+		if _, taken := normalized[language]; taken {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "duplicate_language", Message: "the language is translated twice"})
+		}
+		normalized[language] = translation
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, sharedErrors.Validation("invalid_translations", "invalid translations", fieldErrors...)
+	}
+	return normalized, nil
+}
+
+// Languages returns the translated languages in order
+func (t Translations) Languages() []string {
+	languages := make([]string, 0, len(t))
+	for language := range t {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Localize returns the name and description in language, the given ones when it is not
+// translated
+func (t Translations) Localize(language, name string, description *string) (string, *string) {
+	if translation, ok := t[language]; ok {
+		return translation.Name, translation.Description
+	}
+	return name, description
+}
+
+// ParseLanguage returns the lowercased form of a language tag, false when it isn't one
+func ParseLanguage(tag string) (string, bool) {
+	language := strings.ToLower(strings.TrimSpace(tag))
+	return language, languageTag.MatchString(language)
+}
+
+// Preferred returns the languages asked for in preference order: lang first, then those
+// of an Accept-Language header by quality. Tags that are not languages are skipped.
+func Preferred(lang, acceptLanguage string) []string {
+	var languages []string
+	if language, ok := ParseLanguage(lang); ok {
+		languages = append(languages, language)
+	}
+
+	type weighted struct {
+		language string
+		quality  float64
+	}
+	var accepted []weighted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if language, ok := ParseLanguage(tag); ok && quality > 0 {
+			accepted = append(accepted, weighted{language, quality})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].quality > accepted[j].quality })
+	for _, a := range accepted {
+		languages = append(languages, a.language)
+	}
+	return languages
+}
+
+// Negotiate picks the first preferred language that is the default or available, trying
+// a regional tag's base language too (en for en-us), and the default when none is
+func Negotiate(preferred, available []string, defaultLanguage string) string {
+	offered := map[string]bool{defaultLanguage: true}
+	for _, language := range available {
+		offered[language] = true
+	}
+	for _, language := range preferred {
+		if offered[language] {
+			return language
+		}
+		if base, _, regional := strings.Cut(language, "-"); regional && offered[base] {
+			return base
+		}
+	}
+	return defaultLanguage
+}
+
+// TranslationsRequest replaces the translations of a menu entry, an empty object removes
+// them all
+type TranslationsRequest struct {
+	Translations Translations `json:"translations"`
+}
+
+// EntryTranslations are the translations of a menu category, sub-category or variant
+type EntryTranslations struct {
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	Translations Translations `json:"translations"`
+}
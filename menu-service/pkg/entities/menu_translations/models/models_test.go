@@ -0,0 +1,77 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	got, err := Translations{"EN": {Name: " Banana smoothie "}, "pt-BR": {Name: "Vitamina de banana"}}.Normalize()
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	want := Translations{"en": {Name: "Banana smoothie"}, "pt-br": {Name: "Vitamina de banana"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []Translations{
+		{"english": {Name: "Banana smoothie"}},
+		{"en": {Name: " "}},
+		{"en": {Name: "Banana smoothie"}, "EN": {Name: "Banana shake"}},
+	} {
+		if _, err := bad.Normalize(); err == nil {
+			t.Errorf("Normalize(%v) = nil error, want one", bad)
+		}
+	}
+}
+
+func TestPreferred(t *testing.T) {
+	tests := []struct {
+		lang, acceptLanguage string
+		want                 []string
+	}{
+		{"", "", nil},
+		{"FR", "", []string{"fr"}},
+		{"", "en-US,en;q=0.9,es;q=0.8", []string{"en-us", "en", "es"}},
+		{"de", "es;q=0.5, en;q=0.9, *;q=0.1, it;q=0", []string{"de", "en", "es"}},
+		{"not a tag", "en;q=oops, fr", []string{"fr"}},
+	}
+	for _, tt := range tests {
+		if got := Preferred(tt.lang, tt.acceptLanguage); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Preferred(%q, %q) = %v, want %v", tt.lang, tt.acceptLanguage, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	available := []string{"en", "fr"}
+	tests := []struct {
+		preferred []string
+		want      string
+	}{
+		{nil, "es"},
+		{[]string{"de"}, "es"},
+		{[]string{"de", "fr"}, "fr"},
+		{[]string{"en-us"}, "en"},
+		{[]string{"es-cr", "en"}, "es"},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.preferred, available, "es"); got != tt.want {
+			t.Errorf("Negotiate(%v) = %q, want %q", tt.preferred, got, tt.want)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	description := "Con leche"
+	translations := Translations{"en": {Name: "Banana smoothie"}}
+
+	name, desc := translations.Localize("en", "Batido de banano", &description)
+	if name != "Banana smoothie" || desc != nil {
+		t.Errorf("Localize(en) = %q, %v, want the English name without a description", name, desc)
+	}
+	if name, desc := translations.Localize("es", "Batido de banano", &description); name != "Batido de banano" || desc != &description {
+		t.Errorf("Localize(es) = %q, %v, want the entry's own name and description", name, desc)
+	}
+}
@@ -0,0 +1,26 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	SetMenuCategoryTranslationsQuery    queries.Name = "set_menu_category_translations"
+	SetMenuSubCategoryTranslationsQuery queries.Name = "set_menu_sub_category_translations"
+	SetMenuVariantTranslationsQuery     queries.Name = "set_menu_variant_translations"
+)
+
+// LoadQueries loads and validates the menu translation SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		SetMenuCategoryTranslationsQuery,
+		SetMenuSubCategoryTranslationsQuery,
+		SetMenuVariantTranslationsQuery,
+	)
+}
@@ -0,0 +1,7 @@
+-- Replace the translations of a menu category, returning the ones they replaced
+UPDATE menu_categories e
+SET translations = @translations,
+    updated_at = CURRENT_TIMESTAMP
+FROM (SELECT id, translations FROM menu_categories WHERE id = @id AND deleted_at IS NULL FOR UPDATE) old
+WHERE e.id = old.id
+RETURNING e.id, e.name, old.translations, e.translations;
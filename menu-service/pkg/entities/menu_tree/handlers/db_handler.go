@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	menuTranslationModels "menu-service/pkg/entities/menu_translations/models"
+	menuTreeModels "menu-service/pkg/entities/menu_tree/models"
+	menuTreeSQL "menu-service/pkg/entities/menu_tree/sql"
+	menuVariantHandlers "menu-service/pkg/entities/menu_variants/handlers"
+	menuVariantModels "menu-service/pkg/entities/menu_variants/models"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/money"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DBHandler reads the whole menu as one tree, prices come from the menu variant handler
+type DBHandler struct {
+	db       *sharedDb.DbHandler
+	queries  *queries.Registry
+	variants *menuVariantHandlers.DBHandler
+	logger   *logrus.Logger
+}
+
+// NewDBHandler creates a new database handler
+func NewDBHandler(db *sharedDb.DbHandler, variants *menuVariantHandlers.DBHandler, logger *logrus.Logger) (*DBHandler, error) {
+	queries, err := menuTreeSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DBHandler{
+		db:       db,
+		queries:  queries,
+		variants: variants,
+		logger:   logger,
+	}, nil
+}
+
+// Get returns the menu tree matching the filter with the prices charged at the instant at
+func (h *DBHandler) Get(ctx context.Context, filter *menuTreeModels.Filter, at time.Time) (*menuTreeModels.MenuTree, error) {
+	args := queries.Args{"menu_type": nil, "item_type": nil, "available_only": filter.AvailableOnly}
+	if filter.MenuType != "" {
+		args["menu_type"] = filter.MenuType
+	}
+	if filter.ItemType != "" {
+		args["item_type"] = filter.ItemType
+	}
+
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(menuTreeSQL.GetMenuTreeQuery), args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get menu tree: %w", err)
+	}
+	defer rows.Close()
+
+	var treeRows []menuTreeModels.Row
+	var variants []*menuVariantModels.MenuVariant
+	var variantTranslations []menuTranslationModels.Translations
+	for rows.Next() {
+		row, variant, translations, err := scanRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan menu tree row: %w", err)
+		}
+		treeRows = append(treeRows, row)
+		variants = append(variants, variant)
+		variantTranslations = append(variantTranslations, translations)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating menu tree: %w", err)
+	}
+	rows.Close()
+
+	var priced []*menuVariantModels.MenuVariant
+	for _, variant := range variants {
+		if variant != nil {
+			priced = append(priced, variant)
+		}
+	}
+	if err := h.variants.ApplyEffectivePrices(ctx, at, priced...); err != nil {
+		return nil, err
+	}
+	for i, variant := range variants {
+		if variant != nil {
+			entry := menuTreeModels.NewVariant(variant, variantTranslations[i])
+			treeRows[i].Variant = &entry
+		}
+	}
+
+	return menuTreeModels.Build(treeRows, filter.IsSet()), nil
+}
+
+// scanRow reads a row of the menu tree, the variant and its translations are returned
+// apart for its price to be resolved and the variant is nil when the row has none
+func scanRow(rows *sql.Rows) (menuTreeModels.Row, *menuVariantModels.MenuVariant, menuTranslationModels.Translations, error) {
+	var row menuTreeModels.Row
+	var categoryTranslations, subCategoryTranslations, variantTranslations []byte
+	var categoryDescription sql.NullString
+	var subCategoryID, subCategoryName, subCategoryDescription, itemType sql.NullString
+	var subCategoryOrder sql.NullInt32
+	var variantID, variantName, variantDescription, imageURL sql.NullString
+	var price, happyHourPrice *money.Money
+	var isAvailable, isAlcoholic sql.NullBool
+	var preparationTime, variantOrder sql.NullInt32
+	var menuTypes, dietaryTags, allergens []byte
+
+	err := rows.Scan(
+		&row.Category.ID, &row.Category.Name, &categoryDescription, &row.Category.DisplayOrder, &categoryTranslations,
+		&subCategoryID, &subCategoryName, &subCategoryDescription, &itemType, &subCategoryOrder, &subCategoryTranslations,
+		&variantID, &variantName, &variantDescription, &price, &happyHourPrice, &imageURL, &isAvailable,
+		&preparationTime, &menuTypes, &dietaryTags, &allergens, &isAlcoholic, &variantOrder,
+		&variantTranslations,
+	)
+	if err != nil {
+		return row, nil, nil, err
+	}
+
+	if categoryDescription.Valid {
+		row.Category.Description = &categoryDescription.String
+	}
+	if row.Category.Translations, err = decodeTranslations(categoryTranslations); err != nil {
+		return row, nil, nil, err
+	}
+	if !subCategoryID.Valid {
+		return row, nil, nil, nil
+	}
+	row.SubCategory = &menuTreeModels.SubCategory{
+		ID:           subCategoryID.String,
+		Name:         subCategoryName.String,
+		ItemType:     itemType.String,
+		DisplayOrder: int(subCategoryOrder.Int32),
+	}
+	if subCategoryDescription.Valid {
+		row.SubCategory.Description = &subCategoryDescription.String
+	}
+	if row.SubCategory.Translations, err = decodeTranslations(subCategoryTranslations); err != nil {
+		return row, nil, nil, err
+	}
+	if !variantID.Valid {
+		return row, nil, nil, nil
+	}
+
+	variant := &menuVariantModels.MenuVariant{
+		ID:             variantID.String,
+		Name:           variantName.String,
+		SubCategoryID:  subCategoryID.String,
+		Price:          *price,
+		HappyHourPrice: happyHourPrice,
+		IsAvailable:    isAvailable.Bool,
+		MenuTypes:      json.RawMessage(menuTypes),
+		IsAlcoholic:    isAlcoholic.Bool,
+		DisplayOrder:   int(variantOrder.Int32),
+	}
+	if variantDescription.Valid {
+		variant.Description = &variantDescription.String
+	}
+	if imageURL.Valid {
+		variant.ImageURL = &imageURL.String
+	}
+	if preparationTime.Valid {
+		prepTime := int(preparationTime.Int32)
+		variant.PreparationTime = &prepTime
+	}
+	if dietaryTags != nil {
+		variant.DietaryTags = json.RawMessage(dietaryTags)
+	}
+	if allergens != nil {
+		variant.Allergens = json.RawMessage(allergens)
+	}
+	translations, err := decodeTranslations(variantTranslations)
+	if err != nil {
+		return row, nil, nil, err
+	}
+	return row, variant, translations, nil
+}
+
+// decodeTranslations reads a translations column, nil when there are none
+func decodeTranslations(column []byte) (menuTranslationModels.Translations, error) {
+	var translations menuTranslationModels.Translations
+	if err := json.Unmarshal(column, &translations); err != nil {
+		return nil, fmt.Errorf("invalid translations: %w", err)
+	}
+	if len(translations) == 0 {
+		return nil, nil
+	}
+	return translations, nil
+}
+
+// TableExists tells whether a table with the number is in the venue
+func (h *DBHandler) TableExists(ctx context.Context, number string) (bool, error) {
+	var found string
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuTreeSQL.FindTableQuery), queries.Args{"table_number": number}).Scan(&found)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to find table: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	menuTranslationModels "menu-service/pkg/entities/menu_translations/models"
+	menuTreeModels "menu-service/pkg/entities/menu_tree/models"
+	sharedErrors "shared/errors"
+	sharedHttp "shared/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPHandler handles HTTP requests for the menu tree and the public menu
+type HTTPHandler struct {
+	dbHandler       *DBHandler
+	defaultLanguage string // the language names and descriptions are written in
+	publicMaxAge    int    // seconds shared caches may serve the public menu without asking
+	logger          *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(dbHandler *DBHandler, defaultLanguage string, publicMaxAge int, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{
+		dbHandler:       dbHandler,
+		defaultLanguage: defaultLanguage,
+		publicMaxAge:    publicMaxAge,
+		logger:          logger,
+	}
+}
+
+// Get handles GET /api/v1/menu/tree?menu_type=lunch&item_type=kitchen&available_only=true.
+// The response carries an ETag of its content, a poll with If-None-Match gets a 304
+// until the menu or a price charged changes.
+func (h *HTTPHandler) Get(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := menuTreeModels.Filter{MenuType: query.Get("menu_type"), ItemType: query.Get("item_type")}
+	if value := query.Get("available_only"); value != "" {
+		availableOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			sharedHttp.SendError(w, r, sharedErrors.Validation("invalid_query", "invalid menu tree filter",
+				sharedErrors.FieldError{Field: "available_only", Code: "invalid_bool", Message: "available_only must be true or false"}), "")
+			return
+		}
+		filter.AvailableOnly = availableOnly
+	}
+	if err := filter.Validate(); err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid menu tree filter")
+		return
+	}
+
+	tree, err := h.dbHandler.Get(r.Context(), &filter, time.Now())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get menu tree")
+		sharedHttp.SendError(w, r, err, "Failed to get menu tree")
+		return
+	}
+
+	sharedHttp.SendTaggedResponse(w, r, http.StatusOK, "Menu tree retrieved", tree)
+}
+
+// Public handles GET /api/v1/menu/p/menu?table=12&lang=en, the menu customers reach from a
+// QR code without signing in. The language comes from lang, then Accept-Language. Shared
+// caches may keep it for publicMaxAge seconds and then revalidate it with its ETag.
+func (h *HTTPHandler) Public(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var table *string
+	if number := strings.TrimSpace(query.Get("table")); number != "" {
+		exists, err := h.dbHandler.TableExists(r.Context(), number)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to find public menu table")
+			sharedHttp.SendError(w, r, err, "Failed to get menu")
+			return
+		}
+		if !exists {
+			sharedHttp.SendError(w, r, sharedErrors.NotFound("table_not_found", "table not found"), "")
+			return
+		}
+		table = &number
+	}
+
+	tree, err := h.dbHandler.Get(r.Context(), &menuTreeModels.Filter{AvailableOnly: true}, time.Now())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get public menu")
+		sharedHttp.SendError(w, r, err, "Failed to get menu")
+		return
+	}
+	preferred := menuTranslationModels.Preferred(query.Get("lang"), r.Header.Get("Accept-Language"))
+	menu := menuTreeModels.NewPublicMenu(tree, preferred, h.defaultLanguage)
+	menu.Table = table
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", h.publicMaxAge))
+	w.Header().Set("Vary", "Accept-Language")
+	sharedHttp.SendTaggedResponse(w, r, http.StatusOK, "Menu retrieved", menu)
+}
@@ -0,0 +1,295 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	menuTranslationModels "menu-service/pkg/entities/menu_translations/models"
+	menuVariantModels "menu-service/pkg/entities/menu_variants/models"
+	sharedErrors "shared/errors"
+	"shared/money"
+)
+
+// Filter narrows the menu tree, zero values keep everything
+type Filter struct {
+	MenuType      string // e.g. lunch, variants whose menu_types have it
+	ItemType      string // kitchen or bar, sub-categories of that type
+	AvailableOnly bool   // available variants only
+}
+
+// Validate checks the filter
+func (f *Filter) Validate() error {
+	f.MenuType = strings.TrimSpace(f.MenuType)
+	if f.ItemType != "" && f.ItemType != "kitchen" && f.ItemType != "bar" {
+		return sharedErrors.Validation("invalid_query", "invalid menu tree filter",
+			sharedErrors.FieldError{Field: "item_type", Code: "invalid_item_type", Message: "item_type must be kitchen or bar"})
+	}
+	return nil
+}
+
+// IsSet tells whether the filter narrows anything
+func (f *Filter) IsSet() bool {
+	return f.MenuType != "" || f.ItemType != "" || f.AvailableOnly
+}
+
+// Variant is a menu variant as shown in the menu tree, with the price charged now and
+// nothing about its cost
+type Variant struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	Description     *string         `json:"description,omitempty"`
+	Price           money.Money     `json:"price"`
+	HappyHourPrice  *money.Money    `json:"happy_hour_price,omitempty"`
+	EffectivePrice  money.Money     `json:"effective_price"`
+	PriceRule       string          `json:"price_rule"`
+	ImageURL        *string         `json:"image_url,omitempty"`
+	IsAvailable     bool            `json:"is_available"`
+	PreparationTime *int            `json:"preparation_time,omitempty"`
+	MenuTypes       json.RawMessage `json:"menu_types"`
+	DietaryTags     json.RawMessage `json:"dietary_tags,omitempty"`
+	Allergens       json.RawMessage `json:"allergens,omitempty"`
+	IsAlcoholic     bool            `json:"is_alcoholic"`
+	DisplayOrder    int             `json:"display_order"`
+
+	Translations menuTranslationModels.Translations `json:"translations,omitempty"`
+}
+
+// NewVariant returns the tree entry of a menu variant whose effective price is set
+func NewVariant(v *menuVariantModels.MenuVariant, translations menuTranslationModels.Translations) Variant {
+	variant := Variant{
+		ID:              v.ID,
+		Name:            v.Name,
+		Description:     v.Description,
+		Price:           v.Price,
+		HappyHourPrice:  v.HappyHourPrice,
+		EffectivePrice:  v.Price,
+		PriceRule:       v.PriceRule,
+		ImageURL:        v.ImageURL,
+		IsAvailable:     v.IsAvailable,
+		PreparationTime: v.PreparationTime,
+		MenuTypes:       v.MenuTypes,
+		DietaryTags:     v.DietaryTags,
+		Allergens:       v.Allergens,
+		IsAlcoholic:     v.IsAlcoholic,
+		DisplayOrder:    v.DisplayOrder,
+		Translations:    translations,
+	}
+	if v.EffectivePrice != nil {
+		variant.EffectivePrice = *v.EffectivePrice
+	}
+	return variant
+}
+
+// SubCategory is a menu sub-category with its variants in display order
+type SubCategory struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Description  *string   `json:"description,omitempty"`
+	ItemType     string    `json:"item_type"`
+	DisplayOrder int       `json:"display_order"`
+	Variants     []Variant `json:"variants"`
+
+	Translations menuTranslationModels.Translations `json:"translations,omitempty"`
+}
+
+// Category is a menu category with its sub-categories in display order
+type Category struct {
+	ID            string        `json:"id"`
+	Name          string        `json:"name"`
+	Description   *string       `json:"description,omitempty"`
+	DisplayOrder  int           `json:"display_order"`
+	SubCategories []SubCategory `json:"sub_categories"`
+
+	Translations menuTranslationModels.Translations `json:"translations,omitempty"`
+}
+
+// MenuTree is the whole menu, categories → sub-categories → variants. It holds nothing
+// that changes from one read to the next, so its ETag only changes with the menu.
+type MenuTree struct {
+	Categories []Category `json:"categories"`
+}
+
+// Row is a row of the menu tree query: a category, and its sub-category and variant when
+// it has them
+type Row struct {
+	Category    Category
+	SubCategory *SubCategory
+	Variant     *Variant
+}
+
+// Build nests the rows, ordered by category then sub-category, into a tree. With prune
+// set, sub-categories and categories left without variants are dropped, so a filtered
+// tree has no empty branches.
+func Build(rows []Row, prune bool) *MenuTree {
+	tree := &MenuTree{Categories: []Category{}}
+	for _, row := range rows {
+		categories := tree.Categories
+		if len(categories) == 0 || categories[len(categories)-1].ID != row.Category.ID {
+			category := row.Category
+			category.SubCategories = []SubCategory{}
+			tree.Categories = append(tree.Categories, category)
+		}
+		category := &tree.Categories[len(tree.Categories)-1]
+		if row.SubCategory == nil {
+			continue
+		}
+
+		subCategories := category.SubCategories
+		if len(subCategories) == 0 || subCategories[len(subCategories)-1].ID != row.SubCategory.ID {
+			subCategory := *row.SubCategory
+			subCategory.Variants = []Variant{}
+			category.SubCategories = append(category.SubCategories, subCategory)
+		}
+		subCategory := &category.SubCategories[len(category.SubCategories)-1]
+		if row.Variant != nil {
+			subCategory.Variants = append(subCategory.Variants, *row.Variant)
+		}
+	}
+
+	if prune {
+		tree.prune()
+	}
+	return tree
+}
+
+// prune drops the sub-categories without variants and the categories left empty
+func (t *MenuTree) prune() {
+	categories := t.Categories[:0]
+	for _, category := range t.Categories {
+		subCategories := category.SubCategories[:0]
+		for _, subCategory := range category.SubCategories {
+			if len(subCategory.Variants) > 0 {
+				subCategories = append(subCategories, subCategory)
+			}
+		}
+		category.SubCategories = subCategories
+		if len(subCategories) > 0 {
+			categories = append(categories, category)
+		}
+	}
+	t.Categories = categories
+}
+
+// Languages returns the languages the tree has translations in, in order
+func (t *MenuTree) Languages() []string {
+	seen := map[string]bool{}
+	add := func(translations menuTranslationModels.Translations) {
+		for language := range translations {
+			seen[language] = true
+		}
+	}
+	for _, category := range t.Categories {
+		add(category.Translations)
+		for _, subCategory := range category.SubCategories {
+			add(subCategory.Translations)
+			for _, variant := range subCategory.Variants {
+				add(variant.Translations)
+			}
+		}
+	}
+
+	languages := make([]string, 0, len(seen))
+	for language := range seen {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// PublicVariant is a menu variant as customers see it. Price is the price charged now,
+// RegularPrice the usual one while a happy hour lowers it.
+type PublicVariant struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	Description     *string         `json:"description,omitempty"`
+	Price           money.Money     `json:"price"`
+	RegularPrice    *money.Money    `json:"regular_price,omitempty"`
+	ImageURL        *string         `json:"image_url,omitempty"`
+	PreparationTime *int            `json:"preparation_time,omitempty"`
+	DietaryTags     json.RawMessage `json:"dietary_tags,omitempty"`
+	Allergens       json.RawMessage `json:"allergens,omitempty"`
+	IsAlcoholic     bool            `json:"is_alcoholic"`
+}
+
+// PublicSubCategory is a menu sub-category as customers see it
+type PublicSubCategory struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description *string         `json:"description,omitempty"`
+	Variants    []PublicVariant `json:"variants"`
+}
+
+// PublicCategory is a menu category as customers see it
+type PublicCategory struct {
+	ID            string              `json:"id"`
+	Name          string              `json:"name"`
+	Description   *string             `json:"description,omitempty"`
+	SubCategories []PublicSubCategory `json:"sub_categories"`
+}
+
+// PublicMenu is the customer-facing menu in one language. Languages are those it can be
+// asked in, Table the table a QR code deep link was scanned at.
+type PublicMenu struct {
+	Language   string           `json:"language"`
+	Languages  []string         `json:"languages"`
+	Table      *string          `json:"table,omitempty"`
+	Categories []PublicCategory `json:"categories"`
+}
+
+// NewPublicMenu returns the available variants of a tree as customers see them, in the
+// first preferred language the menu has and otherwise the default one. Entries not
+// translated to that language keep their default language name and description.
+func NewPublicMenu(tree *MenuTree, preferred []string, defaultLanguage string) *PublicMenu {
+	translated := tree.Languages()
+	menu := &PublicMenu{
+		Language:   menuTranslationModels.Negotiate(preferred, translated, defaultLanguage),
+		Languages:  []string{defaultLanguage},
+		Categories: []PublicCategory{},
+	}
+	for _, language := range translated {
+		if language != defaultLanguage {
+			menu.Languages = append(menu.Languages, language)
+		}
+	}
+
+	for _, category := range tree.Categories {
+		public := PublicCategory{ID: category.ID, SubCategories: []PublicSubCategory{}}
+		public.Name, public.Description = category.Translations.Localize(menu.Language, category.Name, category.Description)
+		for _, subCategory := range category.SubCategories {
+			publicSub := PublicSubCategory{ID: subCategory.ID, Variants: []PublicVariant{}}
+			publicSub.Name, publicSub.Description = subCategory.Translations.Localize(menu.Language, subCategory.Name, subCategory.Description)
+			for _, variant := range subCategory.Variants {
+				if variant.IsAvailable {
+					publicSub.Variants = append(publicSub.Variants, newPublicVariant(variant, menu.Language))
+				}
+			}
+			if len(publicSub.Variants) > 0 {
+				public.SubCategories = append(public.SubCategories, publicSub)
+			}
+		}
+		if len(public.SubCategories) > 0 {
+			menu.Categories = append(menu.Categories, public)
+		}
+	}
+	return menu
+}
+
+// newPublicVariant returns a variant as customers see it in language
+func newPublicVariant(v Variant, language string) PublicVariant {
+	public := PublicVariant{
+		ID:              v.ID,
+		Price:           v.EffectivePrice,
+		ImageURL:        v.ImageURL,
+		PreparationTime: v.PreparationTime,
+		DietaryTags:     v.DietaryTags,
+		Allergens:       v.Allergens,
+		IsAlcoholic:     v.IsAlcoholic,
+	}
+	public.Name, public.Description = v.Translations.Localize(language, v.Name, v.Description)
+	if !v.EffectivePrice.Equal(v.Price) {
+		regular := v.Price
+		public.RegularPrice = &regular
+	}
+	return public
+}
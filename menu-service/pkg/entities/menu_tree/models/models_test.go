@@ -0,0 +1,111 @@
+package models
+
+import (
+	"testing"
+
+	menuTranslationModels "menu-service/pkg/entities/menu_translations/models"
+	menuVariantModels "menu-service/pkg/entities/menu_variants/models"
+	"shared/money"
+)
+
+func TestBuild(t *testing.T) {
+	drinks := Category{ID: "c1", Name: "Drinks"}
+	food := Category{ID: "c2", Name: "Food"}
+	desserts := Category{ID: "c3", Name: "Desserts"}
+	sodas := &SubCategory{ID: "s1", Name: "Sodas", ItemType: "bar"}
+	juices := &SubCategory{ID: "s2", Name: "Juices", ItemType: "bar"}
+	burgers := &SubCategory{ID: "s3", Name: "Burgers", ItemType: "kitchen"}
+	rows := []Row{
+		{Category: drinks, SubCategory: sodas, Variant: &Variant{ID: "v1", Name: "Cola"}},
+		{Category: drinks, SubCategory: sodas, Variant: &Variant{ID: "v2", Name: "Ginger ale"}},
+		{Category: drinks, SubCategory: juices},
+		{Category: food, SubCategory: burgers, Variant: &Variant{ID: "v3", Name: "Classic"}},
+		{Category: desserts},
+	}
+
+	tree := Build(rows, false)
+	if len(tree.Categories) != 3 {
+		t.Fatalf("categories = %d, want 3", len(tree.Categories))
+	}
+	got := tree.Categories[0]
+	if got.Name != "Drinks" || len(got.SubCategories) != 2 || len(got.SubCategories[0].Variants) != 2 || len(got.SubCategories[1].Variants) != 0 {
+		t.Errorf("drinks = %+v, want sodas with 2 variants and empty juices", got)
+	}
+	if got := tree.Categories[2]; got.Name != "Desserts" || got.SubCategories == nil || len(got.SubCategories) != 0 {
+		t.Errorf("desserts = %+v, want no sub-categories", got)
+	}
+
+	pruned := Build(rows, true)
+	if len(pruned.Categories) != 2 || len(pruned.Categories[0].SubCategories) != 1 || pruned.Categories[1].Name != "Food" {
+		t.Errorf("pruned = %+v, want drinks with sodas and food", pruned.Categories)
+	}
+
+	if empty := Build(nil, true); empty.Categories == nil {
+		t.Error("empty tree categories = nil, want an empty list")
+	}
+}
+
+func TestNewVariant(t *testing.T) {
+	cost := money.MustParse("4.00")
+	happyHour := money.MustParse("8.00")
+	effective := happyHour
+	v := NewVariant(&menuVariantModels.MenuVariant{
+		ID: "v1", Name: "Mojito", Price: money.MustParse("10.00"), ItemCost: &cost,
+		HappyHourPrice: &happyHour, EffectivePrice: &effective, PriceRule: menuVariantModels.PriceRuleHappyHour,
+	}, nil)
+	if !v.EffectivePrice.Equal(happyHour) || v.PriceRule != menuVariantModels.PriceRuleHappyHour {
+		t.Errorf("effective price = %s by %s, want 8.00 by happy_hour", v.EffectivePrice, v.PriceRule)
+	}
+}
+
+func TestFilterValidate(t *testing.T) {
+	filter := Filter{MenuType: " lunch ", ItemType: "kitchen"}
+	if err := filter.Validate(); err != nil || filter.MenuType != "lunch" || !filter.IsSet() {
+		t.Errorf("Validate() = %v with %+v, want a valid lunch kitchen filter", err, filter)
+	}
+	if err := (&Filter{ItemType: "patio"}).Validate(); err == nil {
+		t.Error("Validate() with item_type patio = nil, want an error")
+	}
+	if (&Filter{}).IsSet() {
+		t.Error("empty filter IsSet() = true")
+	}
+}
+
+func TestNewPublicMenu(t *testing.T) {
+	description := "Con leche"
+	tree := &MenuTree{Categories: []Category{
+		{ID: "c1", Name: "Bebidas", Translations: menuTranslationModels.Translations{"en": {Name: "Drinks"}}, SubCategories: []SubCategory{
+			{ID: "s1", Name: "Batidos", Variants: []Variant{
+				{ID: "v1", Name: "Batido de banano", Description: &description, Price: money.MustParse("3.00"), EffectivePrice: money.MustParse("2.50"), IsAvailable: true,
+					Translations: menuTranslationModels.Translations{"en": {Name: "Banana smoothie"}, "fr": {Name: "Smoothie à la banane"}}},
+				{ID: "v2", Name: "Batido de fresa", Price: money.MustParse("3.00"), EffectivePrice: money.MustParse("3.00")},
+			}},
+		}},
+		{ID: "c2", Name: "Postres", SubCategories: []SubCategory{
+			{ID: "s2", Name: "Helados", Variants: []Variant{{ID: "v3", Name: "Helado", IsAvailable: false}}},
+		}},
+	}}
+
+	menu := NewPublicMenu(tree, []string{"de", "en-us"}, "es")
+	if menu.Language != "en" || len(menu.Languages) != 3 || menu.Languages[0] != "es" {
+		t.Errorf("language = %q of %v, want en of es, en and fr", menu.Language, menu.Languages)
+	}
+	if len(menu.Categories) != 1 {
+		t.Fatalf("categories = %+v, want only drinks, desserts have nothing available", menu.Categories)
+	}
+	category := menu.Categories[0]
+	if category.Name != "Drinks" || category.SubCategories[0].Name != "Batidos" {
+		t.Errorf("names = %q > %q, want Drinks > Batidos", category.Name, category.SubCategories[0].Name)
+	}
+	variants := category.SubCategories[0].Variants
+	if len(variants) != 1 {
+		t.Fatalf("variants = %+v, want the available one", variants)
+	}
+	if v := variants[0]; v.Name != "Banana smoothie" || !v.Price.Equal(money.MustParse("2.50")) || v.RegularPrice == nil || !v.RegularPrice.Equal(money.MustParse("3.00")) {
+		t.Errorf("variant = %+v, want Banana smoothie at 2.50 down from 3.00", v)
+	}
+
+	if menu := NewPublicMenu(tree, nil, "es"); menu.Language != "es" || menu.Categories[0].Name != "Bebidas" || menu.Categories[0].SubCategories[0].Variants[0].RegularPrice == nil {
+		t.Errorf("default menu = %+v, want Spanish names", menu.Categories[0])
+	}
+}
@@ -0,0 +1,24 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	GetMenuTreeQuery queries.Name = "get_menu_tree"
+	FindTableQuery   queries.Name = "find_table"
+)
+
+// LoadQueries loads and validates the menu tree SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		GetMenuTreeQuery,
+		FindTableQuery,
+	)
+}
@@ -0,0 +1 @@
+SELECT table_number FROM tables WHERE table_number = @table_number;
@@ -0,0 +1,15 @@
+-- The live menu in display order, a row per variant with its sub-category and category.
+-- Categories and active sub-categories without a matching variant come with NULLs.
+SELECT mc.id, mc.name, mc.description, mc.display_order, mc.translations,
+       msc.id, msc.name, msc.description, msc.item_type, msc.display_order, msc.translations,
+       mv.id, mv.name, mv.description, mv.price, mv.happy_hour_price, mv.image_url, mv.is_available,
+       mv.preparation_time, mv.menu_types, mv.dietary_tags, mv.allergens, mv.is_alcoholic, mv.display_order,
+       mv.translations
+FROM menu_categories mc
+LEFT JOIN menu_sub_categories msc ON msc.category_id = mc.id AND msc.deleted_at IS NULL AND msc.is_active
+    AND (@item_type::text IS NULL OR msc.item_type = @item_type::text)
+LEFT JOIN menu_variants mv ON mv.sub_category_id = msc.id AND mv.deleted_at IS NULL
+    AND (@menu_type::text IS NULL OR mv.menu_types @> jsonb_build_array(@menu_type::text))
+    AND (NOT @available_only::boolean OR mv.is_available)
+WHERE mc.deleted_at IS NULL
+ORDER BY mc.display_order, mc.name, mc.id, msc.display_order, msc.name, msc.id, mv.display_order, mv.name, mv.id;
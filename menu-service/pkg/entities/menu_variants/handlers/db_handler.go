@@ -1,63 +1,167 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	happyHourHandlers "menu-service/pkg/entities/happy_hours/handlers"
 	"menu-service/pkg/entities/menu_variants/models"
 	menuVariantSQL "menu-service/pkg/entities/menu_variants/sql"
+	modifierGroupHandlers "menu-service/pkg/entities/modifier_groups/handlers"
+	servicePeriodHandlers "menu-service/pkg/entities/service_periods/handlers"
+	"shared/audit"
 	sharedDb "shared/db"
-
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"shared/events"
+	"shared/money"
+	"time"
+
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 )
 
+// auditEntity names the entity in the audit log
+const auditEntity = "menu_variant"
+
+// CostRecomputer costs the menu again after a variant joins or leaves a sub-category, which
+// changes what "any variant of the sub-category" ingredients cost
+type CostRecomputer interface {
+	RecomputeCosts(ctx context.Context) error
+}
+
 // DBHandler handles database operations for menu variants
 type DBHandler struct {
-	db      *sharedDb.DbHandler
-	queries *menuVariantSQL.Queries
-	logger  *logrus.Logger
+	db         *sharedDb.DbHandler
+	audit      *audit.Log
+	queries    *queries.Registry
+	outbox     *events.Outbox
+	costs      CostRecomputer
+	happyHours *happyHourHandlers.DBHandler
+	periods    *servicePeriodHandlers.DBHandler
+	modifiers  *modifierGroupHandlers.DBHandler
+	logger     *logrus.Logger
 }
 
 // NewDBHandler creates a new database handler
-func NewDBHandler(db *sharedDb.DbHandler, logger *logrus.Logger) (*DBHandler, error) {
+func NewDBHandler(db *sharedDb.DbHandler, outbox *events.Outbox, auditLog *audit.Log, happyHours *happyHourHandlers.DBHandler, periods *servicePeriodHandlers.DBHandler, modifiers *modifierGroupHandlers.DBHandler, logger *logrus.Logger) (*DBHandler, error) {
 	queries, err := menuVariantSQL.LoadQueries()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
 	}
 
 	return &DBHandler{
-		db:      db,
-		queries: queries,
-		logger:  logger,
+		db:         db,
+		audit:      auditLog,
+		queries:    queries,
+		outbox:     outbox,
+		happyHours: happyHours,
+		periods:    periods,
+		modifiers:  modifiers,
+		logger:     logger,
 	}, nil
 }
 
-// List returns a paginated list of menu items
-func (h *DBHandler) List(req *models.MenuVariantListRequest) (*models.MenuVariantListResponse, error) {
-	offset := (req.Page - 1) * req.Limit
+// SetCostRecomputer sets what recomputes costs after sub-category membership changes; the
+// cost handler is built on top of this one, so it is set after both exist
+func (h *DBHandler) SetCostRecomputer(costs CostRecomputer) {
+	h.costs = costs
+}
+
+// LockRecipes serializes changes to the recipe graph until the transaction in ctx ends
+func (h *DBHandler) LockRecipes(ctx context.Context) error {
+	if _, err := h.db.ExecNamedContext(ctx, h.queries.Get(menuVariantSQL.LockMenuRecipesQuery), queries.Args{}); err != nil {
+		return fmt.Errorf("failed to lock menu recipes: %w", err)
+	}
+	return nil
+}
+
+// LockRecipesShared keeps the recipe graph from changing until the transaction in ctx ends,
+// without serializing with other holders of the shared lock
+func (h *DBHandler) LockRecipesShared(ctx context.Context) error {
+	if _, err := h.db.ExecNamedContext(ctx, h.queries.Get(menuVariantSQL.LockMenuRecipesSharedQuery), queries.Args{}); err != nil {
+		return fmt.Errorf("failed to lock menu recipes: %w", err)
+	}
+	return nil
+}
+
+// LockCosts locks the menu variants whose costs are about to be stored until the
+// transaction in ctx ends
+func (h *DBHandler) LockCosts(ctx context.Context, ids []string) error {
+	if _, err := h.db.ExecNamedContext(ctx, h.queries.Get(menuVariantSQL.LockMenuVariantCostsQuery), queries.Args{"ids": pq.Array(ids)}); err != nil {
+		return fmt.Errorf("failed to lock menu variant costs: %w", err)
+	}
+	return nil
+}
+
+// CheckRecipeCycle rejects a change that made a menu variant an ingredient of itself,
+// e.g. A uses any variant of a sub-category holding B, and B uses any variant of A's. It
+// runs after the change in the same transaction, which then rolls back.
+func (h *DBHandler) CheckRecipeCycle(ctx context.Context, id string) error {
+	if err := h.LockRecipes(ctx); err != nil {
+		return err
+	}
 
-	// Prepare menu_types filter as JSONB
-	var menuTypesJSON interface{}
-	if req.MenuType != nil {
-		menuTypesJSON = fmt.Sprintf(`["%s"]`, *req.MenuType)
+	var cycle bool
+	if err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuVariantSQL.CheckMenuVariantRecipeCycleQuery), queries.Args{"menu_variant_id": id}).Scan(&cycle); err != nil {
+		return fmt.Errorf("failed to check menu recipe cycle: %w", err)
 	}
+	if cycle {
+		return sharedErrors.Validation("menu_recipe_cycle", "the menu variant would be an ingredient of itself through its sub-category ingredients")
+	}
+	return nil
+}
 
-	countQuery, err := h.queries.Get(menuVariantSQL.CountMenuVariantsQuery)
+// recipeChanged checks a variant that joined a sub-category for cycles and costs the menu again
+func (h *DBHandler) recipeChanged(ctx context.Context, id string) error {
+	if err := h.CheckRecipeCycle(ctx, id); err != nil {
+		return err
+	}
+	if h.costs == nil {
+		return nil
+	}
+	return h.costs.RecomputeCosts(ctx)
+}
+
+// List returns a page of menu items matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.MenuVariantListResponse, error) {
+	return h.list(ctx, spec, menuVariantSQL.ListMenuVariantsQuery, nil)
+}
+
+// ListOrderable returns a page of the menu items matching the list spec that can be
+// ordered at the instant at: live, available, and in an open service period when their menu
+// types name one
+func (h *DBHandler) ListOrderable(ctx context.Context, spec *queryspec.Spec, at time.Time) (*models.MenuVariantListResponse, error) {
+	schedule, err := h.periods.Schedule(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get count query: %w", err)
+		return nil, err
+	}
+	return h.list(ctx, spec, menuVariantSQL.ListOrderableMenuVariantsQuery, queries.Args{
+		"period_names":      pq.StringArray(schedule.Names()),
+		"open_period_names": pq.StringArray(schedule.ActiveNames(at)),
+	})
+}
+
+// list returns a page of the base list script's menu items matching the list spec
+func (h *DBHandler) list(ctx context.Context, spec *queryspec.Spec, base queries.Name, args queries.Args) (*models.MenuVariantListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(base), args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build menu items count: %w", err)
 	}
 
 	var total int
-	if err := h.db.QueryRow(countQuery, req.CategoryID, req.SubCategoryID, req.IsAvailable, menuTypesJSON).Scan(&total); err != nil {
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
 		return nil, fmt.Errorf("failed to count menu items: %w", err)
 	}
 
-	listQuery, err := h.queries.Get(menuVariantSQL.ListMenuVariantsQuery)
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(base), args)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get list query: %w", err)
+		return nil, fmt.Errorf("failed to build menu items list: %w", err)
 	}
 
-	rows, err := h.db.Query(listQuery, req.CategoryID, req.SubCategoryID, req.IsAvailable, menuTypesJSON, req.Limit, offset)
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list menu items: %w", err)
 	}
@@ -72,32 +176,103 @@ func (h *DBHandler) List(req *models.MenuVariantListRequest) (*models.MenuVarian
 		items = append(items, *item)
 	}
 
+	items, pageInfo, err := queryspec.Paginate(spec, items)
+	if err != nil {
+		return nil, err
+	}
+
 	return &models.MenuVariantListResponse{
-		Items: items,
-		Total: total,
-		Page:  req.Page,
-		Limit: req.Limit,
+		Items:    items,
+		Total:    total,
+		Limit:    spec.Limit,
+		PageInfo: pageInfo,
 	}, nil
 }
 
 // GetByID returns a menu item by ID
-func (h *DBHandler) GetByID(id string) (*models.MenuVariant, error) {
-	query, err := h.queries.Get(menuVariantSQL.GetMenuVariantByIDQuery)
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.MenuVariant, error) {
+	row := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuVariantSQL.GetMenuVariantByIDQuery), queries.Args{"id": id})
+	return h.scanMenuVariantRow(row)
+}
+
+// ResolvePrice returns the price of a menu item at the instant at and the rule it came
+// from, at is shown in the venue's timezone
+func (h *DBHandler) ResolvePrice(ctx context.Context, id string, at time.Time) (*models.PriceResolution, error) {
+	item, err := h.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
+		return nil, err
+	}
+	if item == nil {
+		return nil, sharedErrors.NotFound("menu_variant_not_found", "menu item not found")
 	}
 
-	row := h.db.QueryRow(query, id)
-	return h.scanMenuVariantRow(row)
+	schedule, err := h.happyHours.Schedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return item.ResolvePrice(at.In(schedule.Location), schedule.ActiveAt(at)), nil
 }
 
-// Create creates a new menu item
-func (h *DBHandler) Create(req *models.MenuVariantCreateRequest) (*models.MenuVariant, error) {
-	query, err := h.queries.Get(menuVariantSQL.CreateMenuVariantQuery)
+// ApplyEffectivePrices sets the price each menu item is sold at the instant at
+func (h *DBHandler) ApplyEffectivePrices(ctx context.Context, at time.Time, items ...*models.MenuVariant) error {
+	schedule, err := h.happyHours.Schedule(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
+		return err
+	}
+
+	active := schedule.ActiveAt(at)
+	for _, item := range items {
+		resolution := item.ResolvePrice(at, active)
+		item.EffectivePrice, item.PriceRule = &resolution.EffectivePrice, resolution.Rule
+	}
+	return nil
+}
+
+// ApplyModifierGroups sets the modifier groups of a menu variant for its detail
+func (h *DBHandler) ApplyModifierGroups(ctx context.Context, item *models.MenuVariant) error {
+	groups, err := h.modifiers.ForMenuVariant(ctx, item.ID)
+	if err != nil {
+		return err
+	}
+	item.ModifierGroups = groups
+	return nil
+}
+
+// CheckOrderable tells whether each menu variant can be ordered at the instant at, for
+// order creation to reject what isn't being served
+func (h *DBHandler) CheckOrderable(ctx context.Context, ids []string, at time.Time) (*models.OrderableCheckResponse, error) {
+	schedule, err := h.periods.Schedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.OrderableCheckResponse{
+		At:            at.In(schedule.Location),
+		Orderable:     true,
+		ActivePeriods: schedule.ActiveAt(at),
+		Items:         make([]models.OrderableItem, 0, len(ids)),
+	}
+	for _, id := range ids {
+		variant, err := h.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		item := models.CheckOrderable(id, variant, schedule, at)
+		response.Orderable = response.Orderable && item.Orderable
+		response.Items = append(response.Items, item)
 	}
+	return response, nil
+}
+
+// Create creates a new menu item
+func (h *DBHandler) Create(ctx context.Context, req *models.MenuVariantCreateRequest) (*models.MenuVariant, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.MenuVariant, error) {
+		return h.create(ctx, req)
+	})
+}
 
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.MenuVariantCreateRequest) (*models.MenuVariant, error) {
 	// Default empty JSON arrays for JSONB columns if nil
 	menuTypes := req.MenuTypes
 	if menuTypes == nil {
@@ -112,22 +287,34 @@ func (h *DBHandler) Create(req *models.MenuVariantCreateRequest) (*models.MenuVa
 		allergens = json.RawMessage(`[]`)
 	}
 
-	row := h.db.QueryRow(query,
-		req.Name, req.Description, req.SubCategoryID, req.Price, req.HappyHourPrice,
-		req.ImageURL, req.IsAvailable, req.PreparationTime, menuTypes,
-		dietaryTags, allergens, req.IsAlcoholic, req.DisplayOrder,
-	)
+	row := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuVariantSQL.CreateMenuVariantQuery), queries.Args{
+		"name":             req.Name,
+		"description":      req.Description,
+		"sub_category_id":  req.SubCategoryID,
+		"price":            req.Price,
+		"happy_hour_price": req.HappyHourPrice,
+		"image_url":        req.ImageURL,
+		"is_available":     req.IsAvailable,
+		"preparation_time": req.PreparationTime,
+		"menu_types":       menuTypes,
+		"dietary_tags":     dietaryTags,
+		"allergens":        allergens,
+		"is_alcoholic":     req.IsAlcoholic,
+		"display_order":    req.DisplayOrder,
+	})
 
 	return h.scanMenuVariantRowWithoutSubCategory(row)
 }
 
-// Update updates an existing menu item
-func (h *DBHandler) Update(id string, req *models.MenuVariantUpdateRequest) (*models.MenuVariant, error) {
-	query, err := h.queries.Get(menuVariantSQL.UpdateMenuVariantQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
+// Update updates an existing menu item if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.MenuVariantUpdateRequest) (*models.MenuVariant, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.MenuVariant, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
 
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.MenuVariantUpdateRequest) (*models.MenuVariant, error) {
 	// For update, we pass nil to keep existing values, or the new value
 	// The SQL uses COALESCE to handle this
 	var menuTypes, dietaryTags, allergens interface{}
@@ -141,82 +328,212 @@ func (h *DBHandler) Update(id string, req *models.MenuVariantUpdateRequest) (*mo
 		allergens = *req.Allergens
 	}
 
-	row := h.db.QueryRow(query, id,
-		req.Name, req.Description, req.SubCategoryID, req.Price, req.HappyHourPrice,
-		req.ImageURL, req.IsAvailable, req.PreparationTime, menuTypes,
-		dietaryTags, allergens, req.IsAlcoholic, req.DisplayOrder,
-	)
+	var item *models.MenuVariant
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		item = nil
+
+		// Lock the current price so the change event carries the right old value
+		var oldPrice money.Money
+		if req.Price != nil {
+			err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuVariantSQL.LockMenuVariantPriceQuery), queries.Args{"id": id}).Scan(&oldPrice)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return nil
+				}
+				return fmt.Errorf("failed to get menu item price: %w", err)
+			}
+		}
 
-	return h.scanMenuVariantRowWithoutSubCategory(row)
-}
+		row := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuVariantSQL.UpdateMenuVariantQuery), queries.Args{
+			"id":               id,
+			"version":          version,
+			"name":             req.Name,
+			"description":      req.Description,
+			"sub_category_id":  req.SubCategoryID,
+			"price":            req.Price,
+			"happy_hour_price": req.HappyHourPrice,
+			"image_url":        req.ImageURL,
+			"is_available":     req.IsAvailable,
+			"preparation_time": req.PreparationTime,
+			"menu_types":       menuTypes,
+			"dietary_tags":     dietaryTags,
+			"allergens":        allergens,
+			"is_alcoholic":     req.IsAlcoholic,
+			"display_order":    req.DisplayOrder,
+		})
+
+		var err error
+		item, err = h.scanMenuVariantRowWithoutSubCategory(row)
+		if err != nil || item == nil {
+			return err
+		}
+		if req.SubCategoryID != nil {
+			if err := h.recipeChanged(ctx, item.ID); err != nil {
+				return err
+			}
+		}
+		if req.Price == nil || item.Price.Equal(oldPrice) {
+			return nil
+		}
 
-// Delete deletes a menu item
-func (h *DBHandler) Delete(id string) error {
-	deleteQuery, err := h.queries.Get(menuVariantSQL.DeleteMenuVariantQuery)
+		_, err = h.outbox.Add(ctx, events.MenuVariantPriceChanged, "menu_variant", item.ID, events.MenuVariantPriceChangedPayload{
+			MenuVariantID: item.ID,
+			OldPrice:      oldPrice,
+			NewPrice:      item.Price,
+		})
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get delete query: %w", err)
+		return nil, err
+	}
+	if item == nil {
+		return nil, h.versionMismatch(ctx, id)
 	}
 
-	result, err := h.db.Exec(deleteQuery, id)
+	return item, nil
+}
+
+// Delete deletes a menu item
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
+
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(menuVariantSQL.DeleteMenuVariantQuery), queries.Args{"id": id})
 	if err != nil {
 		return fmt.Errorf("failed to delete menu item: %w", err)
 	}
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("menu item not found")
+		return sharedErrors.NotFound("menu_variant_not_found", "menu item not found")
+	}
+
+	// The variant is no longer an option of its sub-category
+	if h.costs != nil {
+		if err := h.costs.RecomputeCosts(ctx); err != nil {
+			return err
+		}
 	}
 
 	h.logger.WithField("id", id).Info("Menu item deleted")
 	return nil
 }
 
-// UpdateAvailability updates the availability of a menu item
-func (h *DBHandler) UpdateAvailability(id string, isAvailable bool) (*models.MenuVariant, error) {
-	query, err := h.queries.Get(menuVariantSQL.UpdateMenuVariantAvailabilityQuery)
+// Restore brings back a soft deleted menu item
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.MenuVariant, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.MenuVariant, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.MenuVariant, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(menuVariantSQL.RestoreMenuVariantQuery), queries.Args{"id": id})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
+		return nil, fmt.Errorf("failed to restore menu item: %w", err)
 	}
 
-	row := h.db.QueryRow(query, id, isAvailable)
-	return h.scanMenuVariantRowWithoutSubCategory(row)
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("menu_variant_not_found", "deleted menu item not found")
+	}
+
+	if err := h.recipeChanged(ctx, id); err != nil {
+		return nil, err
+	}
+
+	h.logger.WithField("id", id).Info("Menu item restored")
+	return h.GetByID(ctx, id)
 }
 
-// UpdateImage updates the image URL of a menu item
-func (h *DBHandler) UpdateImage(id string, imageURL string) (*models.MenuVariant, error) {
-	query, err := h.queries.Get(menuVariantSQL.UpdateMenuVariantImageQuery)
+// Purge hard deletes the menu variants soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuVariantSQL.PurgeMenuVariantsQuery), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
+		return 0, fmt.Errorf("failed to purge menu variants: %w", err)
 	}
+	return purged, nil
+}
 
-	row := h.db.QueryRow(query, id, imageURL)
-	return h.scanMenuVariantRowWithoutSubCategory(row)
+// UpdateAvailability updates the availability of a menu item if it is still at the given version
+func (h *DBHandler) UpdateAvailability(ctx context.Context, id string, version int, isAvailable bool) (*models.MenuVariant, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.MenuVariant, error) {
+		return h.updateAvailability(ctx, id, version, isAvailable)
+	})
 }
 
-// UpdateCost updates the item cost
-func (h *DBHandler) UpdateCost(id string, cost float64) (*models.MenuVariant, error) {
-	query, err := h.queries.Get(menuVariantSQL.UpdateMenuVariantCostQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get query: %w", err)
+// updateAvailability is UpdateAvailability without the audit log entry
+func (h *DBHandler) updateAvailability(ctx context.Context, id string, version int, isAvailable bool) (*models.MenuVariant, error) {
+	row := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuVariantSQL.UpdateMenuVariantAvailabilityQuery), queries.Args{
+		"id":           id,
+		"version":      version,
+		"is_available": isAvailable,
+	})
+	item, err := h.scanMenuVariantRowWithoutSubCategory(row)
+	if err != nil || item != nil {
+		return item, err
+	}
+	return nil, h.versionMismatch(ctx, id)
+}
+
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return err
 	}
+	return sharedErrors.PreconditionFailed("menu_variant_version_mismatch", "menu item was modified by another request")
+}
 
-	row := h.db.QueryRow(query, id, cost)
+// UpdateImage updates the image URL of a menu item
+func (h *DBHandler) UpdateImage(ctx context.Context, id string, imageURL string) (*models.MenuVariant, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.MenuVariant, error) {
+		return h.updateImage(ctx, id, imageURL)
+	})
+}
+
+// updateImage is UpdateImage without the audit log entry
+func (h *DBHandler) updateImage(ctx context.Context, id string, imageURL string) (*models.MenuVariant, error) {
+	row := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuVariantSQL.UpdateMenuVariantImageQuery), queries.Args{
+		"id":        id,
+		"image_url": imageURL,
+	})
+	return h.scanMenuVariantRowWithoutSubCategory(row)
+}
+
+// UpdateCost updates the item cost, nil when the cost is unknown
+func (h *DBHandler) UpdateCost(ctx context.Context, id string, cost *money.Money) (*models.MenuVariant, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.MenuVariant, error) {
+		return h.updateCost(ctx, id, cost)
+	})
+}
+
+// updateCost is UpdateCost without the audit log entry
+func (h *DBHandler) updateCost(ctx context.Context, id string, cost *money.Money) (*models.MenuVariant, error) {
+	row := h.db.QueryRowNamedContext(ctx, h.queries.Get(menuVariantSQL.UpdateMenuVariantCostQuery), queries.Args{
+		"id":        id,
+		"item_cost": cost,
+	})
 	return h.scanMenuVariantRowWithoutSubCategory(row)
 }
 
 // Helper functions for scanning
 func (h *DBHandler) scanMenuVariant(rows *sql.Rows) (*models.MenuVariant, error) {
 	var item models.MenuVariant
-	var description, subMenuName, itemType, imageURL sql.NullString
-	var itemCost, happyHourPrice sql.NullFloat64
+	var description, subMenuName, categoryID, itemType, imageURL sql.NullString
 	var preparationTime sql.NullInt32
 	var dietaryTags, allergens []byte
 
 	err := rows.Scan(
 		&item.ID, &item.Name, &description, &item.SubCategoryID, &subMenuName,
-		&itemType, &item.Price, &itemCost, &happyHourPrice, &imageURL, &item.IsAvailable,
+		&categoryID, &itemType, &item.Price, &item.ItemCost, &item.HappyHourPrice, &imageURL, &item.IsAvailable,
 		&preparationTime, &item.MenuTypes, &dietaryTags, &allergens, &item.IsAlcoholic,
-		&item.DisplayOrder, &item.CreatedAt, &item.UpdatedAt,
+		&item.DisplayOrder, &item.CreatedAt, &item.UpdatedAt, &item.Version, &item.DeletedAt,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan menu item: %w", err)
@@ -228,15 +545,12 @@ func (h *DBHandler) scanMenuVariant(rows *sql.Rows) (*models.MenuVariant, error)
 	if subMenuName.Valid {
 		item.SubCategoryName = subMenuName.String
 	}
+	if categoryID.Valid {
+		item.CategoryID = categoryID.String
+	}
 	if itemType.Valid {
 		item.ItemType = itemType.String
 	}
-	if itemCost.Valid {
-		item.ItemCost = &itemCost.Float64
-	}
-	if happyHourPrice.Valid {
-		item.HappyHourPrice = &happyHourPrice.Float64
-	}
 	if imageURL.Valid {
 		item.ImageURL = &imageURL.String
 	}
@@ -254,18 +568,17 @@ func (h *DBHandler) scanMenuVariant(rows *sql.Rows) (*models.MenuVariant, error)
 	return &item, nil
 }
 
-func (h *DBHandler) scanMenuVariantRow(row *sql.Row) (*models.MenuVariant, error) {
+func (h *DBHandler) scanMenuVariantRow(row *sharedDb.Row) (*models.MenuVariant, error) {
 	var item models.MenuVariant
 	var description, subMenuName, itemType, imageURL sql.NullString
-	var itemCost, happyHourPrice sql.NullFloat64
 	var preparationTime sql.NullInt32
 	var dietaryTags, allergens []byte
 
 	err := row.Scan(
 		&item.ID, &item.Name, &description, &item.SubCategoryID, &subMenuName,
-		&itemType, &item.Price, &itemCost, &happyHourPrice, &imageURL, &item.IsAvailable,
+		&itemType, &item.Price, &item.ItemCost, &item.HappyHourPrice, &imageURL, &item.IsAvailable,
 		&preparationTime, &item.MenuTypes, &dietaryTags, &allergens, &item.IsAlcoholic,
-		&item.DisplayOrder, &item.CreatedAt, &item.UpdatedAt,
+		&item.DisplayOrder, &item.CreatedAt, &item.UpdatedAt, &item.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -283,12 +596,6 @@ func (h *DBHandler) scanMenuVariantRow(row *sql.Row) (*models.MenuVariant, error
 	if itemType.Valid {
 		item.ItemType = itemType.String
 	}
-	if itemCost.Valid {
-		item.ItemCost = &itemCost.Float64
-	}
-	if happyHourPrice.Valid {
-		item.HappyHourPrice = &happyHourPrice.Float64
-	}
 	if imageURL.Valid {
 		item.ImageURL = &imageURL.String
 	}
@@ -306,18 +613,17 @@ func (h *DBHandler) scanMenuVariantRow(row *sql.Row) (*models.MenuVariant, error
 	return &item, nil
 }
 
-func (h *DBHandler) scanMenuVariantRowWithoutSubCategory(row *sql.Row) (*models.MenuVariant, error) {
+func (h *DBHandler) scanMenuVariantRowWithoutSubCategory(row *sharedDb.Row) (*models.MenuVariant, error) {
 	var item models.MenuVariant
 	var description, imageURL sql.NullString
-	var itemCost, happyHourPrice sql.NullFloat64
 	var preparationTime sql.NullInt32
 	var dietaryTags, allergens []byte
 
 	err := row.Scan(
 		&item.ID, &item.Name, &description, &item.SubCategoryID,
-		&item.Price, &itemCost, &happyHourPrice, &imageURL, &item.IsAvailable,
+		&item.Price, &item.ItemCost, &item.HappyHourPrice, &imageURL, &item.IsAvailable,
 		&preparationTime, &item.MenuTypes, &dietaryTags, &allergens, &item.IsAlcoholic,
-		&item.DisplayOrder, &item.CreatedAt, &item.UpdatedAt,
+		&item.DisplayOrder, &item.CreatedAt, &item.UpdatedAt, &item.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -329,12 +635,6 @@ func (h *DBHandler) scanMenuVariantRowWithoutSubCategory(row *sql.Row) (*models.
 	if description.Valid {
 		item.Description = &description.String
 	}
-	if itemCost.Valid {
-		item.ItemCost = &itemCost.Float64
-	}
-	if happyHourPrice.Valid {
-		item.HappyHourPrice = &happyHourPrice.Float64
-	}
 	if imageURL.Valid {
 		item.ImageURL = &imageURL.String
 	}
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"menu-service/pkg/entities/menu_variants/models"
+	menuVariantSQL "menu-service/pkg/entities/menu_variants/sql"
+	sharedErrors "shared/errors"
 	sharedHttp "shared/http"
 
 	"github.com/gorilla/mux"
@@ -23,46 +26,45 @@ func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
 	return &HTTPHandler{dbHandler: dbHandler, logger: logger}
 }
 
-// List handles GET /api/v1/menu/items
+// List handles GET /api/v1/menu/items, orderable_now=true keeps what can be ordered now
 func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 20
-	}
-
-	req := &models.MenuVariantListRequest{
-		Page:  page,
-		Limit: limit,
-	}
-
-	if categoryID := r.URL.Query().Get("category_id"); categoryID != "" {
-		req.CategoryID = &categoryID
-	}
-
-	if subMenuID := r.URL.Query().Get("sub_category_id"); subMenuID != "" {
-		req.SubCategoryID = &subMenuID
+	spec, err := menuVariantSQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
 	}
 
-	if menuType := r.URL.Query().Get("menu_type"); menuType != "" {
-		req.MenuType = &menuType
+	orderableNow := false
+	if value := r.URL.Query().Get("orderable_now"); value != "" {
+		if orderableNow, err = strconv.ParseBool(value); err != nil {
+			sharedHttp.SendError(w, r, sharedErrors.Validation("invalid_query", "invalid list parameters",
+				sharedErrors.FieldError{Field: "orderable_now", Code: "invalid_bool", Message: "orderable_now must be true or false"}), "")
+			return
+		}
 	}
 
-	if availableStr := r.URL.Query().Get("is_available"); availableStr != "" {
-		isAvailable := availableStr == "true"
-		req.IsAvailable = &isAvailable
+	now := time.Now()
+	var response *models.MenuVariantListResponse
+	if orderableNow {
+		response, err = h.dbHandler.ListOrderable(r.Context(), spec, now)
+	} else {
+		response, err = h.dbHandler.List(r.Context(), spec)
 	}
-
-	response, err := h.dbHandler.List(req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list menu variants")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list menu variants")
+		sharedHttp.SendError(w, r, err, "Failed to list menu variants")
 		return
 	}
+	items := make([]*models.MenuVariant, len(response.Items))
+	for i := range response.Items {
+		items[i] = &response.Items[i]
+	}
+	if err := h.dbHandler.ApplyEffectivePrices(r.Context(), now, items...); err != nil {
+		h.logger.WithError(err).Error("Failed to resolve menu variant prices")
+		sharedHttp.SendError(w, r, err, "Failed to list menu variants")
+		return
+	}
+	response.SetLinks(r.URL)
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu items retrieved", response)
 }
@@ -72,10 +74,10 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	item, err := h.dbHandler.GetByID(id)
+	item, err := h.dbHandler.GetByID(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get menu item")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to get menu item")
+		sharedHttp.SendError(w, r, err, "Failed to get menu item")
 		return
 	}
 
@@ -84,9 +86,78 @@ func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.dbHandler.ApplyEffectivePrices(r.Context(), time.Now(), item); err != nil {
+		h.logger.WithError(err).Error("Failed to resolve menu item price")
+		sharedHttp.SendError(w, r, err, "Failed to get menu item")
+		return
+	}
+	if err := h.dbHandler.ApplyModifierGroups(r.Context(), item); err != nil {
+		h.logger.WithError(err).Error("Failed to get menu item modifier groups")
+		sharedHttp.SendError(w, r, err, "Failed to get menu item")
+		return
+	}
+
+	sharedHttp.SetETag(w, item.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu item retrieved", item)
 }
 
+// ResolvePrice handles GET /api/v1/menu/variants/{id}/price?at=2026-01-31T18:30:00-06:00,
+// the price the item is sold at the instant at, now by default
+func (h *HTTPHandler) ResolvePrice(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	at := time.Now()
+	if value := r.URL.Query().Get("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			sharedHttp.SendError(w, r, sharedErrors.Validation("invalid_query", "invalid menu item price parameters",
+				sharedErrors.FieldError{Field: "at", Code: "invalid_timestamp", Message: "at must be an RFC 3339 timestamp"}), "")
+			return
+		}
+		at = parsed
+	}
+
+	resolution, err := h.dbHandler.ResolvePrice(r.Context(), id, at)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to resolve menu item price")
+		sharedHttp.SendError(w, r, err, "Failed to resolve menu item price")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu item price resolved", resolution)
+}
+
+// CheckOrderable handles POST /api/v1/menu/variants/orderable
+func (h *HTTPHandler) CheckOrderable(w http.ResponseWriter, r *http.Request) {
+	var req models.OrderableCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if len(req.MenuVariantIDs) == 0 {
+		sharedHttp.SendError(w, r, sharedErrors.Validation("invalid_orderable_check", "invalid orderable check",
+			sharedErrors.FieldError{Field: "menu_variant_ids", Code: "required", Message: "at least one menu variant ID is required"}), "")
+		return
+	}
+
+	at := time.Now()
+	if req.At != nil {
+		at = *req.At
+	}
+
+	response, err := h.dbHandler.CheckOrderable(r.Context(), req.MenuVariantIDs, at)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to check menu items are orderable")
+		sharedHttp.SendError(w, r, err, "Failed to check menu items are orderable")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu items checked", response)
+}
+
 // Create handles POST /api/v1/menu/items
 func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req models.MenuVariantCreateRequest
@@ -106,13 +177,14 @@ func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	item, err := h.dbHandler.Create(&req)
+	item, err := h.dbHandler.Create(r.Context(), &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create menu item")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to create menu item")
+		sharedHttp.SendError(w, r, err, "Failed to create menu item")
 		return
 	}
 
+	sharedHttp.SetETag(w, item.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Menu item created", item)
 }
 
@@ -121,6 +193,12 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update menu item")
+		return
+	}
+
 	var req models.MenuVariantUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode request body")
@@ -128,10 +206,10 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	item, err := h.dbHandler.Update(id, &req)
+	item, err := h.dbHandler.Update(r.Context(), id, version, &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update menu item")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update menu item")
+		sharedHttp.SendError(w, r, err, "Failed to update menu item")
 		return
 	}
 
@@ -140,6 +218,7 @@ func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sharedHttp.SetETag(w, item.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu item updated", item)
 }
 
@@ -148,25 +227,43 @@ func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	err := h.dbHandler.Delete(id)
+	err := h.dbHandler.Delete(r.Context(), id)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete menu item")
-		if err.Error() == "menu item not found" {
-			sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Menu item not found")
-			return
-		}
-		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		sharedHttp.SendError(w, r, err, "Failed to delete menu item")
 		return
 	}
 
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu item deleted", nil)
 }
 
+// Restore handles POST /api/v1/menu/variants/:id/restore
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	item, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore menu item")
+		sharedHttp.SendError(w, r, err, "Failed to restore menu item")
+		return
+	}
+
+	sharedHttp.SetETag(w, item.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu item restored", item)
+}
+
 // UpdateAvailability handles PATCH /api/v1/menu/items/:id/availability
 func (h *HTTPHandler) UpdateAvailability(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update availability")
+		return
+	}
+
 	var req models.MenuVariantAvailabilityRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode request body")
@@ -174,10 +271,10 @@ func (h *HTTPHandler) UpdateAvailability(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	item, err := h.dbHandler.UpdateAvailability(id, req.IsAvailable)
+	item, err := h.dbHandler.UpdateAvailability(r.Context(), id, version, req.IsAvailable)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update menu item availability")
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Failed to update availability")
+		sharedHttp.SendError(w, r, err, "Failed to update availability")
 		return
 	}
 
@@ -186,5 +283,6 @@ func (h *HTTPHandler) UpdateAvailability(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	sharedHttp.SetETag(w, item.Version)
 	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Menu item availability updated", item)
 }
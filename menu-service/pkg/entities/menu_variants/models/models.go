@@ -3,6 +3,18 @@ package models
 import (
 	"encoding/json"
 	"time"
+
+	happyHourModels "menu-service/pkg/entities/happy_hours/models"
+	modifierGroupModels "menu-service/pkg/entities/modifier_groups/models"
+	servicePeriodModels "menu-service/pkg/entities/service_periods/models"
+	"shared/db/queryspec"
+	"shared/money"
+)
+
+// Rules a menu variant's effective price comes from
+const (
+	PriceRuleBase      = "base"       // the regular price
+	PriceRuleHappyHour = "happy_hour" // the happy hour price while a happy hour runs
 )
 
 // MenuVariant represents a menu variant (actual orderable item with pricing)
@@ -12,10 +24,13 @@ type MenuVariant struct {
 	Description       *string         `json:"description,omitempty"`
 	SubCategoryID     string          `json:"sub_category_id"`
 	SubCategoryName   string          `json:"sub_category_name,omitempty"`
+	CategoryID        string          `json:"category_id,omitempty"` // Inherited from sub_category, set on lists
 	ItemType          string          `json:"item_type,omitempty"` // Inherited from sub_category
-	Price             float64         `json:"price"`
-	ItemCost          *float64        `json:"item_cost,omitempty"`
-	HappyHourPrice    *float64        `json:"happy_hour_price,omitempty"`
+	Price             money.Money     `json:"price"`
+	ItemCost          *money.Money    `json:"item_cost,omitempty"`
+	HappyHourPrice    *money.Money    `json:"happy_hour_price,omitempty"`
+	EffectivePrice    *money.Money    `json:"effective_price,omitempty"` // Price charged now, set on reads
+	PriceRule         string          `json:"price_rule,omitempty"`      // Rule of EffectivePrice
 	ImageURL          *string         `json:"image_url,omitempty"`
 	IsAvailable       bool            `json:"is_available"`
 	PreparationTime   *int            `json:"preparation_time,omitempty"`
@@ -26,6 +41,41 @@ type MenuVariant struct {
 	DisplayOrder      int             `json:"display_order"`
 	CreatedAt         time.Time       `json:"created_at"`
 	UpdatedAt         time.Time       `json:"updated_at"`
+	Version           int             `json:"version"`
+	DeletedAt         *time.Time      `json:"deleted_at,omitempty"`
+	// ModifierGroups are the options picked when ordering the variant, set on the detail
+	ModifierGroups []modifierGroupModels.ModifierGroup `json:"modifier_groups,omitempty"`
+}
+
+// PriceResolution is the price of a menu variant at an instant and the rule it came from.
+// HappyHour is the happy hour that set it, if any.
+type PriceResolution struct {
+	MenuVariantID  string                           `json:"menu_variant_id"`
+	At             time.Time                        `json:"at"`
+	Price          money.Money                      `json:"price"`
+	HappyHourPrice *money.Money                     `json:"happy_hour_price"`
+	EffectivePrice money.Money                      `json:"effective_price"`
+	Rule           string                           `json:"rule"`
+	HappyHour      *happyHourModels.ActiveHappyHour `json:"happy_hour,omitempty"`
+}
+
+// ResolvePrice returns the variant's price while active runs, nil for no happy hour.
+// A happy hour only changes the price of variants with a happy hour price.
+func (v *MenuVariant) ResolvePrice(at time.Time, active *happyHourModels.ActiveHappyHour) *PriceResolution {
+	resolution := &PriceResolution{
+		MenuVariantID:  v.ID,
+		At:             at,
+		Price:          v.Price,
+		HappyHourPrice: v.HappyHourPrice,
+		EffectivePrice: v.Price,
+		Rule:           PriceRuleBase,
+	}
+	if active != nil && v.HappyHourPrice != nil {
+		resolution.EffectivePrice = *v.HappyHourPrice
+		resolution.Rule = PriceRuleHappyHour
+		resolution.HappyHour = active
+	}
+	return resolution
 }
 
 // MenuVariantCreateRequest represents a request to create a menu item
@@ -33,8 +83,8 @@ type MenuVariantCreateRequest struct {
 	Name            string          `json:"name"`
 	Description     *string         `json:"description,omitempty"`
 	SubCategoryID       string          `json:"sub_category_id"`
-	Price           float64         `json:"price"`
-	HappyHourPrice  *float64        `json:"happy_hour_price,omitempty"`
+	Price           money.Money     `json:"price"`
+	HappyHourPrice  *money.Money    `json:"happy_hour_price,omitempty"`
 	ImageURL        *string         `json:"image_url,omitempty"`
 	IsAvailable     bool            `json:"is_available"`
 	PreparationTime *int            `json:"preparation_time,omitempty"`
@@ -50,8 +100,8 @@ type MenuVariantUpdateRequest struct {
 	Name            *string          `json:"name,omitempty"`
 	Description     *string          `json:"description,omitempty"`
 	SubCategoryID       *string          `json:"sub_category_id,omitempty"`
-	Price           *float64         `json:"price,omitempty"`
-	HappyHourPrice  *float64         `json:"happy_hour_price,omitempty"`
+	Price           *money.Money     `json:"price,omitempty"`
+	HappyHourPrice  *money.Money     `json:"happy_hour_price,omitempty"`
 	ImageURL        *string          `json:"image_url,omitempty"`
 	IsAvailable     *bool            `json:"is_available,omitempty"`
 	PreparationTime *int             `json:"preparation_time,omitempty"`
@@ -67,20 +117,71 @@ type MenuVariantAvailabilityRequest struct {
 	IsAvailable bool `json:"is_available"`
 }
 
-// MenuVariantListRequest represents filter parameters for listing menu items
-type MenuVariantListRequest struct {
-	MenuType      *string `json:"menu_type,omitempty"`
-	CategoryID    *string `json:"category_id,omitempty"`
-	SubCategoryID *string `json:"sub_category_id,omitempty"`
-	IsAvailable   *bool   `json:"is_available,omitempty"`
-	Page          int     `json:"page"`
-	Limit         int     `json:"limit"`
-}
-
 // MenuVariantListResponse represents a paginated list of menu items
 type MenuVariantListResponse struct {
 	Items []MenuVariant `json:"items"`
-	Total int        `json:"total"`
-	Page  int        `json:"page"`
-	Limit int        `json:"limit"`
+	Total int           `json:"total"`
+	Limit int           `json:"limit"`
+	queryspec.PageInfo
+}
+
+// MenuTypeNames returns the menu types of the variant, e.g. lunch and dinner
+func (v *MenuVariant) MenuTypeNames() []string {
+	var names []string
+	if err := json.Unmarshal(v.MenuTypes, &names); err != nil {
+		return nil
+	}
+	return names
+}
+
+// Reasons a menu variant can't be ordered
+const (
+	NotOrderableNotFound             = "not_found"              // no live variant has the ID
+	NotOrderableUnavailable          = "unavailable"            // the variant is marked unavailable
+	NotOrderableOutsideServicePeriod = "outside_service_period" // none of its service periods is open
+)
+
+// OrderableCheckRequest asks whether menu variants can be ordered at an instant, e.g.
+// before an order is created
+type OrderableCheckRequest struct {
+	MenuVariantIDs []string   `json:"menu_variant_ids"`
+	At             *time.Time `json:"at,omitempty"` // now when unset
+}
+
+// OrderableItem tells whether one menu variant can be ordered and why not
+type OrderableItem struct {
+	MenuVariantID string   `json:"menu_variant_id"`
+	Name          string   `json:"name,omitempty"`
+	MenuTypes     []string `json:"menu_types,omitempty"`
+	Orderable     bool     `json:"orderable"`
+	Reason        string   `json:"reason,omitempty"`
+}
+
+// OrderableCheckResponse tells which menu variants can be ordered at an instant
+type OrderableCheckResponse struct {
+	At            time.Time                          `json:"at"`
+	Orderable     bool                               `json:"orderable"` // every item is
+	ActivePeriods []servicePeriodModels.ActivePeriod `json:"active_periods"`
+	Items         []OrderableItem                    `json:"items"`
+}
+
+// CheckOrderable tells whether the variant, nil when not found, can be ordered while the
+// schedule's periods open at at are
+func CheckOrderable(id string, v *MenuVariant, schedule *servicePeriodModels.Schedule, at time.Time) OrderableItem {
+	item := OrderableItem{MenuVariantID: id}
+	if v == nil {
+		item.Reason = NotOrderableNotFound
+		return item
+	}
+
+	item.Name, item.MenuTypes = v.Name, v.MenuTypeNames()
+	switch {
+	case !v.IsAvailable:
+		item.Reason = NotOrderableUnavailable
+	case !schedule.Orderable(item.MenuTypes, at):
+		item.Reason = NotOrderableOutsideServicePeriod
+	default:
+		item.Orderable = true
+	}
+	return item
 }
@@ -2,61 +2,69 @@ package sql
 
 import (
 	"embed"
-	"fmt"
-	"strings"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
 )
 
 //go:embed scripts/*.sql
-var sqlFiles embed.FS
-
-// Queries holds all SQL queries
-type Queries struct {
-	queries map[string]string
-}
+var sqlScripts embed.FS
 
-// LoadQueries loads all SQL queries from the scripts directory
-func LoadQueries() (*Queries, error) {
-	queries := &Queries{
-		queries: make(map[string]string),
-	}
-
-	files, err := sqlFiles.ReadDir("scripts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			content, err := sqlFiles.ReadFile("scripts/" + file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read SQL file %s: %w", file.Name(), err)
-			}
+// SQL query names
+const (
+	ListMenuVariantsQuery              queries.Name = "list_menu_variants"
+	ListOrderableMenuVariantsQuery     queries.Name = "list_orderable_menu_variants"
+	GetMenuVariantByIDQuery            queries.Name = "get_menu_variant_by_id"
+	CreateMenuVariantQuery             queries.Name = "create_menu_variant"
+	UpdateMenuVariantQuery             queries.Name = "update_menu_variant"
+	DeleteMenuVariantQuery             queries.Name = "delete_menu_variant"
+	RestoreMenuVariantQuery            queries.Name = "restore_menu_variant"
+	PurgeMenuVariantsQuery             queries.Name = "purge_menu_variants"
+	UpdateMenuVariantAvailabilityQuery queries.Name = "update_menu_variant_availability"
+	UpdateMenuVariantImageQuery        queries.Name = "update_menu_variant_image"
+	UpdateMenuVariantCostQuery         queries.Name = "update_menu_variant_cost"
+	LockMenuVariantPriceQuery          queries.Name = "lock_menu_variant_price"
+	LockMenuRecipesQuery               queries.Name = "lock_menu_recipes"
+	LockMenuRecipesSharedQuery         queries.Name = "lock_menu_recipes_shared"
+	LockMenuVariantCostsQuery          queries.Name = "lock_menu_variant_costs"
+	CheckMenuVariantRecipeCycleQuery   queries.Name = "check_menu_variant_recipe_cycle"
+)
 
-			queryName := strings.TrimSuffix(file.Name(), ".sql")
-			queries.queries[queryName] = string(content)
-		}
-	}
-	return queries, nil
-}
+// ListSchema whitelists the columns of list_menu_variants and list_orderable_menu_variants
+// that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("display_order,name",
+	queryspec.Field{Name: "name", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "category_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "sub_category_id", Type: queryspec.UUID, Filterable: true},
+	queryspec.Field{Name: "item_type", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "price", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "item_cost", Type: queryspec.Number, Filterable: true},
+	queryspec.Field{Name: "is_available", Type: queryspec.Bool, Filterable: true},
+	queryspec.Field{Name: "is_alcoholic", Type: queryspec.Bool, Filterable: true},
+	queryspec.Field{Name: "menu_types", Type: queryspec.JSONArray, Filterable: true},
+	queryspec.Field{Name: "display_order", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).WithAlias("menu_type", "menu_types", queryspec.Contains).WithSoftDelete()
 
-// Get retrieves a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, exists := q.queries[name]
-	if !exists {
-		return "", fmt.Errorf("query '%s' not found", name)
-	}
-	return query, nil
+// LoadQueries loads and validates the menu variant SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListMenuVariantsQuery,
+		ListOrderableMenuVariantsQuery,
+		GetMenuVariantByIDQuery,
+		CreateMenuVariantQuery,
+		UpdateMenuVariantQuery,
+		DeleteMenuVariantQuery,
+		RestoreMenuVariantQuery,
+		PurgeMenuVariantsQuery,
+		UpdateMenuVariantAvailabilityQuery,
+		UpdateMenuVariantImageQuery,
+		UpdateMenuVariantCostQuery,
+		LockMenuVariantPriceQuery,
+		LockMenuRecipesQuery,
+		LockMenuRecipesSharedQuery,
+		LockMenuVariantCostsQuery,
+		CheckMenuVariantRecipeCycleQuery,
+	)
 }
-
-// SQL query constants
-const (
-	ListMenuVariantsQuery           = "list_menu_variants"
-	CountMenuVariantsQuery          = "count_menu_variants"
-	GetMenuVariantByIDQuery         = "get_menu_variant_by_id"
-	CreateMenuVariantQuery          = "create_menu_variant"
-	UpdateMenuVariantQuery          = "update_menu_variant"
-	DeleteMenuVariantQuery          = "delete_menu_variant"
-	UpdateMenuVariantAvailabilityQuery = "update_menu_variant_availability"
-	UpdateMenuVariantImageQuery     = "update_menu_variant_image"
-	UpdateMenuVariantCostQuery      = "update_menu_variant_cost"
-)
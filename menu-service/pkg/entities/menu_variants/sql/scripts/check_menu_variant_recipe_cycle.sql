@@ -0,0 +1,21 @@
+-- Report whether a menu variant uses itself: follow its "any variant of a sub-category"
+-- ingredients through the live variants of each sub-category, and their ingredients, and
+-- check whether the variant is reached again
+WITH RECURSIVE reachable(sub_category_id) AS (
+    SELECT mi.menu_sub_category_id
+    FROM menu_ingredients mi
+    WHERE mi.menu_variant_id = @menu_variant_id
+      AND mi.menu_sub_category_id IS NOT NULL
+  UNION
+    SELECT mi.menu_sub_category_id
+    FROM reachable r
+    JOIN menu_variants mv ON mv.sub_category_id = r.sub_category_id AND mv.deleted_at IS NULL
+    JOIN menu_ingredients mi ON mi.menu_variant_id = mv.id
+    WHERE mi.menu_sub_category_id IS NOT NULL
+)
+SELECT EXISTS (
+    SELECT 1
+    FROM reachable r
+    JOIN menu_variants mv ON mv.sub_category_id = r.sub_category_id
+    WHERE mv.id = @menu_variant_id AND mv.deleted_at IS NULL
+);
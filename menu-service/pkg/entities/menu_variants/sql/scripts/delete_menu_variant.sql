@@ -1 +1 @@
-DELETE FROM menu_variants WHERE id = $1;
+UPDATE menu_variants SET deleted_at = CURRENT_TIMESTAMP WHERE id = @id AND deleted_at IS NULL;
@@ -1,7 +1,7 @@
 SELECT mi.id, mi.name, mi.description, mi.sub_category_id, sm.name as sub_category_name,
        sm.item_type, mi.price, mi.item_cost, mi.happy_hour_price, mi.image_url, mi.is_available,
        mi.preparation_time, mi.menu_types, mi.dietary_tags, mi.allergens, mi.is_alcoholic,
-       mi.display_order, mi.created_at, mi.updated_at
+       mi.display_order, mi.created_at, mi.updated_at, mi.version
 FROM menu_variants mi
 LEFT JOIN menu_sub_categories sm ON mi.sub_category_id = sm.id
-WHERE mi.id = $1;
+WHERE mi.id = @id AND mi.deleted_at IS NULL;
@@ -0,0 +1,21 @@
+-- Menu variants that can be ordered now: live, available, and either none of their menu types
+-- names a service period, or one names a period that is open
+SELECT mi.id, mi.name, mi.description, mi.sub_category_id, sm.name as sub_category_name,
+       sm.category_id, sm.item_type, mi.price, mi.item_cost, mi.happy_hour_price, mi.image_url,
+       mi.is_available, mi.preparation_time, mi.menu_types, mi.dietary_tags, mi.allergens,
+       mi.is_alcoholic, mi.display_order, mi.created_at, mi.updated_at, mi.version,
+       mi.deleted_at
+FROM menu_variants mi
+LEFT JOIN menu_sub_categories sm ON mi.sub_category_id = sm.id
+WHERE mi.deleted_at IS NULL
+  AND mi.is_available = true
+  AND (
+    NOT EXISTS (
+        SELECT 1 FROM jsonb_array_elements_text(mi.menu_types) AS t(menu_type)
+        WHERE lower(t.menu_type) = ANY(@period_names::text[])
+    )
+    OR EXISTS (
+        SELECT 1 FROM jsonb_array_elements_text(mi.menu_types) AS t(menu_type)
+        WHERE lower(t.menu_type) = ANY(@open_period_names::text[])
+    )
+  );
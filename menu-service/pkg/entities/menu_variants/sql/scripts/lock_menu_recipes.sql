@@ -0,0 +1,3 @@
+-- Serialize changes to the recipe graph (ingredients and sub-category membership) until the
+-- transaction ends, so two writes can't close a cycle that neither sees alone
+SELECT pg_advisory_xact_lock(hashtext('menu_recipes'));
@@ -0,0 +1,3 @@
+-- Share the recipe lock with other cost recomputes that leave the graph as it is, while
+-- keeping out the changes to it that take it exclusively
+SELECT pg_advisory_xact_lock_shared(hashtext('menu_recipes'));
@@ -0,0 +1,6 @@
+-- Lock the menu variants whose costs are about to be stored, in id order so two
+-- recomputes reaching the same variants can't deadlock
+SELECT id FROM menu_variants
+WHERE id = ANY(@ids)
+ORDER BY id
+FOR UPDATE;
@@ -0,0 +1 @@
+SELECT price FROM menu_variants WHERE id = @id FOR UPDATE;
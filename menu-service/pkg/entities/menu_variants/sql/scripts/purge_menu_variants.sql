@@ -0,0 +1,11 @@
+-- Hard delete menu items soft deleted before the retention window that no order or combo
+-- references.
+-- Their recipe ingredients and customer favorites go with them.
+WITH purged AS (
+    DELETE FROM menu_variants mv
+    WHERE mv.deleted_at < @deleted_before
+      AND NOT EXISTS (SELECT 1 FROM order_items oi WHERE oi.menu_variant_id = mv.id)
+      AND NOT EXISTS (SELECT 1 FROM combo_slot_options cso WHERE cso.menu_variant_id = mv.id)
+    RETURNING mv.id
+)
+SELECT COUNT(*) FROM purged;
@@ -0,0 +1 @@
+UPDATE menu_variants SET deleted_at = NULL WHERE id = @id AND deleted_at IS NOT NULL;
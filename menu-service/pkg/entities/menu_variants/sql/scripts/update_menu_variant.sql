@@ -1,19 +1,19 @@
 UPDATE menu_variants
-SET name = COALESCE($2, name),
-    description = COALESCE($3, description),
-    sub_category_id = COALESCE($4, sub_category_id),
-    price = COALESCE($5, price),
-    happy_hour_price = COALESCE($6, happy_hour_price),
-    image_url = COALESCE($7, image_url),
-    is_available = COALESCE($8, is_available),
-    preparation_time = COALESCE($9, preparation_time),
-    menu_types = COALESCE($10, menu_types),
-    dietary_tags = COALESCE($11, dietary_tags),
-    allergens = COALESCE($12, allergens),
-    is_alcoholic = COALESCE($13, is_alcoholic),
-    display_order = COALESCE($14, display_order),
+SET name = COALESCE(@name, name),
+    description = COALESCE(@description, description),
+    sub_category_id = COALESCE(@sub_category_id, sub_category_id),
+    price = COALESCE(@price, price),
+    happy_hour_price = COALESCE(@happy_hour_price, happy_hour_price),
+    image_url = COALESCE(@image_url, image_url),
+    is_available = COALESCE(@is_available, is_available),
+    preparation_time = COALESCE(@preparation_time, preparation_time),
+    menu_types = COALESCE(@menu_types, menu_types),
+    dietary_tags = COALESCE(@dietary_tags, dietary_tags),
+    allergens = COALESCE(@allergens, allergens),
+    is_alcoholic = COALESCE(@is_alcoholic, is_alcoholic),
+    display_order = COALESCE(@display_order, display_order),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
+WHERE id = @id AND version = @version AND deleted_at IS NULL
 RETURNING id, name, description, sub_category_id, price, item_cost, happy_hour_price, image_url,
           is_available, preparation_time, menu_types, dietary_tags, allergens, is_alcoholic,
-          display_order, created_at, updated_at;
+          display_order, created_at, updated_at, version;
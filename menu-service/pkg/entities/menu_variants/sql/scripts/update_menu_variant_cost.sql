@@ -1,7 +1,7 @@
 UPDATE menu_variants
-SET item_cost = $2,
+SET item_cost = @item_cost,
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
+WHERE id = @id AND deleted_at IS NULL
 RETURNING id, name, description, sub_category_id, price, item_cost, happy_hour_price, image_url,
           is_available, preparation_time, menu_types, dietary_tags, allergens, is_alcoholic,
-          display_order, created_at, updated_at;
+          display_order, created_at, updated_at, version;
@@ -0,0 +1,517 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"menu-service/pkg/entities/modifier_groups/models"
+	modifierGroupSQL "menu-service/pkg/entities/modifier_groups/sql"
+	"shared/audit"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// auditEntity names the entity in the audit log
+const auditEntity = "modifier_group"
+
+// DBHandler handles database operations for modifier groups, their options and the menu
+// variants and sub-categories they are attached to
+type DBHandler struct {
+	db      *sharedDb.DbHandler
+	audit   *audit.Log
+	queries *queries.Registry
+	logger  *logrus.Logger
+}
+
+// NewDBHandler creates a new database handler
+func NewDBHandler(db *sharedDb.DbHandler, auditLog *audit.Log, logger *logrus.Logger) (*DBHandler, error) {
+	queries, err := modifierGroupSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DBHandler{
+		db:      db,
+		audit:   auditLog,
+		queries: queries,
+		logger:  logger,
+	}, nil
+}
+
+// rowScanner is a single row or the current row of a result set
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanModifierGroup reads a modifier group without its options and links, with
+// deleted_at when withDeleted is set
+func scanModifierGroup(row rowScanner, withDeleted bool) (*models.ModifierGroup, error) {
+	var group models.ModifierGroup
+	var description sql.NullString
+	var maxSelections sql.NullInt64
+
+	dest := []interface{}{&group.ID, &group.Name, &description, &group.MinSelections, &maxSelections, &group.DisplayOrder, &group.CreatedAt, &group.UpdatedAt, &group.Version}
+	if withDeleted {
+		dest = append(dest, &group.DeletedAt)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if description.Valid {
+		group.Description = &description.String
+	}
+	if maxSelections.Valid {
+		max := int(maxSelections.Int64)
+		group.MaxSelections = &max
+	}
+	group.Options = []models.ModifierOption{}
+	group.MenuVariantIDs = []string{}
+	group.MenuSubCategoryIDs = []string{}
+	return &group, nil
+}
+
+// scanModifierGroups reads the groups of a result set and fills in their options and links
+func (h *DBHandler) scanModifierGroups(ctx context.Context, rows *sql.Rows, withDeleted bool) ([]models.ModifierGroup, error) {
+	defer rows.Close()
+
+	var groups []models.ModifierGroup
+	for rows.Next() {
+		group, err := scanModifierGroup(rows, withDeleted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan modifier group: %w", err)
+		}
+		groups = append(groups, *group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating modifier groups: %w", err)
+	}
+	rows.Close()
+
+	if err := h.loadDetails(ctx, groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// loadDetails fills in the options and links of the groups
+func (h *DBHandler) loadDetails(ctx context.Context, groups []models.ModifierGroup) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	byID := make(map[string]*models.ModifierGroup, len(groups))
+	ids := make(pq.StringArray, 0, len(groups))
+	for i := range groups {
+		byID[groups[i].ID] = &groups[i]
+		ids = append(ids, groups[i].ID)
+	}
+
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(modifierGroupSQL.ListModifierOptionsQuery), queries.Args{"modifier_group_ids": ids})
+	if err != nil {
+		return fmt.Errorf("failed to list modifier options: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var option models.ModifierOption
+		var groupID string
+		var quantity sql.NullFloat64
+		if err := rows.Scan(&option.ID, &groupID, &option.Name, &option.PriceDelta, &option.StockVariantID, &quantity, &option.IsDefault, &option.DisplayOrder); err != nil {
+			return fmt.Errorf("failed to scan modifier option: %w", err)
+		}
+		if quantity.Valid {
+			option.Quantity = &quantity.Float64
+		}
+		byID[groupID].Options = append(byID[groupID].Options, option)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating modifier options: %w", err)
+	}
+	rows.Close()
+
+	links, err := h.db.QueryNamedContext(ctx, h.queries.Get(modifierGroupSQL.ListModifierGroupLinksQuery), queries.Args{"modifier_group_ids": ids})
+	if err != nil {
+		return fmt.Errorf("failed to list modifier group links: %w", err)
+	}
+	defer links.Close()
+	for links.Next() {
+		var groupID string
+		var variantID, subCategoryID sql.NullString
+		if err := links.Scan(&groupID, &variantID, &subCategoryID); err != nil {
+			return fmt.Errorf("failed to scan modifier group link: %w", err)
+		}
+		group := byID[groupID]
+		if variantID.Valid {
+			group.MenuVariantIDs = append(group.MenuVariantIDs, variantID.String)
+		} else {
+			group.MenuSubCategoryIDs = append(group.MenuSubCategoryIDs, subCategoryID.String)
+		}
+	}
+	if err := links.Err(); err != nil {
+		return fmt.Errorf("error iterating modifier group links: %w", err)
+	}
+	return nil
+}
+
+// List returns a page of modifier groups matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.ModifierGroupListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(modifierGroupSQL.ListModifierGroupsQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build modifier groups count: %w", err)
+	}
+
+	var total int
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count modifier groups: %w", err)
+	}
+
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(modifierGroupSQL.ListModifierGroupsQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build modifier groups list: %w", err)
+	}
+
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modifier groups: %w", err)
+	}
+	groups, err := h.scanModifierGroups(ctx, rows, true)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, pageInfo, err := queryspec.Paginate(spec, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ModifierGroupListResponse{
+		ModifierGroups: groups,
+		Total:          total,
+		Limit:          spec.Limit,
+		PageInfo:       pageInfo,
+	}, nil
+}
+
+// GetByID returns a modifier group by ID with its options and links
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.ModifierGroup, error) {
+	group, err := scanModifierGroup(h.db.QueryRowNamedContext(ctx, h.queries.Get(modifierGroupSQL.GetModifierGroupByIDQuery), queries.Args{"id": id}), false)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get modifier group: %w", err)
+	}
+
+	groups := []models.ModifierGroup{*group}
+	if err := h.loadDetails(ctx, groups); err != nil {
+		return nil, err
+	}
+	return &groups[0], nil
+}
+
+// ForMenuVariant returns the modifier groups of a menu variant, attached to it or to its
+// sub-category, in display order
+func (h *DBHandler) ForMenuVariant(ctx context.Context, menuVariantID string) ([]models.ModifierGroup, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(modifierGroupSQL.ListMenuVariantModifierGroupsQuery), queries.Args{"menu_variant_id": menuVariantID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list menu variant modifier groups: %w", err)
+	}
+	groups, err := h.scanModifierGroups(ctx, rows, false)
+	if err != nil {
+		return nil, err
+	}
+	if groups == nil {
+		groups = []models.ModifierGroup{}
+	}
+	return groups, nil
+}
+
+// Create creates a new modifier group with its options and links
+func (h *DBHandler) Create(ctx context.Context, req *models.ModifierGroupCreateRequest) (*models.ModifierGroup, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.ModifierGroup, error) {
+		return h.create(ctx, req)
+	})
+}
+
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.ModifierGroupCreateRequest) (*models.ModifierGroup, error) {
+	var group *models.ModifierGroup
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		var id string
+		err := h.db.QueryRowNamedContext(ctx, h.queries.Get(modifierGroupSQL.CreateModifierGroupQuery), queries.Args{
+			"name":           req.Name,
+			"description":    req.Description,
+			"min_selections": req.MinSelections,
+			"max_selections": req.MaxSelections,
+			"display_order":  req.DisplayOrder,
+		}).Scan(&id)
+		if err != nil {
+			return h.writeError("create", err)
+		}
+
+		if err := h.writeOptions(ctx, id, req.Options); err != nil {
+			return err
+		}
+		if err := h.writeLinks(ctx, id, true, req.MenuVariantIDs); err != nil {
+			return err
+		}
+		if err := h.writeLinks(ctx, id, false, req.MenuSubCategoryIDs); err != nil {
+			return err
+		}
+
+		group, err = h.checked(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger.WithField("id", group.ID).Info("Modifier group created")
+	return group, nil
+}
+
+// Update updates an existing modifier group if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.ModifierGroupUpdateRequest) (*models.ModifierGroup, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.ModifierGroup, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
+
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.ModifierGroupUpdateRequest) (*models.ModifierGroup, error) {
+	var group *models.ModifierGroup
+	err := h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		var updatedID string
+		err := h.db.QueryRowNamedContext(ctx, h.queries.Get(modifierGroupSQL.UpdateModifierGroupQuery), queries.Args{
+			"id":             id,
+			"version":        version,
+			"name":           req.Name,
+			"description":    req.Description,
+			"min_selections": req.MinSelections,
+			"max_selections": req.MaxSelections,
+			"display_order":  req.DisplayOrder,
+		}).Scan(&updatedID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return h.versionMismatch(ctx, id)
+			}
+			return h.writeError("update", err)
+		}
+
+		if req.Options != nil {
+			if err := h.writeOptions(ctx, id, *req.Options); err != nil {
+				return err
+			}
+		}
+		if req.MenuVariantIDs != nil {
+			if err := h.writeLinks(ctx, id, true, *req.MenuVariantIDs); err != nil {
+				return err
+			}
+		}
+		if req.MenuSubCategoryIDs != nil {
+			if err := h.writeLinks(ctx, id, false, *req.MenuSubCategoryIDs); err != nil {
+				return err
+			}
+		}
+
+		group, err = h.checked(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, nil
+	}
+
+	h.logger.WithField("id", group.ID).Info("Modifier group updated")
+	return group, nil
+}
+
+// checked reads the group back and checks its options can satisfy its selection rules,
+// failing the transaction when they can't
+func (h *DBHandler) checked(ctx context.Context, id string) (*models.ModifierGroup, error) {
+	group, err := h.GetByID(ctx, id)
+	if err != nil || group == nil {
+		return group, err
+	}
+	if err := group.Validate(); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// writeOptions replaces the options of a group: options with an ID are updated, the others
+// added, and options left out removed
+func (h *DBHandler) writeOptions(ctx context.Context, groupID string, options []models.ModifierOptionRequest) error {
+	keep := pq.StringArray{}
+	for _, option := range options {
+		if option.ID != nil {
+			keep = append(keep, *option.ID)
+		}
+	}
+	if _, err := h.db.ExecNamedContext(ctx, h.queries.Get(modifierGroupSQL.DeleteModifierOptionsQuery), queries.Args{
+		"modifier_group_id": groupID,
+		"keep_ids":          keep,
+	}); err != nil {
+		return fmt.Errorf("failed to remove modifier options: %w", err)
+	}
+
+	for i, option := range options {
+		args := queries.Args{
+			"modifier_group_id": groupID,
+			"name":              option.Name,
+			"price_delta":       option.PriceDelta,
+			"stock_variant_id":  option.StockVariantID,
+			"quantity":          option.Quantity,
+			"is_default":        option.IsDefault,
+			"display_order":     option.DisplayOrder,
+		}
+		if option.ID == nil {
+			if _, err := h.db.ExecNamedContext(ctx, h.queries.Get(modifierGroupSQL.CreateModifierOptionQuery), args); err != nil {
+				return h.optionError(i, err)
+			}
+			continue
+		}
+
+		args["id"] = *option.ID
+		result, err := h.db.ExecNamedContext(ctx, h.queries.Get(modifierGroupSQL.UpdateModifierOptionQuery), args)
+		if err != nil {
+			return h.optionError(i, err)
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			return sharedErrors.Validation("invalid_modifier_group", "invalid modifier group", sharedErrors.FieldError{
+				Field: fmt.Sprintf("options[%d]", i), Code: "unknown_option", Message: "the option is not in this modifier group",
+			})
+		}
+	}
+	return nil
+}
+
+// optionError reports a name another option of the group has or a missing stock variant
+// against the option at index i
+func (h *DBHandler) optionError(i int, err error) error {
+	if dbErr, _ := sharedErrors.As(err); dbErr != nil && (dbErr.Code == "unique_violation" || dbErr.Code == "foreign_key_violation") {
+		return sharedErrors.Validation("invalid_modifier_group", "invalid modifier group", sharedErrors.FieldError{
+			Field: fmt.Sprintf("options[%d]", i), Code: dbErr.Code, Message: dbErr.Message,
+		}).Wrap(err)
+	}
+	return fmt.Errorf("failed to write modifier option: %w", err)
+}
+
+// writeLinks replaces the menu variants, or the sub-categories when variants is false, a
+// group is attached to
+func (h *DBHandler) writeLinks(ctx context.Context, groupID string, variants bool, ids []string) error {
+	if _, err := h.db.ExecNamedContext(ctx, h.queries.Get(modifierGroupSQL.DeleteModifierGroupLinksQuery), queries.Args{
+		"modifier_group_id": groupID,
+		"variants":          variants,
+	}); err != nil {
+		return fmt.Errorf("failed to remove modifier group links: %w", err)
+	}
+
+	field := "menu_sub_category_ids"
+	if variants {
+		field = "menu_variant_ids"
+	}
+	for i, id := range ids {
+		args := queries.Args{"modifier_group_id": groupID, "menu_variant_id": nil, "menu_sub_category_id": nil}
+		if variants {
+			args["menu_variant_id"] = id
+		} else {
+			args["menu_sub_category_id"] = id
+		}
+		if _, err := h.db.ExecNamedContext(ctx, h.queries.Get(modifierGroupSQL.CreateModifierGroupLinkQuery), args); err != nil {
+			return h.linkError(field, i, err)
+		}
+	}
+	return nil
+}
+
+// linkError reports an unknown or repeated id against the entry at index i of field
+func (h *DBHandler) linkError(field string, i int, err error) error {
+	if dbErr, _ := sharedErrors.As(err); dbErr != nil && (dbErr.Code == "unique_violation" || dbErr.Code == "foreign_key_violation") {
+		return sharedErrors.Validation("invalid_modifier_group", "invalid modifier group", sharedErrors.FieldError{
+			Field: fmt.Sprintf("%s[%d]", field, i), Code: dbErr.Code, Message: dbErr.Message,
+		}).Wrap(err)
+	}
+	return fmt.Errorf("failed to attach modifier group: %w", err)
+}
+
+// writeError reports a name another live group already has as a conflict
+func (h *DBHandler) writeError(action string, err error) error {
+	if dbErr, _ := sharedErrors.As(err); dbErr != nil && dbErr.Code == "unique_violation" {
+		return sharedErrors.Conflict("modifier_group_name_taken", "another modifier group already has this name").Wrap(err)
+	}
+	return fmt.Errorf("failed to %s modifier group: %w", action, err)
+}
+
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return err
+	}
+	return sharedErrors.PreconditionFailed("modifier_group_version_mismatch", "modifier group was modified by another request")
+}
+
+// Delete deletes a modifier group
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
+
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(modifierGroupSQL.DeleteModifierGroupQuery), queries.Args{"id": id})
+	if err != nil {
+		return fmt.Errorf("failed to delete modifier group: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sharedErrors.NotFound("modifier_group_not_found", "modifier group not found")
+	}
+
+	h.logger.WithField("id", id).Info("Modifier group deleted")
+	return nil
+}
+
+// Restore brings back a soft deleted modifier group
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.ModifierGroup, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.ModifierGroup, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.ModifierGroup, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(modifierGroupSQL.RestoreModifierGroupQuery), queries.Args{"id": id})
+	if err != nil {
+		return nil, h.writeError("restore", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("modifier_group_not_found", "deleted modifier group not found")
+	}
+
+	h.logger.WithField("id", id).Info("Modifier group restored")
+	return h.GetByID(ctx, id)
+}
+
+// Purge hard deletes the modifier groups soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(modifierGroupSQL.PurgeModifierGroupsQuery), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge modifier groups: %w", err)
+	}
+	return purged, nil
+}
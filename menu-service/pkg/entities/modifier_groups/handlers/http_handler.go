@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"menu-service/pkg/entities/modifier_groups/models"
+	modifierGroupSQL "menu-service/pkg/entities/modifier_groups/sql"
+	sharedHttp "shared/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPHandler handles HTTP requests for modifier groups
+type HTTPHandler struct {
+	dbHandler *DBHandler
+	logger    *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{dbHandler: dbHandler, logger: logger}
+}
+
+// List handles GET /api/v1/menu/modifier-groups
+func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
+	spec, err := modifierGroupSQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
+	}
+
+	response, err := h.dbHandler.List(r.Context(), spec)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list modifier groups")
+		sharedHttp.SendError(w, r, err, "Failed to list modifier groups")
+		return
+	}
+	response.SetLinks(r.URL)
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Modifier groups retrieved", response)
+}
+
+// GetByID handles GET /api/v1/menu/modifier-groups/:id
+func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	group, err := h.dbHandler.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get modifier group")
+		sharedHttp.SendError(w, r, err, "Failed to get modifier group")
+		return
+	}
+
+	if group == nil {
+		sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Modifier group not found")
+		return
+	}
+
+	sharedHttp.SetETag(w, group.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Modifier group retrieved", group)
+}
+
+// Create handles POST /api/v1/menu/modifier-groups
+func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.ModifierGroupCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid modifier group")
+		return
+	}
+
+	group, err := h.dbHandler.Create(r.Context(), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create modifier group")
+		sharedHttp.SendError(w, r, err, "Failed to create modifier group")
+		return
+	}
+
+	sharedHttp.SetETag(w, group.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Modifier group created", group)
+}
+
+// Update handles PUT /api/v1/menu/modifier-groups/:id
+func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update modifier group")
+		return
+	}
+
+	var req models.ModifierGroupUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid modifier group")
+		return
+	}
+
+	group, err := h.dbHandler.Update(r.Context(), id, version, &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to update modifier group")
+		sharedHttp.SendError(w, r, err, "Failed to update modifier group")
+		return
+	}
+
+	if group == nil {
+		sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Modifier group not found")
+		return
+	}
+
+	sharedHttp.SetETag(w, group.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Modifier group updated", group)
+}
+
+// Delete handles DELETE /api/v1/menu/modifier-groups/:id
+func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.dbHandler.Delete(r.Context(), id); err != nil {
+		h.logger.WithError(err).Error("Failed to delete modifier group")
+		sharedHttp.SendError(w, r, err, "Failed to delete modifier group")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Modifier group deleted", nil)
+}
+
+// Restore handles POST /api/v1/menu/modifier-groups/:id/restore
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	group, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore modifier group")
+		sharedHttp.SendError(w, r, err, "Failed to restore modifier group")
+		return
+	}
+
+	sharedHttp.SetETag(w, group.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Modifier group restored", group)
+}
@@ -0,0 +1,247 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"shared/money"
+)
+
+// ModifierOption is one choice of a modifier group, e.g. "tonic" or "extra shot". Picking
+// it adds PriceDelta to the variant's price, negative lowers it, and consumes Quantity of
+// StockVariantID when set.
+type ModifierOption struct {
+	ID             string      `json:"id"`
+	Name           string      `json:"name"`
+	PriceDelta     money.Money `json:"price_delta"`
+	StockVariantID *string     `json:"stock_variant_id,omitempty"`
+	Quantity       *float64    `json:"quantity,omitempty"`
+	IsDefault      bool        `json:"is_default"`
+	DisplayOrder   int         `json:"display_order"`
+}
+
+// ModifierGroup is a set of options picked when ordering a menu variant, between
+// MinSelections and MaxSelections of them. It applies to the menu variants it is attached
+// to and to every variant of the sub-categories it is attached to.
+type ModifierGroup struct {
+	ID                 string           `json:"id"`
+	Name               string           `json:"name"`
+	Description        *string          `json:"description,omitempty"`
+	MinSelections      int              `json:"min_selections"`
+	MaxSelections      *int             `json:"max_selections"` // null allows every option
+	DisplayOrder       int              `json:"display_order"`
+	Options            []ModifierOption `json:"options"`
+	MenuVariantIDs     []string         `json:"menu_variant_ids"`
+	MenuSubCategoryIDs []string         `json:"menu_sub_category_ids"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+	Version            int              `json:"version"`
+	DeletedAt          *time.Time       `json:"deleted_at,omitempty"`
+}
+
+// Validate checks the group's options can satisfy its selection rules
+func (g *ModifierGroup) Validate() error {
+	var fieldErrors []sharedErrors.FieldError
+	if g.MinSelections > len(g.Options) {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "min_selections", Code: "too_few_options",
+			Message: fmt.Sprintf("min_selections is %d but the group has %d options", g.MinSelections, len(g.Options))})
+	}
+
+	defaults := 0
+	for _, option := range g.Options {
+		if option.IsDefault {
+			defaults++
+		}
+	}
+	if g.MaxSelections != nil && defaults > *g.MaxSelections {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "options", Code: "too_many_defaults",
+			Message: fmt.Sprintf("%d options are default but max_selections is %d", defaults, *g.MaxSelections)})
+	}
+
+	if len(fieldErrors) > 0 {
+		return sharedErrors.Validation("invalid_modifier_group", "invalid modifier group", fieldErrors...)
+	}
+	return nil
+}
+
+// Select checks the picked options satisfy every group and returns the price they add
+// with the options that apply. optionIDs are the picked options of all the groups of a
+// menu variant; a group nothing is picked from gets its default options.
+func Select(groups []ModifierGroup, optionIDs []string) (money.Money, []ModifierOption, error) {
+	picked := map[string]bool{}
+	for _, id := range optionIDs {
+		if picked[id] {
+			return money.Money{}, nil, sharedErrors.Validation("duplicate_modifier_option", fmt.Sprintf("modifier option %s is picked twice", id))
+		}
+		picked[id] = true
+	}
+
+	var total money.Money
+	var selected []ModifierOption
+	var fieldErrors []sharedErrors.FieldError
+	for _, group := range groups {
+		var chosen []ModifierOption
+		for _, option := range group.Options {
+			if picked[option.ID] {
+				chosen = append(chosen, option)
+				delete(picked, option.ID)
+			}
+		}
+		if len(chosen) == 0 {
+			for _, option := range group.Options {
+				if option.IsDefault {
+					chosen = append(chosen, option)
+				}
+			}
+		}
+
+		switch {
+		case len(chosen) < group.MinSelections:
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: group.Name, Code: "too_few_selections",
+				Message: fmt.Sprintf("pick at least %d of %s", group.MinSelections, group.Name)})
+		case group.MaxSelections != nil && len(chosen) > *group.MaxSelections:
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: group.Name, Code: "too_many_selections",
+				Message: fmt.Sprintf("pick at most %d of %s", *group.MaxSelections, group.Name)})
+		}
+		for _, option := range chosen {
+			total = total.Add(option.PriceDelta)
+		}
+		selected = append(selected, chosen...)
+	}
+	for id := range picked {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: id, Code: "unknown_option",
+			Message: "the option is not in a modifier group of the menu variant"})
+	}
+
+	if len(fieldErrors) > 0 {
+		return money.Money{}, nil, sharedErrors.Validation("invalid_modifier_selection", "invalid modifier selection", fieldErrors...)
+	}
+	return total, selected, nil
+}
+
+// ModifierOptionRequest is an option of a create or update request; an update keeps the
+// options with an ID, adds those without and removes the rest
+type ModifierOptionRequest struct {
+	ID             *string     `json:"id,omitempty"`
+	Name           string      `json:"name"`
+	PriceDelta     money.Money `json:"price_delta"`
+	StockVariantID *string     `json:"stock_variant_id,omitempty"`
+	Quantity       *float64    `json:"quantity,omitempty"`
+	IsDefault      bool        `json:"is_default,omitempty"`
+	DisplayOrder   int         `json:"display_order,omitempty"`
+}
+
+// ModifierGroupCreateRequest represents a request to create a modifier group
+type ModifierGroupCreateRequest struct {
+	Name               string                  `json:"name"`
+	Description        *string                 `json:"description,omitempty"`
+	MinSelections      int                     `json:"min_selections"`
+	MaxSelections      *int                    `json:"max_selections,omitempty"`
+	DisplayOrder       int                     `json:"display_order,omitempty"`
+	Options            []ModifierOptionRequest `json:"options"`
+	MenuVariantIDs     []string                `json:"menu_variant_ids,omitempty"`
+	MenuSubCategoryIDs []string                `json:"menu_sub_category_ids,omitempty"`
+}
+
+// Validate checks the fields of the request on their own
+func (r *ModifierGroupCreateRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	var fieldErrors []sharedErrors.FieldError
+	if r.Name == "" {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "name", Code: "required", Message: "name is required"})
+	}
+	fieldErrors = append(fieldErrors, validateSelections(&r.MinSelections, r.MaxSelections)...)
+	fieldErrors = append(fieldErrors, validateOptions(r.Options)...)
+
+	if len(fieldErrors) > 0 {
+		return sharedErrors.Validation("invalid_modifier_group", "invalid modifier group", fieldErrors...)
+	}
+	return nil
+}
+
+// ModifierGroupUpdateRequest represents a request to update a modifier group. Options,
+// MenuVariantIDs and MenuSubCategoryIDs replace the current ones when set.
+type ModifierGroupUpdateRequest struct {
+	Name               *string                  `json:"name,omitempty"`
+	Description        *string                  `json:"description,omitempty"`
+	MinSelections      *int                     `json:"min_selections,omitempty"`
+	MaxSelections      *int                     `json:"max_selections,omitempty"`
+	DisplayOrder       *int                     `json:"display_order,omitempty"`
+	Options            *[]ModifierOptionRequest `json:"options,omitempty"`
+	MenuVariantIDs     *[]string                `json:"menu_variant_ids,omitempty"`
+	MenuSubCategoryIDs *[]string                `json:"menu_sub_category_ids,omitempty"`
+}
+
+// Validate checks the fields being changed on their own
+func (r *ModifierGroupUpdateRequest) Validate() error {
+	var fieldErrors []sharedErrors.FieldError
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		r.Name = &name
+		if name == "" {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "name", Code: "required", Message: "name can't be empty"})
+		}
+	}
+	fieldErrors = append(fieldErrors, validateSelections(r.MinSelections, r.MaxSelections)...)
+	if r.Options != nil {
+		fieldErrors = append(fieldErrors, validateOptions(*r.Options)...)
+	}
+
+	if len(fieldErrors) > 0 {
+		return sharedErrors.Validation("invalid_modifier_group", "invalid modifier group", fieldErrors...)
+	}
+	return nil
+}
+
+// validateSelections checks the selection bounds that are set
+func validateSelections(min, max *int) []sharedErrors.FieldError {
+	var fieldErrors []sharedErrors.FieldError
+	if min != nil && *min < 0 {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "min_selections", Code: "invalid_selections", Message: "min_selections can't be negative"})
+	}
+	if max != nil && *max < 1 {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "max_selections", Code: "invalid_selections", Message: "max_selections must be at least 1"})
+	}
+	if min != nil && max != nil && *max < *min {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "max_selections", Code: "invalid_selections", Message: "max_selections can't be below min_selections"})
+	}
+	return fieldErrors
+}
+
+// validateOptions checks every option has a name unique in the group and consumes stock
+// in a positive quantity
+func validateOptions(options []ModifierOptionRequest) []sharedErrors.FieldError {
+	var fieldErrors []sharedErrors.FieldError
+	names := map[string]bool{}
+	for i := range options {
+		option := &options[i]
+		field := fmt.Sprintf("options[%d]", i)
+		option.Name = strings.TrimSpace(option.Name)
+
+		switch {
+		case option.Name == "":
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "required", Message: "name is required"})
+		case names[strings.ToLower(option.Name)]:
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "duplicate_option", Message: fmt.Sprintf("option %q is listed twice", option.Name)})
+		}
+		names[strings.ToLower(option.Name)] = true
+
+		if (option.StockVariantID == nil) != (option.Quantity == nil) {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "invalid_stock", Message: "stock_variant_id and quantity are set together"})
+		} else if option.Quantity != nil && *option.Quantity <= 0 {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: field, Code: "invalid_stock", Message: "quantity must be positive"})
+		}
+	}
+	return fieldErrors
+}
+
+// ModifierGroupListResponse represents a paginated list of modifier groups
+type ModifierGroupListResponse struct {
+	ModifierGroups []ModifierGroup `json:"modifier_groups"`
+	Total          int             `json:"total"`
+	Limit          int             `json:"limit"`
+	queryspec.PageInfo
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"testing"
+
+	sharedErrors "shared/errors"
+	"shared/money"
+)
+
+func intPtr(v int) *int { return &v }
+
+func option(id string, delta string, isDefault bool) ModifierOption {
+	return ModifierOption{ID: id, Name: id, PriceDelta: money.MustParse(delta), IsDefault: isDefault}
+}
+
+func TestSelect(t *testing.T) {
+	mixer := ModifierGroup{Name: "mixer", MinSelections: 1, MaxSelections: intPtr(1), Options: []ModifierOption{
+		option("tonic", "0", true), option("ginger", "300", false),
+	}}
+	extras := ModifierGroup{Name: "extras", MaxSelections: intPtr(2), Options: []ModifierOption{
+		option("shot", "500", false), option("lime", "0", false), option("no-ice", "0", false),
+	}}
+	groups := []ModifierGroup{mixer, extras}
+
+	cases := []struct {
+		picked []string
+		want   string
+		code   string
+	}{
+		{nil, "0", ""}, // the default tonic
+		{[]string{"ginger", "shot"}, "800", ""},
+		{[]string{"tonic", "ginger"}, "", "too_many_selections"},
+		{[]string{"shot", "lime", "no-ice"}, "", "too_many_selections"},
+		{[]string{"olive"}, "", "unknown_option"},
+		{[]string{"shot", "shot"}, "", "duplicate_modifier_option"},
+	}
+	for _, c := range cases {
+		total, _, err := Select(groups, c.picked)
+		if c.code == "" {
+			if err != nil || !total.Equal(money.MustParse(c.want)) {
+				t.Errorf("Select(%v) = %s, %v, want %s", c.picked, total, err, c.want)
+			}
+			continue
+		}
+		appErr, _ := sharedErrors.As(err)
+		if appErr == nil {
+			t.Errorf("Select(%v) succeeded, want %s", c.picked, c.code)
+			continue
+		}
+		found := appErr.Code == c.code
+		for _, fieldErr := range appErr.Fields {
+			found = found || fieldErr.Code == c.code
+		}
+		if !found {
+			t.Errorf("Select(%v) error = %v, want %s", c.picked, err, c.code)
+		}
+	}
+
+	// Without a default a required group must be picked from
+	mixer.Options[0].IsDefault = false
+	if _, _, err := Select([]ModifierGroup{mixer}, nil); err == nil {
+		t.Error("Select(nothing) succeeded with a required group and no default")
+	}
+}
+
+func TestCreateRequestValidate(t *testing.T) {
+	stock, quantity := "stock", 1.5
+	valid := ModifierGroupCreateRequest{Name: " Mixer ", MinSelections: 1, MaxSelections: intPtr(1), Options: []ModifierOptionRequest{
+		{Name: "Tonic", StockVariantID: &stock, Quantity: &quantity},
+		{Name: "Soda"},
+	}}
+	if err := valid.Validate(); err != nil || valid.Name != "Mixer" {
+		t.Errorf("Validate() = %v with name %q, want valid Mixer", err, valid.Name)
+	}
+
+	for name, bad := range map[string]ModifierGroupCreateRequest{
+		"no name":           {Options: []ModifierOptionRequest{{Name: "Tonic"}}},
+		"max below min":     {Name: "Mixer", MinSelections: 2, MaxSelections: intPtr(1)},
+		"duplicate option":  {Name: "Mixer", Options: []ModifierOptionRequest{{Name: "Tonic"}, {Name: "tonic"}}},
+		"stock no quantity": {Name: "Mixer", Options: []ModifierOptionRequest{{Name: "Tonic", StockVariantID: &stock}}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%s) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestGroupValidate(t *testing.T) {
+	group := ModifierGroup{MinSelections: 3, Options: []ModifierOption{option("a", "0", false), option("b", "0", false)}}
+	if err := group.Validate(); err == nil {
+		t.Error("Validate() succeeded with min_selections above the options")
+	}
+
+	group = ModifierGroup{MaxSelections: intPtr(1), Options: []ModifierOption{option("a", "0", true), option("b", "0", true)}}
+	if err := group.Validate(); err == nil {
+		t.Error("Validate() succeeded with more defaults than max_selections")
+	}
+}
@@ -0,0 +1,60 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	ListModifierGroupsQuery            queries.Name = "list_modifier_groups"
+	GetModifierGroupByIDQuery          queries.Name = "get_modifier_group_by_id"
+	ListMenuVariantModifierGroupsQuery queries.Name = "list_menu_variant_modifier_groups"
+	CreateModifierGroupQuery           queries.Name = "create_modifier_group"
+	UpdateModifierGroupQuery           queries.Name = "update_modifier_group"
+	DeleteModifierGroupQuery           queries.Name = "delete_modifier_group"
+	RestoreModifierGroupQuery          queries.Name = "restore_modifier_group"
+	PurgeModifierGroupsQuery           queries.Name = "purge_modifier_groups"
+	ListModifierOptionsQuery           queries.Name = "list_modifier_options"
+	CreateModifierOptionQuery          queries.Name = "create_modifier_option"
+	UpdateModifierOptionQuery          queries.Name = "update_modifier_option"
+	DeleteModifierOptionsQuery         queries.Name = "delete_modifier_options"
+	ListModifierGroupLinksQuery        queries.Name = "list_modifier_group_links"
+	DeleteModifierGroupLinksQuery      queries.Name = "delete_modifier_group_links"
+	CreateModifierGroupLinkQuery       queries.Name = "create_modifier_group_link"
+)
+
+// ListSchema whitelists the columns of list_modifier_groups that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("display_order,name",
+	queryspec.Field{Name: "name", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "min_selections", Type: queryspec.Number, Filterable: true},
+	queryspec.Field{Name: "display_order", Type: queryspec.Number, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).WithSoftDelete()
+
+// LoadQueries loads and validates the modifier group SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListModifierGroupsQuery,
+		GetModifierGroupByIDQuery,
+		ListMenuVariantModifierGroupsQuery,
+		CreateModifierGroupQuery,
+		UpdateModifierGroupQuery,
+		DeleteModifierGroupQuery,
+		RestoreModifierGroupQuery,
+		PurgeModifierGroupsQuery,
+		ListModifierOptionsQuery,
+		CreateModifierOptionQuery,
+		UpdateModifierOptionQuery,
+		DeleteModifierOptionsQuery,
+		ListModifierGroupLinksQuery,
+		DeleteModifierGroupLinksQuery,
+		CreateModifierGroupLinkQuery,
+	)
+}
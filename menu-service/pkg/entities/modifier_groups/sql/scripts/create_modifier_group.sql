@@ -0,0 +1,3 @@
+INSERT INTO modifier_groups (name, description, min_selections, max_selections, display_order)
+VALUES (@name, @description, @min_selections, @max_selections, @display_order)
+RETURNING id;
@@ -0,0 +1,3 @@
+INSERT INTO modifier_group_links (modifier_group_id, menu_variant_id, menu_sub_category_id)
+VALUES (@modifier_group_id, @menu_variant_id, @menu_sub_category_id)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+INSERT INTO modifier_options (modifier_group_id, name, price_delta, stock_variant_id, quantity, is_default, display_order)
+VALUES (@modifier_group_id, @name, @price_delta, @stock_variant_id, @quantity, @is_default, @display_order);
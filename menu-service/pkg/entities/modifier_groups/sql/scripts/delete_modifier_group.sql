@@ -0,0 +1 @@
+UPDATE modifier_groups SET deleted_at = CURRENT_TIMESTAMP WHERE id = @id AND deleted_at IS NULL;
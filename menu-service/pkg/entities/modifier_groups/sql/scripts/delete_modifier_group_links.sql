@@ -0,0 +1,4 @@
+-- Clears the menu variant or the sub-category links of a group before they are replaced
+DELETE FROM modifier_group_links
+WHERE modifier_group_id = @modifier_group_id
+  AND ((@variants AND menu_variant_id IS NOT NULL) OR (NOT @variants AND menu_sub_category_id IS NOT NULL));
@@ -0,0 +1,3 @@
+-- Removes the options of a group an update no longer lists
+DELETE FROM modifier_options
+WHERE modifier_group_id = @modifier_group_id AND NOT (id = ANY(@keep_ids::uuid[]));
@@ -0,0 +1,3 @@
+SELECT id, name, description, min_selections, max_selections, display_order, created_at, updated_at, version
+FROM modifier_groups
+WHERE id = @id AND deleted_at IS NULL;
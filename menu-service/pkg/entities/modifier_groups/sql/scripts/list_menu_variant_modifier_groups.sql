@@ -0,0 +1,12 @@
+-- Modifier groups attached to the menu variant or to its sub-category
+SELECT mg.id, mg.name, mg.description, mg.min_selections, mg.max_selections, mg.display_order,
+       mg.created_at, mg.updated_at, mg.version
+FROM modifier_groups mg
+WHERE mg.deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM modifier_group_links l
+    JOIN menu_variants mv ON mv.id = @menu_variant_id
+    WHERE l.modifier_group_id = mg.id
+      AND (l.menu_variant_id = mv.id OR l.menu_sub_category_id = mv.sub_category_id)
+  )
+ORDER BY mg.display_order, mg.name;
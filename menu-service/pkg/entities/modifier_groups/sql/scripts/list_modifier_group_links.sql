@@ -0,0 +1,4 @@
+SELECT modifier_group_id, menu_variant_id, menu_sub_category_id
+FROM modifier_group_links
+WHERE modifier_group_id = ANY(@modifier_group_ids::uuid[])
+ORDER BY created_at;
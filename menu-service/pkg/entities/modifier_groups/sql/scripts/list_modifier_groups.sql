@@ -0,0 +1,2 @@
+SELECT id, name, description, min_selections, max_selections, display_order, created_at, updated_at, version, deleted_at
+FROM modifier_groups;
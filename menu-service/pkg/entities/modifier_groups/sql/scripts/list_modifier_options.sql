@@ -0,0 +1,4 @@
+SELECT id, modifier_group_id, name, price_delta, stock_variant_id, quantity, is_default, display_order
+FROM modifier_options
+WHERE modifier_group_id = ANY(@modifier_group_ids::uuid[])
+ORDER BY display_order, name;
@@ -0,0 +1,8 @@
+-- Hard delete modifier groups soft deleted before the retention window, their options and
+-- links go with them
+WITH purged AS (
+    DELETE FROM modifier_groups
+    WHERE deleted_at < @deleted_before
+    RETURNING id
+)
+SELECT COUNT(*) FROM purged;
@@ -0,0 +1 @@
+UPDATE modifier_groups SET deleted_at = NULL WHERE id = @id AND deleted_at IS NOT NULL;
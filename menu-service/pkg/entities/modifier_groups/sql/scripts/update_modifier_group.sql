@@ -0,0 +1,9 @@
+UPDATE modifier_groups
+SET name = COALESCE(@name, name),
+    description = COALESCE(@description, description),
+    min_selections = COALESCE(@min_selections, min_selections),
+    max_selections = COALESCE(@max_selections, max_selections),
+    display_order = COALESCE(@display_order, display_order),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id;
@@ -0,0 +1,9 @@
+UPDATE modifier_options
+SET name = @name,
+    price_delta = @price_delta,
+    stock_variant_id = @stock_variant_id,
+    quantity = @quantity,
+    is_default = @is_default,
+    display_order = @display_order,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id AND modifier_group_id = @modifier_group_id;
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"menu-service/pkg/entities/service_periods/models"
+	servicePeriodSQL "menu-service/pkg/entities/service_periods/sql"
+	"shared/audit"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// auditEntity names the entity in the audit log
+const auditEntity = "service_period"
+
+// DBHandler handles database operations for service periods
+type DBHandler struct {
+	db       *sharedDb.DbHandler
+	audit    *audit.Log
+	location *time.Location
+	queries  *queries.Registry
+	logger   *logrus.Logger
+}
+
+// NewDBHandler creates a new service period database handler, location is the venue's
+// timezone the windows are read in
+func NewDBHandler(db *sharedDb.DbHandler, auditLog *audit.Log, location *time.Location, logger *logrus.Logger) (*DBHandler, error) {
+	queries, err := servicePeriodSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &DBHandler{
+		db:       db,
+		audit:    auditLog,
+		location: location,
+		queries:  queries,
+		logger:   logger,
+	}, nil
+}
+
+// rowScanner is a single row or the current row of a result set
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanServicePeriod reads a service period, with deleted_at when withDeleted is set
+func scanServicePeriod(row rowScanner, withDeleted bool) (*models.ServicePeriod, error) {
+	var period models.ServicePeriod
+	var description sql.NullString
+	var windows []byte
+
+	dest := []interface{}{&period.ID, &period.Name, &description, &windows, &period.IsActive, &period.CreatedAt, &period.UpdatedAt, &period.Version}
+	if withDeleted {
+		dest = append(dest, &period.DeletedAt)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	if description.Valid {
+		period.Description = &description.String
+	}
+	if err := json.Unmarshal(windows, &period.Windows); err != nil {
+		return nil, fmt.Errorf("invalid windows of service period %s: %w", period.ID, err)
+	}
+	return &period, nil
+}
+
+// List returns a page of service periods matching the list spec
+func (h *DBHandler) List(ctx context.Context, spec *queryspec.Spec) (*models.ServicePeriodListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(h.queries.Get(servicePeriodSQL.ListServicePeriodsQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build service periods count: %w", err)
+	}
+
+	var total int
+	if err := h.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count service periods: %w", err)
+	}
+
+	listQuery, listArgs, err := spec.ListQuery(h.queries.Get(servicePeriodSQL.ListServicePeriodsQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build service periods list: %w", err)
+	}
+
+	rows, err := h.db.QueryNamedContext(ctx, listQuery, listArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service periods: %w", err)
+	}
+	defer rows.Close()
+
+	var periods []models.ServicePeriod
+	for rows.Next() {
+		period, err := scanServicePeriod(rows, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan service period: %w", err)
+		}
+		periods = append(periods, *period)
+	}
+
+	periods, pageInfo, err := queryspec.Paginate(spec, periods)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ServicePeriodListResponse{
+		ServicePeriods: periods,
+		Total:          total,
+		Limit:          spec.Limit,
+		PageInfo:       pageInfo,
+	}, nil
+}
+
+// GetByID returns a service period by ID
+func (h *DBHandler) GetByID(ctx context.Context, id string) (*models.ServicePeriod, error) {
+	period, err := scanServicePeriod(h.db.QueryRowNamedContext(ctx, h.queries.Get(servicePeriodSQL.GetServicePeriodByIDQuery), queries.Args{"id": id}), false)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get service period: %w", err)
+	}
+	return period, nil
+}
+
+// Create creates a new service period
+func (h *DBHandler) Create(ctx context.Context, req *models.ServicePeriodCreateRequest) (*models.ServicePeriod, error) {
+	return audit.Create(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.ServicePeriod, error) {
+		return h.create(ctx, req)
+	})
+}
+
+// create is Create without the audit log entry
+func (h *DBHandler) create(ctx context.Context, req *models.ServicePeriodCreateRequest) (*models.ServicePeriod, error) {
+	windows, err := json.Marshal(req.Windows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode service period windows: %w", err)
+	}
+
+	period, err := scanServicePeriod(h.db.QueryRowNamedContext(ctx, h.queries.Get(servicePeriodSQL.CreateServicePeriodQuery), queries.Args{
+		"name":        req.Name,
+		"description": req.Description,
+		"windows":     json.RawMessage(windows),
+		"is_active":   req.IsActive,
+	}), false)
+	if err != nil {
+		return nil, h.writeError("create", err)
+	}
+
+	h.logger.WithField("id", period.ID).Info("Service period created")
+	return period, nil
+}
+
+// Update updates an existing service period if it is still at the given version
+func (h *DBHandler) Update(ctx context.Context, id string, version int, req *models.ServicePeriodUpdateRequest) (*models.ServicePeriod, error) {
+	return audit.Update(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) (*models.ServicePeriod, error) {
+		return h.update(ctx, id, version, req)
+	})
+}
+
+// update is Update without the audit log entry
+func (h *DBHandler) update(ctx context.Context, id string, version int, req *models.ServicePeriodUpdateRequest) (*models.ServicePeriod, error) {
+	var windows json.RawMessage
+	if req.Windows != nil {
+		encoded, err := json.Marshal(*req.Windows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode service period windows: %w", err)
+		}
+		windows = encoded
+	}
+
+	period, err := scanServicePeriod(h.db.QueryRowNamedContext(ctx, h.queries.Get(servicePeriodSQL.UpdateServicePeriodQuery), queries.Args{
+		"id":          id,
+		"version":     version,
+		"name":        req.Name,
+		"description": req.Description,
+		"windows":     windows,
+		"is_active":   req.IsActive,
+	}), false)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, h.versionMismatch(ctx, id)
+		}
+		return nil, h.writeError("update", err)
+	}
+
+	h.logger.WithField("id", period.ID).Info("Service period updated")
+	return period, nil
+}
+
+// writeError reports a name another live period already has as a conflict
+func (h *DBHandler) writeError(action string, err error) error {
+	if dbErr, _ := sharedErrors.As(err); dbErr != nil && dbErr.Code == "unique_violation" {
+		return sharedErrors.Conflict("service_period_name_taken", "another service period already has this name").Wrap(err)
+	}
+	return fmt.Errorf("failed to %s service period: %w", action, err)
+}
+
+// versionMismatch tells a stale If-Match from a deleted row after an update matched nothing
+func (h *DBHandler) versionMismatch(ctx context.Context, id string) error {
+	current, err := h.GetByID(ctx, id)
+	if err != nil || current == nil {
+		return err
+	}
+	return sharedErrors.PreconditionFailed("service_period_version_mismatch", "service period was modified by another request")
+}
+
+// Delete deletes a service period
+func (h *DBHandler) Delete(ctx context.Context, id string) error {
+	return audit.Delete(ctx, h.audit, auditEntity, id, h.GetByID, func(ctx context.Context) error {
+		return h.delete(ctx, id)
+	})
+}
+
+// delete is Delete without the audit log entry
+func (h *DBHandler) delete(ctx context.Context, id string) error {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(servicePeriodSQL.DeleteServicePeriodQuery), queries.Args{"id": id})
+	if err != nil {
+		return fmt.Errorf("failed to delete service period: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sharedErrors.NotFound("service_period_not_found", "service period not found")
+	}
+
+	h.logger.WithField("id", id).Info("Service period deleted")
+	return nil
+}
+
+// Restore brings back a soft deleted service period
+func (h *DBHandler) Restore(ctx context.Context, id string) (*models.ServicePeriod, error) {
+	return audit.Restore(ctx, h.audit, auditEntity, func(ctx context.Context) (*models.ServicePeriod, error) {
+		return h.restore(ctx, id)
+	})
+}
+
+// restore is Restore without the audit log entry
+func (h *DBHandler) restore(ctx context.Context, id string) (*models.ServicePeriod, error) {
+	result, err := h.db.ExecNamedContext(ctx, h.queries.Get(servicePeriodSQL.RestoreServicePeriodQuery), queries.Args{"id": id})
+	if err != nil {
+		return nil, h.writeError("restore", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return nil, sharedErrors.NotFound("service_period_not_found", "deleted service period not found")
+	}
+
+	h.logger.WithField("id", id).Info("Service period restored")
+	return h.GetByID(ctx, id)
+}
+
+// Purge hard deletes the service periods soft deleted before deletedBefore and returns how many it removed
+func (h *DBHandler) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	var purged int
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(servicePeriodSQL.PurgeServicePeriodsQuery), queries.Args{"deleted_before": deletedBefore}).Scan(&purged)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge service periods: %w", err)
+	}
+	return purged, nil
+}
+
+// Schedule returns the active service periods. A period whose windows can't be read is
+// left out with a warning rather than failing every menu listing.
+func (h *DBHandler) Schedule(ctx context.Context) (*models.Schedule, error) {
+	rows, err := h.db.QueryNamedContext(ctx, h.queries.Get(servicePeriodSQL.ListActiveServicePeriodsQuery), queries.Args{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active service periods: %w", err)
+	}
+	defer rows.Close()
+
+	schedule := &models.Schedule{Location: h.location}
+	for rows.Next() {
+		period, err := scanServicePeriod(rows, false)
+		if err != nil {
+			h.logger.WithError(err).Warn("Skipping unreadable service period")
+			continue
+		}
+		schedule.Periods = append(schedule.Periods, *period)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating service periods: %w", err)
+	}
+	return schedule, nil
+}
+
+// Active returns the service periods open at the instant at
+func (h *DBHandler) Active(ctx context.Context, at time.Time) (*models.ActiveResponse, error) {
+	schedule, err := h.Schedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ActiveResponse{
+		At:       at.In(h.location),
+		Timezone: h.location.String(),
+		Periods:  schedule.ActiveAt(at),
+	}, nil
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"menu-service/pkg/entities/service_periods/models"
+	servicePeriodSQL "menu-service/pkg/entities/service_periods/sql"
+	sharedErrors "shared/errors"
+	sharedHttp "shared/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPHandler handles HTTP requests for service periods
+type HTTPHandler struct {
+	dbHandler *DBHandler
+	logger    *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(dbHandler *DBHandler, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{dbHandler: dbHandler, logger: logger}
+}
+
+// List handles GET /api/v1/menu/service-periods
+func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
+	spec, err := servicePeriodSQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
+	}
+
+	response, err := h.dbHandler.List(r.Context(), spec)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list service periods")
+		sharedHttp.SendError(w, r, err, "Failed to list service periods")
+		return
+	}
+	response.SetLinks(r.URL)
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Service periods retrieved", response)
+}
+
+// Active handles GET /api/v1/menu/service-periods/active
+func (h *HTTPHandler) Active(w http.ResponseWriter, r *http.Request) {
+	at := time.Now()
+	if value := r.URL.Query().Get("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			sharedHttp.SendError(w, r, sharedErrors.Validation("invalid_query", "invalid service period parameters",
+				sharedErrors.FieldError{Field: "at", Code: "invalid_timestamp", Message: "at must be an RFC 3339 timestamp"}), "")
+			return
+		}
+		at = parsed
+	}
+
+	response, err := h.dbHandler.Active(r.Context(), at)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get active service periods")
+		sharedHttp.SendError(w, r, err, "Failed to get active service periods")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Active service periods retrieved", response)
+}
+
+// GetByID handles GET /api/v1/menu/service-periods/:id
+func (h *HTTPHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	period, err := h.dbHandler.GetByID(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get service period")
+		sharedHttp.SendError(w, r, err, "Failed to get service period")
+		return
+	}
+
+	if period == nil {
+		sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Service period not found")
+		return
+	}
+
+	sharedHttp.SetETag(w, period.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Service period retrieved", period)
+}
+
+// Create handles POST /api/v1/menu/service-periods
+func (h *HTTPHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.ServicePeriodCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid service period")
+		return
+	}
+
+	period, err := h.dbHandler.Create(r.Context(), &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create service period")
+		sharedHttp.SendError(w, r, err, "Failed to create service period")
+		return
+	}
+
+	sharedHttp.SetETag(w, period.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusCreated, "Service period created", period)
+}
+
+// Update handles PUT /api/v1/menu/service-periods/:id
+func (h *HTTPHandler) Update(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	version, err := sharedHttp.IfMatchVersion(r)
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Failed to update service period")
+		return
+	}
+
+	var req models.ServicePeriodUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		sharedHttp.SendErrorResponse(w, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid service period")
+		return
+	}
+
+	period, err := h.dbHandler.Update(r.Context(), id, version, &req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to update service period")
+		sharedHttp.SendError(w, r, err, "Failed to update service period")
+		return
+	}
+
+	if period == nil {
+		sharedHttp.SendErrorResponse(w, http.StatusNotFound, "Service period not found")
+		return
+	}
+
+	sharedHttp.SetETag(w, period.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Service period updated", period)
+}
+
+// Delete handles DELETE /api/v1/menu/service-periods/:id
+func (h *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	if err := h.dbHandler.Delete(r.Context(), id); err != nil {
+		h.logger.WithError(err).Error("Failed to delete service period")
+		sharedHttp.SendError(w, r, err, "Failed to delete service period")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Service period deleted", nil)
+}
+
+// Restore handles POST /api/v1/menu/service-periods/:id/restore
+func (h *HTTPHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	period, err := h.dbHandler.Restore(r.Context(), id)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to restore service period")
+		sharedHttp.SendError(w, r, err, "Failed to restore service period")
+		return
+	}
+
+	sharedHttp.SetETag(w, period.Version)
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Service period restored", period)
+}
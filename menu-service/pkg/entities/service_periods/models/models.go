@@ -0,0 +1,246 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"shared/db/queryspec"
+	sharedErrors "shared/errors"
+	"shared/schedule"
+)
+
+// Window is a time window of a service period on the venue's wall clock, e.g. from 11:00
+// to 15:00 on weekdays. A window whose end is before its start runs past midnight, and
+// one whose end equals its start lasts all day.
+type Window struct {
+	// Days are the days the window starts on, every day when empty
+	Days []string `json:"days,omitempty"`
+	From string   `json:"from"`
+	To   string   `json:"to"`
+}
+
+// normalize rewrites the days as lowercase full names and the times as HH:MM, or returns
+// why the window can't be read
+func (w *Window) normalize() error {
+	days := make([]string, 0, len(w.Days))
+	seen := map[time.Weekday]bool{}
+	for _, name := range w.Days {
+		day, err := schedule.ParseDay(name)
+		if err != nil {
+			return err
+		}
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, strings.ToLower(day.String()))
+		}
+	}
+
+	from, err := schedule.ParseClock(w.From)
+	if err != nil {
+		return err
+	}
+	to, err := schedule.ParseClock(w.To)
+	if err != nil {
+		return err
+	}
+
+	w.Days, w.From, w.To = days, schedule.FormatClock(from), schedule.FormatClock(to)
+	return nil
+}
+
+// window reads the window for evaluation, it must be normalized
+func (w *Window) window() schedule.Window {
+	window := schedule.Window{Days: map[time.Weekday]bool{}}
+	for _, name := range w.Days {
+		day, _ := schedule.ParseDay(name)
+		window.Days[day] = true
+	}
+	window.From, _ = schedule.ParseClock(w.From)
+	window.To, _ = schedule.ParseClock(w.To)
+	return window
+}
+
+// NormalizeName trims and lowercases a period name so it compares with the menu types of
+// menu_variants.menu_types the way the menu listing does
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ServicePeriod is when one menu type is served, e.g. "lunch" on weekdays from 11:00 to
+// 15:00. A menu variant whose menu_types names a period can only be ordered while one of
+// its windows is open.
+type ServicePeriod struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description *string    `json:"description,omitempty"`
+	Windows     []Window   `json:"windows"`
+	IsActive    bool       `json:"is_active"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Version     int        `json:"version"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ActiveAt returns the window of the period open at the instant at in loc, nil when none
+// is or the period is inactive
+func (p *ServicePeriod) ActiveAt(at time.Time, loc *time.Location) *ActivePeriod {
+	if !p.IsActive {
+		return nil
+	}
+	for i := range p.Windows {
+		if start, end, ok := p.Windows[i].window().ActiveAt(at, loc, nil); ok {
+			return &ActivePeriod{ID: p.ID, Name: p.Name, StartsAt: start, EndsAt: end}
+		}
+	}
+	return nil
+}
+
+// ServicePeriodCreateRequest represents a request to create a service period
+type ServicePeriodCreateRequest struct {
+	Name        string   `json:"name"`
+	Description *string  `json:"description,omitempty"`
+	Windows     []Window `json:"windows"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+// Validate checks the name and windows, normalizing them
+func (r *ServicePeriodCreateRequest) Validate() error {
+	var fieldErrors []sharedErrors.FieldError
+	r.Name = NormalizeName(r.Name)
+	if r.Name == "" {
+		fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "name", Code: "required", Message: "name is required"})
+	}
+	fieldErrors = append(fieldErrors, validateWindows(r.Windows)...)
+
+	if len(fieldErrors) > 0 {
+		return sharedErrors.Validation("invalid_service_period", "invalid service period", fieldErrors...)
+	}
+	return nil
+}
+
+// ServicePeriodUpdateRequest represents a request to update a service period, Windows
+// replaces every window when set
+type ServicePeriodUpdateRequest struct {
+	Name        *string   `json:"name,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Windows     *[]Window `json:"windows,omitempty"`
+	IsActive    *bool     `json:"is_active,omitempty"`
+}
+
+// Validate checks the fields being changed, normalizing them
+func (r *ServicePeriodUpdateRequest) Validate() error {
+	var fieldErrors []sharedErrors.FieldError
+	if r.Name != nil {
+		name := NormalizeName(*r.Name)
+		r.Name = &name
+		if name == "" {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "name", Code: "required", Message: "name can't be empty"})
+		}
+	}
+	if r.Windows != nil {
+		fieldErrors = append(fieldErrors, validateWindows(*r.Windows)...)
+	}
+
+	if len(fieldErrors) > 0 {
+		return sharedErrors.Validation("invalid_service_period", "invalid service period", fieldErrors...)
+	}
+	return nil
+}
+
+// validateWindows checks there is at least one window and normalizes each
+func validateWindows(windows []Window) []sharedErrors.FieldError {
+	if len(windows) == 0 {
+		return []sharedErrors.FieldError{{Field: "windows", Code: "required", Message: "at least one window is required"}}
+	}
+	var fieldErrors []sharedErrors.FieldError
+	for i := range windows {
+		if err := windows[i].normalize(); err != nil {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: fmt.Sprintf("windows[%d]", i), Code: "invalid_window", Message: err.Error()})
+		}
+	}
+	return fieldErrors
+}
+
+// ServicePeriodListResponse represents a paginated list of service periods
+type ServicePeriodListResponse struct {
+	ServicePeriods []ServicePeriod `json:"service_periods"`
+	Total          int             `json:"total"`
+	Limit          int             `json:"limit"`
+	queryspec.PageInfo
+}
+
+// ActivePeriod is a service period open at some instant
+type ActivePeriod struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// ActiveResponse lists the service periods open at an instant
+type ActiveResponse struct {
+	At       time.Time      `json:"at"`
+	Timezone string         `json:"timezone"`
+	Periods  []ActivePeriod `json:"periods"`
+}
+
+// Schedule is the set of active service periods of the venue, read in its timezone. An
+// inactive period is left out, so its menu type isn't limited to any time.
+type Schedule struct {
+	Periods  []ServicePeriod
+	Location *time.Location
+}
+
+// Names returns the names of the periods, whether open or not. A menu type no period is
+// named after isn't limited to any time.
+func (s *Schedule) Names() []string {
+	names := make([]string, 0, len(s.Periods))
+	for i := range s.Periods {
+		names = append(names, s.Periods[i].Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveAt returns the periods open at the instant at
+func (s *Schedule) ActiveAt(at time.Time) []ActivePeriod {
+	active := []ActivePeriod{}
+	for i := range s.Periods {
+		if period := s.Periods[i].ActiveAt(at, s.Location); period != nil {
+			active = append(active, *period)
+		}
+	}
+	return active
+}
+
+// ActiveNames returns the names of the periods open at the instant at
+func (s *Schedule) ActiveNames(at time.Time) []string {
+	active := s.ActiveAt(at)
+	names := make([]string, 0, len(active))
+	for _, period := range active {
+		names = append(names, period.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Orderable reports whether a menu variant with the given menu types can be ordered at
+// the instant at: either none of its menu types names a period, or one names an open one
+func (s *Schedule) Orderable(menuTypes []string, at time.Time) bool {
+	limited := false
+	for _, menuType := range menuTypes {
+		name := NormalizeName(menuType)
+		for i := range s.Periods {
+			if s.Periods[i].Name != name {
+				continue
+			}
+			if s.Periods[i].ActiveAt(at, s.Location) != nil {
+				return true
+			}
+			limited = true
+		}
+	}
+	return !limited
+}
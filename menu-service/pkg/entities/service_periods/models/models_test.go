@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// costaRica is UTC-6 all year, like the venue's default timezone
+var costaRica = time.FixedZone("CST", -6*60*60)
+
+func local(value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", value, costaRica)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCreateRequestValidate(t *testing.T) {
+	req := ServicePeriodCreateRequest{
+		Name:    "  Lunch ",
+		Windows: []Window{{Days: []string{"Mon", "tuesday", "monday"}, From: "11:00:00", To: "15:30"}},
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if req.Name != "lunch" {
+		t.Errorf("name = %q, want lunch", req.Name)
+	}
+	window := req.Windows[0]
+	if len(window.Days) != 2 || window.Days[0] != "monday" || window.Days[1] != "tuesday" || window.From != "11:00" || window.To != "15:30" {
+		t.Errorf("window = %+v, want monday and tuesday from 11:00 to 15:30", window)
+	}
+
+	for _, bad := range []ServicePeriodCreateRequest{
+		{Name: "", Windows: []Window{{From: "11:00", To: "15:00"}}},
+		{Name: "lunch"},
+		{Name: "lunch", Windows: []Window{{Days: []string{"someday"}, From: "11:00", To: "15:00"}}},
+		{Name: "lunch", Windows: []Window{{From: "11am", To: "15:00"}}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestScheduleOrderable(t *testing.T) {
+	// 2026-03-06 is a Friday
+	lunch := ServicePeriod{ID: "1", Name: "lunch", IsActive: true, Windows: []Window{{Days: []string{"friday"}, From: "11:00", To: "15:00"}}}
+	dinner := ServicePeriod{ID: "2", Name: "dinner", IsActive: true, Windows: []Window{{From: "18:00", To: "23:00"}}}
+	schedule := &Schedule{Periods: []ServicePeriod{lunch, dinner}, Location: costaRica}
+
+	cases := []struct {
+		menuTypes []string
+		at        string
+		want      bool
+	}{
+		{[]string{"lunch"}, "2026-03-06 12:00", true},
+		{[]string{"Lunch"}, "2026-03-06 12:00", true},
+		{[]string{"lunch"}, "2026-03-06 16:00", false},
+		{[]string{"lunch"}, "2026-03-07 12:00", false}, // Saturday
+		{[]string{"lunch", "dinner"}, "2026-03-07 19:00", true},
+		{[]string{"brunch"}, "2026-03-06 03:00", true}, // no period limits it
+		{nil, "2026-03-06 03:00", true},
+	}
+	for _, c := range cases {
+		if got := schedule.Orderable(c.menuTypes, local(c.at)); got != c.want {
+			t.Errorf("Orderable(%v, %s) = %v, want %v", c.menuTypes, c.at, got, c.want)
+		}
+	}
+
+	active := schedule.ActiveAt(local("2026-03-06 12:00"))
+	if len(active) != 1 || active[0].Name != "lunch" || !active[0].EndsAt.Equal(local("2026-03-06 15:00")) {
+		t.Errorf("ActiveAt(Friday 12:00) = %+v, want lunch until 15:00", active)
+	}
+	if names := schedule.Names(); len(names) != 2 || names[0] != "dinner" {
+		t.Errorf("Names() = %v, want dinner and lunch", names)
+	}
+}
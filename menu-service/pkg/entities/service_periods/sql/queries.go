@@ -0,0 +1,45 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	ListServicePeriodsQuery       queries.Name = "list_service_periods"
+	ListActiveServicePeriodsQuery queries.Name = "list_active_service_periods"
+	GetServicePeriodByIDQuery     queries.Name = "get_service_period_by_id"
+	CreateServicePeriodQuery      queries.Name = "create_service_period"
+	UpdateServicePeriodQuery      queries.Name = "update_service_period"
+	DeleteServicePeriodQuery      queries.Name = "delete_service_period"
+	RestoreServicePeriodQuery     queries.Name = "restore_service_period"
+	PurgeServicePeriodsQuery      queries.Name = "purge_service_periods"
+)
+
+// ListSchema whitelists the columns of list_service_periods that can be filtered and sorted
+var ListSchema = queryspec.NewSchema("name",
+	queryspec.Field{Name: "name", Type: queryspec.String, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "is_active", Type: queryspec.Bool, Filterable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+	queryspec.Field{Name: "updated_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).WithSoftDelete()
+
+// LoadQueries loads and validates the service period SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		ListServicePeriodsQuery,
+		ListActiveServicePeriodsQuery,
+		GetServicePeriodByIDQuery,
+		CreateServicePeriodQuery,
+		UpdateServicePeriodQuery,
+		DeleteServicePeriodQuery,
+		RestoreServicePeriodQuery,
+		PurgeServicePeriodsQuery,
+	)
+}
@@ -0,0 +1,3 @@
+INSERT INTO service_periods (name, description, windows, is_active)
+VALUES (@name, @description, @windows, COALESCE(@is_active, true))
+RETURNING id, name, description, windows, is_active, created_at, updated_at, version;
@@ -0,0 +1 @@
+UPDATE service_periods SET deleted_at = CURRENT_TIMESTAMP WHERE id = @id AND deleted_at IS NULL;
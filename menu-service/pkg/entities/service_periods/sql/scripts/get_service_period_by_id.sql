@@ -0,0 +1,3 @@
+SELECT id, name, description, windows, is_active, created_at, updated_at, version
+FROM service_periods
+WHERE id = @id AND deleted_at IS NULL;
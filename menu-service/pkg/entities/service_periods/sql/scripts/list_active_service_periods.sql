@@ -0,0 +1,4 @@
+SELECT id, name, description, windows, is_active, created_at, updated_at, version
+FROM service_periods
+WHERE is_active = true AND deleted_at IS NULL
+ORDER BY name;
@@ -0,0 +1,2 @@
+SELECT id, name, description, windows, is_active, created_at, updated_at, version, deleted_at
+FROM service_periods;
@@ -0,0 +1,7 @@
+-- Hard delete service periods soft deleted before the retention window
+WITH purged AS (
+    DELETE FROM service_periods
+    WHERE deleted_at < @deleted_before
+    RETURNING id
+)
+SELECT COUNT(*) FROM purged;
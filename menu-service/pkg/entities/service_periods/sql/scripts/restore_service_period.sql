@@ -0,0 +1 @@
+UPDATE service_periods SET deleted_at = NULL WHERE id = @id AND deleted_at IS NOT NULL;
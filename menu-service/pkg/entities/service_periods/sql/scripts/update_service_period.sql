@@ -0,0 +1,8 @@
+UPDATE service_periods
+SET name = COALESCE(@name, name),
+    description = COALESCE(@description, description),
+    windows = COALESCE(@windows, windows),
+    is_active = COALESCE(@is_active, is_active),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id AND version = @version AND deleted_at IS NULL
+RETURNING id, name, description, windows, is_active, created_at, updated_at, version;
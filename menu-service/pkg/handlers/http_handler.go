@@ -2,19 +2,35 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
+	"shared/audit"
 	sharedConfig "shared/config"
 	sharedDb "shared/db"
+	"shared/db/softdelete"
+	"shared/events"
 	sharedHttp "shared/http"
+	"shared/middlewares"
+	"shared/money"
 
+	catalogHandlers "menu-service/pkg/entities/catalog/handlers"
+	comboHandlers "menu-service/pkg/entities/combos/handlers"
+	happyHourHandlers "menu-service/pkg/entities/happy_hours/handlers"
 	menuCategoryHandlers "menu-service/pkg/entities/menu_categories/handlers"
+	menuCostHandlers "menu-service/pkg/entities/menu_costs/handlers"
+	menuEngineeringHandlers "menu-service/pkg/entities/menu_engineering/handlers"
 	menuIngredientHandlers "menu-service/pkg/entities/menu_ingredients/handlers"
+	menuPricingHandlers "menu-service/pkg/entities/menu_pricing/handlers"
+	menuPricingModels "menu-service/pkg/entities/menu_pricing/models"
 	menuSubCategoryHandlers "menu-service/pkg/entities/menu_sub_categories/handlers"
+	menuTranslationHandlers "menu-service/pkg/entities/menu_translations/handlers"
+	menuTranslationModels "menu-service/pkg/entities/menu_translations/models"
+	menuTreeHandlers "menu-service/pkg/entities/menu_tree/handlers"
 	menuVariantHandlers "menu-service/pkg/entities/menu_variants/handlers"
+	modifierGroupHandlers "menu-service/pkg/entities/modifier_groups/handlers"
+	servicePeriodHandlers "menu-service/pkg/entities/service_periods/handlers"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -28,6 +44,16 @@ type MainHTTPHandler struct {
 	menuSubCategoryHandler *menuSubCategoryHandlers.HTTPHandler
 	menuVariantHandler     *menuVariantHandlers.HTTPHandler
 	menuIngredientHandler  *menuIngredientHandlers.HTTPHandler
+	menuCostHandler        *menuCostHandlers.HTTPHandler
+	menuPricingHandler     *menuPricingHandlers.HTTPHandler
+	menuEngineeringHandler *menuEngineeringHandlers.HTTPHandler
+	servicePeriodHandler   *servicePeriodHandlers.HTTPHandler
+	modifierGroupHandler   *modifierGroupHandlers.HTTPHandler
+	comboHandler           *comboHandlers.HTTPHandler
+	catalogHandler         *catalogHandlers.HTTPHandler
+	menuTreeHandler        *menuTreeHandlers.HTTPHandler
+	menuTranslationHandler *menuTranslationHandlers.HTTPHandler
+	purger                 *softdelete.Purger
 	logger                 *logrus.Logger
 }
 
@@ -40,6 +66,7 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 		Password:        cfg.GetString("DB_PASSWORD"),
 		DBName:          cfg.GetString("DB_NAME"),
 		SSLMode:         cfg.GetString("DB_SSL_MODE"),
+		ApplicationName: "menu-service",
 		MaxOpenConns:    25,
 		MaxIdleConns:    5,
 		ConnMaxLifetime: 5 * time.Minute,
@@ -48,6 +75,7 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 		QueryTimeout:    30 * time.Second,
 		MaxRetries:      3,
 		RetryInterval:   2 * time.Second,
+		CacheStatements: true,
 	}
 
 	db, err := sharedDb.NewDatabaseHandler(dbConfig, logger)
@@ -55,8 +83,15 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 		return nil, fmt.Errorf("failed to create database handler: %w", err)
 	}
 
+	// Create audit log, every entity records its changes in it
+	auditLog, err := audit.NewLog(db, "menu-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create audit log: %w", err)
+	}
+
 	// Create menu category handlers
-	menuCategoryDBHandler, err := menuCategoryHandlers.NewDBHandler(db, logger)
+	menuCategoryDBHandler, err := menuCategoryHandlers.NewDBHandler(db, auditLog, logger)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create menu category handler: %w", err)
@@ -64,42 +99,188 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 	menuCategoryHTTPHandler := menuCategoryHandlers.NewHTTPHandler(menuCategoryDBHandler, logger)
 
 	// Create menu sub-category handlers
-	menuSubCategoryDBHandler, err := menuSubCategoryHandlers.NewDBHandler(db, logger)
+	menuSubCategoryDBHandler, err := menuSubCategoryHandlers.NewDBHandler(db, auditLog, logger)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create menu sub-category handler: %w", err)
 	}
 	menuSubCategoryHTTPHandler := menuSubCategoryHandlers.NewHTTPHandler(menuSubCategoryDBHandler, logger)
 
-	// Create menu variant handlers
-	menuVariantDBHandler, err := menuVariantHandlers.NewDBHandler(db, logger)
+	// Create event outbox, relay and subscriber
+	outbox, err := events.NewOutbox(db, "menu-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event outbox: %w", err)
+	}
+	broker, err := events.NewPostgresBroker(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event broker: %w", err)
+	}
+	relay, err := events.NewRelay(db, broker, "menu-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event relay: %w", err)
+	}
+	subscriber, err := events.NewSubscriber(db, "menu-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create event subscriber: %w", err)
+	}
+
+	// Create the happy hour schedule, read in the venue's timezone
+	venueLocation, err := time.LoadLocation(cfg.GetString("VENUE_TIMEZONE"))
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("invalid VENUE_TIMEZONE: %w", err)
+	}
+	happyHourDBHandler, err := happyHourHandlers.NewDBHandler(db, venueLocation, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create happy hour handler: %w", err)
+	}
+
+	// Create service period handlers, when each menu type is served in the venue's timezone
+	servicePeriodDBHandler, err := servicePeriodHandlers.NewDBHandler(db, auditLog, venueLocation, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create service period handler: %w", err)
+	}
+	servicePeriodHTTPHandler := servicePeriodHandlers.NewHTTPHandler(servicePeriodDBHandler, logger)
+
+	// Create modifier group handlers, the options picked when ordering a variant
+	modifierGroupDBHandler, err := modifierGroupHandlers.NewDBHandler(db, auditLog, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create modifier group handler: %w", err)
+	}
+	modifierGroupHTTPHandler := modifierGroupHandlers.NewHTTPHandler(modifierGroupDBHandler, logger)
+
+	// Create menu variant handlers, their listings show the price charged now and can keep
+	// what is orderable now, their detail has the modifier groups
+	menuVariantDBHandler, err := menuVariantHandlers.NewDBHandler(db, outbox, auditLog, happyHourDBHandler, servicePeriodDBHandler, modifierGroupDBHandler, logger)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create menu variant handler: %w", err)
 	}
 	menuVariantHTTPHandler := menuVariantHandlers.NewHTTPHandler(menuVariantDBHandler, logger)
 
+	// Create menu cost handlers, the menu is costed again when ingredients, sub-category
+	// membership or the average cost of a stock variant change
+	menuCostDBHandler, err := menuCostHandlers.NewDBHandler(db, menuVariantDBHandler, cfg.GetString("RECIPE_COST_STRATEGY"), logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create menu cost handler: %w", err)
+	}
+	menuVariantDBHandler.SetCostRecomputer(menuCostDBHandler)
+	menuCostHTTPHandler := menuCostHandlers.NewHTTPHandler(menuCostDBHandler, logger)
+	menuCostEventHandler := menuCostHandlers.NewEventHandler(menuCostDBHandler, logger)
+	subscriber.Handle(events.StockVariantCostChanged, menuCostEventHandler.StockVariantCostChanged)
+
+	// Create combo handlers, choose-one slots are costed like sub-category ingredients
+	comboDBHandler, err := comboHandlers.NewDBHandler(db, auditLog, cfg.GetString("RECIPE_COST_STRATEGY"), logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create combo handler: %w", err)
+	}
+	comboHTTPHandler := comboHandlers.NewHTTPHandler(comboDBHandler, logger)
+
+	// Create menu pricing handlers, prices are suggested from the stored variant costs
+	defaultMargin, err := money.ParseDecimal(cfg.GetString("DEFAULT_EARNING_MARGIN"))
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("invalid DEFAULT_EARNING_MARGIN: %w", err)
+	}
+	taxRate, err := money.ParseDecimal(cfg.GetString("DEFAULT_TAX_RATE"))
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("invalid DEFAULT_TAX_RATE: %w", err)
+	}
+	menuPricingDBHandler, err := menuPricingHandlers.NewDBHandler(db, menuVariantDBHandler, auditLog, menuPricingModels.Rates{
+		DefaultMargin: defaultMargin,
+		TaxRate:       taxRate,
+	}, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create menu pricing handler: %w", err)
+	}
+	menuPricingHTTPHandler := menuPricingHandlers.NewHTTPHandler(menuPricingDBHandler, logger)
+
+	// Create menu engineering handlers, sales come from the order items
+	menuEngineeringDBHandler, err := menuEngineeringHandlers.NewDBHandler(db, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create menu engineering handler: %w", err)
+	}
+	menuEngineeringHTTPHandler := menuEngineeringHandlers.NewHTTPHandler(menuEngineeringDBHandler, logger)
+
 	// Create menu ingredient handlers
-	menuIngredientDBHandler, err := menuIngredientHandlers.NewDBHandler(db, logger)
+	menuIngredientDBHandler, err := menuIngredientHandlers.NewDBHandler(db, menuVariantDBHandler, menuCostDBHandler, auditLog, logger)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to create menu ingredient handler: %w", err)
 	}
 	menuIngredientHTTPHandler := menuIngredientHandlers.NewHTTPHandler(menuIngredientDBHandler, logger)
 
-	// Create cancellable context for health monitor
+	// Create menu import and export handlers on top of the entity handlers
+	catalogDBHandler, err := catalogHandlers.NewDBHandler(db, menuCategoryDBHandler, menuSubCategoryDBHandler, menuVariantDBHandler, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create catalog handler: %w", err)
+	}
+	catalogHTTPHandler := catalogHandlers.NewHTTPHandler(catalogDBHandler, logger)
+
+	// Create menu tree handlers, the whole menu in one read with the prices charged now
+	menuTreeDBHandler, err := menuTreeHandlers.NewDBHandler(db, menuVariantDBHandler, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create menu tree handler: %w", err)
+	}
+	defaultLanguage, ok := menuTranslationModels.ParseLanguage(cfg.GetString("DEFAULT_LANGUAGE"))
+	if !ok {
+		db.Close()
+		return nil, fmt.Errorf("invalid DEFAULT_LANGUAGE %q, want a language tag like es", cfg.GetString("DEFAULT_LANGUAGE"))
+	}
+	menuTreeHTTPHandler := menuTreeHandlers.NewHTTPHandler(menuTreeDBHandler, defaultLanguage, cfg.GetInt("PUBLIC_MENU_MAX_AGE"), logger)
+
+	// Create menu translation handlers, the public menu is shown in them
+	menuTranslationDBHandler, err := menuTranslationHandlers.NewDBHandler(db, auditLog, logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create menu translation handler: %w", err)
+	}
+	menuTranslationHTTPHandler := menuTranslationHandlers.NewHTTPHandler(menuTranslationDBHandler, logger)
+
+	// Create the purger of soft deleted rows, children before their parents
+	purger := softdelete.NewPurger(db, cfg.GetInt("SOFT_DELETE_RETENTION_DAYS"), "menu-service", logger).
+		Add("combos", comboDBHandler).
+		Add("menu_variants", menuVariantDBHandler).
+		Add("menu_sub_categories", menuSubCategoryDBHandler).
+		Add("menu_categories", menuCategoryDBHandler).
+		Add("service_periods", servicePeriodDBHandler).
+		Add("modifier_groups", modifierGroupDBHandler)
+
+	// Create cancellable context for health monitor, event relay, subscriber and purger
 	ctx, cancel := context.WithCancel(context.Background())
 
 	//pvillalobos this should be configurable
-	// Create HTTP health monitor for data-service
+	// Create health monitor: the database is critical since the service talks to
+	// Postgres directly, data-service only serves configuration at startup
 	httpHealthMonitor, err := sharedHttp.NewHealthMonitor(logger, 1*time.Second)
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to create HTTP health monitor: %w", err)
 	}
-	httpHealthMonitor.AddService("data-service", sharedConfig.DATA_SERVICE_URL+"/api/v1/data/p/health")
+	httpHealthMonitor.AddCheck("database", sharedHttp.Critical, func(ctx context.Context) error {
+		return db.Ping()
+	})
+	httpHealthMonitor.AddService("data-service", sharedConfig.DATA_SERVICE_URL+"/api/v1/data/p/readyz", sharedHttp.Degraded)
 	httpHealthMonitor.Start(ctx)
 
+	go relay.Start(ctx)
+	go subscriber.Start(ctx)
+	go purger.Start(ctx)
+
 	return &MainHTTPHandler{
 		db:                     db,
 		httpHealthMonitor:      httpHealthMonitor,
@@ -108,12 +289,22 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 		menuSubCategoryHandler: menuSubCategoryHTTPHandler,
 		menuVariantHandler:     menuVariantHTTPHandler,
 		menuIngredientHandler:  menuIngredientHTTPHandler,
+		menuCostHandler:        menuCostHTTPHandler,
+		menuPricingHandler:     menuPricingHTTPHandler,
+		menuEngineeringHandler: menuEngineeringHTTPHandler,
+		servicePeriodHandler:   servicePeriodHTTPHandler,
+		modifierGroupHandler:   modifierGroupHTTPHandler,
+		comboHandler:           comboHTTPHandler,
+		catalogHandler:         catalogHTTPHandler,
+		menuTreeHandler:        menuTreeHTTPHandler,
+		menuTranslationHandler: menuTranslationHTTPHandler,
+		purger:                 purger,
 		logger:                 logger,
 	}, nil
 }
 
 func (h *MainHTTPHandler) CloseDB() error {
-	// Stop health monitor
+	// Stop health monitor, event relay, subscriber and purger
 	if h.cancelHealthMonitor != nil {
 		h.cancelHealthMonitor()
 	}
@@ -127,8 +318,16 @@ func (h *MainHTTPHandler) CloseDB() error {
 }
 
 func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
-	// Health check
-	router.HandleFunc("/api/v1/menu/p/health", h.HealthCheck).Methods("GET")
+	// Attribute changes to the user the gateway authenticated
+	router.Use(audit.Middleware)
+
+	// Health checks (/p/health is kept for existing clients)
+	router.HandleFunc("/api/v1/menu/p/livez", sharedHttp.LivenessHandler("menu-service")).Methods("GET")
+	router.HandleFunc("/api/v1/menu/p/readyz", h.httpHealthMonitor.ReadinessHandler("menu-service")).Methods("GET")
+	router.HandleFunc("/api/v1/menu/p/health", h.httpHealthMonitor.ReadinessHandler("menu-service")).Methods("GET")
+
+	// Public menu (customers from QR codes, no session: available variants, no costs)
+	router.HandleFunc("/api/v1/menu/p/menu", h.menuTreeHandler.Public).Methods("GET")
 
 	// Menu Categories
 	router.HandleFunc("/api/v1/menu/categories", h.menuCategoryHandler.List).Methods("GET")
@@ -136,6 +335,7 @@ func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/menu/categories", h.menuCategoryHandler.Create).Methods("POST")
 	router.HandleFunc("/api/v1/menu/categories/{id}", h.menuCategoryHandler.Update).Methods("PUT")
 	router.HandleFunc("/api/v1/menu/categories/{id}", h.menuCategoryHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/v1/menu/categories/{id}/restore", h.menuCategoryHandler.Restore).Methods("POST")
 
 	// Menu Sub-Categories
 	router.HandleFunc("/api/v1/menu/sub-categories", h.menuSubCategoryHandler.List).Methods("GET")
@@ -143,14 +343,43 @@ func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/menu/sub-categories", h.menuSubCategoryHandler.Create).Methods("POST")
 	router.HandleFunc("/api/v1/menu/sub-categories/{id}", h.menuSubCategoryHandler.Update).Methods("PUT")
 	router.HandleFunc("/api/v1/menu/sub-categories/{id}", h.menuSubCategoryHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/v1/menu/sub-categories/{id}/restore", h.menuSubCategoryHandler.Restore).Methods("POST")
 
 	// Menu Variants
 	router.HandleFunc("/api/v1/menu/variants", h.menuVariantHandler.List).Methods("GET")
+	router.HandleFunc("/api/v1/menu/variants/orderable", h.menuVariantHandler.CheckOrderable).Methods("POST")
 	router.HandleFunc("/api/v1/menu/variants/{id}", h.menuVariantHandler.GetByID).Methods("GET")
 	router.HandleFunc("/api/v1/menu/variants", h.menuVariantHandler.Create).Methods("POST")
 	router.HandleFunc("/api/v1/menu/variants/{id}", h.menuVariantHandler.Update).Methods("PUT")
 	router.HandleFunc("/api/v1/menu/variants/{id}", h.menuVariantHandler.Delete).Methods("DELETE")
+	router.HandleFunc("/api/v1/menu/variants/{id}/restore", h.menuVariantHandler.Restore).Methods("POST")
 	router.HandleFunc("/api/v1/menu/variants/{id}/availability", h.menuVariantHandler.UpdateAvailability).Methods("PATCH")
+	router.HandleFunc("/api/v1/menu/variants/{id}/price", h.menuVariantHandler.ResolvePrice).Methods("GET")
+
+	// Service periods (when each menu type of menu_types is served)
+	router.HandleFunc("/api/v1/menu/service-periods", h.servicePeriodHandler.List).Methods("GET")
+	router.HandleFunc("/api/v1/menu/service-periods/active", h.servicePeriodHandler.Active).Methods("GET")
+	router.HandleFunc("/api/v1/menu/service-periods/{id}", h.servicePeriodHandler.GetByID).Methods("GET")
+	router.Handle("/api/v1/menu/service-periods", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.servicePeriodHandler.Create))).Methods("POST")
+	router.Handle("/api/v1/menu/service-periods/{id}", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.servicePeriodHandler.Update))).Methods("PUT")
+	router.Handle("/api/v1/menu/service-periods/{id}", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.servicePeriodHandler.Delete))).Methods("DELETE")
+	router.Handle("/api/v1/menu/service-periods/{id}/restore", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.servicePeriodHandler.Restore))).Methods("POST")
+
+	// Modifier groups (options picked when ordering, attached to variants or sub-categories)
+	router.HandleFunc("/api/v1/menu/modifier-groups", h.modifierGroupHandler.List).Methods("GET")
+	router.HandleFunc("/api/v1/menu/modifier-groups/{id}", h.modifierGroupHandler.GetByID).Methods("GET")
+	router.Handle("/api/v1/menu/modifier-groups", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.modifierGroupHandler.Create))).Methods("POST")
+	router.Handle("/api/v1/menu/modifier-groups/{id}", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.modifierGroupHandler.Update))).Methods("PUT")
+	router.Handle("/api/v1/menu/modifier-groups/{id}", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.modifierGroupHandler.Delete))).Methods("DELETE")
+	router.Handle("/api/v1/menu/modifier-groups/{id}/restore", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.modifierGroupHandler.Restore))).Methods("POST")
+
+	// Combos (bundles of variants whose cost and availability roll up from the components)
+	router.HandleFunc("/api/v1/menu/combos", h.comboHandler.List).Methods("GET")
+	router.HandleFunc("/api/v1/menu/combos/{id}", h.comboHandler.GetByID).Methods("GET")
+	router.Handle("/api/v1/menu/combos", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.comboHandler.Create))).Methods("POST")
+	router.Handle("/api/v1/menu/combos/{id}", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.comboHandler.Update))).Methods("PUT")
+	router.Handle("/api/v1/menu/combos/{id}", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.comboHandler.Delete))).Methods("DELETE")
+	router.Handle("/api/v1/menu/combos/{id}/restore", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.comboHandler.Restore))).Methods("POST")
 
 	// Menu Ingredients
 	router.HandleFunc("/api/v1/menu/ingredients", h.menuIngredientHandler.List).Methods("GET")
@@ -159,32 +388,32 @@ func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
 	router.HandleFunc("/api/v1/menu/ingredients/{id}", h.menuIngredientHandler.Update).Methods("PUT")
 	router.HandleFunc("/api/v1/menu/ingredients/{id}", h.menuIngredientHandler.Delete).Methods("DELETE")
 	router.HandleFunc("/api/v1/menu/variants/{variantId}/ingredients", h.menuIngredientHandler.GetByMenuVariant).Methods("GET")
-}
 
-func (h *MainHTTPHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
-		"service":   "menu-service",
-		"timestamp": time.Now(),
-	}
+	// Menu variant costs (status: known, unknown, no_recipe)
+	router.HandleFunc("/api/v1/menu/costs", h.menuCostHandler.List).Methods("GET")
+	router.HandleFunc("/api/v1/menu/variants/{variantId}/cost", h.menuCostHandler.GetByMenuVariant).Methods("GET")
+	router.HandleFunc("/api/v1/menu/variants/{variantId}/bom", h.menuCostHandler.Tree).Methods("GET")
+	router.Handle("/api/v1/menu/costs/recompute", middlewares.RequireRole("admin")(http.HandlerFunc(h.menuCostHandler.Recompute))).Methods("POST")
 
-	// Check cached health state from background monitor
-	healthStatus := h.httpHealthMonitor.GetHealthStatus()
-	if !healthStatus.IsHealthy {
-		response["status"] = "unhealthy"
-		response["message"] = "Dependent services are not healthy"
-		response["services"] = healthStatus.Services
+	// Menu pricing and target margins (a null target_margin inherits again)
+	router.HandleFunc("/api/v1/menu/pricing", h.menuPricingHandler.List).Methods("GET")
+	router.Handle("/api/v1/menu/pricing/reprice", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.menuPricingHandler.Reprice))).Methods("POST")
+	router.Handle("/api/v1/menu/pricing/targets/categories/{id}", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.menuPricingHandler.SetCategoryTargetMargin))).Methods("PUT")
+	router.Handle("/api/v1/menu/pricing/targets/sub-categories/{id}", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.menuPricingHandler.SetSubCategoryTargetMargin))).Methods("PUT")
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(response)
-		return
-	}
+	// Menu engineering (format: json, csv or xlsx)
+	router.Handle("/api/v1/menu/engineering", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.menuEngineeringHandler.Report))).Methods("GET")
+
+	// Menu tree (categories → sub-categories → variants, ETag of the content for polling)
+	router.HandleFunc("/api/v1/menu/tree", h.menuTreeHandler.Get).Methods("GET")
+
+	// Menu translations (names and descriptions in other languages for the public menu)
+	router.Handle("/api/v1/menu/translations/{kind:categories|sub-categories|variants}/{id}", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.menuTranslationHandler.SetTranslations))).Methods("PUT")
 
-	response["status"] = "healthy"
-	response["message"] = "Menu service is healthy"
-	response["services"] = healthStatus.Services
+	// Menu import and export (sheet: tree)
+	router.HandleFunc("/api/v1/menu/export/{sheet}", h.catalogHandler.Export).Methods("GET")
+	router.Handle("/api/v1/menu/import/{sheet}", middlewares.RequireRole("admin", "manager")(http.HandlerFunc(h.catalogHandler.Import))).Methods("POST")
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	// Admin
+	router.Handle("/api/v1/menu/admin/purge", middlewares.RequireRole("admin")(http.HandlerFunc(h.purger.Handler))).Methods("POST")
 }
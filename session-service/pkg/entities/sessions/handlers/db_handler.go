@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"session-service/pkg/entities/sessions/models"
@@ -8,17 +9,32 @@ import (
 	sharedConfig "shared/config"
 	"time"
 
+	"shared/audit"
 	sharedDb "shared/db"
+	"shared/db/queries"
+	sharedErrors "shared/errors"
 
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// auditEntity names the entity in the audit log
+const auditEntity = "session"
+
+// auditedSession is what the audit log keeps of a session, never its token
+type auditedSession struct {
+	ID       string `json:"id"`
+	StaffID  string `json:"staff_id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Role     string `json:"role,omitempty"`
+}
+
 // DBHandler handles database operations for sessions
 type DBHandler struct {
 	db         *sharedDb.DbHandler
-	queries    sessionSQL.Queries
+	audit      *audit.Log
+	queries    *queries.Registry
 	jwtHandler *JWTHandler
 	logger     *logrus.Logger
 }
@@ -33,6 +49,7 @@ func NewDBHandler(cfg *sharedConfig.Config, jwtHandler *JWTHandler, logger *logr
 		Password:        cfg.GetString("DB_PASSWORD"),
 		DBName:          cfg.GetString("DB_NAME"),
 		SSLMode:         cfg.GetString("DB_SSL_MODE"),
+		ApplicationName: "session-service",
 		MaxOpenConns:    25,
 		MaxIdleConns:    5,
 		ConnMaxLifetime: 5 * time.Minute,
@@ -41,6 +58,7 @@ func NewDBHandler(cfg *sharedConfig.Config, jwtHandler *JWTHandler, logger *logr
 		QueryTimeout:    30 * time.Second,
 		MaxRetries:      3,
 		RetryInterval:   2 * time.Second,
+		CacheStatements: true,
 	}
 	// Create database handler using data-service's handler
 	db, err := sharedDb.NewDatabaseHandler(dbConfig, logger)
@@ -54,9 +72,16 @@ func NewDBHandler(cfg *sharedConfig.Config, jwtHandler *JWTHandler, logger *logr
 		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
 	}
 
+	auditLog, err := audit.NewLog(db, "session-service", logger)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create audit log: %w", err)
+	}
+
 	return &DBHandler{
 		db:         db,
-		queries:    *queries,
+		audit:      auditLog,
+		queries:    queries,
 		jwtHandler: jwtHandler,
 		logger:     logger,
 	}, nil
@@ -75,8 +100,8 @@ func (h *DBHandler) GetDB() *sharedDb.DbHandler {
 }
 
 // CreateSession creates a new session for a staff member
-func (h *DBHandler) CreateSession(req *models.SessionCreateRequest) (*models.SessionCreateResponse, error) {
-	staff, err := h.authenticateStaff(req.Username, req.Password)
+func (h *DBHandler) CreateSession(ctx context.Context, req *models.SessionCreateRequest) (*models.SessionCreateResponse, error) {
+	staff, err := h.authenticateStaff(ctx, req.Username, req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
@@ -91,12 +116,26 @@ func (h *DBHandler) CreateSession(req *models.SessionCreateRequest) (*models.Ses
 		return nil, fmt.Errorf("failed to generate JWT token: %w", err)
 	}
 
-	err = h.storeSession(sessionID, tokenString)
+	// The login is attributed to the staff member it authenticated
+	actor := audit.ActorFromContext(ctx)
+	ctx = audit.WithActor(ctx, audit.Actor{ID: staff.ID, Name: staff.Username, RequestID: actor.RequestID})
+
+	err = h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		if err := h.storeSession(ctx, sessionID, tokenString); err != nil {
+			return err
+		}
+		return h.audit.Record(ctx, auditEntity, audit.ActionCreate, nil, &auditedSession{
+			ID:       sessionID,
+			StaffID:  staff.ID,
+			Username: staff.Username,
+			Role:     staff.Role,
+		})
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to store session: %w", err)
 	}
 
-	err = h.updateLastLogin(staff.ID)
+	err = h.updateLastLogin(ctx, staff.ID)
 	if err != nil {
 		h.logger.WithError(err).Warn("Failed to update last login")
 	}
@@ -116,19 +155,13 @@ func (h *DBHandler) CreateSession(req *models.SessionCreateRequest) (*models.Ses
 	}, nil
 }
 
-func (h *DBHandler) authenticateStaff(username, password string) (*models.Staff, error) {
-	query, err := h.queries.Get("get_staff_by_username")
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to get staff by username query")
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) authenticateStaff(ctx context.Context, username, password string) (*models.Staff, error) {
 	var staff models.Staff
 	var passwordHash string
 	var email sql.NullString
 	var lastLoginAt sql.NullTime
 
-	err = h.db.QueryRow(query, username).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(sessionSQL.GetStaffByUsernameQuery), queries.Args{"username": username}).Scan(
 		&staff.ID, &staff.Username, &email, &passwordHash,
 		&staff.FirstName, &staff.LastName, &staff.Role,
 		&staff.IsActive, &lastLoginAt, &staff.CreatedAt, &staff.UpdatedAt,
@@ -136,7 +169,7 @@ func (h *DBHandler) authenticateStaff(username, password string) (*models.Staff,
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, sharedErrors.Unauthorized("invalid_credentials", "invalid username or password")
 		}
 		h.logger.WithError(err).Error("Failed to get user")
 		return nil, fmt.Errorf("failed to get user: %w", err)
@@ -144,7 +177,7 @@ func (h *DBHandler) authenticateStaff(username, password string) (*models.Staff,
 
 	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)); err != nil {
 		h.logger.WithError(err).Error("Failed to compare password hash and password")
-		return nil, fmt.Errorf("invalid password")
+		return nil, sharedErrors.Unauthorized("invalid_credentials", "invalid username or password")
 	}
 
 	if email.Valid {
@@ -157,14 +190,11 @@ func (h *DBHandler) authenticateStaff(username, password string) (*models.Staff,
 	return &staff, nil
 }
 
-func (h *DBHandler) storeSession(sessionID, token string) error {
-	query, err := h.queries.Get("create_session")
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to get create session query")
-		return fmt.Errorf("failed to get query: %w", err)
-	}
-
-	_, err = h.db.Exec(query, sessionID, token)
+func (h *DBHandler) storeSession(ctx context.Context, sessionID, token string) error {
+	_, err := h.db.ExecNamedContext(ctx, h.queries.Get(sessionSQL.CreateSessionQuery), queries.Args{
+		"session_id": sessionID,
+		"token":      token,
+	})
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create session")
 		return fmt.Errorf("failed to create session: %w", err)
@@ -173,19 +203,13 @@ func (h *DBHandler) storeSession(sessionID, token string) error {
 	return nil
 }
 
-func (h *DBHandler) updateLastLogin(staffID string) error {
-	query, err := h.queries.Get("update_last_login")
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to get update last login query")
-		return fmt.Errorf("failed to get query: %w", err)
-	}
-
-	_, err = h.db.Exec(query, staffID)
+func (h *DBHandler) updateLastLogin(ctx context.Context, staffID string) error {
+	_, err := h.db.ExecNamedContext(ctx, h.queries.Get(sessionSQL.UpdateLastLoginQuery), queries.Args{"id": staffID})
 	return err
 }
 
 // ValidateSession validates a session token
-func (h *DBHandler) ValidateSession(token string) (*models.SessionValidationResponse, error) {
+func (h *DBHandler) ValidateSession(ctx context.Context, token string) (*models.SessionValidationResponse, error) {
 	// First validate the JWT token
 	claims, err := h.jwtHandler.ValidateToken(token)
 	if err != nil {
@@ -197,7 +221,7 @@ func (h *DBHandler) ValidateSession(token string) (*models.SessionValidationResp
 
 	// Check if token is expired
 	if time.Now().After(claims.ExpiresAt.Time) {
-		h.deleteSessionByToken(token)
+		h.deleteSessionByToken(ctx, token)
 		return &models.SessionValidationResponse{
 			Valid:   false,
 			Message: "Session expired",
@@ -205,7 +229,7 @@ func (h *DBHandler) ValidateSession(token string) (*models.SessionValidationResp
 	}
 
 	// Check if token exists in database
-	session, err := h.getSessionByToken(token)
+	session, err := h.getSessionByToken(ctx, token)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return &models.SessionValidationResponse{
@@ -218,7 +242,7 @@ func (h *DBHandler) ValidateSession(token string) (*models.SessionValidationResp
 	}
 
 	// Get staff information from JWT claims
-	staff, err := h.getStaffByID(claims.StaffID)
+	staff, err := h.getStaffByID(ctx, claims.StaffID)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get staff by ID")
 		return &models.SessionValidationResponse{
@@ -231,7 +255,7 @@ func (h *DBHandler) ValidateSession(token string) (*models.SessionValidationResp
 	if time.Until(claims.ExpiresAt.Time) < 5*time.Minute {
 		newToken, err := h.jwtHandler.GenerateToken(staff)
 		if err == nil {
-			h.updateSessionToken(session.SessionID, newToken)
+			h.updateSessionToken(ctx, session.SessionID, newToken)
 			// Return new token in response (optional, can be handled by client)
 		}
 	}
@@ -247,15 +271,9 @@ func (h *DBHandler) ValidateSession(token string) (*models.SessionValidationResp
 	}, nil
 }
 
-func (h *DBHandler) getSessionByID(sessionID string) (*models.Session, error) {
-	query, err := h.queries.Get(sessionSQL.GetSessionByIDQuery)
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to get session by ID query")
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) getSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
 	var session models.Session
-	err = h.db.QueryRow(query, sessionID).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(sessionSQL.GetSessionByIDQuery), queries.Args{"session_id": sessionID}).Scan(
 		&session.SessionID, &session.Token,
 	)
 	if err != nil {
@@ -266,15 +284,9 @@ func (h *DBHandler) getSessionByID(sessionID string) (*models.Session, error) {
 	return &session, nil
 }
 
-func (h *DBHandler) getSessionByToken(token string) (*models.Session, error) {
-	query, err := h.queries.Get(sessionSQL.GetSessionByTokenQuery)
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to get session by token query")
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) getSessionByToken(ctx context.Context, token string) (*models.Session, error) {
 	var session models.Session
-	err = h.db.QueryRow(query, token).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(sessionSQL.GetSessionByTokenQuery), queries.Args{"token": token}).Scan(
 		&session.SessionID, &session.Token,
 	)
 	if err != nil {
@@ -285,18 +297,12 @@ func (h *DBHandler) getSessionByToken(token string) (*models.Session, error) {
 	return &session, nil
 }
 
-func (h *DBHandler) getStaffByID(staffID string) (*models.Staff, error) {
-	query, err := h.queries.Get("get_staff_by_id")
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to get staff by ID query")
-		return nil, fmt.Errorf("failed to get query: %w", err)
-	}
-
+func (h *DBHandler) getStaffByID(ctx context.Context, staffID string) (*models.Staff, error) {
 	var staff models.Staff
 	var email sql.NullString
 	var lastLoginAt sql.NullTime
 
-	err = h.db.QueryRow(query, staffID).Scan(
+	err := h.db.QueryRowNamedContext(ctx, h.queries.Get(sessionSQL.GetStaffByIDQuery), queries.Args{"id": staffID}).Scan(
 		&staff.ID, &staff.Username, &email, &staff.FirstName, &staff.LastName, &staff.Role, &staff.IsActive, &lastLoginAt, &staff.CreatedAt, &staff.UpdatedAt,
 	)
 	if err != nil {
@@ -313,13 +319,8 @@ func (h *DBHandler) getStaffByID(staffID string) (*models.Staff, error) {
 	return &staff, nil
 }
 
-func (h *DBHandler) deleteSession(sessionID string) error {
-	query, err := h.queries.Get(sessionSQL.DeleteSessionQuery)
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to get delete session query")
-		return err
-	}
-	_, err = h.db.Exec(query, sessionID)
+func (h *DBHandler) deleteSession(ctx context.Context, sessionID string) error {
+	_, err := h.db.ExecNamedContext(ctx, h.queries.Get(sessionSQL.DeleteSessionQuery), queries.Args{"session_id": sessionID})
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete session")
 		return err
@@ -327,13 +328,8 @@ func (h *DBHandler) deleteSession(sessionID string) error {
 	return nil
 }
 
-func (h *DBHandler) deleteSessionByToken(token string) error {
-	query, err := h.queries.Get(sessionSQL.DeleteSessionByTokenQuery)
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to get delete session by token query")
-		return err
-	}
-	_, err = h.db.Exec(query, token)
+func (h *DBHandler) deleteSessionByToken(ctx context.Context, token string) error {
+	_, err := h.db.ExecNamedContext(ctx, h.queries.Get(sessionSQL.DeleteSessionByTokenQuery), queries.Args{"token": token})
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to delete session by token")
 		return err
@@ -341,13 +337,11 @@ func (h *DBHandler) deleteSessionByToken(token string) error {
 	return nil
 }
 
-func (h *DBHandler) updateSessionToken(sessionID, token string) error {
-	query, err := h.queries.Get(sessionSQL.UpdateSessionTokenQuery)
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to get update session token query")
-		return err
-	}
-	_, err = h.db.Exec(query, sessionID, token)
+func (h *DBHandler) updateSessionToken(ctx context.Context, sessionID, token string) error {
+	_, err := h.db.ExecNamedContext(ctx, h.queries.Get(sessionSQL.UpdateSessionTokenQuery), queries.Args{
+		"session_id": sessionID,
+		"token":      token,
+	})
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to update session token")
 		return err
@@ -356,8 +350,8 @@ func (h *DBHandler) updateSessionToken(sessionID, token string) error {
 }
 
 // DeleteSession handles logout by token
-func (h *DBHandler) DeleteSession(token string) (*models.SessionLogoutResponse, error) {
-	session, err := h.getSessionByToken(token)
+func (h *DBHandler) DeleteSession(ctx context.Context, token string) (*models.SessionLogoutResponse, error) {
+	session, err := h.getSessionByToken(ctx, token)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return &models.SessionLogoutResponse{
@@ -369,7 +363,13 @@ func (h *DBHandler) DeleteSession(token string) (*models.SessionLogoutResponse,
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	if err := h.deleteSessionByToken(token); err != nil {
+	err = h.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		if err := h.deleteSessionByToken(ctx, token); err != nil {
+			return err
+		}
+		return h.audit.Record(ctx, auditEntity, audit.ActionDelete, &auditedSession{ID: session.SessionID}, nil)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to delete session: %w", err)
 	}
 
@@ -32,10 +32,10 @@ func (h *HTTPHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.dbHandler.CreateSession(&req)
+	response, err := h.dbHandler.CreateSession(r.Context(), &req)
 	if err != nil {
 		h.logger.WithError(err).Error("Login failed")
-		sharedHttp.SendErrorResponse(w, http.StatusUnauthorized, "Invalid username or password")
+		sharedHttp.SendError(w, r, err, "Login failed")
 		return
 	}
 
@@ -55,9 +55,9 @@ func (h *HTTPHandler) ValidateSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.dbHandler.ValidateSession(req.Token)
+	response, err := h.dbHandler.ValidateSession(r.Context(), req.Token)
 	if err != nil {
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Validation failed")
+		sharedHttp.SendError(w, r, err, "Validation failed")
 		return
 	}
 
@@ -76,9 +76,9 @@ func (h *HTTPHandler) LogoutSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.dbHandler.DeleteSession(req.Token)
+	response, err := h.dbHandler.DeleteSession(r.Context(), req.Token)
 	if err != nil {
-		sharedHttp.SendErrorResponse(w, http.StatusInternalServerError, "Logout failed")
+		sharedHttp.SendError(w, r, err, "Logout failed")
 		return
 	}
 
@@ -7,6 +7,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	sharedHttp "shared/http"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -190,7 +192,7 @@ func TestContentTypeJSON(t *testing.T) {
 	handler.CreateSession(rr, req)
 
 	contentType := rr.Header().Get("Content-Type")
-	if contentType != "application/json" {
-		t.Errorf("Content-Type = %q, want %q", contentType, "application/json")
+	if contentType != sharedHttp.ProblemContentType {
+		t.Errorf("Content-Type = %q, want %q", contentType, sharedHttp.ProblemContentType)
 	}
 }
@@ -2,63 +2,39 @@ package sql
 
 import (
 	"embed"
-	"fmt"
-	"strings"
+
+	"shared/db/queries"
 )
 
 //go:embed scripts/*.sql
-var sqlFiles embed.FS
-
-// Queries holds all SQL queries
-type Queries struct {
-	queries map[string]string
-}
-
-// LoadQueries loads all SQL queries from the scripts directory
-func LoadQueries() (*Queries, error) {
-	queries := &Queries{
-		queries: make(map[string]string),
-	}
-
-	// Load all SQL files
-	files, err := sqlFiles.ReadDir("scripts")
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
-	}
-
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".sql") {
-			content, err := sqlFiles.ReadFile("scripts/" + file.Name())
-			if err != nil {
-				return nil, fmt.Errorf("failed to read SQL file %s: %w", file.Name(), err)
-			}
+var sqlScripts embed.FS
 
-			queryName := strings.TrimSuffix(file.Name(), ".sql")
-			queries.queries[queryName] = string(content)
-		}
-	}
-	return queries, nil
-}
-
-// Get retrieves a query by name
-func (q *Queries) Get(name string) (string, error) {
-	query, exists := q.queries[name]
-	if !exists {
-		return "", fmt.Errorf("query '%s' not found", name)
-	}
-	return query, nil
-}
-
-// SQL query constants
+// SQL query names
 const (
-	CreateSessionQuery         = "create_session"
-	GetStaffByUsernameQuery    = "get_staff_by_username"
-	UpdateLastLoginQuery       = "update_last_login"
-	GetSessionByIDQuery        = "get_session_by_id"
-	GetSessionByTokenQuery     = "get_session_by_token"
-	GetStaffByIDQuery           = "get_staff_by_id"
-	DeleteSessionQuery         = "delete_session"
-	DeleteSessionByTokenQuery  = "delete_session_by_token"
-	UpdateSessionTokenQuery    = "update_session_token"
-	DeleteExpiredSessionsQuery = "delete_expired_sessions"
+	CreateSessionQuery         queries.Name = "create_session"
+	GetStaffByUsernameQuery    queries.Name = "get_staff_by_username"
+	UpdateLastLoginQuery       queries.Name = "update_last_login"
+	GetSessionByIDQuery        queries.Name = "get_session_by_id"
+	GetSessionByTokenQuery     queries.Name = "get_session_by_token"
+	GetStaffByIDQuery          queries.Name = "get_staff_by_id"
+	DeleteSessionQuery         queries.Name = "delete_session"
+	DeleteSessionByTokenQuery  queries.Name = "delete_session_by_token"
+	UpdateSessionTokenQuery    queries.Name = "update_session_token"
+	DeleteExpiredSessionsQuery queries.Name = "delete_expired_sessions"
 )
+
+// LoadQueries loads and validates the session SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		CreateSessionQuery,
+		GetStaffByUsernameQuery,
+		UpdateLastLoginQuery,
+		GetSessionByIDQuery,
+		GetSessionByTokenQuery,
+		GetStaffByIDQuery,
+		DeleteSessionQuery,
+		DeleteSessionByTokenQuery,
+		UpdateSessionTokenQuery,
+		DeleteExpiredSessionsQuery,
+	)
+}
@@ -1,2 +1,2 @@
 INSERT INTO sessions (session_id, token)
-VALUES ($1, $2)
+VALUES (@session_id, @token)
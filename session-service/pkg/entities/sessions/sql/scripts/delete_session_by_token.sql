@@ -1 +1 @@
-DELETE FROM sessions WHERE token = $1
+DELETE FROM sessions WHERE token = @token
@@ -1,3 +1,3 @@
 SELECT session_id, token
 FROM sessions
-WHERE token = $1
+WHERE token = @token
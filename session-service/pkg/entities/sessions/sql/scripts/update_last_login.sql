@@ -1 +1 @@
-UPDATE staff SET last_login_at = CURRENT_TIMESTAMP WHERE id = $1
+UPDATE staff SET last_login_at = CURRENT_TIMESTAMP WHERE id = @id
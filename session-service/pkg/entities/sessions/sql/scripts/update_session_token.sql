@@ -1 +1 @@
-UPDATE sessions SET token = $2 WHERE session_id = $1
+UPDATE sessions SET token = @token WHERE session_id = @session_id
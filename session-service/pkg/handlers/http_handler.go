@@ -2,10 +2,9 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
-	"net/http"
 	"time"
 
+	"shared/audit"
 	sharedConfig "shared/config"
 	sharedHttp "shared/http"
 
@@ -40,13 +39,17 @@ func NewHTTPHandler(cfg *sharedConfig.Config, logger *logrus.Logger) (*MainHTTPH
 	ctx, cancel := context.WithCancel(context.Background())
 
 	//pvillalobos this should be configurable
-	// Create HTTP health monitor for data-service
+	// Create health monitor: the database is critical since the service talks to
+	// Postgres directly, data-service only serves configuration at startup
 	httpHealthMonitor, err := sharedHttp.NewHealthMonitor(logger, 1*time.Second)
 	if err != nil {
 		cancel()
 		return nil, err
 	}
-	httpHealthMonitor.AddService("data-service", sharedConfig.DATA_SERVICE_URL+"/api/v1/data/p/health")
+	httpHealthMonitor.AddCheck("database", sharedHttp.Critical, func(ctx context.Context) error {
+		return sessionsDBHandler.GetDB().Ping()
+	})
+	httpHealthMonitor.AddService("data-service", sharedConfig.DATA_SERVICE_URL+"/api/v1/data/p/readyz", sharedHttp.Degraded)
 	httpHealthMonitor.Start(ctx)
 
 	return &MainHTTPHandler{
@@ -73,36 +76,14 @@ func (h *MainHTTPHandler) CloseDB() error {
 }
 
 func (h *MainHTTPHandler) SetupRoutes(router *mux.Router) {
-	router.HandleFunc("/api/v1/sessions/p/health", h.HealthCheck).Methods("GET")
+	router.Use(audit.Middleware)
+
+	// Health checks (/p/health is kept for existing clients)
+	router.HandleFunc("/api/v1/sessions/p/livez", sharedHttp.LivenessHandler("session-service")).Methods("GET")
+	router.HandleFunc("/api/v1/sessions/p/readyz", h.httpHealthMonitor.ReadinessHandler("session-service")).Methods("GET")
+	router.HandleFunc("/api/v1/sessions/p/health", h.httpHealthMonitor.ReadinessHandler("session-service")).Methods("GET")
+
 	router.HandleFunc("/api/v1/sessions/p/login", h.sessionsHandler.CreateSession).Methods("POST")
 	router.HandleFunc("/api/v1/sessions/p/validate", h.sessionsHandler.ValidateSession).Methods("POST")
 	router.HandleFunc("/api/v1/sessions/logout", h.sessionsHandler.LogoutSession).Methods("POST")
 }
-
-func (h *MainHTTPHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
-		"service":   "session-service",
-		"timestamp": time.Now(),
-	}
-
-	// Check cached health state from background monitor
-	healthStatus := h.httpHealthMonitor.GetHealthStatus()
-	if !healthStatus.IsHealthy {
-		response["status"] = "unhealthy"
-		response["message"] = "Dependent services are not healthy"
-		response["services"] = healthStatus.Services
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(response)
-		return
-	}
-
-	response["status"] = "healthy"
-	response["message"] = "Session service is healthy"
-	response["services"] = healthStatus.Services
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-}
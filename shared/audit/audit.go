@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"shared/db/queryspec"
+	"shared/middlewares"
+)
+
+// Action is the kind of change recorded in the audit log
+type Action string
+
+// Audited actions
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionDelete  Action = "delete"
+	ActionRestore Action = "restore"
+)
+
+// SystemActor is recorded for changes that are not made on behalf of a user, such as
+// the ones applied by event consumers
+const SystemActor = "system"
+
+// Actor is who made a change
+type Actor struct {
+	ID        string
+	Name      string
+	RequestID string
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a context that attributes changes to actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by ctx, or the system actor
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorContextKey{}).(Actor); ok && actor.ID != "" {
+		return actor
+	}
+	return Actor{ID: SystemActor}
+}
+
+// Middleware attributes the changes made by a request to the user the gateway
+// authenticated, taken from the X-User-ID, X-Username and X-Request-ID headers
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers := middlewares.ExtractGatewayHeaders(r)
+		ctx := WithActor(r.Context(), Actor{
+			ID:        headers.UserID,
+			Name:      headers.Username,
+			RequestID: headers.RequestID,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Entry is a row of the audit log. Before and After hold the whole row for creates,
+// deletes and restores and only the changed fields for updates.
+type Entry struct {
+	ID        string          `json:"id"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Action    Action          `json:"action"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	ActorID   string          `json:"actor_id"`
+	ActorName *string         `json:"actor_name,omitempty"`
+	RequestID *string         `json:"request_id,omitempty"`
+	Source    string          `json:"source"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ListResponse is a page of audit log entries
+type ListResponse struct {
+	Entries []Entry `json:"entries"`
+	Total   int     `json:"total"`
+	Limit   int     `json:"limit"`
+	queryspec.PageInfo
+}
+
+// ignoredFields change on every write, so they are left out of update diffs
+var ignoredFields = map[string]bool{
+	"updated_at": true,
+	"version":    true,
+}
+
+// Diff returns the fields that differ between the JSON forms of before and after.
+// When one side is nil, as for creates and deletes, the other is returned whole.
+func Diff(before, after interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if beforeFields == nil || afterFields == nil {
+		return beforeFields, afterFields, nil
+	}
+
+	changedBefore := make(map[string]interface{})
+	changedAfter := make(map[string]interface{})
+	for name, value := range afterFields {
+		if ignoredFields[name] {
+			continue
+		}
+		if old, ok := beforeFields[name]; !ok || !reflect.DeepEqual(old, value) {
+			changedBefore[name] = beforeFields[name]
+			changedAfter[name] = value
+		}
+	}
+	for name, old := range beforeFields {
+		if _, ok := afterFields[name]; !ok && !ignoredFields[name] {
+			changedBefore[name] = old
+			changedAfter[name] = nil
+		}
+	}
+	return changedBefore, changedAfter, nil
+}
+
+// toFields decodes the JSON form of a row into its fields. A nil row, including a
+// nil pointer, has no fields.
+func toFields(row interface{}) (map[string]interface{}, error) {
+	if row == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audited row: %w", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode audited row: %w", err)
+	}
+	return fields, nil
+}
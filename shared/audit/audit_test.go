@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	auditSQL "shared/audit/sql"
+)
+
+type testRow struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	Notes     *string `json:"notes,omitempty"`
+	UpdatedAt string  `json:"updated_at"`
+	Version   int     `json:"version"`
+}
+
+func TestDiff_Update(t *testing.T) {
+	notes := "seasonal"
+	before := &testRow{ID: "1", Name: "Imperial", Price: 1500, Notes: &notes, UpdatedAt: "t1", Version: 1}
+	after := &testRow{ID: "1", Name: "Imperial", Price: 1800, UpdatedAt: "t2", Version: 2}
+
+	gotBefore, gotAfter, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	wantBefore := map[string]interface{}{"price": 1500.0, "notes": "seasonal"}
+	wantAfter := map[string]interface{}{"price": 1800.0, "notes": nil}
+	if !reflect.DeepEqual(gotBefore, wantBefore) {
+		t.Errorf("before = %v; want %v", gotBefore, wantBefore)
+	}
+	if !reflect.DeepEqual(gotAfter, wantAfter) {
+		t.Errorf("after = %v; want %v", gotAfter, wantAfter)
+	}
+}
+
+func TestDiff_CreateAndDelete(t *testing.T) {
+	row := &testRow{ID: "1", Name: "Imperial", Price: 1500}
+
+	before, after, err := Diff(nil, row)
+	if err != nil || before != nil || after["name"] != "Imperial" || after["id"] != "1" {
+		t.Errorf("Diff(nil, row) = (%v, %v, %v); want the whole row after", before, after, err)
+	}
+
+	var deleted *testRow
+	before, after, err = Diff(row, deleted)
+	if err != nil || after != nil || before["price"] != 1500.0 {
+		t.Errorf("Diff(row, nil) = (%v, %v, %v); want the whole row before", before, after, err)
+	}
+}
+
+func TestMiddleware_Actor(t *testing.T) {
+	var got Actor
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = ActorFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("PUT", "/api/v1/menu/variants/1", nil)
+	req.Header.Set("X-User-ID", "user-123")
+	req.Header.Set("X-Username", "maria")
+	req.Header.Set("X-Request-ID", "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := Actor{ID: "user-123", Name: "maria", RequestID: "req-1"}
+	if got != want {
+		t.Errorf("actor = %+v; want %+v", got, want)
+	}
+}
+
+func TestActorFromContext_System(t *testing.T) {
+	if actor := ActorFromContext(context.Background()); actor.ID != SystemActor {
+		t.Errorf("actor = %q; want %q", actor.ID, SystemActor)
+	}
+	if actor := ActorFromContext(WithActor(context.Background(), Actor{RequestID: "req-1"})); actor.ID != SystemActor {
+		t.Errorf("actor without user = %q; want %q", actor.ID, SystemActor)
+	}
+}
+
+func TestListSchema_Aliases(t *testing.T) {
+	spec, err := auditSQL.ListSchema.Parse(map[string][]string{
+		"entity": {"supplier"},
+		"actor":  {"user-123"},
+		"from":   {"2026-01-01T00:00:00Z"},
+		"to":     {"2026-02-01T00:00:00Z"},
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(spec.Filters) != 4 {
+		t.Errorf("filters = %+v; want entity, actor_id and a created_at range", spec.Filters)
+	}
+}
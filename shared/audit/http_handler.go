@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"net/http"
+
+	auditSQL "shared/audit/sql"
+	sharedHttp "shared/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPHandler serves the audit log query API
+type HTTPHandler struct {
+	log    *Log
+	logger *logrus.Logger
+}
+
+// NewHTTPHandler creates a new HTTP handler
+func NewHTTPHandler(log *Log, logger *logrus.Logger) *HTTPHandler {
+	return &HTTPHandler{log: log, logger: logger}
+}
+
+// List handles GET /api/v1/data/audit, e.g. ?entity=menu_variant&actor=<user id>&from=2026-01-01
+func (h *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
+	spec, err := auditSQL.ListSchema.Parse(r.URL.Query())
+	if err != nil {
+		sharedHttp.SendError(w, r, err, "Invalid list parameters")
+		return
+	}
+
+	response, err := h.log.List(r.Context(), spec)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list audit log")
+		sharedHttp.SendError(w, r, err, "Failed to list audit log")
+		return
+	}
+	response.SetLinks(r.URL)
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Audit log retrieved", response)
+}
@@ -0,0 +1,274 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	auditSQL "shared/audit/sql"
+	sharedDb "shared/db"
+	"shared/db/queries"
+	"shared/db/queryspec"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNoTransaction is returned when a change is recorded outside of a transaction
+var ErrNoTransaction = errors.New("audit entries must be recorded inside a transaction")
+
+// Log records the changes made by a service in the audit_log table
+type Log struct {
+	db      *sharedDb.DbHandler
+	queries *queries.Registry
+	source  string
+	logger  *logrus.Logger
+}
+
+// NewLog creates an audit log for the given source service
+func NewLog(db *sharedDb.DbHandler, source string, logger *logrus.Logger) (*Log, error) {
+	queries, err := auditSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &Log{
+		db:      db,
+		queries: queries,
+		source:  source,
+		logger:  logger,
+	}, nil
+}
+
+// Record stores a change of entity made by the actor of ctx. before and after are the
+// row before and after the change, before is nil for creates and restores and after
+// for deletes. ctx must carry a transaction opened with WithTx so the entry commits
+// with the change; a call where neither side exists or nothing changed records nothing.
+func (l *Log) Record(ctx context.Context, entity string, action Action, before, after interface{}) error {
+	if _, ok := sharedDb.TxFromContext(ctx); !ok {
+		return ErrNoTransaction
+	}
+
+	id, err := rowID(after)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		if id, err = rowID(before); err != nil {
+			return err
+		}
+	}
+	if id == "" {
+		return nil
+	}
+
+	beforeFields, afterFields, err := Diff(before, after)
+	if err != nil {
+		return err
+	}
+	if action == ActionUpdate && len(afterFields) == 0 && len(beforeFields) == 0 {
+		return nil
+	}
+
+	beforeJSON, err := encodeFields(beforeFields)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := encodeFields(afterFields)
+	if err != nil {
+		return err
+	}
+
+	actor := ActorFromContext(ctx)
+	_, err = l.db.ExecNamedContext(ctx, l.queries.Get(auditSQL.InsertAuditEntryQuery), queries.Args{
+		"entity":     entity,
+		"entity_id":  id,
+		"action":     action,
+		"before":     beforeJSON,
+		"after":      afterJSON,
+		"actor_id":   actor.ID,
+		"actor_name": nullString(actor.Name),
+		"request_id": nullString(actor.RequestID),
+		"source":     l.source,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record %s %s audit entry: %w", entity, action, err)
+	}
+
+	l.logger.WithFields(logrus.Fields{
+		"entity":    entity,
+		"entity_id": id,
+		"action":    action,
+		"actor_id":  actor.ID,
+	}).Debug("Change recorded in audit log")
+	return nil
+}
+
+// List returns a page of audit log entries matching the list spec
+func (l *Log) List(ctx context.Context, spec *queryspec.Spec) (*ListResponse, error) {
+	countQuery, countArgs, err := spec.CountQuery(l.queries.Get(auditSQL.ListAuditLogQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build audit log count: %w", err)
+	}
+
+	var total int
+	if err := l.db.QueryRowNamedContext(ctx, countQuery, countArgs).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count audit log entries: %w", err)
+	}
+
+	listQuery, listArgs, err := spec.ListQuery(l.queries.Get(auditSQL.ListAuditLogQuery), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build audit log list: %w", err)
+	}
+
+	rows, err := l.db.QueryNamedContext(ctx, listQuery, listArgs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		var before, after []byte
+		var actorName, requestID sql.NullString
+
+		err := rows.Scan(&entry.ID, &entry.Entity, &entry.EntityID, &entry.Action, &before, &after,
+			&entry.ActorID, &actorName, &requestID, &entry.Source, &entry.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit log entry: %w", err)
+		}
+
+		if before != nil {
+			entry.Before = json.RawMessage(before)
+		}
+		if after != nil {
+			entry.After = json.RawMessage(after)
+		}
+		if actorName.Valid {
+			entry.ActorName = &actorName.String
+		}
+		if requestID.Valid {
+			entry.RequestID = &requestID.String
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate audit log entries: %w", err)
+	}
+
+	entries, pageInfo, err := queryspec.Paginate(spec, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListResponse{
+		Entries:  entries,
+		Total:    total,
+		Limit:    spec.Limit,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// Create runs create in a transaction and records the row it returns
+func Create[T any](ctx context.Context, l *Log, entity string, create func(ctx context.Context) (T, error)) (T, error) {
+	return track(ctx, l, entity, ActionCreate, nil, create)
+}
+
+// Update loads the row with get, runs update in the same transaction and records
+// the fields it changed. An update that returns no row records nothing.
+func Update[T any](ctx context.Context, l *Log, entity, id string, get func(ctx context.Context, id string) (T, error), update func(ctx context.Context) (T, error)) (T, error) {
+	return track(ctx, l, entity, ActionUpdate, func(ctx context.Context) (T, error) {
+		return get(ctx, id)
+	}, update)
+}
+
+// Delete loads the row with get, runs del in the same transaction and records the
+// deleted row
+func Delete[T any](ctx context.Context, l *Log, entity, id string, get func(ctx context.Context, id string) (T, error), del func(ctx context.Context) error) error {
+	_, err := track(ctx, l, entity, ActionDelete, func(ctx context.Context) (T, error) {
+		return get(ctx, id)
+	}, func(ctx context.Context) (T, error) {
+		var deleted T
+		return deleted, del(ctx)
+	})
+	return err
+}
+
+// Restore runs restore in a transaction and records the row it brings back
+func Restore[T any](ctx context.Context, l *Log, entity string, restore func(ctx context.Context) (T, error)) (T, error) {
+	return track(ctx, l, entity, ActionRestore, nil, restore)
+}
+
+// track runs load and write in one transaction and records the change between them
+func track[T any](ctx context.Context, l *Log, entity string, action Action, load, write func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := l.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		var before interface{}
+		if load != nil {
+			row, err := load(ctx)
+			if err != nil {
+				return err
+			}
+			before = row
+		}
+
+		after, err := write(ctx)
+		if err != nil {
+			return err
+		}
+		result = after
+
+		switch action {
+		case ActionDelete:
+			return l.Record(ctx, entity, action, before, nil)
+		case ActionUpdate:
+			// Writes often return fewer columns than get, so the row is read back
+			// the same way it was loaded to keep the diff to what changed
+			row, err := load(ctx)
+			if err != nil {
+				return err
+			}
+			return l.Record(ctx, entity, action, before, row)
+		}
+		return l.Record(ctx, entity, action, before, after)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
+}
+
+// rowID returns the id field of a row, empty when there is no row
+func rowID(row interface{}) (string, error) {
+	fields, err := toFields(row)
+	if err != nil {
+		return "", err
+	}
+	if id, ok := fields["id"]; ok && id != nil {
+		return fmt.Sprint(id), nil
+	}
+	return "", nil
+}
+
+// encodeFields encodes the fields of a row for a JSONB column, nil stays NULL
+func encodeFields(fields map[string]interface{}) (interface{}, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	return string(data), nil
+}
+
+// nullString stores empty strings as NULL
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,41 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	InsertAuditEntryQuery queries.Name = "insert_audit_entry"
+	ListAuditLogQuery     queries.Name = "list_audit_log"
+)
+
+// ListSchema whitelists the columns of list_audit_log that can be filtered and sorted.
+// actor, from and to are shorthands for the usual "who changed what when" questions.
+var ListSchema = queryspec.NewSchema("-created_at",
+	queryspec.Field{Name: "entity", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "entity_id", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "action", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "actor_id", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "request_id", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "source", Type: queryspec.String, Filterable: true},
+	queryspec.Field{Name: "created_at", Type: queryspec.Time, Filterable: true, Sortable: true},
+).
+	WithAlias("actor", "actor_id", queryspec.Eq).
+	WithAlias("from", "created_at", queryspec.Gte).
+	WithAlias("to", "created_at", queryspec.Lt).
+	WithDefaultLimit(50)
+
+// LoadQueries loads and validates the audit log SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		InsertAuditEntryQuery,
+		ListAuditLogQuery,
+	)
+}
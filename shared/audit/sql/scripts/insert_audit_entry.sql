@@ -0,0 +1,2 @@
+INSERT INTO audit_log (entity, entity_id, action, before, after, actor_id, actor_name, request_id, source)
+VALUES (@entity, @entity_id, @action, @before, @after, @actor_id, @actor_name, @request_id, @source);
@@ -0,0 +1,2 @@
+SELECT id, entity, entity_id, action, before, after, actor_id, actor_name, request_id, source, created_at
+FROM audit_log;
@@ -0,0 +1,311 @@
+package bulk
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	sharedDb "shared/db"
+	sharedErrors "shared/errors"
+)
+
+func sampleTable() *Table {
+	t := NewTable("name", "description", "price")
+	t.Append("Cerveza Imperial", "Lager, 350 ml", "1500.00")
+	t.Append(`Café "negro"`, "", "1200.50")
+	t.Append("Nachos & queso", "<shared>", "4500")
+	return t
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{CSV, XLSX} {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, "menu", sampleTable()); err != nil {
+			t.Fatalf("Write(%s) error = %v", format, err)
+		}
+
+		got, err := Read(&buf, format)
+		if err != nil {
+			t.Fatalf("Read(%s) error = %v", format, err)
+		}
+		want := sampleTable()
+		if !reflect.DeepEqual(got.Columns, want.Columns) {
+			t.Errorf("%s columns = %v, want %v", format, got.Columns, want.Columns)
+		}
+
+		records := got.Records()
+		if len(records) != len(want.Rows) {
+			t.Fatalf("%s records = %d, want %d", format, len(records), len(want.Rows))
+		}
+		for i, rec := range records {
+			for j, column := range want.Columns {
+				if rec.String(column) != want.Rows[i][j] {
+					t.Errorf("%s row %d %s = %q, want %q", format, rec.Row, column, rec.String(column), want.Rows[i][j])
+				}
+			}
+		}
+	}
+}
+
+// TestRead_XLSXSharedStrings reads a workbook laid out the way Excel saves it: shared
+// and rich text strings, numbers and booleans, and cells skipped in a row
+func TestRead_XLSXSharedStrings(t *testing.T) {
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Catalog" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId3" Type="worksheet" Target="worksheets/catalog.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>Name</t></si><si><t>Price</t></si><si><t>Active</t></si>` +
+			`<si><r><t>Guaro </t></r><r><t>Sour</t></r></si></sst>`,
+		"xl/worksheets/catalog.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="s"><v>2</v></c></row>` +
+			`<row r="2"><c r="A2" t="s"><v>3</v></c><c r="B2"><v>3500.5</v></c><c r="D2" t="b"><v>1</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	table, err := Read(xlsxArchive(parts), XLSX)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(table.Columns, []string{"name", "price", "", "active"}) {
+		t.Errorf("Columns = %q", table.Columns)
+	}
+	rec := table.Records()[0]
+	if rec.String("name") != "Guaro Sour" || rec.String("price") != "3500.5" || rec.String("active") != "true" {
+		t.Errorf("record = %q %q %q", rec.String("name"), rec.String("price"), rec.String("active"))
+	}
+}
+
+func TestWrite_CSVEscapesFormulas(t *testing.T) {
+	table := NewTable("name", "price")
+	table.Append("=HYPERLINK(\"http://evil\")", "-1")
+	table.Append("@SUM(A1)", "+2")
+	table.Append("Ron", "1500")
+
+	var buf bytes.Buffer
+	if err := Write(&buf, CSV, "menu", table); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n")[1:] {
+		if formulaPrefix(strings.TrimPrefix(line, `"`)) {
+			t.Errorf("line %q starts with a formula", line)
+		}
+	}
+
+	got, err := Read(&buf, CSV)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Rows, table.Rows) {
+		t.Errorf("Rows = %q, want %q back", got.Rows, table.Rows)
+	}
+}
+
+func TestRead_CSVHeaderAndBlankRows(t *testing.T) {
+	input := "\xef\xbb\xbf Name ,IS_ACTIVE\nRon,yes\n,\nGin,0\n"
+	table, err := Read(strings.NewReader(input), CSV)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if !reflect.DeepEqual(table.Columns, []string{"name", "is_active"}) {
+		t.Errorf("Columns = %v, want [name is_active]", table.Columns)
+	}
+
+	records := table.Records()
+	if len(records) != 2 {
+		t.Fatalf("records = %d, want 2", len(records))
+	}
+	if records[1].Row != 4 {
+		t.Errorf("Row = %d, want 4 (blank row 3 skipped)", records[1].Row)
+	}
+	active, err := records[1].Bool("is_active")
+	if err != nil || active == nil || *active {
+		t.Errorf("Bool() = %v, %v, want false", active, err)
+	}
+}
+
+func TestRecord_Parsing(t *testing.T) {
+	table := NewTable("name", "order", "price", "active", "tags")
+	table.Append("", "2.0", "1,250.50", "maybe", "vegan| gluten free |")
+	rec := table.Records()[0]
+
+	if _, err := rec.Required("name"); !isField(err, "name", "required") {
+		t.Errorf("Required() error = %v, want a required name field error", err)
+	}
+	if order, err := rec.Int("order"); err != nil || *order != 2 {
+		t.Errorf("Int() = %v, %v, want 2", order, err)
+	}
+	if price, err := rec.Money("price"); err != nil || price.Amount().String() != "1250.5" {
+		t.Errorf("Money() = %v, %v, want 1250.5", price, err)
+	}
+	if _, err := rec.Bool("active"); !isField(err, "active", "invalid_boolean") {
+		t.Errorf("Bool() error = %v, want an invalid_boolean field error", err)
+	}
+	if tags := rec.List("tags"); !reflect.DeepEqual(tags, []string{"vegan", "gluten free"}) {
+		t.Errorf("List() = %v, want [vegan gluten free]", tags)
+	}
+	if rec.Optional("missing") != nil {
+		t.Error("Optional() of a missing column is not nil")
+	}
+}
+
+func TestRequireColumns(t *testing.T) {
+	err := NewTable("name").RequireColumns("name", "category")
+	if !isField(err, "category", "missing_column") {
+		t.Errorf("RequireColumns() error = %v, want a missing category column", err)
+	}
+	if err := NewTable("name", "category").RequireColumns("name", "category"); err != nil {
+		t.Errorf("RequireColumns() error = %v, want nil", err)
+	}
+}
+
+func TestToRowErrors(t *testing.T) {
+	if rowErrors, ok := toRowErrors(2, sharedErrors.Conflict("unique_violation", "duplicate entry")); !ok || rowErrors[0].Code != "unique_violation" {
+		t.Errorf("toRowErrors() conflict = %v, %v, want a row error", rowErrors, ok)
+	}
+
+	// A serialization failure mapped by the db package aborts the import to be retried
+	txConflict := sharedErrors.Conflict("transaction_conflict", "could not serialize").Wrap(sharedDb.ErrTxConflict)
+	if _, ok := toRowErrors(2, fmt.Errorf("failed to update: %w", txConflict)); ok {
+		t.Error("toRowErrors() reported a transaction conflict as a row error")
+	}
+}
+
+func TestReport_Err(t *testing.T) {
+	report := &Report{Sheet: "suppliers", Rows: 3, Errors: []RowError{
+		{Row: 2, Column: "name", Code: "required", Message: "value is required"},
+		{Row: 2, Column: "email", Code: "invalid", Message: "invalid email"},
+		{Row: 4, Code: "unique_violation", Message: "duplicate entry"},
+	}}
+
+	err := report.Err()
+	domainErr, ok := sharedErrors.As(err)
+	if !ok || domainErr.Kind != sharedErrors.KindValidation {
+		t.Fatalf("Err() = %v, want a validation error", err)
+	}
+	if domainErr.Message != "2 of 3 rows of suppliers failed, nothing was imported" {
+		t.Errorf("Message = %q", domainErr.Message)
+	}
+	if domainErr.Fields[0].Field != "rows[2].name" || domainErr.Fields[2].Field != "rows[4]" {
+		t.Errorf("Fields = %+v", domainErr.Fields)
+	}
+
+	if err := (&Report{}).Err(); err != nil {
+		t.Errorf("Err() without row errors = %v, want nil", err)
+	}
+}
+
+func TestColumnNames(t *testing.T) {
+	for index, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(index); got != name {
+			t.Errorf("columnName(%d) = %s, want %s", index, got, name)
+		}
+		if got, err := columnIndex(name + "12"); err != nil || got != index {
+			t.Errorf("columnIndex(%s12) = %d, %v, want %d", name, got, err, index)
+		}
+	}
+}
+
+// xlsxArchive zips the parts of a workbook whose first sheet is worksheets/sheet1.xml
+func xlsxArchive(parts map[string]string) *bytes.Buffer {
+	var buf bytes.Buffer
+	archive := writeXLSXParts(&buf, parts)
+	archive.Close()
+	return &buf
+}
+
+// writeXLSXParts writes the workbook and the given parts, leaving the archive open
+func writeXLSXParts(w io.Writer, parts map[string]string) *zip.Writer {
+	workbook := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+	}
+	for name, content := range parts {
+		workbook[name] = content
+	}
+
+	archive := zip.NewWriter(w)
+	for name, content := range workbook {
+		f, _ := archive.Create(name)
+		f.Write([]byte(content))
+	}
+	return archive
+}
+
+func worksheet(rows string) map[string]string {
+	return map[string]string{"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+		rows + `</sheetData></worksheet>`}
+}
+
+func TestRead_XLSXColumnBounds(t *testing.T) {
+	table, err := Read(xlsxArchive(worksheet(`<row><c r="XFD1" t="inlineStr"><is><t>last</t></is></c></row>`)), XLSX)
+	if err != nil {
+		t.Fatalf("Read() XFD1 error = %v", err)
+	}
+	if len(table.Columns) != xlsxMaxColumn+1 || table.Columns[xlsxMaxColumn] != "last" {
+		t.Errorf("Columns = %d, want XFD as column %d", len(table.Columns), xlsxMaxColumn+1)
+	}
+
+	// The first reference overflows int, the second would pad hundreds of millions of cells
+	for _, ref := range []string{"ZZZZZZZZZZZZZZ1", "AAAAAAA1", "XFE1"} {
+		_, err := Read(xlsxArchive(worksheet(`<row><c r="`+ref+`"><v>1</v></c></row>`)), XLSX)
+		if domainErr, ok := sharedErrors.As(err); !ok || domainErr.Kind != sharedErrors.KindValidation {
+			t.Errorf("Read() %s error = %v, want a validation error", ref, err)
+		}
+	}
+	if _, err := columnIndex("ZZZZZZZZZZZZZZ1"); err == nil {
+		t.Error("columnIndex(ZZZZZZZZZZZZZZ1) error = nil, want past the last column")
+	}
+}
+
+func TestRead_XLSXSizeLimits(t *testing.T) {
+	// A part that inflates past the cap, as a zip bomb would
+	bomb := worksheet(`<row><c r="A1"><v>` + strings.Repeat("9", xlsxMaxPartSize) + `</v></c></row>`)
+	if _, err := Read(xlsxArchive(bomb), XLSX); err == nil || !strings.Contains(err.Error(), "uncompressed") {
+		t.Errorf("Read() zip bomb error = %v, want the part rejected", err)
+	}
+
+	// The same part with a header claiming it is small is cut off at the cap
+	var buf bytes.Buffer
+	archive := writeXLSXParts(&buf, nil)
+	var compressed bytes.Buffer
+	deflater, _ := flate.NewWriter(&compressed, flate.BestSpeed)
+	deflater.Write([]byte(bomb["xl/worksheets/sheet1.xml"]))
+	deflater.Close()
+	f, _ := archive.CreateRaw(&zip.FileHeader{
+		Name:               "xl/worksheets/sheet1.xml",
+		Method:             zip.Deflate,
+		CompressedSize64:   uint64(compressed.Len()),
+		UncompressedSize64: 1024,
+	})
+	f.Write(compressed.Bytes())
+	archive.Close()
+	if _, err := Read(&buf, XLSX); err == nil {
+		t.Error("Read() with an understated size error = nil, want the part cut off")
+	}
+
+	rows := strings.Repeat(`<row><c r="A1"><v>1</v></c></row>`, MaxRows+2)
+	if _, err := Read(xlsxArchive(worksheet(rows)), XLSX); err == nil || !strings.Contains(err.Error(), "rows") {
+		t.Errorf("Read() with %d rows error = %v, want too many rows", MaxRows+2, err)
+	}
+}
+
+func isField(err error, field, code string) bool {
+	domainErr, ok := sharedErrors.As(err)
+	if !ok {
+		return false
+	}
+	for _, f := range domainErr.Fields {
+		if f.Field == field && f.Code == code {
+			return true
+		}
+	}
+	return false
+}
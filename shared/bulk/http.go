@@ -0,0 +1,119 @@
+package bulk
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	sharedErrors "shared/errors"
+	sharedHttp "shared/http"
+)
+
+// MaxUploadSize is the largest sheet an import accepts
+const MaxUploadSize = 10 << 20
+
+// ReadRequest reads the sheet of an import request, either the "file" field of a
+// multipart form or the raw body. The format is taken from ?format=, then from the
+// file name and then from the Content-Type, defaulting to CSV.
+func ReadRequest(w http.ResponseWriter, r *http.Request) (*Table, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadSize)
+
+	body := io.Reader(r.Body)
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	fileName := ""
+	if contentType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(MaxUploadSize); err != nil {
+			return nil, sharedErrors.Validation("invalid_upload", "failed to read the uploaded file").Wrap(err)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, sharedErrors.Validation("missing_file", "the upload has no \"file\" field").Wrap(err)
+		}
+		defer file.Close()
+		body, fileName, contentType = file, header.Filename, header.Header.Get("Content-Type")
+	}
+
+	format, err := requestFormat(r, fileName, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the whole upload first so an oversized body fails as such
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, sharedErrors.Validation("invalid_upload", fmt.Sprintf("the sheet must be at most %d MB", MaxUploadSize>>20)).Wrap(err)
+	}
+	return Read(bytes.NewReader(data), format)
+}
+
+func requestFormat(r *http.Request, fileName, contentType string) (Format, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return ParseFormat(format)
+	}
+	if ext := filepath.Ext(fileName); ext != "" {
+		return ParseFormat(ext)
+	}
+	if strings.HasPrefix(contentType, xlsxContentType) {
+		return XLSX, nil
+	}
+	return CSV, nil
+}
+
+// DryRun reports whether the request asks for ?dry_run=true
+func DryRun(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("dry_run")
+	if value == "" {
+		return false, nil
+	}
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, sharedErrors.Validation("invalid_dry_run", "dry_run must be true or false",
+			sharedErrors.FieldError{Field: "dry_run", Code: "invalid_boolean", Message: "must be true or false"})
+	}
+	return dryRun, nil
+}
+
+// SendReport writes the outcome of an import. Dry runs always return the report; an
+// import that failed is rendered as a validation problem listing the failing rows.
+func SendReport(w http.ResponseWriter, r *http.Request, report *Report) {
+	if report.DryRun {
+		sharedHttp.SendSuccessResponse(w, http.StatusOK, fmt.Sprintf("%s validated, nothing was imported", report.Sheet), report)
+		return
+	}
+	if err := report.Err(); err != nil {
+		sharedHttp.SendError(w, r, err, "")
+		return
+	}
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, fmt.Sprintf("%s imported", report.Sheet), report)
+}
+
+// SendTable writes an export as an attachment named after the sheet, in the format
+// asked with ?format= (CSV by default)
+func SendTable(w http.ResponseWriter, r *http.Request, sheet string, t *Table) {
+	format := CSV
+	if value := r.URL.Query().Get("format"); value != "" {
+		var err error
+		if format, err = ParseFormat(value); err != nil {
+			sharedHttp.SendError(w, r, err, "")
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, format, sheet, t); err != nil {
+		sharedHttp.SendError(w, r, err, fmt.Sprintf("Failed to export %s", sheet))
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": fmt.Sprintf("%s.%s", sheet, format),
+	}))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
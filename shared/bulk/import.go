@@ -0,0 +1,143 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sharedDb "shared/db"
+	sharedErrors "shared/errors"
+)
+
+// Action is what an import did with a row
+type Action string
+
+// Import actions
+const (
+	Created Action = "created"
+	Updated Action = "updated"
+)
+
+// RowError is a row that could not be imported
+type RowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Report is the outcome of an import. Nothing is written unless Committed is true.
+type Report struct {
+	Sheet     string     `json:"sheet"`
+	DryRun    bool       `json:"dry_run"`
+	Committed bool       `json:"committed"`
+	Rows      int        `json:"rows"`
+	Created   int        `json:"created"`
+	Updated   int        `json:"updated"`
+	Errors    []RowError `json:"errors"`
+}
+
+// RowFunc upserts the row of rec and reports whether it created or updated it. Domain
+// validation, conflict and not found errors are reported against the row; any other
+// error aborts the import.
+type RowFunc func(ctx context.Context, rec Record) (Action, error)
+
+// errRollback ends the import transaction without committing it
+var errRollback = errors.New("bulk: rollback")
+
+// Import runs fn for every record of table in one transaction. Each row runs in a
+// savepoint, so a failing row is reported and the remaining rows are still checked.
+// The transaction is committed only when no row failed and dryRun is false.
+func Import(ctx context.Context, db *sharedDb.DbHandler, sheet string, table *Table, dryRun bool, fn RowFunc) (*Report, error) {
+	records := table.Records()
+	var report *Report
+
+	err := db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		// A retried transaction starts over with a fresh report
+		report = &Report{Sheet: sheet, DryRun: dryRun, Rows: len(records), Errors: []RowError{}}
+
+		for _, rec := range records {
+			var action Action
+			err := db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+				var err error
+				action, err = fn(ctx, rec)
+				return err
+			})
+			if err != nil {
+				rowErrors, ok := toRowErrors(rec.Row, err)
+				if !ok {
+					return fmt.Errorf("failed to import row %d: %w", rec.Row, err)
+				}
+				report.Errors = append(report.Errors, rowErrors...)
+				continue
+			}
+
+			switch action {
+			case Created:
+				report.Created++
+			case Updated:
+				report.Updated++
+			}
+		}
+
+		if dryRun || len(report.Errors) > 0 {
+			return errRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+
+	report.Committed = err == nil
+	return report, nil
+}
+
+// toRowErrors turns the domain error of a row into row errors, one per failing field
+func toRowErrors(row int, err error) ([]RowError, bool) {
+	domainErr, ok := sharedErrors.As(err)
+	// Serialization failures and deadlocks abort the import so WithTx retries it
+	if !ok || sharedDb.IsRetryable(err) {
+		return nil, false
+	}
+	switch domainErr.Kind {
+	case sharedErrors.KindValidation, sharedErrors.KindConflict, sharedErrors.KindNotFound:
+	default:
+		return nil, false
+	}
+
+	if len(domainErr.Fields) == 0 {
+		return []RowError{{Row: row, Code: domainErr.Code, Message: domainErr.Message}}, true
+	}
+	rowErrors := make([]RowError, 0, len(domainErr.Fields))
+	for _, field := range domainErr.Fields {
+		rowErrors = append(rowErrors, RowError{Row: row, Column: field.Field, Code: field.Code, Message: field.Message})
+	}
+	return rowErrors, true
+}
+
+// Err returns the failed rows as a validation error, nil when every row passed
+func (r *Report) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+
+	fields := make([]sharedErrors.FieldError, 0, len(r.Errors))
+	for _, rowErr := range r.Errors {
+		field := fmt.Sprintf("rows[%d]", rowErr.Row)
+		if rowErr.Column != "" {
+			field += "." + rowErr.Column
+		}
+		fields = append(fields, sharedErrors.FieldError{Field: field, Code: rowErr.Code, Message: rowErr.Message})
+	}
+	return sharedErrors.Validation("import_failed",
+		fmt.Sprintf("%d of %d rows of %s failed, nothing was imported", countRows(r.Errors), r.Rows, r.Sheet), fields...)
+}
+
+func countRows(rowErrors []RowError) int {
+	rows := make(map[int]bool, len(rowErrors))
+	for _, rowErr := range rowErrors {
+		rows[rowErr.Row] = true
+	}
+	return len(rows)
+}
@@ -0,0 +1,304 @@
+// Package bulk reads and writes the CSV and XLSX sheets catalog data is imported and
+// exported with, and runs imports as a single all-or-nothing transaction.
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	sharedErrors "shared/errors"
+	"shared/money"
+)
+
+// Format is the file format of a sheet
+type Format string
+
+// Supported formats
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// MaxRows is the most data rows a sheet may have
+const MaxRows = 50000
+
+const (
+	csvContentType  = "text/csv"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// ParseFormat parses a format name or file extension, e.g. "csv" or ".xlsx"
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimPrefix(s, "."))) {
+	case CSV:
+		return CSV, nil
+	case XLSX:
+		return XLSX, nil
+	}
+	return "", sharedErrors.Validation("invalid_format", fmt.Sprintf("unsupported format %q, use csv or xlsx", s))
+}
+
+// ContentType returns the media type of the format
+func (f Format) ContentType() string {
+	if f == XLSX {
+		return xlsxContentType
+	}
+	return csvContentType + "; charset=utf-8"
+}
+
+// Table is a sheet whose first row names its columns
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// NewTable creates an empty table with the given columns
+func NewTable(columns ...string) *Table {
+	return &Table{Columns: columns}
+}
+
+// Append adds a row, values are in column order
+func (t *Table) Append(values ...string) {
+	t.Rows = append(t.Rows, values)
+}
+
+// RequireColumns fails when the header lacks any of columns
+func (t *Table) RequireColumns(columns ...string) error {
+	present := make(map[string]bool, len(t.Columns))
+	for _, column := range t.Columns {
+		present[column] = true
+	}
+
+	var fields []sharedErrors.FieldError
+	for _, column := range columns {
+		if !present[column] {
+			fields = append(fields, sharedErrors.FieldError{Field: column, Code: "missing_column", Message: "column is required"})
+		}
+	}
+	if len(fields) > 0 {
+		return sharedErrors.Validation("missing_columns", "the sheet is missing required columns", fields...)
+	}
+	return nil
+}
+
+// Records returns the rows keyed by column, skipping blank rows
+func (t *Table) Records() []Record {
+	index := make(map[string]int, len(t.Columns))
+	for i, column := range t.Columns {
+		index[column] = i
+	}
+
+	records := make([]Record, 0, len(t.Rows))
+	for i, values := range t.Rows {
+		if blank(values) {
+			continue
+		}
+		// Rows are numbered as spreadsheets show them, the header is row 1
+		records = append(records, Record{Row: i + 2, columns: index, values: values})
+	}
+	return records
+}
+
+// Read reads a sheet in the given format. Column names are trimmed and lower cased.
+func Read(r io.Reader, format Format) (*Table, error) {
+	var rows [][]string
+	var err error
+	switch format {
+	case XLSX:
+		rows, err = readXLSX(r)
+	default:
+		rows, err = readCSV(r)
+	}
+	if err != nil {
+		return nil, sharedErrors.Validation("invalid_sheet", fmt.Sprintf("failed to read %s sheet: %v", format, err)).Wrap(err)
+	}
+	if len(rows) == 0 {
+		return nil, sharedErrors.Validation("empty_sheet", "the sheet has no header row")
+	}
+	if len(rows) > MaxRows+1 {
+		return nil, sharedErrors.Validation("too_many_rows", fmt.Sprintf("the sheet has more than %d rows", MaxRows))
+	}
+
+	table := &Table{Columns: make([]string, len(rows[0])), Rows: rows[1:]}
+	for i, column := range rows[0] {
+		table.Columns[i] = strings.ToLower(strings.TrimSpace(column))
+	}
+	return table, nil
+}
+
+// Write writes the table in the given format
+func Write(w io.Writer, format Format, sheet string, t *Table) error {
+	if format == XLSX {
+		return writeXLSX(w, sheet, t)
+	}
+	return writeCSV(w, t)
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// Excel saves UTF-8 CSV with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for i, value := range row {
+			if len(value) > 1 && value[0] == '\'' && formulaPrefix(value[1:]) {
+				row[i] = value[1:]
+			}
+		}
+	}
+	return rows, nil
+}
+
+// writeCSV writes the table, quoting values a spreadsheet would run as a formula with a
+// leading ' that readCSV removes again
+func writeCSV(w io.Writer, t *Table) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(t.Columns); err != nil {
+		return err
+	}
+	for _, values := range t.Rows {
+		row := make([]string, len(values))
+		for i, value := range values {
+			if formulaPrefix(value) {
+				value = "'" + value
+			}
+			row[i] = value
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formulaPrefix reports whether a spreadsheet would read the value as a formula
+func formulaPrefix(value string) bool {
+	return value != "" && strings.ContainsRune("=+-@", rune(value[0]))
+}
+
+func blank(values []string) bool {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// Record is a row of a table
+type Record struct {
+	// Row is the row number in the sheet, used to point at errors
+	Row     int
+	columns map[string]int
+	values  []string
+}
+
+// String returns the trimmed value of column, empty when the column or value is missing
+func (r Record) String(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.values) {
+		return ""
+	}
+	return strings.TrimSpace(r.values[i])
+}
+
+// Required returns the value of column and fails when it is empty
+func (r Record) Required(column string) (string, error) {
+	value := r.String(column)
+	if value == "" {
+		return "", fieldError(column, "required", "value is required")
+	}
+	return value, nil
+}
+
+// Optional returns the value of column, nil when it is empty
+func (r Record) Optional(column string) *string {
+	value := r.String(column)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// Int returns the integer in column, nil when it is empty
+func (r Record) Int(column string) (*int, error) {
+	value := r.String(column)
+	if value == "" {
+		return nil, nil
+	}
+	// Spreadsheets store whole numbers as "3" or "3.0"
+	n, err := strconv.Atoi(strings.TrimSuffix(value, ".0"))
+	if err != nil {
+		return nil, fieldError(column, "invalid_integer", fmt.Sprintf("%q is not a whole number", value))
+	}
+	return &n, nil
+}
+
+// Bool returns the boolean in column, nil when it is empty. Besides true and false it
+// accepts yes/no, y/n and 1/0 in any case.
+func (r Record) Bool(column string) (*bool, error) {
+	value := strings.ToLower(r.String(column))
+	var b bool
+	switch value {
+	case "":
+		return nil, nil
+	case "true", "yes", "y", "1":
+		b = true
+	case "false", "no", "n", "0":
+		b = false
+	default:
+		return nil, fieldError(column, "invalid_boolean", fmt.Sprintf("%q is not true or false", value))
+	}
+	return &b, nil
+}
+
+// Money returns the amount in column, nil when it is empty
+func (r Record) Money(column string) (*money.Money, error) {
+	value := r.String(column)
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := money.Parse(strings.ReplaceAll(value, ",", ""))
+	if err != nil {
+		return nil, fieldError(column, "invalid_amount", fmt.Sprintf("%q is not an amount", value))
+	}
+	return &amount, nil
+}
+
+// List returns the values of a column holding a "|" separated list, nil when it is empty
+func (r Record) List(column string) []string {
+	value := r.String(column)
+	if value == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(value, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// FormatList joins values into a "|" separated list cell
+func FormatList(values []string) string {
+	return strings.Join(values, "|")
+}
+
+func fieldError(column, code, message string) error {
+	return sharedErrors.Validation("invalid_value", message, sharedErrors.FieldError{Field: column, Code: code, Message: message})
+}
@@ -0,0 +1,367 @@
+package bulk
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The XLSX support covers what catalog sheets need: the first worksheet of a workbook,
+// read as text, and a single worksheet of inline strings written back.
+
+const (
+	xlsxMainNS         = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	xlsxRelationshipNS = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	xlsxMaxSheetName   = 31
+	// xlsxMaxColumn is XFD, the last column a worksheet can have
+	xlsxMaxColumn = 16383
+	// xlsxMaxPartSize bounds each part once decompressed, the upload limit only
+	// applies to the compressed file
+	xlsxMaxPartSize = 32 << 20
+	// xlsxMaxCells bounds the cells of a sheet, counting the blanks that pad short rows,
+	// and the shared strings they can reference
+	xlsxMaxCells = 2 << 20
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is plain (<t>) or rich (<r><t>) text
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxRow struct {
+	Cells []struct {
+		Ref    string   `xml:"r,attr"`
+		Type   string   `xml:"t,attr"`
+		Value  string   `xml:"v"`
+		Inline xlsxText `xml:"is"`
+	} `xml:"c"`
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not an xlsx file: %w", err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		err := decodeZipElements(f, "si", func(d *xml.Decoder, start xml.StartElement) error {
+			if len(shared) == xlsxMaxCells {
+				return fmt.Errorf("the workbook has more than %d shared strings", xlsxMaxCells)
+			}
+			var item xlsxText
+			if err := d.DecodeElement(&item, &start); err != nil {
+				return err
+			}
+			shared = append(shared, item.String())
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("worksheet %s not found", sheetPath)
+	}
+
+	var rows [][]string
+	cells := 0
+	err = decodeZipElements(f, "row", func(d *xml.Decoder, start xml.StartElement) error {
+		// The header row comes on top of the data rows
+		if len(rows) == MaxRows+1 {
+			return fmt.Errorf("the sheet has more than %d rows", MaxRows)
+		}
+		var row xlsxRow
+		if err := d.DecodeElement(&row, &start); err != nil {
+			return err
+		}
+
+		var values []string
+		for i, cell := range row.Cells {
+			column := i
+			if cell.Ref != "" {
+				var err error
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return err
+				}
+			}
+			if column > xlsxMaxColumn {
+				return fmt.Errorf("cell %s is past the last column XFD", cell.Ref)
+			}
+			if len(values) <= column {
+				if cells += column + 1 - len(values); cells > xlsxMaxCells {
+					return fmt.Errorf("the sheet has more than %d cells", xlsxMaxCells)
+				}
+				values = append(values, make([]string, column+1-len(values))...)
+			}
+
+			switch cell.Type {
+			case "s":
+				n, err := strconv.Atoi(cell.Value)
+				if err != nil || n < 0 || n >= len(shared) {
+					return fmt.Errorf("cell %s references a missing shared string", cell.Ref)
+				}
+				values[column] = shared[n]
+			case "inlineStr":
+				values[column] = cell.Inline.String()
+			case "b":
+				values[column] = map[string]string{"1": "true", "0": "false"}[cell.Value]
+			default:
+				values[column] = cell.Value
+			}
+		}
+		rows = append(rows, values)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// firstSheetPath follows the workbook relationships to the first worksheet
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	wf, wok := files["xl/workbook.xml"]
+	rf, rok := files["xl/_rels/workbook.xml.rels"]
+	if !wok || !rok {
+		return "", errors.New("workbook not found")
+	}
+	if err := decodeZipXML(wf, &workbook); err != nil {
+		return "", err
+	}
+	if err := decodeZipXML(rf, &rels); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("workbook has no sheets")
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RelID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	return "", errors.New("first sheet not found")
+}
+
+// openZipPart opens a part of the workbook, reading at most xlsxMaxPartSize bytes of it
+// even when its header understates the uncompressed size
+func openZipPart(f *zip.File) (io.ReadCloser, error) {
+	if f.UncompressedSize64 > xlsxMaxPartSize {
+		return nil, fmt.Errorf("%s is larger than %d MB uncompressed", f.Name, xlsxMaxPartSize>>20)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(rc, xlsxMaxPartSize), rc}, nil
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := openZipPart(f)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("invalid %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// decodeZipElements streams a part and calls fn for each element named local, so
+// large parts are never held in memory at once
+func decodeZipElements(f *zip.File, local string, fn func(d *xml.Decoder, start xml.StartElement) error) error {
+	rc, err := openZipPart(f)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	d := xml.NewDecoder(rc)
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", f.Name, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != local {
+			continue
+		}
+		if err := fn(d, start); err != nil {
+			return err
+		}
+	}
+}
+
+// columnIndex returns the zero based column of a cell reference such as "AB12".
+// Columns past XFD are rejected before they can overflow.
+func columnIndex(ref string) (int, error) {
+	column := 0
+	letters := 0
+	for _, c := range strings.ToUpper(ref) {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		column = column*26 + int(c-'A'+1)
+		if column-1 > xlsxMaxColumn {
+			return 0, fmt.Errorf("cell reference %q is past the last column XFD", ref)
+		}
+		letters++
+	}
+	if letters == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return column - 1, nil
+}
+
+// columnName returns the letters of a zero based column, e.g. 27 is "AB"
+func columnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+func writeXLSX(w io.Writer, sheet string, t *Table) error {
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+	if len(sheet) > xlsxMaxSheetName {
+		sheet = sheet[:xlsxMaxSheetName]
+	}
+
+	parts := map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`,
+		"_rels/.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`,
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="` + xlsxMainNS + `" xmlns:r="` + xlsxRelationshipNS + `">` +
+			`<sheets><sheet name="` + escapeXML(sheet) + `" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`,
+		"xl/worksheets/sheet1.xml": worksheetXML(t),
+	}
+
+	names := make([]string, 0, len(parts))
+	for name := range parts {
+		names = append(names, name)
+	}
+	// [Content_Types].xml sorts first, some readers expect it there
+	sort.Strings(names)
+
+	archive := zip.NewWriter(w)
+	for _, name := range names {
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, parts[name]); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func worksheetXML(t *Table) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="` + xlsxMainNS + `"><sheetData>`)
+
+	writeRow := func(row int, values []string) {
+		fmt.Fprintf(&b, `<row r="%d">`, row)
+		for i, value := range values {
+			if value == "" {
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, columnName(i), row, escapeXML(value))
+		}
+		b.WriteString(`</row>`)
+	}
+
+	writeRow(1, t.Columns)
+	for i, values := range t.Rows {
+		writeRow(i+2, values)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -224,6 +224,12 @@ func setDefaultValues(config *Config, serviceName string) {
 		config.Set("DB_NAME", "barrest_db")
 		config.Set("DB_SSL_MODE", "disable")
 		config.Set("LOG_LEVEL", "info")
+		// Logical backups taken with pg_dump
+		config.Set("BACKUP_DIR", "/var/lib/barrest/backups")
+		config.Set("BACKUP_INTERVAL", "24h")
+		config.Set("BACKUP_RETENTION_DAYS", "14")
+		// Retention policies, their windows are RETENTION_<TABLE>_DAYS rows of the settings table
+		config.Set("RETENTION_INTERVAL", "24h")
 	case "session":
 		config.Set("SERVER_PORT", "8087")
 		config.Set("SERVER_HOST", "0.0.0.0")
@@ -262,6 +268,17 @@ func setDefaultValues(config *Config, serviceName string) {
 		// Cost calculation settings
 		config.Set("DEFAULT_PORTION_GRAMS", "120")   // Default portion size in grams for cost calculation
 		config.Set("DEFAULT_EARNING_MARGIN", "30.0") // Default earning margin percentage (30%)
+		config.Set("DEFAULT_TAX_RATE", "13.0")       // Tax added to menu prices for the tax-inclusive price
+		// Happy hours and other schedules follow the venue's wall clock
+		config.Set("VENUE_TIMEZONE", "America/Costa_Rica")
+		// How "any variant of a sub-category" ingredients are costed: average or max
+		config.Set("RECIPE_COST_STRATEGY", "average")
+		// Soft deleted rows are purged after this many days
+		config.Set("SOFT_DELETE_RETENTION_DAYS", "90")
+		// Menu names are written in this language, translations add others
+		config.Set("DEFAULT_LANGUAGE", "es")
+		// Seconds shared caches may serve the public menu before revalidating it
+		config.Set("PUBLIC_MENU_MAX_AGE", "60")
 	case "invoice":
 		config.Set("SERVER_PORT", "8092")
 		config.Set("SERVER_HOST", "0.0.0.0")
@@ -275,6 +292,8 @@ func setDefaultValues(config *Config, serviceName string) {
 		// Cost calculation settings
 		config.Set("DEFAULT_PORTION_GRAMS", "120")   // Default portion size in grams for cost calculation
 		config.Set("DEFAULT_EARNING_MARGIN", "30.0") // Default earning margin percentage (30%)
+		// Soft deleted rows are purged after this many days
+		config.Set("SOFT_DELETE_RETENTION_DAYS", "90")
 	case "inventory":
 		config.Set("SERVER_PORT", "8084")
 		config.Set("SERVER_HOST", "0.0.0.0")
@@ -288,6 +307,8 @@ func setDefaultValues(config *Config, serviceName string) {
 		// Cost calculation settings
 		config.Set("DEFAULT_PORTION_GRAMS", "120")   // Default portion size in grams for cost calculation
 		config.Set("DEFAULT_EARNING_MARGIN", "30.0") // Default earning margin percentage (30%)
+		// Soft deleted rows are purged after this many days
+		config.Set("SOFT_DELETE_RETENTION_DAYS", "90")
 	case "gateway":
 		config.Set("SERVER_PORT", "8082")
 		config.Set("SERVER_HOST", "0.0.0.0")
@@ -304,6 +325,9 @@ func setDefaultValues(config *Config, serviceName string) {
 		config.Set("CORS_ALLOWED_ORIGINS", "*")
 		config.Set("CORS_ALLOWED_METHODS", "GET,POST,PUT,DELETE,OPTIONS")
 		config.Set("CORS_ALLOWED_HEADERS", "Content-Type,Authorization")
+		// Requests per minute and burst allowed to each client on public routes
+		config.Set("PUBLIC_RATE_LIMIT", "120")
+		config.Set("PUBLIC_RATE_BURST", "60")
 	}
 }
 
@@ -352,6 +376,17 @@ func populateConfigFromEnvironment(config *Config, logger *logrus.Logger) {
 		"DEFAULT_SERVICE_RATE",
 		"DEFAULT_PORTION_GRAMS",
 		"DEFAULT_EARNING_MARGIN",
+		"RECIPE_COST_STRATEGY",
+		"VENUE_TIMEZONE",
+		"SOFT_DELETE_RETENTION_DAYS",
+		"DEFAULT_LANGUAGE",
+		"PUBLIC_MENU_MAX_AGE",
+		"PUBLIC_RATE_LIMIT",
+		"PUBLIC_RATE_BURST",
+		"BACKUP_DIR",
+		"BACKUP_INTERVAL",
+		"BACKUP_RETENTION_DAYS",
+		"RETENTION_INTERVAL",
 	}
 
 	for _, key := range envKeys {
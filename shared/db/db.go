@@ -3,8 +3,15 @@ package db
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"net"
 	"shared/config"
+	"shared/db/queries"
+	sharedErrors "shared/errors"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/lib/pq"
@@ -24,6 +31,10 @@ type Config struct {
 	DBName   string
 	SSLMode  string
 
+	// ApplicationName is reported to Postgres so pg_stat_activity can attribute
+	// connections to the service that opened them
+	ApplicationName string
+
 	// Connection pool settings
 	MaxOpenConns    int
 	MaxIdleConns    int
@@ -37,6 +48,9 @@ type Config struct {
 	// Retry settings
 	MaxRetries    int
 	RetryInterval time.Duration
+
+	// CacheStatements prepares named queries once and reuses the statements
+	CacheStatements bool
 }
 
 // DefaultConfig returns a default configuration
@@ -56,6 +70,8 @@ func DefaultConfig(logger *logrus.Logger) *Config {
 		DBName:   dbName,
 		SSLMode:  sslMode,
 
+		ApplicationName: "data-service",
+
 		// Connection pool settings
 		MaxOpenConns:    config.DATA_SERVICE_MAX_OPEN_CONNS,
 		MaxIdleConns:    config.DATA_SERVICE_MAX_IDLE_CONNS,
@@ -82,6 +98,10 @@ type DbHandler struct {
 	config        *Config
 	logger        *logrus.Logger
 	healthMonitor *DBHealthMonitor
+
+	// Prepared statement cache for named queries
+	stmtMu sync.RWMutex
+	stmts  map[queries.Name]*sql.Stmt
 }
 
 // NewDbHandler creates a new database handler instance
@@ -89,6 +109,7 @@ func NewDbHandler(config *Config, logger *logrus.Logger) *DbHandler {
 	return &DbHandler{
 		config: config,
 		logger: logger,
+		stmts:  make(map[queries.Name]*sql.Stmt),
 	}
 }
 
@@ -203,6 +224,8 @@ func (h *DbHandler) Close() error {
 		h.cancelCtx()
 	}
 
+	h.closeStatements()
+
 	err := h.db.Close()
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to close database connection")
@@ -237,7 +260,7 @@ func (h *DbHandler) Ping() error {
 }
 
 // BeginTx starts a new transaction
-func (h *DbHandler) BeginTx(ctx context.Context) (*sql.Tx, error) {
+func (h *DbHandler) BeginTx(ctx context.Context) (*Tx, error) {
 	if h.db == nil {
 		h.logger.Error("Database connection is nil for BeginTx")
 		return nil, fmt.Errorf("database connection is nil")
@@ -250,11 +273,11 @@ func (h *DbHandler) BeginTx(ctx context.Context) (*sql.Tx, error) {
 	}
 
 	h.logger.Debug("Transaction started")
-	return tx, nil
+	return &Tx{Tx: tx, handler: h}, nil
 }
 
 // CommitTx commits a transaction
-func (h *DbHandler) CommitTx(tx *sql.Tx) error {
+func (h *DbHandler) CommitTx(tx *Tx) error {
 	if tx == nil {
 		h.logger.Error("Transaction is nil for CommitTx")
 		return fmt.Errorf("transaction is nil")
@@ -271,7 +294,7 @@ func (h *DbHandler) CommitTx(tx *sql.Tx) error {
 }
 
 // RollbackTx rolls back a transaction
-func (h *DbHandler) RollbackTx(tx *sql.Tx) error {
+func (h *DbHandler) RollbackTx(tx *Tx) error {
 	if tx == nil {
 		h.logger.Error("Transaction is nil for RollbackTx")
 		return fmt.Errorf("transaction is nil")
@@ -419,9 +442,14 @@ func (h *DbHandler) IsConnected() bool {
 	return h.healthMonitor.IsHealthy()
 }
 
+// NewListener opens a dedicated LISTEN/NOTIFY connection using the handler configuration
+func (h *DbHandler) NewListener(minReconnect, maxReconnect time.Duration, eventCallback pq.EventCallbackType) *pq.Listener {
+	return pq.NewListener(h.buildConnectionString(), minReconnect, maxReconnect, eventCallback)
+}
+
 // buildConnectionString creates the PostgreSQL connection string
 func (h *DbHandler) buildConnectionString() string {
-	return fmt.Sprintf(
+	connStr := fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s connect_timeout=%d statement_timeout=%d tcp_user_timeout=%d",
 		h.config.Host,
 		h.config.Port,
@@ -433,6 +461,10 @@ func (h *DbHandler) buildConnectionString() string {
 		1000, // 1 second statement timeout in milliseconds
 		2000, // 2 second TCP user timeout in milliseconds (forces TCP to give up faster)
 	)
+	if h.config.ApplicationName != "" {
+		connStr += fmt.Sprintf(" application_name='%s'", strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(h.config.ApplicationName))
+	}
+	return connStr
 }
 
 // configureConnectionPool sets up the connection pool
@@ -458,6 +490,19 @@ func (h *DbHandler) sanitizeQuery(query string) string {
 	return query
 }
 
+// PostgreSQL error codes mapped onto domain errors
+const (
+	uniqueViolationCode           pq.ErrorCode = "23505"
+	foreignKeyViolationCode       pq.ErrorCode = "23503"
+	notNullViolationCode          pq.ErrorCode = "23502"
+	checkViolationCode            pq.ErrorCode = "23514"
+	invalidTextRepresentationCode pq.ErrorCode = "22P02"
+
+	connectionExceptionClass   pq.ErrorClass = "08"
+	insufficientResourcesClass pq.ErrorClass = "53"
+	operatorInterventionClass  pq.ErrorClass = "57"
+)
+
 // handlePostgreSQLError handles PostgreSQL-specific errors
 func (h *DbHandler) handlePostgreSQLError(err error) error {
 	if pqErr, ok := err.(*pq.Error); ok {
@@ -472,15 +517,34 @@ func (h *DbHandler) handlePostgreSQLError(err error) error {
 		}).Error("PostgreSQL error occurred")
 
 		switch pqErr.Code {
-		case "23505": // unique_violation
-			return fmt.Errorf("duplicate entry: %s", pqErr.Detail)
-		case "23503": // foreign_key_violation
-			return fmt.Errorf("foreign key constraint violation: %s", pqErr.Detail)
-		case "23502": // not_null_violation
-			return fmt.Errorf("required field missing: %s", pqErr.Column)
-		default:
-			return fmt.Errorf("database error [%s]: %s", pqErr.Code, pqErr.Message)
+		case uniqueViolationCode:
+			return sharedErrors.Conflict("unique_violation", fmt.Sprintf("duplicate entry: %s", pqErr.Detail)).Wrap(pqErr)
+		case foreignKeyViolationCode:
+			// Inserts and updates reference a missing row; deletes remove a row still referenced
+			if strings.HasPrefix(pqErr.Message, "update or delete") {
+				return sharedErrors.Conflict("foreign_key_violation", fmt.Sprintf("record is still referenced: %s", pqErr.Detail)).Wrap(pqErr)
+			}
+			return sharedErrors.Validation("foreign_key_violation", fmt.Sprintf("foreign key constraint violation: %s", pqErr.Detail)).Wrap(pqErr)
+		case notNullViolationCode:
+			return sharedErrors.Validation("not_null_violation", fmt.Sprintf("required field missing: %s", pqErr.Column),
+				sharedErrors.FieldError{Field: pqErr.Column, Code: "required", Message: "field is required"}).Wrap(pqErr)
+		case checkViolationCode:
+			return sharedErrors.Validation("check_violation", fmt.Sprintf("value violates constraint %s", pqErr.Constraint)).Wrap(pqErr)
+		case invalidTextRepresentationCode:
+			return sharedErrors.Validation("invalid_input", pqErr.Message).Wrap(pqErr)
+		case serializationFailureCode, deadlockDetectedCode:
+			return sharedErrors.Conflict("transaction_conflict", fmt.Sprintf("%s [%s]: %s", ErrTxConflict, pqErr.Code, pqErr.Message)).Wrap(ErrTxConflict)
+		}
+
+		if class := pqErr.Code.Class(); class == connectionExceptionClass || class == insufficientResourcesClass || class == operatorInterventionClass {
+			return sharedErrors.Unavailable("database_unavailable", fmt.Sprintf("database unavailable [%s]: %s", pqErr.Code, pqErr.Message)).Wrap(pqErr)
 		}
+		return sharedErrors.Internal("database_error", fmt.Sprintf("database error [%s]: %s", pqErr.Code, pqErr.Message)).Wrap(pqErr)
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr) {
+		return sharedErrors.Unavailable("database_unavailable", "database unavailable").Wrap(err)
 	}
 
 	return err
@@ -494,9 +558,10 @@ type IDBHandler interface {
 	Ping() error
 
 	// Transaction management
-	BeginTx(ctx context.Context) (*sql.Tx, error)
-	CommitTx(tx *sql.Tx) error
-	RollbackTx(tx *sql.Tx) error
+	BeginTx(ctx context.Context) (*Tx, error)
+	CommitTx(tx *Tx) error
+	RollbackTx(tx *Tx) error
+	WithTx(ctx context.Context, opts *sql.TxOptions, fn TxFunc) error
 
 	// Query operations
 	Query(query string, args ...interface{}) (*sql.Rows, error)
@@ -508,6 +573,11 @@ type IDBHandler interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 
+	// Named query operations
+	QueryNamedContext(ctx context.Context, query *queries.Query, args queries.Args) (*sql.Rows, error)
+	QueryRowNamedContext(ctx context.Context, query *queries.Query, args queries.Args) *Row
+	ExecNamedContext(ctx context.Context, query *queries.Query, args queries.Args) (sql.Result, error)
+
 	// Prepared statements
 	Prepare(query string) (*sql.Stmt, error)
 	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
@@ -516,4 +586,5 @@ type IDBHandler interface {
 	GetDB() *sql.DB
 	GetStats() sql.DBStats
 	IsConnected() bool
+	NewListener(minReconnect, maxReconnect time.Duration, eventCallback pq.EventCallbackType) *pq.Listener
 }
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestBuildConnectionString_ApplicationName(t *testing.T) {
+	h := &DbHandler{config: &Config{Host: "postgres", Port: 5432, DBName: "barrest_db", ApplicationName: "menu-service"}}
+	if connStr := h.buildConnectionString(); !strings.HasSuffix(connStr, " application_name='menu-service'") {
+		t.Errorf("buildConnectionString() = %q, want application_name set", connStr)
+	}
+
+	// The name is quoted so lib/pq parses it back unchanged
+	h.config.ApplicationName = `bar's \ app`
+	if _, err := pq.NewConnector(h.buildConnectionString()); err != nil {
+		t.Errorf("NewConnector() error = %v", err)
+	}
+
+	h.config.ApplicationName = ""
+	if connStr := h.buildConnectionString(); strings.Contains(connStr, "application_name") {
+		t.Errorf("buildConnectionString() = %q, want no application_name", connStr)
+	}
+}
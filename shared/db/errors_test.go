@@ -0,0 +1,55 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	sharedErrors "shared/errors"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+func TestHandlePostgreSQLError(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	h := &DbHandler{logger: logger}
+
+	tests := []struct {
+		name     string
+		err      *pq.Error
+		wantKind sharedErrors.Kind
+		wantCode string
+	}{
+		{"unique violation", &pq.Error{Code: "23505"}, sharedErrors.KindConflict, "unique_violation"},
+		{"missing reference", &pq.Error{Code: "23503", Message: "insert or update on table \"menu_variants\" violates foreign key constraint"}, sharedErrors.KindValidation, "foreign_key_violation"},
+		{"referenced row deleted", &pq.Error{Code: "23503", Message: "update or delete on table \"suppliers\" violates foreign key constraint"}, sharedErrors.KindConflict, "foreign_key_violation"},
+		{"not null violation", &pq.Error{Code: "23502", Column: "name"}, sharedErrors.KindValidation, "not_null_violation"},
+		{"check violation", &pq.Error{Code: "23514"}, sharedErrors.KindValidation, "check_violation"},
+		{"invalid uuid", &pq.Error{Code: "22P02"}, sharedErrors.KindValidation, "invalid_input"},
+		{"serialization failure", &pq.Error{Code: "40001"}, sharedErrors.KindConflict, "transaction_conflict"},
+		{"connection failure", &pq.Error{Code: "08006"}, sharedErrors.KindUnavailable, "database_unavailable"},
+		{"too many connections", &pq.Error{Code: "53300"}, sharedErrors.KindUnavailable, "database_unavailable"},
+		{"syntax error", &pq.Error{Code: "42601"}, sharedErrors.KindInternal, "database_error"},
+	}
+
+	for _, tt := range tests {
+		domainErr, ok := sharedErrors.As(h.handlePostgreSQLError(tt.err))
+		if !ok {
+			t.Errorf("%s: handlePostgreSQLError() did not return a domain error", tt.name)
+			continue
+		}
+		if domainErr.Kind != tt.wantKind || domainErr.Code != tt.wantCode {
+			t.Errorf("%s: handlePostgreSQLError() = (%s, %s); want (%s, %s)", tt.name, domainErr.Kind, domainErr.Code, tt.wantKind, tt.wantCode)
+		}
+	}
+
+	if err := h.handlePostgreSQLError(&pq.Error{Code: "40P01"}); !IsRetryable(err) {
+		t.Errorf("deadlock: IsRetryable() = false; want true")
+	}
+
+	plain := errors.New("boom")
+	if err := h.handlePostgreSQLError(plain); err != plain {
+		t.Errorf("plain error: handlePostgreSQLError() = %v; want it unchanged", err)
+	}
+}
@@ -0,0 +1,244 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"shared/db/queries"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Row is the result of a named single-row query. It defers binding and
+// preparation errors to Scan so call sites keep the database/sql shape.
+type Row struct {
+	row     *sql.Row
+	err     error
+	handler *DbHandler
+}
+
+// Scan copies the columns of the row into dest. PostgreSQL errors raised while
+// executing the query are mapped the same way as for the other named helpers.
+func (r *Row) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	if err := r.row.Scan(dest...); err != nil {
+		if err == sql.ErrNoRows {
+			return err
+		}
+		return r.handler.handlePostgreSQLError(err)
+	}
+	return nil
+}
+
+// Err returns the binding or query error, if any
+func (r *Row) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.row.Err()
+}
+
+// Tx wraps sql.Tx with the named query helpers
+type Tx struct {
+	*sql.Tx
+	handler *DbHandler
+	depth   int
+}
+
+// QueryNamedContext executes a named query that returns rows with context
+func (h *DbHandler) QueryNamedContext(ctx context.Context, query *queries.Query, args queries.Args) (*sql.Rows, error) {
+	return h.queryNamed(ctx, nil, query, args)
+}
+
+// QueryRowNamedContext executes a named query that returns a single row with context
+func (h *DbHandler) QueryRowNamedContext(ctx context.Context, query *queries.Query, args queries.Args) *Row {
+	return h.queryRowNamed(ctx, nil, query, args)
+}
+
+// ExecNamedContext executes a named query without returning rows with context
+func (h *DbHandler) ExecNamedContext(ctx context.Context, query *queries.Query, args queries.Args) (sql.Result, error) {
+	return h.execNamed(ctx, nil, query, args)
+}
+
+// QueryNamedContext executes a named query that returns rows inside the transaction with context
+func (t *Tx) QueryNamedContext(ctx context.Context, query *queries.Query, args queries.Args) (*sql.Rows, error) {
+	return t.handler.queryNamed(ctx, t.Tx, query, args)
+}
+
+// QueryRowNamedContext executes a named query that returns a single row inside the transaction with context
+func (t *Tx) QueryRowNamedContext(ctx context.Context, query *queries.Query, args queries.Args) *Row {
+	return t.handler.queryRowNamed(ctx, t.Tx, query, args)
+}
+
+// ExecNamedContext executes a named query without returning rows inside the transaction with context
+func (t *Tx) ExecNamedContext(ctx context.Context, query *queries.Query, args queries.Args) (sql.Result, error) {
+	return t.handler.execNamed(ctx, t.Tx, query, args)
+}
+
+func (h *DbHandler) queryNamed(ctx context.Context, tx *sql.Tx, query *queries.Query, args queries.Args) (*sql.Rows, error) {
+	if tx == nil {
+		tx = h.contextTx(ctx)
+	}
+
+	positional, err := query.Bind(args)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := h.statement(ctx, tx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	var rows *sql.Rows
+	if stmt != nil {
+		rows, err = stmt.QueryContext(ctx, positional...)
+	} else if tx != nil {
+		rows, err = tx.QueryContext(ctx, query.SQL(), positional...)
+	} else {
+		rows, err = h.db.QueryContext(ctx, query.SQL(), positional...)
+	}
+
+	logEntry := h.namedLogEntry(query, time.Since(start))
+	if err != nil {
+		logEntry.WithError(err).Error("Query execution failed for QueryNamed")
+		return nil, h.handlePostgreSQLError(err)
+	}
+
+	logEntry.Debug("Query executed successfully")
+	return rows, nil
+}
+
+func (h *DbHandler) queryRowNamed(ctx context.Context, tx *sql.Tx, query *queries.Query, args queries.Args) *Row {
+	if tx == nil {
+		tx = h.contextTx(ctx)
+	}
+
+	positional, err := query.Bind(args)
+	if err != nil {
+		return &Row{err: err}
+	}
+
+	stmt, err := h.statement(ctx, tx, query)
+	if err != nil {
+		return &Row{err: err}
+	}
+
+	start := time.Now()
+	var row *sql.Row
+	if stmt != nil {
+		row = stmt.QueryRowContext(ctx, positional...)
+	} else if tx != nil {
+		row = tx.QueryRowContext(ctx, query.SQL(), positional...)
+	} else {
+		row = h.db.QueryRowContext(ctx, query.SQL(), positional...)
+	}
+
+	h.namedLogEntry(query, time.Since(start)).Debug("QueryRow executed")
+	return &Row{row: row, handler: h}
+}
+
+func (h *DbHandler) execNamed(ctx context.Context, tx *sql.Tx, query *queries.Query, args queries.Args) (sql.Result, error) {
+	if tx == nil {
+		tx = h.contextTx(ctx)
+	}
+
+	positional, err := query.Bind(args)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := h.statement(ctx, tx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	var result sql.Result
+	if stmt != nil {
+		result, err = stmt.ExecContext(ctx, positional...)
+	} else if tx != nil {
+		result, err = tx.ExecContext(ctx, query.SQL(), positional...)
+	} else {
+		result, err = h.db.ExecContext(ctx, query.SQL(), positional...)
+	}
+
+	logEntry := h.namedLogEntry(query, time.Since(start))
+	if err != nil {
+		logEntry.WithError(err).Error("Exec execution failed for ExecNamed")
+		return nil, h.handlePostgreSQLError(err)
+	}
+
+	logEntry.Debug("Exec executed successfully")
+	return result, nil
+}
+
+// statement returns the cached prepared statement for the query, bound to tx when given.
+// It returns nil when statement caching is disabled or the query is not cacheable.
+func (h *DbHandler) statement(ctx context.Context, tx *sql.Tx, query *queries.Query) (*sql.Stmt, error) {
+	if h.db == nil {
+		h.logger.Error("Database connection is nil for named query")
+		return nil, fmt.Errorf("database connection is nil")
+	}
+
+	if !h.config.CacheStatements || !query.Cacheable() {
+		return nil, nil
+	}
+
+	h.stmtMu.RLock()
+	stmt, ok := h.stmts[query.Name()]
+	h.stmtMu.RUnlock()
+
+	if !ok {
+		h.stmtMu.Lock()
+		if stmt, ok = h.stmts[query.Name()]; !ok {
+			var err error
+			stmt, err = h.PrepareContext(ctx, query.SQL())
+			if err != nil {
+				h.stmtMu.Unlock()
+				return nil, err
+			}
+			h.stmts[query.Name()] = stmt
+		}
+		h.stmtMu.Unlock()
+	}
+
+	if tx != nil {
+		return tx.StmtContext(ctx, stmt), nil
+	}
+	return stmt, nil
+}
+
+// contextTx returns the transaction carried by ctx when it belongs to this handler.
+// Named queries issued with such a context join the transaction opened by WithTx.
+func (h *DbHandler) contextTx(ctx context.Context) *sql.Tx {
+	if tx, ok := TxFromContext(ctx); ok && tx.handler == h {
+		return tx.Tx
+	}
+	return nil
+}
+
+// closeStatements closes every cached prepared statement
+func (h *DbHandler) closeStatements() {
+	h.stmtMu.Lock()
+	defer h.stmtMu.Unlock()
+
+	for name, stmt := range h.stmts {
+		if err := stmt.Close(); err != nil {
+			h.logger.WithError(err).WithField("query", name).Warn("Failed to close prepared statement")
+		}
+		delete(h.stmts, name)
+	}
+}
+
+func (h *DbHandler) namedLogEntry(query *queries.Query, duration time.Duration) *logrus.Entry {
+	return h.logger.WithFields(logrus.Fields{
+		"query":    query.Name(),
+		"duration": duration,
+	})
+}
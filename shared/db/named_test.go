@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+
+	"shared/db/queries"
+	"shared/db/queryspec"
+
+	"github.com/sirupsen/logrus"
+)
+
+// recordingDriver prepares any statement and records the SQL of every statement it runs.
+// Statements check their argument count the way PostgreSQL does.
+type recordingDriver struct {
+	mu       sync.Mutex
+	prepared []string
+	ran      []string
+}
+
+var placeholder = regexp.MustCompile(`\$\d+`)
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) { return &recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.mu.Lock()
+	c.d.prepared = append(c.d.prepared, query)
+	c.d.mu.Unlock()
+
+	inputs := map[string]bool{}
+	for _, p := range placeholder.FindAllString(query, -1) {
+		inputs[p] = true
+	}
+	return &recordingStmt{d: c.d, query: query, inputs: len(inputs)}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type recordingStmt struct {
+	d      *recordingDriver
+	query  string
+	inputs int
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return s.inputs }
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	s.d.ran = append(s.d.ran, s.query)
+	s.d.mu.Unlock()
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{"id"} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestQueryNamed_CachedHandlerRunsEachSpecSQL(t *testing.T) {
+	rec := &recordingDriver{}
+	sql.Register("recording", rec)
+	db, err := sql.Open("recording", "")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	defer db.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	h := NewDbHandler(&Config{CacheStatements: true}, logger)
+	h.db = db
+
+	base, err := queries.Compile("list_items", "SELECT id, name, price FROM items WHERE deleted = @deleted;")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	schema := queryspec.NewSchema("name",
+		queryspec.Field{Name: "name", Type: queryspec.String, Filterable: true, Sortable: true},
+		queryspec.Field{Name: "price", Type: queryspec.Number, Filterable: true, Sortable: true},
+	)
+
+	var want []string
+	for _, query := range []string{"filter[name]=Beer", "filter[price][gte]=10&filter[name]=Beer&sort=-price"} {
+		values, _ := url.ParseQuery(query)
+		spec, err := schema.Parse(values)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", query, err)
+		}
+		list, args, err := spec.ListQuery(base, queries.Args{"deleted": false})
+		if err != nil {
+			t.Fatalf("ListQuery(%q) error = %v", query, err)
+		}
+
+		rows, err := h.QueryNamedContext(context.Background(), list, args)
+		if err != nil {
+			t.Fatalf("QueryNamedContext(%q) error = %v", query, err)
+		}
+		rows.Close()
+		want = append(want, list.SQL())
+	}
+
+	if len(rec.ran) != 2 || rec.ran[0] != want[0] || rec.ran[1] != want[1] {
+		t.Errorf("ran %q, want the SQL of each spec %q", rec.ran, want)
+	}
+
+	// Scripts loaded by name are still prepared once
+	for i := 0; i < 2; i++ {
+		rows, err := h.QueryNamedContext(context.Background(), base, queries.Args{"deleted": false})
+		if err != nil {
+			t.Fatalf("QueryNamedContext(base) error = %v", err)
+		}
+		rows.Close()
+	}
+	prepared := 0
+	for _, query := range rec.prepared {
+		if query == base.SQL() {
+			prepared++
+		}
+	}
+	if prepared != 1 {
+		t.Errorf("base query prepared %d times, want once", prepared)
+	}
+}
@@ -0,0 +1,262 @@
+package queries
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Name identifies a SQL script by its file name without the .sql extension
+type Name string
+
+// Args holds the named arguments bound to a query
+type Args map[string]interface{}
+
+// Query is a loaded SQL script whose named parameters were compiled to positional placeholders
+type Query struct {
+	name     Name
+	script   string
+	sql      string
+	params   []string
+	uncached bool
+}
+
+// Name returns the query name
+func (q *Query) Name() Name {
+	return q.name
+}
+
+// Script returns the original script with its @name parameters
+func (q *Query) Script() string {
+	return q.script
+}
+
+// SQL returns the compiled SQL using PostgreSQL positional placeholders ($1, $2, ...)
+func (q *Query) SQL() string {
+	return q.sql
+}
+
+// Uncached marks a query built for a single request, whose SQL changes while its name
+// stays the same, so its statement is never cached under the name
+func (q *Query) Uncached() *Query {
+	q.uncached = true
+	return q
+}
+
+// Cacheable reports whether the prepared statement of the query can be cached by name
+func (q *Query) Cacheable() bool {
+	return !q.uncached
+}
+
+// Params returns the parameter names in positional order
+func (q *Query) Params() []string {
+	return q.params
+}
+
+// Bind maps the named arguments onto the positional arguments expected by the compiled SQL.
+// Every parameter must be provided and every argument must be used by the query.
+func (q *Query) Bind(args Args) ([]interface{}, error) {
+	positional := make([]interface{}, len(q.params))
+	for i, param := range q.params {
+		value, ok := args[param]
+		if !ok {
+			return nil, fmt.Errorf("query '%s': missing argument '%s'", q.name, param)
+		}
+		positional[i] = value
+	}
+
+	if len(args) != len(q.params) {
+		for arg := range args {
+			if !q.hasParam(arg) {
+				return nil, fmt.Errorf("query '%s': unknown argument '%s'", q.name, arg)
+			}
+		}
+	}
+
+	return positional, nil
+}
+
+func (q *Query) hasParam(name string) bool {
+	for _, param := range q.params {
+		if param == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry holds a validated set of queries
+type Registry struct {
+	queries map[Name]*Query
+}
+
+// Load reads every .sql file in dir and validates it against the declared query names.
+// Loading fails if a declared name has no script, if a script is not declared, or if a
+// script cannot be compiled.
+func Load(fsys fs.FS, dir string, names ...Name) (*Registry, error) {
+	declared := make(map[Name]bool, len(names))
+	for _, name := range names {
+		if declared[name] {
+			return nil, fmt.Errorf("query '%s' is declared more than once", name)
+		}
+		declared[name] = true
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SQL scripts directory: %w", err)
+	}
+
+	registry := &Registry{
+		queries: make(map[Name]*Query, len(names)),
+	}
+
+	var orphans []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		name := Name(strings.TrimSuffix(entry.Name(), ".sql"))
+		if !declared[name] {
+			orphans = append(orphans, entry.Name())
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SQL file %s: %w", entry.Name(), err)
+		}
+
+		query, err := Compile(name, string(content))
+		if err != nil {
+			return nil, err
+		}
+		registry.queries[name] = query
+	}
+
+	if len(orphans) > 0 {
+		return nil, fmt.Errorf("SQL files without a declared query: %s", strings.Join(orphans, ", "))
+	}
+
+	var missing []string
+	for _, name := range names {
+		if _, ok := registry.queries[name]; !ok {
+			missing = append(missing, string(name)+".sql")
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("declared queries without a SQL file: %s", strings.Join(missing, ", "))
+	}
+
+	return registry, nil
+}
+
+// Get returns a query by name. Names are validated by Load, so asking for a name
+// that was not declared is a programming error.
+func (r *Registry) Get(name Name) *Query {
+	query, ok := r.queries[name]
+	if !ok {
+		panic(fmt.Sprintf("query '%s' is not registered", name))
+	}
+	return query
+}
+
+// Compile converts a script using @name parameters into positional SQL.
+// A parameter used several times maps to the same placeholder. String literals,
+// quoted identifiers and comments are left untouched. Positional placeholders are
+// rejected so scripts stay on a single parameter style.
+func Compile(name Name, script string) (*Query, error) {
+	var out strings.Builder
+	out.Grow(len(script))
+
+	var params []string
+	index := make(map[string]int)
+
+	for i := 0; i < len(script); {
+		c := script[i]
+
+		switch {
+		case c == '\'' || c == '"':
+			end := skipQuoted(script, i, c)
+			out.WriteString(script[i:end])
+			i = end
+
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			out.WriteString(script[i : i+end])
+			i += end
+
+		case c == '/' && i+1 < len(script) && script[i+1] == '*':
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("query '%s': unterminated block comment", name)
+			}
+			out.WriteString(script[i : i+2+end+2])
+			i += 2 + end + 2
+
+		case c == '$' && i+1 < len(script) && isDigit(script[i+1]):
+			return nil, fmt.Errorf("query '%s': positional parameter at offset %d, use @name parameters", name, i)
+
+		case c == '@' && i+1 < len(script) && isIdentStart(script[i+1]):
+			end := i + 1
+			for end < len(script) && isIdentPart(script[end]) {
+				end++
+			}
+			param := script[i+1 : end]
+			position, ok := index[param]
+			if !ok {
+				params = append(params, param)
+				position = len(params)
+				index[param] = position
+			}
+			fmt.Fprintf(&out, "$%d", position)
+			i = end
+
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+
+	return &Query{
+		name:   name,
+		script: script,
+		sql:    out.String(),
+		params: params,
+	}, nil
+}
+
+// skipQuoted returns the index just past the quoted section starting at start.
+// Doubled quotes inside the section are treated as escaped quotes.
+func skipQuoted(s string, start int, quote byte) int {
+	for i := start + 1; i < len(s); i++ {
+		if s[i] != quote {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(s)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
@@ -0,0 +1,123 @@
+package queries
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCompile_NamedParameters(t *testing.T) {
+	query, err := Compile("update_item", `UPDATE items
+SET name = COALESCE(@name, name),
+    tags = COALESCE(@tags::jsonb, tags)
+WHERE id = @id AND (@name IS NULL OR name <> @name)`)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	want := `UPDATE items
+SET name = COALESCE($1, name),
+    tags = COALESCE($2::jsonb, tags)
+WHERE id = $3 AND ($1 IS NULL OR name <> $1)`
+	if query.SQL() != want {
+		t.Errorf("SQL() = %q; want %q", query.SQL(), want)
+	}
+
+	params := strings.Join(query.Params(), ",")
+	if params != "name,tags,id" {
+		t.Errorf("Params() = %s; want name,tags,id", params)
+	}
+}
+
+func TestCompile_IgnoresLiteralsAndComments(t *testing.T) {
+	script := `-- lookup by @comment
+SELECT '@literal', "@ident", menu_types @> @types /* @block */ FROM t WHERE id = @id`
+	query, err := Compile("lookup", script)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	want := `-- lookup by @comment
+SELECT '@literal', "@ident", menu_types @> $1 /* @block */ FROM t WHERE id = $2`
+	if query.SQL() != want {
+		t.Errorf("SQL() = %q; want %q", query.SQL(), want)
+	}
+}
+
+func TestCompile_RejectsPositionalParameters(t *testing.T) {
+	if _, err := Compile("legacy", "SELECT * FROM t WHERE id = $1"); err == nil {
+		t.Error("Compile() error = nil; want error for positional parameter")
+	}
+}
+
+func TestBind(t *testing.T) {
+	query, err := Compile("q", "SELECT * FROM t WHERE a = @a AND b = @b")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	args, err := query.Bind(Args{"b": 2, "a": 1})
+	if err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if len(args) != 2 || args[0] != 1 || args[1] != 2 {
+		t.Errorf("Bind() = %v; want [1 2]", args)
+	}
+
+	if _, err := query.Bind(Args{"a": 1}); err == nil {
+		t.Error("Bind() error = nil; want missing argument error")
+	}
+
+	if _, err := query.Bind(Args{"a": 1, "b": 2, "c": 3}); err == nil {
+		t.Error("Bind() error = nil; want unknown argument error")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"scripts/get_item.sql":    {Data: []byte("SELECT * FROM items WHERE id = @id")},
+		"scripts/delete_item.sql": {Data: []byte("DELETE FROM items WHERE id = @id")},
+	}
+
+	registry, err := Load(fsys, "scripts", "get_item", "delete_item")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := registry.Get("get_item").SQL(); got != "SELECT * FROM items WHERE id = $1" {
+		t.Errorf("Get(get_item).SQL() = %q", got)
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"scripts/get_item.sql": {Data: []byte("SELECT 1")},
+	}
+
+	_, err := Load(fsys, "scripts", "get_item", "delete_item")
+	if err == nil || !strings.Contains(err.Error(), "delete_item.sql") {
+		t.Errorf("Load() error = %v; want missing delete_item.sql", err)
+	}
+}
+
+func TestLoad_OrphanFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"scripts/get_item.sql":    {Data: []byte("SELECT 1")},
+		"scripts/unused_item.sql": {Data: []byte("SELECT 2")},
+	}
+
+	_, err := Load(fsys, "scripts", "get_item")
+	if err == nil || !strings.Contains(err.Error(), "unused_item.sql") {
+		t.Errorf("Load() error = %v; want orphan unused_item.sql", err)
+	}
+}
+
+func TestLoad_DuplicateName(t *testing.T) {
+	fsys := fstest.MapFS{
+		"scripts/get_item.sql": {Data: []byte("SELECT 1")},
+	}
+
+	if _, err := Load(fsys, "scripts", "get_item", "get_item"); err == nil {
+		t.Error("Load() error = nil; want duplicate declaration error")
+	}
+}
@@ -0,0 +1,161 @@
+package queryspec
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Cursor points at the row a page starts after. Values holds the sort keys of that
+// row and Sort the sort it was created for, so a cursor cannot be replayed against
+// a different order.
+type Cursor struct {
+	Sort   string            `json:"s"`
+	Values map[string]string `json:"v"`
+	Prev   bool              `json:"p,omitempty"`
+}
+
+// Encode returns the opaque form used in the cursor parameter
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("cursor is malformed")
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("cursor is malformed")
+	}
+	return &cursor, nil
+}
+
+// checkCursor verifies that the cursor matches the requested sort and holds valid keys
+func (sp *Spec) checkCursor(cursor *Cursor) error {
+	if cursor.Sort != sp.SortString() {
+		return fmt.Errorf("cursor was created for sort '%s'", cursor.Sort)
+	}
+	for _, key := range sp.keys() {
+		value, ok := cursor.Values[key.Field]
+		if !ok {
+			return fmt.Errorf("cursor is missing the value of '%s'", key.Field)
+		}
+		normalized, err := normalizeValue(sp.schema.fields[key.Field].Type, Eq, value)
+		if err != nil {
+			return fmt.Errorf("cursor value of '%s' is invalid: %w", key.Field, err)
+		}
+		cursor.Values[key.Field] = normalized
+	}
+	return nil
+}
+
+// Links are the URLs of the current, next and previous pages
+type Links struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// PageInfo describes where a page sits in the list. Embed it in list responses.
+type PageInfo struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Links      Links  `json:"links"`
+}
+
+// SetLinks builds the page links from the request URL, keeping its filters and sort
+func (p *PageInfo) SetLinks(u *url.URL) {
+	p.Links = Links{Self: u.RequestURI()}
+	if p.NextCursor != "" {
+		p.Links.Next = withCursor(u, p.NextCursor)
+	}
+	if p.PrevCursor != "" {
+		p.Links.Prev = withCursor(u, p.PrevCursor)
+	}
+}
+
+func withCursor(u *url.URL, cursor string) string {
+	query := u.Query()
+	query.Set("cursor", cursor)
+	query.Del("page")
+	link := *u
+	link.RawQuery = query.Encode()
+	return link.RequestURI()
+}
+
+// Paginate turns the rows read with ListQuery into a page: it drops the extra row,
+// restores the order of a page read backwards and creates the cursors of the
+// neighbouring pages from the first and last items.
+func Paginate[T any](sp *Spec, items []T) ([]T, PageInfo, error) {
+	var info PageInfo
+
+	hasMore := len(items) > sp.Limit
+	if hasMore {
+		items = items[:sp.Limit]
+	}
+
+	backward := sp.backward()
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	if len(items) == 0 {
+		return items, info, nil
+	}
+
+	if (!backward && hasMore) || backward {
+		cursor, err := sp.cursorFor(items[len(items)-1], false)
+		if err != nil {
+			return nil, info, err
+		}
+		info.NextCursor = cursor
+	}
+	if (backward && hasMore) || (!backward && sp.Cursor != nil) {
+		cursor, err := sp.cursorFor(items[0], true)
+		if err != nil {
+			return nil, info, err
+		}
+		info.PrevCursor = cursor
+	}
+
+	return items, info, nil
+}
+
+// cursorFor reads the sort keys of an item through its JSON form, whose field names
+// are the schema field names
+func (sp *Spec) cursorFor(item interface{}, prev bool) (string, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor item: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var fields map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return "", fmt.Errorf("failed to decode cursor item: %w", err)
+	}
+
+	cursor := &Cursor{Sort: sp.SortString(), Values: make(map[string]string), Prev: prev}
+	for _, key := range sp.keys() {
+		switch value := fields[key.Field].(type) {
+		case string:
+			cursor.Values[key.Field] = value
+		case json.Number:
+			cursor.Values[key.Field] = value.String()
+		case bool:
+			cursor.Values[key.Field] = strconv.FormatBool(value)
+		default:
+			return "", fmt.Errorf("sort field '%s' has no value to build a cursor from", key.Field)
+		}
+	}
+	return cursor.Encode(), nil
+}
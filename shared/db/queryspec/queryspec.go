@@ -0,0 +1,398 @@
+package queryspec
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sharedErrors "shared/errors"
+)
+
+const (
+	// DefaultLimit is the page size used when the request does not set one
+	DefaultLimit = 20
+	// MaxLimit is the largest page size a request may ask for
+	MaxLimit = 100
+)
+
+// FieldType decides how filter and cursor values are validated and cast in SQL
+type FieldType string
+
+const (
+	String    FieldType = "string"
+	Number    FieldType = "number"
+	Bool      FieldType = "bool"
+	Time      FieldType = "time"
+	UUID      FieldType = "uuid"
+	JSONArray FieldType = "json_array"
+)
+
+// Op is a filter operator as used in filter[field][op]=value
+type Op string
+
+const (
+	Eq       Op = "eq"
+	Ne       Op = "ne"
+	Gt       Op = "gt"
+	Gte      Op = "gte"
+	Lt       Op = "lt"
+	Lte      Op = "lte"
+	In       Op = "in"
+	Like     Op = "like"
+	IsNull   Op = "null"
+	Contains Op = "contains"
+)
+
+// opsByType lists the operators each field type accepts
+var opsByType = map[FieldType][]Op{
+	String:    {Eq, Ne, In, Like, IsNull},
+	Number:    {Eq, Ne, Gt, Gte, Lt, Lte, In, IsNull},
+	Bool:      {Eq, Ne, IsNull},
+	Time:      {Eq, Ne, Gt, Gte, Lt, Lte, IsNull},
+	UUID:      {Eq, Ne, In, IsNull},
+	JSONArray: {Contains},
+}
+
+var (
+	filterParam = regexp.MustCompile(`^filter\[([a-z0-9_]+)\](?:\[([a-z]+)\])?$`)
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// reservedParams are query parameters that are never treated as filter shorthands
+var reservedParams = map[string]bool{"sort": true, "cursor": true, "limit": true, "page": true, DeletedParam: true}
+
+const (
+	// DeletedAtField is the column soft deleted rows are marked with
+	DeletedAtField = "deleted_at"
+	// DeletedParam selects soft deleted rows: exclude (the default), include or only
+	DeletedParam = "deleted"
+)
+
+// Field is a whitelisted list field. Name is the API name and must match the JSON name
+// of the model and the column name returned by the base list query.
+type Field struct {
+	Name       string
+	Type       FieldType
+	Filterable bool
+	Sortable   bool
+}
+
+// Sort is a field the list is ordered by
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Filter is a parsed filter condition
+type Filter struct {
+	Field  string
+	Op     Op
+	Values []string
+}
+
+// alias maps a plain query parameter onto a filter
+type alias struct {
+	field string
+	op    Op
+}
+
+// Schema is the per-entity whitelist of fields that can be filtered and sorted
+type Schema struct {
+	fields       map[string]Field
+	aliases      map[string]alias
+	defaultSort  []Sort
+	defaultLimit int
+	softDelete   bool
+}
+
+// NewSchema creates a schema. Every schema has an "id" field used as the sort tiebreaker,
+// defaultSort is written like the sort parameter, e.g. "display_order,name".
+// Sortable fields must not be NULL so cursors can compare them.
+func NewSchema(defaultSort string, fields ...Field) *Schema {
+	s := &Schema{
+		fields:       make(map[string]Field, len(fields)+1),
+		aliases:      make(map[string]alias),
+		defaultLimit: DefaultLimit,
+	}
+	s.fields["id"] = Field{Name: "id", Type: UUID, Filterable: true}
+	for _, field := range fields {
+		s.fields[field.Name] = field
+	}
+
+	sorts, err := s.parseSort(defaultSort)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default sort %q: %v", defaultSort, err))
+	}
+	s.defaultSort = sorts
+	return s
+}
+
+// WithAlias accepts param=value as a filter on field with op. It keeps the plain
+// parameters of older clients working, e.g. category_id for stock_category_id.
+func (s *Schema) WithAlias(param string, field string, op Op) *Schema {
+	if f, ok := s.fields[field]; !ok || !f.Filterable || !allowsOp(f.Type, op) {
+		panic(fmt.Sprintf("invalid alias %q: field '%s' cannot be filtered with '%s'", param, field, op))
+	}
+	s.aliases[param] = alias{field: field, op: op}
+	return s
+}
+
+// WithDefaultLimit changes the page size used when the request does not set one
+func (s *Schema) WithDefaultLimit(limit int) *Schema {
+	if limit < 1 || limit > MaxLimit {
+		panic(fmt.Sprintf("invalid default limit %d", limit))
+	}
+	s.defaultLimit = limit
+	return s
+}
+
+// WithSoftDelete adds the deleted_at field and hides soft deleted rows unless the
+// request sets deleted=include or deleted=only, or filters deleted_at itself
+func (s *Schema) WithSoftDelete() *Schema {
+	s.fields[DeletedAtField] = Field{Name: DeletedAtField, Type: Time, Filterable: true}
+	s.softDelete = true
+	return s
+}
+
+// Spec is a parsed list request
+type Spec struct {
+	schema  *Schema
+	Filters []Filter
+	Sort    []Sort
+	Limit   int
+	Cursor  *Cursor
+}
+
+// Parse reads filter[field][op], sort, cursor and limit from the query string. A plain
+// field=value parameter is accepted as a shorthand for filter[field][eq]=value unless
+// an alias maps it to another filter.
+func (s *Schema) Parse(values url.Values) (*Spec, error) {
+	spec := &Spec{schema: s, Sort: s.defaultSort, Limit: s.defaultLimit}
+	var fieldErrors []sharedErrors.FieldError
+
+	for param, vals := range values {
+		if len(vals) == 0 || reservedParams[param] {
+			continue
+		}
+
+		name, op := param, Eq
+		if match := filterParam.FindStringSubmatch(param); match != nil {
+			name = match[1]
+			if match[2] != "" {
+				op = Op(match[2])
+			}
+		} else if a, ok := s.aliases[param]; ok {
+			name, op = a.field, a.op
+		} else if _, ok := s.fields[param]; !ok {
+			// Unknown plain parameters belong to the handler, not to the list spec
+			continue
+		}
+
+		filter, err := s.parseFilter(name, op, vals[len(vals)-1])
+		if err != nil {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: param, Code: "invalid_filter", Message: err.Error()})
+			continue
+		}
+		spec.Filters = append(spec.Filters, filter)
+	}
+
+	if raw := values.Get("sort"); raw != "" {
+		sorts, err := s.parseSort(raw)
+		if err != nil {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "sort", Code: "invalid_sort", Message: err.Error()})
+		} else {
+			spec.Sort = sorts
+		}
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "limit", Code: "invalid_limit", Message: fmt.Sprintf("limit must be between 1 and %d", MaxLimit)})
+		} else {
+			spec.Limit = limit
+		}
+	}
+
+	if raw := values.Get("cursor"); raw != "" && len(fieldErrors) == 0 {
+		cursor, err := decodeCursor(raw)
+		if err == nil {
+			err = spec.checkCursor(cursor)
+		}
+		if err != nil {
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: "cursor", Code: "invalid_cursor", Message: err.Error()})
+		} else {
+			spec.Cursor = cursor
+		}
+	}
+
+	if s.softDelete && !spec.filters(DeletedAtField) {
+		switch values.Get(DeletedParam) {
+		case "", "exclude":
+			spec.Filters = append(spec.Filters, Filter{Field: DeletedAtField, Op: IsNull, Values: []string{"true"}})
+		case "only":
+			spec.Filters = append(spec.Filters, Filter{Field: DeletedAtField, Op: IsNull, Values: []string{"false"}})
+		case "include":
+		default:
+			fieldErrors = append(fieldErrors, sharedErrors.FieldError{Field: DeletedParam, Code: "invalid_filter", Message: "expected exclude, include or only"})
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, sharedErrors.Validation("invalid_list_query", "invalid filter, sort or pagination parameters", fieldErrors...)
+	}
+
+	// Map iteration order is random, keep the generated SQL stable
+	sort.SliceStable(spec.Filters, func(i, j int) bool {
+		if spec.Filters[i].Field != spec.Filters[j].Field {
+			return spec.Filters[i].Field < spec.Filters[j].Field
+		}
+		return spec.Filters[i].Op < spec.Filters[j].Op
+	})
+	return spec, nil
+}
+
+// filters reports whether the spec has a filter on field
+func (sp *Spec) filters(field string) bool {
+	for _, filter := range sp.Filters {
+		if filter.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFilter validates a filter against the whitelist and normalizes its values
+func (s *Schema) parseFilter(name string, op Op, raw string) (Filter, error) {
+	field, ok := s.fields[name]
+	if !ok || !field.Filterable {
+		return Filter{}, fmt.Errorf("field '%s' cannot be filtered", name)
+	}
+	if !allowsOp(field.Type, op) {
+		return Filter{}, fmt.Errorf("operator '%s' is not supported for field '%s'", op, name)
+	}
+
+	values := []string{raw}
+	if op == In {
+		values = strings.Split(raw, ",")
+	}
+	for i, value := range values {
+		normalized, err := normalizeValue(field.Type, op, strings.TrimSpace(value))
+		if err != nil {
+			return Filter{}, fmt.Errorf("invalid value for field '%s': %w", name, err)
+		}
+		values[i] = normalized
+	}
+
+	return Filter{Field: name, Op: op, Values: values}, nil
+}
+
+// parseSort reads a comma separated list of fields, a leading '-' sorts descending
+func (s *Schema) parseSort(raw string) ([]Sort, error) {
+	var sorts []Sort
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key := Sort{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		field, ok := s.fields[key.Field]
+		if !ok || (!field.Sortable && key.Field != "id") {
+			return nil, fmt.Errorf("field '%s' cannot be sorted", key.Field)
+		}
+		if seen[key.Field] {
+			return nil, fmt.Errorf("field '%s' is sorted more than once", key.Field)
+		}
+		seen[key.Field] = true
+		sorts = append(sorts, key)
+	}
+	return sorts, nil
+}
+
+// keys returns the sort fields with the id tiebreaker appended
+func (sp *Spec) keys() []Sort {
+	keys := make([]Sort, 0, len(sp.Sort)+1)
+	for _, key := range sp.Sort {
+		if key.Field == "id" {
+			return append(keys, key)
+		}
+		keys = append(keys, key)
+	}
+	return append(keys, Sort{Field: "id"})
+}
+
+// SortString returns the sort in the format of the sort parameter
+func (sp *Spec) SortString() string {
+	parts := make([]string, len(sp.Sort))
+	for i, key := range sp.Sort {
+		parts[i] = key.Field
+		if key.Desc {
+			parts[i] = "-" + key.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func allowsOp(fieldType FieldType, op Op) bool {
+	for _, allowed := range opsByType[fieldType] {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeValue validates a raw filter value and returns it in the form bound to SQL
+func normalizeValue(fieldType FieldType, op Op, value string) (string, error) {
+	if op == IsNull {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("expected true or false")
+		}
+		return strconv.FormatBool(b), nil
+	}
+	if value == "" {
+		return "", fmt.Errorf("value is empty")
+	}
+
+	switch fieldType {
+	case Number:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("expected a number")
+		}
+	case Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("expected true or false")
+		}
+		return strconv.FormatBool(b), nil
+	case Time:
+		t, err := parseTime(value)
+		if err != nil {
+			return "", fmt.Errorf("expected an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+		return formatTime(t), nil
+	case UUID:
+		if !uuidPattern.MatchString(value) {
+			return "", fmt.Errorf("expected a UUID")
+		}
+	}
+	return value, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// formatTime renders a time in UTC without a zone, the way timestamp columns store it
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.999999")
+}
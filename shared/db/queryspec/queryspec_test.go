@@ -0,0 +1,217 @@
+package queryspec
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"shared/db/queries"
+	sharedErrors "shared/errors"
+)
+
+var testSchema = NewSchema("display_order,name",
+	Field{Name: "name", Type: String, Filterable: true, Sortable: true},
+	Field{Name: "price", Type: Number, Filterable: true, Sortable: true},
+	Field{Name: "display_order", Type: Number, Sortable: true},
+	Field{Name: "is_available", Type: Bool, Filterable: true},
+	Field{Name: "updated_at", Type: Time, Filterable: true, Sortable: true},
+	Field{Name: "menu_types", Type: JSONArray, Filterable: true},
+).WithAlias("menu_type", "menu_types", Contains).WithAlias("name", "name", Like)
+
+type testItem struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	DisplayOrder int       `json:"display_order"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func mustParse(t *testing.T, query string) *Spec {
+	t.Helper()
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatalf("ParseQuery(%q) error = %v", query, err)
+	}
+	spec, err := testSchema.Parse(values)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", query, err)
+	}
+	return spec
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		query string
+		field string
+	}{
+		{"filter[display_order][eq]=1", "filter[display_order][eq]"},
+		{"filter[name][gt]=a", "filter[name][gt]"},
+		{"filter[price][gte]=cheap", "filter[price][gte]"},
+		{"filter[updated_at][lt]=yesterday", "filter[updated_at][lt]"},
+		{"is_available=maybe", "is_available"},
+		{"sort=-is_available", "sort"},
+		{"limit=1000", "limit"},
+		{"cursor=not-a-cursor", "cursor"},
+	}
+
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+		_, err := testSchema.Parse(values)
+		domainErr, ok := sharedErrors.As(err)
+		if !ok || domainErr.Kind != sharedErrors.KindValidation {
+			t.Errorf("Parse(%q) error = %v; want a validation error", tt.query, err)
+			continue
+		}
+		if len(domainErr.Fields) != 1 || domainErr.Fields[0].Field != tt.field {
+			t.Errorf("Parse(%q) fields = %+v; want an error for %s", tt.query, domainErr.Fields, tt.field)
+		}
+	}
+}
+
+func TestListQuery(t *testing.T) {
+	base, err := queries.Compile("list_items", "SELECT id, name, price FROM items WHERE deleted = @deleted;")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+
+	spec := mustParse(t, "filter[price][gte]=10&filter[name]=Beer&menu_type=lunch&sort=-price&limit=5&page=3")
+	query, args, err := spec.ListQuery(base, queries.Args{"deleted": false})
+	if err != nil {
+		t.Fatalf("ListQuery() error = %v", err)
+	}
+
+	wantSQL := `SELECT * FROM (
+SELECT id, name, price FROM items WHERE deleted = $1
+) AS q
+WHERE q."menu_types" @> jsonb_build_array($2::text)
+  AND q."name" = $3::text
+  AND q."price" >= $4::numeric
+ORDER BY q."price" DESC, q."id" ASC
+LIMIT $5`
+	if query.SQL() != wantSQL {
+		t.Errorf("SQL() =\n%s\nwant\n%s", query.SQL(), wantSQL)
+	}
+
+	positional, err := query.Bind(args)
+	if err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if positional[0] != false || positional[1] != "lunch" || positional[2] != "Beer" || positional[4] != 6 {
+		t.Errorf("Bind() = %v; want the base argument, the filters and limit+1", positional)
+	}
+}
+
+func TestPaginate_Cursors(t *testing.T) {
+	base, _ := queries.Compile("list_items", "SELECT id, name, display_order FROM items")
+	now := time.Date(2026, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	items := []testItem{
+		{ID: "00000000-0000-0000-0000-000000000001", Name: "Ale", DisplayOrder: 1, UpdatedAt: now},
+		{ID: "00000000-0000-0000-0000-000000000002", Name: "Beer", DisplayOrder: 1, UpdatedAt: now},
+		{ID: "00000000-0000-0000-0000-000000000003", Name: "Cider", DisplayOrder: 2, UpdatedAt: now},
+	}
+
+	first := mustParse(t, "limit=2")
+	page, info, err := Paginate(first, items)
+	if err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+	if len(page) != 2 || info.NextCursor == "" || info.PrevCursor != "" {
+		t.Fatalf("first page = %d items, info %+v; want 2 items and only a next cursor", len(page), info)
+	}
+
+	second := mustParse(t, "limit=2&cursor="+info.NextCursor)
+	query, _, err := second.ListQuery(base, nil)
+	if err != nil {
+		t.Fatalf("ListQuery() error = %v", err)
+	}
+	wantCondition := `(q."display_order" > $1::numeric) OR (q."display_order" = $1::numeric AND q."name" > $2::text)`
+	if !strings.Contains(query.SQL(), wantCondition) {
+		t.Errorf("SQL() = %s; want the keyset condition %s", query.SQL(), wantCondition)
+	}
+
+	page, info, err = Paginate(second, items[2:])
+	if err != nil {
+		t.Fatalf("Paginate() error = %v", err)
+	}
+	if len(page) != 1 || info.NextCursor != "" || info.PrevCursor == "" {
+		t.Fatalf("second page = %d items, info %+v; want 1 item and only a prev cursor", len(page), info)
+	}
+
+	back := mustParse(t, "limit=2&cursor="+info.PrevCursor)
+	query, _, _ = back.ListQuery(base, nil)
+	if !strings.Contains(query.SQL(), `ORDER BY q."display_order" DESC, q."name" DESC, q."id" DESC`) {
+		t.Errorf("SQL() = %s; want the order reversed for a previous page", query.SQL())
+	}
+	page, _, _ = Paginate(back, []testItem{items[1], items[0]})
+	if page[0].Name != "Ale" {
+		t.Errorf("previous page starts with %s; want Ale", page[0].Name)
+	}
+
+	if _, err := testSchema.Parse(url.Values{"sort": {"name"}, "cursor": {info.PrevCursor}}); err == nil {
+		t.Error("Parse() accepted a cursor created for another sort")
+	}
+}
+
+func TestPageInfo_SetLinks(t *testing.T) {
+	u, _ := url.Parse("/api/v1/menu/variants?sort=name&page=2")
+	info := PageInfo{NextCursor: "abc"}
+	info.SetLinks(u)
+
+	if info.Links.Self != "/api/v1/menu/variants?sort=name&page=2" {
+		t.Errorf("Self = %s", info.Links.Self)
+	}
+	if info.Links.Next != "/api/v1/menu/variants?cursor=abc&sort=name" || info.Links.Prev != "" {
+		t.Errorf("Links = %+v; want a next link with the cursor and no prev link", info.Links)
+	}
+}
+
+func TestParse_Alias(t *testing.T) {
+	spec := mustParse(t, "name=be_er")
+	if len(spec.Filters) != 1 || spec.Filters[0].Op != Like {
+		t.Fatalf("Filters = %+v; want name mapped to a like filter", spec.Filters)
+	}
+
+	base, _ := queries.Compile("list_items", "SELECT id, name FROM items")
+	query, args, _ := spec.ListQuery(base, nil)
+	if !strings.Contains(query.SQL(), `q."name" ILIKE '%' || $1::text || '%'`) || args["qs_0"] != `be\_er` {
+		t.Errorf("SQL() = %s, args %v; want an escaped ILIKE filter", query.SQL(), args)
+	}
+}
+
+func TestParse_SoftDelete(t *testing.T) {
+	schema := NewSchema("name",
+		Field{Name: "name", Type: String, Filterable: true, Sortable: true},
+	).WithSoftDelete()
+
+	tests := []struct {
+		query string
+		want  []Filter
+	}{
+		{"", []Filter{{Field: DeletedAtField, Op: IsNull, Values: []string{"true"}}}},
+		{"deleted=only", []Filter{{Field: DeletedAtField, Op: IsNull, Values: []string{"false"}}}},
+		{"deleted=include", nil},
+		{"filter[deleted_at][gte]=2025-01-01", []Filter{{Field: DeletedAtField, Op: Gte, Values: []string{"2025-01-01T00:00:00"}}}},
+	}
+
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+		spec, err := schema.Parse(values)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.query, err)
+		}
+		if len(spec.Filters) != len(tt.want) {
+			t.Fatalf("Parse(%q) Filters = %+v; want %+v", tt.query, spec.Filters, tt.want)
+		}
+		for i, want := range tt.want {
+			got := spec.Filters[i]
+			if got.Field != want.Field || got.Op != want.Op || got.Values[0] != want.Values[0] {
+				t.Errorf("Parse(%q) Filters[%d] = %+v; want %+v", tt.query, i, got, want)
+			}
+		}
+	}
+
+	values, _ := url.ParseQuery("deleted=maybe")
+	if _, err := schema.Parse(values); err == nil {
+		t.Error("Parse(deleted=maybe) error = nil; want a validation error")
+	}
+}
@@ -0,0 +1,193 @@
+package queryspec
+
+import (
+	"fmt"
+	"strings"
+
+	"shared/db/queries"
+
+	"github.com/lib/pq"
+)
+
+// paramPrefix namespaces the parameters added to a base query
+const paramPrefix = "qs_"
+
+// casts are the SQL types filter and cursor values are bound as
+var casts = map[FieldType]string{
+	String:    "text",
+	Number:    "numeric",
+	Bool:      "boolean",
+	Time:      "timestamp",
+	UUID:      "uuid",
+	JSONArray: "text",
+}
+
+// ListQuery wraps the base list script with the filters, the cursor condition, the
+// order and the limit. One row more than the page size is fetched so Paginate can
+// tell whether another page exists. args are the arguments of the base script.
+func (sp *Spec) ListQuery(base *queries.Query, args queries.Args) (*queries.Query, queries.Args, error) {
+	b, err := newBuilder(base, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conditions := sp.filterConditions(b)
+	if sp.Cursor != nil {
+		conditions = append(conditions, sp.cursorCondition(b))
+	}
+
+	backward := sp.backward()
+	order := make([]string, 0, len(sp.keys()))
+	for _, key := range sp.keys() {
+		direction := "ASC"
+		if key.Desc != backward {
+			direction = "DESC"
+		}
+		order = append(order, fmt.Sprintf("%s %s", column(key.Field), direction))
+	}
+
+	script := fmt.Sprintf("SELECT * FROM (\n%s\n) AS q%s\nORDER BY %s\nLIMIT %s",
+		baseScript(base), where(conditions), strings.Join(order, ", "), b.bind(sp.Limit+1))
+	return b.compile(base.Name()+"_page", script)
+}
+
+// CountQuery counts the rows of the base list script that match the filters
+func (sp *Spec) CountQuery(base *queries.Query, args queries.Args) (*queries.Query, queries.Args, error) {
+	b, err := newBuilder(base, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	script := fmt.Sprintf("SELECT COUNT(*) FROM (\n%s\n) AS q%s", baseScript(base), where(sp.filterConditions(b)))
+	return b.compile(base.Name()+"_count", script)
+}
+
+// filterConditions renders every filter as a parameterized condition
+func (sp *Spec) filterConditions(b *builder) []string {
+	conditions := make([]string, 0, len(sp.Filters))
+	for _, filter := range sp.Filters {
+		field := sp.schema.fields[filter.Field]
+		col := column(field.Name)
+		cast := casts[field.Type]
+		value := filter.Values[0]
+
+		var condition string
+		switch filter.Op {
+		case Eq:
+			condition = fmt.Sprintf("%s = %s::%s", col, b.bind(value), cast)
+		case Ne:
+			condition = fmt.Sprintf("%s IS DISTINCT FROM %s::%s", col, b.bind(value), cast)
+		case Gt:
+			condition = fmt.Sprintf("%s > %s::%s", col, b.bind(value), cast)
+		case Gte:
+			condition = fmt.Sprintf("%s >= %s::%s", col, b.bind(value), cast)
+		case Lt:
+			condition = fmt.Sprintf("%s < %s::%s", col, b.bind(value), cast)
+		case Lte:
+			condition = fmt.Sprintf("%s <= %s::%s", col, b.bind(value), cast)
+		case In:
+			condition = fmt.Sprintf("%s = ANY(%s::%s[])", col, b.bind(pq.StringArray(filter.Values)), cast)
+		case Like:
+			condition = fmt.Sprintf("%s ILIKE '%%' || %s::text || '%%'", col, b.bind(escapeLike(value)))
+		case IsNull:
+			if value == "true" {
+				condition = fmt.Sprintf("%s IS NULL", col)
+			} else {
+				condition = fmt.Sprintf("%s IS NOT NULL", col)
+			}
+		case Contains:
+			condition = fmt.Sprintf("%s @> jsonb_build_array(%s::text)", col, b.bind(value))
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
+
+// cursorCondition selects the rows after the cursor in the requested direction. For
+// the keys (a, b, id) it renders a > @a OR (a = @a AND b > @b) OR (a = @a AND b = @b AND id > @id),
+// with the comparison flipped for descending keys and for backward pages.
+func (sp *Spec) cursorCondition(b *builder) string {
+	keys := sp.keys()
+	backward := sp.backward()
+
+	params := make([]string, len(keys))
+	for i, key := range keys {
+		params[i] = fmt.Sprintf("%s::%s", b.bind(sp.Cursor.Values[key.Field]), casts[sp.schema.fields[key.Field].Type])
+	}
+
+	alternatives := make([]string, 0, len(keys))
+	for i, key := range keys {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = %s", column(keys[j].Field), params[j]))
+		}
+		op := ">"
+		if key.Desc != backward {
+			op = "<"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", column(key.Field), op, params[i]))
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+func (sp *Spec) backward() bool {
+	return sp.Cursor != nil && sp.Cursor.Prev
+}
+
+// builder collects the arguments of a wrapped query
+type builder struct {
+	args queries.Args
+	next int
+}
+
+func newBuilder(base *queries.Query, args queries.Args) (*builder, error) {
+	b := &builder{args: make(queries.Args, len(args))}
+	for name, value := range args {
+		b.args[name] = value
+	}
+	for _, param := range base.Params() {
+		if strings.HasPrefix(param, paramPrefix) {
+			return nil, fmt.Errorf("query '%s': parameter '%s' uses the reserved prefix '%s'", base.Name(), param, paramPrefix)
+		}
+	}
+	return b, nil
+}
+
+// bind adds a value and returns its parameter reference
+func (b *builder) bind(value interface{}) string {
+	name := fmt.Sprintf("%s%d", paramPrefix, b.next)
+	b.next++
+	b.args[name] = value
+	return "@" + name
+}
+
+func (b *builder) compile(name queries.Name, script string) (*queries.Query, queries.Args, error) {
+	query, err := queries.Compile(name, script)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Every filter, sort and cursor builds other SQL under the same name
+	return query.Uncached(), b.args, nil
+}
+
+// baseScript returns the base script without its trailing semicolon
+func baseScript(base *queries.Query) string {
+	return strings.TrimRight(strings.TrimSpace(base.Script()), ";")
+}
+
+func column(name string) string {
+	return `q."` + name + `"`
+}
+
+func where(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "\nWHERE " + strings.Join(conditions, "\n  AND ")
+}
+
+// escapeLike escapes the LIKE wildcards so user input matches literally
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
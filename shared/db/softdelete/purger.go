@@ -0,0 +1,136 @@
+package softdelete
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	sharedDb "shared/db"
+	sharedHttp "shared/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultRetentionDays is how long soft deleted rows are kept when a service does not configure it
+	DefaultRetentionDays = 90
+	// PurgeInterval is how often the purge job looks for expired rows
+	PurgeInterval = 1 * time.Hour
+)
+
+// Purgeable is a repository of soft deleted rows
+type Purgeable interface {
+	// Purge hard deletes the rows soft deleted before deletedBefore and returns how many it removed
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
+}
+
+// target is a table registered with the purger
+type target struct {
+	name string
+	repo Purgeable
+}
+
+// Result is the outcome of a purge run
+type Result struct {
+	DeletedBefore time.Time      `json:"deleted_before"`
+	Purged        map[string]int `json:"purged"`
+}
+
+// Purger hard deletes soft deleted rows once they are older than the retention window
+type Purger struct {
+	db        *sharedDb.DbHandler
+	retention time.Duration
+	targets   []target
+	source    string
+	logger    *logrus.Logger
+}
+
+// NewPurger creates a purger that keeps soft deleted rows for retentionDays
+func NewPurger(db *sharedDb.DbHandler, retentionDays int, source string, logger *logrus.Logger) *Purger {
+	if retentionDays <= 0 {
+		retentionDays = DefaultRetentionDays
+	}
+
+	return &Purger{
+		db:        db,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		source:    source,
+		logger:    logger,
+	}
+}
+
+// Add registers a table. Tables are purged in the order they were added, so children
+// must be added before the parents they reference.
+func (p *Purger) Add(name string, repo Purgeable) *Purger {
+	p.targets = append(p.targets, target{name: name, repo: repo})
+	return p
+}
+
+// Start purges expired rows every PurgeInterval until ctx is cancelled
+func (p *Purger) Start(ctx context.Context) {
+	ticker := time.NewTicker(PurgeInterval)
+	defer ticker.Stop()
+
+	p.logger.WithFields(logrus.Fields{
+		"source":    p.source,
+		"retention": p.retention.String(),
+	}).Info("Soft delete purger started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.WithField("source", p.source).Info("Soft delete purger stopped")
+			return
+		case <-ticker.C:
+			if !p.db.IsConnected() {
+				continue
+			}
+			if _, err := p.PurgeExpired(ctx); err != nil {
+				p.logger.WithError(err).Warn("Failed to purge soft deleted rows")
+			}
+		}
+	}
+}
+
+// PurgeExpired hard deletes every row soft deleted before the retention window in one
+// transaction, so a failing table leaves all of them untouched
+func (p *Purger) PurgeExpired(ctx context.Context) (*Result, error) {
+	result := &Result{
+		DeletedBefore: time.Now().UTC().Add(-p.retention),
+		Purged:        make(map[string]int, len(p.targets)),
+	}
+
+	err := p.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		for _, t := range p.targets {
+			purged, err := t.repo.Purge(ctx, result.DeletedBefore)
+			if err != nil {
+				return fmt.Errorf("failed to purge %s: %w", t.name, err)
+			}
+			result.Purged[t.name] = purged
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.logger.WithFields(logrus.Fields{
+		"source":         p.source,
+		"deleted_before": result.DeletedBefore,
+		"purged":         result.Purged,
+	}).Info("Soft deleted rows purged")
+	return result, nil
+}
+
+// Handler runs a purge on request, e.g. POST /api/v1/menu/admin/purge
+func (p *Purger) Handler(w http.ResponseWriter, r *http.Request) {
+	result, err := p.PurgeExpired(r.Context())
+	if err != nil {
+		p.logger.WithError(err).Error("Failed to purge soft deleted rows")
+		sharedHttp.SendError(w, r, err, "Failed to purge soft deleted rows")
+		return
+	}
+
+	sharedHttp.SendSuccessResponse(w, http.StatusOK, "Soft deleted rows purged", result)
+}
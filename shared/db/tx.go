@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrTxConflict marks serialization failures and deadlocks. Transactions failing
+// with it can be retried from the start.
+var ErrTxConflict = errors.New("transaction conflict")
+
+const (
+	serializationFailureCode pq.ErrorCode = "40001"
+	deadlockDetectedCode     pq.ErrorCode = "40P01"
+
+	txRetryBaseDelay = 25 * time.Millisecond
+	txRetryMaxDelay  = 1 * time.Second
+)
+
+// TxFunc is the unit of work executed by WithTx. The context it receives carries
+// the transaction, so a nested WithTx call made with it joins the transaction
+// through a savepoint instead of opening a new one.
+type TxFunc func(ctx context.Context, tx *Tx) error
+
+type txContextKey struct{}
+
+// TxFromContext returns the transaction carried by ctx, if any
+func TxFromContext(ctx context.Context) (*Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*Tx)
+	return tx, ok
+}
+
+// WithTx runs fn inside a transaction. The transaction is committed when fn returns
+// nil and rolled back otherwise. Serialization failures and deadlocks restart the
+// whole transaction up to MaxRetries times with a jittered backoff.
+//
+// When ctx already carries a transaction of this handler, fn runs inside a savepoint
+// of that transaction; a failure only rolls back to the savepoint and retries are
+// left to the outermost call.
+func (h *DbHandler) WithTx(ctx context.Context, opts *sql.TxOptions, fn TxFunc) error {
+	if tx, ok := TxFromContext(ctx); ok && tx.handler == h {
+		return tx.withSavepoint(ctx, fn)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := h.runTx(ctx, opts, fn)
+		if err == nil || !IsRetryable(err) || attempt >= h.config.MaxRetries {
+			return err
+		}
+
+		delay := txRetryDelay(attempt)
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"attempt": attempt + 1,
+			"delay":   delay,
+		}).Warn("Transaction conflict, retrying")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// runTx executes a single transaction attempt
+func (h *DbHandler) runTx(ctx context.Context, opts *sql.TxOptions, fn TxFunc) (err error) {
+	if h.db == nil {
+		h.logger.Error("Database connection is nil for WithTx")
+		return fmt.Errorf("database connection is nil")
+	}
+
+	sqlTx, err := h.db.BeginTx(ctx, opts)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to begin transaction for WithTx")
+		return h.handlePostgreSQLError(err)
+	}
+	tx := &Tx{Tx: sqlTx, handler: h}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = sqlTx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, tx), tx); err != nil {
+		if rbErr := sqlTx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			h.logger.WithError(rbErr).Error("Failed to rollback transaction")
+		}
+		return err
+	}
+
+	if err := sqlTx.Commit(); err != nil {
+		h.logger.WithError(err).Error("Failed to commit transaction")
+		return h.handlePostgreSQLError(err)
+	}
+
+	h.logger.Debug("Transaction committed")
+	return nil
+}
+
+// withSavepoint runs fn inside a savepoint of the transaction
+func (t *Tx) withSavepoint(ctx context.Context, fn TxFunc) error {
+	t.depth++
+	defer func() { t.depth-- }()
+
+	savepoint := fmt.Sprintf("sp_%d", t.depth)
+	if _, err := t.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return t.handler.handlePostgreSQLError(err)
+	}
+
+	if err := fn(ctx, t); err != nil {
+		if _, rbErr := t.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rbErr != nil {
+			t.handler.logger.WithError(rbErr).WithField("savepoint", savepoint).Error("Failed to rollback to savepoint")
+		}
+		return err
+	}
+
+	if _, err := t.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); err != nil {
+		return t.handler.handlePostgreSQLError(err)
+	}
+	return nil
+}
+
+// IsRetryable reports whether err is a serialization failure or a deadlock,
+// either raw from the driver or already mapped by the handler
+func IsRetryable(err error) bool {
+	if errors.Is(err, ErrTxConflict) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == serializationFailureCode || pqErr.Code == deadlockDetectedCode
+	}
+	return false
+}
+
+// txRetryDelay returns an exponential backoff with jitter for the given attempt
+func txRetryDelay(attempt int) time.Duration {
+	delay := txRetryBaseDelay << attempt
+	if delay <= 0 || delay > txRetryMaxDelay {
+		delay = txRetryMaxDelay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
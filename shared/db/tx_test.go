@@ -0,0 +1,40 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", &pq.Error{Code: "40001"}, true},
+		{"deadlock", &pq.Error{Code: "40P01"}, true},
+		{"wrapped driver error", fmt.Errorf("failed to update: %w", &pq.Error{Code: "40001"}), true},
+		{"mapped conflict", fmt.Errorf("%w [40001]: could not serialize", ErrTxConflict), true},
+		{"unique violation", &pq.Error{Code: "23505"}, false},
+		{"plain error", errors.New("boom"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable() = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTxRetryDelay(t *testing.T) {
+	for attempt := 0; attempt < 100; attempt++ {
+		delay := txRetryDelay(attempt)
+		if delay <= 0 || delay > txRetryMaxDelay {
+			t.Errorf("txRetryDelay(%d) = %v; want within (0, %v]", attempt, delay, txRetryMaxDelay)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"shared/money"
+)
+
+// Type identifies a domain event
+type Type string
+
+// Domain events exchanged between services
+const (
+	OutcomeInvoiceCreated   Type = "outcome_invoice.created"
+	StockDepleted           Type = "stock.depleted"
+	MenuVariantPriceChanged Type = "menu_variant.price_changed"
+	StockVariantCostChanged Type = "stock_variant.cost_changed"
+)
+
+// Channel is the PostgreSQL NOTIFY channel used to announce published events
+const Channel = "outbox_events"
+
+// ErrNoTransaction is returned when an event is recorded outside of a transaction
+var ErrNoTransaction = errors.New("outbox events must be recorded inside a transaction")
+
+// Event is a domain event stored in the outbox
+type Event struct {
+	ID            string          `json:"id"`
+	Type          Type            `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Source        string          `json:"source"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int             `json:"attempts"`
+	CreatedAt     time.Time       `json:"created_at"`
+	PublishedAt   *time.Time      `json:"published_at,omitempty"`
+}
+
+// Decode unmarshals the event payload into v
+func (e *Event) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s payload: %w", e.Type, err)
+	}
+	return nil
+}
+
+// OutcomeInvoiceCreatedPayload is raised when a supplier purchase invoice is recorded
+type OutcomeInvoiceCreatedPayload struct {
+	InvoiceID       string                      `json:"invoice_id"`
+	TransactionDate time.Time                   `json:"transaction_date"`
+	Items           []OutcomeInvoiceItemPayload `json:"items"`
+}
+
+// OutcomeInvoiceItemPayload is a purchased stock line of an outcome invoice
+type OutcomeInvoiceItemPayload struct {
+	InvoiceItemID  string      `json:"invoice_item_id"`
+	StockVariantID string      `json:"stock_variant_id"`
+	Count          float64     `json:"count"`
+	Unit           string      `json:"unit"`
+	Price          money.Money `json:"price"`
+}
+
+// StockDepletedPayload is raised when a stock count record is marked as out
+type StockDepletedPayload struct {
+	StockCountID   string `json:"stock_count_id"`
+	StockVariantID string `json:"stock_variant_id"`
+}
+
+// MenuVariantPriceChangedPayload is raised when the price of a menu variant changes
+type MenuVariantPriceChangedPayload struct {
+	MenuVariantID string      `json:"menu_variant_id"`
+	OldPrice      money.Money `json:"old_price"`
+	NewPrice      money.Money `json:"new_price"`
+}
+
+// StockVariantCostChangedPayload is raised when the average cost per portion of a stock
+// variant changes, so the menu items made with it can be costed again
+type StockVariantCostChangedPayload struct {
+	StockVariantID string      `json:"stock_variant_id"`
+	OldCost        money.Money `json:"old_cost"`
+	NewCost        money.Money `json:"new_cost"`
+}
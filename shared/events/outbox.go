@@ -0,0 +1,109 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	sharedDb "shared/db"
+	"shared/db/queries"
+	eventsSQL "shared/events/sql"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Outbox records domain events in the transaction of the change that raised them
+type Outbox struct {
+	db      *sharedDb.DbHandler
+	queries *queries.Registry
+	source  string
+	logger  *logrus.Logger
+}
+
+// NewOutbox creates an outbox for the given source service
+func NewOutbox(db *sharedDb.DbHandler, source string, logger *logrus.Logger) (*Outbox, error) {
+	queries, err := eventsSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &Outbox{
+		db:      db,
+		queries: queries,
+		source:  source,
+		logger:  logger,
+	}, nil
+}
+
+// Add records an event. ctx must carry a transaction opened with WithTx so the
+// event is only stored when the surrounding change commits.
+func (o *Outbox) Add(ctx context.Context, eventType Type, aggregateType, aggregateID string, payload interface{}) (*Event, error) {
+	if _, ok := sharedDb.TxFromContext(ctx); !ok {
+		return nil, ErrNoTransaction
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", eventType, err)
+	}
+
+	event, err := scanEvent(o.db.QueryRowNamedContext(ctx, o.queries.Get(eventsSQL.InsertOutboxEventQuery), queries.Args{
+		"event_type":     eventType,
+		"aggregate_type": aggregateType,
+		"aggregate_id":   aggregateID,
+		"source":         o.source,
+		"payload":        string(data),
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+
+	o.logger.WithFields(logrus.Fields{
+		"event_id":     event.ID,
+		"event_type":   event.Type,
+		"aggregate_id": event.AggregateID,
+	}).Debug("Event recorded in outbox")
+	return event, nil
+}
+
+// scanner is implemented by *sharedDb.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEvent scans an outbox_events row
+func scanEvent(row scanner) (*Event, error) {
+	var event Event
+	var payload []byte
+	var publishedAt sql.NullTime
+
+	if err := row.Scan(
+		&event.ID, &event.Type, &event.AggregateType, &event.AggregateID, &event.Source,
+		&payload, &event.Attempts, &event.CreatedAt, &publishedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	event.Payload = json.RawMessage(payload)
+	if publishedAt.Valid {
+		event.PublishedAt = &publishedAt.Time
+	}
+	return &event, nil
+}
+
+// scanEvents scans multiple outbox_events rows
+func scanEvents(rows *sql.Rows) ([]*Event, error) {
+	var events []*Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate events: %w", err)
+	}
+	return events, nil
+}
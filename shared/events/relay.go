@@ -0,0 +1,158 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sharedDb "shared/db"
+	"shared/db/queries"
+	eventsSQL "shared/events/sql"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	RelayInterval  = 1 * time.Second
+	RelayBatchSize = 100
+)
+
+// Broker delivers published events to consumers
+type Broker interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// PostgresBroker announces events with NOTIFY on Channel. The notification is sent
+// when the relay transaction commits, so consumers never see unpublished events.
+type PostgresBroker struct {
+	db      *sharedDb.DbHandler
+	queries *queries.Registry
+}
+
+// NewPostgresBroker creates a LISTEN/NOTIFY broker
+func NewPostgresBroker(db *sharedDb.DbHandler) (*PostgresBroker, error) {
+	queries, err := eventsSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &PostgresBroker{db: db, queries: queries}, nil
+}
+
+// Publish notifies listeners about the event
+func (b *PostgresBroker) Publish(ctx context.Context, event *Event) error {
+	if _, err := b.db.ExecNamedContext(ctx, b.queries.Get(eventsSQL.NotifyEventQuery), queries.Args{
+		"channel":  Channel,
+		"event_id": event.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to notify event: %w", err)
+	}
+	return nil
+}
+
+// Relay moves events recorded by a service from the outbox to the broker.
+// Delivery is at-least-once: an event is marked published only after the
+// broker accepted it, and failed deliveries are retried on the next run.
+type Relay struct {
+	db      *sharedDb.DbHandler
+	queries *queries.Registry
+	broker  Broker
+	source  string
+	logger  *logrus.Logger
+}
+
+// NewRelay creates a relay for the events recorded by source
+func NewRelay(db *sharedDb.DbHandler, broker Broker, source string, logger *logrus.Logger) (*Relay, error) {
+	queries, err := eventsSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &Relay{
+		db:      db,
+		queries: queries,
+		broker:  broker,
+		source:  source,
+		logger:  logger,
+	}, nil
+}
+
+// Start publishes pending events every RelayInterval until ctx is cancelled
+func (r *Relay) Start(ctx context.Context) {
+	ticker := time.NewTicker(RelayInterval)
+	defer ticker.Stop()
+
+	r.logger.WithField("source", r.source).Info("Outbox relay started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.WithField("source", r.source).Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+			if !r.db.IsConnected() {
+				continue
+			}
+			if _, err := r.PublishPending(ctx); err != nil {
+				r.logger.WithError(err).Warn("Failed to publish outbox events")
+			}
+		}
+	}
+}
+
+// PublishPending publishes one batch of unpublished events and returns how many were delivered
+func (r *Relay) PublishPending(ctx context.Context) (int, error) {
+	published := 0
+
+	err := r.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		published = 0
+
+		rows, err := r.db.QueryNamedContext(ctx, r.queries.Get(eventsSQL.FetchUnpublishedEventsQuery), queries.Args{
+			"source": r.source,
+			"limit":  RelayBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fetch unpublished events: %w", err)
+		}
+		pending, err := scanEvents(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, event := range pending {
+			if err := r.broker.Publish(ctx, event); err != nil {
+				r.logger.WithError(err).WithFields(logrus.Fields{
+					"event_id":   event.ID,
+					"event_type": event.Type,
+					"attempts":   event.Attempts + 1,
+				}).Warn("Failed to publish event")
+
+				if _, err := r.db.ExecNamedContext(ctx, r.queries.Get(eventsSQL.MarkEventFailedQuery), queries.Args{
+					"id":         event.ID,
+					"last_error": err.Error(),
+				}); err != nil {
+					return fmt.Errorf("failed to mark event as failed: %w", err)
+				}
+				continue
+			}
+
+			if _, err := r.db.ExecNamedContext(ctx, r.queries.Get(eventsSQL.MarkEventPublishedQuery), queries.Args{"id": event.ID}); err != nil {
+				return fmt.Errorf("failed to mark event as published: %w", err)
+			}
+			published++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if published > 0 {
+		r.logger.WithFields(logrus.Fields{
+			"source":    r.source,
+			"published": published,
+		}).Debug("Outbox events published")
+	}
+	return published, nil
+}
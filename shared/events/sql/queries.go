@@ -0,0 +1,38 @@
+package sql
+
+import (
+	"embed"
+
+	"shared/db/queries"
+)
+
+//go:embed scripts/*.sql
+var sqlScripts embed.FS
+
+// SQL query names
+const (
+	InsertOutboxEventQuery      queries.Name = "insert_outbox_event"
+	FetchUnpublishedEventsQuery queries.Name = "fetch_unpublished_events"
+	MarkEventPublishedQuery     queries.Name = "mark_event_published"
+	MarkEventFailedQuery        queries.Name = "mark_event_failed"
+	NotifyEventQuery            queries.Name = "notify_event"
+	GetOutboxEventQuery         queries.Name = "get_outbox_event"
+	ListPendingEventsQuery      queries.Name = "list_pending_events"
+	ClaimEventQuery             queries.Name = "claim_event"
+	RecordEventFailureQuery     queries.Name = "record_event_failure"
+)
+
+// LoadQueries loads and validates the outbox SQL scripts
+func LoadQueries() (*queries.Registry, error) {
+	return queries.Load(sqlScripts, "scripts",
+		InsertOutboxEventQuery,
+		FetchUnpublishedEventsQuery,
+		MarkEventPublishedQuery,
+		MarkEventFailedQuery,
+		NotifyEventQuery,
+		GetOutboxEventQuery,
+		ListPendingEventsQuery,
+		ClaimEventQuery,
+		RecordEventFailureQuery,
+	)
+}
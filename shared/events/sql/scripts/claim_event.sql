@@ -0,0 +1,4 @@
+INSERT INTO processed_events (consumer, event_id)
+VALUES (@consumer, @event_id)
+ON CONFLICT (consumer, event_id) DO NOTHING
+RETURNING event_id;
@@ -0,0 +1,6 @@
+SELECT id, event_type, aggregate_type, aggregate_id, source, payload, attempts, created_at, published_at
+FROM outbox_events
+WHERE published_at IS NULL AND source = @source
+ORDER BY created_at
+LIMIT @limit
+FOR UPDATE SKIP LOCKED;
@@ -0,0 +1,3 @@
+SELECT id, event_type, aggregate_type, aggregate_id, source, payload, attempts, created_at, published_at
+FROM outbox_events
+WHERE id = @id;
@@ -0,0 +1,3 @@
+INSERT INTO outbox_events (event_type, aggregate_type, aggregate_id, source, payload)
+VALUES (@event_type, @aggregate_type, @aggregate_id, @source, @payload)
+RETURNING id, event_type, aggregate_type, aggregate_id, source, payload, attempts, created_at, published_at;
@@ -0,0 +1,12 @@
+SELECT e.id, e.event_type, e.aggregate_type, e.aggregate_id, e.source, e.payload, e.attempts, e.created_at, e.published_at
+FROM outbox_events e
+LEFT JOIN event_failures f ON f.consumer = @consumer AND f.event_id = e.id
+WHERE e.published_at IS NOT NULL
+  AND e.event_type = ANY(@event_types)
+  AND f.dead_lettered_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM processed_events p
+      WHERE p.consumer = @consumer AND p.event_id = e.id
+  )
+ORDER BY COALESCE(f.attempts, 0), e.created_at
+LIMIT @limit;
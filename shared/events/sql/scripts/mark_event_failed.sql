@@ -0,0 +1,3 @@
+UPDATE outbox_events
+SET attempts = attempts + 1, last_error = @last_error
+WHERE id = @id;
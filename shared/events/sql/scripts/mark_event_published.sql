@@ -0,0 +1,3 @@
+UPDATE outbox_events
+SET published_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL
+WHERE id = @id;
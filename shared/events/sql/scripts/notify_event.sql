@@ -0,0 +1 @@
+SELECT pg_notify(@channel, @event_id);
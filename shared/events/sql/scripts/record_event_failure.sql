@@ -0,0 +1,8 @@
+INSERT INTO event_failures (consumer, event_id, attempts, last_error, dead_lettered_at)
+VALUES (@consumer, @event_id, 1, @last_error, CASE WHEN @max_attempts::integer <= 1 THEN CURRENT_TIMESTAMP END)
+ON CONFLICT (consumer, event_id) DO UPDATE
+SET attempts = event_failures.attempts + 1,
+    last_error = EXCLUDED.last_error,
+    failed_at = CURRENT_TIMESTAMP,
+    dead_lettered_at = CASE WHEN event_failures.attempts + 1 >= @max_attempts::integer THEN CURRENT_TIMESTAMP END
+RETURNING attempts, dead_lettered_at IS NOT NULL;
@@ -0,0 +1,270 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	sharedDb "shared/db"
+	"shared/db/queries"
+	eventsSQL "shared/events/sql"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SubscriberCatchUpInterval = 10 * time.Second
+	SubscriberBatchSize       = 100
+	// SubscriberMaxAttempts is how many times a consumer tries an event before dead-lettering it
+	SubscriberMaxAttempts = 10
+
+	listenerMinReconnect = 1 * time.Second
+	listenerMaxReconnect = 30 * time.Second
+)
+
+// Handler processes an event. It runs inside the transaction that marks the event
+// as processed, so repository calls made with ctx commit or roll back with it.
+type Handler func(ctx context.Context, event *Event) error
+
+// Subscriber consumes published events exactly once per consumer name. Events are
+// received through LISTEN/NOTIFY and a periodic catch-up picks up anything missed
+// while the service was down or the listener was reconnecting.
+//
+// Failed deliveries are counted per consumer; after SubscriberMaxAttempts the event
+// is dead-lettered and left out of catch-up, so it cannot hold back newer events.
+type Subscriber struct {
+	db       *sharedDb.DbHandler
+	store    consumerStore
+	name     string
+	logger   *logrus.Logger
+	handlers map[Type]Handler
+}
+
+// NewSubscriber creates a subscriber identified by name
+func NewSubscriber(db *sharedDb.DbHandler, name string, logger *logrus.Logger) (*Subscriber, error) {
+	queries, err := eventsSQL.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SQL queries: %w", err)
+	}
+
+	return &Subscriber{
+		db:       db,
+		store:    &pgConsumerStore{db: db, queries: queries},
+		name:     name,
+		logger:   logger,
+		handlers: make(map[Type]Handler),
+	}, nil
+}
+
+// Handle registers the handler for an event type. Handlers must be registered before Start.
+func (s *Subscriber) Handle(eventType Type, handler Handler) {
+	s.handlers[eventType] = handler
+}
+
+// Start consumes events until ctx is cancelled
+func (s *Subscriber) Start(ctx context.Context) {
+	listener := s.db.NewListener(listenerMinReconnect, listenerMaxReconnect, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			s.logger.WithError(err).Warn("Event listener connection problem")
+		}
+	})
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	if err := listener.Listen(Channel); err != nil {
+		s.logger.WithError(err).Warn("Failed to listen for events, relying on catch-up only")
+	}
+
+	ticker := time.NewTicker(SubscriberCatchUpInterval)
+	defer ticker.Stop()
+
+	s.logger.WithField("consumer", s.name).Info("Event subscriber started")
+	if s.db.IsConnected() {
+		s.catchUp(ctx)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.WithField("consumer", s.name).Info("Event subscriber stopped")
+			return
+		case n := <-listener.Notify:
+			// A nil notification means the connection was re-established
+			if n == nil {
+				if s.db.IsConnected() {
+					s.catchUp(ctx)
+				}
+				continue
+			}
+			if err := s.Process(ctx, n.Extra); err != nil {
+				s.logger.WithError(err).WithField("event_id", n.Extra).Warn("Failed to process event")
+			}
+		case <-ticker.C:
+			if s.db.IsConnected() {
+				s.catchUp(ctx)
+			}
+		}
+	}
+}
+
+// Process handles a single event unless this consumer already processed it.
+// A handler failure is recorded against the event once the transaction rolled back.
+func (s *Subscriber) Process(ctx context.Context, eventID string) error {
+	var failed *Event
+
+	err := s.store.withTx(ctx, func(ctx context.Context) error {
+		event, err := s.store.getEvent(ctx, eventID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return fmt.Errorf("failed to get event: %w", err)
+		}
+
+		handler, ok := s.handlers[event.Type]
+		if !ok {
+			return nil
+		}
+
+		claimed, err := s.store.claim(ctx, s.name, event.ID)
+		if err != nil {
+			return fmt.Errorf("failed to claim event: %w", err)
+		}
+		if !claimed {
+			// Already processed by this consumer
+			return nil
+		}
+
+		if err := handler(ctx, event); err != nil {
+			failed = event
+			return fmt.Errorf("failed to handle %s event: %w", event.Type, err)
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"consumer":   s.name,
+			"event_id":   event.ID,
+			"event_type": event.Type,
+		}).Info("Event processed")
+		return nil
+	})
+	if err != nil && failed != nil && ctx.Err() == nil {
+		s.recordFailure(ctx, failed, err)
+	}
+	return err
+}
+
+// recordFailure counts a failed delivery and reports the event once it is dead-lettered
+func (s *Subscriber) recordFailure(ctx context.Context, event *Event, cause error) {
+	logEntry := s.logger.WithFields(logrus.Fields{
+		"consumer":   s.name,
+		"event_id":   event.ID,
+		"event_type": event.Type,
+	})
+
+	attempts, deadLettered, err := s.store.recordFailure(ctx, s.name, event.ID, cause.Error(), SubscriberMaxAttempts)
+	if err != nil {
+		logEntry.WithError(err).Warn("Failed to record event failure")
+		return
+	}
+	if deadLettered {
+		logEntry.WithError(cause).WithField("attempts", attempts).Error("Event dead-lettered, it will not be retried")
+	}
+}
+
+// catchUp processes published events this consumer has neither handled nor dead-lettered
+func (s *Subscriber) catchUp(ctx context.Context) {
+	if len(s.handlers) == 0 {
+		return
+	}
+
+	eventTypes := make([]string, 0, len(s.handlers))
+	for eventType := range s.handlers {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	pending, err := s.store.listPending(ctx, s.name, eventTypes, SubscriberBatchSize)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to list pending events")
+		return
+	}
+
+	for _, event := range pending {
+		if err := s.Process(ctx, event.ID); err != nil {
+			s.logger.WithError(err).WithField("event_id", event.ID).Warn("Failed to process event")
+		}
+	}
+}
+
+// consumerStore keeps track of what each consumer did with the published events
+type consumerStore interface {
+	// withTx runs fn in a transaction carried by the context it receives
+	withTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// getEvent returns sql.ErrNoRows when the event does not exist
+	getEvent(ctx context.Context, eventID string) (*Event, error)
+	// claim marks the event processed by consumer and reports false if it already was
+	claim(ctx context.Context, consumer, eventID string) (bool, error)
+	// listPending returns published events of the given types the consumer has neither
+	// processed nor dead-lettered, the least attempted and then the oldest first
+	listPending(ctx context.Context, consumer string, eventTypes []string, limit int) ([]*Event, error)
+	// recordFailure counts a failed delivery and dead-letters the event at maxAttempts
+	recordFailure(ctx context.Context, consumer, eventID, lastError string, maxAttempts int) (int, bool, error)
+}
+
+// pgConsumerStore is the consumerStore backed by processed_events and event_failures
+type pgConsumerStore struct {
+	db      *sharedDb.DbHandler
+	queries *queries.Registry
+}
+
+func (p *pgConsumerStore) withTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return p.db.WithTx(ctx, nil, func(ctx context.Context, tx *sharedDb.Tx) error {
+		return fn(ctx)
+	})
+}
+
+func (p *pgConsumerStore) getEvent(ctx context.Context, eventID string) (*Event, error) {
+	return scanEvent(p.db.QueryRowNamedContext(ctx, p.queries.Get(eventsSQL.GetOutboxEventQuery), queries.Args{"id": eventID}))
+}
+
+func (p *pgConsumerStore) claim(ctx context.Context, consumer, eventID string) (bool, error) {
+	var claimed string
+	err := p.db.QueryRowNamedContext(ctx, p.queries.Get(eventsSQL.ClaimEventQuery), queries.Args{
+		"consumer": consumer,
+		"event_id": eventID,
+	}).Scan(&claimed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (p *pgConsumerStore) listPending(ctx context.Context, consumer string, eventTypes []string, limit int) ([]*Event, error) {
+	rows, err := p.db.QueryNamedContext(ctx, p.queries.Get(eventsSQL.ListPendingEventsQuery), queries.Args{
+		"event_types": pq.Array(eventTypes),
+		"consumer":    consumer,
+		"limit":       limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanEvents(rows)
+}
+
+// recordFailure runs in its own transaction, outside the rolled back delivery
+func (p *pgConsumerStore) recordFailure(ctx context.Context, consumer, eventID, lastError string, maxAttempts int) (int, bool, error) {
+	var attempts int
+	var deadLettered bool
+	err := p.db.QueryRowNamedContext(ctx, p.queries.Get(eventsSQL.RecordEventFailureQuery), queries.Args{
+		"consumer":     consumer,
+		"event_id":     eventID,
+		"last_error":   lastError,
+		"max_attempts": maxAttempts,
+	}).Scan(&attempts, &deadLettered)
+	return attempts, deadLettered, err
+}
@@ -0,0 +1,139 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// memoryStore keeps consumer progress in memory, mirroring the SQL scripts
+type memoryStore struct {
+	events    []*Event
+	processed map[string]bool
+	attempts  map[string]int
+	dead      map[string]bool
+}
+
+func newMemoryStore(events ...*Event) *memoryStore {
+	return &memoryStore{
+		events:    events,
+		processed: make(map[string]bool),
+		attempts:  make(map[string]int),
+		dead:      make(map[string]bool),
+	}
+}
+
+func (m *memoryStore) withTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	processed := make(map[string]bool, len(m.processed))
+	for id := range m.processed {
+		processed[id] = true
+	}
+	if err := fn(ctx); err != nil {
+		m.processed = processed
+		return err
+	}
+	return nil
+}
+
+func (m *memoryStore) getEvent(ctx context.Context, eventID string) (*Event, error) {
+	for _, event := range m.events {
+		if event.ID == eventID {
+			return event, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *memoryStore) claim(ctx context.Context, consumer, eventID string) (bool, error) {
+	if m.processed[eventID] {
+		return false, nil
+	}
+	m.processed[eventID] = true
+	return true, nil
+}
+
+func (m *memoryStore) listPending(ctx context.Context, consumer string, eventTypes []string, limit int) ([]*Event, error) {
+	var pending []*Event
+	for _, event := range m.events {
+		if !m.processed[event.ID] && !m.dead[event.ID] {
+			pending = append(pending, event)
+		}
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return m.attempts[pending[i].ID] < m.attempts[pending[j].ID]
+	})
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending, nil
+}
+
+func (m *memoryStore) recordFailure(ctx context.Context, consumer, eventID, lastError string, maxAttempts int) (int, bool, error) {
+	m.attempts[eventID]++
+	if m.attempts[eventID] >= maxAttempts {
+		m.dead[eventID] = true
+	}
+	return m.attempts[eventID], m.dead[eventID], nil
+}
+
+func TestSubscriber_FailingEventDoesNotBlockLaterEvents(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// A full batch of poison events published before the event that can be handled
+	var events []*Event
+	for i := 0; i < SubscriberBatchSize; i++ {
+		events = append(events, &Event{ID: fmt.Sprintf("poison-%03d", i), Type: OutcomeInvoiceCreated, CreatedAt: created})
+	}
+	events = append(events, &Event{ID: "later", Type: StockDepleted, CreatedAt: created.Add(time.Minute)})
+
+	store := newMemoryStore(events...)
+	s := &Subscriber{store: store, name: "test", logger: logger, handlers: make(map[Type]Handler)}
+
+	var handled []string
+	s.Handle(OutcomeInvoiceCreated, func(ctx context.Context, event *Event) error {
+		return errors.New("stock variant not found")
+	})
+	s.Handle(StockDepleted, func(ctx context.Context, event *Event) error {
+		handled = append(handled, event.ID)
+		return nil
+	})
+
+	// The first catch-up only sees the poison batch and fails all of it
+	s.catchUp(context.Background())
+	if len(handled) != 0 {
+		t.Fatalf("handled = %v after the first catch-up, want nothing", handled)
+	}
+	if store.processed["poison-000"] || store.attempts["poison-000"] != 1 {
+		t.Errorf("poison event processed = %v, attempts = %d, want rolled back with 1 attempt",
+			store.processed["poison-000"], store.attempts["poison-000"])
+	}
+
+	// Failed events go behind the ones not tried yet
+	s.catchUp(context.Background())
+	if len(handled) != 1 || handled[0] != "later" {
+		t.Fatalf("handled = %v after the second catch-up, want [later]", handled)
+	}
+
+	for i := 0; i < SubscriberMaxAttempts; i++ {
+		s.catchUp(context.Background())
+	}
+	if !store.dead["poison-000"] || store.attempts["poison-000"] != SubscriberMaxAttempts {
+		t.Errorf("poison event dead = %v, attempts = %d, want dead-lettered after %d attempts",
+			store.dead["poison-000"], store.attempts["poison-000"], SubscriberMaxAttempts)
+	}
+	if pending, _ := store.listPending(context.Background(), "test", nil, SubscriberBatchSize); len(pending) != 0 {
+		t.Errorf("pending = %d events, want none once dead-lettered", len(pending))
+	}
+	if len(handled) != 1 {
+		t.Errorf("handled = %v, want the later event exactly once", handled)
+	}
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	sharedErrors "shared/errors"
+)
+
+// ETag returns the strong entity tag of a row version, e.g. "3"
+func ETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// SetETag sets the ETag header of a response to the row version
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", ETag(version))
+}
+
+// IfMatchVersion returns the row version a PUT or PATCH is conditioned on. The If-Match
+// header is required; a missing header is a 428 and a tag that is not a row version
+// can never match, so it is a 412.
+func IfMatchVersion(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, sharedErrors.PreconditionRequired("if_match_required", "If-Match header with the entity version is required")
+	}
+
+	tag, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, sharedErrors.PreconditionFailed("if_match_invalid", "If-Match must be a single strong entity tag")
+	}
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 1 {
+		return 0, sharedErrors.PreconditionFailed("if_match_invalid", "If-Match must be a single strong entity tag")
+	}
+	return version, nil
+}
+
+// ContentETag returns the strong entity tag of a response body, a hash of its bytes
+func ContentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return strconv.Quote(hex.EncodeToString(sum[:16]))
+}
+
+// SendTaggedResponse sends a success response tagged with the ETag of its body, for
+// documents that are not a single versioned row. Any change to the data changes the tag,
+// and a GET whose If-None-Match has it gets a 304 without the body. Clients must
+// revalidate before reusing a cached copy unless the handler set its own Cache-Control.
+func SendTaggedResponse(w http.ResponseWriter, r *http.Request, code int, message string, data interface{}) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(Response{Code: code, Message: message, Data: data}); err != nil {
+		SendError(w, r, err, "Failed to encode response")
+		return
+	}
+
+	tag := ContentETag(body.Bytes())
+	w.Header().Set("ETag", tag)
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	if noneMatch(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(body.Bytes())
+}
+
+// noneMatch tells whether an If-None-Match header matches tag, comparing weakly as a GET
+// does
+func noneMatch(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
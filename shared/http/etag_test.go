@@ -0,0 +1,89 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sharedErrors "shared/errors"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		header      string
+		wantVersion int
+		wantKind    sharedErrors.Kind
+	}{
+		{ETag(3), 3, ""},
+		{` "12" `, 12, ""},
+		{"", 0, sharedErrors.KindPreconditionRequired},
+		{"3", 0, sharedErrors.KindPreconditionFailed},
+		{`W/"3"`, 0, sharedErrors.KindPreconditionFailed},
+		{`"3", "4"`, 0, sharedErrors.KindPreconditionFailed},
+		{`"0"`, 0, sharedErrors.KindPreconditionFailed},
+		{"*", 0, sharedErrors.KindPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("PUT", "/api/v1/menu/variants/1", nil)
+		if tt.header != "" {
+			req.Header.Set("If-Match", tt.header)
+		}
+
+		version, err := IfMatchVersion(req)
+		if tt.wantKind == "" {
+			if err != nil || version != tt.wantVersion {
+				t.Errorf("IfMatchVersion(%q) = (%d, %v); want %d", tt.header, version, err, tt.wantVersion)
+			}
+			continue
+		}
+		if kind := sharedErrors.KindOf(err); kind != tt.wantKind {
+			t.Errorf("IfMatchVersion(%q) error kind = %s; want %s", tt.header, kind, tt.wantKind)
+		}
+	}
+}
+
+func TestSendError_PreconditionFailed(t *testing.T) {
+	w := httptest.NewRecorder()
+	SendError(w, httptest.NewRequest("PUT", "/", nil), sharedErrors.PreconditionFailed("menu_variant_version_mismatch", "stale"), "Failed")
+
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("status = %d; want %d", w.Code, http.StatusPreconditionFailed)
+	}
+}
+
+func TestSendTaggedResponse(t *testing.T) {
+	data := map[string]string{"name": "Drinks"}
+
+	w := httptest.NewRecorder()
+	SendTaggedResponse(w, httptest.NewRequest("GET", "/api/v1/menu/tree", nil), http.StatusOK, "Menu tree retrieved", data)
+	tag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || tag == "" || w.Body.Len() == 0 {
+		t.Fatalf("first GET = %d with ETag %q; want 200 with an ETag and a body", w.Code, tag)
+	}
+
+	tests := []struct {
+		ifNoneMatch string
+		data        map[string]string
+		wantCode    int
+	}{
+		{tag, data, http.StatusNotModified},
+		{`"other", W/` + tag, data, http.StatusNotModified},
+		{"*", data, http.StatusNotModified},
+		{`"other"`, data, http.StatusOK},
+		{tag, map[string]string{"name": "Food"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/v1/menu/tree", nil)
+		req.Header.Set("If-None-Match", tt.ifNoneMatch)
+		w := httptest.NewRecorder()
+		SendTaggedResponse(w, req, http.StatusOK, "Menu tree retrieved", tt.data)
+
+		if w.Code != tt.wantCode {
+			t.Errorf("If-None-Match %q, data %v: status = %d; want %d", tt.ifNoneMatch, tt.data, w.Code, tt.wantCode)
+		}
+		if w.Code == http.StatusNotModified && w.Body.Len() != 0 {
+			t.Errorf("If-None-Match %q: 304 has a body", tt.ifNoneMatch)
+		}
+	}
+}
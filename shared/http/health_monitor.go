@@ -2,58 +2,135 @@ package http
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
-// ServiceHealth tracks the health state of an HTTP service
+const (
+	// HealthHistorySize is the number of status transitions kept per dependency
+	HealthHistorySize = 20
+
+	healthCheckTimeout = 3 * time.Second
+)
+
+// Criticality tells how a failing dependency affects the service
+type Criticality string
+
+const (
+	// Critical dependencies are required to serve traffic
+	Critical Criticality = "critical"
+	// Degraded dependencies only reduce functionality when they fail
+	Degraded Criticality = "degraded"
+)
+
+// Status is the health state of a dependency or a whole service
+type Status string
+
+const (
+	StatusUnknown  Status = "unknown"
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// CheckFunc probes a dependency, a nil error means the dependency is up
+type CheckFunc func(ctx context.Context) error
+
+// HealthTransition records a status change of a dependency
+type HealthTransition struct {
+	From      Status    `json:"from"`
+	To        Status    `json:"to"`
+	At        time.Time `json:"at"`
+	LatencyMs int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// ServiceHealth tracks the health state of a dependency
 type ServiceHealth struct {
-	Name      string
-	URL       string
-	Healthy   bool
-	LastCheck time.Time
+	Name         string
+	URL          string
+	Criticality  Criticality
+	Status       Status
+	Latency      time.Duration
+	LastCheck    time.Time
+	LastError    string
+	Since        time.Time
+	History      []HealthTransition
+	Dependencies map[string]DependencyHealth
+
+	check CheckFunc
+}
+
+// DependencyHealth is the reported view of a dependency. Dependencies holds the
+// graph reported by a downstream service's readiness endpoint.
+type DependencyHealth struct {
+	URL          string                      `json:"url,omitempty"`
+	Criticality  Criticality                 `json:"criticality"`
+	Status       Status                      `json:"status"`
+	LatencyMs    int64                       `json:"latency_ms"`
+	LastCheck    time.Time                   `json:"last_check"`
+	LastError    string                      `json:"last_error,omitempty"`
+	Since        time.Time                   `json:"since"`
+	History      []HealthTransition          `json:"history,omitempty"`
+	Dependencies map[string]DependencyHealth `json:"dependencies,omitempty"`
 }
 
-// HealthMonitor can monitor either database health or HTTP services
+// HTTPHealthMonitor periodically checks the dependencies of a service
 type HTTPHealthMonitor struct {
 	logger   *logrus.Logger
 	interval time.Duration
 
-	// For HTTP monitoring
 	client   *http.Client
 	mu       sync.RWMutex
 	services map[string]*ServiceHealth
-	healthy  atomic.Bool
 }
 
-// NewHealthMonitor creates a new health monitor for database
+// NewHealthMonitor creates a new dependency health monitor
 func NewHealthMonitor(logger *logrus.Logger, interval time.Duration) (*HTTPHealthMonitor, error) {
 	hm := &HTTPHealthMonitor{
 		logger:   logger,
 		interval: interval,
-		client:   &http.Client{},
+		client:   &http.Client{Timeout: healthCheckTimeout},
 		services: make(map[string]*ServiceHealth),
 	}
 	hm.logger.WithFields(logrus.Fields{
 		"interval": interval,
 	}).Info("Creating new health monitor")
 
-	// Start as unhealthy until first successful check
-	hm.healthy.Store(true)
 	return hm, nil
 }
 
-func (hm *HTTPHealthMonitor) AddService(name string, url string) {
+// AddService registers an HTTP dependency checked through its readiness endpoint
+func (hm *HTTPHealthMonitor) AddService(name string, url string, criticality Criticality) {
+	hm.addDependency(&ServiceHealth{
+		Name:        name,
+		URL:         url,
+		Criticality: criticality,
+	})
+}
+
+// AddCheck registers a dependency checked by a custom probe, such as a database ping
+func (hm *HTTPHealthMonitor) AddCheck(name string, criticality Criticality, check CheckFunc) {
+	hm.addDependency(&ServiceHealth{
+		Name:        name,
+		Criticality: criticality,
+		check:       check,
+	})
+}
+
+// addDependency stores a dependency in unknown state until its first check
+func (hm *HTTPHealthMonitor) addDependency(svc *ServiceHealth) {
+	svc.Status = StatusUnknown
+	svc.Since = time.Now()
+
 	hm.mu.Lock()
 	defer hm.mu.Unlock()
-	hm.services[name] = &ServiceHealth{
-		Name: name,
-		URL:  url,
-	}
+	hm.services[svc.Name] = svc
 }
 
 // Concurrent start begins the background health monitoring
@@ -61,12 +138,12 @@ func (hm *HTTPHealthMonitor) Start(ctx context.Context) {
 	go hm.startHTTPMonitoring(ctx)
 }
 
-// startHTTPMonitoring monitors HTTP services
+// startHTTPMonitoring monitors the registered dependencies
 func (hm *HTTPHealthMonitor) startHTTPMonitoring(ctx context.Context) {
 	hm.logger.WithField("interval", hm.interval).Info("🏥 HTTP health monitor starting")
 
 	// Initial check
-	hm.checkAllServices()
+	hm.checkAllServices(ctx)
 
 	ticker := time.NewTicker(hm.interval)
 	defer ticker.Stop()
@@ -77,13 +154,13 @@ func (hm *HTTPHealthMonitor) startHTTPMonitoring(ctx context.Context) {
 			hm.logger.Info("HTTP health monitor stopped")
 			return
 		case <-ticker.C:
-			hm.checkAllServices()
+			hm.checkAllServices(ctx)
 		}
 	}
 }
 
-// checkAllServices checks all HTTP services
-func (hm *HTTPHealthMonitor) checkAllServices() {
+// checkAllServices checks all dependencies concurrently
+func (hm *HTTPHealthMonitor) checkAllServices(ctx context.Context) {
 	hm.mu.RLock()
 	services := make([]*ServiceHealth, 0, len(hm.services))
 	for _, svc := range hm.services {
@@ -91,84 +168,211 @@ func (hm *HTTPHealthMonitor) checkAllServices() {
 	}
 	hm.mu.RUnlock()
 
+	var wg sync.WaitGroup
 	for _, svc := range services {
-		hm.checkService(svc)
+		wg.Add(1)
+		go func(svc *ServiceHealth) {
+			defer wg.Done()
+			hm.checkService(ctx, svc)
+		}(svc)
 	}
+	wg.Wait()
 }
 
-// checkService pings a single HTTP service
-func (hm *HTTPHealthMonitor) checkService(svc *ServiceHealth) {
-	req, err := http.NewRequest("GET", svc.URL, nil)
-	if err != nil {
-		hm.logger.WithFields(logrus.Fields{
-			"service": svc.Name,
-			"error":   err.Error(),
-		}).Error("Failed to create HTTP request")
+// checkService probes a single dependency and records the result
+func (hm *HTTPHealthMonitor) checkService(ctx context.Context, svc *ServiceHealth) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
 
-		hm.setServiceHealth(svc.Name, false)
+	start := time.Now()
+	if svc.check != nil {
+		err := svc.check(ctx)
+		status := StatusUp
+		if err != nil {
+			status = StatusDown
+		}
+		hm.setServiceHealth(svc.Name, status, time.Since(start), err, nil)
 		return
 	}
+
+	status, dependencies, err := hm.checkHTTP(ctx, svc.URL)
+	hm.setServiceHealth(svc.Name, status, time.Since(start), err, dependencies)
+}
+
+// checkHTTP calls a readiness endpoint and reads the dependency graph it reports
+func (hm *HTTPHealthMonitor) checkHTTP(ctx context.Context, url string) (Status, map[string]DependencyHealth, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return StatusDown, nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
 	req.Header.Set("X-Health-Check", "true")
 
 	resp, err := hm.client.Do(req)
 	if err != nil {
-		hm.setServiceHealth(svc.Name, false)
-		hm.logger.WithFields(logrus.Fields{
-			"service": svc.Name,
-			"url":     svc.URL,
-			"error":   err.Error(),
-		}).Error("Health check failed")
-		return
+		return StatusDown, nil, err
 	}
 	defer resp.Body.Close()
 
-	healthy := resp.StatusCode == http.StatusOK
-	hm.setServiceHealth(svc.Name, healthy)
+	var report struct {
+		Status       Status                      `json:"status"`
+		Dependencies map[string]DependencyHealth `json:"dependencies"`
+	}
+	// Older services only answer with a status code, so the body is optional
+	_ = json.NewDecoder(resp.Body).Decode(&report)
+
+	if resp.StatusCode != http.StatusOK {
+		return StatusDown, report.Dependencies, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	if report.Status == StatusDegraded {
+		return StatusDegraded, report.Dependencies, nil
+	}
+	return StatusUp, report.Dependencies, nil
 }
 
-// setServiceHealth updates HTTP service health state
-func (hm *HTTPHealthMonitor) setServiceHealth(name string, healthy bool) {
+// setServiceHealth updates the dependency state and records status transitions
+func (hm *HTTPHealthMonitor) setServiceHealth(name string, status Status, latency time.Duration, checkErr error, dependencies map[string]DependencyHealth) {
 	hm.mu.Lock()
 	defer hm.mu.Unlock()
-	if svc, ok := hm.services[name]; ok {
-		svc.Healthy = healthy
-		svc.LastCheck = time.Now()
+
+	svc, ok := hm.services[name]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	errMsg := ""
+	if checkErr != nil {
+		errMsg = checkErr.Error()
+	}
+
+	if svc.Status != status {
+		svc.History = append(svc.History, HealthTransition{
+			From:      svc.Status,
+			To:        status,
+			At:        now,
+			LatencyMs: latency.Milliseconds(),
+			Error:     errMsg,
+		})
+		if len(svc.History) > HealthHistorySize {
+			svc.History = svc.History[len(svc.History)-HealthHistorySize:]
+		}
+
+		fields := logrus.Fields{
+			"dependency":  svc.Name,
+			"criticality": svc.Criticality,
+			"from":        svc.Status,
+			"to":          status,
+			"latency_ms":  latency.Milliseconds(),
+		}
+		if checkErr != nil {
+			hm.logger.WithFields(fields).WithError(checkErr).Error("❌ Dependency health changed")
+		} else {
+			hm.logger.WithFields(fields).Info("✅ Dependency health changed")
+		}
+
+		svc.Status = status
+		svc.Since = now
 	}
+
+	svc.Latency = latency
+	svc.LastCheck = now
+	svc.LastError = errMsg
+	svc.Dependencies = dependencies
 }
 
-// HealthStatus represents the overall health with individual service statuses
+// HealthStatus represents the overall health with individual dependency statuses
 type HealthStatus struct {
-	IsHealthy bool            `json:"is_healthy"`
-	Services  map[string]bool `json:"services"`
+	Status       Status                      `json:"status"`
+	IsHealthy    bool                        `json:"is_healthy"`
+	Services     map[string]bool             `json:"services"`
+	Dependencies map[string]DependencyHealth `json:"dependencies"`
 }
 
-// GetHealthStatus returns overall health and individual service statuses
+// GetHealthStatus returns the overall status and the dependency graph. The service is
+// down when a critical dependency is not available and degraded when any other
+// dependency is failing.
 func (hm *HTTPHealthMonitor) GetHealthStatus() HealthStatus {
 	hm.mu.RLock()
 	defer hm.mu.RUnlock()
 
+	status := StatusUp
 	services := make(map[string]bool)
-	allHealthy := true
+	dependencies := make(map[string]DependencyHealth)
 
 	for name, svc := range hm.services {
-		services[name] = svc.Healthy
-		if !svc.Healthy {
-			allHealthy = false
+		available := svc.Status == StatusUp || svc.Status == StatusDegraded
+		services[name] = available
+
+		switch {
+		case svc.Status == StatusUp:
+		case !available && svc.Criticality == Critical:
+			status = StatusDown
+		case status == StatusUp:
+			status = StatusDegraded
 		}
-	}
 
-	// If no services registered, consider unhealthy
-	if len(services) == 0 {
-		allHealthy = false
+		history := make([]HealthTransition, len(svc.History))
+		copy(history, svc.History)
+		dependencies[name] = DependencyHealth{
+			URL:          svc.URL,
+			Criticality:  svc.Criticality,
+			Status:       svc.Status,
+			LatencyMs:    svc.Latency.Milliseconds(),
+			LastCheck:    svc.LastCheck,
+			LastError:    svc.LastError,
+			Since:        svc.Since,
+			History:      history,
+			Dependencies: svc.Dependencies,
+		}
 	}
 
 	return HealthStatus{
-		IsHealthy: allHealthy,
-		Services:  services,
+		Status:       status,
+		IsHealthy:    status != StatusDown,
+		Services:     services,
+		Dependencies: dependencies,
 	}
 }
 
-// IsHealthy returns the current overall health state
+// IsHealthy reports whether every critical dependency is available
 func (hm *HTTPHealthMonitor) IsHealthy() bool {
 	return hm.GetHealthStatus().IsHealthy
 }
+
+// LivenessHandler reports that the process is running. It never checks dependencies,
+// so a failing database does not get the service restarted.
+func LivenessHandler(service string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"service":   service,
+			"status":    StatusUp,
+			"timestamp": time.Now(),
+		})
+	}
+}
+
+// ReadinessHandler reports whether the service can take traffic together with its
+// dependency graph. Degraded services are still ready.
+func (hm *HTTPHealthMonitor) ReadinessHandler(service string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		healthStatus := hm.GetHealthStatus()
+
+		statusCode := http.StatusOK
+		if !healthStatus.IsHealthy {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"service":      service,
+			"status":       healthStatus.Status,
+			"is_healthy":   healthStatus.IsHealthy,
+			"timestamp":    time.Now(),
+			"services":     healthStatus.Services,
+			"dependencies": healthStatus.Dependencies,
+		})
+	}
+}
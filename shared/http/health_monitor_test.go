@@ -0,0 +1,79 @@
+package http
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestMonitor(t *testing.T) *HTTPHealthMonitor {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+
+	hm, err := NewHealthMonitor(logger, time.Second)
+	if err != nil {
+		t.Fatalf("NewHealthMonitor() error = %v", err)
+	}
+	hm.AddService("database", "", Critical)
+	hm.AddService("data-service", "", Degraded)
+	return hm
+}
+
+func TestGetHealthStatus_Criticality(t *testing.T) {
+	tests := []struct {
+		name        string
+		database    Status
+		dataService Status
+		wantStatus  Status
+		wantHealthy bool
+	}{
+		{"all up", StatusUp, StatusUp, StatusUp, true},
+		{"degraded dependency down", StatusUp, StatusDown, StatusDegraded, true},
+		{"critical dependency down", StatusDown, StatusUp, StatusDown, false},
+		{"critical dependency degraded", StatusDegraded, StatusUp, StatusDegraded, true},
+		{"critical dependency unchecked", StatusUnknown, StatusUp, StatusDown, false},
+	}
+
+	for _, tt := range tests {
+		hm := newTestMonitor(t)
+		if tt.database != StatusUnknown {
+			hm.setServiceHealth("database", tt.database, time.Millisecond, nil, nil)
+		}
+		hm.setServiceHealth("data-service", tt.dataService, time.Millisecond, nil, nil)
+
+		status := hm.GetHealthStatus()
+		if status.Status != tt.wantStatus || status.IsHealthy != tt.wantHealthy {
+			t.Errorf("%s: GetHealthStatus() = (%s, %v); want (%s, %v)", tt.name, status.Status, status.IsHealthy, tt.wantStatus, tt.wantHealthy)
+		}
+	}
+}
+
+func TestSetServiceHealth_History(t *testing.T) {
+	hm := newTestMonitor(t)
+
+	hm.setServiceHealth("database", StatusUp, time.Millisecond, nil, nil)
+	hm.setServiceHealth("database", StatusUp, time.Millisecond, nil, nil)
+	hm.setServiceHealth("database", StatusDown, 5*time.Millisecond, errors.New("connection refused"), nil)
+
+	dep := hm.GetHealthStatus().Dependencies["database"]
+	if len(dep.History) != 2 {
+		t.Fatalf("len(History) = %d; want 2", len(dep.History))
+	}
+	last := dep.History[1]
+	if last.From != StatusUp || last.To != StatusDown || last.Error != "connection refused" || last.LatencyMs != 5 {
+		t.Errorf("last transition = %+v; want up -> down with error and latency", last)
+	}
+
+	for i := 0; i < HealthHistorySize*2; i++ {
+		status := StatusUp
+		if i%2 == 0 {
+			status = StatusDown
+		}
+		hm.setServiceHealth("database", status, time.Millisecond, nil, nil)
+	}
+	if got := len(hm.GetHealthStatus().Dependencies["database"].History); got != HealthHistorySize {
+		t.Errorf("len(History) = %d; want %d", got, HealthHistorySize)
+	}
+}
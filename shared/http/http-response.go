@@ -12,13 +12,6 @@ type Response struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Error   string `json:"error,omitempty"`
-}
-
 // SendSuccess sends a successful JSON response
 func SendSuccessResponse(w http.ResponseWriter, code int, message string, data interface{}) {
 	response := Response{
@@ -32,16 +25,10 @@ func SendSuccessResponse(w http.ResponseWriter, code int, message string, data i
 	json.NewEncoder(w).Encode(response)
 }
 
-// SendError sends an error JSON response
+// SendErrorResponse sends an RFC 7807 problem with a code derived from the status.
+// Prefer SendError when an error value is available.
 func SendErrorResponse(w http.ResponseWriter, code int, message string) {
-	response := ErrorResponse{
-		Code:    code,
-		Message: message,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(response)
+	WriteProblem(w, NewProblem(code, "", message))
 }
 
 // SendJSON sends a generic JSON response
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	sharedErrors "shared/errors"
+)
+
+const (
+	// ProblemContentType is the media type of RFC 7807 error responses
+	ProblemContentType = "application/problem+json"
+
+	problemTypePrefix = "urn:barrest:problem:"
+)
+
+// Problem is an RFC 7807 problem details document. Code is the stable machine-readable
+// error code; Message repeats Detail for clients written against the previous format.
+type Problem struct {
+	Type      string                    `json:"type"`
+	Title     string                    `json:"title"`
+	Status    int                       `json:"status"`
+	Detail    string                    `json:"detail,omitempty"`
+	Instance  string                    `json:"instance,omitempty"`
+	Code      string                    `json:"code"`
+	Message   string                    `json:"message,omitempty"`
+	Errors    []sharedErrors.FieldError `json:"errors,omitempty"`
+	RequestID string                    `json:"request_id,omitempty"`
+	Service   string                    `json:"service,omitempty"`
+	Timestamp time.Time                 `json:"timestamp"`
+}
+
+// NewProblem creates a problem for the given status, code and detail
+func NewProblem(status int, code string, detail string) *Problem {
+	if code == "" {
+		code = statusCode(status)
+	}
+	return &Problem{
+		Type:      problemTypePrefix + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Code:      code,
+		Message:   detail,
+		Timestamp: time.Now(),
+	}
+}
+
+// ProblemFromError builds a problem from err. Domain errors keep their status, code,
+// message and field details; any other error becomes a 500 with the fallback detail
+// so internal messages are not leaked to clients.
+func ProblemFromError(err error, fallback string) *Problem {
+	domainErr, ok := sharedErrors.As(err)
+	if !ok || domainErr.Kind == sharedErrors.KindInternal {
+		code := ""
+		if ok {
+			code = domainErr.Code
+		}
+		return NewProblem(http.StatusInternalServerError, code, fallback)
+	}
+
+	code := domainErr.Code
+	if code == "" {
+		code = string(domainErr.Kind)
+	}
+	problem := NewProblem(HTTPStatus(domainErr.Kind), code, domainErr.Message)
+	problem.Errors = domainErr.Fields
+	return problem
+}
+
+// WithRequest fills the instance and request ID from the request
+func (p *Problem) WithRequest(r *http.Request) *Problem {
+	if r != nil {
+		p.Instance = r.URL.Path
+		p.RequestID = r.Header.Get("X-Request-ID")
+	}
+	return p
+}
+
+// WriteProblem renders a problem as application/problem+json
+func WriteProblem(w http.ResponseWriter, problem *Problem) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// SendError renders err as a problem. fallback is the detail used for errors that
+// are not domain errors.
+func SendError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	WriteProblem(w, ProblemFromError(err, fallback).WithRequest(r))
+}
+
+// HTTPStatus returns the HTTP status a domain error kind is rendered with
+func HTTPStatus(kind sharedErrors.Kind) int {
+	switch kind {
+	case sharedErrors.KindNotFound:
+		return http.StatusNotFound
+	case sharedErrors.KindConflict:
+		return http.StatusConflict
+	case sharedErrors.KindValidation:
+		return http.StatusBadRequest
+	case sharedErrors.KindUnauthorized:
+		return http.StatusUnauthorized
+	case sharedErrors.KindForbidden:
+		return http.StatusForbidden
+	case sharedErrors.KindUnavailable:
+		return http.StatusServiceUnavailable
+	case sharedErrors.KindPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case sharedErrors.KindPreconditionRequired:
+		return http.StatusPreconditionRequired
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// statusCode derives a default error code from an HTTP status, e.g. 404 -> not_found
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sharedErrors "shared/errors"
+)
+
+func TestProblemFromError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{"not found", sharedErrors.NotFound("menu_variant_not_found", "menu item not found"), http.StatusNotFound, "menu_variant_not_found", "menu item not found"},
+		{"wrapped conflict", fmt.Errorf("failed to delete: %w", sharedErrors.Conflict("supplier_in_use", "supplier has invoices")), http.StatusConflict, "supplier_in_use", "supplier has invoices"},
+		{"validation", sharedErrors.Validation("check_violation", "invalid value"), http.StatusBadRequest, "check_violation", "invalid value"},
+		{"unavailable", sharedErrors.Unavailable("database_unavailable", "database unavailable"), http.StatusServiceUnavailable, "database_unavailable", "database unavailable"},
+		{"internal keeps code, hides message", sharedErrors.Internal("database_error", "syntax error at or near"), http.StatusInternalServerError, "database_error", "Failed to update"},
+		{"plain error", errors.New("pq: connection reset"), http.StatusInternalServerError, "internal_server_error", "Failed to update"},
+	}
+
+	for _, tt := range tests {
+		p := ProblemFromError(tt.err, "Failed to update")
+		if p.Status != tt.wantStatus || p.Code != tt.wantCode || p.Detail != tt.wantDetail {
+			t.Errorf("%s: ProblemFromError() = (%d, %s, %q); want (%d, %s, %q)", tt.name, p.Status, p.Code, p.Detail, tt.wantStatus, tt.wantCode, tt.wantDetail)
+		}
+	}
+}
+
+func TestSendError(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/v1/menu/variants", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	w := httptest.NewRecorder()
+
+	err := sharedErrors.Validation("invalid_menu_variant", "invalid menu item",
+		sharedErrors.FieldError{Field: "price", Code: "required", Message: "price is required"})
+	SendError(w, req, err, "Failed to create menu item")
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d; want %d", w.Code, http.StatusBadRequest)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != ProblemContentType {
+		t.Errorf("Content-Type = %s; want %s", contentType, ProblemContentType)
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to unmarshal problem: %v", err)
+	}
+	if problem.Type != "urn:barrest:problem:invalid_menu_variant" || problem.Instance != "/api/v1/menu/variants" || problem.RequestID != "req-123" {
+		t.Errorf("problem = %+v; want type, instance and request id set", problem)
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "price" {
+		t.Errorf("errors = %+v; want the price field error", problem.Errors)
+	}
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"net/http"
+
+	sharedErrors "shared/errors"
+	sharedHttp "shared/http"
+)
+
+// RequireRole only lets through requests whose X-User-Role, set by the gateway from
+// the session, is one of roles
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !allowed[ExtractGatewayHeaders(r).UserRole] {
+				sharedHttp.SendError(w, r, sharedErrors.Forbidden("role_forbidden", "your role is not allowed to perform this action"), "")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
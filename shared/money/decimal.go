@@ -0,0 +1,291 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of decimal places a Decimal keeps. It is two more than the
+// DECIMAL(…,2) columns so cost and tax math does not lose precision before the
+// result is rounded.
+const Scale = 4
+
+// one is a Decimal of 1 in units
+const one = 10000
+
+// ErrDivisionByZero is returned when dividing by a zero Decimal
+var ErrDivisionByZero = errors.New("money: division by zero")
+
+// Decimal is an exact fixed point number with Scale decimal places. The zero value is 0.
+type Decimal struct {
+	units int64
+}
+
+// NewDecimal returns value × 10^-places, e.g. NewDecimal(1250, 2) is 12.50. Digits past
+// Scale are rounded half away from zero.
+func NewDecimal(value int64, places int) Decimal {
+	if places <= Scale {
+		return Decimal{units: value * pow10(Scale-places)}
+	}
+	return Decimal{units: divRound(big.NewInt(value), big.NewInt(pow10(places-Scale)))}
+}
+
+// DecimalFromInt returns a whole number
+func DecimalFromInt(value int64) Decimal {
+	return Decimal{units: value * one}
+}
+
+// DecimalFromFloat converts a float, rounded half away from zero to Scale places. It is
+// meant for values that only exist as floats, such as quantities and settings.
+func DecimalFromFloat(value float64) Decimal {
+	return Decimal{units: int64(math.Round(value * one))}
+}
+
+// ParseDecimal parses a decimal string such as "1250", "-3.5" or "0.1234" exactly.
+// Digits past Scale are rounded half away from zero.
+func ParseDecimal(s string) (Decimal, error) {
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" || !digitsOnly(whole) || !digitsOnly(fraction) {
+		return Decimal{}, fmt.Errorf("money: invalid decimal %q", s)
+	}
+
+	digits, ok := new(big.Int).SetString(whole+fraction+"0", 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("money: invalid decimal %q", s)
+	}
+	if negative {
+		digits.Neg(digits)
+	}
+	// digits carries one extra place so a single rounding step handles every length
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(len(fraction)+1)), nil)
+	units := new(big.Int).Mul(digits, big.NewInt(one))
+	if !units.IsInt64() {
+		return Decimal{}, fmt.Errorf("money: decimal %q out of range", s)
+	}
+	return Decimal{units: divRound(units, scale)}, nil
+}
+
+// MustParseDecimal is ParseDecimal for constants, it panics on invalid input
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Add returns d + o
+func (d Decimal) Add(o Decimal) Decimal {
+	return Decimal{units: d.units + o.units}
+}
+
+// Sub returns d - o
+func (d Decimal) Sub(o Decimal) Decimal {
+	return Decimal{units: d.units - o.units}
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{units: -d.units}
+}
+
+// Mul returns d × o rounded half away from zero to Scale places
+func (d Decimal) Mul(o Decimal) Decimal {
+	product := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(o.units))
+	return Decimal{units: divRound(product, big.NewInt(one))}
+}
+
+// Div returns d ÷ o rounded half away from zero to Scale places
+func (d Decimal) Div(o Decimal) (Decimal, error) {
+	if o.units == 0 {
+		return Decimal{}, ErrDivisionByZero
+	}
+	numerator := new(big.Int).Mul(big.NewInt(d.units), big.NewInt(one))
+	return Decimal{units: divRound(numerator, big.NewInt(o.units))}, nil
+}
+
+// Round rounds d half away from zero to places decimal places, the rule Postgres
+// applies when a value is stored in a DECIMAL column
+func (d Decimal) Round(places int) Decimal {
+	if places >= Scale {
+		return d
+	}
+	if places < 0 {
+		places = 0
+	}
+	step := pow10(Scale - places)
+	return Decimal{units: divRound(big.NewInt(d.units), big.NewInt(step)) * step}
+}
+
+// Cmp returns -1, 0 or +1 when d is less than, equal to or greater than o
+func (d Decimal) Cmp(o Decimal) int {
+	switch {
+	case d.units < o.units:
+		return -1
+	case d.units > o.units:
+		return 1
+	}
+	return 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d
+func (d Decimal) Sign() int {
+	return d.Cmp(Decimal{})
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.units == 0
+}
+
+// Float64 returns the nearest float, for display and ratios only
+func (d Decimal) Float64() float64 {
+	return float64(d.units) / one
+}
+
+// String formats d without trailing zeros, e.g. "12.5"
+func (d Decimal) String() string {
+	return d.format(0)
+}
+
+// StringFixed formats d with exactly places decimal places, rounding when needed
+func (d Decimal) StringFixed(places int) string {
+	if places > Scale {
+		places = Scale
+	}
+	return d.Round(places).format(places)
+}
+
+// format writes d with at least minPlaces decimal places
+func (d Decimal) format(minPlaces int) string {
+	units := d.units
+	sign := ""
+	if units < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(big.NewInt(units)).String()
+	if len(abs) <= Scale {
+		abs = strings.Repeat("0", Scale-len(abs)+1) + abs
+	}
+	whole, fraction := abs[:len(abs)-Scale], abs[len(abs)-Scale:]
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) < minPlaces {
+		fraction += strings.Repeat("0", minPlaces-len(fraction))
+	}
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+// MarshalJSON writes d as a JSON number with its exact digits
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or a numeric string without going through float64
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text, err := jsonNumberText(data)
+	if err != nil || text == "" {
+		return err
+	}
+	parsed, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value stores d as its exact decimal text
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads a NUMERIC column, which the driver returns as text. NULL scans as 0, use
+// *Decimal for nullable columns.
+func (d *Decimal) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Decimal{}
+		return nil
+	case []byte:
+		return d.scanText(string(v))
+	case string:
+		return d.scanText(v)
+	case int64:
+		*d = DecimalFromInt(v)
+		return nil
+	case float64:
+		*d = DecimalFromFloat(v)
+		return nil
+	}
+	return fmt.Errorf("money: cannot scan %T into a decimal", src)
+}
+
+func (d *Decimal) scanText(text string) error {
+	parsed, err := ParseDecimal(text)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// jsonNumberText returns the text of a JSON number or numeric string, empty for null
+func jsonNumberText(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return "", nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		text, err := strconv.Unquote(string(data))
+		if err != nil {
+			return "", fmt.Errorf("money: invalid amount %s", data)
+		}
+		return text, nil
+	}
+	return string(data), nil
+}
+
+// divRound divides and rounds half away from zero
+func divRound(numerator, denominator *big.Int) int64 {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	if twice.Cmp(new(big.Int).Abs(denominator)) >= 0 {
+		if (numerator.Sign() < 0) != (denominator.Sign() < 0) {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient.Int64()
+}
+
+func pow10(n int) int64 {
+	result := int64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
+
+func digitsOnly(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Currency is an ISO 4217 currency code
+type Currency string
+
+// Supported currencies
+const (
+	CRC Currency = "CRC"
+	USD Currency = "USD"
+)
+
+// DefaultCurrency is the currency of amounts read from the database and the API, every
+// price in the bar is in colones
+const DefaultCurrency = CRC
+
+// StoragePlaces is the scale of the DECIMAL(…,2) money columns
+const StoragePlaces = 2
+
+// places is how many decimals a currency is rounded to when an amount is charged.
+// Colones have cents on paper but no coins for them, so CRC rounds to whole colones.
+var places = map[Currency]int{
+	CRC: 0,
+	USD: 2,
+}
+
+// Places returns the number of decimals amounts of c are charged with
+func (c Currency) Places() int {
+	if p, ok := places[c]; ok {
+		return p
+	}
+	return StoragePlaces
+}
+
+// Money is an exact amount in a currency. The zero value is 0 in DefaultCurrency.
+type Money struct {
+	amount   Decimal
+	currency Currency
+}
+
+// New returns amount in currency c
+func New(amount Decimal, c Currency) Money {
+	return Money{amount: amount, currency: c}
+}
+
+// FromInt returns a whole amount in DefaultCurrency
+func FromInt(whole int64) Money {
+	return Money{amount: DecimalFromInt(whole)}
+}
+
+// Parse parses an amount in DefaultCurrency such as "2500" or "1250.50"
+func Parse(s string) (Money, error) {
+	amount, err := ParseDecimal(s)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount}, nil
+}
+
+// MustParse is Parse for constants, it panics on invalid input
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Amount returns the amount without its currency
+func (m Money) Amount() Decimal {
+	return m.amount
+}
+
+// Currency returns the currency of m
+func (m Money) Currency() Currency {
+	if m.currency == "" {
+		return DefaultCurrency
+	}
+	return m.currency
+}
+
+// Add returns m + o. Adding amounts of different currencies is a programming error
+// and panics.
+func (m Money) Add(o Money) Money {
+	m.mustMatch(o)
+	return Money{amount: m.amount.Add(o.amount), currency: m.currency}
+}
+
+// Sub returns m - o. Subtracting amounts of different currencies panics.
+func (m Money) Sub(o Money) Money {
+	m.mustMatch(o)
+	return Money{amount: m.amount.Sub(o.amount), currency: m.currency}
+}
+
+// Mul returns m × quantity, e.g. a unit price times a count
+func (m Money) Mul(quantity Decimal) Money {
+	return Money{amount: m.amount.Mul(quantity), currency: m.currency}
+}
+
+// Div returns m ÷ quantity, e.g. a purchase price split into portions
+func (m Money) Div(quantity Decimal) (Money, error) {
+	amount, err := m.amount.Div(quantity)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, currency: m.currency}, nil
+}
+
+// Percent returns rate percent of m, e.g. the 13% sales tax of a subtotal
+func (m Money) Percent(rate Decimal) Money {
+	amount, _ := m.amount.Mul(rate).Div(DecimalFromInt(100))
+	return Money{amount: amount, currency: m.currency}
+}
+
+// Round rounds m half away from zero to the decimals its currency is charged with,
+// whole colones for CRC
+func (m Money) Round() Money {
+	return m.RoundTo(m.Currency().Places())
+}
+
+// RoundTo rounds m half away from zero to places decimals, e.g. StoragePlaces before
+// a computed cost is stored
+func (m Money) RoundTo(places int) Money {
+	return Money{amount: m.amount.Round(places), currency: m.currency}
+}
+
+// Cmp compares the amounts of m and o, see Decimal.Cmp
+func (m Money) Cmp(o Money) int {
+	m.mustMatch(o)
+	return m.amount.Cmp(o.amount)
+}
+
+// Equal reports whether m and o are the same amount in the same currency
+func (m Money) Equal(o Money) bool {
+	return m.Currency() == o.Currency() && m.amount.Cmp(o.amount) == 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of m
+func (m Money) Sign() int {
+	return m.amount.Sign()
+}
+
+// IsZero reports whether m is 0
+func (m Money) IsZero() bool {
+	return m.amount.IsZero()
+}
+
+// String formats m with its currency, e.g. "2500.00 CRC"
+func (m Money) String() string {
+	return m.amount.format(StoragePlaces) + " " + string(m.Currency())
+}
+
+// MarshalJSON writes the amount as a JSON number with at least two decimals. The
+// currency is implied by the API, which only deals in DefaultCurrency.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.amount.format(StoragePlaces)), nil
+}
+
+// UnmarshalJSON reads a JSON number or numeric string in DefaultCurrency without going
+// through float64
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var amount Decimal
+	if err := amount.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	*m = Money{amount: amount}
+	return nil
+}
+
+// Value stores the exact amount in a DECIMAL column, which rounds it to the column scale
+func (m Money) Value() (driver.Value, error) {
+	return m.amount.Value()
+}
+
+// Scan reads a DECIMAL column as an amount in DefaultCurrency. Use *Money for nullable
+// columns.
+func (m *Money) Scan(src interface{}) error {
+	var amount Decimal
+	if err := amount.Scan(src); err != nil {
+		return err
+	}
+	*m = Money{amount: amount}
+	return nil
+}
+
+func (m Money) mustMatch(o Money) {
+	if m.Currency() != o.Currency() {
+		panic(fmt.Sprintf("money: cannot combine %s and %s", m.Currency(), o.Currency()))
+	}
+}
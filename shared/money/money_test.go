@@ -0,0 +1,158 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0", "0"},
+		{"1250", "1250"},
+		{"1250.50", "1250.5"},
+		{"-3.5", "-3.5"},
+		{".25", "0.25"},
+		{"0.12344", "0.1234"},
+		{"0.12345", "0.1235"},
+		{"-0.12345", "-0.1235"},
+	}
+	for _, tt := range tests {
+		got, err := ParseDecimal(tt.in)
+		if err != nil {
+			t.Fatalf("ParseDecimal(%q) error = %v", tt.in, err)
+		}
+		if got.String() != tt.want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "abc", "1.2.3", "1e5", "-"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) error = nil, want an error", in)
+		}
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	a := MustParseDecimal("0.1")
+	b := MustParseDecimal("0.2")
+	if got := a.Add(b); got.Cmp(MustParseDecimal("0.3")) != 0 {
+		t.Errorf("0.1 + 0.2 = %s, want 0.3", got)
+	}
+
+	if got := MustParseDecimal("2.5").Mul(MustParseDecimal("1.5")); got.String() != "3.75" {
+		t.Errorf("2.5 × 1.5 = %s, want 3.75", got)
+	}
+
+	third, err := DecimalFromInt(1).Div(DecimalFromInt(3))
+	if err != nil {
+		t.Fatalf("Div() error = %v", err)
+	}
+	if third.String() != "0.3333" {
+		t.Errorf("1 ÷ 3 = %s, want 0.3333", third)
+	}
+
+	if _, err := DecimalFromInt(1).Div(Decimal{}); err != ErrDivisionByZero {
+		t.Errorf("Div(0) error = %v, want ErrDivisionByZero", err)
+	}
+}
+
+func TestDecimal_Round(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int
+		want   string
+	}{
+		{"2.345", 2, "2.35"},
+		{"2.344", 2, "2.34"},
+		{"-2.345", 2, "-2.35"},
+		{"1250.5", 0, "1251"},
+		{"1250.49", 0, "1250"},
+		{"-0.5", 0, "-1"},
+	}
+	for _, tt := range tests {
+		if got := MustParseDecimal(tt.in).Round(tt.places); got.String() != tt.want {
+			t.Errorf("Round(%s, %d) = %s, want %s", tt.in, tt.places, got, tt.want)
+		}
+	}
+}
+
+func TestMoney_RoundsByCurrency(t *testing.T) {
+	if got := MustParse("1250.50").Round(); got.Amount().String() != "1251" {
+		t.Errorf("CRC Round() = %s, want 1251", got)
+	}
+	if got := New(MustParseDecimal("12.345"), USD).Round(); got.Amount().String() != "12.35" {
+		t.Errorf("USD Round() = %s, want 12.35", got)
+	}
+}
+
+func TestMoney_Percent(t *testing.T) {
+	subtotal := MustParse("10550")
+	tax := subtotal.Percent(DecimalFromInt(13))
+	if tax.Amount().String() != "1371.5" {
+		t.Errorf("13%% of 10550 = %s, want 1371.5", tax)
+	}
+	if total := subtotal.Add(tax.Round()); total.Amount().String() != "11922" {
+		t.Errorf("total = %s, want 11922", total)
+	}
+}
+
+func TestMoney_MixedCurrenciesPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Add() of CRC and USD did not panic")
+		}
+	}()
+	FromInt(1).Add(New(DecimalFromInt(1), USD))
+}
+
+func TestMoney_JSON(t *testing.T) {
+	type priced struct {
+		Price    Money  `json:"price"`
+		ItemCost *Money `json:"item_cost,omitempty"`
+	}
+
+	data, err := json.Marshal(priced{Price: MustParse("2500")})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"price":2500.00}` {
+		t.Errorf("Marshal() = %s, want {\"price\":2500.00}", data)
+	}
+
+	var decoded priced
+	if err := json.Unmarshal([]byte(`{"price":"1250.5","item_cost":0.1}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !decoded.Price.Equal(MustParse("1250.50")) {
+		t.Errorf("Price = %s, want 1250.50 CRC", decoded.Price)
+	}
+	if decoded.ItemCost == nil || decoded.ItemCost.Amount().String() != "0.1" {
+		t.Errorf("ItemCost = %v, want 0.1", decoded.ItemCost)
+	}
+
+	if err := json.Unmarshal([]byte(`{"price":"free"}`), &decoded); err == nil {
+		t.Error("Unmarshal() of a non numeric price error = nil, want an error")
+	}
+}
+
+func TestMoney_SQL(t *testing.T) {
+	var m Money
+	if err := m.Scan([]byte("1234.56")); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if m.Amount().String() != "1234.56" || m.Currency() != CRC {
+		t.Errorf("Scan() = %s, want 1234.56 CRC", m)
+	}
+
+	value, err := m.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+	if value != "1234.56" {
+		t.Errorf("Value() = %v, want 1234.56", value)
+	}
+}
@@ -0,0 +1,124 @@
+// Package schedule evaluates weekly time windows, such as happy hours and service
+// periods, on the venue's wall clock
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window is a daily time window on some weekdays. From and To are wall clock times since
+// midnight; a window whose end is before its start runs past midnight and belongs to the
+// day it starts on, and one whose end equals its start lasts all day.
+type Window struct {
+	// Days are the weekdays the window starts on, every day when empty
+	Days map[time.Weekday]bool
+	From time.Duration
+	To   time.Duration
+}
+
+// weekdays maps day names and their three letter prefixes to weekdays
+var weekdays = map[string]time.Weekday{}
+
+func init() {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		weekdays[name] = day
+		weekdays[name[:3]] = day
+	}
+}
+
+// ParseDay reads a day name such as "monday" or "mon", in any case
+func ParseDay(name string) (time.Weekday, error) {
+	day, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("unknown day %q", name)
+	}
+	return day, nil
+}
+
+// ParseDays reads a JSON array of day names ("monday", "mon") or numbers, 0 or 7 for
+// Sunday to 6 for Saturday. Null or empty is every day.
+func ParseDays(data []byte) (map[time.Weekday]bool, error) {
+	days := map[time.Weekday]bool{}
+	if len(data) == 0 || string(data) == "null" {
+		return days, nil
+	}
+
+	var values []interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("days must be an array: %w", err)
+	}
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			day, err := ParseDay(v)
+			if err != nil {
+				return nil, err
+			}
+			days[day] = true
+		case float64:
+			if v != float64(int(v)) || v < 0 || v > 7 {
+				return nil, fmt.Errorf("day %v is not 0 to 7", v)
+			}
+			days[time.Weekday(int(v)%7)] = true
+		default:
+			return nil, fmt.Errorf("day %v is not a name or number", v)
+		}
+	}
+	return days, nil
+}
+
+// ParseClock reads an HH:MM or HH:MM:SS time of day as the time since midnight
+func ParseClock(value string) (time.Duration, error) {
+	t, err := time.Parse(time.TimeOnly, value)
+	if err != nil {
+		if t, err = time.Parse("15:04", value); err != nil {
+			return 0, fmt.Errorf("invalid time of day %q, want HH:MM", value)
+		}
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+}
+
+// FormatClock writes a time since midnight as HH:MM, with seconds when it has any
+func FormatClock(since time.Duration) string {
+	clock := fmt.Sprintf("%02d:%02d", int(since/time.Hour), int(since%time.Hour/time.Minute))
+	if seconds := int(since % time.Minute / time.Second); seconds != 0 {
+		clock += fmt.Sprintf(":%02d", seconds)
+	}
+	return clock
+}
+
+// ActiveAt returns the start and end of the window running at the instant at on the wall
+// clock of loc, which may have started the day before. startsOn further limits the days
+// the window starts on, e.g. to a promotion's dates; nil allows every day.
+func (w Window) ActiveAt(at time.Time, loc *time.Location, startsOn func(day time.Time) bool) (time.Time, time.Time, bool) {
+	local := at.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	for _, day := range []time.Time{today, today.AddDate(0, 0, -1)} {
+		if len(w.Days) > 0 && !w.Days[day.Weekday()] {
+			continue
+		}
+		if startsOn != nil && !startsOn(day) {
+			continue
+		}
+
+		start := clock(day, w.From)
+		end := clock(day, w.To)
+		if w.To <= w.From {
+			end = clock(day.AddDate(0, 0, 1), w.To)
+		}
+		if !local.Before(start) && local.Before(end) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// clock returns the wall clock time since midnight on the day of day, in its location
+func clock(day time.Time, since time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		int(since/time.Hour), int(since%time.Hour/time.Minute), int(since%time.Minute/time.Second), 0, day.Location())
+}
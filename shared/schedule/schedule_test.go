@@ -0,0 +1,63 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDays(t *testing.T) {
+	days, err := ParseDays([]byte(`["Monday", "fri", 0, 7]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 3 || !days[time.Monday] || !days[time.Friday] || !days[time.Sunday] {
+		t.Errorf("days = %v, want monday, friday and sunday", days)
+	}
+
+	if days, err := ParseDays(nil); err != nil || len(days) != 0 {
+		t.Errorf("ParseDays(nil) = %v, %v, want every day", days, err)
+	}
+	for _, bad := range []string{`["someday"]`, `[8]`, `[1.5]`, `{"monday": true}`} {
+		if _, err := ParseDays([]byte(bad)); err == nil {
+			t.Errorf("ParseDays(%s) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"17:00":    17 * time.Hour,
+		"07:30:15": 7*time.Hour + 30*time.Minute + 15*time.Second,
+	} {
+		got, err := ParseClock(value)
+		if err != nil || got != want {
+			t.Errorf("ParseClock(%q) = %v, %v, want %v", value, got, err, want)
+		}
+		if back, _ := ParseClock(FormatClock(got)); back != want {
+			t.Errorf("FormatClock(%v) = %q does not parse back", got, FormatClock(got))
+		}
+	}
+	for _, bad := range []string{"", "25:00", "5pm"} {
+		if _, err := ParseClock(bad); err == nil {
+			t.Errorf("ParseClock(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestActiveAt(t *testing.T) {
+	loc := time.FixedZone("CST", -6*60*60)
+	lunch := Window{Days: map[time.Weekday]bool{time.Saturday: true}, From: 11 * time.Hour, To: 15 * time.Hour}
+
+	// 2026-03-07 is a Saturday
+	start, end, ok := lunch.ActiveAt(time.Date(2026, 3, 7, 12, 0, 0, 0, loc), loc, nil)
+	if !ok || start.Hour() != 11 || end.Hour() != 15 {
+		t.Errorf("ActiveAt(Saturday 12:00) = %s, %s, %v, want 11:00 to 15:00", start, end, ok)
+	}
+	if _, _, ok := lunch.ActiveAt(time.Date(2026, 3, 8, 12, 0, 0, 0, loc), loc, nil); ok {
+		t.Error("ActiveAt(Sunday 12:00) is active, want Saturdays only")
+	}
+	never := func(time.Time) bool { return false }
+	if _, _, ok := lunch.ActiveAt(time.Date(2026, 3, 7, 12, 0, 0, 0, loc), loc, never); ok {
+		t.Error("ActiveAt with startsOn rejecting every day is active")
+	}
+}
@@ -44,8 +44,17 @@ function getResponseData(result) {
     return result.data || result;
 }
 
+/**
+ * Build the If-Match header value for an entity version, as returned in its ETag
+ * @param {number} version - The entity's version field
+ * @returns {string}
+ */
+function ifMatch(version) {
+    return `"${version}"`;
+}
+
 // Export for global access
 window.isSuccessfulResponse = isSuccessfulResponse;
 window.getErrorMessage = getErrorMessage;
 window.getResponseData = getResponseData;
-
+window.ifMatch = ifMatch;